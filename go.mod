@@ -3,14 +3,15 @@ module github.com/grantcarthew/acon
 go 1.25.4
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/JohannesKaufmann/html-to-markdown/v2 v2.5.0
 	github.com/spf13/cobra v1.10.1
 	github.com/yuin/goldmark v1.7.13
+	golang.org/x/net v0.47.0
 )
 
 require (
 	github.com/JohannesKaufmann/dom v0.2.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
-	golang.org/x/net v0.47.0 // indirect
 )
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type archiveRequestV1 struct {
+	Pages []archivePageV1 `json:"pages"`
+}
+
+type archivePageV1 struct {
+	ID int64 `json:"id"`
+}
+
+type longTaskResponseV1 struct {
+	ID string `json:"id"`
+}
+
+// ArchivePage starts archiving a page and returns the ID of the long task
+// that archives it. Confluence archives in the background, so the page may
+// still be current when this returns. The v2 API cannot archive, so this
+// uses v1.
+func (c *Client) ArchivePage(ctx context.Context, pageID string) (string, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return "", fmt.Errorf("pageID cannot be empty")
+	}
+	id, err := strconv.ParseInt(pageID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid page ID %s", pageID)
+	}
+
+	body := archiveRequestV1{Pages: []archivePageV1{{ID: id}}}
+	respBody, err := c.doRequest(ctx, "POST", "/wiki/rest/api/content/archive", body)
+	if err != nil {
+		return "", fmt.Errorf("archive page request failed: %w", err)
+	}
+
+	var result longTaskResponseV1
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to parse archive page response: %w", err)
+	}
+	return result.ID, nil
+}
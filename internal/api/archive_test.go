@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_ArchivePage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Method = %s, want POST", r.Method)
+		}
+		if r.URL.Path != "/wiki/rest/api/content/archive" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		var body archiveRequestV1
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if len(body.Pages) != 1 || body.Pages[0].ID != 123 {
+			t.Errorf("body = %+v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id":"task-9","links":{"status":"/rest/api/longtask/task-9"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	taskID, err := client.ArchivePage(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("ArchivePage() error = %v", err)
+	}
+	if taskID != "task-9" {
+		t.Errorf("ArchivePage() = %q, want task-9", taskID)
+	}
+
+	if _, err := client.ArchivePage(context.Background(), " "); err == nil || !strings.Contains(err.Error(), "pageID cannot be empty") {
+		t.Errorf("ArchivePage() with empty ID: error = %v", err)
+	}
+	if _, err := client.ArchivePage(context.Background(), "abc"); err == nil || !strings.Contains(err.Error(), "invalid page ID") {
+		t.Errorf("ArchivePage() with non-numeric ID: error = %v", err)
+	}
+}
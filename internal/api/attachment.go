@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Attachment is a file attached to a page
+type Attachment struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	MediaType    string `json:"mediaType,omitempty"`
+	DownloadLink string `json:"downloadLink,omitempty"`
+}
+
+type attachmentListResponse struct {
+	Results []Attachment `json:"results"`
+}
+
+// attachmentV1 is the v1 representation of an attachment
+type attachmentV1 struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Extensions struct {
+		MediaType string `json:"mediaType"`
+	} `json:"extensions"`
+}
+
+type attachmentListResponseV1 struct {
+	Results []attachmentV1 `json:"results"`
+}
+
+// UploadAttachment attaches data to a page under filename. An existing
+// attachment with the same filename gets a new version instead of a duplicate.
+// The v2 API has no upload endpoint, so this uses v1.
+func (c *Client) UploadAttachment(ctx context.Context, pageID, filename string, data []byte) (*Attachment, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, fmt.Errorf("pageID cannot be empty")
+	}
+	if strings.TrimSpace(filename) == "" {
+		return nil, fmt.Errorf("filename cannot be empty")
+	}
+
+	var start time.Time
+	if c.VerboseLog != nil {
+		start = time.Now()
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.WriteField("minorEdit", "true"); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	path := fmt.Sprintf("/wiki/rest/api/content/%s/child/attachment", url.PathEscape(pageID))
+	reqURL := strings.TrimRight(c.BaseURL, "/") + path
+	c.logVerbose("[API] PUT %s (%s, %d bytes)\n", reqURL, filename, len(data))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, reqURL, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	// Confluence rejects multipart uploads without this XSRF opt-out header
+	req.Header.Set("X-Atlassian-Token", "no-check")
+
+	respBody, err := c.send(req, start)
+	if err != nil {
+		return nil, fmt.Errorf("upload attachment request failed: %w", err)
+	}
+
+	var result attachmentListResponseV1
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse upload attachment response: %w", err)
+	}
+	if len(result.Results) == 0 {
+		return nil, fmt.Errorf("upload attachment response contained no attachment")
+	}
+
+	a := result.Results[0]
+	return &Attachment{ID: a.ID, Title: a.Title, MediaType: a.Extensions.MediaType}, nil
+}
+
+// GetAttachment returns the page's attachment with the given filename, or
+// nil if the page has none by that name.
+func (c *Client) GetAttachment(ctx context.Context, pageID, filename string) (*Attachment, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, fmt.Errorf("pageID cannot be empty")
+	}
+	if strings.TrimSpace(filename) == "" {
+		return nil, fmt.Errorf("filename cannot be empty")
+	}
+
+	path := fmt.Sprintf("/wiki/api/v2/pages/%s/attachments?filename=%s", url.PathEscape(pageID), url.QueryEscape(filename))
+	respBody, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get attachment request failed: %w", err)
+	}
+
+	var result attachmentListResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse get attachment response: %w", err)
+	}
+	for i := range result.Results {
+		if result.Results[i].Title == filename {
+			return &result.Results[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// DownloadAttachment returns the content of the page's attachment with the
+// given filename.
+func (c *Client) DownloadAttachment(ctx context.Context, pageID, filename string) ([]byte, error) {
+	attachment, err := c.GetAttachment(ctx, pageID, filename)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		return nil, fmt.Errorf("attachment not found: %s", filename)
+	}
+	if attachment.DownloadLink == "" {
+		return nil, fmt.Errorf("attachment %s has no download link", filename)
+	}
+
+	// Download links are relative to the /wiki context path
+	data, err := c.doRequest(ctx, "GET", "/wiki"+attachment.DownloadLink, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download attachment request failed: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_UploadAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Method = %s, want PUT", r.Method)
+		}
+		if r.URL.Path != "/wiki/rest/api/content/123/child/attachment" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		if r.Header.Get("X-Atlassian-Token") != "no-check" {
+			t.Errorf("X-Atlassian-Token = %q, want no-check", r.Header.Get("X-Atlassian-Token"))
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if header.Filename != "diagram.svg" || string(data) != "<svg/>" {
+			t.Errorf("uploaded %q = %q", header.Filename, data)
+		}
+		if r.FormValue("minorEdit") != "true" {
+			t.Errorf("minorEdit = %q, want true", r.FormValue("minorEdit"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"id":"att42","title":"diagram.svg","extensions":{"mediaType":"image/svg+xml"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	att, err := client.UploadAttachment(context.Background(), "123", "diagram.svg", []byte("<svg/>"))
+	if err != nil {
+		t.Fatalf("UploadAttachment() error = %v", err)
+	}
+	if att.ID != "att42" || att.Title != "diagram.svg" || att.MediaType != "image/svg+xml" {
+		t.Errorf("UploadAttachment() = %+v", att)
+	}
+}
+
+func TestClient_UploadAttachment_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		pageID      string
+		filename    string
+		status      int
+		response    string
+		errContains string
+	}{
+		{name: "empty page id", filename: "a.svg", errContains: "pageID cannot be empty"},
+		{name: "empty filename", pageID: "123", errContains: "filename cannot be empty"},
+		{name: "api error", pageID: "123", filename: "a.svg", status: http.StatusForbidden, response: `{"message":"no"}`, errContains: "upload attachment request failed"},
+		{name: "empty results", pageID: "123", filename: "a.svg", status: http.StatusOK, response: `{"results":[]}`, errContains: "contained no attachment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, err = client.UploadAttachment(context.Background(), tt.pageID, tt.filename, []byte("x"))
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("UploadAttachment() error = %v, want containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestClient_DownloadAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wiki/api/v2/pages/123/attachments":
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("filename") != "chart one.png" {
+				_, _ = w.Write([]byte(`{"results":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[{"id":"att7","title":"chart one.png","downloadLink":"/download/attachments/123/chart%20one.png?api=v2"}]}`))
+		case "/wiki/download/attachments/123/chart one.png":
+			_, _ = w.Write([]byte("PNGDATA"))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	data, err := client.DownloadAttachment(context.Background(), "123", "chart one.png")
+	if err != nil {
+		t.Fatalf("DownloadAttachment() error = %v", err)
+	}
+	if string(data) != "PNGDATA" {
+		t.Errorf("DownloadAttachment() = %q, want PNGDATA", data)
+	}
+
+	_, err = client.DownloadAttachment(context.Background(), "123", "missing.png")
+	if err == nil || !strings.Contains(err.Error(), "attachment not found") {
+		t.Errorf("missing attachment error = %v", err)
+	}
+}
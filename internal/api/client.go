@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -50,10 +51,27 @@ type Version struct {
 }
 
 type Space struct {
-	ID   string `json:"id"`
-	Key  string `json:"key"`
-	Name string `json:"name"`
-	Type string `json:"type"`
+	ID          string            `json:"id"`
+	Key         string            `json:"key"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Status      string            `json:"status,omitempty"`
+	Description *SpaceDescription `json:"description,omitempty"`
+	HomepageID  string            `json:"homepageId,omitempty"`
+	CreatedAt   string            `json:"createdAt,omitempty"`
+}
+
+// SpaceDescription holds a space description in the requested format
+type SpaceDescription struct {
+	Plain *BodyContent `json:"plain,omitempty"`
+}
+
+// DescriptionText returns the plain-text space description, or "" if none was returned.
+func (s *Space) DescriptionText() string {
+	if s.Description == nil || s.Description.Plain == nil {
+		return ""
+	}
+	return s.Description.Plain.Value
 }
 
 // PaginationLinks represents the _links field in paginated API responses
@@ -125,9 +143,25 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.send(req, start)
+}
+
+// APIError is returned for non-2xx responses, so callers can act on the status code
+type APIError struct {
+	StatusCode int
+	Body       string
+}
 
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// send authenticates and performs req, returning the response body or an
+// error for non-2xx responses. start is the request start time for verbose timing.
+func (c *Client) send(req *http.Request, start time.Time) ([]byte, error) {
 	req.SetBasicAuth(c.Email, c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.client.Do(req)
@@ -149,7 +183,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		c.logVerbose("[API] Error response: %s\n", string(respBody))
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	if c.VerboseLog != nil {
@@ -194,11 +228,21 @@ func (c *Client) CreatePage(ctx context.Context, req *PageCreateRequest) (*Page,
 }
 
 func (c *Client) GetPage(ctx context.Context, pageID string) (*Page, error) {
+	return c.getPage(ctx, pageID, "storage")
+}
+
+// GetPageADF returns a page with its body in Atlassian Document Format, in
+// Body.AtlasDocFormat, instead of storage format.
+func (c *Client) GetPageADF(ctx context.Context, pageID string) (*Page, error) {
+	return c.getPage(ctx, pageID, "atlas_doc_format")
+}
+
+func (c *Client) getPage(ctx context.Context, pageID, bodyFormat string) (*Page, error) {
 	if strings.TrimSpace(pageID) == "" {
 		return nil, fmt.Errorf("pageID cannot be empty")
 	}
 
-	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/api/v2/pages/%s?body-format=storage", pageID), nil)
+	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/api/v2/pages/%s?body-format=%s", pageID, bodyFormat), nil)
 	if err != nil {
 		return nil, fmt.Errorf("get page request failed: %w", err)
 	}
@@ -211,6 +255,47 @@ func (c *Client) GetPage(ctx context.Context, pageID string) (*Page, error) {
 	return &result, nil
 }
 
+// GetPageByTitle returns the current page with the given title in a space,
+// or nil if there is none. The body is not included.
+func (c *Client) GetPageByTitle(ctx context.Context, spaceID, title string) (*Page, error) {
+	if strings.TrimSpace(spaceID) == "" {
+		return nil, fmt.Errorf("spaceID cannot be empty")
+	}
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("title cannot be empty")
+	}
+
+	path := fmt.Sprintf("/wiki/api/v2/pages?space-id=%s&title=%s&status=current", url.QueryEscape(spaceID), url.QueryEscape(title))
+	respBody, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get page by title request failed: %w", err)
+	}
+
+	var result PageListResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse get page by title response: %w", err)
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+	return &result.Results[0], nil
+}
+
+// FindPagesByTitle lists up to limit current pages with the given title, in
+// the space spaceID or, if it is "", in every space. The body is not
+// included.
+func (c *Client) FindPagesByTitle(ctx context.Context, spaceID, title string, limit int) ([]Page, bool, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, false, fmt.Errorf("title cannot be empty")
+	}
+
+	path := fmt.Sprintf("/wiki/api/v2/pages?title=%s&status=current&limit=%d", url.QueryEscape(title), min(limit, maxPerPage))
+	if spaceID != "" {
+		path += "&space-id=" + url.QueryEscape(spaceID)
+	}
+	return c.paginatePages(ctx, path, limit, "find pages by title")
+}
+
 func (c *Client) UpdatePage(ctx context.Context, pageID string, req *PageUpdateRequest) (*Page, error) {
 	if strings.TrimSpace(pageID) == "" {
 		return nil, fmt.Errorf("pageID cannot be empty")
@@ -297,25 +382,102 @@ func (c *Client) MovePage(ctx context.Context, pageID, newParentID string) (*Pag
 	return c.UpdatePage(ctx, pageID, req)
 }
 
+// copyPageRequestV1 is the v1 body for copying a single page
+type copyPageRequestV1 struct {
+	CopyAttachments bool              `json:"copyAttachments"`
+	CopyLabels      bool              `json:"copyLabels"`
+	CopyProperties  bool              `json:"copyProperties"`
+	CopyPermissions bool              `json:"copyPermissions"`
+	Destination     copyDestinationV1 `json:"destination"`
+	PageTitle       string            `json:"pageTitle,omitempty"`
+}
+
+type copyDestinationV1 struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// contentV1 is the part of a v1 content response that CopyPage reads
+type contentV1 struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Version *struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+// CopyPage copies a page, with its attachments, labels, and properties but
+// not its children, to be a child of parentID, which may be in another
+// space. The copy is titled title, or like the page if title is "". The v2
+// API cannot copy pages, so this uses v1. The body is not included in the
+// returned page.
+func (c *Client) CopyPage(ctx context.Context, pageID, parentID, title string) (*Page, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, fmt.Errorf("pageID cannot be empty")
+	}
+	if strings.TrimSpace(parentID) == "" {
+		return nil, fmt.Errorf("parentID cannot be empty")
+	}
+
+	body := copyPageRequestV1{
+		CopyAttachments: true,
+		CopyLabels:      true,
+		CopyProperties:  true,
+		Destination:     copyDestinationV1{Type: "parent_page", Value: parentID},
+		PageTitle:       title,
+	}
+	path := fmt.Sprintf("/wiki/rest/api/content/%s/copy", url.PathEscape(pageID))
+	respBody, err := c.doRequest(ctx, "POST", path, body)
+	if err != nil {
+		return nil, fmt.Errorf("copy page request failed: %w", err)
+	}
+
+	var result contentV1
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse copy page response: %w", err)
+	}
+	page := &Page{ID: result.ID, Title: result.Title, Status: result.Status, ParentID: parentID}
+	if result.Version != nil {
+		page.Version = &Version{Number: result.Version.Number}
+	}
+	return page, nil
+}
+
 const maxPerPage = 25 // Confluence API v2 max per request
 const maxLimit = 1000 // Protect against memory exhaustion and excessive API calls (40 max requests)
 
-// paginatePages handles common pagination logic for page list operations.
-// It validates the limit, fetches pages across multiple API requests if needed,
-// trims results to the exact limit, and returns whether more pages are available.
-func (c *Client) paginatePages(ctx context.Context, initialPath string, limit int, errorContext string) ([]Page, bool, error) {
+// listResponse is the generic shape of a v2 paginated list response.
+type listResponse[T any] struct {
+	Results []T             `json:"results"`
+	Links   PaginationLinks `json:"_links,omitempty"`
+}
+
+// paginate handles common pagination logic for v2 list operations.
+// It validates the limit, fetches results across multiple API requests if needed,
+// trims results to the exact limit, and returns whether more results are available.
+func paginate[T any](ctx context.Context, c *Client, initialPath string, limit int, errorContext string) ([]T, bool, error) {
+	all, _, hasMore, err := paginateFrom[T](ctx, c, initialPath, limit, errorContext)
+	return all, hasMore, err
+}
+
+// paginateFrom is paginate that also returns the API next link for the first
+// result not returned, or "" when the listing is exhausted. Each request asks
+// only for the results still needed, so the next link resumes exactly where
+// this call stopped.
+func paginateFrom[T any](ctx context.Context, c *Client, initialPath string, limit int, errorContext string) ([]T, string, bool, error) {
 	if limit <= 0 {
-		return nil, false, fmt.Errorf("limit must be greater than 0")
+		return nil, "", false, fmt.Errorf("limit must be greater than 0")
 	}
 	if limit > maxLimit {
-		return nil, false, fmt.Errorf("limit cannot exceed %d", maxLimit)
+		return nil, "", false, fmt.Errorf("limit cannot exceed %d", maxLimit)
 	}
 
 	c.logVerbose("[Pagination] Starting pagination: limit=%d\n", limit)
 
-	var allPages []Page
-	hasMore := false
-	path := initialPath
+	var all []T
+	next := ""
+	path := withLimit(initialPath, min(limit, maxPerPage))
 	requestNum := 0
 
 	for {
@@ -324,67 +486,124 @@ func (c *Client) paginatePages(ctx context.Context, initialPath string, limit in
 
 		respBody, err := c.doRequest(ctx, "GET", path, nil)
 		if err != nil {
-			return nil, false, fmt.Errorf("%s request failed: %w", errorContext, err)
+			return nil, "", false, fmt.Errorf("%s request failed: %w", errorContext, err)
 		}
 
-		var result PageListResponse
+		var result listResponse[T]
 		if err := json.Unmarshal(respBody, &result); err != nil {
-			return nil, false, fmt.Errorf("failed to parse %s response: %w", errorContext, err)
+			return nil, "", false, fmt.Errorf("failed to parse %s response: %w", errorContext, err)
 		}
 
-		c.logVerbose("[Pagination] Received %d pages (total so far: %d)\n", len(result.Results), len(allPages)+len(result.Results))
-		allPages = append(allPages, result.Results...)
+		c.logVerbose("[Pagination] Received %d results (total so far: %d)\n", len(result.Results), len(all)+len(result.Results))
+		all = append(all, result.Results...)
 
-		// Check if there are more pages available from the API
-		hasMore = result.Links.Next != ""
+		// Check if there are more results available from the API
+		next = result.Links.Next
 
-		// Stop if we have enough or no more pages
-		if len(allPages) >= limit || !hasMore {
+		// Stop if we have enough or no more results
+		if len(all) >= limit || next == "" {
 			break
 		}
 
 		// Use the API-provided next link for subsequent requests
-		path = result.Links.Next
+		path = withLimit(next, min(limit-len(all), maxPerPage))
 	}
 
 	// Trim to exact limit if we accumulated more than requested
-	trimmed := len(allPages) > limit
+	trimmed := len(all) > limit
 	if trimmed {
-		c.logVerbose("[Pagination] Trimming results from %d to %d\n", len(allPages), limit)
-		allPages = allPages[:limit]
+		c.logVerbose("[Pagination] Trimming results from %d to %d\n", len(all), limit)
+		all = all[:limit]
 	}
 
-	// hasMore is true if either the API has more pages OR we trimmed local results
-	hasMore = hasMore || trimmed
-	c.logVerbose("[Pagination] Complete: returning %d pages, hasMore=%v\n", len(allPages), hasMore)
+	// hasMore is true if either the API has more results OR we trimmed local results
+	hasMore := next != "" || trimmed
+	c.logVerbose("[Pagination] Complete: returning %d results, hasMore=%v\n", len(all), hasMore)
 
-	return allPages, hasMore, nil
+	return all, next, hasMore, nil
+}
+
+// paginatePages handles common pagination logic for page list operations.
+func (c *Client) paginatePages(ctx context.Context, initialPath string, limit int, errorContext string) ([]Page, bool, error) {
+	return paginate[Page](ctx, c, initialPath, limit, errorContext)
 }
 
 func (c *Client) ListPages(ctx context.Context, spaceID string, limit int, sort string) ([]Page, bool, error) {
+	pages, _, hasMore, err := c.listPages(ctx, spaceID, limit, sort, "storage", "")
+	return pages, hasMore, err
+}
+
+// ListPagesWithCursor lists pages in a space starting at cursor and returns
+// the cursor for the next batch, or "" when there are no more pages.
+// The sort order is fixed by the cursor when resuming.
+func (c *Client) ListPagesWithCursor(ctx context.Context, spaceID string, limit int, sort string, cursor Cursor) ([]Page, Cursor, error) {
+	pages, next, _, err := c.listPages(ctx, spaceID, limit, sort, "storage", cursor)
+	return pages, next, err
+}
+
+// ListPagesADFWithCursor is ListPagesWithCursor with each page's body in
+// Atlassian Document Format instead of storage format.
+func (c *Client) ListPagesADFWithCursor(ctx context.Context, spaceID string, limit int, sort string, cursor Cursor) ([]Page, Cursor, error) {
+	pages, next, _, err := c.listPages(ctx, spaceID, limit, sort, "atlas_doc_format", cursor)
+	return pages, next, err
+}
+
+func (c *Client) listPages(ctx context.Context, spaceID string, limit int, sort, bodyFormat string, cursor Cursor) ([]Page, Cursor, bool, error) {
 	if strings.TrimSpace(spaceID) == "" {
-		return nil, false, fmt.Errorf("spaceID cannot be empty")
+		return nil, "", false, fmt.Errorf("spaceID cannot be empty")
 	}
 
-	path := fmt.Sprintf("/wiki/api/v2/pages?space-id=%s&limit=%d&body-format=storage", spaceID, min(limit, maxPerPage))
-	if strings.TrimSpace(sort) != "" {
-		path += fmt.Sprintf("&sort=%s", sort)
+	path, err := cursor.resumePath(cursorKindPages, spaceID, "/wiki/api/v2/pages?")
+	if err != nil {
+		return nil, "", false, err
+	}
+	if path == "" {
+		path = fmt.Sprintf("/wiki/api/v2/pages?space-id=%s&limit=%d&body-format=%s", spaceID, min(limit, maxPerPage), bodyFormat)
+		if strings.TrimSpace(sort) != "" {
+			path += fmt.Sprintf("&sort=%s", sort)
+		}
 	}
 
-	return c.paginatePages(ctx, path, limit, "list pages")
+	pages, next, hasMore, err := paginateFrom[Page](ctx, c, path, limit, "list pages")
+	if err != nil {
+		return nil, "", false, err
+	}
+	return pages, newCursor(cursorKindPages, spaceID, next), hasMore, nil
 }
 
 func (c *Client) GetChildPages(ctx context.Context, parentID string, limit int, sort string) ([]Page, bool, error) {
+	pages, _, hasMore, err := c.getChildPages(ctx, parentID, limit, sort, "")
+	return pages, hasMore, err
+}
+
+// GetChildPagesWithCursor lists the children of parentID starting at cursor and
+// returns the cursor for the next batch, or "" when there are no more pages.
+func (c *Client) GetChildPagesWithCursor(ctx context.Context, parentID string, limit int, sort string, cursor Cursor) ([]Page, Cursor, error) {
+	pages, next, _, err := c.getChildPages(ctx, parentID, limit, sort, cursor)
+	return pages, next, err
+}
+
+func (c *Client) getChildPages(ctx context.Context, parentID string, limit int, sort string, cursor Cursor) ([]Page, Cursor, bool, error) {
 	if strings.TrimSpace(parentID) == "" {
-		return nil, false, fmt.Errorf("parentID cannot be empty")
+		return nil, "", false, fmt.Errorf("parentID cannot be empty")
 	}
 
-	path := fmt.Sprintf("/wiki/api/v2/pages/%s/children?limit=%d", parentID, min(limit, maxPerPage))
-	if strings.TrimSpace(sort) != "" {
-		path += fmt.Sprintf("&sort=%s", sort)
+	path, err := cursor.resumePath(cursorKindChildren, parentID, "/wiki/api/v2/pages/")
+	if err != nil {
+		return nil, "", false, err
+	}
+	if path == "" {
+		path = fmt.Sprintf("/wiki/api/v2/pages/%s/children?limit=%d", parentID, min(limit, maxPerPage))
+		if strings.TrimSpace(sort) != "" {
+			path += fmt.Sprintf("&sort=%s", sort)
+		}
 	}
 
-	return c.paginatePages(ctx, path, limit, "get child pages")
+	pages, next, hasMore, err := paginateFrom[Page](ctx, c, path, limit, "get child pages")
+	if err != nil {
+		return nil, "", false, err
+	}
+	return pages, newCursor(cursorKindChildren, parentID, next), hasMore, nil
 }
 
 func (c *Client) GetSpace(ctx context.Context, spaceKey string) (*Space, error) {
@@ -392,7 +611,7 @@ func (c *Client) GetSpace(ctx context.Context, spaceKey string) (*Space, error)
 		return nil, fmt.Errorf("spaceKey cannot be empty")
 	}
 
-	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/api/v2/spaces?keys=%s", spaceKey), nil)
+	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/api/v2/spaces?keys=%s&description-format=plain", spaceKey), nil)
 	if err != nil {
 		return nil, fmt.Errorf("get space request failed: %w", err)
 	}
@@ -414,7 +633,7 @@ func (c *Client) GetSpaceByID(ctx context.Context, spaceID string) (*Space, erro
 		return nil, fmt.Errorf("spaceID cannot be empty")
 	}
 
-	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/api/v2/spaces/%s", spaceID), nil)
+	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/api/v2/spaces/%s?description-format=plain", spaceID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("get space by id request failed: %w", err)
 	}
@@ -428,37 +647,24 @@ func (c *Client) GetSpaceByID(ctx context.Context, spaceID string) (*Space, erro
 }
 
 func (c *Client) ListSpaces(ctx context.Context, limit int) ([]Space, error) {
-	var allSpaces []Space
-	perPage := min(limit, maxPerPage)
-
-	path := fmt.Sprintf("/wiki/api/v2/spaces?limit=%d", perPage)
-
-	for {
-		respBody, err := c.doRequest(ctx, "GET", path, nil)
-		if err != nil {
-			return nil, fmt.Errorf("list spaces request failed: %w", err)
-		}
-
-		var result SpaceListResponse
-		if err := json.Unmarshal(respBody, &result); err != nil {
-			return nil, fmt.Errorf("failed to parse list spaces response: %w", err)
-		}
-
-		allSpaces = append(allSpaces, result.Results...)
-
-		// Stop if we have enough or no more pages
-		if len(allSpaces) >= limit || result.Links.Next == "" {
-			break
-		}
+	spaces, _, err := c.ListSpacesWithCursor(ctx, limit, "")
+	return spaces, err
+}
 
-		// Use the next link for subsequent requests
-		path = result.Links.Next
+// ListSpacesWithCursor lists spaces starting at cursor and returns the cursor
+// for the next batch, or "" when there are no more spaces.
+func (c *Client) ListSpacesWithCursor(ctx context.Context, limit int, cursor Cursor) ([]Space, Cursor, error) {
+	path, err := cursor.resumePath(cursorKindSpaces, "", "/wiki/api/v2/spaces?")
+	if err != nil {
+		return nil, "", err
 	}
-
-	// Trim to exact limit
-	if len(allSpaces) > limit {
-		allSpaces = allSpaces[:limit]
+	if path == "" {
+		path = fmt.Sprintf("/wiki/api/v2/spaces?limit=%d", min(limit, maxPerPage))
 	}
 
-	return allSpaces, nil
+	spaces, next, _, err := paginateFrom[Space](ctx, c, path, limit, "list spaces")
+	if err != nil {
+		return nil, "", err
+	}
+	return spaces, newCursor(cursorKindSpaces, "", next), nil
 }
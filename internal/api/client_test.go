@@ -220,6 +220,52 @@ func TestClient_GetPage(t *testing.T) {
 	}
 }
 
+func TestClient_GetPageADF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("body-format"); got != "atlas_doc_format" {
+			t.Errorf("body-format = %q, want atlas_doc_format", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","title":"T","body":{"atlas_doc_format":{"representation":"atlas_doc_format","value":"{\"type\":\"doc\"}"}}}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	page, err := client.GetPageADF(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetPageADF() error = %v", err)
+	}
+	if page.Body == nil || page.Body.AtlasDocFormat == nil || page.Body.AtlasDocFormat.Value != `{"type":"doc"}` {
+		t.Errorf("GetPageADF() body = %+v, want the ADF document", page.Body)
+	}
+}
+
+func TestClient_ListPagesADFWithCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("body-format"); got != "atlas_doc_format" {
+			t.Errorf("body-format = %q, want atlas_doc_format", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(PageListResponse{Results: []Page{{ID: "1", Title: "Page 1"}}}) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	pages, next, err := client.ListPagesADFWithCursor(context.Background(), "space-1", 10, "", "")
+	if err != nil {
+		t.Fatalf("ListPagesADFWithCursor() error = %v", err)
+	}
+	if len(pages) != 1 || next != "" {
+		t.Errorf("ListPagesADFWithCursor() = %d pages, next %q, want 1 page and no next", len(pages), next)
+	}
+}
+
 func TestClient_CreatePage(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1389,6 +1435,55 @@ func TestClient_GetSpaceByID(t *testing.T) {
 	}
 }
 
+func TestClient_GetSpaceByID_RicherFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("description-format") != "plain" {
+			t.Errorf("description-format = %q, want %q", r.URL.Query().Get("description-format"), "plain")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": "98765",
+			"key": "TEST",
+			"name": "Test Space",
+			"type": "global",
+			"status": "current",
+			"homepageId": "12345",
+			"createdAt": "2024-03-01T10:00:00.000Z",
+			"description": {"plain": {"value": "Team docs", "representation": "plain"}}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	space, err := client.GetSpaceByID(context.Background(), "98765")
+	if err != nil {
+		t.Fatalf("GetSpaceByID() error = %v", err)
+	}
+
+	if space.Status != "current" {
+		t.Errorf("Status = %q, want %q", space.Status, "current")
+	}
+	if space.HomepageID != "12345" {
+		t.Errorf("HomepageID = %q, want %q", space.HomepageID, "12345")
+	}
+	if space.CreatedAt != "2024-03-01T10:00:00.000Z" {
+		t.Errorf("CreatedAt = %q, want %q", space.CreatedAt, "2024-03-01T10:00:00.000Z")
+	}
+	if got := space.DescriptionText(); got != "Team docs" {
+		t.Errorf("DescriptionText() = %q, want %q", got, "Team docs")
+	}
+}
+
+func TestSpace_DescriptionText_Empty(t *testing.T) {
+	space := &Space{ID: "1", Key: "TEST"}
+	if got := space.DescriptionText(); got != "" {
+		t.Errorf("DescriptionText() = %q, want empty", got)
+	}
+}
+
 func TestClient_ListSpaces(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1547,3 +1642,132 @@ func TestClient_doRequest_ContextCancellation(t *testing.T) {
 		t.Error("Expected error for cancelled context")
 	}
 }
+
+func TestClient_GetPageByTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantID   string
+	}{
+		{name: "found", response: `{"results":[{"id":"42","title":"Setup & Install"}]}`, wantID: "42"},
+		{name: "not found", response: `{"results":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if r.URL.Path != "/wiki/api/v2/pages" || q.Get("space-id") != "7" || q.Get("title") != "Setup & Install" {
+					t.Errorf("GET %s?%s", r.URL.Path, r.URL.RawQuery)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			page, err := client.GetPageByTitle(context.Background(), "7", "Setup & Install")
+			if err != nil {
+				t.Fatalf("GetPageByTitle() error = %v", err)
+			}
+			if tt.wantID == "" {
+				if page != nil {
+					t.Errorf("GetPageByTitle() = %+v, want nil", page)
+				}
+				return
+			}
+			if page == nil || page.ID != tt.wantID {
+				t.Errorf("GetPageByTitle() = %+v, want ID %s", page, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestClient_GetPageByTitle_Validation(t *testing.T) {
+	client, err := NewClient("https://example.atlassian.net", "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.GetPageByTitle(context.Background(), "", "Title"); err == nil || !strings.Contains(err.Error(), "spaceID cannot be empty") {
+		t.Errorf("empty space error = %v", err)
+	}
+	if _, err := client.GetPageByTitle(context.Background(), "7", " "); err == nil || !strings.Contains(err.Error(), "title cannot be empty") {
+		t.Errorf("empty title error = %v", err)
+	}
+}
+
+func TestClient_CopyPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/wiki/rest/api/content/10/copy" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding body: %v", err)
+		}
+		if body["copyAttachments"] != true || body["copyLabels"] != true || body["pageTitle"] != "Copy of Home" {
+			t.Errorf("body = %v", body)
+		}
+		if dest, _ := body["destination"].(map[string]any); dest["type"] != "parent_page" || dest["value"] != "20" {
+			t.Errorf("destination = %v", body["destination"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"30","title":"Copy of Home","status":"current","version":{"number":1}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	page, err := client.CopyPage(context.Background(), "10", "20", "Copy of Home")
+	if err != nil {
+		t.Fatalf("CopyPage() error = %v", err)
+	}
+	if page.ID != "30" || page.Title != "Copy of Home" || page.ParentID != "20" || page.Version == nil || page.Version.Number != 1 {
+		t.Errorf("CopyPage() = %+v", page)
+	}
+
+	if _, err := client.CopyPage(context.Background(), "10", " ", ""); err == nil || !strings.Contains(err.Error(), "parentID cannot be empty") {
+		t.Errorf("empty parent error = %v", err)
+	}
+}
+
+func TestClient_FindPagesByTitle(t *testing.T) {
+	tests := []struct {
+		name      string
+		spaceID   string
+		wantQuery string
+	}{
+		{name: "in space", spaceID: "7", wantQuery: "limit=25&space-id=7&status=current&title=Runbook%3A+Deploys"},
+		{name: "every space", wantQuery: "limit=25&status=current&title=Runbook%3A+Deploys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/wiki/api/v2/pages" || r.URL.Query().Encode() != tt.wantQuery {
+					t.Errorf("GET %s?%s, want query %s", r.URL.Path, r.URL.Query().Encode(), tt.wantQuery)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"results":[{"id":"42","spaceId":"7","title":"Runbook: Deploys"},{"id":"43","spaceId":"8","title":"Runbook: Deploys"}]}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			pages, more, err := client.FindPagesByTitle(context.Background(), tt.spaceID, "Runbook: Deploys", 25)
+			if err != nil {
+				t.Fatalf("FindPagesByTitle() error = %v", err)
+			}
+			if len(pages) != 2 || pages[0].ID != "42" || more {
+				t.Errorf("FindPagesByTitle() = %+v, %v", pages, more)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Cursor is an opaque token that resumes a list or search operation where a
+// previous call stopped. The empty Cursor starts from the first result.
+//
+// A cursor records the operation and scope (space, parent page, query) it was
+// issued for, so it cannot be replayed against a different listing.
+type Cursor string
+
+// Cursor kinds identify the operation a cursor belongs to
+const (
+	cursorKindPages    = "pages"
+	cursorKindChildren = "children"
+	cursorKindSpaces   = "spaces"
+	cursorKindTasks    = "tasks"
+	cursorKindSearch   = "search"
+)
+
+// cursorPayload is the decoded form of a Cursor
+type cursorPayload struct {
+	Kind  string `json:"k"`
+	Scope string `json:"s,omitempty"`
+	Next  string `json:"n"`
+}
+
+// newCursor encodes a cursor for the given operation. An empty next yields an
+// empty Cursor, meaning there are no further results.
+func newCursor(kind, scope, next string) Cursor {
+	if next == "" {
+		return ""
+	}
+	data, err := json.Marshal(cursorPayload{Kind: kind, Scope: scope, Next: next})
+	if err != nil {
+		return ""
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(data))
+}
+
+// decode validates the cursor against the expected operation and scope and
+// returns the stored continuation value. An empty Cursor decodes to "".
+func (c Cursor) decode(kind, scope string) (string, error) {
+	if c == "" {
+		return "", nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(string(c)))
+	if err != nil {
+		return "", fmt.Errorf("invalid cursor: not an acon cursor token")
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(data, &p); err != nil || p.Kind == "" || p.Next == "" {
+		return "", fmt.Errorf("invalid cursor: not an acon cursor token")
+	}
+	if p.Kind != kind {
+		return "", fmt.Errorf("invalid cursor: issued for %s, not %s", p.Kind, kind)
+	}
+	if p.Scope != scope {
+		return "", fmt.Errorf("invalid cursor: issued for a different %s listing", kind)
+	}
+
+	return p.Next, nil
+}
+
+// resumePath decodes a v2 list cursor into the API path to fetch next.
+// The path must stay under prefix so a cursor cannot redirect requests
+// to an unrelated endpoint.
+func (c Cursor) resumePath(kind, scope, prefix string) (string, error) {
+	next, err := c.decode(kind, scope)
+	if err != nil || next == "" {
+		return next, err
+	}
+	if !strings.HasPrefix(next, prefix) {
+		return "", fmt.Errorf("invalid cursor: unexpected resume path")
+	}
+	return next, nil
+}
+
+// withLimit returns path with its limit query parameter set to n, so each
+// request asks for no more results than the caller still needs.
+func withLimit(path string, n int) string {
+	u, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(n))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := newCursor(cursorKindPages, "space-1", "/wiki/api/v2/pages?cursor=abc&limit=25")
+	if c == "" {
+		t.Fatal("newCursor() returned empty cursor")
+	}
+
+	next, err := c.decode(cursorKindPages, "space-1")
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if next != "/wiki/api/v2/pages?cursor=abc&limit=25" {
+		t.Errorf("decode() = %q", next)
+	}
+}
+
+func TestCursor_EmptyNext(t *testing.T) {
+	if c := newCursor(cursorKindPages, "space-1", ""); c != "" {
+		t.Errorf("newCursor() with empty next = %q, want empty", c)
+	}
+
+	next, err := Cursor("").decode(cursorKindPages, "space-1")
+	if err != nil || next != "" {
+		t.Errorf("empty cursor decode() = %q, %v, want empty and nil", next, err)
+	}
+}
+
+func TestCursor_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		cursor      Cursor
+		kind        string
+		scope       string
+		errContains string
+	}{
+		{
+			name:        "not base64",
+			cursor:      "not a cursor!",
+			kind:        cursorKindPages,
+			errContains: "not an acon cursor token",
+		},
+		{
+			name:        "raw confluence cursor",
+			cursor:      "eyJsaW1pdCI6MjUsInN0YXJ0IjoyNX0",
+			kind:        cursorKindSearch,
+			errContains: "not an acon cursor token",
+		},
+		{
+			name:        "wrong kind",
+			cursor:      newCursor(cursorKindSpaces, "", "/wiki/api/v2/spaces?cursor=abc"),
+			kind:        cursorKindPages,
+			scope:       "space-1",
+			errContains: "issued for spaces, not pages",
+		},
+		{
+			name:        "wrong scope",
+			cursor:      newCursor(cursorKindPages, "space-1", "/wiki/api/v2/pages?cursor=abc"),
+			kind:        cursorKindPages,
+			scope:       "space-2",
+			errContains: "different pages listing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cursor.decode(tt.kind, tt.scope)
+			if err == nil {
+				t.Fatal("decode() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("decode() error = %q, want containing %q", err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+func TestCursor_ResumePathPrefix(t *testing.T) {
+	c := newCursor(cursorKindSpaces, "", "/wiki/rest/api/user/current")
+	if _, err := c.resumePath(cursorKindSpaces, "", "/wiki/api/v2/spaces?"); err == nil {
+		t.Error("resumePath() expected error for path outside prefix, got nil")
+	}
+}
+
+func TestWithLimit(t *testing.T) {
+	got := withLimit("/wiki/api/v2/pages?cursor=abc&limit=25", 7)
+	if !strings.Contains(got, "limit=7") || strings.Contains(got, "limit=25") {
+		t.Errorf("withLimit() = %q, want limit=7 only", got)
+	}
+	if !strings.Contains(got, "cursor=abc") {
+		t.Errorf("withLimit() = %q, lost cursor parameter", got)
+	}
+}
+
+func TestClient_ListPagesWithCursor_Resume(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+
+		start := 0
+		if r.URL.Query().Get("cursor") == "second" {
+			start = 3
+		}
+		var limit int
+		_, _ = fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limit)
+
+		var pages []Page
+		for i := start; i < start+limit && i < 5; i++ {
+			pages = append(pages, Page{ID: fmt.Sprintf("%d", i+1)})
+		}
+		resp := PageListResponse{Results: pages}
+		if start == 0 {
+			resp.Links.Next = "/wiki/api/v2/pages?space-id=space-1&cursor=second&limit=25"
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	first, next, err := client.ListPagesWithCursor(context.Background(), "space-1", 3, "", "")
+	if err != nil {
+		t.Fatalf("ListPagesWithCursor() error = %v", err)
+	}
+	if len(first) != 3 || next == "" {
+		t.Fatalf("first batch = %d pages, next = %q; want 3 pages and a cursor", len(first), next)
+	}
+
+	second, next, err := client.ListPagesWithCursor(context.Background(), "space-1", 10, "", next)
+	if err != nil {
+		t.Fatalf("ListPagesWithCursor() resume error = %v", err)
+	}
+	if len(second) != 2 || second[0].ID != "4" {
+		t.Errorf("second batch = %+v, want pages 4 and 5", second)
+	}
+	if next != "" {
+		t.Errorf("next = %q, want empty after last batch", next)
+	}
+	if !strings.Contains(queries[1], "limit=10") {
+		t.Errorf("resume query = %q, want limit rewritten to 10", queries[1])
+	}
+
+	if _, _, err := client.ListPagesWithCursor(context.Background(), "space-2", 10, "", newCursor(cursorKindPages, "space-1", "/wiki/api/v2/pages?cursor=x")); err == nil {
+		t.Error("ListPagesWithCursor() expected error for cursor from another space, got nil")
+	}
+}
+
+func TestClient_SearchWithCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		resp := SearchResponse{Results: []SearchResult{{Title: "A"}}}
+		if r.URL.Query().Get("cursor") == "" {
+			resp.Links.Next = "/rest/api/search?cql=type%3Dpage&cursor=raw123"
+		} else if r.URL.Query().Get("cursor") != "raw123" {
+			t.Errorf("cursor = %q, want %q", r.URL.Query().Get("cursor"), "raw123")
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, next, err := client.SearchWithCursor(context.Background(), "type=page", 1, "")
+	if err != nil {
+		t.Fatalf("SearchWithCursor() error = %v", err)
+	}
+	if next == "" || next == "raw123" {
+		t.Fatalf("next = %q, want an encoded cursor", next)
+	}
+
+	if _, _, err := client.SearchWithCursor(context.Background(), "type=blogpost", 1, next); err == nil {
+		t.Error("SearchWithCursor() expected error for cursor from another query, got nil")
+	}
+
+	_, next, err = client.SearchWithCursor(context.Background(), "type=page", 1, next)
+	if err != nil {
+		t.Fatalf("SearchWithCursor() resume error = %v", err)
+	}
+	if next != "" {
+		t.Errorf("next = %q, want empty after last batch", next)
+	}
+}
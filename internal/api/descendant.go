@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxDescendantDepth is the deepest a single descendants listing reaches
+const MaxDescendantDepth = 5
+
+// Descendant is a page, or other content such as a folder, below a page
+type Descendant struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	Type          string `json:"type"`
+	Status        string `json:"status,omitempty"`
+	ParentID      string `json:"parentId,omitempty"`
+	Depth         int    `json:"depth"`
+	ChildPosition int    `json:"childPosition"`
+}
+
+// GetDescendants lists the content below a page, down to depth levels
+// (1 to MaxDescendantDepth), and reports whether there is more than limit.
+// Depth is counted from 1 for the page's children.
+func (c *Client) GetDescendants(ctx context.Context, pageID string, depth, limit int) ([]Descendant, bool, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, false, fmt.Errorf("pageID cannot be empty")
+	}
+	if depth < 1 || depth > MaxDescendantDepth {
+		return nil, false, fmt.Errorf("depth must be between 1 and %d", MaxDescendantDepth)
+	}
+	path := fmt.Sprintf("/wiki/api/v2/pages/%s/descendants?depth=%d&limit=%d", url.PathEscape(pageID), depth, min(limit, maxPerPage))
+	return paginate[Descendant](ctx, c, path, limit, "get descendants")
+}
+
+// ListRootPages lists the top-level pages of a space, those with no parent
+// page, and reports whether there is more than limit
+func (c *Client) ListRootPages(ctx context.Context, spaceID string, limit int) ([]Page, bool, error) {
+	if strings.TrimSpace(spaceID) == "" {
+		return nil, false, fmt.Errorf("spaceID cannot be empty")
+	}
+	path := fmt.Sprintf("/wiki/api/v2/spaces/%s/pages?depth=root&limit=%d", url.PathEscape(spaceID), min(limit, maxPerPage))
+	return c.paginatePages(ctx, path, limit, "list root pages")
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_GetDescendants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/api/v2/pages/123/descendants" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("depth"); got != "5" {
+			t.Errorf("depth = %q, want 5", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(`{"results":[{"id":"1","title":"Install","type":"page","parentId":"123","depth":1,"childPosition":0}],"_links":{"next":"/wiki/api/v2/pages/123/descendants?depth=5&cursor=abc"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"results":[{"id":"2","title":"Notes","type":"folder","parentId":"1","depth":2,"childPosition":3}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	got, more, err := client.GetDescendants(context.Background(), "123", 5, 100)
+	if err != nil {
+		t.Fatalf("GetDescendants() error = %v", err)
+	}
+	if more || len(got) != 2 || got[1] != (Descendant{ID: "2", Title: "Notes", Type: "folder", ParentID: "1", Depth: 2, ChildPosition: 3}) {
+		t.Errorf("GetDescendants() = %+v, %v", got, more)
+	}
+
+	for _, depth := range []int{0, 6} {
+		if _, _, err := client.GetDescendants(context.Background(), "123", depth, 10); err == nil || !strings.Contains(err.Error(), "depth") {
+			t.Errorf("GetDescendants(depth %d) error = %v, want depth error", depth, err)
+		}
+	}
+}
+
+func TestClient_ListRootPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/api/v2/spaces/55/pages" || r.URL.Query().Get("depth") != "root" {
+			t.Errorf("URL = %q", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"id":"9","title":"Home"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	pages, more, err := client.ListRootPages(context.Background(), "55", 25)
+	if err != nil {
+		t.Fatalf("ListRootPages() error = %v", err)
+	}
+	if more || len(pages) != 1 || pages[0].Title != "Home" {
+		t.Errorf("ListRootPages() = %+v, %v", pages, more)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Label is a tag on a page
+type Label struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+type labelListResponseV1 struct {
+	Results []Label `json:"results"`
+}
+
+// AddLabels adds global labels to a page and returns all of its labels.
+// Labels the page already has are left as they are. The v2 API cannot add
+// labels, so this uses v1.
+func (c *Client) AddLabels(ctx context.Context, pageID string, labels []string) ([]Label, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, fmt.Errorf("pageID cannot be empty")
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("no labels to add")
+	}
+
+	body := make([]Label, 0, len(labels))
+	for _, name := range labels {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("label cannot be empty")
+		}
+		if strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("label %q cannot contain spaces", name)
+		}
+		body = append(body, Label{Prefix: "global", Name: name})
+	}
+
+	path := fmt.Sprintf("/wiki/rest/api/content/%s/label", url.PathEscape(pageID))
+	respBody, err := c.doRequest(ctx, "POST", path, body)
+	if err != nil {
+		return nil, fmt.Errorf("add labels request failed: %w", err)
+	}
+
+	var result labelListResponseV1
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse add labels response: %w", err)
+	}
+	return result.Results, nil
+}
+
+// maxPageLabels caps the labels read from one page
+const maxPageLabels = 500
+
+// GetLabels returns the labels on a page
+func (c *Client) GetLabels(ctx context.Context, pageID string) ([]Label, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, fmt.Errorf("pageID cannot be empty")
+	}
+	path := fmt.Sprintf("/wiki/api/v2/pages/%s/labels?limit=%d", url.PathEscape(pageID), maxPerPage)
+	labels, _, err := paginate[Label](ctx, c, path, maxPageLabels, "get labels")
+	return labels, err
+}
+
+// RemoveLabel removes a label from a page. The v2 API cannot remove labels,
+// so this uses v1.
+func (c *Client) RemoveLabel(ctx context.Context, pageID, label string) error {
+	if strings.TrimSpace(pageID) == "" {
+		return fmt.Errorf("pageID cannot be empty")
+	}
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return fmt.Errorf("label cannot be empty")
+	}
+
+	path := fmt.Sprintf("/wiki/rest/api/content/%s/label/%s", url.PathEscape(pageID), url.PathEscape(label))
+	if _, err := c.doRequest(ctx, "DELETE", path, nil); err != nil {
+		return fmt.Errorf("remove label request failed: %w", err)
+	}
+	return nil
+}
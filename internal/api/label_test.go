@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_AddLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Method = %s, want POST", r.Method)
+		}
+		if r.URL.Path != "/wiki/rest/api/content/123/label" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		var body []Label
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if len(body) != 2 || body[0] != (Label{Prefix: "global", Name: "howto"}) || body[1].Name != "ops" {
+			t.Errorf("body = %+v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"id":"1","name":"howto","prefix":"global"},{"id":"2","name":"ops","prefix":"global"},{"id":"3","name":"old","prefix":"global"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	labels, err := client.AddLabels(context.Background(), "123", []string{"howto", " ops "})
+	if err != nil {
+		t.Fatalf("AddLabels() error = %v", err)
+	}
+	if len(labels) != 3 || labels[2].Name != "old" {
+		t.Errorf("AddLabels() = %+v", labels)
+	}
+}
+
+func TestClient_AddLabels_Validation(t *testing.T) {
+	client, err := NewClient("https://example.atlassian.net", "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		pageID      string
+		labels      []string
+		errContains string
+	}{
+		{name: "empty page id", labels: []string{"a"}, errContains: "pageID cannot be empty"},
+		{name: "no labels", pageID: "1", errContains: "no labels to add"},
+		{name: "blank label", pageID: "1", labels: []string{" "}, errContains: "label cannot be empty"},
+		{name: "label with space", pageID: "1", labels: []string{"two words"}, errContains: "cannot contain spaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.AddLabels(context.Background(), tt.pageID, tt.labels)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("AddLabels() error = %v, want containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestClient_GetLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Method = %s, want GET", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			if r.URL.Path != "/wiki/api/v2/pages/123/labels" {
+				t.Errorf("Path = %q", r.URL.Path)
+			}
+			_, _ = w.Write([]byte(`{"results":[{"id":"1","name":"howto","prefix":"global"}],"_links":{"next":"/wiki/api/v2/pages/123/labels?cursor=abc"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"results":[{"id":"2","name":"ops","prefix":"global"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	labels, err := client.GetLabels(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("GetLabels() error = %v", err)
+	}
+	if len(labels) != 2 || labels[0].Name != "howto" || labels[1].Name != "ops" {
+		t.Errorf("GetLabels() = %+v", labels)
+	}
+
+	if _, err := client.GetLabels(context.Background(), " "); err == nil {
+		t.Error("GetLabels() with empty page ID: want error")
+	}
+}
+
+func TestClient_RemoveLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Method = %s, want DELETE", r.Method)
+		}
+		if r.URL.Path != "/wiki/rest/api/content/123/label/old" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if err := client.RemoveLabel(context.Background(), "123", " old "); err != nil {
+		t.Fatalf("RemoveLabel() error = %v", err)
+	}
+	if err := client.RemoveLabel(context.Background(), "123", " "); err == nil || !strings.Contains(err.Error(), "label cannot be empty") {
+		t.Errorf("RemoveLabel() with empty label: error = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ContentProperty is a key/value property stored on a page. Confluence
+// keeps page settings such as the title emoji and full-width appearance in
+// properties.
+type ContentProperty struct {
+	ID      string          `json:"id"`
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version *Version        `json:"version,omitempty"`
+}
+
+type contentPropertyListResponse struct {
+	Results []ContentProperty `json:"results"`
+}
+
+// contentPropertyWrite is the body for creating or updating a property
+type contentPropertyWrite struct {
+	Key     string   `json:"key"`
+	Value   any      `json:"value"`
+	Version *Version `json:"version,omitempty"`
+}
+
+// GetPageProperty returns the page's property with the given key, or nil
+// if the page does not have it.
+func (c *Client) GetPageProperty(ctx context.Context, pageID, key string) (*ContentProperty, error) {
+	if strings.TrimSpace(pageID) == "" {
+		return nil, fmt.Errorf("pageID cannot be empty")
+	}
+	if strings.TrimSpace(key) == "" {
+		return nil, fmt.Errorf("property key cannot be empty")
+	}
+
+	path := fmt.Sprintf("/wiki/api/v2/pages/%s/properties?key=%s", url.PathEscape(pageID), url.QueryEscape(key))
+	respBody, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get page property request failed: %w", err)
+	}
+
+	var result contentPropertyListResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse page properties response: %w", err)
+	}
+	for i := range result.Results {
+		if result.Results[i].Key == key {
+			return &result.Results[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SetPageProperty sets the page's property key to value, which is encoded
+// as JSON. The property is created if the page does not have it, and
+// otherwise updated to a new version.
+func (c *Client) SetPageProperty(ctx context.Context, pageID, key string, value any) (*ContentProperty, error) {
+	existing, err := c.GetPageProperty(ctx, pageID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	body := contentPropertyWrite{Key: key, Value: value}
+	method := "POST"
+	path := fmt.Sprintf("/wiki/api/v2/pages/%s/properties", url.PathEscape(pageID))
+	if existing != nil {
+		number := 1
+		if existing.Version != nil {
+			number = existing.Version.Number + 1
+		}
+		body.Version = &Version{Number: number}
+		method = "PUT"
+		path += "/" + url.PathEscape(existing.ID)
+	}
+
+	respBody, err := c.doRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, fmt.Errorf("set page property request failed: %w", err)
+	}
+
+	var result ContentProperty
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse page property response: %w", err)
+	}
+	return &result, nil
+}
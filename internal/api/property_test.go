@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_SetPageProperty(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   string
+		wantMethod string
+		wantPath   string
+		wantVer    int
+	}{
+		{
+			name:       "creates missing property",
+			existing:   `{"results":[]}`,
+			wantMethod: http.MethodPost,
+			wantPath:   "/wiki/api/v2/pages/123/properties",
+		},
+		{
+			name:       "updates existing property",
+			existing:   `{"results":[{"id":"77","key":"emoji-title-published","value":"1f680","version":{"number":2}}]}`,
+			wantMethod: http.MethodPut,
+			wantPath:   "/wiki/api/v2/pages/123/properties/77",
+			wantVer:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wrote bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodGet {
+					if r.URL.Path != "/wiki/api/v2/pages/123/properties" || r.URL.Query().Get("key") != "emoji-title-published" {
+						t.Errorf("GET %s?%s", r.URL.Path, r.URL.RawQuery)
+					}
+					_, _ = w.Write([]byte(tt.existing))
+					return
+				}
+				wrote = true
+				if r.Method != tt.wantMethod || r.URL.Path != tt.wantPath {
+					t.Errorf("%s %s, want %s %s", r.Method, r.URL.Path, tt.wantMethod, tt.wantPath)
+				}
+				var body contentPropertyWrite
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatalf("decode body: %v", err)
+				}
+				if body.Key != "emoji-title-published" || body.Value != "1f4d8" {
+					t.Errorf("body = %+v", body)
+				}
+				gotVer := 0
+				if body.Version != nil {
+					gotVer = body.Version.Number
+				}
+				if gotVer != tt.wantVer {
+					t.Errorf("version = %d, want %d", gotVer, tt.wantVer)
+				}
+				_, _ = w.Write([]byte(`{"id":"77","key":"emoji-title-published","value":"1f4d8"}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			prop, err := client.SetPageProperty(context.Background(), "123", "emoji-title-published", "1f4d8")
+			if err != nil {
+				t.Fatalf("SetPageProperty() error = %v", err)
+			}
+			if !wrote {
+				t.Error("property was not written")
+			}
+			if prop.ID != "77" || string(prop.Value) != `"1f4d8"` {
+				t.Errorf("SetPageProperty() = %+v", prop)
+			}
+		})
+	}
+}
+
+func TestClient_GetPageProperty_Validation(t *testing.T) {
+	client, err := NewClient("https://example.atlassian.net", "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		pageID      string
+		key         string
+		errContains string
+	}{
+		{name: "empty page id", key: "k", errContains: "pageID cannot be empty"},
+		{name: "empty key", pageID: "1", errContains: "property key cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetPageProperty(context.Background(), tt.pageID, tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("GetPageProperty() error = %v, want containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 // DefaultSearchLimit is the default maximum number of search results per request
@@ -22,12 +24,42 @@ type SearchSpace struct {
 	Name string `json:"name"`
 }
 
+// SearchUser represents a user reference in search results
+type SearchUser struct {
+	AccountID   string `json:"accountId,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// SearchVersion represents content version data, present when content.version is expanded
+type SearchVersion struct {
+	Number int        `json:"number"`
+	When   string     `json:"when,omitempty"`
+	By     SearchUser `json:"by"`
+}
+
+// SearchHistory represents content creation data, present when content.history is expanded
+type SearchHistory struct {
+	CreatedDate string     `json:"createdDate,omitempty"`
+	CreatedBy   SearchUser `json:"createdBy"`
+}
+
+// SearchContainer represents the parent of a comment or attachment,
+// present when content.container is expanded
+type SearchContainer struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+}
+
 // SearchContent represents the nested content object in search results
 type SearchContent struct {
-	ID     string      `json:"id"`
-	Type   string      `json:"type"`
-	Status string      `json:"status"`
-	Space  SearchSpace `json:"space"`
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	Status    string           `json:"status"`
+	Space     SearchSpace      `json:"space"`
+	Version   *SearchVersion   `json:"version,omitempty"`
+	History   *SearchHistory   `json:"history,omitempty"`
+	Container *SearchContainer `json:"container,omitempty"`
 }
 
 // SearchResult represents a single search result from the v1 API
@@ -218,12 +250,64 @@ func BuildCQL(params SearchParams) (string, error) {
 	return strings.Join(conditions, " and "), nil
 }
 
+// defaultSearchExpand is the expansion used by Search
+var defaultSearchExpand = []string{"content.space"}
+
+// activityContentTypes are the content types reported by BuildActivityCQL
+var activityContentTypes = []string{"page", "blogpost", "comment", "attachment"}
+
+// BuildActivityCQL constructs a CQL query for content in a space modified within
+// the last since duration, newest first. CQL's now() function is used so the
+// window is evaluated server-side, independent of the user's timezone setting.
+func BuildActivityCQL(spaceKey string, since time.Duration) (string, error) {
+	if spaceKey == "" {
+		return "", fmt.Errorf("space key cannot be empty")
+	}
+	if err := validateSpaceKey(spaceKey); err != nil {
+		return "", fmt.Errorf("invalid space key: %w", err)
+	}
+	if since <= 0 {
+		return "", fmt.Errorf("since must be greater than 0")
+	}
+
+	minutes := int64(math.Ceil(since.Minutes()))
+
+	// SECURITY NOTE: the space key is safe unescaped because validateSpaceKey enforces
+	// the same strict pattern used by BuildCQL, and the type list is a fixed allowlist.
+	return fmt.Sprintf(`space = "%s" and type in (%s) and lastmodified >= now("-%dm") order by lastmodified desc`,
+		spaceKey, strings.Join(activityContentTypes, ", "), minutes), nil
+}
+
 // Search performs a CQL search using the v1 API.
 // The cql parameter should be the complete CQL query string (not URL-encoded).
 // The limit parameter controls the maximum number of results per page.
 // The cursor parameter is used for pagination (empty string for first page).
 // Returns the search response, the next cursor (empty if no more results), and an error.
 func (c *Client) Search(ctx context.Context, cql string, limit int, cursor string) (*SearchResponse, string, error) {
+	return c.SearchWithExpand(ctx, cql, limit, cursor, defaultSearchExpand)
+}
+
+// SearchWithExpand is Search with a caller-chosen list of expansions,
+// e.g. "content.version" to include version author and date.
+func (c *Client) SearchWithExpand(ctx context.Context, cql string, limit int, cursor string, expand []string) (*SearchResponse, string, error) {
+	return c.search(ctx, cql, limit, 0, cursor, expand)
+}
+
+// SearchFrom is SearchWithCursor starting at the result at offset start,
+// counting from 0, instead of at a cursor. The returned cursor continues
+// after the last result returned.
+func (c *Client) SearchFrom(ctx context.Context, cql string, limit, start int) (*SearchResponse, Cursor, error) {
+	if start < 0 {
+		return nil, "", fmt.Errorf("start cannot be negative")
+	}
+	result, next, err := c.search(ctx, cql, limit, start, "", defaultSearchExpand)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, newCursor(cursorKindSearch, cql, next), nil
+}
+
+func (c *Client) search(ctx context.Context, cql string, limit, start int, cursor string, expand []string) (*SearchResponse, string, error) {
 	if strings.TrimSpace(cql) == "" {
 		return nil, "", fmt.Errorf("cql query cannot be empty")
 	}
@@ -239,8 +323,13 @@ func (c *Client) Search(ctx context.Context, cql string, limit int, cursor strin
 	if cursor != "" {
 		params.Set("cursor", cursor)
 	}
+	if start > 0 {
+		params.Set("start", fmt.Sprintf("%d", start))
+	}
 	params.Set("excerpt", "highlight")
-	params.Set("expand", "content.space")
+	if len(expand) > 0 {
+		params.Set("expand", strings.Join(expand, ","))
+	}
 
 	path := "/wiki/rest/api/search?" + params.Encode()
 
@@ -260,6 +349,21 @@ func (c *Client) Search(ctx context.Context, cql string, limit int, cursor strin
 	return &result, nextCursor, nil
 }
 
+// SearchWithCursor is Search using a typed Cursor. The returned cursor resumes
+// the same CQL query and is "" when there are no more results.
+func (c *Client) SearchWithCursor(ctx context.Context, cql string, limit int, cursor Cursor) (*SearchResponse, Cursor, error) {
+	raw, err := cursor.decode(cursorKindSearch, cql)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, next, err := c.Search(ctx, cql, limit, raw)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, newCursor(cursorKindSearch, cql, next), nil
+}
+
 // extractCursorFromLink parses the cursor parameter from a _links.next URL.
 // Returns empty string if no cursor is found.
 func extractCursorFromLink(nextLink string) string {
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildCQL(t *testing.T) {
@@ -1090,3 +1091,110 @@ func TestValidateContentType(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SearchWithExpand(t *testing.T) {
+	var gotExpand string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotExpand = r.URL.Query().Get("expand")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"title":"Doc","content":{"id":"1","type":"page",` +
+			`"version":{"number":3,"when":"2026-10-14T09:00:00.000Z","by":{"displayName":"Ann"}},` +
+			`"container":{"id":"9","type":"space","title":"Docs"}}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	resp, _, err := client.SearchWithExpand(context.Background(), "type=page", 10, "", []string{"content.space", "content.version"})
+	if err != nil {
+		t.Fatalf("SearchWithExpand() error = %v", err)
+	}
+	if gotExpand != "content.space,content.version" {
+		t.Errorf("expand = %q, want %q", gotExpand, "content.space,content.version")
+	}
+	v := resp.Results[0].Content.Version
+	if v == nil || v.Number != 3 || v.By.DisplayName != "Ann" {
+		t.Errorf("Version = %+v, want number 3 by Ann", v)
+	}
+	if c := resp.Results[0].Content.Container; c == nil || c.Title != "Docs" {
+		t.Errorf("Container = %+v, want title Docs", c)
+	}
+}
+
+func TestClient_SearchFrom(t *testing.T) {
+	var gotStart, gotCursor string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotStart, gotCursor = r.URL.Query().Get("start"), r.URL.Query().Get("cursor")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"title":"Doc"}],"_links":{"next":"/rest/api/search?cursor=abc"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	resp, next, err := client.SearchFrom(context.Background(), "type=page", 10, 50)
+	if err != nil {
+		t.Fatalf("SearchFrom() error = %v", err)
+	}
+	if gotStart != "50" || gotCursor != "" {
+		t.Errorf("start = %q, cursor = %q, want start 50 and no cursor", gotStart, gotCursor)
+	}
+	if len(resp.Results) != 1 || next == "" {
+		t.Errorf("results = %d, next = %q, want one result and a next cursor", len(resp.Results), next)
+	}
+
+	if _, _, err := client.SearchWithCursor(context.Background(), "type=page", 10, next); err != nil || gotCursor != "abc" || gotStart != "" {
+		t.Errorf("resumed search: err = %v, cursor = %q, start = %q, want cursor abc", err, gotCursor, gotStart)
+	}
+
+	if _, _, err := client.SearchFrom(context.Background(), "type=page", 10, -1); err == nil {
+		t.Error("SearchFrom() with negative start succeeded, want error")
+	}
+}
+
+func TestBuildActivityCQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		spaceKey    string
+		since       time.Duration
+		want        string
+		errContains string
+	}{
+		{
+			name:     "one day",
+			spaceKey: "DOCS",
+			since:    24 * time.Hour,
+			want:     `space = "DOCS" and type in (page, blogpost, comment, attachment) and lastmodified >= now("-1440m") order by lastmodified desc`,
+		},
+		{
+			name:     "partial minute rounds up",
+			spaceKey: "~jdoe",
+			since:    90 * time.Second,
+			want:     `space = "~jdoe" and type in (page, blogpost, comment, attachment) and lastmodified >= now("-2m") order by lastmodified desc`,
+		},
+		{name: "empty space", since: time.Hour, errContains: "space key cannot be empty"},
+		{name: "injection attempt", spaceKey: `DOCS" or space = "X`, since: time.Hour, errContains: "invalid space key"},
+		{name: "zero duration", spaceKey: "DOCS", errContains: "since must be greater than 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildActivityCQL(tt.spaceKey, tt.since)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("BuildActivityCQL() error = %v, want containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildActivityCQL() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildActivityCQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
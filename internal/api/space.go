@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SpaceUpdateRequest holds the space fields to change. Nil fields are left unchanged.
+type SpaceUpdateRequest struct {
+	Name        *string
+	Description *string
+	HomepageID  *string
+}
+
+// spaceUpdateBodyV1 is the v1 request body for updating a space.
+// The v2 API does not support space updates.
+type spaceUpdateBodyV1 struct {
+	Name        string              `json:"name,omitempty"`
+	Description *spaceDescriptionV1 `json:"description,omitempty"`
+	Homepage    *spaceHomepageRefV1 `json:"homepage,omitempty"`
+}
+
+type spaceDescriptionV1 struct {
+	Plain plainValueV1 `json:"plain"`
+}
+
+type plainValueV1 struct {
+	Value          string `json:"value"`
+	Representation string `json:"representation"`
+}
+
+type spaceHomepageRefV1 struct {
+	ID string `json:"id"`
+}
+
+// spaceV1 is the v1 representation of a space. Unlike v2, its ID is numeric.
+type spaceV1 struct {
+	ID     int64  `json:"id"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// UpdateSpace changes a space's name, description, or homepage using the v1 API.
+func (c *Client) UpdateSpace(ctx context.Context, spaceKey string, req *SpaceUpdateRequest) (*Space, error) {
+	if strings.TrimSpace(spaceKey) == "" {
+		return nil, fmt.Errorf("spaceKey cannot be empty")
+	}
+	if req == nil || (req.Name == nil && req.Description == nil && req.HomepageID == nil) {
+		return nil, fmt.Errorf("no space fields to update")
+	}
+
+	var body spaceUpdateBodyV1
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, fmt.Errorf("space name cannot be empty")
+		}
+		body.Name = *req.Name
+	}
+	if req.Description != nil {
+		body.Description = &spaceDescriptionV1{
+			Plain: plainValueV1{Value: *req.Description, Representation: "plain"},
+		}
+	}
+	if req.HomepageID != nil {
+		if strings.TrimSpace(*req.HomepageID) == "" {
+			return nil, fmt.Errorf("homepage ID cannot be empty")
+		}
+		body.Homepage = &spaceHomepageRefV1{ID: *req.HomepageID}
+	}
+
+	respBody, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/wiki/rest/api/space/%s", spaceKey), body)
+	if err != nil {
+		return nil, fmt.Errorf("update space request failed: %w", err)
+	}
+
+	var result spaceV1
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse update space response: %w", err)
+	}
+
+	return &Space{
+		ID:     strconv.FormatInt(result.ID, 10),
+		Key:    result.Key,
+		Name:   result.Name,
+		Type:   result.Type,
+		Status: result.Status,
+	}, nil
+}
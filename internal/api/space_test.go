@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestClient_UpdateSpace(t *testing.T) {
+	tests := []struct {
+		name        string
+		spaceKey    string
+		req         *SpaceUpdateRequest
+		statusCode  int
+		wantBody    []string
+		notInBody   []string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:       "all fields",
+			spaceKey:   "DOCS",
+			req:        &SpaceUpdateRequest{Name: strPtr("Docs"), Description: strPtr("Team docs"), HomepageID: strPtr("42")},
+			statusCode: http.StatusOK,
+			wantBody:   []string{`"name":"Docs"`, `"plain":{"value":"Team docs","representation":"plain"}`, `"homepage":{"id":"42"}`},
+		},
+		{
+			name:       "description only",
+			spaceKey:   "DOCS",
+			req:        &SpaceUpdateRequest{Description: strPtr("")},
+			statusCode: http.StatusOK,
+			wantBody:   []string{`"description"`},
+			notInBody:  []string{`"name"`, `"homepage"`},
+		},
+		{
+			name:        "empty space key",
+			spaceKey:    "",
+			req:         &SpaceUpdateRequest{Name: strPtr("x")},
+			wantErr:     true,
+			errContains: "spaceKey cannot be empty",
+		},
+		{
+			name:        "no fields",
+			spaceKey:    "DOCS",
+			req:         &SpaceUpdateRequest{},
+			wantErr:     true,
+			errContains: "no space fields to update",
+		},
+		{
+			name:        "blank name",
+			spaceKey:    "DOCS",
+			req:         &SpaceUpdateRequest{Name: strPtr("  ")},
+			wantErr:     true,
+			errContains: "space name cannot be empty",
+		},
+		{
+			name:        "API error",
+			spaceKey:    "DOCS",
+			req:         &SpaceUpdateRequest{Name: strPtr("Docs")},
+			statusCode:  http.StatusForbidden,
+			wantErr:     true,
+			errContains: "API error (status 403)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut || r.URL.Path != "/wiki/rest/api/space/"+tt.spaceKey {
+					t.Errorf("request = %s %s, want PUT /wiki/rest/api/space/%s", r.Method, r.URL.Path, tt.spaceKey)
+				}
+				body, _ := io.ReadAll(r.Body)
+				for _, want := range tt.wantBody {
+					if !strings.Contains(string(body), want) {
+						t.Errorf("body %s missing %s", body, want)
+					}
+				}
+				for _, unwanted := range tt.notInBody {
+					if strings.Contains(string(body), unwanted) {
+						t.Errorf("body %s unexpectedly contains %s", body, unwanted)
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": 98304, "key": tt.spaceKey, "name": "Docs", "type": "global"})
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			space, err := client.UpdateSpace(context.Background(), tt.spaceKey, tt.req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateSpace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("UpdateSpace() error = %q, want containing %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if space.ID != "98304" || space.Key != tt.spaceKey {
+				t.Errorf("UpdateSpace() = %+v, want ID 98304 and key %s", space, tt.spaceKey)
+			}
+		})
+	}
+}
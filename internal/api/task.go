@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Task status values accepted by the tasks endpoints
+const (
+	TaskStatusComplete   = "complete"
+	TaskStatusIncomplete = "incomplete"
+)
+
+// Task represents an inline task (action item) embedded in a page or blog post
+type Task struct {
+	ID          string       `json:"id"`
+	LocalID     string       `json:"localId,omitempty"`
+	SpaceID     string       `json:"spaceId,omitempty"`
+	PageID      string       `json:"pageId,omitempty"`
+	BlogPostID  string       `json:"blogPostId,omitempty"`
+	Status      string       `json:"status"`
+	Body        *PageBodyGet `json:"body,omitempty"`
+	CreatedBy   string       `json:"createdBy,omitempty"`
+	AssignedTo  string       `json:"assignedTo,omitempty"`
+	CompletedBy string       `json:"completedBy,omitempty"`
+	CreatedAt   string       `json:"createdAt,omitempty"`
+	UpdatedAt   string       `json:"updatedAt,omitempty"`
+	DueAt       string       `json:"dueAt,omitempty"`
+	CompletedAt string       `json:"completedAt,omitempty"`
+}
+
+// TaskListParams holds the filters for listing tasks.
+// Empty fields are not sent to the API.
+type TaskListParams struct {
+	AssignedTo string // Account ID of the assignee
+	Status     string // complete or incomplete
+	SpaceID    string
+	PageID     string
+}
+
+// taskUpdateRequest is the body for updating a task's status
+type taskUpdateRequest struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// validateTaskStatus checks that status is one of the values the API accepts.
+func validateTaskStatus(status string) error {
+	if status != TaskStatusComplete && status != TaskStatusIncomplete {
+		return fmt.Errorf("invalid task status: %s (valid: %s, %s)", status, TaskStatusComplete, TaskStatusIncomplete)
+	}
+	return nil
+}
+
+// ListTasks returns tasks matching params, fetching up to limit results.
+// The second return value reports whether more results are available.
+func (c *Client) ListTasks(ctx context.Context, params TaskListParams, limit int) ([]Task, bool, error) {
+	tasks, _, hasMore, err := c.listTasks(ctx, params, limit, "")
+	return tasks, hasMore, err
+}
+
+// ListTasksWithCursor is ListTasks starting at cursor. It returns the cursor
+// for the next batch, or "" when there are no more tasks. A cursor only
+// resumes a listing with the same params.
+func (c *Client) ListTasksWithCursor(ctx context.Context, params TaskListParams, limit int, cursor Cursor) ([]Task, Cursor, error) {
+	tasks, next, _, err := c.listTasks(ctx, params, limit, cursor)
+	return tasks, next, err
+}
+
+func (c *Client) listTasks(ctx context.Context, params TaskListParams, limit int, cursor Cursor) ([]Task, Cursor, bool, error) {
+	if params.Status != "" {
+		if err := validateTaskStatus(params.Status); err != nil {
+			return nil, "", false, err
+		}
+	}
+
+	query := url.Values{}
+	query.Set("body-format", "storage")
+	if params.AssignedTo != "" {
+		query.Set("assigned-to", params.AssignedTo)
+	}
+	if params.Status != "" {
+		query.Set("status", params.Status)
+	}
+	if params.SpaceID != "" {
+		query.Set("space-id", params.SpaceID)
+	}
+	if params.PageID != "" {
+		query.Set("page-id", params.PageID)
+	}
+
+	scope := query.Encode()
+	path, err := cursor.resumePath(cursorKindTasks, scope, "/wiki/api/v2/tasks?")
+	if err != nil {
+		return nil, "", false, err
+	}
+	if path == "" {
+		query.Set("limit", fmt.Sprintf("%d", min(limit, maxPerPage)))
+		path = "/wiki/api/v2/tasks?" + query.Encode()
+	}
+
+	tasks, next, hasMore, err := paginateFrom[Task](ctx, c, path, limit, "list tasks")
+	if err != nil {
+		return nil, "", false, err
+	}
+	return tasks, newCursor(cursorKindTasks, scope, next), hasMore, nil
+}
+
+// UpdateTaskStatus marks a task complete or incomplete.
+func (c *Client) UpdateTaskStatus(ctx context.Context, taskID, status string) (*Task, error) {
+	if strings.TrimSpace(taskID) == "" {
+		return nil, fmt.Errorf("taskID cannot be empty")
+	}
+	if err := validateTaskStatus(status); err != nil {
+		return nil, err
+	}
+
+	req := &taskUpdateRequest{ID: taskID, Status: status}
+	respBody, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/wiki/api/v2/tasks/%s", taskID), req)
+	if err != nil {
+		return nil, fmt.Errorf("update task request failed: %w", err)
+	}
+
+	var result Task
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse update task response: %w", err)
+	}
+
+	return &result, nil
+}
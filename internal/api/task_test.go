@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_ListTasks(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      TaskListParams
+		limit       int
+		wantQuery   []string
+		wantCount   int
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:      "assigned and status filters",
+			params:    TaskListParams{AssignedTo: "acc-1", Status: TaskStatusIncomplete},
+			limit:     10,
+			wantQuery: []string{"assigned-to=acc-1", "status=incomplete", "limit=10", "body-format=storage"},
+			wantCount: 2,
+		},
+		{
+			name:      "space and page filters",
+			params:    TaskListParams{SpaceID: "space-1", PageID: "123"},
+			limit:     50,
+			wantQuery: []string{"space-id=space-1", "page-id=123", "limit=25"},
+			wantCount: 2,
+		},
+		{
+			name:        "invalid status",
+			params:      TaskListParams{Status: "done"},
+			limit:       10,
+			wantErr:     true,
+			errContains: "invalid task status",
+		},
+		{
+			name:        "zero limit",
+			limit:       0,
+			wantErr:     true,
+			errContains: "limit must be greater than 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/wiki/api/v2/tasks" {
+					t.Errorf("path = %q, want /wiki/api/v2/tasks", r.URL.Path)
+				}
+				for _, want := range tt.wantQuery {
+					if !strings.Contains(r.URL.RawQuery, want) {
+						t.Errorf("query %q missing %q", r.URL.RawQuery, want)
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(listResponse[Task]{
+					Results: []Task{
+						{ID: "1", Status: "incomplete", PageID: "123"},
+						{ID: "2", Status: "incomplete", PageID: "456"},
+					},
+				})
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			tasks, _, err := client.ListTasks(context.Background(), tt.params, tt.limit)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("ListTasks() error = %q, want containing %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if len(tasks) != tt.wantCount {
+				t.Errorf("ListTasks() returned %d tasks, want %d", len(tasks), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateTaskStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		taskID      string
+		status      string
+		statusCode  int
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:       "mark complete",
+			taskID:     "42",
+			status:     TaskStatusComplete,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "mark incomplete",
+			taskID:     "42",
+			status:     TaskStatusIncomplete,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "empty task ID",
+			taskID:      " ",
+			status:      TaskStatusComplete,
+			wantErr:     true,
+			errContains: "taskID cannot be empty",
+		},
+		{
+			name:        "invalid status",
+			taskID:      "42",
+			status:      "finished",
+			wantErr:     true,
+			errContains: "invalid task status",
+		},
+		{
+			name:        "API error",
+			taskID:      "42",
+			status:      TaskStatusComplete,
+			statusCode:  http.StatusNotFound,
+			wantErr:     true,
+			errContains: "API error (status 404)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("method = %s, want PUT", r.Method)
+				}
+				if r.URL.Path != "/wiki/api/v2/tasks/"+tt.taskID {
+					t.Errorf("path = %q, want /wiki/api/v2/tasks/%s", r.URL.Path, tt.taskID)
+				}
+				body, _ := io.ReadAll(r.Body)
+				var req taskUpdateRequest
+				if err := json.Unmarshal(body, &req); err != nil {
+					t.Fatalf("unmarshal request: %v", err)
+				}
+				if req.ID != tt.taskID || req.Status != tt.status {
+					t.Errorf("request = %+v, want id=%s status=%s", req, tt.taskID, tt.status)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				_ = json.NewEncoder(w).Encode(Task{ID: tt.taskID, Status: tt.status})
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test@example.com", "token")
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			task, err := client.UpdateTaskStatus(context.Background(), tt.taskID, tt.status)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateTaskStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("UpdateTaskStatus() error = %q, want containing %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if task.Status != tt.status {
+				t.Errorf("UpdateTaskStatus() status = %q, want %q", task.Status, tt.status)
+			}
+		})
+	}
+}
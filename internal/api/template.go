@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ContentTemplate is a page template kept in Confluence, in a space or
+// global to the site
+type ContentTemplate struct {
+	TemplateID   string                `json:"templateId"`
+	Name         string                `json:"name"`
+	Description  string                `json:"description,omitempty"`
+	TemplateType string                `json:"templateType,omitempty"`
+	Space        *ContentTemplateSpace `json:"space,omitempty"`
+	Labels       []Label               `json:"labels,omitempty"`
+	Body         *ContentTemplateBody  `json:"body,omitempty"`
+}
+
+// ContentTemplateSpace is the space a template belongs to
+type ContentTemplateSpace struct {
+	Key string `json:"key"`
+}
+
+// ContentTemplateBody holds a template's content in storage format
+type ContentTemplateBody struct {
+	Storage *BodyContent `json:"storage,omitempty"`
+}
+
+type contentTemplateListResponseV1 struct {
+	Results []ContentTemplate `json:"results"`
+}
+
+// ListContentTemplates returns up to limit page templates of the space with
+// key spaceKey, or the global templates if spaceKey is empty. The v2 API has
+// no templates, so this uses v1.
+func (c *Client) ListContentTemplates(ctx context.Context, spaceKey string, limit int) ([]ContentTemplate, error) {
+	params := url.Values{}
+	if spaceKey != "" {
+		params.Set("spaceKey", spaceKey)
+	}
+	if limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
+	path := "/wiki/rest/api/template/page"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	respBody, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list templates request failed: %w", err)
+	}
+
+	var result contentTemplateListResponseV1
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse list templates response: %w", err)
+	}
+	return result.Results, nil
+}
+
+// GetContentTemplate returns a page template with its storage format body.
+func (c *Client) GetContentTemplate(ctx context.Context, templateID string) (*ContentTemplate, error) {
+	if strings.TrimSpace(templateID) == "" {
+		return nil, fmt.Errorf("templateID cannot be empty")
+	}
+
+	respBody, err := c.doRequest(ctx, "GET", fmt.Sprintf("/wiki/rest/api/template/%s", url.PathEscape(templateID)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get template request failed: %w", err)
+	}
+
+	var result ContentTemplate
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse get template response: %w", err)
+	}
+	return &result, nil
+}
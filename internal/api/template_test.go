@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListContentTemplates(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/template/page" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"templateId":"98305","name":"Meeting notes","description":"Weekly sync","templateType":"page","space":{"key":"DOCS"}}],"size":1}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	templates, err := client.ListContentTemplates(context.Background(), "DOCS", 50)
+	if err != nil {
+		t.Fatalf("ListContentTemplates() error = %v", err)
+	}
+	if len(templates) != 1 || templates[0].TemplateID != "98305" || templates[0].Name != "Meeting notes" || templates[0].Space.Key != "DOCS" {
+		t.Errorf("ListContentTemplates() = %+v", templates)
+	}
+
+	if _, err := client.ListContentTemplates(context.Background(), "", 0); err != nil {
+		t.Fatalf("ListContentTemplates() global error = %v", err)
+	}
+	if len(queries) != 2 || queries[0] != "limit=50&spaceKey=DOCS" || queries[1] != "" {
+		t.Errorf("queries = %q", queries)
+	}
+}
+
+func TestClient_GetContentTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/template/98305" {
+			t.Errorf("Path = %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"templateId":"98305","name":"Meeting notes","body":{"storage":{"value":"<h1>Attendees</h1>","representation":"storage"}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	template, err := client.GetContentTemplate(context.Background(), "98305")
+	if err != nil {
+		t.Fatalf("GetContentTemplate() error = %v", err)
+	}
+	if template.Body == nil || template.Body.Storage == nil || template.Body.Storage.Value != "<h1>Attendees</h1>" {
+		t.Errorf("GetContentTemplate() = %+v", template)
+	}
+
+	if _, err := client.GetContentTemplate(context.Background(), " "); err == nil {
+		t.Error("GetContentTemplate() with an empty ID succeeded")
+	}
+}
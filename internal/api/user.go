@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// User represents a Confluence user as returned by the v1 user endpoints
+type User struct {
+	AccountID   string `json:"accountId"`
+	AccountType string `json:"accountType,omitempty"`
+	Email       string `json:"email,omitempty"`
+	PublicName  string `json:"publicName,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// GetCurrentUser returns the user the client is authenticated as.
+// The v2 API has no equivalent endpoint, so this uses the v1 API.
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	respBody, err := c.doRequest(ctx, "GET", "/wiki/rest/api/user/current", nil)
+	if err != nil {
+		return nil, fmt.Errorf("get current user request failed: %w", err)
+	}
+
+	var user User
+	if err := json.Unmarshal(respBody, &user); err != nil {
+		return nil, fmt.Errorf("failed to parse get current user response: %w", err)
+	}
+
+	return &user, nil
+}
+
+// userSearchResponse is the v1 user search response
+type userSearchResponse struct {
+	Results []struct {
+		User User `json:"user"`
+	} `json:"results"`
+}
+
+// SearchUsers returns up to limit users whose full name matches query, using
+// the v1 user search. Email addresses are only returned for users whose
+// profile visibility allows it.
+func (c *Client) SearchUsers(ctx context.Context, query string, limit int) ([]User, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("user search query cannot be empty")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	params := url.Values{}
+	params.Set("cql", fmt.Sprintf(`user.fullname ~ "%s"`, escapeCQLString(query)))
+	params.Set("limit", strconv.Itoa(limit))
+	respBody, err := c.doRequest(ctx, "GET", "/wiki/rest/api/search/user?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("user search request failed: %w", err)
+	}
+
+	var result userSearchResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse user search response: %w", err)
+	}
+	users := make([]User, 0, len(result.Results))
+	for _, r := range result.Results {
+		users = append(users, r.User)
+	}
+	return users, nil
+}
+
+// GetUser returns the user with the given account ID, using the v1 API.
+func (c *Client) GetUser(ctx context.Context, accountID string) (*User, error) {
+	if strings.TrimSpace(accountID) == "" {
+		return nil, fmt.Errorf("accountID cannot be empty")
+	}
+
+	respBody, err := c.doRequest(ctx, "GET", "/wiki/rest/api/user?accountId="+url.QueryEscape(accountID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get user request failed: %w", err)
+	}
+
+	var user User
+	if err := json.Unmarshal(respBody, &user); err != nil {
+		return nil, fmt.Errorf("failed to parse get user response: %w", err)
+	}
+
+	return &user, nil
+}
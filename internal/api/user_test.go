@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_GetCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/user/current" {
+			t.Errorf("path = %q, want /wiki/rest/api/user/current", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(User{AccountID: "acc-1", DisplayName: "Test User"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	user, err := client.GetCurrentUser(context.Background())
+	if err != nil {
+		t.Fatalf("GetCurrentUser() error = %v", err)
+	}
+	if user.AccountID != "acc-1" {
+		t.Errorf("AccountID = %q, want %q", user.AccountID, "acc-1")
+	}
+}
+
+func TestClient_GetUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/user" || r.URL.Query().Get("accountId") != "acc:1" {
+			t.Errorf("GET %s?%s, want /wiki/rest/api/user?accountId=acc:1", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(User{AccountID: "acc:1", DisplayName: "Jane Doe"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	user, err := client.GetUser(context.Background(), "acc:1")
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if user.DisplayName != "Jane Doe" {
+		t.Errorf("DisplayName = %q, want %q", user.DisplayName, "Jane Doe")
+	}
+
+	if _, err := client.GetUser(context.Background(), " "); err == nil {
+		t.Error("GetUser() with empty account ID succeeded")
+	}
+}
+
+func TestClient_SearchUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/search/user" {
+			t.Errorf("path = %q, want /wiki/rest/api/search/user", r.URL.Path)
+		}
+		if got, want := r.URL.Query().Get("cql"), `user.fullname ~ "Jane Doe\-Smith"`; got != want {
+			t.Errorf("cql = %q, want %q", got, want)
+		}
+		if got := r.URL.Query().Get("limit"); got != "10" {
+			t.Errorf("limit = %q, want 10", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"user":{"accountId":"acc-1","displayName":"Jane Doe-Smith"}},{"user":{"accountId":"acc-2","displayName":"Jane Doe-Smithers"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	users, err := client.SearchUsers(context.Background(), "Jane Doe-Smith", 10)
+	if err != nil {
+		t.Fatalf("SearchUsers() error = %v", err)
+	}
+	if len(users) != 2 || users[0].AccountID != "acc-1" || users[1].DisplayName != "Jane Doe-Smithers" {
+		t.Errorf("users = %+v", users)
+	}
+
+	if _, err := client.SearchUsers(context.Background(), " ", 10); err == nil {
+		t.Error("SearchUsers() with empty query: want error")
+	}
+}
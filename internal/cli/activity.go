@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	activitySpace string
+	activitySince string
+	activityLimit int
+)
+
+// activityExpand requests the version, history, and container data the digest needs
+var activityExpand = []string{"content.space", "content.version", "content.history", "content.container"}
+
+// activityItem is a single entry in the activity digest
+type activityItem struct {
+	Title   string `json:"title"`
+	Type    string `json:"type"`
+	Author  string `json:"author,omitempty"`
+	When    string `json:"when,omitempty"`
+	Version int    `json:"version,omitempty"`
+	On      string `json:"on,omitempty"` // Container title for comments and attachments
+	URL     string `json:"url,omitempty"`
+}
+
+// activityDigest groups recent activity in a space by kind
+type activityDigest struct {
+	Space       string         `json:"space"`
+	Since       string         `json:"since"`
+	Created     []activityItem `json:"created"`
+	Edited      []activityItem `json:"edited"`
+	Comments    []activityItem `json:"comments"`
+	Attachments []activityItem `json:"attachments"`
+	Truncated   bool           `json:"truncated"`
+}
+
+// parseSince parses a look-back window such as 30m, 24h, 7d, or 2w.
+// Days and weeks are accepted in addition to Go duration units.
+func parseSince(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(s, "d"), strings.HasSuffix(s, "w"):
+		unit := 24 * time.Hour
+		if strings.HasSuffix(s, "w") {
+			unit *= 7
+		}
+		var n int
+		n, err = strconv.Atoi(s[:len(s)-1])
+		d = time.Duration(n) * unit
+	default:
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --since value '%s' (examples: 30m, 24h, 7d, 2w)", s)
+	}
+	return d, nil
+}
+
+// absoluteURL resolves a search result URL against the base URL.
+// Returns an empty string for URLs that are neither absolute nor root-relative.
+func absoluteURL(baseURL, u string) string {
+	switch {
+	case strings.HasPrefix(u, "http://"), strings.HasPrefix(u, "https://"):
+		return u
+	case strings.HasPrefix(u, "/"):
+		return strings.TrimRight(baseURL, "/") + u
+	default:
+		return ""
+	}
+}
+
+// buildActivityDigest sorts search results into the digest's groups.
+func buildActivityDigest(spaceKey string, since time.Time, baseURL string, results []api.SearchResult) activityDigest {
+	digest := activityDigest{
+		Space:       spaceKey,
+		Since:       since.Format(time.RFC3339),
+		Created:     []activityItem{},
+		Edited:      []activityItem{},
+		Comments:    []activityItem{},
+		Attachments: []activityItem{},
+	}
+
+	for _, r := range results {
+		item := activityItem{
+			Title: r.Title,
+			Type:  r.Content.Type,
+			When:  r.LastModified,
+			URL:   absoluteURL(baseURL, r.URL),
+		}
+		if v := r.Content.Version; v != nil {
+			item.Version = v.Number
+			item.Author = v.By.DisplayName
+			if v.When != "" {
+				item.When = v.When
+			}
+		}
+		if c := r.Content.Container; c != nil {
+			item.On = c.Title
+		}
+
+		switch r.Content.Type {
+		case "comment":
+			digest.Comments = append(digest.Comments, item)
+		case "attachment":
+			digest.Attachments = append(digest.Attachments, item)
+		default:
+			if createdSince(r.Content, since) {
+				digest.Created = append(digest.Created, item)
+			} else {
+				digest.Edited = append(digest.Edited, item)
+			}
+		}
+	}
+
+	return digest
+}
+
+// createdSince reports whether content was created at or after since.
+// A page created and then edited inside the window still counts as created.
+// Falls back to the version number when the creation date is unavailable.
+func createdSince(c api.SearchContent, since time.Time) bool {
+	if c.History != nil && c.History.CreatedDate != "" {
+		if created, err := time.Parse(time.RFC3339, c.History.CreatedDate); err == nil {
+			return !created.Before(since)
+		}
+	}
+	return c.Version != nil && c.Version.Number == 1
+}
+
+// printActivityDigest renders the digest as plain-text Markdown suitable for
+// pasting into chat or email.
+func printActivityDigest(out io.Writer, digest activityDigest, window string) {
+	total := len(digest.Created) + len(digest.Edited) + len(digest.Comments) + len(digest.Attachments)
+	if total == 0 {
+		fmt.Fprintf(out, "No activity in %s in the last %s\n", digest.Space, window)
+		return
+	}
+
+	fmt.Fprintf(out, "Activity in %s - last %s\n", digest.Space, window)
+
+	sections := []struct {
+		title string
+		items []activityItem
+	}{
+		{"Created", digest.Created},
+		{"Edited", digest.Edited},
+		{"Comments", digest.Comments},
+		{"Attachments", digest.Attachments},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s (%d)\n", section.title, len(section.items))
+		for _, item := range section.items {
+			line := "- " + item.Title
+			if item.On != "" {
+				line += " on " + item.On
+			}
+			if section.title == "Edited" && item.Version > 0 {
+				line += fmt.Sprintf(" (v%d)", item.Version)
+			}
+			if item.Author != "" {
+				line += " by " + item.Author
+			}
+			if item.URL != "" {
+				line += " - " + item.URL
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+
+	if digest.Truncated {
+		fmt.Fprintf(out, "\nShowing the %d most recent changes (increase --limit to see more)\n", total)
+	}
+}
+
+var activityCmd = &cobra.Command{
+	Use:   "activity",
+	Short: "Summarise recent activity in a space",
+	Long: `Summarise recent creations, edits, comments, and attachment uploads in a space.
+
+Output is plain-text Markdown suitable for a daily digest in chat or email.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		spaceKey := activitySpace
+		if spaceKey == "" {
+			spaceKey = cfg.SpaceKey
+		}
+		if spaceKey == "" {
+			return fmt.Errorf("space key required: use --space flag or set CONFLUENCE_SPACE_KEY")
+		}
+		if activityLimit <= 0 {
+			return fmt.Errorf("limit must be greater than 0")
+		}
+
+		window, err := parseSince(activitySince)
+		if err != nil {
+			return err
+		}
+
+		cql, err := api.BuildActivityCQL(spaceKey, window)
+		if err != nil {
+			return fmt.Errorf("invalid activity parameters: %w", err)
+		}
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Activity] CQL: %s\n", cql)
+		}
+
+		sinceTime := time.Now().Add(-window)
+		var results []api.SearchResult
+		truncated := false
+		cursor := ""
+		for {
+			perPage := min(activityLimit-len(results), api.DefaultSearchLimit)
+			resp, next, err := client.SearchWithExpand(cmd.Context(), cql, perPage, cursor, activityExpand)
+			if err != nil {
+				return fmt.Errorf("searching activity: %w", err)
+			}
+			results = append(results, resp.Results...)
+			if next == "" {
+				break
+			}
+			if len(results) >= activityLimit {
+				truncated = true
+				break
+			}
+			cursor = next
+		}
+
+		digest := buildActivityDigest(spaceKey, sinceTime, cfg.BaseURL, results)
+		digest.Truncated = truncated
+
+		if outputJSON {
+			return printJSON(digest)
+		}
+		printActivityDigest(os.Stdout, digest, activitySince)
+		return nil
+	},
+}
+
+func init() {
+	activityCmd.Flags().StringVarP(&activitySpace, "space", "s", "", "Space key (uses config default if not specified)")
+	activityCmd.Flags().StringVar(&activitySince, "since", "24h", "Look-back window (e.g. 30m, 24h, 7d, 2w)")
+	activityCmd.Flags().IntVarP(&activityLimit, "limit", "l", 100, "Maximum number of changes to include")
+	activityCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	activityCmd.GroupID = "core"
+	rootCmd.AddCommand(activityCmd)
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grantcarthew/acon/internal/api"
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"24h", 24 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"0h", 0, true},
+		{"-1d", 0, true},
+		{"xd", 0, true},
+		{"yesterday", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSince(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/wiki/x", "https://site/wiki/x"},
+		{"https://other/x", "https://other/x"},
+		{"relative", ""},
+	}
+	for _, tt := range tests {
+		if got := absoluteURL("https://site/", tt.url); got != tt.want {
+			t.Errorf("absoluteURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestBuildActivityDigest(t *testing.T) {
+	results := []api.SearchResult{
+		{Title: "New", URL: "/wiki/new", Content: api.SearchContent{Type: "page", Version: &api.SearchVersion{Number: 1, By: api.SearchUser{DisplayName: "Ann"}}}},
+		{Title: "Old", URL: "/wiki/old", Content: api.SearchContent{Type: "blogpost", Version: &api.SearchVersion{Number: 4, By: api.SearchUser{DisplayName: "Bob"}}}},
+		{Title: "Re: Old", Content: api.SearchContent{Type: "comment", Container: &api.SearchContainer{Title: "Old"}}},
+		{Title: "diagram.png", Content: api.SearchContent{Type: "attachment", Container: &api.SearchContainer{Title: "New"}}},
+	}
+
+	digest := buildActivityDigest("DOCS", time.Unix(0, 0), "https://site", results)
+
+	if len(digest.Created) != 1 || digest.Created[0].Title != "New" || digest.Created[0].URL != "https://site/wiki/new" {
+		t.Errorf("Created = %+v", digest.Created)
+	}
+	if len(digest.Edited) != 1 || digest.Edited[0].Version != 4 {
+		t.Errorf("Edited = %+v", digest.Edited)
+	}
+	if len(digest.Comments) != 1 || digest.Comments[0].On != "Old" {
+		t.Errorf("Comments = %+v", digest.Comments)
+	}
+	if len(digest.Attachments) != 1 || digest.Attachments[0].On != "New" {
+		t.Errorf("Attachments = %+v", digest.Attachments)
+	}
+
+	var out bytes.Buffer
+	printActivityDigest(&out, digest, "24h")
+	for _, want := range []string{
+		"Activity in DOCS - last 24h",
+		"Created (1)\n- New by Ann - https://site/wiki/new",
+		"Edited (1)\n- Old (v4) by Bob - https://site/wiki/old",
+		"Comments (1)\n- Re: Old on Old",
+		"Attachments (1)\n- diagram.png on New",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestBuildActivityDigest_CreatedThenEdited(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	results := []api.SearchResult{
+		{Title: "Fresh", Content: api.SearchContent{
+			Type:    "page",
+			Version: &api.SearchVersion{Number: 3},
+			History: &api.SearchHistory{CreatedDate: "2025-03-01T09:15:00.000Z"},
+		}},
+		{Title: "Existing", Content: api.SearchContent{
+			Type:    "page",
+			Version: &api.SearchVersion{Number: 2},
+			History: &api.SearchHistory{CreatedDate: "2025-02-20T09:15:00.000Z"},
+		}},
+	}
+
+	digest := buildActivityDigest("DOCS", since, "", results)
+
+	if len(digest.Created) != 1 || digest.Created[0].Title != "Fresh" {
+		t.Errorf("Created = %+v, want Fresh", digest.Created)
+	}
+	if len(digest.Edited) != 1 || digest.Edited[0].Title != "Existing" {
+		t.Errorf("Edited = %+v, want Existing", digest.Edited)
+	}
+}
+
+func TestPrintActivityDigest_Empty(t *testing.T) {
+	var out bytes.Buffer
+	printActivityDigest(&out, activityDigest{Space: "DOCS"}, "7d")
+	if got := out.String(); got != "No activity in DOCS in the last 7d\n" {
+		t.Errorf("output = %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/api"
+)
+
+// Confluence keeps the title emoji and page width in content properties,
+// with one copy for the published page and one for the editor draft.
+var (
+	emojiPropertyKeys      = []string{"emoji-title-published", "emoji-title-draft"}
+	appearancePropertyKeys = []string{"content-appearance-published", "content-appearance-draft"}
+)
+
+// setPageAppearance sets the title emoji and page width given in the
+// frontmatter. Settings the frontmatter leaves out are not touched.
+func setPageAppearance(ctx context.Context, client *api.Client, pageID string, meta pageMeta) error {
+	var props [][2]string
+	if meta.emojiID != "" {
+		for _, key := range emojiPropertyKeys {
+			props = append(props, [2]string{key, meta.emojiID})
+		}
+	}
+	if meta.fullWidth != nil {
+		appearance := "default"
+		if *meta.fullWidth {
+			appearance = "full-width"
+		}
+		for _, key := range appearancePropertyKeys {
+			props = append(props, [2]string{key, appearance})
+		}
+	}
+
+	for _, prop := range props {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Page] Setting property %s=%s\n", prop[0], prop[1])
+		}
+		if _, err := client.SetPageProperty(ctx, pageID, prop[0], prop[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+var pageAppendCmd = &cobra.Command{
+	Use:   "append [PAGE_ID]",
+	Short: "Add markdown to the end of a page",
+	Long: `Convert a markdown fragment and publish it after the page's current body as
+the next version, for log-style pages such as changelogs and decision
+records. Without PAGE_ID, the page is the one titled --title, looked up in
+--space or the default space.
+
+The new version is made from the version read, so if the page changes in
+between, the update fails rather than losing the other change.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addToPage(cmd, args, false)
+	},
+}
+
+var pagePrependCmd = &cobra.Command{
+	Use:   "prepend [PAGE_ID]",
+	Short: "Add markdown to the start of a page",
+	Long: `Convert a markdown fragment and publish it before the page's current body
+as the next version, as page append does at the end.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addToPage(cmd, args, true)
+	},
+}
+
+// addToPage publishes the markdown of --file after the body of a page, or
+// before it if prepend is set
+func addToPage(cmd *cobra.Command, args []string, prepend bool) error {
+	client, cfg, err := initClient()
+	if err != nil {
+		return err
+	}
+	pageID, err := resolvePageArg(cmd.Context(), client, cfg, args)
+	if err != nil {
+		return err
+	}
+	existing, err := client.GetPage(cmd.Context(), pageID)
+	if err != nil {
+		return fmt.Errorf("getting page: %w", err)
+	}
+	if err := checkPageProtection(cmd.Context(), client, cfg, pageID, existing.SpaceID, "update"); err != nil {
+		return err
+	}
+
+	body, err := readMarkdownFragment(pageFile)
+	if err != nil {
+		return err
+	}
+
+	opts, err := converterOptionsForSpaceID(cmd.Context(), client, cfg, existing.SpaceID)
+	if err != nil {
+		return err
+	}
+	if err := applyConverterFlags(&opts); err != nil {
+		return err
+	}
+	fragment, files, err := convertMarkdown(cmd.Context(), client, converter.NewConverter(opts), converter.Document{}, body, pageFile)
+	if err != nil {
+		return err
+	}
+
+	current := ""
+	if existing.Body != nil && existing.Body.Storage != nil {
+		current = existing.Body.Storage.Value
+	}
+	htmlContent := current + fragment
+	if prepend {
+		htmlContent = fragment + current
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page] Adding %d bytes of storage format to %d\n", len(fragment), len(current))
+	}
+
+	meta := pageMeta{status: existing.Status}
+	if meta.status == "" {
+		meta.status = "current"
+	}
+	result, err := publishPageVersion(cmd.Context(), client, existing, meta, htmlContent, files, updateMsg)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		return printJSON(result)
+	}
+	if dryRun {
+		printDryRunUpdate(existing, result)
+		return nil
+	}
+	space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
+	if err != nil || space.Key == "" {
+		fmt.Fprintf(os.Stderr, "Warning: page updated but could not resolve space key for URL\n")
+		fmt.Println(result.ID)
+		return nil
+	}
+	fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
+	return nil
+}
+
+// readMarkdownFragment reads markdown to publish as part of a page from
+// path, or stdin if path is "" or "-". Frontmatter is refused, as there is
+// no page of its own for it to set.
+func readMarkdownFragment(path string) ([]byte, error) {
+	content, err := readAndValidateContent(path)
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := converter.SplitFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) != len(content) {
+		return nil, fmt.Errorf("frontmatter is not used in part of a page; use page meta to change its settings")
+	}
+	return body, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{pageAppendCmd, pagePrependCmd} {
+		cmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Title of the page, instead of PAGE_ID")
+		cmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space to look up --title in (uses config default if not specified)")
+		cmd.Flags().StringVarP(&pageFile, "file", "f", "", "Markdown file, or - for stdin")
+		cmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version update message")
+		cmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+		cmd.Flags().BoolVar(&renderDiagrams, "render-diagrams", false, "Render diagram fences locally and attach them as images")
+		cmd.Flags().StringVar(&diagramFormat, "diagram-format", "svg", "Rendered diagram format: svg, png")
+		cmd.Flags().IntVar(&headingOff, "heading-offset", 0, "Shift heading levels by this much, e.g. 1 to publish # as h2 (-5 to 5)")
+		cmd.Flags().BoolVar(&allowHTML, "allow-html", false, "Pass through raw HTML <br>, <sup>, and <sub> instead of omitting it")
+		cmd.Flags().BoolVar(&bareDates, "dates", false, "Convert ISO dates (2025-03-01) in text to date lozenges, not just {date:...}")
+		cmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of omitting raw HTML that cannot be published")
+		cmd.Flags().BoolVar(&typography, "typography", false, "Use curly quotes, en and em dashes, and ellipses for straight quotes, -- and ---, and ...")
+		cmd.Flags().BoolVar(&subscript, "subscript", false, "Convert ~text~ to subscript instead of strikethrough")
+		cmd.Flags().BoolVar(&hardWraps, "hard-wraps", false, "Turn every newline within a paragraph into a line break")
+		cmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+		cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+		pageCmd.AddCommand(cmd)
+	}
+}
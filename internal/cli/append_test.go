@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestPageAppendCmd(t *testing.T) {
+	var update api.PageUpdateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/wiki/api/v2/pages/42":
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1","status":"current","title":"Log","version":{"number":2},"body":{"storage":{"representation":"storage","value":"<p>Old</p>"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/wiki/api/v2/pages/42":
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &update); err != nil {
+				t.Errorf("decoding update: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1"}`))
+		case r.URL.Path == "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	dir := t.TempDir()
+	write := func(content string) string {
+		t.Helper()
+		path := filepath.Join(dir, "entry.md")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	run := func(cmd *cobra.Command) (string, error) {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := cmd.RunE(testCommand(), []string{"42"})
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		want string
+	}{
+		{"append", pageAppendCmd, "<p>Old</p><p>New</p>\n"},
+		{"prepend", pagePrependCmd, "<p>New</p>\n<p>Old</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			update = api.PageUpdateRequest{}
+			pageFile = write("New\n")
+			stdout, err := run(tt.cmd)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if want := server.URL + "/wiki/spaces/DOCS/pages/42\n"; stdout != want {
+				t.Errorf("stdout = %q, want %q", stdout, want)
+			}
+			if update.Body == nil || update.Body.Value != tt.want {
+				t.Errorf("body = %+v, want %q", update.Body, tt.want)
+			}
+			if update.Title != "Log" || update.Status != "current" || update.Version.Number != 3 {
+				t.Errorf("update = %+v", update)
+			}
+		})
+	}
+
+	resetPageFlags(t)
+	pageFile = write("---\ntitle: Other\n---\nNew\n")
+	if _, err := run(pageAppendCmd); err == nil || !strings.Contains(err.Error(), "frontmatter is not used in part of a page") {
+		t.Errorf("frontmatter: err = %v", err)
+	}
+
+	pageFile, dryRun = write("New\n"), true
+	update = api.PageUpdateRequest{}
+	stdout, err := run(pageAppendCmd)
+	if err != nil {
+		t.Fatalf("append --dry-run: %v", err)
+	}
+	if !strings.HasPrefix(stdout, `Would update page 42 "Log" to version 3`) || update.Title != "" {
+		t.Errorf("--dry-run stdout = %q, update = %+v", stdout, update)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var pageArchiveCmd = &cobra.Command{
+	Use:   "archive [PAGE_ID]",
+	Short: "Archive a page",
+	Long: `Archive a Confluence page, given by ID or by --title, which is looked up in
+--space or the default space. Confluence archives in the background, so the
+page may take a moment to leave the page tree; it can be restored from the
+space's archive.
+
+A PAGE_ID of - reads page IDs from stdin, one per line, and archives each in
+turn, stopping at the first failure.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		return forEachPage(cmd.Context(), client, cfg, args, func(pageID string) error {
+			return archivePage(cmd.Context(), client, cfg, pageID)
+		})
+	},
+}
+
+// archivePage archives a page, or with --dry-run reports that it would
+func archivePage(ctx context.Context, client *api.Client, cfg *config.Config, pageID string) error {
+	if err := checkPageProtection(ctx, client, cfg, pageID, "", "archive"); err != nil {
+		return err
+	}
+	if dryRun {
+		page, err := client.GetPage(ctx, pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		fmt.Printf("Would archive page %s %q\n", page.ID, page.Title)
+		return nil
+	}
+
+	taskID, err := client.ArchivePage(ctx, pageID)
+	if err != nil {
+		return fmt.Errorf("archiving page: %w", err)
+	}
+	fmt.Printf("Page %s archiving (task %s)\n", pageID, taskID)
+	return nil
+}
+
+func init() {
+	pageArchiveCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Title of the page to archive, instead of PAGE_ID")
+	pageArchiveCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space to look up --title in (uses config default if not specified)")
+	pageArchiveCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+	pageArchiveCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+	pageCmd.AddCommand(pageArchiveCmd)
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+// maxImageSize caps local images referenced from Markdown
+const maxImageSize = 25 * 1024 * 1024 // 25MB
+
+// pendingFile is a file waiting to be uploaded as an attachment
+type pendingFile struct {
+	filename string
+	data     []byte
+}
+
+// attachments collects the files converted Markdown refers to as page
+// attachments, so they can be uploaded once the target page exists. The
+// converter cannot report errors, so the first one is kept in err.
+type attachments struct {
+	files []pendingFile
+	err   error
+}
+
+// prepareAttachments hooks local images, and diagrams if --render-diagrams
+// is set, into the conversion of doc. markdownPath locates relative image
+// paths; "" or "-" (stdin) resolves them against the working directory.
+func prepareAttachments(ctx context.Context, doc *converter.Document, markdownPath string) (*attachments, error) {
+	a := &attachments{}
+	baseDir := "."
+	if markdownPath != "" && markdownPath != "-" {
+		baseDir = filepath.Dir(markdownPath)
+	}
+	doc.AttachImage = func(path string) (string, error) {
+		return a.attachLocalImage(baseDir, path)
+	}
+	if err := attachDiagramRenderer(ctx, doc, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// has reports whether filename is already queued
+func (a *attachments) has(filename string) bool {
+	for _, f := range a.files {
+		if f.filename == filename {
+			return true
+		}
+	}
+	return false
+}
+
+// add queues data under filename. Confluence keys attachments by filename,
+// so two different files with the same name cannot share a page.
+func (a *attachments) add(filename string, data []byte) error {
+	for _, f := range a.files {
+		if f.filename == filename {
+			if bytes.Equal(f.data, data) {
+				return nil
+			}
+			return fmt.Errorf("two different files are named %s", filename)
+		}
+	}
+	a.files = append(a.files, pendingFile{filename: filename, data: data})
+	return nil
+}
+
+// fail records err if it is the first failure
+func (a *attachments) fail(err error) {
+	if a.err == nil {
+		a.err = err
+	}
+}
+
+// attachLocalImage implements converter.ImageAttacher for images on disk.
+func (a *attachments) attachLocalImage(baseDir, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, filepath.FromSlash(path))
+	}
+	filename := filepath.Base(path)
+	data, err := readLocalImage(path)
+	if err == nil {
+		err = a.add(filename, data)
+	}
+	if err != nil {
+		a.fail(fmt.Errorf("attaching image %s: %w", path, err))
+		return "", err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Attachment] Queued image %s\n", path)
+	}
+	return filename, nil
+}
+
+// readLocalImage reads an image file, refusing anything over maxImageSize
+func readLocalImage(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxImageSize {
+		return nil, fmt.Errorf("file too large: %d bytes (max %d)", info.Size(), maxImageSize)
+	}
+	return os.ReadFile(path)
+}
+
+// upload attaches every queued file to pageID.
+func (a *attachments) upload(ctx context.Context, client *api.Client, pageID string) error {
+	for _, f := range a.files {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Attachment] Uploading %s to page %s\n", f.filename, pageID)
+		}
+		if _, err := client.UploadAttachment(ctx, pageID, f.filename, f.data); err != nil {
+			return fmt.Errorf("uploading %s: %w", f.filename, err)
+		}
+	}
+	return nil
+}
+
+// downloadAttachments saves the attachments of pageID named by filenames
+// into dir, creating it if needed. Existing files are overwritten.
+func downloadAttachments(ctx context.Context, client *api.Client, pageID, dir string, filenames []string) error {
+	if len(filenames) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating attachment directory: %w", err)
+	}
+	for _, filename := range filenames {
+		// The filename comes from the page, so keep it inside dir
+		if filename != filepath.Base(filename) || filename == "." || filename == ".." {
+			return fmt.Errorf("refusing to download attachment with unsafe filename %q", filename)
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Attachment] Downloading %s from page %s\n", filename, pageID)
+		}
+		data, err := client.DownloadAttachment(ctx, pageID, filename)
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", filename, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filename), data, 0o644); err != nil {
+			return fmt.Errorf("saving %s: %w", filename, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+func TestPrepareAttachments_LocalImages(t *testing.T) {
+	resetPageFlags(t)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "img", "arch.png"), []byte("png-bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	markdownPath := filepath.Join(dir, "doc.md")
+
+	var doc converter.Document
+	files, err := prepareAttachments(context.Background(), &doc, markdownPath)
+	if err != nil {
+		t.Fatalf("prepareAttachments() error = %v", err)
+	}
+
+	out, err := converter.NewConverter(converter.Options{}).ConvertDocument("![a](./img/arch.png) ![b](img/arch.png) ![c](https://example.com/x.png)", doc)
+	if err != nil {
+		t.Fatalf("ConvertDocument() error = %v", err)
+	}
+	if files.err != nil {
+		t.Fatalf("unexpected error = %v", files.err)
+	}
+	if strings.Count(out, `<ri:attachment ri:filename="arch.png" />`) != 2 || !strings.Contains(out, `ri:value="https://example.com/x.png"`) {
+		t.Errorf("storage = %q", out)
+	}
+	if len(files.files) != 1 || files.files[0].filename != "arch.png" || string(files.files[0].data) != "png-bytes" {
+		t.Errorf("queued files = %+v", files.files)
+	}
+}
+
+func TestPrepareAttachments_Errors(t *testing.T) {
+	resetPageFlags(t)
+
+	dir := t.TempDir()
+	for _, name := range []string{"a/logo.png", "b/logo.png"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		wantErr  string
+	}{
+		{name: "missing file", markdown: "![x](nope.png)", wantErr: "attaching image"},
+		{name: "name clash", markdown: "![x](a/logo.png) ![y](b/logo.png)", wantErr: "two different files are named logo.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc converter.Document
+			files, err := prepareAttachments(context.Background(), &doc, filepath.Join(dir, "doc.md"))
+			if err != nil {
+				t.Fatalf("prepareAttachments() error = %v", err)
+			}
+			if _, err := converter.NewConverter(converter.Options{}).ConvertDocument(tt.markdown, doc); err != nil {
+				t.Fatalf("ConvertDocument() error = %v", err)
+			}
+			if files.err == nil || !strings.Contains(files.err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", files.err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAttachments_Upload(t *testing.T) {
+	var mu sync.Mutex
+	var uploaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		mu.Lock()
+		uploaded = append(uploaded, r.URL.Path+" "+header.Filename+"="+string(data))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"id":"att1","title":"` + header.Filename + `"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	files := &attachments{}
+	_ = files.add("a.png", []byte("A"))
+	_ = files.add("b.svg", []byte("B"))
+	if err := files.upload(context.Background(), client, "42"); err != nil {
+		t.Fatalf("upload() error = %v", err)
+	}
+
+	want := []string{
+		"/wiki/rest/api/content/42/child/attachment a.png=A",
+		"/wiki/rest/api/content/42/child/attachment b.svg=B",
+	}
+	if strings.Join(uploaded, "\n") != strings.Join(want, "\n") {
+		t.Errorf("uploaded = %v, want %v", uploaded, want)
+	}
+}
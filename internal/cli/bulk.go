@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// stdinArg is the PAGE_ID argument that reads page IDs from stdin instead
+const stdinArg = "-"
+
+// readPageIDs reads page IDs, or page URLs, one per line from stdin, as
+// printed by search --ids. Blank lines are skipped.
+func readPageIDs() ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(stdinReader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		id, err := pageIDArg(line)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading page IDs from stdin: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no page IDs on stdin")
+	}
+	return ids, nil
+}
+
+// forEachPage calls fn for the pages args names: each page ID on stdin if
+// args is "-", in turn and stopping at the first failure, or else the page
+// resolvePageArg finds
+func forEachPage(ctx context.Context, client *api.Client, cfg *config.Config, args []string, fn func(pageID string) error) error {
+	if len(args) == 1 && args[0] == stdinArg {
+		if pageTitle != "" {
+			return fmt.Errorf("--title cannot be used with page IDs from stdin")
+		}
+		ids, err := readPageIDs()
+		if err != nil {
+			return err
+		}
+		for i, id := range ids {
+			if err := fn(id); err != nil {
+				return fmt.Errorf("page %s (%d of %d pages done): %w", id, i, len(ids), err)
+			}
+		}
+		return nil
+	}
+
+	pageID, err := resolvePageArg(ctx, client, cfg, args)
+	if err != nil {
+		return err
+	}
+	return fn(pageID)
+}
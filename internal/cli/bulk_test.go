@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestReadPageIDs(t *testing.T) {
+	withStdin(t, strings.NewReader("1\n\n  2  \nhttps://example.atlassian.net/wiki/spaces/OPS/pages/3/Runbook\n"), 0, nil)
+	ids, err := readPageIDs()
+	if err != nil {
+		t.Fatalf("readPageIDs: %v", err)
+	}
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("ids = %v, want [1 2 3]", ids)
+	}
+
+	withStdin(t, strings.NewReader("\n\n"), 0, nil)
+	if _, err := readPageIDs(); err == nil || !strings.Contains(err.Error(), "no page IDs") {
+		t.Errorf("empty stdin error = %v, want no page IDs", err)
+	}
+}
+
+func TestPageDeleteCmd_Stdin(t *testing.T) {
+	var mu sync.Mutex
+	var deletes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/")
+		switch {
+		case r.Method == http.MethodDelete && id == "3":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			mu.Lock()
+			deletes = append(deletes, id)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"id":"` + id + `","spaceId":"s1","title":"Page ` + id + `"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	run := func() (string, error) {
+		t.Helper()
+		withStdin(t, strings.NewReader("1\n2\n3\n4\n"), 0, nil)
+		finish := captureStdStreams(t)
+		runErr := pageDeleteCmd.RunE(testCommand(), []string{"-"})
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+
+	resetPageFlags(t)
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "requires --force") {
+		t.Errorf("without --force error = %v, want --force required", err)
+	}
+
+	dryRun = true
+	stdout, err := run()
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if stdout != "Would delete page 1 \"Page 1\"\nWould delete page 2 \"Page 2\"\nWould delete page 3 \"Page 3\"\nWould delete page 4 \"Page 4\"\n" {
+		t.Errorf("dry run output = %q", stdout)
+	}
+	if len(deletes) != 0 {
+		t.Errorf("dry run deletes = %v, want none", deletes)
+	}
+
+	dryRun, force = false, true
+	stdout, err = run()
+	if err == nil || !strings.Contains(err.Error(), "page 3 (2 of 4 pages done)") {
+		t.Errorf("err = %v, want failure at page 3", err)
+	}
+	if stdout != "Page 1 deleted successfully\nPage 2 deleted successfully\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+	if strings.Join(deletes, ",") != "1,2" {
+		t.Errorf("deletes = %v, want [1 2]", deletes)
+	}
+}
+
+func TestPageMoveCmd_Stdin(t *testing.T) {
+	resetPageFlags(t)
+	tree := newFakeTree(
+		api.Page{ID: "10", SpaceID: "space-1", Title: "Home", Version: &api.Version{Number: 1},
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Value: "<p>Home</p>"}}},
+		api.Page{ID: "20", SpaceID: "space-1", Title: "A", Version: &api.Version{Number: 1},
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Value: "<p>A</p>"}}},
+		api.Page{ID: "30", SpaceID: "space-1", Title: "B", Version: &api.Version{Number: 1},
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Value: "<p>B</p>"}}},
+	)
+	server := httptest.NewServer(tree)
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	moveParent = "10"
+	withStdin(t, strings.NewReader("20\n30\n"), 0, nil)
+	finish := captureStdStreams(t)
+	runErr := pageMoveCmd.RunE(testCommand(), []string{"-"})
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("move: %v", runErr)
+	}
+	want := server.URL + "/wiki/spaces/DOCS/pages/20\n" + server.URL + "/wiki/spaces/DOCS/pages/30\n"
+	if stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if strings.Join(tree.puts, ",") != "20,30" {
+		t.Errorf("puts = %v, want [20 30]", tree.puts)
+	}
+}
+
+func TestPageArchiveCmd_Stdin(t *testing.T) {
+	var archived []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content/archive":
+			var body struct {
+				Pages []struct {
+					ID int64 `json:"id"`
+				} `json:"pages"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Pages) != 1 {
+				t.Errorf("archive body = %+v, %v", body, err)
+			}
+			id := strconv.FormatInt(body.Pages[0].ID, 10)
+			if id == "3" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			archived = append(archived, id)
+			_, _ = w.Write([]byte(`{"id":"task-` + id + `"}`))
+		case r.Method == http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/")
+			_, _ = w.Write([]byte(`{"id":"` + id + `","spaceId":"s1","title":"Page ` + id + `"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	run := func() (string, error) {
+		t.Helper()
+		withStdin(t, strings.NewReader("1\n2\n3\n4\n"), 0, nil)
+		finish := captureStdStreams(t)
+		runErr := pageArchiveCmd.RunE(testCommand(), []string{"-"})
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+
+	resetPageFlags(t)
+	dryRun = true
+	stdout, err := run()
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.HasPrefix(stdout, "Would archive page 1 \"Page 1\"\n") || len(archived) != 0 {
+		t.Errorf("dry run stdout = %q, archived = %v", stdout, archived)
+	}
+
+	dryRun = false
+	stdout, err = run()
+	if err == nil || !strings.Contains(err.Error(), "page 3 (2 of 4 pages done)") {
+		t.Errorf("err = %v, want failure at page 3", err)
+	}
+	if stdout != "Page 1 archiving (task task-1)\nPage 2 archiving (task task-2)\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
+
+func TestPageLabelCmd_Stdin(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodPost:
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	resetPageFlags(t)
+	withStdin(t, strings.NewReader("1\n2\n"), 0, nil)
+	finish := captureStdStreams(t)
+	runErr := pageLabelAddCmd.RunE(testCommand(), []string{"-", "obsolete"})
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("label add: %v", runErr)
+	}
+	if stdout != "Added labels to page 1: obsolete\nAdded labels to page 2: obsolete\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+
+	finish = captureStdStreams(t)
+	runErr = pageLabelRemoveCmd.RunE(testCommand(), []string{"2", "old", "stale"})
+	_, _ = finish()
+	if runErr != nil {
+		t.Fatalf("label remove: %v", runErr)
+	}
+	want := []string{
+		"POST /wiki/rest/api/content/1/label",
+		"POST /wiki/rest/api/content/2/label",
+		"DELETE /wiki/rest/api/content/2/label/old",
+		"DELETE /wiki/rest/api/content/2/label/stale",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
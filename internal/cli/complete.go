@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	maxCompletions     = 100             // Spaces or pages offered by one completion
+	completionCacheTTL = 5 * time.Minute // How long completions are reused
+	completionTimeout  = 5 * time.Second // Longest a completion waits on Confluence
+)
+
+// completionCacheDir returns the directory completions are cached in.
+// Override in tests.
+var completionCacheDir = func() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "acon", "completion"), nil
+}
+
+// registerCompletions completes --space with space keys, --parent with page
+// IDs described by their titles, and --title with page titles, on cmd and
+// every command below it that has these flags. Flags that already have a
+// completion are left alone.
+func registerCompletions(cmd *cobra.Command) {
+	completions := map[string]cobra.CompletionFunc{
+		"space":  completeSpaceKeys,
+		"parent": completeParentPages,
+		"title":  completePageTitles,
+	}
+	for name, fn := range completions {
+		if cmd == pageCreateCmd && name == "title" {
+			// A new page's title is not one that already exists
+			continue
+		}
+		if cmd.Flags().Lookup(name) == nil {
+			continue
+		}
+		if _, ok := cmd.GetFlagCompletionFunc(name); ok {
+			continue
+		}
+		_ = cmd.RegisterFlagCompletionFunc(name, fn)
+	}
+	for _, child := range cmd.Commands() {
+		registerCompletions(child)
+	}
+}
+
+// completeSpaceKeys completes space keys, described by the space name
+func completeSpaceKeys(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return cachedCompletions(cmd, "spaces", func(ctx context.Context, client *api.Client, cfg *config.Config) ([]cobra.Completion, error) {
+		spaces, err := client.ListSpaces(ctx, maxCompletions)
+		if err != nil {
+			return nil, err
+		}
+		var keys []cobra.Completion
+		for _, space := range spaces {
+			keys = append(keys, cobra.CompletionWithDesc(space.Key, space.Name))
+		}
+		return keys, nil
+	})
+}
+
+// completeSpaceKeyArg completes a SPACE_KEY argument
+func completeSpaceKeyArg(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSpaceKeys(cmd, args, toComplete)
+}
+
+// completeParentPages completes page IDs in the --space space, or the
+// default space, described by their titles
+func completeParentPages(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	spaceKey := completionSpace(cmd)
+	return cachedCompletions(cmd, "pages "+spaceKey, func(ctx context.Context, client *api.Client, cfg *config.Config) ([]cobra.Completion, error) {
+		pages, err := completionSpacePages(ctx, client, cfg, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		var ids []cobra.Completion
+		for _, page := range pages {
+			ids = append(ids, cobra.CompletionWithDesc(page.ID, page.Title))
+		}
+		return ids, nil
+	})
+}
+
+// completePageTitles completes the titles of the pages under --parent if
+// it is given, otherwise of the pages in the --space space or the default
+// space
+func completePageTitles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	parent := ""
+	if flag := cmd.Flags().Lookup("parent"); flag != nil {
+		parent = flag.Value.String()
+	}
+	spaceKey := completionSpace(cmd)
+	return cachedCompletions(cmd, "titles "+parent+" "+spaceKey, func(ctx context.Context, client *api.Client, cfg *config.Config) ([]cobra.Completion, error) {
+		var titles []cobra.Completion
+		if parent != "" {
+			parentID, err := pageIDArg(parent)
+			if err != nil {
+				return nil, err
+			}
+			pages, _, err := client.GetChildPages(ctx, parentID, maxCompletions, "")
+			if err != nil {
+				return nil, err
+			}
+			for _, page := range pages {
+				titles = append(titles, page.Title)
+			}
+			return titles, nil
+		}
+		pages, err := completionSpacePages(ctx, client, cfg, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			titles = append(titles, page.Title)
+		}
+		return titles, nil
+	})
+}
+
+// completionSpacePages lists the pages of a space, or of the default space
+// if spaceKey is "", by title
+func completionSpacePages(ctx context.Context, client *api.Client, cfg *config.Config, spaceKey string) ([]api.Page, error) {
+	if spaceKey == "" {
+		spaceKey = cfg.SpaceKey
+	}
+	if spaceKey == "" {
+		return nil, nil
+	}
+	space, err := client.GetSpace(ctx, spaceKey)
+	if err != nil {
+		return nil, err
+	}
+	pages, _, err := client.ListPages(ctx, space.ID, maxCompletions, "title")
+	return pages, err
+}
+
+// completionSpace returns the --space value typed so far on the command line
+func completionSpace(cmd *cobra.Command) string {
+	if flag := cmd.Flags().Lookup("space"); flag != nil {
+		return flag.Value.String()
+	}
+	return ""
+}
+
+// cachedCompletions returns the completions cached under key for the
+// configured Confluence site if they are recent, and otherwise fetches and
+// caches them. Completion runs as a new process on every Tab press, so the
+// cache is kept on disk. Failures give no completions rather than an error.
+func cachedCompletions(cmd *cobra.Command, key string, fetch func(context.Context, *api.Client, *config.Config) ([]cobra.Completion, error)) ([]cobra.Completion, cobra.ShellCompDirective) {
+	directive := cobra.ShellCompDirectiveNoFileComp
+
+	client, cfg, err := initClient()
+	if err != nil {
+		return nil, directive
+	}
+	sum := sha256.Sum256([]byte(cfg.BaseURL + "\n" + cfg.Email + "\n" + key))
+	var path string
+	if dir, err := completionCacheDir(); err == nil {
+		path = filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+	}
+
+	if path != "" {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < completionCacheTTL {
+			if data, err := os.ReadFile(path); err == nil {
+				var cached []cobra.Completion
+				if json.Unmarshal(data, &cached) == nil {
+					return cached, directive
+				}
+			}
+		}
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+	completions, err := fetch(ctx, client, cfg)
+	if err != nil {
+		cobra.CompDebugln("acon: "+err.Error(), false)
+		return nil, directive
+	}
+
+	if path != "" {
+		if data, err := json.Marshal(completions); err == nil {
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err == nil {
+				_ = os.WriteFile(path, data, 0o600)
+			}
+		}
+	}
+	return completions, directive
+}
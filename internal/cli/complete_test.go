@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestCompletions(t *testing.T) {
+	resetPageFlags(t)
+	cacheDir := t.TempDir()
+	origDir := completionCacheDir
+	completionCacheDir = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { completionCacheDir = origDir })
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/api/v2/spaces":
+			if r.URL.Query().Get("keys") == "OPS" {
+				_, _ = w.Write([]byte(`{"results":[{"id":"s1","key":"OPS"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[{"id":"s1","key":"OPS","name":"Operations"},{"id":"s2","key":"DEV","name":"Development"}]}`))
+		case "/wiki/api/v2/pages":
+			_, _ = w.Write([]byte(`{"results":[{"id":"10","title":"Home"},{"id":"11","title":"Runbooks"}]}`))
+		case "/wiki/api/v2/pages/11/children":
+			_, _ = w.Write([]byte(`{"results":[{"id":"20","title":"Restart the API"}]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL, SpaceKey: "OPS"})
+
+	got, directive := completeSpaceKeys(testCommand(), nil, "")
+	if strings.Join(got, ",") != "OPS\tOperations,DEV\tDevelopment" || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("space keys = %q, %v", got, directive)
+	}
+	before := requests.Load()
+	if again, _ := completeSpaceKeys(testCommand(), nil, ""); strings.Join(again, ",") != strings.Join(got, ",") || requests.Load() != before {
+		t.Errorf("cached space keys = %q with %d new requests, want the same keys from the cache", again, requests.Load()-before)
+	}
+
+	got, _ = completeParentPages(pageListCmd, nil, "")
+	if strings.Join(got, ",") != "10\tHome,11\tRunbooks" {
+		t.Errorf("parent pages = %q", got)
+	}
+
+	got, _ = completePageTitles(pageViewCmd, nil, "")
+	if strings.Join(got, ",") != "Home,Runbooks" {
+		t.Errorf("titles in the space = %q", got)
+	}
+	pageParent = "11"
+	got, _ = completePageTitles(pageUpsertCmd, nil, "")
+	if strings.Join(got, ",") != "Restart the API" {
+		t.Errorf("titles under parent = %q", got)
+	}
+}
+
+func TestRegisterCompletions(t *testing.T) {
+	registerCompletions(rootCmd)
+	for _, tt := range []struct {
+		cmd  *cobra.Command
+		flag string
+		want bool
+	}{
+		{pageViewCmd, "space", true},
+		{pageViewCmd, "title", true},
+		{pageMoveCmd, "parent", true},
+		{searchCmd, "space", true},
+		{pageCreateCmd, "title", false},
+		{pageCreateCmd, "parent", true},
+	} {
+		if _, ok := tt.cmd.GetFlagCompletionFunc(tt.flag); ok != tt.want {
+			t.Errorf("%s --%s completion = %v, want %v", tt.cmd.CommandPath(), tt.flag, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the config file",
+	Long: `Read and write the acon config file, which holds the Confluence site,
+credentials, and default space so they need not be set in the environment.
+Environment variables, where set, override the config file.
+
+Settings for other sites go in named profiles, selected with --profile or
+$ACON_PROFILE. A profile's settings override the environment and the
+top-level settings; any it leaves out fall back to them.`,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the config file",
+	Long: `Create the config file, filled in with the settings currently taken from
+the environment. Settings that are not set are left commented out, to be
+filled in with acon config set.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.FilePath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("config file %s already exists (use --force to replace it)", path)
+		}
+		values, err := config.Effective("")
+		if err != nil && !force {
+			return err
+		}
+
+		var b strings.Builder
+		b.WriteString("# acon config file\n# Environment variables, where set, override these settings.\n")
+		for _, v := range values {
+			fmt.Fprintf(&b, "\n# %s (overridden by %s)\n", v.Description, strings.Join(v.Env, ", "))
+			if v.Value == "" {
+				fmt.Fprintf(&b, "# %s = \"\"\n", v.Key)
+				continue
+			}
+			fmt.Fprintf(&b, "%s = %s\n", v.Key, strconv.Quote(v.Value))
+		}
+		if err := config.WriteFile(path, b.String()); err != nil {
+			return err
+		}
+		fmt.Printf("Created config file %s\n", path)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set KEY VALUE",
+	Short:             "Set a value in the config file",
+	Long:              "Set a value in the config file, in the --profile profile if given. Keys: " + configKeys(),
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKey,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.FilePath()
+		if err != nil {
+			return err
+		}
+		setting, err := config.LookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+		if err := config.SetValue(path, configProfile, args[0], args[1]); err != nil {
+			return err
+		}
+		if configProfile != "" {
+			fmt.Printf("Set %s in profile %s in %s\n", args[0], configProfile, path)
+			return nil
+		}
+		fmt.Printf("Set %s in %s\n", args[0], path)
+		for _, name := range setting.Env {
+			if os.Getenv(name) != "" {
+				fmt.Fprintf(os.Stderr, "Warning: %s is set and overrides the config file\n", name)
+				break
+			}
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get KEY",
+	Short:             "Show the effective value of a setting",
+	Long:              "Show the effective value of a setting, from the environment or the config file. The API token is masked. Keys: " + configKeys(),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := config.LookupSetting(args[0]); err != nil {
+			return err
+		}
+		values, err := config.Effective(configProfile)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if v.Key != args[0] {
+				continue
+			}
+			if v.Value == "" {
+				return fmt.Errorf("%s is not set", v.Key)
+			}
+			fmt.Println(v.Display(v.Value))
+		}
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the effective configuration",
+	Long:  "Show every setting with its effective value and where it came from. The API token is masked.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.FilePath()
+		if err != nil {
+			return err
+		}
+		values, err := config.Effective(configProfile)
+		if err != nil {
+			return err
+		}
+		profiles, err := config.Profiles()
+		if err != nil {
+			return err
+		}
+		selected := configProfile
+		if selected == "" {
+			selected = os.Getenv("ACON_PROFILE")
+		}
+
+		if outputJSON {
+			type setting struct {
+				Key    string `json:"key"`
+				Value  string `json:"value"`
+				Source string `json:"source"`
+			}
+			out := struct {
+				File     string    `json:"file"`
+				Profile  string    `json:"profile,omitempty"`
+				Profiles []string  `json:"profiles"`
+				Settings []setting `json:"settings"`
+			}{File: path, Profile: selected, Profiles: profiles, Settings: []setting{}}
+			for _, v := range values {
+				out.Settings = append(out.Settings, setting{Key: v.Key, Value: v.Display(v.Value), Source: v.Source})
+			}
+			return printJSON(out)
+		}
+
+		fmt.Printf("Config file: %s\n", path)
+		if len(profiles) > 0 {
+			fmt.Printf("Profiles: %s\n", strings.Join(profiles, ", "))
+		}
+		if selected != "" {
+			fmt.Printf("Profile: %s\n", selected)
+		}
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, v := range values {
+			value, source := v.Display(v.Value), v.Source
+			if v.Value == "" {
+				value, source = "-", "not set"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, value, source)
+		}
+		return w.Flush()
+	},
+}
+
+// configKeys lists the settable config keys
+func configKeys() string {
+	keys := make([]string, len(config.Settings))
+	for i, s := range config.Settings {
+		keys[i] = s.Key
+	}
+	return strings.Join(keys, ", ")
+}
+
+// completeProfiles completes --profile with the profiles in the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	profiles, err := config.Profiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKey completes a config KEY argument, described by what it sets
+func completeConfigKey(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var keys []cobra.Completion
+	for _, s := range config.Settings {
+		keys = append(keys, cobra.CompletionWithDesc(s.Key, s.Description))
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	configInitCmd.Flags().BoolVar(&force, "force", false, "Replace an existing config file")
+	configListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configCmd.GroupID = "utility"
+	rootCmd.AddCommand(configCmd)
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+}
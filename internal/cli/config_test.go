@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// withConfigFile points the config file at path and clears the environment
+// variables that override it.
+func withConfigFile(t *testing.T, path string) {
+	t.Helper()
+	for _, s := range config.Settings {
+		for _, name := range s.Env {
+			t.Setenv(name, "")
+		}
+	}
+	t.Setenv("ACON_CONFIG", path)
+	t.Setenv("ACON_PROFILE", "")
+}
+
+func TestConfigCmd(t *testing.T) {
+	resetPageFlags(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	withConfigFile(t, path)
+	t.Setenv("CONFLUENCE_BASE_URL", "https://example.atlassian.net/wiki")
+
+	run := func(cmdRunE func() error) (string, string, error) {
+		t.Helper()
+		finish := captureStdStreams(t)
+		err := cmdRunE()
+		stdout, stderr := finish()
+		return stdout, stderr, err
+	}
+
+	stdout, _, err := run(func() error { return configInitCmd.RunE(testCommand(), nil) })
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if stdout != "Created config file "+path+"\n" {
+		t.Errorf("init stdout = %q", stdout)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading config file: %v", err)
+	}
+	if !strings.Contains(string(data), "\nbase_url = \"https://example.atlassian.net/wiki\"\n") ||
+		!strings.Contains(string(data), "\n# email = \"\"\n") {
+		t.Errorf("config file = %q", data)
+	}
+	if _, _, err := run(func() error { return configInitCmd.RunE(testCommand(), nil) }); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second init error = %v, want already exists", err)
+	}
+
+	if _, _, err := run(func() error { return configSetCmd.RunE(testCommand(), []string{"email", "nobody"}) }); err == nil {
+		t.Error("set with an invalid email succeeded")
+	}
+	if _, _, err := run(func() error { return configSetCmd.RunE(testCommand(), []string{"api_token", "secret-token-1234"}) }); err != nil {
+		t.Fatalf("set api_token: %v", err)
+	}
+	_, stderr, err := run(func() error {
+		return configSetCmd.RunE(testCommand(), []string{"base_url", "https://other.atlassian.net/wiki"})
+	})
+	if err != nil {
+		t.Fatalf("set base_url: %v", err)
+	}
+	if !strings.Contains(stderr, "CONFLUENCE_BASE_URL is set and overrides the config file") {
+		t.Errorf("set stderr = %q, want override warning", stderr)
+	}
+
+	stdout, _, err = run(func() error { return configGetCmd.RunE(testCommand(), []string{"api_token"}) })
+	if err != nil || stdout != "secr****1234\n" {
+		t.Errorf("get api_token = %q, %v", stdout, err)
+	}
+	if _, _, err := run(func() error { return configGetCmd.RunE(testCommand(), []string{"email"}) }); err == nil || !strings.Contains(err.Error(), "email is not set") {
+		t.Errorf("get email error = %v, want not set", err)
+	}
+
+	stdout, _, err = run(func() error { return configListCmd.RunE(testCommand(), nil) })
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{
+		"Config file: " + path,
+		"base_url   https://example.atlassian.net/wiki  CONFLUENCE_BASE_URL",
+		"email      -                                   not set",
+		"api_token  secr****1234                        config file",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("list output missing %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "secret-token-1234") {
+		t.Error("list output shows the API token")
+	}
+}
+
+func TestConfigCmd_Profile(t *testing.T) {
+	resetPageFlags(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	withConfigFile(t, path)
+	t.Cleanup(func() { configProfile = "" })
+
+	configProfile = "client"
+	finish := captureStdStreams(t)
+	err := configSetCmd.RunE(testCommand(), []string{"base_url", "https://client.atlassian.net/wiki"})
+	stdout, _ := finish()
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if stdout != "Set base_url in profile client in "+path+"\n" {
+		t.Errorf("set stdout = %q", stdout)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "[profile.client]\nbase_url = \"https://client.atlassian.net/wiki\"\n" {
+		t.Errorf("config file = %q, %v", data, err)
+	}
+
+	finish = captureStdStreams(t)
+	err = configListCmd.RunE(testCommand(), nil)
+	stdout, _ = finish()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{"Profiles: client\n", "Profile: client\n", "https://client.atlassian.net/wiki  profile client"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("list output missing %q:\n%s", want, stdout)
+		}
+	}
+
+	configProfile = "missing"
+	finish = captureStdStreams(t)
+	err = configGetCmd.RunE(testCommand(), []string{"base_url"})
+	finish()
+	if err == nil || !strings.Contains(err.Error(), `no profile "missing"`) {
+		t.Errorf("get with a missing profile error = %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// force skips the confirmation of destructive commands
+var force bool
+
+const forceUsage = "Do not ask for confirmation"
+
+// confirm asks the question on stderr and reports whether the answer read
+// from stdin is yes. Without a terminal to answer from, it fails rather than
+// wait, so scripts must pass --force.
+func confirm(question string) (bool, error) {
+	stat, err := stdinStat()
+	if err != nil {
+		return false, fmt.Errorf("checking stdin: %w", err)
+	}
+	if stat.Mode()&os.ModeCharDevice == 0 {
+		return false, fmt.Errorf("confirmation required: use --force to proceed without a terminal")
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(stdinReader).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+// converterOptions builds converter options from the config profile that
+// applies to spaceKey, so output is tailored to the destination space.
+func converterOptions(cfg *config.Config, spaceKey string) converter.Options {
+	profile := cfg.ConverterProfileFor(spaceKey)
+	if verbose && len(profile.DisabledMacros) > 0 {
+		fmt.Fprintf(os.Stderr, "[Convert] Space %s: disabled macros %v\n", spaceKey, profile.DisabledMacros)
+	}
+	return converter.Options{
+		DisabledMacros:  profile.DisabledMacros,
+		MermaidMacro:    profile.MermaidMacro,
+		PlantUMLMacro:   profile.PlantUMLMacro,
+		PlantUMLServer:  profile.PlantUMLServer,
+		MathBlockMacro:  profile.MathBlockMacro,
+		MathInlineMacro: profile.MathInlineMacro,
+		Jira: converter.JiraOptions{
+			Projects: profile.JiraProjects,
+			Server:   profile.JiraServer,
+			ServerID: profile.JiraServerID,
+		},
+	}
+}
+
+// converterOptionsForSpaceID is converterOptions for callers that only know the
+// space ID. The space key is only looked up when per-space profiles exist.
+func converterOptionsForSpaceID(ctx context.Context, client *api.Client, cfg *config.Config, spaceID string) (converter.Options, error) {
+	if len(cfg.SpaceConverters) == 0 {
+		return converterOptions(cfg, ""), nil
+	}
+	space, err := client.GetSpaceByID(ctx, spaceID)
+	if err != nil {
+		return converter.Options{}, fmt.Errorf("resolving space for converter profile: %w", err)
+	}
+	return converterOptions(cfg, space.Key), nil
+}
+
+// applyConverterFlags copies the conversion flags of page create and update
+// into opts. The TOC levels also apply to "[TOC]" markers, so they are
+// honoured without --toc.
+func applyConverterFlags(opts *converter.Options) error {
+	for _, level := range []int{tocMin, tocMax} {
+		if level < 0 || level > 6 {
+			return fmt.Errorf("invalid TOC heading level %d (valid: 1-6)", level)
+		}
+	}
+	if tocMin > 0 && tocMax > 0 && tocMin > tocMax {
+		return fmt.Errorf("--toc-min-level %d is greater than --toc-max-level %d", tocMin, tocMax)
+	}
+	if headingOff < -5 || headingOff > 5 {
+		return fmt.Errorf("invalid heading offset %d (valid: -5 to 5)", headingOff)
+	}
+	opts.HeadingOffset = headingOff
+	opts.TOC = converter.TOCOptions{Insert: pageTOC, MinLevel: tocMin, MaxLevel: tocMax}
+	opts.AllowHTML = allowHTML
+	opts.BareDates = bareDates
+	opts.Strict = strict
+	opts.Typography = typography
+	opts.Subscript = subscript
+	opts.HardWraps = hardWraps
+	return nil
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+func TestConverterOptionsForSpaceID(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.Space{ID: "space-1", Key: "LEGACY"})
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	t.Run("no space profiles skips lookup", func(t *testing.T) {
+		hits.Store(0)
+		cfg := &config.Config{Converter: config.ConverterProfile{DisabledMacros: []string{"mermaid"}}}
+		opts, err := converterOptionsForSpaceID(context.Background(), client, cfg, "space-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(opts.DisabledMacros, []string{"mermaid"}) {
+			t.Errorf("DisabledMacros = %v, want [mermaid]", opts.DisabledMacros)
+		}
+		if hits.Load() != 0 {
+			t.Errorf("space lookups = %d, want 0", hits.Load())
+		}
+	})
+
+	t.Run("space profile selected by resolved key", func(t *testing.T) {
+		hits.Store(0)
+		cfg := &config.Config{SpaceConverters: map[string]config.ConverterProfile{
+			"LEGACY": {DisabledMacros: []string{"code"}},
+		}}
+		opts, err := converterOptionsForSpaceID(context.Background(), client, cfg, "space-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(opts.DisabledMacros, []string{"code"}) {
+			t.Errorf("DisabledMacros = %v, want [code]", opts.DisabledMacros)
+		}
+		if hits.Load() != 1 {
+			t.Errorf("space lookups = %d, want 1", hits.Load())
+		}
+	})
+}
+
+func TestApplyConverterFlags_TOC(t *testing.T) {
+	tests := []struct {
+		name    string
+		toc     bool
+		min     int
+		max     int
+		want    converter.TOCOptions
+		wantErr string
+	}{
+		{name: "defaults", want: converter.TOCOptions{}},
+		{name: "insert with levels", toc: true, min: 2, max: 4, want: converter.TOCOptions{Insert: true, MinLevel: 2, MaxLevel: 4}},
+		{name: "max only", max: 3, want: converter.TOCOptions{MaxLevel: 3}},
+		{name: "level out of range", max: 7, wantErr: "invalid TOC heading level 7"},
+		{name: "negative level", min: -1, wantErr: "invalid TOC heading level -1"},
+		{name: "min above max", min: 4, max: 2, wantErr: "--toc-min-level 4 is greater than --toc-max-level 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			pageTOC, tocMin, tocMax = tt.toc, tt.min, tt.max
+
+			var opts converter.Options
+			err := applyConverterFlags(&opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("applyConverterFlags() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyConverterFlags() error = %v", err)
+			}
+			if opts.TOC != tt.want {
+				t.Errorf("TOC = %+v, want %+v", opts.TOC, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConverterFlags_HeadingOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		offset  int
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "demote", offset: 1},
+		{name: "promote", offset: -2},
+		{name: "too large", offset: 6, wantErr: "invalid heading offset 6"},
+		{name: "too small", offset: -6, wantErr: "invalid heading offset -6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			headingOff = tt.offset
+
+			var opts converter.Options
+			err := applyConverterFlags(&opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("applyConverterFlags() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyConverterFlags() error = %v", err)
+			}
+			if opts.HeadingOffset != tt.offset {
+				t.Errorf("HeadingOffset = %d, want %d", opts.HeadingOffset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestApplyConverterFlags_AllowHTML(t *testing.T) {
+	resetPageFlags(t)
+	var opts converter.Options
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if opts.AllowHTML {
+		t.Error("AllowHTML = true without --allow-html")
+	}
+
+	allowHTML = true
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if !opts.AllowHTML {
+		t.Error("AllowHTML = false with --allow-html")
+	}
+}
+
+func TestApplyConverterFlags_Dates(t *testing.T) {
+	resetPageFlags(t)
+	var opts converter.Options
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if opts.BareDates {
+		t.Error("BareDates = true without --dates")
+	}
+
+	bareDates = true
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if !opts.BareDates {
+		t.Error("BareDates = false with --dates")
+	}
+}
+
+func TestApplyConverterFlags_Strict(t *testing.T) {
+	resetPageFlags(t)
+	var opts converter.Options
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if opts.Strict {
+		t.Error("Strict = true without --strict")
+	}
+
+	strict = true
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if !opts.Strict {
+		t.Error("Strict = false with --strict")
+	}
+}
+
+func TestApplyConverterFlags_Typography(t *testing.T) {
+	resetPageFlags(t)
+	var opts converter.Options
+	typography = true
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if !opts.Typography {
+		t.Error("Typography = false with --typography")
+	}
+}
+
+func TestApplyConverterFlags_Subscript(t *testing.T) {
+	resetPageFlags(t)
+	var opts converter.Options
+	subscript = true
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if !opts.Subscript {
+		t.Error("Subscript = false with --subscript")
+	}
+}
+
+func TestApplyConverterFlags_HardWraps(t *testing.T) {
+	resetPageFlags(t)
+	var opts converter.Options
+	hardWraps = true
+	if err := applyConverterFlags(&opts); err != nil {
+		t.Fatalf("applyConverterFlags() error = %v", err)
+	}
+	if !opts.HardWraps {
+		t.Error("HardWraps = false with --hard-wraps")
+	}
+}
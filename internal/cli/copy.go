@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyRecursive bool
+	copyPrefix    string
+)
+
+// copiedPage is a page created by page copy
+type copiedPage struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	SourceID string `json:"sourceId"`
+	ParentID string `json:"parentId"`
+}
+
+// pageCopier copies pages under a new parent
+type pageCopier struct {
+	ctx      context.Context
+	client   *api.Client
+	baseURL  string
+	spaceKey string
+	copied   []copiedPage
+}
+
+var pageCopyCmd = &cobra.Command{
+	Use:   "copy PAGE_ID",
+	Short: "Copy a page, or a page tree, under a new parent",
+	Long: `Copy a page, with its attachments, labels, and properties, to be a child of
+the --parent page, which may be in another space. With --recursive, the
+pages below it are copied too, keeping their order in the tree.
+
+Titles must be unique within a space, so copies within the same space need
+a --prefix, such as "Copy of ", which is put before every copied title.
+Content other than pages, such as folders, is not copied.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &pageParent); err != nil {
+			return err
+		}
+		if pageParent == "" {
+			return fmt.Errorf("--parent flag is required")
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		source, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		parent, err := client.GetPage(cmd.Context(), pageParent)
+		if err != nil {
+			return fmt.Errorf("getting parent page: %w", err)
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), parent.SpaceID)
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+
+		root := &treeNode{ID: source.ID, Title: source.Title, Type: "page"}
+		if copyRecursive {
+			if err := addDescendants(cmd.Context(), client, root, 0); err != nil {
+				return err
+			}
+		}
+
+		c := &pageCopier{ctx: cmd.Context(), client: client, baseURL: cfg.BaseURL, spaceKey: space.Key}
+		if err := c.copy(root, parent.ID); err != nil {
+			if len(c.copied) > 0 {
+				return fmt.Errorf("%w (%d pages were copied before the failure)", err, len(c.copied))
+			}
+			return err
+		}
+
+		if outputJSON {
+			return printJSON(c.copied)
+		}
+		return nil
+	},
+}
+
+// copy copies the page node to be a child of parentID, then the pages
+// below it under the copy
+func (c *pageCopier) copy(node *treeNode, parentID string) error {
+	if node.Type != "page" {
+		fmt.Fprintf(os.Stderr, "Warning: skipping %s %q (%s) and the content below it: only pages can be copied\n", node.Type, node.Title, node.ID)
+		return nil
+	}
+	title := copyPrefix + node.Title
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Copy] Copying %s to %q under %s\n", node.ID, title, parentID)
+	}
+	page, err := c.client.CopyPage(c.ctx, node.ID, parentID, title)
+	if err != nil {
+		return fmt.Errorf("copying page %s: %w", node.ID, err)
+	}
+	c.copied = append(c.copied, copiedPage{ID: page.ID, Title: page.Title, SourceID: node.ID, ParentID: parentID})
+	if !outputJSON {
+		fmt.Println(pageURL(c.baseURL, c.spaceKey, page.ID))
+	}
+
+	for _, child := range node.Children {
+		if err := c.copy(child, page.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	pageCopyCmd.Flags().StringVarP(&pageParent, "parent", "p", "", "Parent page ID for the copy (required)")
+	pageCopyCmd.Flags().BoolVarP(&copyRecursive, "recursive", "r", false, "Copy the pages below it too")
+	pageCopyCmd.Flags().StringVar(&copyPrefix, "prefix", "", "Text to put before each copied title, such as \"Copy of \"")
+	pageCopyCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	pageCmd.AddCommand(pageCopyCmd)
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageCopyCmd(t *testing.T) {
+	var copies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/wiki/api/v2/pages/1":
+			_, _ = w.Write([]byte(`{"id":"1","spaceId":"s1","title":"Guide"}`))
+		case r.URL.Path == "/wiki/api/v2/pages/50":
+			_, _ = w.Write([]byte(`{"id":"50","spaceId":"s2","title":"Archive"}`))
+		case r.URL.Path == "/wiki/api/v2/spaces/s2":
+			_, _ = w.Write([]byte(`{"id":"s2","key":"OTHER"}`))
+		case r.URL.Path == "/wiki/api/v2/pages/1/descendants":
+			_, _ = w.Write([]byte(`{"results":[
+				{"id":"3","title":"Notes","type":"folder","parentId":"1","depth":1,"childPosition":1},
+				{"id":"2","title":"Install","type":"page","parentId":"1","depth":1,"childPosition":0},
+				{"id":"4","title":"Draft","type":"page","parentId":"3","depth":2,"childPosition":0},
+				{"id":"5","title":"Linux","type":"page","parentId":"2","depth":2,"childPosition":0}]}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/copy"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/wiki/rest/api/content/"), "/copy")
+			var body struct {
+				Destination struct{ Value string } `json:"destination"`
+				PageTitle   string                 `json:"pageTitle"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding copy request: %v", err)
+			}
+			copies = append(copies, fmt.Sprintf("%s->%s %s", id, body.Destination.Value, body.PageTitle))
+			_, _ = fmt.Fprintf(w, `{"id":"c%s","title":%q}`, id, body.PageTitle)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+	reset := func() {
+		copyRecursive = false
+		copyPrefix = ""
+	}
+	t.Cleanup(reset)
+
+	tests := []struct {
+		name       string
+		setup      func()
+		wantCopies []string
+		wantOut    string
+		wantErr    string
+	}{
+		{
+			name:       "single page",
+			setup:      func() { pageParent = "50" },
+			wantCopies: []string{"1->50 Guide"},
+			wantOut:    server.URL + "/wiki/spaces/OTHER/pages/c1\n",
+		},
+		{
+			name:       "recursive with prefix",
+			setup:      func() { pageParent = "50"; copyRecursive = true; copyPrefix = "Copy of " },
+			wantCopies: []string{"1->50 Copy of Guide", "2->c1 Copy of Install", "5->c2 Copy of Linux"},
+			wantOut: server.URL + "/wiki/spaces/OTHER/pages/c1\n" +
+				server.URL + "/wiki/spaces/OTHER/pages/c2\n" +
+				server.URL + "/wiki/spaces/OTHER/pages/c5\n",
+		},
+		{
+			name:    "parent required",
+			wantErr: "--parent flag is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			reset()
+			copies = nil
+			if tt.setup != nil {
+				tt.setup()
+			}
+			finish := captureStdStreams(t)
+			runErr := pageCopyCmd.RunE(testCommand(), []string{"1"})
+			stdout, stderr := finish()
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Fatalf("RunE error = %v, want %q", runErr, tt.wantErr)
+				}
+				return
+			}
+			if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if strings.Join(copies, "\n") != strings.Join(tt.wantCopies, "\n") {
+				t.Errorf("copies = %q, want %q", copies, tt.wantCopies)
+			}
+			if stdout != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout, tt.wantOut)
+			}
+			if copyRecursive && !strings.Contains(stderr, `skipping folder "Notes"`) {
+				t.Errorf("stderr = %q, want folder warning", stderr)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/grantcarthew/acon/internal/diff"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +26,10 @@ var debugMdCmd = &cobra.Command{
 			return fmt.Errorf("reading stdin: %w", err)
 		}
 
-		storage := converter.MarkdownToStorage(string(markdown))
+		storage, err := converter.MarkdownToStorage(string(markdown))
+		if err != nil {
+			return fmt.Errorf("converting markdown: %w", err)
+		}
 		fmt.Println(storage)
 		return nil
 	},
@@ -33,17 +39,118 @@ var debugStorageCmd = &cobra.Command{
 	Use:   "storage",
 	Short: "Convert storage format to markdown",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		storage, err := io.ReadAll(os.Stdin)
+		// Streamed, so large pages convert a block at a time
+		out := bufio.NewWriter(os.Stdout)
+		if err := converter.StorageToMarkdownStream(out, os.Stdin, storageOptions()); err != nil {
+			return fmt.Errorf("converting storage to markdown: %w", err)
+		}
+		fmt.Fprintln(out)
+		return out.Flush()
+	},
+}
+
+var debugValidateCmd = &cobra.Command{
+	Use:   "validate [PAGE_ID]",
+	Short: "Check storage format for problems",
+	Long: `Check storage format read from stdin, or the body of a page, for problems
+Confluence would reject: malformed XML such as an unescaped & or an
+unterminated CDATA section, and elements the Cloud editor does not accept
+or that are out of place. Each problem is printed as line:column: message.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var storage string
+		if len(args) == 1 {
+			client, _, err := initClient()
+			if err != nil {
+				return err
+			}
+			pageID, err := pageIDArg(args[0])
+			if err != nil {
+				return err
+			}
+			page, err := client.GetPage(cmd.Context(), pageID)
+			if err != nil {
+				return fmt.Errorf("getting page: %w", err)
+			}
+			if page.Body == nil || page.Body.Storage == nil {
+				return fmt.Errorf("page %s has no storage format body", page.ID)
+			}
+			storage = page.Body.Storage.Value
+		} else {
+			data, err := io.ReadAll(stdinReader)
+			if err != nil {
+				return fmt.Errorf("reading stdin: %w", err)
+			}
+			storage = string(data)
+		}
+
+		problems := converter.CheckStorage(storage)
+		if len(problems) == 0 {
+			fmt.Println("Storage format is valid")
+			return nil
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		// Problems are the output, not a misuse, so fail without usage
+		cmd.SilenceUsage = true
+		if len(problems) == 1 {
+			return fmt.Errorf("1 problem found")
+		}
+		return fmt.Errorf("%d problems found", len(problems))
+	},
+}
+
+var debugRoundtripCmd = &cobra.Command{
+	Use:   "roundtrip [FILE]",
+	Short: "Show what Markdown loses on a round trip through storage format",
+	Long: `Convert Markdown, read from FILE or stdin, to storage format and back, as
+page create and page view --round-trip would, and print a unified diff of
+the lines lost or altered. Frontmatter is left out of the comparison. The
+command fails if the Markdown does not come back unchanged.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := "stdin"
+		var data []byte
+		var err error
+		if len(args) == 1 {
+			name = args[0]
+			data, err = os.ReadFile(name)
+		} else {
+			data, err = io.ReadAll(stdinReader)
+		}
 		if err != nil {
-			return fmt.Errorf("reading stdin: %w", err)
+			return fmt.Errorf("reading markdown: %w", err)
 		}
+		_, body, err := converter.SplitFrontmatter(data)
+		if err != nil {
+			return err
+		}
+		markdown := strings.TrimSpace(string(body))
 
-		markdown, err := converter.StorageToMarkdown(string(storage))
+		storage, err := converter.MarkdownToStorage(markdown)
+		if err != nil {
+			return fmt.Errorf("converting markdown: %w", err)
+		}
+		opts := storageOptions()
+		opts.RoundTrip = true
+		result, err := converter.StorageToMarkdownWithOptions(storage, opts)
 		if err != nil {
 			return fmt.Errorf("converting storage to markdown: %w", err)
 		}
-		fmt.Println(markdown)
-		return nil
+
+		unified := diff.Unified(name, "round trip", markdown, strings.TrimSpace(result))
+		if unified == "" {
+			fmt.Println("Round trip is lossless")
+			return nil
+		}
+		fmt.Print(unified)
+		// Differences are the output, not a misuse, so fail without usage
+		cmd.SilenceUsage = true
+		if n := diff.Hunks(unified); n != 1 {
+			return fmt.Errorf("%d differences found", n)
+		}
+		return fmt.Errorf("1 difference found")
 	},
 }
 
@@ -52,4 +159,13 @@ func init() {
 	rootCmd.AddCommand(debugCmd)
 	debugCmd.AddCommand(debugMdCmd)
 	debugCmd.AddCommand(debugStorageCmd)
+	debugCmd.AddCommand(debugValidateCmd)
+	debugCmd.AddCommand(debugRoundtripCmd)
+
+	debugStorageCmd.Flags().BoolVar(&admonitions, "admonitions", false, "Write panels as ::: admonitions instead of GitHub alerts")
+	debugStorageCmd.Flags().BoolVar(&roundTrip, "round-trip", false, "Write macros as shortcodes such as {status:...} that publish back unchanged")
+	debugStorageCmd.Flags().BoolVar(&keepHTML, "keep-html", false, "Keep merged table cells and coloured text as inline HTML")
+
+	debugRoundtripCmd.Flags().BoolVar(&admonitions, "admonitions", false, "Write panels as ::: admonitions instead of GitHub alerts")
+	debugRoundtripCmd.Flags().BoolVar(&keepHTML, "keep-html", false, "Keep merged table cells and coloured text as inline HTML")
 }
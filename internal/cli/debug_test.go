@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestDebugValidateCmd_Stdin(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "valid", input: "<p>Hello</p>", want: "Storage format is valid"},
+		{name: "one problem", input: "<p>a & b</p>", want: "1:7: invalid character entity & (no semicolon)", wantErr: "1 problem found"},
+		{
+			name:    "several problems",
+			input:   "<script>x</script>\n<p><blink>y</blink></p>",
+			want:    "1:1: unsupported element <script>\n2:4: unsupported element <blink>",
+			wantErr: "2 problems found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := stdinReader
+			stdinReader = strings.NewReader(tt.input)
+			t.Cleanup(func() { stdinReader = prev })
+
+			finish := captureStdStreams(t)
+			runErr := debugValidateCmd.RunE(testCommand(), nil)
+			stdout, _ := finish()
+
+			if strings.TrimSpace(stdout) != tt.want {
+				t.Errorf("stdout = %q, want %q", stdout, tt.want)
+			}
+			if tt.wantErr == "" && runErr != nil {
+				t.Errorf("RunE returned error: %v", runErr)
+			}
+			if tt.wantErr != "" && (runErr == nil || runErr.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", runErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDebugValidateCmd_Page(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/api/v2/pages/123" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.Page{
+			ID:   "123",
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: "<p>ok</p><ac:widget />"}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := debugValidateCmd.RunE(testCommand(), []string{"123"})
+	stdout, _ := finish()
+
+	if runErr == nil || runErr.Error() != "1 problem found" {
+		t.Errorf("error = %v, want 1 problem found", runErr)
+	}
+	if strings.TrimSpace(stdout) != "1:10: unsupported element <ac:widget>" {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
+
+func TestDebugRoundtripCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "lossless", input: "# Title\n\nSome *text* and **bold**.\n", want: "Round trip is lossless"},
+		{name: "frontmatter left out", input: "---\ntitle: T\n---\n\n- item\n", want: "Round trip is lossless"},
+		{
+			name:    "altered lines",
+			input:   "# Title\n\n* item\n",
+			want:    "--- stdin\n+++ round trip\n@@ -1,3 +1,3 @@\n # Title\n \n-* item\n+- item",
+			wantErr: "1 difference found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := stdinReader
+			stdinReader = strings.NewReader(tt.input)
+			t.Cleanup(func() { stdinReader = prev })
+
+			cmd := testCommand()
+			finish := captureStdStreams(t)
+			runErr := debugRoundtripCmd.RunE(cmd, nil)
+			stdout, _ := finish()
+
+			if strings.TrimSpace(stdout) != tt.want {
+				t.Errorf("stdout = %q, want %q", stdout, tt.want)
+			}
+			if tt.wantErr == "" && runErr != nil {
+				t.Errorf("RunE returned error: %v", runErr)
+			}
+			if tt.wantErr != "" && (runErr == nil || runErr.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", runErr, tt.wantErr)
+			}
+			if tt.wantErr != "" && !cmd.SilenceUsage {
+				t.Error("differences found would print usage")
+			}
+		})
+	}
+}
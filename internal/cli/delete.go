@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+var deleteRecursive bool
+
+// deletePageTree deletes a page and every page below it, deepest first, so
+// no page is deleted while it still has children
+func deletePageTree(ctx context.Context, client *api.Client, cfg *config.Config, pageID string) error {
+	page, err := client.GetPage(ctx, pageID)
+	if err != nil {
+		return fmt.Errorf("getting page: %w", err)
+	}
+	root := &treeNode{ID: page.ID, Title: page.Title, Type: "page"}
+	if err := addDescendants(ctx, client, root, 0); err != nil {
+		return err
+	}
+
+	var pages []*treeNode
+	var others []string
+	collectLeafFirst(root, &pages, &others)
+	if len(others) > 0 {
+		return fmt.Errorf("page %s has content other than pages below it, which acon cannot delete: %s", pageID, strings.Join(others, ", "))
+	}
+	for _, p := range pages {
+		if err := checkPageProtection(ctx, client, cfg, p.ID, page.SpaceID, "delete"); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		for _, p := range pages {
+			fmt.Printf("Would delete page %s %q\n", p.ID, p.Title)
+		}
+		return nil
+	}
+	if !force {
+		below := len(pages) - 1
+		pageWord := "pages"
+		if below == 1 {
+			pageWord = "page"
+		}
+		ok, err := confirm(fmt.Sprintf("Delete page %s %q and the %d %s below it?", page.ID, page.Title, below, pageWord))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("page %s not deleted", pageID)
+		}
+	}
+
+	for i, p := range pages {
+		if err := client.DeletePage(ctx, p.ID); err != nil {
+			return fmt.Errorf("deleting page %s (%d of %d pages deleted): %w", p.ID, i, len(pages), err)
+		}
+		fmt.Printf("Deleted page %s %q (%d/%d)\n", p.ID, p.Title, i+1, len(pages))
+	}
+	return nil
+}
+
+// collectLeafFirst appends the pages of the tree under node to pages, each
+// after the pages below it, and describes any other content in others
+func collectLeafFirst(node *treeNode, pages *[]*treeNode, others *[]string) {
+	for _, child := range node.Children {
+		collectLeafFirst(child, pages, others)
+	}
+	if node.Type != "page" {
+		*others = append(*others, fmt.Sprintf("%s %q (%s)", node.Type, node.Title, node.ID))
+		return
+	}
+	*pages = append(*pages, node)
+}
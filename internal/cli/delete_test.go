@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageDeleteCmd_Recursive(t *testing.T) {
+	const pages = `{"results":[
+		{"id":"2","title":"Install","type":"page","parentId":"1","depth":1,"childPosition":0},
+		{"id":"3","title":"FAQ","type":"page","parentId":"1","depth":1,"childPosition":1},
+		{"id":"4","title":"Linux","type":"page","parentId":"2","depth":2,"childPosition":0}]}`
+
+	tests := []struct {
+		name        string
+		descendants string
+		setup       func()
+		input       string
+		wantOut     string
+		wantErr     string
+		wantDeletes []string
+	}{
+		{
+			name:        "force",
+			descendants: pages,
+			setup:       func() { force = true },
+			wantOut: "Deleted page 4 \"Linux\" (1/4)\nDeleted page 2 \"Install\" (2/4)\n" +
+				"Deleted page 3 \"FAQ\" (3/4)\nDeleted page 1 \"Home\" (4/4)\n",
+			wantDeletes: []string{"4", "2", "3", "1"},
+		},
+		{
+			name:        "confirmed",
+			descendants: pages,
+			input:       "y\n",
+			wantOut: "Deleted page 4 \"Linux\" (1/4)\nDeleted page 2 \"Install\" (2/4)\n" +
+				"Deleted page 3 \"FAQ\" (3/4)\nDeleted page 1 \"Home\" (4/4)\n",
+			wantDeletes: []string{"4", "2", "3", "1"},
+		},
+		{
+			name:        "declined",
+			descendants: pages,
+			input:       "n\n",
+			wantErr:     "page 1 not deleted",
+		},
+		{
+			name:        "dry run",
+			descendants: pages,
+			setup:       func() { dryRun = true },
+			wantOut: "Would delete page 4 \"Linux\"\nWould delete page 2 \"Install\"\n" +
+				"Would delete page 3 \"FAQ\"\nWould delete page 1 \"Home\"\n",
+		},
+		{
+			name: "folder below",
+			descendants: `{"results":[
+				{"id":"2","title":"Install","type":"page","parentId":"1","depth":1,"childPosition":0},
+				{"id":"5","title":"Notes","type":"folder","parentId":"2","depth":2,"childPosition":0}]}`,
+			setup:   func() { force = true },
+			wantErr: `folder "Notes" (5)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			deleteRecursive = true
+			if tt.setup != nil {
+				tt.setup()
+			}
+			withStdin(t, strings.NewReader(tt.input), os.ModeCharDevice, nil)
+
+			var mu sync.Mutex
+			var deletes []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodDelete:
+					mu.Lock()
+					deletes = append(deletes, strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/"))
+					mu.Unlock()
+					w.WriteHeader(http.StatusNoContent)
+				case r.URL.Path == "/wiki/api/v2/pages/1":
+					_, _ = w.Write([]byte(`{"id":"1","spaceId":"s1","title":"Home"}`))
+				case r.URL.Path == "/wiki/api/v2/pages/1/descendants":
+					_, _ = w.Write([]byte(tt.descendants))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client, err := api.NewClient(server.URL, "e@x", "t")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+			finish := captureStdStreams(t)
+			runErr := pageDeleteCmd.RunE(testCommand(), []string{"1"})
+			stdout, _ := finish()
+
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want containing %q", runErr, tt.wantErr)
+				}
+			} else if runErr != nil {
+				t.Fatalf("unexpected error: %v", runErr)
+			}
+			if stdout != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout, tt.wantOut)
+			}
+			if strings.Join(deletes, ",") != strings.Join(tt.wantDeletes, ",") {
+				t.Errorf("deletes = %v, want %v", deletes, tt.wantDeletes)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+var (
+	renderDiagrams bool
+	diagramFormat  string
+)
+
+// mermaidCommand is the Mermaid CLI used by --render-diagrams. Override in tests.
+var mermaidCommand = "mmdc"
+
+// diagramRenderer renders diagram fences with local tools and queues the
+// images for upload once the target page exists.
+type diagramRenderer struct {
+	ctx    context.Context
+	format string
+	queue  *attachments
+}
+
+// attachDiagramRenderer installs a diagram renderer that queues images in a
+// when --render-diagrams is set. Otherwise diagrams are left as macros.
+func attachDiagramRenderer(ctx context.Context, doc *converter.Document, a *attachments) error {
+	if !renderDiagrams {
+		return nil
+	}
+	if diagramFormat != "svg" && diagramFormat != "png" {
+		return fmt.Errorf("invalid diagram format '%s' (valid: svg, png)", diagramFormat)
+	}
+	d := &diagramRenderer{ctx: ctx, format: diagramFormat, queue: a}
+	doc.RenderDiagram = d.render
+	return nil
+}
+
+// render implements converter.DiagramRenderer. Images are named by a hash of
+// their source, so an unchanged diagram keeps its attachment across updates.
+// Failures are recorded in the queue for the caller to report. Languages with
+// no local renderer are declined, leaving the converter to use their macro.
+func (d *diagramRenderer) render(lang string, source []byte) (string, error) {
+	if lang != "mermaid" {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Diagram] No local renderer for %s, keeping the macro\n", lang)
+		}
+		return "", fmt.Errorf("no local renderer for %s diagrams", lang)
+	}
+
+	sum := sha256.Sum256(source)
+	filename := fmt.Sprintf("%s-%x.%s", lang, sum[:6], d.format)
+	if d.queue.has(filename) {
+		return filename, nil
+	}
+
+	data, err := renderMermaid(d.ctx, source, d.format)
+	if err == nil {
+		err = d.queue.add(filename, data)
+	}
+	if err != nil {
+		d.queue.fail(fmt.Errorf("rendering %s diagram: %w", lang, err))
+		return "", err
+	}
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Diagram] Rendered %s (%d bytes)\n", filename, len(data))
+	}
+	return filename, nil
+}
+
+// renderMermaid renders Mermaid source to an image with the Mermaid CLI.
+func renderMermaid(ctx context.Context, source []byte, format string) ([]byte, error) {
+	path, err := exec.LookPath(mermaidCommand)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found: install @mermaid-js/mermaid-cli or omit --render-diagrams", mermaidCommand)
+	}
+
+	dir, err := os.MkdirTemp("", "acon-mermaid-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "diagram.mmd")
+	out := filepath.Join(dir, "diagram."+format)
+	if err := os.WriteFile(in, source, 0o600); err != nil {
+		return nil, fmt.Errorf("writing diagram source: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, path, "--quiet", "--input", in, "--output", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", mermaidCommand, err, strings.TrimSpace(string(output)))
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		return nil, fmt.Errorf("reading rendered diagram: %w", err)
+	}
+	return data, nil
+}
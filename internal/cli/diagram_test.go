@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+// fakeMermaid installs a stand-in for mmdc that writes the diagram source,
+// prefixed with "rendered:", to the --output path.
+func fakeMermaid(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake mmdc is a shell script")
+	}
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --input) in="$2"; shift ;;
+    --output) out="$2"; shift ;;
+  esac
+  shift
+done
+grep -q fail "$in" && { echo "parse error" >&2; exit 1; }
+{ printf 'rendered:'; cat "$in"; } > "$out"
+`
+	path := filepath.Join(t.TempDir(), "mmdc")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := mermaidCommand
+	mermaidCommand = path
+	t.Cleanup(func() { mermaidCommand = old })
+}
+
+func TestAttachDiagramRenderer(t *testing.T) {
+	resetPageFlags(t)
+
+	var doc converter.Document
+	if err := attachDiagramRenderer(context.Background(), &doc, &attachments{}); err != nil || doc.RenderDiagram != nil {
+		t.Fatalf("without --render-diagrams got renderer = %v, err = %v", doc.RenderDiagram != nil, err)
+	}
+
+	renderDiagrams = true
+	diagramFormat = "gif"
+	if err := attachDiagramRenderer(context.Background(), &doc, &attachments{}); err == nil || !strings.Contains(err.Error(), "invalid diagram format") {
+		t.Errorf("error = %v, want invalid diagram format", err)
+	}
+
+	diagramFormat = "png"
+	if err := attachDiagramRenderer(context.Background(), &doc, &attachments{}); err != nil || doc.RenderDiagram == nil {
+		t.Fatalf("with --render-diagrams got renderer = %v, err = %v", doc.RenderDiagram != nil, err)
+	}
+}
+
+func TestDiagramRenderer_Render(t *testing.T) {
+	fakeMermaid(t)
+	queue := &attachments{}
+	d := &diagramRenderer{ctx: context.Background(), format: "svg", queue: queue}
+
+	name, err := d.render("mermaid", []byte("graph TD; A-->B"))
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if !strings.HasPrefix(name, "mermaid-") || !strings.HasSuffix(name, ".svg") {
+		t.Errorf("filename = %q", name)
+	}
+	if len(queue.files) != 1 || string(queue.files[0].data) != "rendered:graph TD; A-->B" {
+		t.Fatalf("files = %+v", queue.files)
+	}
+
+	// The same source is rendered once and keeps its name
+	again, err := d.render("mermaid", []byte("graph TD; A-->B"))
+	if err != nil || again != name || len(queue.files) != 1 {
+		t.Errorf("second render = %q, %v (%d files)", again, err, len(queue.files))
+	}
+
+	if _, err := d.render("mermaid", []byte("fail")); err == nil {
+		t.Fatal("render() expected error")
+	}
+	if queue.err == nil || !strings.Contains(queue.err.Error(), "parse error") {
+		t.Errorf("recorded error = %v, want mmdc output", queue.err)
+	}
+}
+
+func TestDiagramRenderer_DeclinesOtherLanguages(t *testing.T) {
+	queue := &attachments{}
+	d := &diagramRenderer{ctx: context.Background(), format: "svg", queue: queue}
+
+	if _, err := d.render("plantuml", []byte("A -> B")); err == nil {
+		t.Fatal("render() expected error for plantuml")
+	}
+	if queue.err != nil || len(queue.files) != 0 {
+		t.Errorf("declined diagram recorded err = %v, files = %d", queue.err, len(queue.files))
+	}
+}
+
+func TestRenderMermaid_MissingCommand(t *testing.T) {
+	old := mermaidCommand
+	mermaidCommand = "acon-test-no-such-mmdc"
+	t.Cleanup(func() { mermaidCommand = old })
+
+	_, err := renderMermaid(context.Background(), []byte("graph TD"), "svg")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("error = %v, want not found", err)
+	}
+}
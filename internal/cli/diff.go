@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/grantcarthew/acon/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffColor string
+
+	// stdoutStat returns stdout file info. Override in tests.
+	stdoutStat func() (os.FileInfo, error) = func() (os.FileInfo, error) { return os.Stdout.Stat() }
+)
+
+// ANSI escapes for diff output
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiCyan  = "\033[36m"
+)
+
+var pageDiffCmd = &cobra.Command{
+	Use:   "diff PAGE_ID",
+	Short: "Show how a markdown file differs from a page",
+	Long: `Convert the page body to markdown, as page view does, and print a unified
+diff of it against a markdown file, read from --file or stdin, to review
+what page update would change. Frontmatter is left out of the comparison.
+
+Pass the flags the file was written with, such as --round-trip for files
+from page export --round-trip, so that unchanged content compares equal.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		color, err := useColor(diffColor)
+		if err != nil {
+			return err
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		content, err := readAndValidateContent(pageFile)
+		if err != nil {
+			return err
+		}
+		_, body, err := converter.SplitFrontmatter(content)
+		if err != nil {
+			return err
+		}
+
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		page, err := client.GetPageADF(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		remote, err := pageMarkdown(cmd.Context(), client, cfg.BaseURL, page, storageOptions(), "Page Diff")
+		if err != nil {
+			return fmt.Errorf("converting page to markdown: %w", err)
+		}
+
+		remoteName := "page " + page.ID
+		if page.Version != nil {
+			remoteName = fmt.Sprintf("page %s (version %d)", page.ID, page.Version.Number)
+		}
+		localName := pageFile
+		if localName == "" || localName == "-" {
+			localName = "stdin"
+		}
+		unified := diff.Unified(remoteName, localName, strings.TrimSpace(remote), strings.TrimSpace(string(body)))
+		if unified == "" {
+			fmt.Println("No differences")
+			return nil
+		}
+		if color {
+			unified = colorDiff(unified)
+		}
+		fmt.Print(unified)
+		return nil
+	},
+}
+
+// useColor reports whether to colour output for mode: always, never, or
+// auto, which colours it when stdout is a terminal and NO_COLOR is unset
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		stat, err := stdoutStat()
+		if err != nil {
+			return false, nil
+		}
+		return stat.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("invalid --color %q: use auto, always, or never", mode)
+	}
+}
+
+// colorDiff colours the lines of a unified diff: file headers bold, hunk
+// headers cyan, removed lines red, and added lines green
+func colorDiff(unified string) string {
+	lines := strings.SplitAfter(unified, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		var code string
+		switch {
+		case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+			code = ansiBold
+		case strings.HasPrefix(text, "@@"):
+			code = ansiCyan
+		case strings.HasPrefix(text, "-"):
+			code = ansiRed
+		case strings.HasPrefix(text, "+"):
+			code = ansiGreen
+		}
+		if code == "" {
+			b.WriteString(line)
+			continue
+		}
+		b.WriteString(code + text + ansiReset + line[len(text):])
+	}
+	return b.String()
+}
+
+func init() {
+	pageDiffCmd.Flags().StringVarP(&pageFile, "file", "f", "", "Markdown file, or - for stdin")
+	pageDiffCmd.Flags().StringVar(&diffColor, "color", "auto", "Colour the diff: auto, always, never")
+	pageDiffCmd.Flags().BoolVar(&admonitions, "admonitions", false, "Write panels as ::: admonitions instead of GitHub alerts")
+	pageDiffCmd.Flags().BoolVar(&roundTrip, "round-trip", false, "Write macros as shortcodes such as {status:...} that publish back unchanged")
+	pageDiffCmd.Flags().BoolVar(&keepHTML, "keep-html", false, "Keep merged table cells and coloured text as inline HTML")
+
+	pageCmd.AddCommand(pageDiffCmd)
+}
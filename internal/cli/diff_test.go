@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageDiffCmd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/api/v2/pages/1" {
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","title":"Home","version":{"number":3},
+			"body":{"storage":{"value":"<h1>Home</h1><p>Hello</p><p>Bye</p>"}}}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+	t.Cleanup(func() { diffColor = "auto" })
+
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.md")
+	same := filepath.Join(dir, "same.md")
+	if err := os.WriteFile(changed, []byte("---\ntitle: Home\n---\n# Home\n\nHello world\n\nBye\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(same, []byte("# Home\n\nHello\n\nBye\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		file  string
+		color string
+		want  string
+	}{
+		{
+			name:  "changed",
+			file:  changed,
+			color: "never",
+			want:  "--- page 1 (version 3)\n+++ " + changed + "\n@@ -1,5 +1,5 @@\n # Home\n \n-Hello\n+Hello world\n \n Bye\n",
+		},
+		{
+			name:  "colored",
+			file:  changed,
+			color: "always",
+			want: "\033[1m--- page 1 (version 3)\033[0m\n\033[1m+++ " + changed + "\033[0m\n\033[36m@@ -1,5 +1,5 @@\033[0m\n # Home\n \n" +
+				"\033[31m-Hello\033[0m\n\033[32m+Hello world\033[0m\n \n Bye\n",
+		},
+		{
+			name:  "same",
+			file:  same,
+			color: "always",
+			want:  "No differences\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			pageFile = tt.file
+			diffColor = tt.color
+			finish := captureStdStreams(t)
+			runErr := pageDiffCmd.RunE(testCommand(), []string{"1"})
+			stdout, _ := finish()
+			if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if stdout != tt.want {
+				t.Errorf("stdout = %q, want %q", stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	origStat := stdoutStat
+	t.Cleanup(func() { stdoutStat = origStat })
+
+	tests := []struct {
+		name    string
+		mode    string
+		tty     bool
+		noColor string
+		want    bool
+		wantErr bool
+	}{
+		{name: "always", mode: "always", want: true},
+		{name: "never", mode: "never", tty: true, want: false},
+		{name: "auto terminal", mode: "auto", tty: true, want: true},
+		{name: "auto pipe", mode: "auto", want: false},
+		{name: "auto NO_COLOR", mode: "auto", tty: true, noColor: "1", want: false},
+		{name: "invalid", mode: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			var mode os.FileMode
+			if tt.tty {
+				mode = os.ModeCharDevice
+			}
+			stdoutStat = func() (os.FileInfo, error) { return fakeFileInfo{mode: mode}, nil }
+			got, err := useColor(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("useColor(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("useColor(%q) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/grantcarthew/acon/internal/api"
+)
+
+// dryRun makes commands that change pages report what they would change
+// instead. Reads still go ahead, so the report reflects the pages as they
+// are.
+var dryRun bool
+
+const dryRunUsage = "Show what would change without changing anything"
+
+// storageSize returns the size of a page's storage format body
+func storageSize(page *api.Page) int {
+	if page.Body == nil || page.Body.Storage == nil {
+		return 0
+	}
+	return len(page.Body.Storage.Value)
+}
+
+// printDryRunCreate reports the page, from createPage with --dry-run, that
+// would be created in the space spaceKey
+func printDryRunCreate(page *api.Page, spaceKey string) {
+	where := "at the top of space " + spaceKey
+	if page.ParentID != "" {
+		where = fmt.Sprintf("in space %s under page %s", spaceKey, page.ParentID)
+	}
+	fmt.Printf("Would create page %q %s (%d bytes of storage)\n", page.Title, where, storageSize(page))
+}
+
+// printDryRunUpdate reports the version of existing, from updatePage with
+// --dry-run, that would be made
+func printDryRunUpdate(existing, page *api.Page) {
+	line := fmt.Sprintf("Would update page %s %q to version %d (%d bytes of storage)", page.ID, page.Title, page.Version.Number, storageSize(page))
+	if page.Title != existing.Title {
+		line += fmt.Sprintf(", renaming it from %q", existing.Title)
+	}
+	if page.ParentID != existing.ParentID {
+		line += ", moving it under page " + page.ParentID
+	}
+	if existing.Status != "" && page.Status != existing.Status {
+		line += fmt.Sprintf(", changing its status from %s to %s", existing.Status, page.Status)
+	}
+	fmt.Println(line)
+}
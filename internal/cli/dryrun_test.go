@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestDryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("dry run made a %s request to %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/api/v2/spaces":
+			_, _ = w.Write([]byte(`{"results":[{"id":"s1","key":"DOCS"}]}`))
+		case "/wiki/api/v2/pages/7":
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "7", SpaceID: "s1", Title: "Guide", ParentID: "5", Version: &api.Version{Number: 4}})
+		case "/wiki/api/v2/pages/9":
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "9", SpaceID: "s1", Title: "Archive", Version: &api.Version{Number: 1}})
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	file := filepath.Join(t.TempDir(), "guide.md")
+	if err := os.WriteFile(file, []byte("Hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		cmd   *cobra.Command
+		args  []string
+		setup func()
+		want  string
+	}{
+		{
+			name:  "create",
+			cmd:   pageCreateCmd,
+			setup: func() { pageTitle = "New"; pageSpace = "DOCS"; pageParent = "9" },
+			want:  "Would create page \"New\" in space DOCS under page 9 (13 bytes of storage)\n",
+		},
+		{
+			name:  "update",
+			cmd:   pageUpdateCmd,
+			args:  []string{"7"},
+			setup: func() { pageTitle = "Setup"; pageParent = "9" },
+			want:  "Would update page 7 \"Setup\" to version 5 (13 bytes of storage), renaming it from \"Guide\", moving it under page 9\n",
+		},
+		{
+			name: "delete",
+			cmd:  pageDeleteCmd,
+			args: []string{"7"},
+			want: "Would delete page 7 \"Guide\"\n",
+		},
+		{
+			name:  "move",
+			cmd:   pageMoveCmd,
+			args:  []string{"7"},
+			setup: func() { moveParent = "9" },
+			want:  "Would move page 7 \"Guide\" from under page 5 to under page 9 \"Archive\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			pageFile = file
+			dryRun = true
+			if tt.setup != nil {
+				tt.setup()
+			}
+			finish := captureStdStreams(t)
+			runErr := tt.cmd.RunE(testCommand(), tt.args)
+			stdout, _ := finish()
+			if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if stdout != tt.want {
+				t.Errorf("stdout = %q, want %q", stdout, tt.want)
+			}
+		})
+	}
+}
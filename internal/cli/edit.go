@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+// defaultEditor is run when neither VISUAL nor EDITOR is set
+const defaultEditor = "vi"
+
+// runEditor opens path in the user's editor and waits for it to exit.
+// Override in tests.
+var runEditor = func(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running editor %s: %w", fields[0], err)
+	}
+	return nil
+}
+
+var pageEditCmd = &cobra.Command{
+	Use:   "edit PAGE_ID",
+	Short: "Edit a page in your editor",
+	Long: `Download a page as markdown into a temporary file, with its images, and open
+it in $VISUAL or $EDITOR (vi if neither is set). When the editor exits,
+the saved file is published as the next version of the page, as page update
+would. Closing the editor without changing the file leaves the page as it
+is.
+
+Macros are written as shortcodes that publish back unchanged, and the
+frontmatter title, status, and parent can be edited too. If the page gets a
+new version while it is being edited, or publishing fails, the file is kept
+and its path printed so the edits are not lost.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		page, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		if err := checkPageProtection(cmd.Context(), client, cfg, pageID, page.SpaceID, "update"); err != nil {
+			return err
+		}
+
+		storage := storageOptions()
+		storage.RoundTrip = true
+		markdown, err := pageMarkdown(cmd.Context(), client, cfg.BaseURL, page, storage, "Page Edit")
+		if err != nil {
+			return fmt.Errorf("converting page to markdown: %w", err)
+		}
+		fm := converter.Frontmatter{Title: page.Title, Parent: page.ParentID}
+		if page.Status != "" && page.Status != "current" {
+			fm.Status = page.Status
+		}
+		original := []byte(converter.FormatFrontmatter(fm) + markdown + "\n")
+
+		dir, err := os.MkdirTemp("", "acon-edit-")
+		if err != nil {
+			return fmt.Errorf("creating temp dir: %w", err)
+		}
+		file := filepath.Join(dir, pageID+".md")
+		published := false
+		defer func() {
+			if published {
+				os.RemoveAll(dir)
+			}
+		}()
+
+		if err := os.WriteFile(file, original, 0o600); err != nil {
+			return fmt.Errorf("writing page: %w", err)
+		}
+		if page.Body != nil && page.Body.Storage != nil {
+			filenames := converter.ImageAttachments(page.Body.Storage.Value)
+			if err := downloadAttachments(cmd.Context(), client, pageID, filepath.Join(dir, converter.AttachmentDir), filenames); err != nil {
+				return err
+			}
+		}
+
+		if err := runEditor(file); err != nil {
+			return fmt.Errorf("%w (the page is in %s)", err, file)
+		}
+		edited, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("reading edited page: %w", err)
+		}
+		if bytes.Equal(edited, original) || len(bytes.TrimSpace(edited)) == 0 {
+			published = true
+			fmt.Fprintln(os.Stderr, "No changes made")
+			return nil
+		}
+
+		current, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w (your edits are in %s)", err, file)
+		}
+		if current.Version != nil && page.Version != nil && current.Version.Number != page.Version.Number {
+			return fmt.Errorf("page %s was updated to version %d while you were editing; your edits are in %s", pageID, current.Version.Number, file)
+		}
+		meta, content, err := readPageMeta(edited)
+		if err != nil {
+			return fmt.Errorf("%w (your edits are in %s)", err, file)
+		}
+		opts, err := converterOptionsForSpaceID(cmd.Context(), client, cfg, current.SpaceID)
+		if err != nil {
+			return err
+		}
+		result, err := updatePage(cmd.Context(), client, converter.NewConverter(opts), converter.Document{}, current, meta, content, file, updateMsg)
+		if err != nil {
+			return fmt.Errorf("%w (your edits are in %s)", err, file)
+		}
+		published = true
+
+		if outputJSON {
+			return printJSON(result)
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
+		if err != nil || space.Key == "" {
+			fmt.Println(result.ID)
+			return nil
+		}
+		fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
+		return nil
+	},
+}
+
+func init() {
+	pageEditCmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version message")
+	pageEditCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageEditCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+
+	pageCmd.AddCommand(pageEditCmd)
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageEditCmd(t *testing.T) {
+	var version int
+	var updated *api.PageUpdateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/pages/1":
+			_ = json.NewEncoder(w).Encode(api.Page{
+				ID:       "1",
+				SpaceID:  "s1",
+				Title:    "Home",
+				ParentID: "9",
+				Version:  &api.Version{Number: version},
+				Body:     &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: "<p>Hello</p>"}},
+			})
+		case r.Method == http.MethodPut && r.URL.Path == "/wiki/api/v2/pages/1":
+			body, _ := io.ReadAll(r.Body)
+			updated = &api.PageUpdateRequest{}
+			if err := json.Unmarshal(body, updated); err != nil {
+				t.Errorf("decoding update request: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "1", SpaceID: "s1", Title: updated.Title})
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+	origEditor := runEditor
+	t.Cleanup(func() { runEditor = origEditor })
+
+	tests := []struct {
+		name      string
+		edit      func(path string) error
+		wantPut   string
+		wantTitle string
+		wantErr   string
+		wantKept  bool
+	}{
+		{
+			name: "publishes edits",
+			edit: func(path string) error {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				want := "---\ntitle: Home\nparent: \"9\"\n---\n\nHello\n"
+				if string(data) != want {
+					t.Errorf("file = %q, want %q", data, want)
+				}
+				return os.WriteFile(path, []byte(strings.Replace(strings.Replace(string(data), "Hello", "Hello world", 1), "Home", "Start", 1)), 0o600)
+			},
+			wantPut:   "<p>Hello world</p>\n",
+			wantTitle: "Start",
+		},
+		{
+			name: "no changes",
+			edit: func(string) error { return nil },
+		},
+		{
+			name: "page changed meanwhile",
+			edit: func(path string) error {
+				version = 3
+				return os.WriteFile(path, []byte("Changed\n"), 0o600)
+			},
+			wantErr:  "was updated to version 3 while you were editing",
+			wantKept: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			version = 2
+			updated = nil
+			var file string
+			runEditor = func(path string) error {
+				file = path
+				return tt.edit(path)
+			}
+			finish := captureStdStreams(t)
+			runErr := pageEditCmd.RunE(testCommand(), []string{"1"})
+			stdout, _ := finish()
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Fatalf("RunE error = %v, want %q", runErr, tt.wantErr)
+				}
+			} else if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if _, err := os.Stat(file); (err == nil) != tt.wantKept {
+				t.Errorf("file kept = %v, want %v", err == nil, tt.wantKept)
+			}
+			if tt.wantKept {
+				os.RemoveAll(filepath.Dir(file))
+			}
+
+			if tt.wantPut == "" {
+				if updated != nil {
+					t.Errorf("page updated, want no update")
+				}
+				return
+			}
+			if updated == nil {
+				t.Fatal("page not updated")
+			}
+			if updated.Body.Value != tt.wantPut || updated.Title != tt.wantTitle || updated.Version.Number != 3 {
+				t.Errorf("update = %q %q version %d, want %q %q version 3", updated.Title, updated.Body.Value, updated.Version.Number, tt.wantTitle, tt.wantPut)
+			}
+			if want := server.URL + "/wiki/spaces/DOCS/pages/1\n"; stdout != want {
+				t.Errorf("stdout = %q, want %q", stdout, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxExportChildren caps the children exported under one page
+	maxExportChildren = 1000
+	// spaceExportBatch is the number of pages listed per request when
+	// exporting a space
+	spaceExportBatch = 1000
+	// exportManifestFile is the manifest space export writes into the
+	// export directory
+	exportManifestFile = "manifest.json"
+)
+
+var (
+	exportDir         string
+	exportRecursive   bool
+	exportConcurrency int
+	exportResume      bool
+)
+
+// localPage is a page and the markdown file holding it, as written by
+// page and space export, read by page import, and kept in step by sync
+type localPage struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	ParentID    string   `json:"parentId,omitempty"`
+	Version     int      `json:"version,omitempty"`
+	Path        string   `json:"path"`
+	Attachments []string `json:"attachments,omitempty"`
+	// Hash is the SHA-256 of the file as it was last synced
+	Hash string `json:"hash,omitempty"`
+}
+
+// exportManifest describes a space export: the space, and its pages in
+// tree order, each with the path of its file relative to the export
+// directory
+type exportManifest struct {
+	Space struct {
+		ID   string `json:"id"`
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"space"`
+	Pages []localPage `json:"pages"`
+}
+
+// pageExporter writes pages as markdown files under a directory
+type pageExporter struct {
+	ctx      context.Context
+	client   *api.Client
+	baseURL  string
+	spaceKey string
+	storage  converter.StorageOptions
+	written  []localPage
+}
+
+var pageExportCmd = &cobra.Command{
+	Use:   "export PAGE_ID",
+	Short: "Export a page, or a page tree, to markdown files",
+	Long: `Export a page as a markdown file named after its title, with frontmatter
+holding its title, ID, space, parent, version, and labels. With --recursive,
+the children of each page are exported into a directory named like the
+page's file, mirroring the page tree.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		page, err := client.GetPageADF(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), page.SpaceID)
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+		if err := os.MkdirAll(exportDir, 0o755); err != nil {
+			return fmt.Errorf("creating export directory: %w", err)
+		}
+
+		e := &pageExporter{ctx: cmd.Context(), client: client, baseURL: cfg.BaseURL, spaceKey: space.Key, storage: storageOptions()}
+		if err := e.export(page, exportDir, map[string]bool{}); err != nil {
+			return err
+		}
+
+		if outputJSON {
+			return printJSON(e.written)
+		}
+		for _, p := range e.written {
+			fmt.Println(p.Path)
+		}
+		return nil
+	},
+}
+
+// export writes page into dir, taking a filename not yet in taken, and
+// with --recursive its children into a directory beside it
+func (e *pageExporter) export(page *api.Page, dir string, taken map[string]bool) error {
+	name := exportName(page, taken)
+	p := filepath.Join(dir, name+".md")
+	version, _, err := e.writePage(page, p, "")
+	if err != nil {
+		return err
+	}
+	e.written = append(e.written, localPage{ID: page.ID, Title: page.Title, ParentID: page.ParentID, Version: version, Path: p})
+
+	if !exportRecursive {
+		return nil
+	}
+	children, more, err := e.client.GetChildPages(e.ctx, page.ID, maxExportChildren, "child-position")
+	if err != nil {
+		return fmt.Errorf("listing children of page %s: %w", page.ID, err)
+	}
+	if more {
+		fmt.Fprintf(os.Stderr, "Warning: page %s has more than %d children; only the first %d are exported\n", page.ID, maxExportChildren, maxExportChildren)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	childDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(childDir, 0o755); err != nil {
+		return fmt.Errorf("creating directory for page %s: %w", page.ID, err)
+	}
+	childTaken := map[string]bool{}
+	for _, child := range children {
+		// Child listings carry no body, so each page is fetched in full
+		full, err := e.client.GetPageADF(e.ctx, child.ID)
+		if err != nil {
+			return fmt.Errorf("getting page %s: %w", child.ID, err)
+		}
+		if err := e.export(full, childDir, childTaken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePage writes page to the file p as markdown with frontmatter,
+// returning the version written. With attachmentDir set, a path relative to
+// p, images attached to the page link into that directory and are
+// downloaded there, and their filenames are returned.
+func (e *pageExporter) writePage(page *api.Page, p, attachmentDir string) (int, []string, error) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Export] Exporting page %s: %s\n", page.ID, page.Title)
+	}
+	opts := e.storage
+	opts.AttachmentDir = attachmentDir
+	markdown, err := pageMarkdown(e.ctx, e.client, e.baseURL, page, opts, "Page Export")
+	if err != nil {
+		return 0, nil, fmt.Errorf("converting page %s: %w", page.ID, err)
+	}
+	labels, err := e.client.GetLabels(e.ctx, page.ID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("getting labels of page %s: %w", page.ID, err)
+	}
+
+	fm := converter.Frontmatter{Title: page.Title, ID: page.ID, Space: e.spaceKey, Parent: page.ParentID}
+	if page.Status != "" && page.Status != "current" {
+		fm.Status = page.Status
+	}
+	if page.Version != nil {
+		fm.Version = page.Version.Number
+	}
+	for _, label := range labels {
+		fm.Labels = append(fm.Labels, label.Name)
+	}
+
+	content := converter.FormatFrontmatter(fm) + markdown + "\n"
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		return 0, nil, fmt.Errorf("writing page %s: %w", page.ID, err)
+	}
+
+	var filenames []string
+	if attachmentDir != "" {
+		filenames = pageImageAttachments(page)
+		dir := filepath.Join(filepath.Dir(p), filepath.FromSlash(attachmentDir))
+		if err := downloadAttachments(e.ctx, e.client, page.ID, dir, filenames); err != nil {
+			return 0, nil, fmt.Errorf("page %s: %w", page.ID, err)
+		}
+	}
+	return fm.Version, filenames, nil
+}
+
+var spaceExportCmd = &cobra.Command{
+	Use:   "export SPACE_KEY|SPACE_ID",
+	Short: "Export every page in a space to markdown files",
+	Long: `Export every page in a space as markdown files in directories mirroring
+the page tree, as page export --recursive does from each top-level page.
+Images attached to a page are downloaded into attachments/<name>/ beside
+its file, where <name> is the file's name without .md.
+
+A manifest.json in the export directory lists the space and each page's ID,
+title, parent, version, path, and attachments. It is saved as each page is
+written, so with --resume an interrupted or earlier export skips the pages
+it already holds at their current version.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportConcurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		var space *api.Space
+		if isNumericID(args[0]) {
+			space, err = client.GetSpaceByID(cmd.Context(), args[0])
+		} else {
+			space, err = client.GetSpace(cmd.Context(), args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+
+		// The space listing carries each page's body, so pages are not
+		// fetched again
+		var pages []api.Page
+		var cursor api.Cursor
+		for {
+			batch, next, err := client.ListPagesADFWithCursor(cmd.Context(), space.ID, spaceExportBatch, "", cursor)
+			if err != nil {
+				return fmt.Errorf("listing pages: %w", err)
+			}
+			pages = append(pages, batch...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+
+		var previous map[string]localPage
+		if exportResume {
+			previous, err = readExportManifest(exportDir)
+			if err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(exportDir, 0o755); err != nil {
+			return fmt.Errorf("creating export directory: %w", err)
+		}
+
+		s := &spaceExporter{
+			pageExporter: pageExporter{ctx: cmd.Context(), client: client, baseURL: cfg.BaseURL, spaceKey: space.Key, storage: storageOptions()},
+			pages:        map[string]*api.Page{},
+			done:         map[string]localPage{},
+		}
+		s.manifest.Space.ID, s.manifest.Space.Key, s.manifest.Space.Name = space.ID, space.Key, space.Name
+		s.plan(pages)
+
+		var todo []localPage
+		for _, entry := range s.manifest.Pages {
+			if prev, ok := previous[entry.ID]; ok && prev.Path == entry.Path && prev.Version == entry.Version && fileExists(filepath.Join(exportDir, filepath.FromSlash(entry.Path))) {
+				if verbose {
+					fmt.Fprintf(os.Stderr, "[Space Export] Page %s is unchanged at version %d\n", entry.ID, entry.Version)
+				}
+				s.done[entry.ID] = prev
+				continue
+			}
+			todo = append(todo, entry)
+		}
+		skipped := len(s.done)
+
+		runErr := s.run(todo)
+		if err := s.save(); err != nil && runErr == nil {
+			runErr = err
+		}
+		if runErr != nil {
+			return runErr
+		}
+
+		if outputJSON {
+			return printJSON(s.manifest)
+		}
+		for _, entry := range todo {
+			fmt.Println(filepath.Join(exportDir, filepath.FromSlash(entry.Path)))
+		}
+		if skipped > 0 {
+			pageWord := "pages"
+			if skipped == 1 {
+				pageWord = "page"
+			}
+			fmt.Fprintf(os.Stderr, "Skipped %d unchanged %s\n", skipped, pageWord)
+		}
+		return nil
+	},
+}
+
+// spaceExporter writes the pages of a space with several workers, keeping
+// the manifest up to date as they finish
+type spaceExporter struct {
+	pageExporter
+	manifest exportManifest
+	pages    map[string]*api.Page
+
+	mu   sync.Mutex
+	done map[string]localPage
+}
+
+// plan lays out pages as a tree, filling the manifest with every page in
+// tree order and the path it is written to. Pages whose parent is not in
+// the space listing are top-level. Siblings are ordered by page ID, so
+// repeated exports name clashing titles the same way.
+func (s *spaceExporter) plan(pages []api.Page) {
+	for i := range pages {
+		s.pages[pages[i].ID] = &pages[i]
+	}
+	children := map[string][]*api.Page{}
+	for i := range pages {
+		parent := pages[i].ParentID
+		if _, ok := s.pages[parent]; !ok {
+			parent = ""
+		}
+		children[parent] = append(children[parent], &pages[i])
+	}
+
+	var walk func(parentID, dir string)
+	walk = func(parentID, dir string) {
+		siblings := children[parentID]
+		sort.Slice(siblings, func(i, j int) bool {
+			a, b := siblings[i].ID, siblings[j].ID
+			if len(a) != len(b) {
+				return len(a) < len(b)
+			}
+			return a < b
+		})
+		// Keep page files from clashing with the attachments directory
+		taken := map[string]bool{converter.AttachmentDir: true}
+		for _, page := range siblings {
+			name := exportName(page, taken)
+			entry := localPage{ID: page.ID, Title: page.Title, ParentID: parentID, Path: path.Join(dir, name+".md")}
+			if page.Version != nil {
+				entry.Version = page.Version.Number
+			}
+			s.manifest.Pages = append(s.manifest.Pages, entry)
+			walk(page.ID, path.Join(dir, name))
+		}
+	}
+	walk("", "")
+}
+
+// run writes the todo pages with exportConcurrency workers, stopping at
+// the first error
+func (s *spaceExporter) run(todo []localPage) error {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	worker := s.pageExporter
+	worker.ctx = ctx
+
+	jobs := make(chan localPage)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for range exportConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				if err := s.exportOne(&worker, entry); err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+	for _, entry := range todo {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- entry
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// exportOne writes the page of entry, then records it in the manifest
+func (s *spaceExporter) exportOne(worker *pageExporter, entry localPage) error {
+	if worker.ctx.Err() != nil {
+		return worker.ctx.Err()
+	}
+	p := filepath.Join(exportDir, filepath.FromSlash(entry.Path))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("creating directory for page %s: %w", entry.ID, err)
+	}
+	attachmentDir := converter.AttachmentDir + "/" + strings.TrimSuffix(path.Base(entry.Path), ".md")
+	version, filenames, err := worker.writePage(s.pages[entry.ID], p, attachmentDir)
+	if err != nil {
+		return err
+	}
+	entry.Version = version
+	entry.Attachments = filenames
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done[entry.ID] = entry
+	return s.saveLocked()
+}
+
+// save writes the manifest of the pages exported so far
+func (s *spaceExporter) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+// saveLocked writes the manifest with s.mu held. It is written to a
+// temporary file first, so an interrupted export leaves the last complete
+// manifest.
+func (s *spaceExporter) saveLocked() error {
+	manifest := exportManifest{Space: s.manifest.Space, Pages: []localPage{}}
+	for _, entry := range s.manifest.Pages {
+		if done, ok := s.done[entry.ID]; ok {
+			manifest.Pages = append(manifest.Pages, done)
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	p := filepath.Join(exportDir, exportManifestFile)
+	if err := os.WriteFile(p+".tmp", append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	if err := os.Rename(p+".tmp", p); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
+
+// readExportManifest returns the pages in the manifest of an earlier
+// export into dir by ID, or none if there is no manifest
+func readExportManifest(dir string) (map[string]localPage, error) {
+	data, err := os.ReadFile(filepath.Join(dir, exportManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifest exportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", filepath.Join(dir, exportManifestFile), err)
+	}
+	pages := make(map[string]localPage, len(manifest.Pages))
+	for _, entry := range manifest.Pages {
+		pages[entry.ID] = entry
+	}
+	return pages, nil
+}
+
+// fileExists reports whether p is an existing regular file
+func fileExists(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// exportName returns the file name, without extension, for a page: its
+// title in lower case with runs of other characters as single hyphens. A
+// name already taken in the directory, or an empty one, gets the page ID.
+func exportName(page *api.Page, taken map[string]bool) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(page.Title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	name := b.String()
+	if name == "" || taken[name] {
+		name = strings.TrimPrefix(name+"-"+page.ID, "-")
+	}
+	taken[name] = true
+	return name
+}
+
+func init() {
+	pageExportCmd.Flags().StringVarP(&exportDir, "output", "o", ".", "Directory to write the markdown files into")
+	pageExportCmd.Flags().BoolVarP(&exportRecursive, "recursive", "r", false, "Export the page's descendants too, in directories mirroring the page tree")
+	pageExportCmd.Flags().BoolVar(&admonitions, "admonitions", false, "Write panels as ::: admonitions instead of GitHub alerts")
+	pageExportCmd.Flags().BoolVar(&roundTrip, "round-trip", false, "Write macros as shortcodes such as {status:...} that publish back unchanged")
+	pageExportCmd.Flags().BoolVar(&keepHTML, "keep-html", false, "Keep merged table cells and coloured text as inline HTML")
+	pageExportCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	spaceExportCmd.ValidArgsFunction = completeSpaceKeyArg
+	spaceExportCmd.Flags().StringVarP(&exportDir, "output", "o", ".", "Directory to write the markdown files and manifest into")
+	spaceExportCmd.Flags().IntVarP(&exportConcurrency, "concurrency", "c", 4, "Number of pages to export at once")
+	spaceExportCmd.Flags().BoolVar(&exportResume, "resume", false, "Skip pages the manifest already holds at their current version")
+	spaceExportCmd.Flags().BoolVar(&admonitions, "admonitions", false, "Write panels as ::: admonitions instead of GitHub alerts")
+	spaceExportCmd.Flags().BoolVar(&roundTrip, "round-trip", false, "Write macros as shortcodes such as {status:...} that publish back unchanged")
+	spaceExportCmd.Flags().BoolVar(&keepHTML, "keep-html", false, "Keep merged table cells and coloured text as inline HTML")
+	spaceExportCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON (the manifest)")
+
+	pageCmd.AddCommand(pageExportCmd)
+	spaceCmd.AddCommand(spaceExportCmd)
+}
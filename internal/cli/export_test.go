@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// resetExportFlags restores page export flags after a test
+func resetExportFlags(t *testing.T) {
+	t.Helper()
+	resetPageFlags(t)
+	reset := func() {
+		exportDir = "."
+		exportRecursive = false
+		exportConcurrency = 4
+		exportResume = false
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestPageExportCmd(t *testing.T) {
+	pages := map[string]api.Page{
+		"123": {ID: "123", SpaceID: "space-1", Title: "Docs Home", Version: &api.Version{Number: 3}},
+		"456": {ID: "456", SpaceID: "space-1", Title: "Install / Setup", ParentID: "123", Version: &api.Version{Number: 1}},
+		"789": {ID: "789", SpaceID: "space-1", Title: "Linux", ParentID: "456", Status: "draft", Version: &api.Version{Number: 2}},
+	}
+	bodies := map[string]string{
+		"123": "<h1>Welcome</h1>",
+		"456": "<p>Run the installer.</p>",
+		"789": "<p>Use the package.</p>",
+	}
+	children := map[string][]api.Page{"123": {{ID: "456"}}, "456": {{ID: "789"}}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/")
+		switch {
+		case path == "spaces/space-1":
+			_ = json.NewEncoder(w).Encode(api.Space{ID: "space-1", Key: "DOCS"})
+		case strings.HasSuffix(path, "/labels"):
+			if path == "pages/123/labels" {
+				_, _ = w.Write([]byte(`{"results":[{"id":"1","name":"howto","prefix":"global"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case strings.HasSuffix(path, "/children"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "pages/"), "/children")
+			_ = json.NewEncoder(w).Encode(api.PageListResponse{Results: children[id]})
+		case strings.HasPrefix(path, "pages/"):
+			id := strings.TrimPrefix(path, "pages/")
+			page, ok := pages[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			page.Body = &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: bodies[id]}}
+			_ = json.NewEncoder(w).Encode(page)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	t.Run("single page", func(t *testing.T) {
+		resetExportFlags(t)
+		exportDir = t.TempDir()
+
+		finish := captureStdStreams(t)
+		runErr := pageExportCmd.RunE(testCommand(), []string{"123"})
+		stdout, _ := finish()
+		if runErr != nil {
+			t.Fatalf("RunE returned error: %v", runErr)
+		}
+
+		path := filepath.Join(exportDir, "docs-home.md")
+		if strings.TrimSpace(stdout) != path {
+			t.Errorf("stdout = %q, want %q", stdout, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading export: %v", err)
+		}
+		want := "---\ntitle: Docs Home\nid: \"123\"\nspace: DOCS\nversion: 3\nlabels: [howto]\n---\n\n# Welcome\n"
+		if string(data) != want {
+			t.Errorf("export = %q, want %q", data, want)
+		}
+		if _, err := os.Stat(filepath.Join(exportDir, "docs-home")); !os.IsNotExist(err) {
+			t.Errorf("child directory created without --recursive: %v", err)
+		}
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		resetExportFlags(t)
+		exportDir = t.TempDir()
+		exportRecursive = true
+
+		finish := captureStdStreams(t)
+		runErr := pageExportCmd.RunE(testCommand(), []string{"123"})
+		stdout, _ := finish()
+		if runErr != nil {
+			t.Fatalf("RunE returned error: %v", runErr)
+		}
+
+		want := []string{
+			filepath.Join(exportDir, "docs-home.md"),
+			filepath.Join(exportDir, "docs-home", "install-setup.md"),
+			filepath.Join(exportDir, "docs-home", "install-setup", "linux.md"),
+		}
+		if got := strings.Split(strings.TrimSpace(stdout), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("stdout = %q, want %q", got, want)
+		}
+		data, err := os.ReadFile(want[2])
+		if err != nil {
+			t.Fatalf("reading export: %v", err)
+		}
+		if !strings.Contains(string(data), "parent: \"456\"\nstatus: draft\nversion: 2\n") {
+			t.Errorf("child export = %q, want parent, status, and version", data)
+		}
+	})
+}
+
+func TestSpaceExportCmd(t *testing.T) {
+	pages := []api.Page{
+		{ID: "30", SpaceID: "space-1", Title: "Attachments", ParentID: "10", Version: &api.Version{Number: 1}},
+		{ID: "10", SpaceID: "space-1", Title: "Home", ParentID: "5", Version: &api.Version{Number: 4}},
+		{ID: "20", SpaceID: "space-1", Title: "Guide", ParentID: "10", Version: &api.Version{Number: 2}},
+	}
+	bodies := map[string]string{
+		"10": "<p>Start here.</p>",
+		"20": `<p><ac:image><ri:attachment ri:filename="chart.png" /></ac:image></p>`,
+		"30": "<p>Files.</p>",
+	}
+	var converted atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/wiki/api/v2/spaces":
+			_, _ = w.Write([]byte(`{"results":[{"id":"space-1","key":"DOCS","name":"Docs"}]}`))
+		case r.URL.Path == "/wiki/api/v2/pages":
+			var list api.PageListResponse
+			for _, page := range pages {
+				page.Body = &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: bodies[page.ID]}}
+				list.Results = append(list.Results, page)
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		case strings.HasSuffix(r.URL.Path, "/labels"):
+			converted.Add(1)
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case r.URL.Path == "/wiki/api/v2/pages/20/attachments":
+			_, _ = w.Write([]byte(`{"results":[{"id":"att1","title":"chart.png","downloadLink":"/download/chart.png"}]}`))
+		case r.URL.Path == "/wiki/download/chart.png":
+			_, _ = w.Write([]byte("PNG"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+	resetExportFlags(t)
+	exportDir = t.TempDir()
+	exportConcurrency = 2
+
+	run := func() string {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := spaceExportCmd.RunE(testCommand(), []string{"DOCS"})
+		stdout, _ := finish()
+		if runErr != nil {
+			t.Fatalf("RunE returned error: %v", runErr)
+		}
+		return stdout
+	}
+
+	stdout := run()
+	want := []string{
+		filepath.Join(exportDir, "home.md"),
+		filepath.Join(exportDir, "home", "guide.md"),
+		filepath.Join(exportDir, "home", "attachments-30.md"),
+	}
+	if got := strings.Split(strings.TrimSpace(stdout), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	guide, err := os.ReadFile(want[1])
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+	if !strings.Contains(string(guide), "![chart](attachments/guide/chart.png)") {
+		t.Errorf("guide = %q, want image linked into its attachment directory", guide)
+	}
+	if data, err := os.ReadFile(filepath.Join(exportDir, "home", "attachments", "guide", "chart.png")); err != nil || string(data) != "PNG" {
+		t.Errorf("attachment = %q, %v, want PNG", data, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(exportDir, exportManifestFile))
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	var manifest exportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("parsing manifest: %v", err)
+	}
+	if manifest.Space.Key != "DOCS" || len(manifest.Pages) != 3 {
+		t.Fatalf("manifest = %+v, want space DOCS with 3 pages", manifest)
+	}
+	if p := manifest.Pages[1]; p.ID != "20" || p.ParentID != "10" || p.Version != 2 || p.Path != "home/guide.md" || len(p.Attachments) != 1 {
+		t.Errorf("manifest page = %+v, want guide under home with its attachment", p)
+	}
+	if manifest.Pages[0].ParentID != "" {
+		t.Errorf("top-level page parent = %q, want none", manifest.Pages[0].ParentID)
+	}
+
+	// Resuming writes only the page whose version changed
+	exportResume = true
+	pages[2].Version.Number = 3
+	converted.Store(0)
+	stdout = run()
+	if strings.TrimSpace(stdout) != want[1] {
+		t.Errorf("resumed stdout = %q, want only %q", stdout, want[1])
+	}
+	if n := converted.Load(); n != 1 {
+		t.Errorf("resume exported %d pages, want 1", n)
+	}
+	data, _ = os.ReadFile(filepath.Join(exportDir, exportManifestFile))
+	if !strings.Contains(string(data), `"version": 3`) || !strings.Contains(string(data), `"path": "home.md"`) {
+		t.Errorf("resumed manifest = %s, want every page with the new version", data)
+	}
+}
+
+func TestExportCmd_OutputFlag(t *testing.T) {
+	// -o/--output on export is the directory, shadowing the global
+	// --output result format, which has no shorthand
+	if f := rootCmd.PersistentFlags().Lookup("output"); f == nil || f.Shorthand != "" {
+		t.Fatalf("global --output = %+v, want no shorthand", f)
+	}
+	for _, cmd := range []*cobra.Command{pageExportCmd, spaceExportCmd} {
+		resetExportFlags(t)
+		if err := cmd.ParseFlags([]string{"-o", "docs"}); err != nil {
+			t.Fatalf("%s: ParseFlags: %v", cmd.Name(), err)
+		}
+		if exportDir != "docs" || outputFormat != "" {
+			t.Errorf("%s -o docs: exportDir = %q, outputFormat = %q", cmd.Name(), exportDir, outputFormat)
+		}
+	}
+}
+
+func TestExportName(t *testing.T) {
+	taken := map[string]bool{}
+	tests := []struct {
+		page api.Page
+		want string
+	}{
+		{api.Page{ID: "1", Title: "Getting Started"}, "getting-started"},
+		{api.Page{ID: "2", Title: "Getting  started!"}, "getting-started-2"},
+		{api.Page{ID: "3", Title: "Café: Menü"}, "café-menü"},
+		{api.Page{ID: "4", Title: "???"}, "4"},
+	}
+	for _, tt := range tests {
+		if got := exportName(&tt.page, taken); got != tt.want {
+			t.Errorf("exportName(%q) = %q, want %q", tt.page.Title, got, tt.want)
+		}
+	}
+}
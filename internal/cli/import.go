@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+// importNode is a page to create from a directory being imported
+type importNode struct {
+	// file is the markdown file, slash-separated and relative to the
+	// imported directory, or "" for a directory with no file of its own
+	file     string
+	name     string // file name without extension, or directory name
+	meta     pageMeta
+	content  []byte
+	children []*importNode
+}
+
+// pageImporter creates the pages of an imported directory
+type pageImporter struct {
+	ctx     context.Context
+	client  *api.Client
+	cfg     *config.Config
+	root    string
+	space   *api.Space
+	conv    *converter.Converter
+	created []localPage
+}
+
+var pageImportCmd = &cobra.Command{
+	Use:   "import DIR",
+	Short: "Create a page tree from a directory of markdown files",
+	Long: `Create a page for each markdown file in DIR, keeping the directory structure
+as the page tree, as page export --recursive writes it: the files in a
+directory named like a file, without .md, become children of that file's
+page. A directory with no such file becomes a page listing its children.
+Directories without markdown files, such as attachments/, are skipped.
+
+Pages are titled from their frontmatter, or else the file name, and the
+frontmatter's status, labels, emoji, and width apply as for page create.
+Its space and parent are ignored. Relative links between the files become
+links to their pages.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &pageParent); err != nil {
+			return err
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		root := args[0]
+		nodes, err := scanImportDir(root, ".")
+		if err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("no markdown files found in %s", root)
+		}
+		links := map[string]converter.PageRef{}
+		if err := checkImportTitles(nodes, map[string]string{}, links); err != nil {
+			return err
+		}
+
+		spaceKey := pageSpace
+		if spaceKey == "" {
+			spaceKey = cfg.SpaceKey
+		}
+		if spaceKey == "" {
+			return fmt.Errorf("space key required: use --space flag or set CONFLUENCE_SPACE_KEY")
+		}
+		space, err := client.GetSpace(cmd.Context(), spaceKey)
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+
+		opts := converterOptions(cfg, space.Key)
+		if err := applyConverterFlags(&opts); err != nil {
+			return err
+		}
+		opts.PageLinks = links
+
+		im := &pageImporter{ctx: cmd.Context(), client: client, cfg: cfg, root: root, space: space, conv: converter.NewConverter(opts)}
+		under := "at the top of space " + space.Key
+		if pageParent != "" {
+			under = "under page " + pageParent
+		}
+		if err := im.create(nodes, pageParent, under); err != nil {
+			if len(im.created) > 0 {
+				return fmt.Errorf("%w (%d pages were created before the failure)", err, len(im.created))
+			}
+			return err
+		}
+
+		if outputJSON {
+			return printJSON(im.created)
+		}
+		return nil
+	},
+}
+
+// scanImportDir returns the pages for the markdown files and directories
+// in the directory rel of root, ordered by name. Hidden files and
+// directories are skipped.
+func scanImportDir(root, rel string) ([]*importNode, error) {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	var nodes []*importNode
+	byName := map[string]*importNode{}
+	for _, entry := range entries {
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || (ext != ".md" && ext != ".markdown") {
+			continue
+		}
+		file := path.Join(rel, entry.Name())
+		content, err := readAndValidateContent(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		meta, content, err := readPageMeta(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		node := &importNode{file: file, name: strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())), meta: meta, content: content}
+		if node.meta.title == "" {
+			node.meta.title = node.name
+		}
+		if byName[node.name] != nil {
+			return nil, fmt.Errorf("%s and %s would both take the children in %s/", byName[node.name].file, file, path.Join(rel, node.name))
+		}
+		byName[node.name] = node
+		nodes = append(nodes, node)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		children, err := scanImportDir(root, path.Join(rel, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if len(children) == 0 {
+			continue
+		}
+		if node := byName[entry.Name()]; node != nil {
+			node.children = children
+			continue
+		}
+		nodes = append(nodes, &importNode{
+			name:     entry.Name(),
+			meta:     pageMeta{title: entry.Name(), status: "current"},
+			content:  []byte("{children}"),
+			children: children,
+		})
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].name < nodes[j].name })
+	return nodes, nil
+}
+
+// checkImportTitles fails if two pages would share a title, which
+// Confluence does not allow within a space, and maps each file to its page
+// in links. titles holds the files seen so far by title.
+func checkImportTitles(nodes []*importNode, titles map[string]string, links map[string]converter.PageRef) error {
+	for _, node := range nodes {
+		where := node.file
+		if where == "" {
+			where = "directory " + node.name
+		}
+		if other, ok := titles[node.meta.title]; ok {
+			return fmt.Errorf("%s and %s both have the title %q", other, where, node.meta.title)
+		}
+		titles[node.meta.title] = where
+		if node.file != "" {
+			links[node.file] = converter.PageRef{Title: node.meta.title}
+		}
+		if err := checkImportTitles(node.children, titles, links); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// create creates the pages in nodes under the page with ID parentID, or
+// at the top of the space if it is "", each before its children. under
+// describes the parent for --dry-run, where new pages have no ID.
+func (im *pageImporter) create(nodes []*importNode, parentID, under string) error {
+	for _, node := range nodes {
+		var doc converter.Document
+		markdownPath := ""
+		if node.file != "" {
+			doc.LinkBase = path.Dir(node.file)
+			markdownPath = filepath.Join(im.root, filepath.FromSlash(node.file))
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Page Import] Creating %q from %s\n", node.meta.title, markdownPath)
+		}
+		meta := node.meta
+		meta.parent = parentID
+		page, err := createPage(im.ctx, im.client, im.conv, doc, im.space.ID, meta, node.content, markdownPath)
+		if err != nil {
+			if node.file != "" {
+				return fmt.Errorf("%s: %w", node.file, err)
+			}
+			return fmt.Errorf("directory %s: %w", node.name, err)
+		}
+
+		entry := localPage{ID: page.ID, Title: page.Title, ParentID: parentID, Path: markdownPath}
+		if page.Version != nil {
+			entry.Version = page.Version.Number
+		}
+		im.created = append(im.created, entry)
+		switch {
+		case outputJSON:
+		case dryRun:
+			fmt.Printf("Would create page %q %s (%d bytes of storage)\n", page.Title, under, storageSize(page))
+		default:
+			fmt.Println(pageURL(im.cfg.BaseURL, im.space.Key, page.ID))
+		}
+
+		if err := im.create(node.children, page.ID, fmt.Sprintf("under %q", page.Title)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	pageImportCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space key (uses config default if not specified)")
+	pageImportCmd.Flags().StringVarP(&pageParent, "parent", "p", "", "Parent page ID for the top-level pages")
+	pageImportCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageImportCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	pageImportCmd.Flags().BoolVar(&renderDiagrams, "render-diagrams", false, "Render diagram fences locally and attach them as images")
+	pageImportCmd.Flags().StringVar(&diagramFormat, "diagram-format", "svg", "Rendered diagram format: svg, png")
+	pageImportCmd.Flags().BoolVar(&pageTOC, "toc", false, "Insert a table of contents at the top if there is no [TOC] marker")
+	pageImportCmd.Flags().IntVar(&tocMin, "toc-min-level", 0, "Smallest heading level in the table of contents (1-6)")
+	pageImportCmd.Flags().IntVar(&tocMax, "toc-max-level", 0, "Largest heading level in the table of contents (1-6)")
+	pageImportCmd.Flags().IntVar(&headingOff, "heading-offset", 0, "Shift heading levels by this much, e.g. 1 to publish # as h2 (-5 to 5)")
+	pageImportCmd.Flags().BoolVar(&allowHTML, "allow-html", false, "Pass through raw HTML <br>, <sup>, and <sub> instead of omitting it")
+	pageImportCmd.Flags().BoolVar(&bareDates, "dates", false, "Convert ISO dates (2025-03-01) in text to date lozenges, not just {date:...}")
+	pageImportCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of omitting raw HTML that cannot be published")
+	pageImportCmd.Flags().BoolVar(&typography, "typography", false, "Use curly quotes, en and em dashes, and ellipses for straight quotes, -- and ---, and ...")
+	pageImportCmd.Flags().BoolVar(&subscript, "subscript", false, "Convert ~text~ to subscript instead of strikethrough")
+	pageImportCmd.Flags().BoolVar(&hardWraps, "hard-wraps", false, "Turn every newline within a paragraph into a line break")
+
+	pageCmd.AddCommand(pageImportCmd)
+}
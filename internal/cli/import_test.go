@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// writeTree writes files, keyed by slash-separated path, under dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPageImportCmd(t *testing.T) {
+	resetPageFlags(t)
+	pageSpace = "DOCS"
+	pageParent = "42"
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"home.md":                    "---\ntitle: Docs Home\nid: \"999\"\nspace: OLD\nparent: \"1\"\nlabels: [docs]\n---\n# Welcome\n\nSee the [guide](home/guide.md#setup).\n",
+		"home/guide.md":              "Back [home](../home.md).\n",
+		"home/attachments/guide.png": "PNG",
+		"reference/api.md":           "# API\n",
+		"notes.txt":                  "not markdown",
+	})
+
+	var created []api.PageCreateRequest
+	var labelPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces":
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{Results: []api.Space{{ID: "space-9", Key: "DOCS"}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages":
+			var req api.PageCreateRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decoding create request: %v", err)
+			}
+			created = append(created, req)
+			_ = json.NewEncoder(w).Encode(api.Page{ID: strconv.Itoa(100 + len(created)), SpaceID: req.SpaceID, Title: req.Title, ParentID: req.ParentID})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/label"):
+			labelPaths = append(labelPaths, r.URL.Path)
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := pageImportCmd.RunE(testCommand(), []string{root})
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+
+	type page struct{ title, parent string }
+	want := []page{{"Docs Home", "42"}, {"guide", "101"}, {"reference", "42"}, {"api", "103"}}
+	if len(created) != len(want) {
+		t.Fatalf("created %d pages, want %d: %+v", len(created), len(want), created)
+	}
+	for i, w := range want {
+		if got := (page{created[i].Title, created[i].ParentID}); got != w || created[i].SpaceID != "space-9" {
+			t.Errorf("page %d = %+v in %s, want %+v in space-9", i, got, created[i].SpaceID, w)
+		}
+	}
+	if body := created[0].Body.Value; !strings.Contains(body, `<ac:link ac:anchor="setup"><ri:page ri:content-title="guide" />`) {
+		t.Errorf("home body = %q, want link to the guide page", body)
+	}
+	if body := created[1].Body.Value; !strings.Contains(body, `<ri:page ri:content-title="Docs Home" />`) {
+		t.Errorf("guide body = %q, want link to the home page", body)
+	}
+	if body := created[2].Body.Value; !strings.Contains(body, `ac:name="children"`) {
+		t.Errorf("directory page body = %q, want children macro", body)
+	}
+	if len(labelPaths) != 1 || labelPaths[0] != "/wiki/rest/api/content/101/label" {
+		t.Errorf("label requests = %v, want one for the home page", labelPaths)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 4 || lines[0] != server.URL+"/wiki/spaces/DOCS/pages/101" {
+		t.Errorf("stdout = %q, want a URL per page", stdout)
+	}
+}
+
+func TestPageImportCmd_DuplicateTitles(t *testing.T) {
+	resetPageFlags(t)
+	pageSpace = "DOCS"
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.md":       "---\ntitle: Setup\n---\nA\n",
+		"guide/b.md": "---\ntitle: Setup\n---\nB\n",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := pageImportCmd.RunE(testCommand(), []string{root})
+	finish()
+	if runErr == nil || !strings.Contains(runErr.Error(), `a.md and guide/b.md both have the title "Setup"`) {
+		t.Errorf("RunE error = %v, want duplicate title error", runErr)
+	}
+}
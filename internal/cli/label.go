@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var pageLabelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Manage page labels",
+	Long: `List, add, and remove the labels of a page. For add and remove, a PAGE_ID
+of - reads page IDs from stdin, one per line, and labels each in turn,
+stopping at the first failure.`,
+}
+
+var pageLabelListCmd = &cobra.Command{
+	Use:   "list PAGE_ID",
+	Short: "List the labels of a page",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := initClient()
+		if err != nil {
+			return err
+		}
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		labels, err := client.GetLabels(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting labels: %w", err)
+		}
+		if outputJSON {
+			if labels == nil {
+				labels = []api.Label{}
+			}
+			return printJSON(labels)
+		}
+		for _, label := range labels {
+			fmt.Println(label.Name)
+		}
+		return nil
+	},
+}
+
+var pageLabelAddCmd = &cobra.Command{
+	Use:   "add PAGE_ID LABEL...",
+	Short: "Add labels to a page",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		labels := args[1:]
+		return forEachPage(cmd.Context(), client, cfg, args[:1], func(pageID string) error {
+			if err := checkPageProtection(cmd.Context(), client, cfg, pageID, "", "label"); err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Printf("Would add labels to page %s: %s\n", pageID, strings.Join(labels, ", "))
+				return nil
+			}
+			if _, err := client.AddLabels(cmd.Context(), pageID, labels); err != nil {
+				return fmt.Errorf("adding labels: %w", err)
+			}
+			fmt.Printf("Added labels to page %s: %s\n", pageID, strings.Join(labels, ", "))
+			return nil
+		})
+	},
+}
+
+var pageLabelRemoveCmd = &cobra.Command{
+	Use:   "remove PAGE_ID LABEL...",
+	Short: "Remove labels from a page",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		labels := args[1:]
+		return forEachPage(cmd.Context(), client, cfg, args[:1], func(pageID string) error {
+			if err := checkPageProtection(cmd.Context(), client, cfg, pageID, "", "label"); err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Printf("Would remove labels from page %s: %s\n", pageID, strings.Join(labels, ", "))
+				return nil
+			}
+			for _, label := range labels {
+				if err := client.RemoveLabel(cmd.Context(), pageID, label); err != nil {
+					return fmt.Errorf("removing label %s: %w", label, err)
+				}
+			}
+			fmt.Printf("Removed labels from page %s: %s\n", pageID, strings.Join(labels, ", "))
+			return nil
+		})
+	},
+}
+
+func init() {
+	pageLabelListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	for _, cmd := range []*cobra.Command{pageLabelAddCmd, pageLabelRemoveCmd} {
+		cmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+		cmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	}
+
+	pageLabelCmd.AddCommand(pageLabelListCmd)
+	pageLabelCmd.AddCommand(pageLabelAddCmd)
+	pageLabelCmd.AddCommand(pageLabelRemoveCmd)
+	pageCmd.AddCommand(pageLabelCmd)
+}
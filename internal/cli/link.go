@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	linkTiny bool
+	linkOpen bool
+)
+
+// openBrowser opens url in the default browser without waiting for it.
+// Override in tests.
+var openBrowser = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening browser: %w", err)
+	}
+	return nil
+}
+
+var pageLinkCmd = &cobra.Command{
+	Use:   "link [PAGE_ID]",
+	Short: "Print or open the URL of a page",
+	Long: `Print the URL of a page, ending in its title as Confluence writes it, or
+with --tiny its /x/ short link. --open also opens the URL in the default
+browser.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		pageID, err := resolvePageArg(cmd.Context(), client, cfg, args)
+		if err != nil {
+			return err
+		}
+		page, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), page.SpaceID)
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+		tinyURL, err := pageTinyURL(cfg.BaseURL, page.ID)
+		if err != nil {
+			return err
+		}
+		fullURL := pageURL(cfg.BaseURL, space.Key, page.ID) + "/" + titleSlug(page.Title)
+		link := fullURL
+		if linkTiny {
+			link = tinyURL
+		}
+
+		if outputJSON {
+			err := printJSON(struct {
+				ID      string `json:"id"`
+				Title   string `json:"title"`
+				Space   string `json:"space"`
+				URL     string `json:"url"`
+				TinyURL string `json:"tiny_url"`
+			}{page.ID, page.Title, space.Key, fullURL, tinyURL})
+			if err != nil {
+				return err
+			}
+		} else {
+			fmt.Println(link)
+		}
+		if linkOpen {
+			return openBrowser(link)
+		}
+		return nil
+	},
+}
+
+// titleSlug writes a page title as Confluence does at the end of a page URL,
+// with + for spaces
+func titleSlug(title string) string {
+	return url.QueryEscape(title)
+}
+
+// pageTinyURL returns the /x/ short link of a page, the inverse of tinyLinkID
+func pageTinyURL(baseURL, pageID string) (string, error) {
+	id, err := strconv.ParseUint(pageID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid page ID %s", pageID)
+	}
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, id)
+	code := strings.TrimRight(base64.StdEncoding.EncodeToString(data), "=")
+	code = strings.TrimRight(code, "A")
+	code = strings.NewReplacer("/", "-", "+", "_").Replace(code)
+	return fmt.Sprintf("%s/wiki/x/%s", baseURL, code), nil
+}
+
+func init() {
+	pageLinkCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Title of the page, instead of PAGE_ID")
+	pageLinkCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space to look up --title in (uses config default if not specified)")
+	pageLinkCmd.Flags().BoolVar(&linkTiny, "tiny", false, "Print the /x/ short link instead")
+	pageLinkCmd.Flags().BoolVar(&linkOpen, "open", false, "Open the URL in the default browser")
+	pageLinkCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON, with both URLs")
+
+	pageCmd.AddCommand(pageLinkCmd)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageTinyURL(t *testing.T) {
+	for id, want := range map[string]string{
+		"98307":      "https://example.atlassian.net/wiki/x/A4AB",
+		"1234567890": "https://example.atlassian.net/wiki/x/0gKWSQ",
+	} {
+		got, err := pageTinyURL("https://example.atlassian.net", id)
+		if err != nil {
+			t.Fatalf("pageTinyURL(%s): %v", id, err)
+		}
+		if got != want {
+			t.Errorf("pageTinyURL(%s) = %q, want %q", id, got, want)
+		}
+		if back, err := pageIDArg(got); err != nil || back != id {
+			t.Errorf("pageIDArg(%q) = %q, %v, want %s", got, back, err, id)
+		}
+	}
+	if _, err := pageTinyURL("https://example.atlassian.net", "abc"); err == nil {
+		t.Error("pageTinyURL with a non-numeric ID succeeded")
+	}
+}
+
+func TestPageLinkCmd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/api/v2/pages/98307":
+			_, _ = w.Write([]byte(`{"id":"98307","spaceId":"s1","title":"Release Notes & FAQ"}`))
+		case "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	var opened []string
+	origOpen := openBrowser
+	openBrowser = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	t.Cleanup(func() {
+		openBrowser = origOpen
+		linkTiny, linkOpen = false, false
+	})
+
+	run := func() string {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := pageLinkCmd.RunE(testCommand(), []string{"98307"})
+		stdout, _ := finish()
+		if runErr != nil {
+			t.Fatalf("link: %v", runErr)
+		}
+		return stdout
+	}
+
+	resetPageFlags(t)
+	if got, want := run(), server.URL+"/wiki/spaces/DOCS/pages/98307/Release+Notes+%26+FAQ\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	linkTiny, linkOpen = true, true
+	if got, want := run(), server.URL+"/wiki/x/A4AB\n"; got != want {
+		t.Errorf("--tiny stdout = %q, want %q", got, want)
+	}
+	if len(opened) != 1 || opened[0] != server.URL+"/wiki/x/A4AB" {
+		t.Errorf("opened = %v, want the tiny link", opened)
+	}
+
+	linkTiny, linkOpen, outputJSON = false, false, true
+	if got := run(); !strings.Contains(got, `"tiny_url": "`+server.URL+`/wiki/x/A4AB"`) ||
+		!strings.Contains(got, `"url": "`+server.URL+`/wiki/spaces/DOCS/pages/98307/Release+Notes+%26+FAQ"`) {
+		t.Errorf("--json stdout = %s", got)
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+// mentionSearchLimit caps the users fetched when resolving a mention
+const mentionSearchLimit = 10
+
+// mentions resolves @mentions to account IDs during conversion. Lookups are
+// cached, and the first failure is kept so the publish can be stopped
+// rather than leaving a mention as plain text.
+type mentions struct {
+	ctx    context.Context
+	client *api.Client
+	ids    map[string]string
+	err    error
+}
+
+// prepareMentions hooks user mention resolution into the conversion of doc.
+func prepareMentions(ctx context.Context, client *api.Client, doc *converter.Document) *mentions {
+	m := &mentions{ctx: ctx, client: client, ids: map[string]string{}}
+	doc.ResolveUser = m.resolve
+	return m
+}
+
+// resolve implements converter.UserResolver.
+func (m *mentions) resolve(query string) (string, error) {
+	if id, ok := m.ids[query]; ok {
+		return id, nil
+	}
+	users, err := m.client.SearchUsers(m.ctx, query, mentionSearchLimit)
+	if err == nil {
+		var user api.User
+		user, err = matchUser(users, query)
+		if err == nil {
+			m.ids[query] = user.AccountID
+			if verbose {
+				fmt.Fprintf(os.Stderr, "[Mention] %s is %s\n", query, user.AccountID)
+			}
+			return user.AccountID, nil
+		}
+	}
+	if m.err == nil {
+		m.err = fmt.Errorf("resolving mention @%s: %w", query, err)
+	}
+	return "", err
+}
+
+// matchUser picks the user a mention refers to: the one whose email (for an
+// email query) or display name matches exactly, or the only search result.
+func matchUser(users []api.User, query string) (api.User, error) {
+	var matches []api.User
+	for _, u := range users {
+		if strings.EqualFold(u.Email, query) || strings.EqualFold(u.DisplayName, query) || strings.EqualFold(u.PublicName, query) {
+			matches = append(matches, u)
+		}
+	}
+	if len(matches) == 0 && len(users) == 1 {
+		matches = users
+	}
+	switch len(matches) {
+	case 0:
+		return api.User{}, fmt.Errorf("no user found")
+	case 1:
+		return matches[0], nil
+	default:
+		return api.User{}, fmt.Errorf("%d users match", len(matches))
+	}
+}
+
+// userNames resolves mentioned account IDs to display names while viewing
+// a page. Lookups are cached, failures included, so each user is fetched
+// once however often they are mentioned.
+type userNames struct {
+	ctx    context.Context
+	client *api.Client
+	names  map[string]string // "" for a failed lookup
+}
+
+// prepareUserNames hooks display name resolution into opts.
+func prepareUserNames(ctx context.Context, client *api.Client, opts *converter.StorageOptions) {
+	u := &userNames{ctx: ctx, client: client, names: map[string]string{}}
+	opts.ResolveUserName = u.resolve
+}
+
+// resolve implements converter.UserNameResolver.
+func (u *userNames) resolve(accountID string) (string, error) {
+	name, ok := u.names[accountID]
+	if !ok {
+		user, err := u.client.GetUser(u.ctx, accountID)
+		switch {
+		case err != nil:
+			if verbose {
+				fmt.Fprintf(os.Stderr, "[Mention] %s: %v\n", accountID, err)
+			}
+		case user.DisplayName != "":
+			name = user.DisplayName
+		default:
+			name = user.PublicName
+		}
+		u.names[accountID] = name
+	}
+	if name == "" {
+		return "", fmt.Errorf("no display name for %s", accountID)
+	}
+	return name, nil
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+func TestPrepareMentions(t *testing.T) {
+	searches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		w.Header().Set("Content-Type", "application/json")
+		switch cql := r.URL.Query().Get("cql"); {
+		case strings.Contains(cql, "Jane"):
+			_, _ = w.Write([]byte(`{"results":[{"user":{"accountId":"acc-jane","displayName":"Jane Doe"}},{"user":{"accountId":"acc-janet","displayName":"Janet Doe"}}]}`))
+		case strings.Contains(cql, "bob"):
+			_, _ = w.Write([]byte(`{"results":[{"user":{"accountId":"acc-bob","displayName":"Bob"}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	var doc converter.Document
+	users := prepareMentions(context.Background(), client, &doc)
+	out, err := converter.NewConverter(converter.Options{}).ConvertDocument("@{Jane Doe}, @bob@example.com and @{Jane Doe} again", doc)
+	if err != nil {
+		t.Fatalf("ConvertDocument() error = %v", err)
+	}
+	if users.err != nil {
+		t.Fatalf("unexpected error = %v", users.err)
+	}
+	want := `<ac:link><ri:user ri:account-id="acc-jane" /></ac:link>, <ac:link><ri:user ri:account-id="acc-bob" /></ac:link> and <ac:link><ri:user ri:account-id="acc-jane" /></ac:link> again`
+	if !strings.Contains(out, want) {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if searches != 2 {
+		t.Errorf("searches = %d, want 2 (cached)", searches)
+	}
+
+	if _, err := converter.NewConverter(converter.Options{}).ConvertDocument("@{Nobody}", doc); err != nil {
+		t.Fatalf("ConvertDocument() error = %v", err)
+	}
+	if users.err == nil || !strings.Contains(users.err.Error(), "@Nobody") {
+		t.Errorf("err = %v, want unresolved mention error", users.err)
+	}
+}
+
+func TestMatchUser(t *testing.T) {
+	users := []api.User{
+		{AccountID: "1", DisplayName: "Jane Doe", Email: "jane@example.com"},
+		{AccountID: "2", DisplayName: "Jane Doe"},
+		{AccountID: "3", DisplayName: "Janet", PublicName: "jd"},
+	}
+	tests := []struct {
+		name    string
+		users   []api.User
+		query   string
+		want    string
+		wantErr string
+	}{
+		{name: "email", users: users, query: "JANE@example.com", want: "1"},
+		{name: "public name", users: users, query: "jd", want: "3"},
+		{name: "duplicate display names", users: users, query: "Jane Doe", wantErr: "2 users match"},
+		{name: "single fuzzy result", users: users[2:], query: "Jan", want: "3"},
+		{name: "no exact match among several", users: users, query: "Jan", wantErr: "no user found"},
+		{name: "no results", query: "x", wantErr: "no user found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchUser(tt.users, tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("matchUser() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.AccountID != tt.want {
+				t.Errorf("matchUser() = %+v, %v, want account %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareUserNames(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("accountId") {
+		case "acc-jane":
+			_, _ = w.Write([]byte(`{"accountId":"acc-jane","displayName":"Jane Doe"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	var opts converter.StorageOptions
+	prepareUserNames(context.Background(), client, &opts)
+	mention := func(id string) string {
+		return `<ac:link><ri:user ri:account-id="` + id + `" /></ac:link>`
+	}
+	storage := "<p>" + mention("acc-jane") + ", " + mention("acc-gone") + ", " + mention("acc-jane") + ", " + mention("acc-gone") + "</p>"
+	out, err := converter.StorageToMarkdownWithOptions(storage, opts)
+	if err != nil {
+		t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+	}
+	if want := "@Jane Doe, @acc-gone, @Jane Doe, @acc-gone"; strings.TrimSpace(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if lookups != 2 {
+		t.Errorf("lookups = %d, want 2 (cached)", lookups)
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var metaRemoveLabels []string
+
+var pageMetaCmd = &cobra.Command{
+	Use:   "meta PAGE_ID",
+	Short: "Change the title, parent, status, or labels of a page",
+	Long: `Change any of the title, parent, status, and labels of a page in one call,
+leaving the body as it is. A new title, parent, or status publishes the
+page's current body again as the next version with them; labels alone are
+added and removed without making a new version.
+
+--label adds labels the page does not have, and --remove-label removes
+labels it has.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &pageParent); err != nil {
+			return err
+		}
+		if pageTitle == "" && pageParent == "" && pageStatus == "" && len(pageLabels) == 0 && len(metaRemoveLabels) == 0 {
+			return fmt.Errorf("nothing to change: give --title, --parent, --status, --label, or --remove-label")
+		}
+		switch pageStatus {
+		case "", "current", "draft":
+		default:
+			return fmt.Errorf("invalid status %q: must be current or draft", pageStatus)
+		}
+		for _, label := range metaRemoveLabels {
+			if slices.Contains(pageLabels, label) {
+				return fmt.Errorf("label %s is both added and removed", label)
+			}
+		}
+
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		existing, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		if err := checkPageProtection(cmd.Context(), client, cfg, pageID, existing.SpaceID, "update"); err != nil {
+			return err
+		}
+
+		meta := pageMeta{title: pageTitle, parent: pageParent, status: pageStatus, labels: pageLabels}
+		if meta.status == "" {
+			meta.status = existing.Status
+		}
+		if meta.status == "" {
+			meta.status = "current"
+		}
+		newVersion := (meta.title != "" && meta.title != existing.Title) ||
+			(meta.parent != "" && meta.parent != existing.ParentID) ||
+			(existing.Status != "" && meta.status != existing.Status)
+
+		result := existing
+		switch {
+		case newVersion:
+			if existing.Body == nil || existing.Body.Storage == nil {
+				return fmt.Errorf("page %s has no storage format body to republish", pageID)
+			}
+			result, err = publishPageVersion(cmd.Context(), client, existing, meta, existing.Body.Storage.Value, &attachments{}, updateMsg)
+			if err != nil {
+				return err
+			}
+		case dryRun:
+		case len(meta.labels) > 0:
+			if _, err := client.AddLabels(cmd.Context(), pageID, meta.labels); err != nil {
+				return fmt.Errorf("adding labels: %w", err)
+			}
+		}
+
+		if dryRun {
+			if outputJSON {
+				return printJSON(result)
+			}
+			if newVersion {
+				printDryRunUpdate(existing, result)
+			}
+			if len(meta.labels) > 0 {
+				fmt.Printf("Would add labels to page %s: %s\n", pageID, strings.Join(meta.labels, ", "))
+			}
+			if len(metaRemoveLabels) > 0 {
+				fmt.Printf("Would remove labels from page %s: %s\n", pageID, strings.Join(metaRemoveLabels, ", "))
+			}
+			return nil
+		}
+		for _, label := range metaRemoveLabels {
+			if err := client.RemoveLabel(cmd.Context(), pageID, label); err != nil {
+				return fmt.Errorf("removing label %s: %w", label, err)
+			}
+		}
+
+		if outputJSON {
+			return printJSON(result)
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
+		if err != nil || space.Key == "" {
+			fmt.Fprintf(os.Stderr, "Warning: page updated but could not resolve space key for URL\n")
+			fmt.Println(result.ID)
+			return nil
+		}
+		fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
+		return nil
+	},
+}
+
+func init() {
+	pageMetaCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "New page title")
+	pageMetaCmd.Flags().StringVarP(&pageParent, "parent", "p", "", "New parent page ID")
+	pageMetaCmd.Flags().StringVar(&pageStatus, "status", "", "New page status: current, draft")
+	pageMetaCmd.Flags().StringSliceVar(&pageLabels, "label", nil, "Label to add (repeatable)")
+	pageMetaCmd.Flags().StringSliceVar(&metaRemoveLabels, "remove-label", nil, "Label to remove (repeatable)")
+	pageMetaCmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version message")
+	pageMetaCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageMetaCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	pageMetaCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+
+	pageCmd.AddCommand(pageMetaCmd)
+}
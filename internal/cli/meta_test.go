@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageMetaCmd(t *testing.T) {
+	var requests []string
+	var update api.PageUpdateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/wiki/api/v2/pages/42":
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1","status":"current","title":"Plan","parentId":"5","version":{"number":7},"body":{"storage":{"representation":"storage","value":"<p>Body</p>"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/wiki/api/v2/pages/42":
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &update); err != nil {
+				t.Errorf("decoding update: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1"}`))
+		case r.Method == "POST" && r.URL.Path == "/wiki/rest/api/content/42/label":
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/wiki/rest/api/content/42/label/"):
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	run := func() (string, error) {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := pageMetaCmd.RunE(testCommand(), []string{"42"})
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+
+	resetPageFlags(t)
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "nothing to change") {
+		t.Errorf("no flags: err = %v", err)
+	}
+	pageLabels, metaRemoveLabels = []string{"a"}, []string{"a"}
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "both added and removed") {
+		t.Errorf("same label added and removed: err = %v", err)
+	}
+
+	// Labels alone make no new version
+	pageLabels, metaRemoveLabels = []string{"ops"}, []string{"old"}
+	if _, err := run(); err != nil {
+		t.Fatalf("meta --label: %v", err)
+	}
+	if want := []string{"POST /wiki/rest/api/content/42/label", "DELETE /wiki/rest/api/content/42/label/old"}; strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+
+	requests = nil
+	pageLabels, metaRemoveLabels = nil, nil
+	pageTitle, pageParent, pageStatus, dryRun = "Plan B", "9", "draft", true
+	stdout, err := run()
+	if err != nil {
+		t.Fatalf("meta --dry-run: %v", err)
+	}
+	if want := `Would update page 42 "Plan B" to version 8 (11 bytes of storage), renaming it from "Plan", moving it under page 9, changing its status from current to draft` + "\n"; stdout != want {
+		t.Errorf("--dry-run stdout = %q, want %q", stdout, want)
+	}
+	if len(requests) != 0 {
+		t.Errorf("dry run made requests %v", requests)
+	}
+
+	dryRun = false
+	stdout, err = run()
+	if err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	if want := server.URL + "/wiki/spaces/DOCS/pages/42\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if update.Title != "Plan B" || update.ParentID != "9" || update.Status != "draft" || update.Version.Number != 8 ||
+		update.Body == nil || update.Body.Value != "<p>Body</p>" {
+		t.Errorf("update = %+v", update)
+	}
+}
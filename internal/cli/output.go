@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/grantcarthew/acon/internal/api"
+)
+
+var (
+	// outputFormat is the --output format of list and search results
+	outputFormat string
+	// outputJSONL writes list and search results as JSON Lines
+	outputJSONL bool
+	// outputIDs and outputURLs write only the ID or URL of each result
+	outputIDs  bool
+	outputURLs bool
+)
+
+const (
+	outputFormats = "table, tsv, csv, yaml, json"
+	jsonlUsage    = "Output each result as JSON on its own line (JSON Lines)"
+	idsUsage      = "Output only the ID of each result, one per line"
+	urlsUsage     = "Output only the URL of each result, one per line"
+)
+
+// resultTable is list or search results as rows of named columns, for the
+// --output formats other than json
+type resultTable struct {
+	columns []string
+	rows    [][]string
+}
+
+// resultFormat returns the --output format, json if only --json is set,
+// jsonl, template, ids, or urls if --jsonl, --template, --ids, or --urls
+// is, or "" for the command's usual text output
+func resultFormat() (string, error) {
+	var given []string
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{
+		{outputJSON, "--json"},
+		{outputJSONL, "--jsonl"},
+		{outputFormat != "", "--output"},
+		{outputTemplate != "", "--template"},
+		{outputIDs, "--ids"},
+		{outputURLs, "--urls"},
+	} {
+		if f.set {
+			given = append(given, f.name)
+		}
+	}
+	// --output json is --json, so the two agree
+	if len(given) > 1 && !(len(given) == 2 && outputJSON && outputFormat == "json") {
+		return "", fmt.Errorf("%s cannot be combined", strings.Join(given, " and "))
+	}
+
+	switch {
+	case outputJSONL:
+		return "jsonl", nil
+	case outputIDs:
+		return "ids", nil
+	case outputURLs:
+		return "urls", nil
+	case outputTemplate != "":
+		if _, err := parseOutputTemplate(); err != nil {
+			return "", err
+		}
+		return "template", nil
+	}
+	switch outputFormat {
+	case "":
+		if outputJSON {
+			return "json", nil
+		}
+		return "", nil
+	case "json", "table", "tsv", "csv", "yaml":
+		return outputFormat, nil
+	}
+	return "", fmt.Errorf("invalid --output %q: use %s", outputFormat, outputFormats)
+}
+
+// viewFormat is resultFormat for commands that show a single item, which
+// have no columns for the tabular formats
+func viewFormat() (string, error) {
+	format, err := resultFormat()
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case "", "json", "template":
+		return format, nil
+	}
+	return "", fmt.Errorf("--output %s is only for list and search results: use --json or --template", format)
+}
+
+// printResults writes t in format. The next cursor, if any, goes after the
+// table and into the yaml document, but to stderr for tsv and csv so their
+// output stays plain rows.
+func printResults(out io.Writer, format string, t resultTable, next api.Cursor) error {
+	switch format {
+	case "table":
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(t.columns, "\t")))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(cleanCells(row), "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		printNextCursor(out, next)
+	case "tsv":
+		fmt.Fprintln(out, strings.Join(t.columns, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(out, strings.Join(cleanCells(row), "\t"))
+		}
+		printNextCursor(os.Stderr, next)
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write(t.columns); err != nil {
+			return err
+		}
+		if err := w.WriteAll(t.rows); err != nil {
+			return err
+		}
+		printNextCursor(os.Stderr, next)
+	case "yaml":
+		if len(t.rows) == 0 {
+			fmt.Fprintln(out, "results: []")
+		} else {
+			fmt.Fprintln(out, "results:")
+		}
+		for _, row := range t.rows {
+			for i, column := range t.columns {
+				lead := "    "
+				if i == 0 {
+					lead = "  - "
+				}
+				fmt.Fprintf(out, "%s%s: %s\n", lead, column, strconv.Quote(row[i]))
+			}
+		}
+		if next != "" {
+			fmt.Fprintf(out, "next_cursor: %s\n", strconv.Quote(string(next)))
+		}
+	default:
+		return fmt.Errorf("invalid output format %q", format)
+	}
+	return nil
+}
+
+// printJSONLines writes each item as JSON on a line of its own, and the next
+// cursor, if any, to stderr
+func printJSONLines[T any](out io.Writer, items []T, next api.Cursor) error {
+	enc := json.NewEncoder(out)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+	}
+	printNextCursor(os.Stderr, next)
+	return nil
+}
+
+// printLines writes each non-empty line, as the ID or URL of a result, and
+// the next cursor, if any, to stderr
+func printLines(out io.Writer, lines []string, next api.Cursor) {
+	for _, line := range lines {
+		if line != "" {
+			fmt.Fprintln(out, line)
+		}
+	}
+	printNextCursor(os.Stderr, next)
+}
+
+var cellSpaces = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// cleanCells replaces the tabs and line breaks in cells with spaces, so each
+// row stays on one line of a table or tsv
+func cleanCells(row []string) []string {
+	cells := make([]string, len(row))
+	for i, cell := range row {
+		cells[i] = cellSpaces.Replace(cell)
+	}
+	return cells
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestResultFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		json    bool
+		jsonl   bool
+		ids     bool
+		urls    bool
+		want    string
+		wantErr string
+	}{
+		{ids: true, want: "ids"},
+		{urls: true, want: "urls"},
+		{ids: true, urls: true, wantErr: "--ids and --urls cannot be combined"},
+		{want: ""},
+		{jsonl: true, want: "jsonl"},
+		{format: "csv", jsonl: true, wantErr: "--jsonl and --output cannot be combined"},
+		{json: true, want: "json"},
+		{format: "csv", want: "csv"},
+		{format: "json", json: true, want: "json"},
+		{format: "tsv", json: true, wantErr: "--json and --output cannot be combined"},
+		{format: "xml", wantErr: `invalid --output "xml"`},
+	}
+	for _, tt := range tests {
+		resetPageFlags(t)
+		outputFormat, outputJSON, outputJSONL = tt.format, tt.json, tt.jsonl
+		outputIDs, outputURLs = tt.ids, tt.urls
+		got, err := resultFormat()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resultFormat(%q, %v) error = %v, want %q", tt.format, tt.json, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resultFormat(%q, %v) = %q, %v, want %q", tt.format, tt.json, got, err, tt.want)
+		}
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	table := resultTable{
+		columns: []string{"id", "title"},
+		rows:    [][]string{{"1", "Home"}, {"22", "Say \"hi\", then\tleave"}},
+	}
+	tests := []struct {
+		format string
+		next   api.Cursor
+		want   string
+	}{
+		{format: "table", next: "abc", want: "ID  TITLE\n1   Home\n22  Say \"hi\", then leave\nNext Cursor: abc\n"},
+		{format: "tsv", want: "id\ttitle\n1\tHome\n22\tSay \"hi\", then leave\n"},
+		{format: "csv", want: "id,title\n1,Home\n22,\"Say \"\"hi\"\", then\tleave\"\n"},
+		{format: "yaml", next: "abc", want: "results:\n  - id: \"1\"\n    title: \"Home\"\n  - id: \"22\"\n    title: \"Say \\\"hi\\\", then\\tleave\"\nnext_cursor: \"abc\"\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := printResults(&out, tt.format, table, tt.next); err != nil {
+			t.Fatalf("printResults(%s): %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("printResults(%s) = %q, want %q", tt.format, out.String(), tt.want)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := printResults(&out, "yaml", resultTable{columns: []string{"id"}}, ""); err != nil || out.String() != "results: []\n" {
+		t.Errorf("empty yaml = %q, %v", out.String(), err)
+	}
+}
+
+func TestPrintJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	spaces := []api.Space{{ID: "1", Key: "OPS"}, {ID: "2", Key: "DEV"}}
+	if err := printJSONLines(&out, spaces, ""); err != nil {
+		t.Fatalf("printJSONLines: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"id":"1","key":"OPS"`) || !strings.HasPrefix(lines[1], `{"id":"2","key":"DEV"`) {
+		t.Errorf("output = %q, want one space per line", out.String())
+	}
+}
+
+func TestSpaceListCmd_Output(t *testing.T) {
+	resetPageFlags(t)
+	outputFormat = "csv"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"id":"1","key":"OPS","name":"Operations, Team","type":"global"}]}`))
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := spaceListCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("RunE: %v", runErr)
+	}
+	if want := "key,name,type,id\nOPS,\"Operations, Team\",global,1\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+
+	outputFormat, outputURLs = "", true
+	finish = captureStdStreams(t)
+	runErr = spaceListCmd.RunE(testCommand(), nil)
+	stdout, _ = finish()
+	if want := server.URL + "/wiki/spaces/OPS\n"; runErr != nil || stdout != want {
+		t.Errorf("--urls stdout = %q, %v, want %q", stdout, runErr, want)
+	}
+}
@@ -17,20 +17,39 @@ import (
 )
 
 const (
-	maxContentSize = 10 * 1024 * 1024 // 10MB
+	maxContentSize      = 10 * 1024 * 1024 // 10MB
+	maxDeleteChildCount = 100              // Child pages counted when confirming a delete
 )
 
 var (
-	pageTitle  string
-	pageFile   string
-	pageSpace  string
-	pageParent string
-	pageLimit  int
-	pageSort   string
-	pageDesc   bool
-	outputJSON bool
-	updateMsg  string
-	moveParent string
+	pageTitle   string
+	pageFile    string
+	pageSpace   string
+	pageParent  string
+	pageLimit   int
+	pageCursor  string
+	pageSort    string
+	pageDesc    bool
+	outputJSON  bool
+	updateMsg   string
+	moveParent  string
+	pageTOC     bool
+	allowHTML   bool
+	bareDates   bool
+	strict      bool
+	typography  bool
+	subscript   bool
+	hardWraps   bool
+	pageStatus  string
+	pageLabels  []string
+	tocMin      int
+	tocMax      int
+	headingOff  int
+	admonitions bool
+	roundTrip   bool
+	keepHTML    bool
+	downloadAtt bool
+	childLinks  bool
 
 	// stdinReader is the source for stdin input. Override in tests.
 	stdinReader io.Reader = os.Stdin
@@ -101,6 +120,10 @@ func pageURL(baseURL, spaceKey, pageID string) string {
 	return fmt.Sprintf("%s/wiki/spaces/%s/pages/%s", baseURL, spaceKey, pageID)
 }
 
+func spaceURL(baseURL, spaceKey string) string {
+	return fmt.Sprintf("%s/wiki/spaces/%s", baseURL, spaceKey)
+}
+
 var pageCmd = &cobra.Command{
 	Use:   "page",
 	Short: "Manage Confluence pages",
@@ -110,14 +133,51 @@ var pageCmd = &cobra.Command{
 var pageCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new page",
-	Long:  "Create a new Confluence page from markdown file or stdin",
+	Long: `Create a new Confluence page from markdown file, stdin, or a page template
+(see acon template). --confluence-template creates the page from a template
+kept in Confluence instead, as it is.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &pageParent); err != nil {
+			return err
+		}
+		if pageTemplate != "" && pageFile != "" {
+			return fmt.Errorf("--template and --file cannot be combined")
+		}
+		if confluenceTemplate != "" && (pageTemplate != "" || pageFile != "") {
+			return fmt.Errorf("--confluence-template cannot be combined with --template or --file")
+		}
+		if len(templateVars) > 0 && pageTemplate == "" {
+			return fmt.Errorf("--var requires --template")
+		}
+
 		client, cfg, err := initClient()
 		if err != nil {
 			return err
 		}
 
-		spaceKey := pageSpace
+		var content []byte
+		var storage string
+		markdownPath := pageFile
+		switch {
+		case confluenceTemplate != "":
+			storage, err = contentTemplateStorage(cmd.Context(), client, confluenceTemplate)
+		case pageTemplate != "":
+			content, markdownPath, err = renderPageTemplate(pageTemplate, templateVars)
+		default:
+			content, err = readAndValidateContent(pageFile)
+		}
+		if err != nil {
+			return err
+		}
+		meta, content, err := readPageMeta(content)
+		if err != nil {
+			return err
+		}
+		if meta.title == "" {
+			return fmt.Errorf("title required: use --title flag or set title in frontmatter")
+		}
+
+		spaceKey := meta.space
 		if spaceKey == "" {
 			spaceKey = cfg.SpaceKey
 		}
@@ -138,78 +198,174 @@ var pageCreateCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "[Page Create] Space ID: %s\n", space.ID)
 		}
 
-		content, err := readAndValidateContent(pageFile)
+		opts := converterOptions(cfg, spaceKey)
+		if err := applyConverterFlags(&opts); err != nil {
+			return err
+		}
+		var result *api.Page
+		if confluenceTemplate != "" {
+			result, err = publishNewPage(cmd.Context(), client, space.ID, meta, storage, &attachments{})
+		} else {
+			result, err = createPage(cmd.Context(), client, converter.NewConverter(opts), converter.Document{}, space.ID, meta, content, markdownPath)
+		}
 		if err != nil {
 			return err
 		}
 
-		if verbose {
-			fmt.Fprintf(os.Stderr, "[Page Create] Read %d bytes of markdown content\n", len(content))
-			fmt.Fprintf(os.Stderr, "[Page Create] Converting markdown to Confluence storage format\n")
+		if outputJSON {
+			return printJSON(result)
+		}
+		if dryRun {
+			printDryRunCreate(result, spaceKey)
+			return nil
 		}
+		fmt.Println(pageURL(cfg.BaseURL, spaceKey, result.ID))
+		return nil
+	},
+}
 
-		htmlContent := converter.MarkdownToStorage(string(content))
+// createPage converts the markdown content of doc with conv and creates it as a
+// page in the space with ID spaceID, then uploads the images it attaches
+// and applies the labels and appearance in meta. markdownPath locates
+// relative image paths, as for prepareAttachments. With --dry-run, nothing
+// is created and the page that would be is returned, without an ID.
+func createPage(ctx context.Context, client *api.Client, conv *converter.Converter, doc converter.Document, spaceID string, meta pageMeta, content []byte, markdownPath string) (*api.Page, error) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Create] Read %d bytes of markdown content\n", len(content))
+		fmt.Fprintf(os.Stderr, "[Page Create] Converting markdown to Confluence storage format\n")
+	}
 
-		if verbose {
-			fmt.Fprintf(os.Stderr, "[Page Create] Converted to %d bytes of storage format\n", len(htmlContent))
-		}
+	htmlContent, files, err := convertMarkdown(ctx, client, conv, doc, content, markdownPath)
+	if err != nil {
+		return nil, err
+	}
 
-		req := &api.PageCreateRequest{
-			SpaceID: space.ID,
-			Status:  "current",
-			Title:   pageTitle,
-			Body: &api.PageBodyWrite{
-				Representation: "storage",
-				Value:          htmlContent,
-			},
-		}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Create] Converted to %d bytes of storage format\n", len(htmlContent))
+	}
+	return publishNewPage(ctx, client, spaceID, meta, htmlContent, files)
+}
 
-		if pageParent != "" {
-			req.ParentID = pageParent
-			if verbose {
-				fmt.Fprintf(os.Stderr, "[Page Create] Setting parent ID: %s\n", pageParent)
-			}
-		}
+// convertMarkdown converts the markdown content with conv, and the settings
+// in doc, to storage format ready to publish, returning it with the local
+// images to upload first. markdownPath locates relative image paths, as for
+// prepareAttachments. Commands converting several documents share one conv.
+func convertMarkdown(ctx context.Context, client *api.Client, conv *converter.Converter, doc converter.Document, content []byte, markdownPath string) (string, *attachments, error) {
+	files, err := prepareAttachments(ctx, &doc, markdownPath)
+	if err != nil {
+		return "", nil, err
+	}
+	users := prepareMentions(ctx, client, &doc)
+	htmlContent, err := conv.ConvertDocument(string(content), doc)
+	if err != nil {
+		return "", nil, fmt.Errorf("converting markdown: %w", err)
+	}
+	if files.err != nil {
+		return "", nil, files.err
+	}
+	if users.err != nil {
+		return "", nil, users.err
+	}
+	if err := converter.ValidateStorage(htmlContent); err != nil {
+		return "", nil, fmt.Errorf("refusing to upload: %w", err)
+	}
+	return htmlContent, files, nil
+}
 
+// publishNewPage creates a page with the storage format body htmlContent in
+// the space with ID spaceID, then uploads files to it and applies the labels
+// and appearance in meta. With --dry-run, nothing is created and the page
+// that would be is returned, without an ID.
+func publishNewPage(ctx context.Context, client *api.Client, spaceID string, meta pageMeta, htmlContent string, files *attachments) (*api.Page, error) {
+	req := &api.PageCreateRequest{
+		SpaceID: spaceID,
+		Status:  meta.status,
+		Title:   meta.title,
+		Body: &api.PageBodyWrite{
+			Representation: "storage",
+			Value:          htmlContent,
+		},
+	}
+
+	if meta.parent != "" {
+		req.ParentID = meta.parent
 		if verbose {
-			fmt.Fprintf(os.Stderr, "[Page Create] Creating page: %s\n", pageTitle)
+			fmt.Fprintf(os.Stderr, "[Page Create] Setting parent ID: %s\n", meta.parent)
 		}
+	}
 
-		result, err := client.CreatePage(cmd.Context(), req)
-		if err != nil {
-			return fmt.Errorf("creating page: %w", err)
-		}
+	if dryRun {
+		return &api.Page{
+			SpaceID:  spaceID,
+			Status:   meta.status,
+			Title:    meta.title,
+			ParentID: meta.parent,
+			Version:  &api.Version{Number: 1},
+			Body:     &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: htmlContent}},
+		}, nil
+	}
 
-		if verbose {
-			fmt.Fprintf(os.Stderr, "[Page Create] Page created successfully, ID: %s\n", result.ID)
-		}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Create] Creating page: %s\n", meta.title)
+	}
 
-		if outputJSON {
-			return printJSON(result)
+	result, err := client.CreatePage(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("creating page: %w", err)
+	}
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Create] Page created successfully, ID: %s\n", result.ID)
+	}
+
+	// Attachments need a page to hang off, so images go up after creation
+	if err := files.upload(ctx, client, result.ID); err != nil {
+		return nil, fmt.Errorf("page %s created but attachment upload failed: %w", result.ID, err)
+	}
+	if len(meta.labels) > 0 {
+		if _, err := client.AddLabels(ctx, result.ID, meta.labels); err != nil {
+			return nil, fmt.Errorf("page %s created but adding labels failed: %w", result.ID, err)
 		}
-		fmt.Println(pageURL(cfg.BaseURL, spaceKey, result.ID))
-		return nil
-	},
+	}
+	if err := setPageAppearance(ctx, client, result.ID, meta); err != nil {
+		return nil, fmt.Errorf("page %s created but setting its appearance failed: %w", result.ID, err)
+	}
+	return result, nil
 }
 
 var pageViewCmd = &cobra.Command{
-	Use:   "view PAGE_ID",
+	Use:   "view [PAGE_ID]",
 	Short: "View a page",
-	Long:  "View details of a Confluence page",
-	Args:  cobra.ExactArgs(1),
+	Long: `View details of a Confluence page, given by ID or by --title, which is
+looked up in --space or the default space`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, _, err := initClient()
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		format, err := viewFormat()
 		if err != nil {
 			return err
 		}
 
-		pageID := args[0]
+		pageID, err := resolvePageArg(cmd.Context(), client, cfg, args)
+		if err != nil {
+			return err
+		}
 
 		if verbose {
 			fmt.Fprintf(os.Stderr, "[Page View] Fetching page: %s\n", pageID)
 		}
 
-		page, err := client.GetPage(cmd.Context(), pageID)
+		// Markdown is rendered from the page's ADF, which holds the content
+		// Cloud's editor writes; JSON and templates show storage format
+		getPage := client.GetPage
+		if format == "" {
+			getPage = client.GetPageADF
+		}
+		page, err := getPage(cmd.Context(), pageID)
 		if err != nil {
 			return fmt.Errorf("getting page: %w", err)
 		}
@@ -218,86 +374,156 @@ var pageViewCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "[Page View] Page title: %s\n", page.Title)
 		}
 
-		if outputJSON {
+		switch format {
+		case "json":
 			return printJSON(page)
+		case "template":
+			return printTemplate(os.Stdout, []*api.Page{page})
 		}
-		if page.Body != nil && page.Body.Storage != nil {
-			if verbose {
-				fmt.Fprintf(os.Stderr, "[Page View] Converting %d bytes from storage to markdown\n", len(page.Body.Storage.Value))
-			}
-			markdown, err := converter.StorageToMarkdown(page.Body.Storage.Value)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to convert to markdown: %v\n", err)
-				fmt.Println(page.Body.Storage.Value)
-			} else {
-				if verbose {
-					fmt.Fprintf(os.Stderr, "[Page View] Converted to %d bytes of markdown\n", len(markdown))
-				}
-				fmt.Println(markdown)
-			}
+		printPageMarkdown(cmd.Context(), client, cfg.BaseURL, page, "Page View")
+		if downloadAtt {
+			return downloadAttachments(cmd.Context(), client, page.ID, converter.AttachmentDir, pageImageAttachments(page))
 		}
 		return nil
 	},
 }
 
+// pageBody returns the page body in ADF, or failing that in storage
+// format, and the format's name. A page without a body returns nil.
+func pageBody(page *api.Page) (*api.BodyContent, string) {
+	switch {
+	case page.Body == nil:
+		return nil, ""
+	case page.Body.AtlasDocFormat != nil:
+		return page.Body.AtlasDocFormat, "ADF"
+	case page.Body.Storage != nil:
+		return page.Body.Storage, "storage"
+	}
+	return nil, ""
+}
+
+// pageImageAttachments returns the filenames of the page's attachments shown
+// as images in its body
+func pageImageAttachments(page *api.Page) []string {
+	body, format := pageBody(page)
+	switch format {
+	case "ADF":
+		return converter.ADFImageAttachments(body.Value, page.ID)
+	case "storage":
+		return converter.ImageAttachments(body.Value)
+	}
+	return nil
+}
+
+// printPageMarkdown prints the page body converted to markdown, falling back
+// to the raw body if conversion fails. Links to other pages and user
+// mentions are resolved through client. logPrefix tags verbose output.
+func printPageMarkdown(ctx context.Context, client *api.Client, baseURL string, page *api.Page, logPrefix string) {
+	body, _ := pageBody(page)
+	if body == nil {
+		return
+	}
+	markdown, err := pageMarkdown(ctx, client, baseURL, page, storageOptions(), logPrefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to convert to markdown: %v\n", err)
+		fmt.Println(body.Value)
+		return
+	}
+	fmt.Println(markdown)
+}
+
+// pageMarkdown converts the page body, in ADF or storage format, to
+// markdown with opts, resolving links to other pages and user mentions
+// through client. A page without a body converts to "". logPrefix tags
+// verbose output.
+func pageMarkdown(ctx context.Context, client *api.Client, baseURL string, page *api.Page, opts converter.StorageOptions, logPrefix string) (string, error) {
+	body, format := pageBody(page)
+	if body == nil {
+		return "", nil
+	}
+	opts.JiraURL = baseURL
+	preparePageLinks(ctx, client, baseURL, page.SpaceID, &opts)
+	prepareUserNames(ctx, client, &opts)
+	if childLinks {
+		prepareChildPages(ctx, client, baseURL, page, &opts)
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[%s] Converting %d bytes from %s to markdown\n", logPrefix, len(body.Value), format)
+	}
+	convert := converter.StorageToMarkdownWithOptions
+	if format == "ADF" {
+		convert = converter.ADFToMarkdownWithOptions
+	}
+	markdown, err := convert(body.Value, opts)
+	if err != nil {
+		return "", err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[%s] Converted to %d bytes of markdown\n", logPrefix, len(markdown))
+	}
+	return markdown, nil
+}
+
+// storageOptions returns the storage → Markdown options set by flags
+func storageOptions() converter.StorageOptions {
+	return converter.StorageOptions{Admonitions: admonitions, RoundTrip: roundTrip, KeepHTML: keepHTML}
+}
+
 var pageUpdateCmd = &cobra.Command{
-	Use:   "update PAGE_ID",
+	Use:   "update [PAGE_ID]",
 	Short: "Update a page",
-	Long:  "Update an existing Confluence page",
-	Args:  cobra.ExactArgs(1),
+	Long: `Update an existing Confluence page. Without PAGE_ID, the page is the one
+titled --title, looked up in --space or the default space, and keeps its
+title.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, cfg, err := initClient()
 		if err != nil {
 			return err
 		}
 
-		pageID := args[0]
+		pageID, err := resolvePageArg(cmd.Context(), client, cfg, args)
+		if err != nil {
+			return err
+		}
 
 		existing, err := client.GetPage(cmd.Context(), pageID)
 		if err != nil {
 			return fmt.Errorf("getting existing page: %w", err)
 		}
 
+		if err := checkPageProtection(cmd.Context(), client, cfg, pageID, existing.SpaceID, "update"); err != nil {
+			return err
+		}
+
 		content, err := readAndValidateContent(pageFile)
 		if err != nil {
 			return err
 		}
-
-		htmlContent := converter.MarkdownToStorage(string(content))
-
-		title := pageTitle
-		if title == "" {
-			title = existing.Title
+		meta, content, err := readPageMeta(content)
+		if err != nil {
+			return err
 		}
 
-		newVersion := 1
-		if existing.Version != nil {
-			newVersion = existing.Version.Number + 1
+		opts, err := converterOptionsForSpaceID(cmd.Context(), client, cfg, existing.SpaceID)
+		if err != nil {
+			return err
 		}
-
-		req := &api.PageUpdateRequest{
-			ID:      pageID,
-			SpaceID: existing.SpaceID,
-			Status:  "current",
-			Title:   title,
-			Body: &api.PageBodyWrite{
-				Representation: "storage",
-				Value:          htmlContent,
-			},
-			Version: &api.Version{
-				Number:  newVersion,
-				Message: updateMsg,
-			},
+		if err := applyConverterFlags(&opts); err != nil {
+			return err
 		}
-
-		result, err := client.UpdatePage(cmd.Context(), pageID, req)
+		result, err := updatePage(cmd.Context(), client, converter.NewConverter(opts), converter.Document{}, existing, meta, content, pageFile, updateMsg)
 		if err != nil {
-			return fmt.Errorf("updating page: %w", err)
+			return err
 		}
 
 		if outputJSON {
 			return printJSON(result)
 		}
+		if dryRun {
+			printDryRunUpdate(existing, result)
+			return nil
+		}
 		space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: page updated but could not resolve space key for URL: %v\n", err)
@@ -314,33 +540,203 @@ var pageUpdateCmd = &cobra.Command{
 	},
 }
 
+// updatePage converts the markdown content of doc with conv and makes it the
+// next version of the existing page, with the title, status, parent,
+// labels, and appearance in meta. The title and parent are left as they are
+// if meta leaves them out. markdownPath locates relative image paths, as for
+// prepareAttachments, and message is the version message. With --dry-run,
+// nothing is changed and the version that would be made is returned.
+func updatePage(ctx context.Context, client *api.Client, conv *converter.Converter, doc converter.Document, existing *api.Page, meta pageMeta, content []byte, markdownPath, message string) (*api.Page, error) {
+	htmlContent, files, err := convertMarkdown(ctx, client, conv, doc, content, markdownPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return publishPageVersion(ctx, client, existing, meta, htmlContent, files, message)
+}
+
+// publishPageVersion makes the storage format body htmlContent the next
+// version of the existing page, uploading files first, and applies the
+// title, status, parent, labels, and appearance in meta as updatePage
+// does. With --dry-run, nothing is changed and the version that would be
+// made is returned.
+func publishPageVersion(ctx context.Context, client *api.Client, existing *api.Page, meta pageMeta, htmlContent string, files *attachments, message string) (*api.Page, error) {
+	pageID := existing.ID
+	title := meta.title
+	if title == "" {
+		title = existing.Title
+	}
+
+	newVersion := 1
+	if existing.Version != nil {
+		newVersion = existing.Version.Number + 1
+	}
+
+	req := &api.PageUpdateRequest{
+		ID:      pageID,
+		SpaceID: existing.SpaceID,
+		Status:  meta.status,
+		Title:   title,
+		Body: &api.PageBodyWrite{
+			Representation: "storage",
+			Value:          htmlContent,
+		},
+		Version: &api.Version{
+			Number:  newVersion,
+			Message: message,
+		},
+	}
+
+	if meta.parent != "" && meta.parent != existing.ParentID {
+		req.ParentID = meta.parent
+	}
+
+	if dryRun {
+		parentID := existing.ParentID
+		if req.ParentID != "" {
+			parentID = req.ParentID
+		}
+		return &api.Page{
+			ID:       pageID,
+			SpaceID:  existing.SpaceID,
+			Status:   meta.status,
+			Title:    title,
+			ParentID: parentID,
+			Version:  &api.Version{Number: newVersion, Message: message},
+			Body:     &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: htmlContent}},
+		}, nil
+	}
+
+	// Upload first so the new version never references a missing image
+	if err := files.upload(ctx, client, pageID); err != nil {
+		return nil, err
+	}
+
+	result, err := client.UpdatePage(ctx, pageID, req)
+	if err != nil {
+		return nil, fmt.Errorf("updating page: %w", err)
+	}
+	if len(meta.labels) > 0 {
+		if _, err := client.AddLabels(ctx, pageID, meta.labels); err != nil {
+			return nil, fmt.Errorf("page %s updated but adding labels failed: %w", pageID, err)
+		}
+	}
+	if err := setPageAppearance(ctx, client, pageID, meta); err != nil {
+		return nil, fmt.Errorf("page %s updated but setting its appearance failed: %w", pageID, err)
+	}
+	return result, nil
+}
+
 var pageDeleteCmd = &cobra.Command{
-	Use:   "delete PAGE_ID",
+	Use:   "delete [PAGE_ID]",
 	Short: "Delete a page",
-	Long:  "Delete a Confluence page",
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete a Confluence page, given by ID or by --title, which is looked up
+in --space or the default space. The page's title and number of child pages
+are shown for confirmation first, unless --force (or --yes) is given; without
+a terminal to confirm from, --force is required.
+
+With --recursive, every page below it is deleted too, deepest first, with
+each deletion reported as it happens. Content other than pages, such as
+folders, stops the delete before anything is removed.
+
+A PAGE_ID of - reads page IDs from stdin, one per line, and deletes each in
+turn, stopping at the first failure. Stdin cannot then answer confirmations,
+so --force (or --dry-run) is required.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, _, err := initClient()
+		client, cfg, err := initClient()
 		if err != nil {
 			return err
 		}
 
-		pageID := args[0]
-
-		if err := client.DeletePage(cmd.Context(), pageID); err != nil {
-			return fmt.Errorf("deleting page: %w", err)
+		if len(args) == 1 && args[0] == stdinArg {
+			if pageTitle != "" {
+				return fmt.Errorf("--title cannot be used with page IDs from stdin")
+			}
+			if !force && !dryRun {
+				return fmt.Errorf("deleting page IDs from stdin requires --force, as stdin cannot also answer the confirmation")
+			}
+			ids, err := readPageIDs()
+			if err != nil {
+				return err
+			}
+			for i, id := range ids {
+				if err := deletePage(cmd.Context(), client, cfg, id); err != nil {
+					return fmt.Errorf("page %s (%d of %d pages done): %w", id, i, len(ids), err)
+				}
+			}
+			return nil
 		}
 
-		fmt.Printf("Page %s deleted successfully\n", pageID)
-		return nil
+		pageID, err := resolvePageArg(cmd.Context(), client, cfg, args)
+		if err != nil {
+			return err
+		}
+		return deletePage(cmd.Context(), client, cfg, pageID)
 	},
 }
 
+// deletePage deletes a page, and with --recursive the pages below it, after
+// confirming unless --force is set
+func deletePage(ctx context.Context, client *api.Client, cfg *config.Config, pageID string) error {
+	if deleteRecursive {
+		return deletePageTree(ctx, client, cfg, pageID)
+	}
+
+	if err := checkPageProtection(ctx, client, cfg, pageID, "", "delete"); err != nil {
+		return err
+	}
+
+	if dryRun || !force {
+		page, err := client.GetPage(ctx, pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		if dryRun {
+			fmt.Printf("Would delete page %s %q\n", page.ID, page.Title)
+			return nil
+		}
+		children, more, err := client.GetChildPages(ctx, pageID, maxDeleteChildCount, "")
+		if err != nil {
+			return fmt.Errorf("listing child pages: %w", err)
+		}
+		question := fmt.Sprintf("Delete page %s %q?", page.ID, page.Title)
+		if len(children) > 0 {
+			count := fmt.Sprint(len(children))
+			if more {
+				count = "more than " + count
+			}
+			pageWord := "pages"
+			if len(children) == 1 && !more {
+				pageWord = "page"
+			}
+			question = fmt.Sprintf("Delete page %s %q? It has %s child %s.", page.ID, page.Title, count, pageWord)
+		}
+		ok, err := confirm(question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("page %s not deleted", pageID)
+		}
+	}
+
+	if err := client.DeletePage(ctx, pageID); err != nil {
+		return fmt.Errorf("deleting page: %w", err)
+	}
+
+	fmt.Printf("Page %s deleted successfully\n", pageID)
+	return nil
+}
+
 var pageListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List pages",
 	Long:  "List pages in a Confluence space",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &pageParent); err != nil {
+			return err
+		}
 		client, cfg, err := initClient()
 		if err != nil {
 			return err
@@ -348,37 +744,77 @@ var pageListCmd = &cobra.Command{
 
 		var (
 			pages         []api.Page
-			hasMore       bool
+			next          api.Cursor
 			spaceKeyCache map[string]string
 		)
 
 		if pageParent != "" {
-			pages, hasMore, spaceKeyCache, err = listChildPages(cmd.Context(), client)
+			pages, next, spaceKeyCache, err = listChildPages(cmd.Context(), client)
 		} else {
-			pages, hasMore, spaceKeyCache, err = listPagesBySpace(cmd.Context(), client, cfg)
+			pages, next, spaceKeyCache, err = listPagesBySpace(cmd.Context(), client, cfg)
 		}
 		if err != nil {
 			return err
 		}
 
-		if outputJSON {
-			return printJSON(pages)
+		format, err := resultFormat()
+		if err != nil {
+			return err
+		}
+		switch format {
+		case "json":
+			return printJSON(newListOutput(pages, next))
+		case "jsonl":
+			return printJSONLines(os.Stdout, pages, next)
+		case "ids", "urls":
+			var lines []string
+			for _, page := range pages {
+				if format == "ids" {
+					lines = append(lines, page.ID)
+				} else if key := pageSpaceKey(cmd.Context(), client, page, spaceKeyCache); key != "" {
+					lines = append(lines, pageURL(cfg.BaseURL, key, page.ID))
+				}
+			}
+			printLines(os.Stdout, lines, next)
+			return nil
+		case "template":
+			if err := printTemplate(os.Stdout, pages); err != nil {
+				return err
+			}
+			printNextCursor(os.Stderr, next)
+			return nil
+		case "":
+		default:
+			t := resultTable{columns: []string{"id", "title", "status", "space", "url"}}
+			for _, page := range pages {
+				key := pageSpaceKey(cmd.Context(), client, page, spaceKeyCache)
+				url := ""
+				if key != "" {
+					url = pageURL(cfg.BaseURL, key, page.ID)
+				}
+				t.rows = append(t.rows, []string{page.ID, page.Title, page.Status, key, url})
+			}
+			return printResults(os.Stdout, format, t, next)
 		}
 
-		return printPageList(cmd.Context(), client, os.Stdout, cfg.BaseURL, pages, hasMore, spaceKeyCache)
+		if err := printPageList(cmd.Context(), client, os.Stdout, cfg.BaseURL, pages, next != "", spaceKeyCache); err != nil {
+			return err
+		}
+		printNextCursor(os.Stdout, next)
+		return nil
 	},
 }
 
 // listPagesBySpace fetches pages in a space using the user-supplied or configured
 // space key. The returned cache is primed with the resolved space so the printer
 // avoids a redundant lookup.
-func listPagesBySpace(ctx context.Context, client *api.Client, cfg *config.Config) ([]api.Page, bool, map[string]string, error) {
+func listPagesBySpace(ctx context.Context, client *api.Client, cfg *config.Config) ([]api.Page, api.Cursor, map[string]string, error) {
 	spaceKey := pageSpace
 	if spaceKey == "" {
 		spaceKey = cfg.SpaceKey
 	}
 	if spaceKey == "" {
-		return nil, false, nil, fmt.Errorf("space key required: use --space flag or set CONFLUENCE_SPACE_KEY")
+		return nil, "", nil, fmt.Errorf("space key required: use --space flag or set CONFLUENCE_SPACE_KEY")
 	}
 
 	if verbose {
@@ -387,37 +823,37 @@ func listPagesBySpace(ctx context.Context, client *api.Client, cfg *config.Confi
 
 	sortValue := mapSpaceSortValue(pageSort, pageDesc)
 	if sortValue == "" && pageSort != "" {
-		return nil, false, nil, fmt.Errorf("invalid sort value '%s' (valid: title, created, modified, id)", pageSort)
+		return nil, "", nil, fmt.Errorf("invalid sort value '%s' (valid: title, created, modified, id)", pageSort)
 	}
 
 	space, err := client.GetSpace(ctx, spaceKey)
 	if err != nil {
-		return nil, false, nil, fmt.Errorf("getting space: %w", err)
+		return nil, "", nil, fmt.Errorf("getting space: %w", err)
 	}
 
-	pages, hasMore, err := client.ListPages(ctx, space.ID, pageLimit, sortValue)
+	pages, next, err := client.ListPagesWithCursor(ctx, space.ID, pageLimit, sortValue, api.Cursor(pageCursor))
 	if err != nil {
-		return nil, false, nil, fmt.Errorf("listing pages: %w", err)
+		return nil, "", nil, fmt.Errorf("listing pages: %w", err)
 	}
 
-	return pages, hasMore, map[string]string{space.ID: spaceKey}, nil
+	return pages, next, map[string]string{space.ID: spaceKey}, nil
 }
 
 // listChildPages fetches children of a specific parent page. The returned cache
 // is empty; the printer populates it on first miss.
-func listChildPages(ctx context.Context, client *api.Client) ([]api.Page, bool, map[string]string, error) {
+func listChildPages(ctx context.Context, client *api.Client) ([]api.Page, api.Cursor, map[string]string, error) {
 	if verbose {
 		fmt.Fprintf(os.Stderr, "[Page List] Listing children of parent: %s (limit: %d, sort: %s)\n", pageParent, pageLimit, pageSort)
 	}
 
 	sortValue, valid := mapChildSortValue(pageSort, pageDesc)
 	if !valid {
-		return nil, false, nil, fmt.Errorf("invalid sort value '%s' (valid: web, title, created, modified, id)", pageSort)
+		return nil, "", nil, fmt.Errorf("invalid sort value '%s' (valid: web, title, created, modified, id)", pageSort)
 	}
 
-	pages, hasMore, err := client.GetChildPages(ctx, pageParent, pageLimit, sortValue)
+	pages, next, err := client.GetChildPagesWithCursor(ctx, pageParent, pageLimit, sortValue, api.Cursor(pageCursor))
 	if err != nil {
-		return nil, false, nil, fmt.Errorf("listing child pages: %w", err)
+		return nil, "", nil, fmt.Errorf("listing child pages: %w", err)
 	}
 
 	if pageSort == "title" {
@@ -432,29 +868,14 @@ func listChildPages(ctx context.Context, client *api.Client) ([]api.Page, bool,
 		})
 	}
 
-	return pages, hasMore, map[string]string{}, nil
+	return pages, next, map[string]string{}, nil
 }
 
 // printPageList renders a human-readable listing, resolving any space IDs not
 // already present in the cache.
 func printPageList(ctx context.Context, client *api.Client, out io.Writer, baseURL string, pages []api.Page, hasMore bool, spaceKeyCache map[string]string) error {
 	for _, page := range pages {
-		key, ok := spaceKeyCache[page.SpaceID]
-		if !ok {
-			space, err := client.GetSpaceByID(ctx, page.SpaceID)
-			switch {
-			case err != nil:
-				fmt.Fprintf(os.Stderr, "Warning: could not resolve space key for page %s: %v\n", page.ID, err)
-				// Negative-cache the miss so we do not repeat the lookup for every page in the same space.
-				spaceKeyCache[page.SpaceID] = ""
-			case space.Key == "":
-				fmt.Fprintf(os.Stderr, "Warning: space %s returned empty key for page %s\n", page.SpaceID, page.ID)
-				spaceKeyCache[page.SpaceID] = ""
-			default:
-				key = space.Key
-				spaceKeyCache[page.SpaceID] = key
-			}
-		}
+		key := pageSpaceKey(ctx, client, page, spaceKeyCache)
 		fmt.Fprintf(out, "Title: %s\n", page.Title)
 		fmt.Fprintf(out, "Status: %s\n", page.Status)
 		if key == "" {
@@ -477,45 +898,128 @@ func printPageList(ctx context.Context, client *api.Client, out io.Writer, baseU
 	return nil
 }
 
+// pageSpaceKey returns the key of the page's space, looked up once per space
+// and kept in spaceKeyCache, or "" with a warning if it cannot be resolved
+func pageSpaceKey(ctx context.Context, client *api.Client, page api.Page, spaceKeyCache map[string]string) string {
+	key, ok := spaceKeyCache[page.SpaceID]
+	if ok {
+		return key
+	}
+	space, err := client.GetSpaceByID(ctx, page.SpaceID)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: could not resolve space key for page %s: %v\n", page.ID, err)
+	case space.Key == "":
+		fmt.Fprintf(os.Stderr, "Warning: space %s returned empty key for page %s\n", page.SpaceID, page.ID)
+	default:
+		key = space.Key
+	}
+	// Cache misses too, so we do not repeat the lookup for every page in the same space.
+	spaceKeyCache[page.SpaceID] = key
+	return key
+}
+
 var pageMoveCmd = &cobra.Command{
 	Use:   "move PAGE_ID",
 	Short: "Move a page to a new parent",
-	Long:  "Move a Confluence page to a new parent page within the same space",
-	Args:  cobra.ExactArgs(1),
+	Long: `Move a Confluence page to a new parent page within the same space.
+
+A PAGE_ID of - reads page IDs from stdin, one per line, and moves each in
+turn, stopping at the first failure.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &moveParent); err != nil {
+			return err
+		}
 		client, cfg, err := initClient()
 		if err != nil {
 			return err
 		}
 
-		pageID := args[0]
-
 		if moveParent == "" {
 			return fmt.Errorf("--parent flag is required")
 		}
 
-		result, err := client.MovePage(cmd.Context(), pageID, moveParent)
-		if err != nil {
-			return fmt.Errorf("moving page: %w", err)
+		if args[0] == stdinArg {
+			ids, err := readPageIDs()
+			if err != nil {
+				return err
+			}
+			var results []*api.Page
+			for i, id := range ids {
+				result, err := movePage(cmd.Context(), client, cfg, id)
+				if err != nil {
+					return fmt.Errorf("page %s (%d of %d pages done): %w", id, i, len(ids), err)
+				}
+				if result != nil {
+					results = append(results, result)
+				}
+			}
+			if outputJSON && !dryRun {
+				return printJSON(results)
+			}
+			return nil
 		}
 
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		result, err := movePage(cmd.Context(), client, cfg, pageID)
+		if err != nil || result == nil {
+			return err
+		}
 		if outputJSON {
 			return printJSON(result)
 		}
-		space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
+		return nil
+	},
+}
+
+// movePage moves a page under --parent and, unless --json is set, prints its
+// URL. A dry run prints the move instead and returns no page.
+func movePage(ctx context.Context, client *api.Client, cfg *config.Config, pageID string) (*api.Page, error) {
+	if err := checkPageProtection(ctx, client, cfg, pageID, "", "move"); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		page, err := client.GetPage(ctx, pageID)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: page moved but could not resolve space key for URL: %v\n", err)
-			fmt.Println(result.ID)
-			return nil
+			return nil, fmt.Errorf("getting page: %w", err)
 		}
-		if space.Key == "" {
-			fmt.Fprintf(os.Stderr, "Warning: page moved but space %s returned empty key\n", result.SpaceID)
-			fmt.Println(result.ID)
-			return nil
+		parent, err := client.GetPage(ctx, moveParent)
+		if err != nil {
+			return nil, fmt.Errorf("getting parent page: %w", err)
 		}
-		fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
-		return nil
-	},
+		if page.SpaceID != parent.SpaceID {
+			return nil, fmt.Errorf("moving page: cross-space moves are not supported; use create and delete instead")
+		}
+		fmt.Printf("Would move page %s %q from under page %s to under page %s %q\n", page.ID, page.Title, page.ParentID, parent.ID, parent.Title)
+		return nil, nil
+	}
+
+	result, err := client.MovePage(ctx, pageID, moveParent)
+	if err != nil {
+		return nil, fmt.Errorf("moving page: %w", err)
+	}
+
+	if outputJSON {
+		return result, nil
+	}
+	space, err := client.GetSpaceByID(ctx, result.SpaceID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: page moved but could not resolve space key for URL: %v\n", err)
+		fmt.Println(result.ID)
+		return result, nil
+	}
+	if space.Key == "" {
+		fmt.Fprintf(os.Stderr, "Warning: page moved but space %s returned empty key\n", result.SpaceID)
+		fmt.Println(result.ID)
+		return result, nil
+	}
+	fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
+	return result, nil
 }
 
 func readAndValidateContent(pageFile string) ([]byte, error) {
@@ -584,6 +1088,72 @@ func readAndValidateContent(pageFile string) ([]byte, error) {
 	return content, nil
 }
 
+// pageMeta is the page settings for create and update, taken from the
+// Markdown frontmatter with any flags given overriding it.
+type pageMeta struct {
+	title     string
+	space     string
+	parent    string
+	status    string
+	labels    []string
+	emojiID   string
+	fullWidth *bool
+}
+
+// readPageMeta splits the frontmatter from content, merges it with the
+// flags, and returns the settings with the remaining Markdown body.
+func readPageMeta(content []byte) (pageMeta, []byte, error) {
+	fm, body, err := converter.SplitFrontmatter(content)
+	if err != nil {
+		return pageMeta{}, nil, err
+	}
+	meta := pageMeta{
+		title:     fm.Title,
+		space:     fm.Space,
+		parent:    fm.Parent,
+		status:    fm.Status,
+		labels:    fm.Labels,
+		fullWidth: fm.FullWidth,
+	}
+	if fm.Emoji != "" {
+		if meta.emojiID, err = converter.EmojiID(fm.Emoji); err != nil {
+			return pageMeta{}, nil, fmt.Errorf("frontmatter: emoji: %w", err)
+		}
+	}
+	if pageTitle != "" {
+		meta.title = pageTitle
+	}
+	if pageSpace != "" {
+		meta.space = pageSpace
+	}
+	if pageParent != "" {
+		meta.parent = pageParent
+	}
+	if err := pageIDFlag("parent", &meta.parent); err != nil {
+		return pageMeta{}, nil, err
+	}
+	if pageStatus != "" {
+		meta.status = pageStatus
+	}
+	if len(pageLabels) > 0 {
+		meta.labels = pageLabels
+	}
+
+	switch meta.status {
+	case "":
+		meta.status = "current"
+	case "current", "draft":
+	default:
+		return pageMeta{}, nil, fmt.Errorf("invalid status %q: must be current or draft", meta.status)
+	}
+
+	if verbose && len(body) != len(content) {
+		fmt.Fprintf(os.Stderr, "[Content] Read frontmatter: title=%q space=%q parent=%q status=%q labels=%v\n",
+			fm.Title, fm.Space, fm.Parent, fm.Status, fm.Labels)
+	}
+	return meta, bytes.TrimSpace(body), nil
+}
+
 func printJSON(v interface{}) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -593,32 +1163,108 @@ func printJSON(v interface{}) error {
 	return nil
 }
 
+// listOutput is the JSON shape of list commands. NextCursor is passed back
+// via --cursor to resume the listing and is omitted on the last batch.
+type listOutput[T any] struct {
+	Results    []T        `json:"results"`
+	NextCursor api.Cursor `json:"next_cursor,omitempty"`
+}
+
+func newListOutput[T any](results []T, next api.Cursor) listOutput[T] {
+	if results == nil {
+		results = []T{}
+	}
+	return listOutput[T]{Results: results, NextCursor: next}
+}
+
+// printNextCursor prints the resume hint for human-readable list output.
+func printNextCursor(out io.Writer, next api.Cursor) {
+	if next != "" {
+		fmt.Fprintf(out, "Next Cursor: %s\n", next)
+	}
+}
+
 func init() {
-	pageCreateCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Page title (required)")
+	pageCreateCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Page title (required unless set in frontmatter)")
 	pageCreateCmd.Flags().StringVarP(&pageFile, "file", "f", "", "Markdown file, or - for stdin")
+	pageCreateCmd.Flags().StringVar(&pageTemplate, "template", "", "Page template to render instead of --file (see acon template list)")
+	pageCreateCmd.Flags().StringArrayVar(&templateVars, "var", nil, "Template variable as NAME=VALUE (repeatable)")
+	pageCreateCmd.Flags().StringVar(&confluenceTemplate, "confluence-template", "", "ID of a Confluence template to create the page from (see acon template list --confluence)")
 	pageCreateCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space key (uses config default if not specified)")
 	pageCreateCmd.Flags().StringVarP(&pageParent, "parent", "p", "", "Parent page ID")
 	pageCreateCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
-	if err := pageCreateCmd.MarkFlagRequired("title"); err != nil {
-		panic(err)
-	}
-
+	pageCreateCmd.Flags().BoolVar(&renderDiagrams, "render-diagrams", false, "Render diagram fences locally and attach them as images")
+	pageCreateCmd.Flags().StringVar(&diagramFormat, "diagram-format", "svg", "Rendered diagram format: svg, png")
+	pageCreateCmd.Flags().BoolVar(&pageTOC, "toc", false, "Insert a table of contents at the top if there is no [TOC] marker")
+	pageCreateCmd.Flags().IntVar(&tocMin, "toc-min-level", 0, "Smallest heading level in the table of contents (1-6)")
+	pageCreateCmd.Flags().IntVar(&tocMax, "toc-max-level", 0, "Largest heading level in the table of contents (1-6)")
+	pageCreateCmd.Flags().IntVar(&headingOff, "heading-offset", 0, "Shift heading levels by this much, e.g. 1 to publish # as h2 (-5 to 5)")
+	pageCreateCmd.Flags().BoolVar(&allowHTML, "allow-html", false, "Pass through raw HTML <br>, <sup>, and <sub> instead of omitting it")
+	pageCreateCmd.Flags().BoolVar(&bareDates, "dates", false, "Convert ISO dates (2025-03-01) in text to date lozenges, not just {date:...}")
+	pageCreateCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of omitting raw HTML that cannot be published")
+	pageCreateCmd.Flags().BoolVar(&typography, "typography", false, "Use curly quotes, en and em dashes, and ellipses for straight quotes, -- and ---, and ...")
+	pageCreateCmd.Flags().BoolVar(&subscript, "subscript", false, "Convert ~text~ to subscript instead of strikethrough")
+	pageCreateCmd.Flags().BoolVar(&hardWraps, "hard-wraps", false, "Turn every newline within a paragraph into a line break")
+	pageCreateCmd.Flags().StringVar(&pageStatus, "status", "", "Page status: current, draft (default current)")
+	pageCreateCmd.Flags().StringSliceVar(&pageLabels, "label", nil, "Label to add (repeatable, replaces frontmatter labels)")
+	pageCreateCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+	pageViewCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Title of the page to view, instead of PAGE_ID")
+	pageViewCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space to look up --title in (uses config default if not specified)")
 	pageViewCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
-
-	pageUpdateCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "New page title (optional)")
+	pageViewCmd.Flags().StringVar(&outputTemplate, "template", "", templateUsage)
+	pageViewCmd.Flags().BoolVar(&admonitions, "admonitions", false, "Write panels as ::: admonitions instead of GitHub alerts")
+	pageViewCmd.Flags().BoolVar(&downloadAtt, "download-attachments", false, "Save attached images into ./attachments, where the Markdown links to them")
+	pageViewCmd.Flags().BoolVar(&roundTrip, "round-trip", false, "Write macros as shortcodes such as {status:...} that publish back unchanged")
+	pageViewCmd.Flags().BoolVar(&keepHTML, "keep-html", false, "Keep merged table cells and coloured text as inline HTML")
+	pageViewCmd.Flags().BoolVar(&childLinks, "expand-children", false, "Write children macros as lists of links to the child pages")
+
+	pageUpdateCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "New page title (optional), or the title of the page to update if PAGE_ID is not given")
+	pageUpdateCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space to look up --title in (uses config default if not specified)")
 	pageUpdateCmd.Flags().StringVarP(&pageFile, "file", "f", "", "Markdown file, or - for stdin")
 	pageUpdateCmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version update message")
 	pageUpdateCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageUpdateCmd.Flags().BoolVar(&renderDiagrams, "render-diagrams", false, "Render diagram fences locally and attach them as images")
+	pageUpdateCmd.Flags().StringVar(&diagramFormat, "diagram-format", "svg", "Rendered diagram format: svg, png")
+	pageUpdateCmd.Flags().BoolVar(&pageTOC, "toc", false, "Insert a table of contents at the top if there is no [TOC] marker")
+	pageUpdateCmd.Flags().IntVar(&tocMin, "toc-min-level", 0, "Smallest heading level in the table of contents (1-6)")
+	pageUpdateCmd.Flags().IntVar(&tocMax, "toc-max-level", 0, "Largest heading level in the table of contents (1-6)")
+	pageUpdateCmd.Flags().IntVar(&headingOff, "heading-offset", 0, "Shift heading levels by this much, e.g. 1 to publish # as h2 (-5 to 5)")
+	pageUpdateCmd.Flags().BoolVar(&allowHTML, "allow-html", false, "Pass through raw HTML <br>, <sup>, and <sub> instead of omitting it")
+	pageUpdateCmd.Flags().BoolVar(&bareDates, "dates", false, "Convert ISO dates (2025-03-01) in text to date lozenges, not just {date:...}")
+	pageUpdateCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of omitting raw HTML that cannot be published")
+	pageUpdateCmd.Flags().BoolVar(&typography, "typography", false, "Use curly quotes, en and em dashes, and ellipses for straight quotes, -- and ---, and ...")
+	pageUpdateCmd.Flags().BoolVar(&subscript, "subscript", false, "Convert ~text~ to subscript instead of strikethrough")
+	pageUpdateCmd.Flags().BoolVar(&hardWraps, "hard-wraps", false, "Turn every newline within a paragraph into a line break")
+	pageUpdateCmd.Flags().StringVar(&pageStatus, "status", "", "Page status: current, draft (default current)")
+	pageUpdateCmd.Flags().StringSliceVar(&pageLabels, "label", nil, "Label to add (repeatable, replaces frontmatter labels)")
+	pageUpdateCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+	pageUpdateCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+	pageDeleteCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Title of the page to delete, instead of PAGE_ID")
+	pageDeleteCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space to look up --title in (uses config default if not specified)")
+	pageDeleteCmd.Flags().BoolVarP(&deleteRecursive, "recursive", "r", false, "Delete every page below it too, deepest first")
+	pageDeleteCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+	pageDeleteCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	pageDeleteCmd.Flags().BoolVar(&force, "force", false, forceUsage)
+	pageDeleteCmd.Flags().BoolVarP(&force, "yes", "y", false, forceUsage)
 
 	pageListCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space key (uses config default if not specified)")
 	pageListCmd.Flags().StringVarP(&pageParent, "parent", "p", "", "Parent page ID (list children of this page)")
 	pageListCmd.Flags().IntVarP(&pageLimit, "limit", "l", 25, "Maximum number of pages to list")
+	pageListCmd.Flags().StringVar(&pageCursor, "cursor", "", "Resume from the cursor printed by a previous list")
 	pageListCmd.Flags().StringVar(&pageSort, "sort", "", "Sort order: web, title, created, modified, id")
 	pageListCmd.Flags().BoolVar(&pageDesc, "desc", false, "Sort in descending order")
 	pageListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageListCmd.Flags().StringVar(&outputTemplate, "template", "", templateUsage)
+	pageListCmd.Flags().BoolVar(&outputJSONL, "jsonl", false, jsonlUsage)
+	pageListCmd.Flags().BoolVar(&outputIDs, "ids", false, idsUsage)
+	pageListCmd.Flags().BoolVar(&outputURLs, "urls", false, urlsUsage)
 
 	pageMoveCmd.Flags().StringVarP(&moveParent, "parent", "p", "", "Target parent page ID (required)")
 	pageMoveCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageMoveCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+	pageMoveCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
 	if err := pageMoveCmd.MarkFlagRequired("parent"); err != nil {
 		panic(err)
 	}
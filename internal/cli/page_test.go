@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/grantcarthew/acon/internal/api"
 	"github.com/grantcarthew/acon/internal/config"
+	"github.com/grantcarthew/acon/internal/converter"
 	"github.com/spf13/cobra"
 )
 
@@ -593,11 +595,47 @@ func resetPageFlags(t *testing.T) {
 		pageSpace = ""
 		pageParent = ""
 		pageLimit = 25
+		pageCursor = ""
 		pageSort = ""
 		pageDesc = false
 		outputJSON = false
 		updateMsg = ""
 		moveParent = ""
+		overrideProtection = false
+		renderDiagrams = false
+		diagramFormat = "svg"
+		pageTOC = false
+		allowHTML = false
+		bareDates = false
+		strict = false
+		typography = false
+		subscript = false
+		hardWraps = false
+		tocMin = 0
+		tocMax = 0
+		headingOff = 0
+		admonitions = false
+		roundTrip = false
+		keepHTML = false
+		downloadAtt = false
+		childLinks = false
+		pageTemplate = ""
+		templateVars = nil
+		confluenceTemplate = ""
+		templateConfluence = false
+		renameEmoji = ""
+		metaRemoveLabels = nil
+		sectionHeading = ""
+		pageStatus = ""
+		pageLabels = nil
+		dryRun = false
+		force = false
+		deleteRecursive = false
+		outputFormat = ""
+		outputTemplate = ""
+		outputJSONL = false
+		outputIDs = false
+		outputURLs = false
 	}
 	reset()
 	t.Cleanup(reset)
@@ -753,6 +791,33 @@ func TestPageUpdateCmd_SpaceLookupFails(t *testing.T) {
 	}
 }
 
+func TestPageUpdateCmd_InvalidStorage(t *testing.T) {
+	resetPageFlags(t)
+	pageFile = "-"
+
+	server := httptest.NewServer(updateMoveHandler(t, http.StatusOK, "MYSPACE"))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	cfg := &config.Config{BaseURL: server.URL, Converter: config.ConverterProfile{MermaidMacro: "my diagrams"}}
+	withMockClient(t, client, cfg)
+	withMockStdin(t, "```mermaid\ngraph TD\n```")
+
+	finish := captureStdStreams(t)
+	runErr := pageUpdateCmd.RunE(testCommand(), []string{"123"})
+	_, _ = finish()
+
+	if !errors.Is(runErr, converter.ErrInvalidStorage) {
+		t.Fatalf("RunE error = %v, want ErrInvalidStorage", runErr)
+	}
+	if !strings.Contains(runErr.Error(), `invalid name "my diagrams"`) {
+		t.Errorf("RunE error = %v, want the invalid macro name", runErr)
+	}
+}
+
 func TestPageMoveCmd_HappyPath(t *testing.T) {
 	resetPageFlags(t)
 	moveParent = "456"
@@ -1512,3 +1577,502 @@ func TestPrintPageList_MultipleSpaces(t *testing.T) {
 		t.Errorf("output missing URL for page 3 in space alpha:\n%s", out)
 	}
 }
+
+func TestReadPageMeta(t *testing.T) {
+	content := []byte("---\ntitle: From File\nspace: DOCS\nparent: \"42\"\nstatus: draft\nlabels: [a, b]\n---\n\n# Body\n")
+
+	t.Run("frontmatter only", func(t *testing.T) {
+		resetPageFlags(t)
+		meta, body, err := readPageMeta(content)
+		if err != nil {
+			t.Fatalf("readPageMeta: %v", err)
+		}
+		want := pageMeta{title: "From File", space: "DOCS", parent: "42", status: "draft", labels: []string{"a", "b"}}
+		if meta.title != want.title || meta.space != want.space || meta.parent != want.parent ||
+			meta.status != want.status || strings.Join(meta.labels, ",") != "a,b" {
+			t.Errorf("meta = %+v, want %+v", meta, want)
+		}
+		if string(body) != "# Body" {
+			t.Errorf("body = %q, want %q", body, "# Body")
+		}
+	})
+
+	t.Run("flags override", func(t *testing.T) {
+		resetPageFlags(t)
+		pageTitle = "From Flag"
+		pageSpace = "OTHER"
+		pageParent = "7"
+		pageStatus = "current"
+		pageLabels = []string{"c"}
+		meta, _, err := readPageMeta(content)
+		if err != nil {
+			t.Fatalf("readPageMeta: %v", err)
+		}
+		if meta.title != "From Flag" || meta.space != "OTHER" || meta.parent != "7" ||
+			meta.status != "current" || strings.Join(meta.labels, ",") != "c" {
+			t.Errorf("meta = %+v, want flag values", meta)
+		}
+	})
+
+	t.Run("parent URL", func(t *testing.T) {
+		resetPageFlags(t)
+		meta, _, err := readPageMeta([]byte("---\nparent: https://example.atlassian.net/wiki/x/A4AB\n---\n# Body"))
+		if err != nil {
+			t.Fatalf("readPageMeta: %v", err)
+		}
+		if meta.parent != "98307" {
+			t.Errorf("parent = %q, want 98307", meta.parent)
+		}
+	})
+
+	t.Run("no frontmatter", func(t *testing.T) {
+		resetPageFlags(t)
+		meta, body, err := readPageMeta([]byte("# Body"))
+		if err != nil {
+			t.Fatalf("readPageMeta: %v", err)
+		}
+		if meta.status != "current" || meta.title != "" {
+			t.Errorf("meta = %+v, want default status only", meta)
+		}
+		if string(body) != "# Body" {
+			t.Errorf("body = %q, want unchanged", body)
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		resetPageFlags(t)
+		pageStatus = "archived"
+		if _, _, err := readPageMeta([]byte("# Body")); err == nil || !strings.Contains(err.Error(), "invalid status") {
+			t.Errorf("err = %v, want invalid status", err)
+		}
+	})
+
+	t.Run("emoji and width", func(t *testing.T) {
+		resetPageFlags(t)
+		meta, _, err := readPageMeta([]byte("---\nemoji: \":rocket:\"\nfull-width: true\n---\n# Body"))
+		if err != nil {
+			t.Fatalf("readPageMeta: %v", err)
+		}
+		if meta.emojiID != "1f680" || meta.fullWidth == nil || !*meta.fullWidth {
+			t.Errorf("meta = %+v, want emoji 1f680 and full width", meta)
+		}
+	})
+
+	t.Run("unknown emoji", func(t *testing.T) {
+		resetPageFlags(t)
+		if _, _, err := readPageMeta([]byte("---\nemoji: :nope:\n---\n# Body")); err == nil || !strings.Contains(err.Error(), "emoji: unknown emoji shortcode") {
+			t.Errorf("err = %v, want unknown emoji", err)
+		}
+	})
+
+	t.Run("unterminated frontmatter", func(t *testing.T) {
+		resetPageFlags(t)
+		if _, _, err := readPageMeta([]byte("---\ntitle: x\n# Body")); err == nil {
+			t.Error("expected error for unterminated frontmatter")
+		}
+	})
+}
+
+func TestPageCreateCmd_Frontmatter(t *testing.T) {
+	resetPageFlags(t)
+	pageFile = "-"
+
+	var created api.PageCreateRequest
+	var labelPath string
+	var labels []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces":
+			if got := r.URL.Query().Get("keys"); got != "DOCS" {
+				t.Errorf("space key = %q, want DOCS", got)
+			}
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{Results: []api.Space{{ID: "space-9", Key: "DOCS"}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("decoding create request: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "555", SpaceID: "space-9", Title: created.Title})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/label"):
+			labelPath = r.URL.Path
+			if err := json.NewDecoder(r.Body).Decode(&labels); err != nil {
+				t.Errorf("decoding label request: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL, SpaceKey: "DEFAULT"})
+	withMockStdin(t, "---\ntitle: Release Notes\nspace: DOCS\nparent: 42\nstatus: draft\nlabels:\n  - release\n  - notes\n---\n# Hello\n")
+
+	finish := captureStdStreams(t)
+	runErr := pageCreateCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	if created.Title != "Release Notes" || created.SpaceID != "space-9" || created.ParentID != "42" || created.Status != "draft" {
+		t.Errorf("create request = %+v, want frontmatter values", created)
+	}
+	if created.Body == nil || strings.Contains(created.Body.Value, "title:") || !strings.Contains(created.Body.Value, "Hello") {
+		t.Errorf("body = %+v, want converted markdown without frontmatter", created.Body)
+	}
+	if labelPath != "/wiki/rest/api/content/555/label" {
+		t.Errorf("label path = %q", labelPath)
+	}
+	if len(labels) != 2 || labels[0]["name"] != "release" || labels[1]["name"] != "notes" {
+		t.Errorf("labels = %v, want release, notes", labels)
+	}
+	if !strings.Contains(stdout, server.URL+"/wiki/spaces/DOCS/pages/555") {
+		t.Errorf("stdout = %q, want page URL", stdout)
+	}
+}
+
+func TestPageCreateCmd_Appearance(t *testing.T) {
+	resetPageFlags(t)
+	pageFile = "-"
+
+	props := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces":
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{Results: []api.Space{{ID: "space-9", Key: "DOCS"}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages":
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "555", SpaceID: "space-9"})
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/pages/555/properties":
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages/555/properties":
+			var body struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding property request: %v", err)
+			}
+			props[body.Key] = body.Value
+			_, _ = w.Write([]byte(`{"id":"1"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL, SpaceKey: "DOCS"})
+	withMockStdin(t, "---\ntitle: Runbook\nemoji: \"📘\"\nfull-width: true\n---\n# Hello\n")
+
+	finish := captureStdStreams(t)
+	runErr := pageCreateCmd.RunE(testCommand(), nil)
+	_, _ = finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	want := map[string]string{
+		"emoji-title-published":        "1f4d8",
+		"emoji-title-draft":            "1f4d8",
+		"content-appearance-published": "full-width",
+		"content-appearance-draft":     "full-width",
+	}
+	if !reflect.DeepEqual(props, want) {
+		t.Errorf("properties = %v, want %v", props, want)
+	}
+}
+
+func TestPageCreateCmd_TitleRequired(t *testing.T) {
+	resetPageFlags(t)
+	pageFile = "-"
+
+	client, err := api.NewClient("http://127.0.0.1:1", "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{SpaceKey: "DOCS"})
+	withMockStdin(t, "# No title anywhere")
+
+	err = pageCreateCmd.RunE(testCommand(), nil)
+	if err == nil || !strings.Contains(err.Error(), "title required") {
+		t.Errorf("err = %v, want title required", err)
+	}
+}
+
+func TestPageUpdateCmd_Frontmatter(t *testing.T) {
+	resetPageFlags(t)
+	pageFile = "-"
+
+	var updated api.PageUpdateRequest
+	var labelCalls int32
+	moves := updateMoveHandler(t, http.StatusOK, "MYSPACE")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/wiki/api/v2/pages/"):
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &updated); err != nil {
+				t.Errorf("decoding update request: %v", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content/123/label":
+			atomic.AddInt32(&labelCalls, 1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"results":[]}`))
+			return
+		}
+		moves.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+	withMockStdin(t, "---\ntitle: Renamed\nspace: IGNORED\nparent: 77\nlabels: docs\n---\n# updated body\n")
+
+	finish := captureStdStreams(t)
+	runErr := pageUpdateCmd.RunE(testCommand(), []string{"123"})
+	finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	if updated.Title != "Renamed" || updated.ParentID != "77" || updated.Status != "current" || updated.SpaceID != "space-1" {
+		t.Errorf("update request = %+v, want frontmatter title and parent", updated)
+	}
+	if atomic.LoadInt32(&labelCalls) != 1 {
+		t.Errorf("label calls = %d, want 1", labelCalls)
+	}
+}
+
+func TestPageViewCmd_PageLinks(t *testing.T) {
+	resetPageFlags(t)
+
+	storage := `<p><ac:link><ri:page ri:content-title="Install Guide" /></ac:link> and ` +
+		`<ac:link><ri:page ri:space-key="OTHER" ri:content-title="Gone" /></ac:link></p>`
+	var titleLookups int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/wiki/api/v2/pages/123":
+			_ = json.NewEncoder(w).Encode(api.Page{
+				ID:      "123",
+				SpaceID: "space-1",
+				Title:   "Home",
+				Body:    &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: storage}},
+			})
+		case r.URL.Path == "/wiki/api/v2/spaces/space-1":
+			_ = json.NewEncoder(w).Encode(api.Space{ID: "space-1", Key: "DOCS"})
+		case r.URL.Path == "/wiki/api/v2/spaces" && r.URL.Query().Get("keys") == "OTHER":
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{Results: []api.Space{{ID: "space-2", Key: "OTHER"}}})
+		case r.URL.Path == "/wiki/api/v2/pages":
+			titleLookups++
+			if r.URL.Query().Get("title") == "Install Guide" && r.URL.Query().Get("space-id") == "space-1" {
+				_ = json.NewEncoder(w).Encode(api.PageListResponse{Results: []api.Page{{ID: "456", Title: "Install Guide"}}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(api.PageListResponse{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := pageViewCmd.RunE(testCommand(), []string{"123"})
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	want := "[Install Guide](" + server.URL + "/wiki/spaces/DOCS/pages/456) and [[OTHER:Gone]]"
+	if strings.TrimSpace(stdout) != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if titleLookups != 2 {
+		t.Errorf("title lookups = %d, want 2", titleLookups)
+	}
+}
+
+func TestPageViewCmd_ExpandChildren(t *testing.T) {
+	resetPageFlags(t)
+	childLinks = true
+
+	storage := `<ac:structured-macro ac:name="children"><ac:parameter ac:name="depth">2</ac:parameter></ac:structured-macro>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/api/v2/pages/123":
+			_ = json.NewEncoder(w).Encode(api.Page{
+				ID:      "123",
+				SpaceID: "space-1",
+				Body:    &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: storage}},
+			})
+		case "/wiki/api/v2/spaces/space-1":
+			_ = json.NewEncoder(w).Encode(api.Space{ID: "space-1", Key: "DOCS"})
+		case "/wiki/api/v2/pages/123/children":
+			if r.URL.Query().Get("sort") != "child-position" {
+				t.Errorf("sort = %q, want child-position", r.URL.Query().Get("sort"))
+			}
+			_ = json.NewEncoder(w).Encode(api.PageListResponse{Results: []api.Page{{ID: "456", Title: "Install"}}})
+		case "/wiki/api/v2/pages/456/children":
+			_ = json.NewEncoder(w).Encode(api.PageListResponse{Results: []api.Page{{ID: "789", Title: "Linux"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := pageViewCmd.RunE(testCommand(), []string{"123"})
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	want := "- [Install](" + server.URL + "/wiki/spaces/DOCS/pages/456)\n" +
+		"  - [Linux](" + server.URL + "/wiki/spaces/DOCS/pages/789)"
+	if strings.TrimSpace(stdout) != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestPageViewCmd_DownloadAttachments(t *testing.T) {
+	resetPageFlags(t)
+	downloadAtt = true
+	t.Chdir(t.TempDir())
+
+	storage := `<p><ac:image><ri:attachment ri:filename="chart.png" /></ac:image></p>` +
+		`<p><ac:image><ri:attachment ri:filename="chart.png" /></ac:image></p>`
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wiki/api/v2/pages/123":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(api.Page{
+				ID:      "123",
+				SpaceID: "space-1",
+				Body:    &api.PageBodyGet{Storage: &api.BodyContent{Representation: "storage", Value: storage}},
+			})
+		case "/wiki/api/v2/pages/123/attachments":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"results":[{"id":"att1","title":"chart.png","downloadLink":"/download/attachments/123/chart.png"}]}`))
+		case "/wiki/download/attachments/123/chart.png":
+			downloads++
+			_, _ = w.Write([]byte("PNGDATA"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := pageViewCmd.RunE(testCommand(), []string{"123"})
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	if !strings.Contains(stdout, "![chart](attachments/chart.png)") {
+		t.Errorf("stdout = %q, want attachment image link", stdout)
+	}
+	data, err := os.ReadFile(filepath.Join("attachments", "chart.png"))
+	if err != nil || string(data) != "PNGDATA" {
+		t.Errorf("attachments/chart.png = %q, %v", data, err)
+	}
+	if downloads != 1 {
+		t.Errorf("downloads = %d, want 1", downloads)
+	}
+}
+
+func TestPageViewCmd_ADF(t *testing.T) {
+	resetPageFlags(t)
+	downloadAtt = true
+	t.Chdir(t.TempDir())
+
+	adf := `{"type":"doc","version":1,"content":[` +
+		`{"type":"paragraph","content":[{"type":"text","text":"Ship it","marks":[{"type":"strong"}]}]},` +
+		`{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"f1","collection":"contentId-123","__fileName":"chart.png"}}]},` +
+		`{"type":"rule"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wiki/api/v2/pages/123":
+			if got := r.URL.Query().Get("body-format"); got != "atlas_doc_format" {
+				t.Errorf("body-format = %q, want atlas_doc_format", got)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(api.Page{
+				ID:      "123",
+				SpaceID: "space-1",
+				Body:    &api.PageBodyGet{AtlasDocFormat: &api.BodyContent{Representation: "atlas_doc_format", Value: adf}},
+			})
+		case "/wiki/api/v2/pages/123/attachments":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"results":[{"id":"att1","title":"chart.png","downloadLink":"/download/attachments/123/chart.png"}]}`))
+		case "/wiki/download/attachments/123/chart.png":
+			_, _ = w.Write([]byte("PNGDATA"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := pageViewCmd.RunE(testCommand(), []string{"123"})
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	want := "**Ship it**\n\n![chart](attachments/chart.png)\n\n---"
+	if strings.TrimSpace(stdout) != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	data, err := os.ReadFile(filepath.Join("attachments", "chart.png"))
+	if err != nil || string(data) != "PNGDATA" {
+		t.Errorf("attachments/chart.png = %q, %v", data, err)
+	}
+}
+
+func TestDownloadAttachments_UnsafeFilename(t *testing.T) {
+	err := downloadAttachments(context.Background(), nil, "123", t.TempDir(), []string{"../evil.png"})
+	if err == nil || !strings.Contains(err.Error(), "unsafe filename") {
+		t.Errorf("error = %v, want unsafe filename error", err)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/converter"
+)
+
+// pageLinks resolves links to pages by title into page URLs while viewing
+// a page. Lookups are cached, and a link that cannot be resolved is left to
+// the converter to write as a wiki link.
+type pageLinks struct {
+	ctx     context.Context
+	client  *api.Client
+	baseURL string
+	spaceID string // space of the page being viewed
+	spaces  map[string]*api.Space
+	urls    map[converter.PageRef]string
+}
+
+// preparePageLinks hooks page link resolution into opts for a page in the
+// space with ID spaceID.
+func preparePageLinks(ctx context.Context, client *api.Client, baseURL, spaceID string, opts *converter.StorageOptions) {
+	p := &pageLinks{
+		ctx:     ctx,
+		client:  client,
+		baseURL: baseURL,
+		spaceID: spaceID,
+		spaces:  map[string]*api.Space{},
+		urls:    map[converter.PageRef]string{},
+	}
+	opts.ResolvePage = p.resolve
+}
+
+// resolve implements converter.PageResolver.
+func (p *pageLinks) resolve(ref converter.PageRef) (string, error) {
+	if u, ok := p.urls[ref]; ok {
+		return u, nil
+	}
+	u, err := p.lookup(ref)
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Page Link] %s: %v\n", ref.Title, err)
+		}
+		return "", err
+	}
+	p.urls[ref] = u
+	return u, nil
+}
+
+// lookup finds the page ref names and returns its URL
+func (p *pageLinks) lookup(ref converter.PageRef) (string, error) {
+	space, err := p.space(ref.SpaceKey)
+	if err != nil {
+		return "", err
+	}
+	page, err := p.client.GetPageByTitle(p.ctx, space.ID, ref.Title)
+	if err != nil {
+		return "", err
+	}
+	if page == nil {
+		return "", fmt.Errorf("page not found in space %s", space.Key)
+	}
+	return pageURL(p.baseURL, space.Key, page.ID), nil
+}
+
+// space returns the space with the given key, or the viewed page's space
+// for an empty key
+func (p *pageLinks) space(key string) (*api.Space, error) {
+	if space, ok := p.spaces[key]; ok {
+		return space, nil
+	}
+	var space *api.Space
+	var err error
+	if key == "" {
+		space, err = p.client.GetSpaceByID(p.ctx, p.spaceID)
+	} else {
+		space, err = p.client.GetSpace(p.ctx, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.spaces[key] = space
+	return space, nil
+}
+
+// maxChildPages limits the child pages listed for each page by a children
+// macro
+const maxChildPages = 250
+
+// childPages lists the children of pages for children macros while viewing
+// page.
+type childPages struct {
+	ctx      context.Context
+	client   *api.Client
+	baseURL  string
+	page     *api.Page
+	spaceKey string // looked up on first use
+}
+
+// prepareChildPages hooks child page listing into opts for page.
+func prepareChildPages(ctx context.Context, client *api.Client, baseURL string, page *api.Page, opts *converter.StorageOptions) {
+	c := &childPages{ctx: ctx, client: client, baseURL: baseURL, page: page}
+	opts.ResolveChildren = c.resolve
+}
+
+// resolve implements converter.ChildrenResolver.
+func (c *childPages) resolve(pageID string) ([]converter.ChildPage, error) {
+	if pageID == "" {
+		pageID = c.page.ID
+	}
+	if c.spaceKey == "" {
+		space, err := c.client.GetSpaceByID(c.ctx, c.page.SpaceID)
+		if err != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "[Children] %s: %v\n", c.page.SpaceID, err)
+			}
+			return nil, err
+		}
+		c.spaceKey = space.Key
+	}
+	pages, _, err := c.client.GetChildPages(c.ctx, pageID, maxChildPages, "child-position")
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Children] %s: %v\n", pageID, err)
+		}
+		return nil, err
+	}
+	children := make([]converter.ChildPage, len(pages))
+	for i, p := range pages {
+		children[i] = converter.ChildPage{ID: p.ID, Title: p.Title, URL: pageURL(c.baseURL, c.spaceKey, p.ID)}
+	}
+	return children, nil
+}
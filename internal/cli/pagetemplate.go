@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// pageTemplateExt is the extension of page template files
+const pageTemplateExt = ".md"
+
+// maxContentTemplates caps the Confluence templates listed
+const maxContentTemplates = 200
+
+var (
+	pageTemplate       string
+	templateVars       []string
+	confluenceTemplate string
+	templateConfluence bool
+)
+
+var pageTemplateFuncs = template.FuncMap{
+	"now": time.Now,
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage page templates",
+	Long: `Page templates are markdown files, with optional frontmatter, for recurring
+pages such as meeting notes. page create --template NAME renders NAME.md from
+the template directory as a Go template, filling in each --var NAME=VALUE as
+{{.NAME}}, then publishes it as it would a markdown file.
+
+The template directory is $ACON_TEMPLATES, else the templates directory beside
+the config file (~/.config/acon/templates).
+
+Templates kept in Confluence are listed with template list --confluence, and
+page create --confluence-template ID creates a page from one.`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List page templates",
+	Long: `List the page templates in the template directory or, with --confluence, the
+templates kept in Confluence: those of the --space space, or the global
+templates without --space.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if templateConfluence {
+			return listContentTemplates(cmd.Context())
+		}
+		if pageSpace != "" {
+			return fmt.Errorf("--space requires --confluence")
+		}
+		dir, err := config.TemplateDir()
+		if err != nil {
+			return err
+		}
+		names, err := listPageTemplates(dir)
+		if err != nil {
+			return err
+		}
+
+		if outputJSON {
+			type entry struct {
+				Name string `json:"name"`
+				Path string `json:"path"`
+			}
+			out := []entry{}
+			for _, name := range names {
+				out = append(out, entry{Name: name, Path: filepath.Join(dir, name+pageTemplateExt)})
+			}
+			return printJSON(out)
+		}
+		if len(names) == 0 {
+			fmt.Printf("No templates in %s\n", dir)
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tPATH")
+		for _, name := range names {
+			fmt.Fprintf(w, "%s\t%s\n", name, filepath.Join(dir, name+pageTemplateExt))
+		}
+		return w.Flush()
+	},
+}
+
+// listContentTemplates prints the Confluence templates of --space, or the
+// global templates
+func listContentTemplates(ctx context.Context) error {
+	client, _, err := initClient()
+	if err != nil {
+		return err
+	}
+	templates, err := client.ListContentTemplates(ctx, pageSpace, maxContentTemplates)
+	if err != nil {
+		return fmt.Errorf("listing templates: %w", err)
+	}
+
+	if outputJSON {
+		if templates == nil {
+			templates = []api.ContentTemplate{}
+		}
+		return printJSON(templates)
+	}
+	if len(templates) == 0 {
+		if pageSpace != "" {
+			fmt.Printf("No templates in space %s\n", pageSpace)
+		} else {
+			fmt.Println("No global templates")
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tDESCRIPTION")
+	for _, t := range templates {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.TemplateID, t.Name, t.Description)
+	}
+	return w.Flush()
+}
+
+// contentTemplateStorage returns the storage format body of the Confluence
+// template with ID templateID
+func contentTemplateStorage(ctx context.Context, client *api.Client, templateID string) (string, error) {
+	tmpl, err := client.GetContentTemplate(ctx, templateID)
+	if err != nil {
+		return "", fmt.Errorf("getting template: %w", err)
+	}
+	if tmpl.Body == nil || tmpl.Body.Storage == nil {
+		return "", fmt.Errorf("template %s has no storage format body", templateID)
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Page Create] Using template %q (%d bytes of storage format)\n", tmpl.Name, len(tmpl.Body.Storage.Value))
+	}
+	return tmpl.Body.Storage.Value, nil
+}
+
+// completePageTemplates completes --template with the names of page templates
+func completePageTemplates(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	dir, err := config.TemplateDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, err := listPageTemplates(dir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// listPageTemplates returns the names of the templates in dir, sorted. A
+// missing directory has no templates.
+func listPageTemplates(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading template directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != pageTemplateExt {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), pageTemplateExt))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// renderPageTemplate renders the template name, a file in the template
+// directory or a path to a markdown file, with vars given as NAME=VALUE.
+// It returns the markdown and the template's path, which relative image
+// paths are resolved against.
+func renderPageTemplate(name string, vars []string) ([]byte, string, error) {
+	data := map[string]string{}
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, "", fmt.Errorf("invalid --var %q: use NAME=VALUE", v)
+		}
+		data[key] = value
+	}
+
+	path := name
+	if !strings.ContainsRune(name, filepath.Separator) && filepath.Ext(name) != pageTemplateExt {
+		dir, err := config.TemplateDir()
+		if err != nil {
+			return nil, "", err
+		}
+		path = filepath.Join(dir, name+pageTemplateExt)
+	}
+	text, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("template %s not found at %s (see acon template list)", name, path)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("reading template: %w", err)
+	}
+
+	// A variable the template uses but --var does not give is an error,
+	// rather than a page published with "<no value>" in it
+	tmpl, err := template.New(name).Funcs(pageTemplateFuncs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing template %s: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, "", fmt.Errorf("rendering template %s: %w", name, err)
+	}
+	if out.Len() > maxContentSize {
+		return nil, "", fmt.Errorf("rendered template too large: %d bytes (max %d)", out.Len(), maxContentSize)
+	}
+	return out.Bytes(), path, nil
+}
+
+func init() {
+	templateListCmd.Flags().BoolVar(&templateConfluence, "confluence", false, "List the templates kept in Confluence instead")
+	templateListCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space whose Confluence templates to list (default: global templates)")
+	templateListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	templateCmd.AddCommand(templateListCmd)
+	_ = pageCreateCmd.RegisterFlagCompletionFunc("template", completePageTemplates)
+
+	templateCmd.GroupID = "core"
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// withTemplateDir creates the page templates in files, by name, in a
+// temporary template directory and returns it.
+func withTemplateDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("writing template: %v", err)
+		}
+	}
+	t.Setenv("ACON_TEMPLATES", dir)
+	return dir
+}
+
+func TestRenderPageTemplate(t *testing.T) {
+	dir := withTemplateDir(t, map[string]string{
+		"meeting-notes.md": "---\ntitle: Meeting {{.date}}\n---\n# {{.team}} sync\n",
+	})
+
+	got, path, err := renderPageTemplate("meeting-notes", []string{"date=2025-03-01", "team=Ops=Infra"})
+	if err != nil {
+		t.Fatalf("renderPageTemplate: %v", err)
+	}
+	if string(got) != "---\ntitle: Meeting 2025-03-01\n---\n# Ops=Infra sync\n" {
+		t.Errorf("rendered = %q", got)
+	}
+	if path != filepath.Join(dir, "meeting-notes.md") {
+		t.Errorf("path = %q", path)
+	}
+
+	// A path to a template outside the directory
+	if _, _, err := renderPageTemplate(filepath.Join(dir, "meeting-notes.md"), []string{"date=x", "team=y"}); err != nil {
+		t.Errorf("renderPageTemplate by path: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		vars    []string
+		wantErr string
+	}{
+		{"meeting-notes", []string{"date=2025-03-01"}, `map has no entry for key "team"`},
+		{"meeting-notes", []string{"date"}, `invalid --var "date"`},
+		{"missing", nil, "template missing not found"},
+	}
+	for _, tt := range tests {
+		if _, _, err := renderPageTemplate(tt.name, tt.vars); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("renderPageTemplate(%s, %v) error = %v, want containing %q", tt.name, tt.vars, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTemplateListCmd(t *testing.T) {
+	resetPageFlags(t)
+	dir := withTemplateDir(t, map[string]string{
+		"retro.md":         "# Retro\n",
+		"meeting-notes.md": "# Notes\n",
+		"README.txt":       "not a template",
+	})
+
+	finish := captureStdStreams(t)
+	err := templateListCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := "NAME           PATH\nmeeting-notes  " + filepath.Join(dir, "meeting-notes.md") + "\nretro          " + filepath.Join(dir, "retro.md") + "\n"
+	if stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestPageCreateCmd_Template(t *testing.T) {
+	resetPageFlags(t)
+	withTemplateDir(t, map[string]string{
+		"meeting-notes.md": "---\ntitle: Meeting {{.date}}\n---\n# Attendees\n\n{{.who}}\n",
+	})
+
+	var created api.PageCreateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces":
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{Results: []api.Space{{ID: "space-9", Key: "DOCS"}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("decoding create request: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "555", SpaceID: "space-9", Title: created.Title})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL, SpaceKey: "DOCS"})
+
+	pageTemplate, pageFile = "meeting-notes", "notes.md"
+	if err := pageCreateCmd.RunE(testCommand(), nil); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("--template with --file error = %v", err)
+	}
+
+	pageFile = ""
+	templateVars = []string{"date=2025-03-01", "who=Alice and Bob"}
+	finish := captureStdStreams(t)
+	runErr := pageCreateCmd.RunE(testCommand(), nil)
+	finish()
+	if runErr != nil {
+		t.Fatalf("create: %v", runErr)
+	}
+	if created.Title != "Meeting 2025-03-01" {
+		t.Errorf("title = %q, want Meeting 2025-03-01", created.Title)
+	}
+	if created.Body == nil || !strings.Contains(created.Body.Value, "Alice and Bob") {
+		t.Errorf("body = %+v, want the rendered template", created.Body)
+	}
+}
+
+func TestPageCreateCmd_ConfluenceTemplate(t *testing.T) {
+	resetPageFlags(t)
+
+	var created api.PageCreateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/rest/api/template/98305":
+			_, _ = w.Write([]byte(`{"templateId":"98305","name":"Meeting notes","body":{"storage":{"value":"<h2>Attendees</h2><ac:task-list></ac:task-list>","representation":"storage"}}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces":
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{Results: []api.Space{{ID: "space-9", Key: "DOCS"}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("decoding create request: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "555", SpaceID: "space-9", Title: created.Title})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL, SpaceKey: "DOCS"})
+
+	confluenceTemplate, pageFile = "98305", "notes.md"
+	if err := pageCreateCmd.RunE(testCommand(), nil); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("--confluence-template with --file error = %v", err)
+	}
+
+	pageFile, pageTitle = "", "Ops sync"
+	finish := captureStdStreams(t)
+	runErr := pageCreateCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("create: %v", runErr)
+	}
+	if created.Title != "Ops sync" || created.SpaceID != "space-9" {
+		t.Errorf("create request = %+v", created)
+	}
+	if created.Body == nil || created.Body.Value != "<h2>Attendees</h2><ac:task-list></ac:task-list>" {
+		t.Errorf("body = %+v, want the template body as it is", created.Body)
+	}
+	if stdout != server.URL+"/wiki/spaces/DOCS/pages/555\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
+
+func TestTemplateListCmd_Confluence(t *testing.T) {
+	resetPageFlags(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/template/page" || r.URL.Query().Get("spaceKey") != "DOCS" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"templateId":"98305","name":"Meeting notes","description":"Weekly sync"}]}`))
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	pageSpace = "DOCS"
+	if err := templateListCmd.RunE(testCommand(), nil); err == nil || !strings.Contains(err.Error(), "--space requires --confluence") {
+		t.Errorf("--space without --confluence error = %v", err)
+	}
+
+	templateConfluence = true
+	finish := captureStdStreams(t)
+	runErr := templateListCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("list: %v", runErr)
+	}
+	if stdout != "ID     NAME           DESCRIPTION\n98305  Meeting notes  Weekly sync\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
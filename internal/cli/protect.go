@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// overrideProtection bypasses the config file's protect rules.
+var overrideProtection bool
+
+const overrideProtectionUsage = "Allow changes to targets protected in the config file"
+
+// checkPageProtection returns an error when pageID is protected by a config
+// rule and --override-protection was not given. spaceID may be empty, in which
+// case the page is fetched only if a space rule could apply.
+func checkPageProtection(ctx context.Context, client *api.Client, cfg *config.Config, pageID, spaceID, action string) error {
+	if len(cfg.Protections) == 0 {
+		return nil
+	}
+
+	var spaceKey, homepageID string
+	if cfg.HasSpaceProtections() {
+		if spaceID == "" {
+			page, err := client.GetPage(ctx, pageID)
+			if err != nil {
+				return fmt.Errorf("checking page protection: %w", err)
+			}
+			spaceID = page.SpaceID
+		}
+		space, err := client.GetSpaceByID(ctx, spaceID)
+		if err != nil {
+			return fmt.Errorf("checking page protection: %w", err)
+		}
+		spaceKey, homepageID = space.Key, space.HomepageID
+	}
+
+	p, protected := cfg.PageProtection(pageID, spaceKey, homepageID)
+	if !protected {
+		return nil
+	}
+	if overrideProtection {
+		fmt.Fprintf(os.Stderr, "Warning: overriding protection rule %q for page %s\n", p.Rule, pageID)
+		return nil
+	}
+	return &protectedError{action: action, pageID: pageID, rule: p.Rule}
+}
+
+// protectedError is the error checkPageProtection returns for a protected
+// page, so callers working through many pages can skip it instead
+type protectedError struct {
+	action, pageID, rule string
+}
+
+func (e *protectedError) Error() string {
+	return fmt.Sprintf("refusing to %s page %s: protected by rule %q (use --override-protection to proceed)", e.action, e.pageID, e.rule)
+}
+
+// checkSpaceProtection is checkPageProtection for operations on a whole space.
+func checkSpaceProtection(cfg *config.Config, spaceKey, action string) error {
+	p, protected := cfg.SpaceProtection(spaceKey)
+	if !protected {
+		return nil
+	}
+	if overrideProtection {
+		fmt.Fprintf(os.Stderr, "Warning: overriding protection rule %q for space %s\n", p.Rule, spaceKey)
+		return nil
+	}
+	return fmt.Errorf("refusing to %s space %s: protected by rule %q (use --override-protection to proceed)", action, spaceKey, p.Rule)
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// protectHandler serves GetPage, GetSpaceByID, and DeletePage, counting deletes.
+func protectHandler(deletes *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete:
+			deletes.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasPrefix(r.URL.Path, "/wiki/api/v2/pages/"):
+			id := strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/")
+			_ = json.NewEncoder(w).Encode(api.Page{ID: id, SpaceID: "space-1", Title: "p"})
+		case strings.HasPrefix(r.URL.Path, "/wiki/api/v2/spaces/"):
+			_ = json.NewEncoder(w).Encode(api.Space{ID: "space-1", Key: "DOCS", HomepageID: "100"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestPageDeleteCmd_Protection(t *testing.T) {
+	tests := []struct {
+		name        string
+		pageID      string
+		rules       []string
+		override    bool
+		wantErr     string
+		wantDeletes int32
+	}{
+		{name: "no rules", pageID: "100", wantDeletes: 1},
+		{name: "page ID rule", pageID: "5", rules: []string{"pageID:5"}, wantErr: `protected by rule "pageID:5"`},
+		{name: "homepage rule", pageID: "100", rules: []string{"DOCS:HOME"}, wantErr: `protected by rule "DOCS:HOME"`},
+		{name: "homepage rule spares other pages", pageID: "101", rules: []string{"DOCS:HOME"}, wantDeletes: 1},
+		{name: "space rule", pageID: "101", rules: []string{"DOCS"}, wantErr: "--override-protection"},
+		{name: "override", pageID: "100", rules: []string{"DOCS:HOME"}, override: true, wantDeletes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			overrideProtection = tt.override
+			force = true
+
+			var deletes atomic.Int32
+			server := httptest.NewServer(protectHandler(&deletes))
+			defer server.Close()
+
+			client, err := api.NewClient(server.URL, "e@x", "t")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			cfg := &config.Config{BaseURL: server.URL}
+			for _, rule := range tt.rules {
+				p, err := config.ParseProtection(rule)
+				if err != nil {
+					t.Fatalf("ParseProtection: %v", err)
+				}
+				cfg.Protections = append(cfg.Protections, p)
+			}
+			withMockClient(t, client, cfg)
+
+			finish := captureStdStreams(t)
+			runErr := pageDeleteCmd.RunE(testCommand(), []string{tt.pageID})
+			_, stderr := finish()
+
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want containing %q", runErr, tt.wantErr)
+				}
+			} else if runErr != nil {
+				t.Fatalf("unexpected error: %v", runErr)
+			}
+			if deletes.Load() != tt.wantDeletes {
+				t.Errorf("deletes = %d, want %d", deletes.Load(), tt.wantDeletes)
+			}
+			if tt.override && !strings.Contains(stderr, "overriding protection") {
+				t.Errorf("stderr = %q, want override warning", stderr)
+			}
+		})
+	}
+}
+
+func TestPageDeleteCmd_Confirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		mode        os.FileMode
+		force       bool
+		wantPrompt  string
+		wantErr     string
+		wantDeletes int32
+	}{
+		{name: "yes", input: "y\n", mode: os.ModeCharDevice, wantPrompt: `Delete page 100 "p"? It has 2 child pages. [y/N] `, wantDeletes: 1},
+		{name: "no", input: "n\n", mode: os.ModeCharDevice, wantErr: "page 100 not deleted"},
+		{name: "empty answer", input: "\n", mode: os.ModeCharDevice, wantErr: "page 100 not deleted"},
+		{name: "no terminal", wantErr: "use --force"},
+		{name: "force", force: true, wantDeletes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			force = tt.force
+			withStdin(t, strings.NewReader(tt.input), tt.mode, nil)
+
+			var deletes atomic.Int32
+			handler := protectHandler(&deletes)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/wiki/api/v2/pages/100/children" {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"results":[{"id":"1","title":"a"},{"id":"2","title":"b"}]}`))
+					return
+				}
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client, err := api.NewClient(server.URL, "e@x", "t")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+			finish := captureStdStreams(t)
+			runErr := pageDeleteCmd.RunE(testCommand(), []string{"100"})
+			_, stderr := finish()
+
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want containing %q", runErr, tt.wantErr)
+				}
+			} else if runErr != nil {
+				t.Fatalf("unexpected error: %v", runErr)
+			}
+			if deletes.Load() != tt.wantDeletes {
+				t.Errorf("deletes = %d, want %d", deletes.Load(), tt.wantDeletes)
+			}
+			if stderr != tt.wantPrompt && tt.wantPrompt != "" {
+				t.Errorf("stderr = %q, want %q", stderr, tt.wantPrompt)
+			}
+		})
+	}
+}
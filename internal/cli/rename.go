@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+var renameEmoji string
+
+var pageRenameCmd = &cobra.Command{
+	Use:   "rename PAGE_ID [NEW_TITLE]",
+	Short: "Change the title of a page",
+	Long: `Change the title of a page, and with --emoji its title emoji, leaving the
+body as it is. The page's current body is published again as the next
+version under the new title, so no markdown is needed.
+
+Without NEW_TITLE, --emoji is required and only the emoji is changed, which
+makes no new version.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var meta pageMeta
+		if len(args) == 2 {
+			meta.title = args[1]
+			if meta.title == "" {
+				return fmt.Errorf("NEW_TITLE cannot be empty")
+			}
+		}
+		if renameEmoji != "" {
+			id, err := converter.EmojiID(renameEmoji)
+			if err != nil {
+				return fmt.Errorf("--emoji: %w", err)
+			}
+			meta.emojiID = id
+		}
+		if meta.title == "" && meta.emojiID == "" {
+			return fmt.Errorf("NEW_TITLE or --emoji is required")
+		}
+
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		existing, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		if err := checkPageProtection(cmd.Context(), client, cfg, pageID, existing.SpaceID, "rename"); err != nil {
+			return err
+		}
+
+		result := existing
+		if meta.title != "" && meta.title != existing.Title {
+			if existing.Body == nil || existing.Body.Storage == nil {
+				return fmt.Errorf("page %s has no storage format body to republish", pageID)
+			}
+			meta.status = existing.Status
+			if meta.status == "" {
+				meta.status = "current"
+			}
+			result, err = publishPageVersion(cmd.Context(), client, existing, meta, existing.Body.Storage.Value, &attachments{}, updateMsg)
+			if err != nil {
+				return err
+			}
+		} else if dryRun {
+			fmt.Printf("Would set the emoji of page %s %q to %s\n", existing.ID, existing.Title, renameEmoji)
+			return nil
+		} else if err := setPageAppearance(cmd.Context(), client, pageID, meta); err != nil {
+			return fmt.Errorf("setting emoji: %w", err)
+		}
+
+		if outputJSON {
+			return printJSON(result)
+		}
+		if dryRun {
+			printDryRunUpdate(existing, result)
+			if renameEmoji != "" {
+				fmt.Printf("Would set the emoji of page %s %q to %s\n", result.ID, result.Title, renameEmoji)
+			}
+			return nil
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
+		if err != nil || space.Key == "" {
+			fmt.Fprintf(os.Stderr, "Warning: page renamed but could not resolve space key for URL\n")
+			fmt.Println(result.ID)
+			return nil
+		}
+		fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
+		return nil
+	},
+}
+
+func init() {
+	pageRenameCmd.Flags().StringVar(&renameEmoji, "emoji", "", "Title emoji, as :shortcode: or the emoji itself")
+	pageRenameCmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version message")
+	pageRenameCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageRenameCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	pageRenameCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+
+	pageCmd.AddCommand(pageRenameCmd)
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageRenameCmd(t *testing.T) {
+	var updates []api.PageUpdateRequest
+	var properties []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/wiki/api/v2/pages/42":
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1","status":"draft","title":"Old","version":{"number":3},"body":{"storage":{"representation":"storage","value":"<p>Body</p>"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/wiki/api/v2/pages/42":
+			body, _ := io.ReadAll(r.Body)
+			var req api.PageUpdateRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				t.Errorf("decoding update: %v", err)
+			}
+			updates = append(updates, req)
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1","title":"` + req.Title + `"}`))
+		case r.URL.Path == "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		case strings.HasPrefix(r.URL.Path, "/wiki/api/v2/pages/42/properties"):
+			properties = append(properties, r.Method+" "+r.URL.Path)
+			if r.Method == "GET" {
+				_, _ = w.Write([]byte(`{"results":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"p1","key":"k","value":"v","version":{"number":1}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := pageRenameCmd.RunE(testCommand(), args)
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+
+	resetPageFlags(t)
+	if _, err := run("42"); err == nil || !strings.Contains(err.Error(), "NEW_TITLE or --emoji") {
+		t.Errorf("rename without a title or emoji: err = %v", err)
+	}
+
+	dryRun = true
+	stdout, err := run("42", "New")
+	if err != nil {
+		t.Fatalf("rename --dry-run: %v", err)
+	}
+	if !strings.Contains(stdout, `renaming it from "Old"`) || len(updates) != 0 {
+		t.Errorf("--dry-run stdout = %q, updates = %d", stdout, len(updates))
+	}
+
+	renameEmoji = ":x:"
+	stdout, err = run("42", "New")
+	if err != nil {
+		t.Fatalf("rename --emoji --dry-run: %v", err)
+	}
+	if !strings.Contains(stdout, `renaming it from "Old"`) || !strings.Contains(stdout, `Would set the emoji of page 42 "New" to :x:`) ||
+		len(updates) != 0 || len(properties) != 0 {
+		t.Errorf("--emoji --dry-run stdout = %q, updates = %d, properties = %v", stdout, len(updates), properties)
+	}
+
+	renameEmoji, dryRun, updateMsg = "", false, "Retitled"
+	stdout, err = run("42", "New")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if want := server.URL + "/wiki/spaces/DOCS/pages/42\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("updates = %d, want 1", len(updates))
+	}
+	req := updates[0]
+	if req.Title != "New" || req.Status != "draft" || req.Version.Number != 4 || req.Version.Message != "Retitled" ||
+		req.Body == nil || req.Body.Value != "<p>Body</p>" {
+		t.Errorf("update = %+v (body %+v)", req, req.Body)
+	}
+	if len(properties) != 0 {
+		t.Errorf("properties set without --emoji: %v", properties)
+	}
+
+	renameEmoji = ":rocket:"
+	if _, err := run("42", "Old"); err != nil {
+		t.Fatalf("rename --emoji: %v", err)
+	}
+	if len(updates) != 1 {
+		t.Errorf("emoji-only rename made a new version")
+	}
+	if len(properties) == 0 {
+		t.Error("emoji-only rename set no properties")
+	}
+
+	renameEmoji = ":nope:"
+	if _, err := run("42"); err == nil || !strings.Contains(err.Error(), "unknown emoji") {
+		t.Errorf("unknown emoji: err = %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// pageIDFlag replaces a page URL given to the page ID flag name, such as
+// --parent, with the page's ID, as pageIDArg does for arguments. An unset
+// flag is left empty.
+func pageIDFlag(name string, value *string) error {
+	if *value == "" {
+		return nil
+	}
+	id, err := pageIDArg(*value)
+	if err != nil {
+		return fmt.Errorf("--%s: %w", name, err)
+	}
+	*value = id
+	return nil
+}
+
+// maxTitleMatches caps the pages listed when a title is ambiguous
+const maxTitleMatches = 10
+
+// pageIDArg returns the page ID in arg, which is a page ID or a page URL as
+// copied from the browser: a /pages/ID/... link, a link with a pageId
+// query, or a /x/ tiny link
+func pageIDArg(arg string) (string, error) {
+	if !strings.Contains(arg, "://") {
+		return arg, nil
+	}
+	u, err := url.Parse(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL %s: %w", arg, err)
+	}
+	if id := u.Query().Get("pageId"); isNumericID(id) {
+		return id, nil
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, segment := range segments {
+		if i+1 >= len(segments) {
+			break
+		}
+		switch segment {
+		case "pages":
+			// /pages/ID/Title, or /pages/edit-v2/ID in the editor
+			for _, next := range segments[i+1 : min(i+3, len(segments))] {
+				if isNumericID(next) {
+					return next, nil
+				}
+			}
+		case "x":
+			if id, ok := tinyLinkID(segments[i+1]); ok {
+				return id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no page ID found in URL %s", arg)
+}
+
+// tinyLinkID decodes the code of a /x/ tiny link: the page ID's
+// little-endian bytes in base64, with - for / and _ for +, and trailing
+// zero digits (A) and padding dropped
+func tinyLinkID(code string) (string, bool) {
+	if code == "" || len(code) > 11 {
+		return "", false
+	}
+	code = strings.NewReplacer("-", "/", "_", "+").Replace(code)
+	code += strings.Repeat("A", 11-len(code)) + "="
+	data, err := base64.StdEncoding.DecodeString(code)
+	if err != nil || len(data) != 8 {
+		return "", false
+	}
+	id := binary.LittleEndian.Uint64(data)
+	if id == 0 {
+		return "", false
+	}
+	return strconv.FormatUint(id, 10), true
+}
+
+// resolvePageArg returns the ID of the page given by ID or URL as the only
+// argument or, with no argument, of the page titled --title. The title is
+// looked up in the --space space, or the configured default, or else in
+// every space, and must name exactly one page.
+func resolvePageArg(ctx context.Context, client *api.Client, cfg *config.Config, args []string) (string, error) {
+	if len(args) == 1 {
+		return pageIDArg(args[0])
+	}
+	if pageTitle == "" {
+		return "", fmt.Errorf("page ID or title required: give PAGE_ID or use --title")
+	}
+
+	spaceKey := pageSpace
+	if spaceKey == "" {
+		spaceKey = cfg.SpaceKey
+	}
+	spaceID := ""
+	if spaceKey != "" {
+		space, err := client.GetSpace(ctx, spaceKey)
+		if err != nil {
+			return "", fmt.Errorf("getting space: %w", err)
+		}
+		spaceID = space.ID
+	}
+
+	pages, more, err := client.FindPagesByTitle(ctx, spaceID, pageTitle, maxTitleMatches)
+	if err != nil {
+		return "", fmt.Errorf("looking up page: %w", err)
+	}
+	switch {
+	case len(pages) == 0 && spaceKey != "":
+		return "", fmt.Errorf("no page titled %q in space %s", pageTitle, spaceKey)
+	case len(pages) == 0:
+		return "", fmt.Errorf("no page titled %q", pageTitle)
+	case len(pages) == 1:
+		return pages[0].ID, nil
+	}
+
+	matches := make([]string, 0, len(pages))
+	spaceKeys := map[string]string{}
+	for _, page := range pages {
+		key, ok := spaceKeys[page.SpaceID]
+		if !ok {
+			key = page.SpaceID
+			if space, err := client.GetSpaceByID(ctx, page.SpaceID); err == nil && space.Key != "" {
+				key = space.Key
+			}
+			spaceKeys[page.SpaceID] = key
+		}
+		matches = append(matches, fmt.Sprintf("%s in space %s", page.ID, key))
+	}
+	count := fmt.Sprint(len(pages))
+	if more {
+		count = "more than " + count
+	}
+	return "", fmt.Errorf("%s pages are titled %q (%s): use --space or the page ID", count, pageTitle, strings.Join(matches, ", "))
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestResolvePageArg(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/wiki/api/v2/spaces":
+			if q.Get("keys") == "OPS" {
+				_, _ = w.Write([]byte(`{"results":[{"id":"s1","key":"OPS"}]}`))
+			} else {
+				_, _ = w.Write([]byte(`{"results":[{"id":"s9","key":"EMPTY"}]}`))
+			}
+		case "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"OPS"}`))
+		case "/wiki/api/v2/spaces/s2":
+			_, _ = w.Write([]byte(`{"id":"s2","key":"DEV"}`))
+		case "/wiki/api/v2/pages":
+			switch {
+			case q.Get("space-id") == "s1":
+				_, _ = w.Write([]byte(`{"results":[{"id":"42","spaceId":"s1","title":"Runbook: Deploys"}]}`))
+			case q.Get("space-id") != "":
+				_, _ = w.Write([]byte(`{"results":[]}`))
+			default:
+				_, _ = w.Write([]byte(`{"results":[{"id":"42","spaceId":"s1","title":"Runbook: Deploys"},{"id":"43","spaceId":"s2","title":"Runbook: Deploys"}]}`))
+			}
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		title   string
+		space   string
+		want    string
+		wantErr string
+	}{
+		{name: "page ID", args: []string{"7"}, title: "ignored", want: "7"},
+		{name: "title in space", title: "Runbook: Deploys", space: "OPS", want: "42"},
+		{name: "not in space", title: "Runbook: Deploys", space: "EMPTY", wantErr: `no page titled "Runbook: Deploys" in space EMPTY`},
+		{name: "ambiguous", title: "Runbook: Deploys", wantErr: `2 pages are titled "Runbook: Deploys" (42 in space OPS, 43 in space DEV): use --space or the page ID`},
+		{name: "neither", wantErr: "page ID or title required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			pageTitle = tt.title
+			pageSpace = tt.space
+			got, err := resolvePageArg(context.Background(), client, &config.Config{}, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolvePageArg() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolvePageArg() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolvePageArg() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageIDArg(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{arg: "123456", want: "123456"},
+		{arg: "https://example.atlassian.net/wiki/spaces/OPS/pages/123456/Runbook+Deploys", want: "123456"},
+		{arg: "https://example.atlassian.net/wiki/spaces/OPS/pages/123456", want: "123456"},
+		{arg: "https://example.atlassian.net/wiki/spaces/OPS/pages/edit-v2/123456", want: "123456"},
+		{arg: "https://example.atlassian.net/wiki/pages/viewpage.action?pageId=123456", want: "123456"},
+		{arg: "https://example.atlassian.net/wiki/x/A4AB", want: "98307"},
+		{arg: "https://example.atlassian.net/wiki/x/0gKWSQ", want: "1234567890"},
+		{arg: "https://example.atlassian.net/wiki/spaces/OPS/overview", wantErr: true},
+		{arg: "https://example.atlassian.net/wiki/x/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := pageIDArg(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pageIDArg(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pageIDArg(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageIDFlag(t *testing.T) {
+	value := "https://example.atlassian.net/wiki/spaces/OPS/pages/123456/Runbook"
+	if err := pageIDFlag("parent", &value); err != nil || value != "123456" {
+		t.Errorf("pageIDFlag(URL) = %q, %v, want 123456", value, err)
+	}
+	value = ""
+	if err := pageIDFlag("parent", &value); err != nil || value != "" {
+		t.Errorf("pageIDFlag(\"\") = %q, %v, want it left empty", value, err)
+	}
+	value = "https://example.atlassian.net/wiki/spaces/OPS/overview"
+	if err := pageIDFlag("parent", &value); err == nil || !strings.HasPrefix(err.Error(), "--parent: ") {
+		t.Errorf("pageIDFlag(space URL) error = %v, want a --parent error", err)
+	}
+}
@@ -18,6 +18,8 @@ var (
 	Version = "dev"
 
 	verbose bool
+	// profile selects a [profile.NAME] section of the config file
+	configProfile string
 )
 
 var rootCmd = &cobra.Command{
@@ -32,7 +34,12 @@ Environment Variables:
   CONFLUENCE_BASE_URL       Confluence URL (overrides ATLASSIAN_BASE_URL)
   CONFLUENCE_EMAIL          User email (overrides ATLASSIAN_EMAIL)
   CONFLUENCE_API_TOKEN      API token (overrides ATLASSIAN_API_TOKEN)
-  CONFLUENCE_SPACE_KEY      Default space key (optional)`,
+  CONFLUENCE_SPACE_KEY      Default space key (optional)
+  ACON_CONFIG               Config file path (default: ~/.config/acon/config.toml)
+  ACON_PROFILE              Config file profile to use (overridden by --profile)
+
+The site, credentials, and default space may instead be kept in the config
+file, with a profile for each site: see acon config.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cmd.Help()
 	},
@@ -40,6 +47,8 @@ Environment Variables:
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Show detailed warnings and debug information")
+	rootCmd.PersistentFlags().StringVar(&configProfile, "profile", "", "Config file profile to use (default $ACON_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "", "Format of list and search results: "+outputFormats)
 
 	rootCmd.Version = Version
 	rootCmd.SetVersionTemplate(`acon version {{.Version}}
@@ -64,6 +73,7 @@ Report issues: https://github.com/grantcarthew/acon/issues/new
 func Execute() error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	registerCompletions(rootCmd)
 	return rootCmd.ExecuteContext(ctx)
 }
 
@@ -83,7 +93,7 @@ func defaultNewClient() (*api.Client, *config.Config, error) {
 		verboseLog = os.Stderr
 	}
 
-	cfg, err := config.LoadWithVerbose(verboseLog)
+	cfg, err := config.LoadProfile(configProfile, verboseLog)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -110,6 +110,12 @@ func truncateExcerpt(text string, maxLen int) string {
 	return truncated + "..."
 }
 
+// searchOutput is the JSON shape of search results with the resume cursor
+type searchOutput struct {
+	*api.SearchResponse
+	NextCursor api.Cursor `json:"next_cursor,omitempty"`
+}
+
 var (
 	searchTitle   string
 	searchLabel   string
@@ -119,6 +125,8 @@ var (
 	searchCursor  string
 	searchType    string
 	searchCQL     string
+	searchStart   int
+	searchAll     bool
 )
 
 var searchCmd = &cobra.Command{
@@ -190,75 +198,133 @@ var searchCmd = &cobra.Command{
 			}
 		}
 
-		// Execute search
-		result, nextCursor, err := client.Search(cmd.Context(), cql, searchLimit, searchCursor)
+		format, err := resultFormat()
 		if err != nil {
-			return fmt.Errorf("search failed: %w", err)
+			return err
+		}
+		if searchStart < 0 {
+			return fmt.Errorf("--start cannot be negative")
+		}
+		if searchStart > 0 && searchCursor != "" {
+			return fmt.Errorf("--start cannot be combined with --cursor")
 		}
 
-		// Output results
-		if outputJSON {
-			return printJSON(result)
+		// Execute search
+		fetch := func(cursor api.Cursor) (*api.SearchResponse, api.Cursor, error) {
+			var result *api.SearchResponse
+			var next api.Cursor
+			var err error
+			if cursor == "" && searchStart > 0 {
+				result, next, err = client.SearchFrom(cmd.Context(), cql, searchLimit, searchStart)
+			} else {
+				result, next, err = client.SearchWithCursor(cmd.Context(), cql, searchLimit, cursor)
+			}
+			if err != nil {
+				return nil, "", fmt.Errorf("search failed: %w", err)
+			}
+			return result, next, nil
+		}
+		result, nextCursor, err := fetch(api.Cursor(searchCursor))
+		if err != nil {
+			return err
 		}
 
-		// Human-readable output
-		if len(result.Results) == 0 {
-			fmt.Println("No results found")
-			return nil
+		// Highlight the text query, or failing that the title query, in excerpts
+		highlightTerm := textQuery
+		if highlightTerm == "" {
+			highlightTerm = searchTitle
 		}
 
-		for i, searchResult := range result.Results {
-			// Title with space key
-			spaceKey := searchResult.Content.Space.Key
-			fmt.Printf("%s (%s)\n", searchResult.Title, spaceKey)
-
-			// Full URL - construct from base URL
-			if searchResult.URL != "" {
-				// Handle both relative and absolute URLs
-				var fullURL string
-				if strings.HasPrefix(searchResult.URL, "http://") || strings.HasPrefix(searchResult.URL, "https://") {
-					// Absolute URL - use as-is
-					fullURL = searchResult.URL
-				} else if strings.HasPrefix(searchResult.URL, "/") {
-					// Relative URL - append to base (already validated above)
-					fullURL = strings.TrimRight(cfg.BaseURL, "/") + searchResult.URL
-				} else {
-					// Invalid format - warn user and skip (API contract issue)
-					fmt.Fprintf(os.Stderr, "Warning: Skipping malformed URL for '%s': %s\n", searchResult.Title, searchResult.URL)
-					fullURL = ""
+		if searchAll {
+			switch format {
+			case "", "jsonl", "template", "ids", "urls":
+				// These print result by result, so each batch is printed as it arrives
+				count := 0
+				for {
+					for _, searchResult := range result.Results {
+						switch format {
+						case "jsonl":
+							err = printJSONLines(os.Stdout, []api.SearchResult{searchResult}, "")
+						case "template":
+							err = printTemplate(os.Stdout, []api.SearchResult{searchResult})
+						case "ids", "urls":
+							printLines(os.Stdout, []string{searchResultLine(format, cfg.BaseURL, searchResult)}, "")
+						default:
+							if count > 0 {
+								fmt.Println()
+							}
+							printSearchResult(searchResult, cfg.BaseURL, highlightTerm)
+						}
+						if err != nil {
+							return err
+						}
+						count++
+					}
+					if nextCursor == "" {
+						break
+					}
+					if result, nextCursor, err = fetch(nextCursor); err != nil {
+						return err
+					}
 				}
-
-				if fullURL != "" {
-					fmt.Printf("%s\n", fullURL)
+				if format == "" {
+					if count == 0 {
+						fmt.Println("No results found")
+					} else {
+						fmt.Printf("\nShowing all %d results\n", count)
+					}
 				}
+				return nil
 			}
-
-			// Excerpt (with search term highlighting for terminal)
-			if searchResult.Excerpt != "" {
-				// Use text query or title query for highlighting
-				highlightTerm := textQuery
-				if highlightTerm == "" {
-					highlightTerm = searchTitle
+			// The other formats need every result before printing any
+			for nextCursor != "" {
+				more, next, err := fetch(nextCursor)
+				if err != nil {
+					return err
 				}
-				fmt.Printf("%s\n", formatExcerptForTerminal(searchResult.Excerpt, highlightTerm))
+				result.Results = append(result.Results, more.Results...)
+				nextCursor = next
 			}
+			result.Size = len(result.Results)
+		}
 
-			// Modified date
-			if searchResult.LastModified != "" {
-				// Parse and format the date
-				t, err := time.Parse(time.RFC3339, searchResult.LastModified)
-				if err != nil {
-					// Log warning in verbose mode only
-					if verbose {
-						fmt.Fprintf(os.Stderr, "Warning: Could not parse date for '%s' (raw: %s, error: %v)\n",
-							searchResult.Title, searchResult.LastModified, err)
-					}
-					// Show "Unknown" instead of potentially malformed data
-					fmt.Printf("Modified: Unknown\n")
-				} else {
-					fmt.Printf("Modified: %s\n", t.Format("2006-01-02"))
-				}
+		// Output results
+		switch format {
+		case "json":
+			return printJSON(searchOutput{SearchResponse: result, NextCursor: nextCursor})
+		case "jsonl":
+			return printJSONLines(os.Stdout, result.Results, nextCursor)
+		case "ids", "urls":
+			var lines []string
+			for _, r := range result.Results {
+				lines = append(lines, searchResultLine(format, cfg.BaseURL, r))
 			}
+			printLines(os.Stdout, lines, nextCursor)
+			return nil
+		case "template":
+			if err := printTemplate(os.Stdout, result.Results); err != nil {
+				return err
+			}
+			printNextCursor(os.Stderr, nextCursor)
+			return nil
+		case "":
+		default:
+			t := resultTable{columns: []string{"id", "type", "title", "space", "url", "modified"}}
+			for _, r := range result.Results {
+				t.rows = append(t.rows, []string{r.Content.ID, r.Content.Type, r.Title, r.Content.Space.Key,
+					searchResultURL(cfg.BaseURL, r), r.LastModified})
+			}
+			return printResults(os.Stdout, format, t, nextCursor)
+		}
+
+		// Human-readable output
+		if len(result.Results) == 0 {
+			fmt.Println("No results found")
+			return nil
+		}
+
+		for i, searchResult := range result.Results {
+			printSearchResult(searchResult, cfg.BaseURL, highlightTerm)
 
 			// Separator between results (but not after the last one)
 			if i < len(result.Results)-1 {
@@ -279,6 +345,67 @@ var searchCmd = &cobra.Command{
 	},
 }
 
+// printSearchResult writes one search result as its title and space, URL,
+// excerpt with highlightTerm highlighted, and modified date
+func printSearchResult(searchResult api.SearchResult, baseURL, highlightTerm string) {
+	// Title with space key
+	fmt.Printf("%s (%s)\n", searchResult.Title, searchResult.Content.Space.Key)
+
+	// Full URL - construct from base URL
+	if fullURL := searchResultURL(baseURL, searchResult); fullURL != "" {
+		fmt.Printf("%s\n", fullURL)
+	}
+
+	// Excerpt (with search term highlighting for terminal)
+	if searchResult.Excerpt != "" {
+		fmt.Printf("%s\n", formatExcerptForTerminal(searchResult.Excerpt, highlightTerm))
+	}
+
+	// Modified date
+	if searchResult.LastModified != "" {
+		// Parse and format the date
+		t, err := time.Parse(time.RFC3339, searchResult.LastModified)
+		if err != nil {
+			// Log warning in verbose mode only
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Warning: Could not parse date for '%s' (raw: %s, error: %v)\n",
+					searchResult.Title, searchResult.LastModified, err)
+			}
+			// Show "Unknown" instead of potentially malformed data
+			fmt.Printf("Modified: Unknown\n")
+		} else {
+			fmt.Printf("Modified: %s\n", t.Format("2006-01-02"))
+		}
+	}
+}
+
+// searchResultLine returns the ID of a search result for --ids, or its URL
+// for --urls
+func searchResultLine(format, baseURL string, result api.SearchResult) string {
+	if format == "ids" {
+		return result.Content.ID
+	}
+	return searchResultURL(baseURL, result)
+}
+
+// searchResultURL returns the full URL of a search result, or "" if it has
+// none or it is malformed
+func searchResultURL(baseURL string, result api.SearchResult) string {
+	switch {
+	case result.URL == "":
+		return ""
+	case strings.HasPrefix(result.URL, "http://") || strings.HasPrefix(result.URL, "https://"):
+		// Absolute URL - use as-is
+		return result.URL
+	case strings.HasPrefix(result.URL, "/"):
+		// Relative URL - append to base
+		return strings.TrimRight(baseURL, "/") + result.URL
+	}
+	// Invalid format - warn user and skip (API contract issue)
+	fmt.Fprintf(os.Stderr, "Warning: Skipping malformed URL for '%s': %s\n", result.Title, result.URL)
+	return ""
+}
+
 func init() {
 	searchCmd.Flags().StringVar(&searchTitle, "title", "", "Search in page titles")
 	searchCmd.Flags().StringVar(&searchLabel, "label", "", "Search by label (exact match)")
@@ -286,9 +413,15 @@ func init() {
 	searchCmd.Flags().StringVarP(&searchSpace, "space", "s", "", "Filter by space key (uses config default if not specified)")
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "l", api.DefaultSearchLimit, "Maximum number of results per page")
 	searchCmd.Flags().StringVar(&searchCursor, "cursor", "", "Pagination cursor from previous search")
+	searchCmd.Flags().IntVar(&searchStart, "start", 0, "Skip this many results before the first one shown")
+	searchCmd.Flags().BoolVar(&searchAll, "all", false, "Fetch every page of results, with --limit results per request")
 	searchCmd.Flags().StringVar(&searchType, "type", "", "Content type (page, blogpost, attachment, etc.)")
 	searchCmd.Flags().StringVar(&searchCQL, "cql", "", "Raw CQL query (overrides all other flags)")
 	searchCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	searchCmd.Flags().StringVar(&outputTemplate, "template", "", templateUsage)
+	searchCmd.Flags().BoolVar(&outputJSONL, "jsonl", false, jsonlUsage)
+	searchCmd.Flags().BoolVar(&outputIDs, "ids", false, idsUsage)
+	searchCmd.Flags().BoolVar(&outputURLs, "urls", false, urlsUsage)
 
 	searchCmd.GroupID = "core"
 	rootCmd.AddCommand(searchCmd)
@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
 )
 
 func TestFormatExcerptForTerminal(t *testing.T) {
@@ -185,3 +190,107 @@ func TestTruncateExcerpt(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchCmd_StartAll(t *testing.T) {
+	var starts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		starts = append(starts, r.URL.Query().Get("start"))
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"results":[{"title":"One","url":"/spaces/OPS/pages/1","content":{"id":"1","space":{"key":"OPS"}}},` +
+				`{"title":"Two","content":{"id":"2","space":{"key":"OPS"}}}],"totalSize":3,` +
+				`"_links":{"next":"/rest/api/search?cursor=c2"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"title":"Three","content":{"id":"3","space":{"key":"OPS"}}}],"totalSize":3}`))
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	reset := func() {
+		searchTitle, searchCursor = "", ""
+		searchLimit, searchStart, searchAll = api.DefaultSearchLimit, 0, false
+	}
+	t.Cleanup(reset)
+
+	tests := []struct {
+		name       string
+		setup      func()
+		want       string
+		wantStarts string
+		wantErr    string
+	}{
+		{
+			name:       "first page",
+			want:       "One (OPS)\n" + server.URL + "/spaces/OPS/pages/1\n\nTwo (OPS)\n\nShowing 2 of 3 results\nNext Cursor: ",
+			wantStarts: "",
+		},
+		{
+			name:       "all",
+			setup:      func() { searchAll = true },
+			want:       "One (OPS)\n" + server.URL + "/spaces/OPS/pages/1\n\nTwo (OPS)\n\nThree (OPS)\n\nShowing all 3 results\n",
+			wantStarts: ",",
+		},
+		{
+			name:       "all as jsonl",
+			setup:      func() { searchAll, outputJSONL = true, true },
+			want:       `{"title":"One"`,
+			wantStarts: ",",
+		},
+		{
+			name:       "all ids",
+			setup:      func() { searchAll, outputIDs = true, true },
+			want:       "1\n2\n3\n",
+			wantStarts: ",",
+		},
+		{
+			name:       "urls",
+			setup:      func() { outputURLs = true },
+			want:       server.URL + "/spaces/OPS/pages/1\n",
+			wantStarts: "",
+		},
+		{
+			name:       "start",
+			setup:      func() { searchStart = 5; outputTemplate = "{{.Title}}" },
+			want:       "One\nTwo\n",
+			wantStarts: "5",
+		},
+		{
+			name:    "start with cursor",
+			setup:   func() { searchStart, searchCursor = 5, "abc" },
+			wantErr: "--start cannot be combined with --cursor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			reset()
+			starts = nil
+			if tt.setup != nil {
+				tt.setup()
+			}
+			finish := captureStdStreams(t)
+			runErr := searchCmd.RunE(testCommand(), []string{})
+			stdout, _ := finish()
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", runErr, tt.wantErr)
+				}
+				return
+			}
+			if runErr != nil {
+				t.Fatalf("search: %v", runErr)
+			}
+			if !strings.HasPrefix(stdout, tt.want) {
+				t.Errorf("stdout = %q, want prefix %q", stdout, tt.want)
+			}
+			if strings.Join(starts, ",") != tt.wantStarts {
+				t.Errorf("start params = %q, want %q", strings.Join(starts, ","), tt.wantStarts)
+			}
+		})
+	}
+}
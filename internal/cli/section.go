@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+var sectionHeading string
+
+var pageSectionCmd = &cobra.Command{
+	Use:   "section",
+	Short: "Change part of a page by heading",
+}
+
+var pageSectionUpdateCmd = &cobra.Command{
+	Use:   "update PAGE_ID",
+	Short: "Replace the content under a heading",
+	Long: `Convert a markdown fragment and publish it as the content under the heading
+--heading, leaving the heading and the rest of the page as they are. The
+content replaced runs to the next heading of the same or a higher level, or
+to the end of the layout cell or other element the heading is in.
+
+--heading is matched against the heading's text ignoring case, and must
+match exactly one heading. Headings in the fragment are published at the
+levels written, so use --heading-offset to put them below the heading.
+
+The new version is made from the version read, so if the page changes in
+between, the update fails rather than losing the other change.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sectionHeading == "" {
+			return fmt.Errorf("--heading flag is required")
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		pageID, err := pageIDArg(args[0])
+		if err != nil {
+			return err
+		}
+		existing, err := client.GetPage(cmd.Context(), pageID)
+		if err != nil {
+			return fmt.Errorf("getting page: %w", err)
+		}
+		if err := checkPageProtection(cmd.Context(), client, cfg, pageID, existing.SpaceID, "update"); err != nil {
+			return err
+		}
+		if existing.Body == nil || existing.Body.Storage == nil {
+			return fmt.Errorf("page %s has no storage format body", pageID)
+		}
+
+		content, err := readMarkdownFragment(pageFile)
+		if err != nil {
+			return err
+		}
+		opts, err := converterOptionsForSpaceID(cmd.Context(), client, cfg, existing.SpaceID)
+		if err != nil {
+			return err
+		}
+		if err := applyConverterFlags(&opts); err != nil {
+			return err
+		}
+		fragment, files, err := convertMarkdown(cmd.Context(), client, converter.NewConverter(opts), converter.Document{}, content, pageFile)
+		if err != nil {
+			return err
+		}
+		htmlContent, err := converter.ReplaceStorageSection(existing.Body.Storage.Value, sectionHeading, fragment)
+		if err != nil {
+			return fmt.Errorf("page %s: %w", pageID, err)
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Page] Replaced section %q with %d bytes of storage format\n", sectionHeading, len(fragment))
+		}
+
+		meta := pageMeta{status: existing.Status}
+		if meta.status == "" {
+			meta.status = "current"
+		}
+		result, err := publishPageVersion(cmd.Context(), client, existing, meta, htmlContent, files, updateMsg)
+		if err != nil {
+			return err
+		}
+
+		if outputJSON {
+			return printJSON(result)
+		}
+		if dryRun {
+			printDryRunUpdate(existing, result)
+			return nil
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), result.SpaceID)
+		if err != nil || space.Key == "" {
+			fmt.Fprintf(os.Stderr, "Warning: page updated but could not resolve space key for URL\n")
+			fmt.Println(result.ID)
+			return nil
+		}
+		fmt.Println(pageURL(cfg.BaseURL, space.Key, result.ID))
+		return nil
+	},
+}
+
+func init() {
+	pageSectionUpdateCmd.Flags().StringVar(&sectionHeading, "heading", "", "Text of the heading whose content to replace (required)")
+	pageSectionUpdateCmd.Flags().StringVarP(&pageFile, "file", "f", "", "Markdown file, or - for stdin")
+	pageSectionUpdateCmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version update message")
+	pageSectionUpdateCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageSectionUpdateCmd.Flags().BoolVar(&renderDiagrams, "render-diagrams", false, "Render diagram fences locally and attach them as images")
+	pageSectionUpdateCmd.Flags().StringVar(&diagramFormat, "diagram-format", "svg", "Rendered diagram format: svg, png")
+	pageSectionUpdateCmd.Flags().IntVar(&headingOff, "heading-offset", 0, "Shift heading levels by this much, e.g. 1 to publish # as h2 (-5 to 5)")
+	pageSectionUpdateCmd.Flags().BoolVar(&allowHTML, "allow-html", false, "Pass through raw HTML <br>, <sup>, and <sub> instead of omitting it")
+	pageSectionUpdateCmd.Flags().BoolVar(&bareDates, "dates", false, "Convert ISO dates (2025-03-01) in text to date lozenges, not just {date:...}")
+	pageSectionUpdateCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of omitting raw HTML that cannot be published")
+	pageSectionUpdateCmd.Flags().BoolVar(&typography, "typography", false, "Use curly quotes, en and em dashes, and ellipses for straight quotes, -- and ---, and ...")
+	pageSectionUpdateCmd.Flags().BoolVar(&subscript, "subscript", false, "Convert ~text~ to subscript instead of strikethrough")
+	pageSectionUpdateCmd.Flags().BoolVar(&hardWraps, "hard-wraps", false, "Turn every newline within a paragraph into a line break")
+	pageSectionUpdateCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+	pageSectionUpdateCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+	pageSectionCmd.AddCommand(pageSectionUpdateCmd)
+	pageCmd.AddCommand(pageSectionCmd)
+}
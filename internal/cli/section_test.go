@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageSectionUpdateCmd(t *testing.T) {
+	var update api.PageUpdateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/wiki/api/v2/pages/42":
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1","status":"current","title":"Runbook","version":{"number":5},"body":{"storage":{"representation":"storage","value":"<h2>Deploy</h2><p>Ship</p><h2>Rollback Steps</h2><p>Old</p><h2>Contacts</h2><p>Ops</p>"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/wiki/api/v2/pages/42":
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &update); err != nil {
+				t.Errorf("decoding update: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id":"42","spaceId":"s1"}`))
+		case r.URL.Path == "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	file := filepath.Join(t.TempDir(), "steps.md")
+	if err := os.WriteFile(file, []byte("Stop\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run := func() (string, error) {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := pageSectionUpdateCmd.RunE(testCommand(), []string{"42"})
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+
+	resetPageFlags(t)
+	pageFile = file
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), "--heading flag is required") {
+		t.Errorf("no --heading: err = %v", err)
+	}
+
+	sectionHeading = "Missing"
+	if _, err := run(); err == nil || !strings.Contains(err.Error(), `no heading "Missing"`) {
+		t.Errorf("missing heading: err = %v", err)
+	}
+
+	sectionHeading, updateMsg = "rollback steps", "New steps"
+	stdout, err := run()
+	if err != nil {
+		t.Fatalf("section update: %v", err)
+	}
+	if want := server.URL + "/wiki/spaces/DOCS/pages/42\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	want := "<h2>Deploy</h2><p>Ship</p><h2>Rollback Steps</h2><p>Stop</p>\n<h2>Contacts</h2><p>Ops</p>"
+	if update.Body == nil || update.Body.Value != want {
+		t.Errorf("body = %+v, want %q", update.Body, want)
+	}
+	if update.Title != "Runbook" || update.Version.Number != 6 || update.Version.Message != "New steps" {
+		t.Errorf("update = %+v", update)
+	}
+}
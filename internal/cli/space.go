@@ -1,46 +1,78 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
 	"github.com/spf13/cobra"
 )
 
 var (
-	spaceLimit int
+	spaceLimit       int
+	spaceCursor      string
+	spaceName        string
+	spaceDescription string
+	spaceHomepage    string
+	spaceSetHome     string
 )
 
 var spaceCmd = &cobra.Command{
 	Use:   "space",
 	Short: "Manage Confluence spaces",
-	Long:  "View and list Confluence spaces",
+	Long:  "View, list, and update Confluence spaces",
 }
 
 var spaceViewCmd = &cobra.Command{
-	Use:   "view SPACE_KEY",
+	Use:   "view SPACE_KEY|SPACE_ID",
 	Short: "View a space",
-	Long:  "View details of a Confluence space",
+	Long:  "View details of a Confluence space by key or numeric ID",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, _, err := initClient()
 		if err != nil {
 			return err
 		}
+		format, err := viewFormat()
+		if err != nil {
+			return err
+		}
 
-		spaceKey := args[0]
-
-		space, err := client.GetSpace(cmd.Context(), spaceKey)
+		var space *api.Space
+		if isNumericID(args[0]) {
+			space, err = client.GetSpaceByID(cmd.Context(), args[0])
+		} else {
+			space, err = client.GetSpace(cmd.Context(), args[0])
+		}
 		if err != nil {
 			return fmt.Errorf("getting space: %w", err)
 		}
 
-		if outputJSON {
+		switch format {
+		case "json":
 			return printJSON(space)
+		case "template":
+			return printTemplate(os.Stdout, []*api.Space{space})
 		}
 		fmt.Printf("ID: %s\n", space.ID)
 		fmt.Printf("Key: %s\n", space.Key)
 		fmt.Printf("Name: %s\n", space.Name)
 		fmt.Printf("Type: %s\n", space.Type)
+		if space.Status != "" {
+			fmt.Printf("Status: %s\n", space.Status)
+		}
+		if space.HomepageID != "" {
+			fmt.Printf("Homepage ID: %s\n", space.HomepageID)
+		}
+		if space.CreatedAt != "" {
+			fmt.Printf("Created: %s\n", space.CreatedAt)
+		}
+		if desc := space.DescriptionText(); desc != "" {
+			fmt.Printf("Description: %s\n", desc)
+		}
 		return nil
 	},
 }
@@ -50,18 +82,49 @@ var spaceListCmd = &cobra.Command{
 	Short: "List spaces",
 	Long:  "List Confluence spaces",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, _, err := initClient()
+		client, cfg, err := initClient()
 		if err != nil {
 			return err
 		}
 
-		spaces, err := client.ListSpaces(cmd.Context(), spaceLimit)
+		spaces, next, err := client.ListSpacesWithCursor(cmd.Context(), spaceLimit, api.Cursor(spaceCursor))
 		if err != nil {
 			return fmt.Errorf("listing spaces: %w", err)
 		}
 
-		if outputJSON {
-			return printJSON(spaces)
+		format, err := resultFormat()
+		if err != nil {
+			return err
+		}
+		switch format {
+		case "json":
+			return printJSON(newListOutput(spaces, next))
+		case "jsonl":
+			return printJSONLines(os.Stdout, spaces, next)
+		case "ids", "urls":
+			var lines []string
+			for _, space := range spaces {
+				if format == "ids" {
+					lines = append(lines, space.ID)
+				} else {
+					lines = append(lines, spaceURL(cfg.BaseURL, space.Key))
+				}
+			}
+			printLines(os.Stdout, lines, next)
+			return nil
+		case "template":
+			if err := printTemplate(os.Stdout, spaces); err != nil {
+				return err
+			}
+			printNextCursor(os.Stderr, next)
+			return nil
+		case "":
+		default:
+			t := resultTable{columns: []string{"key", "name", "type", "id"}}
+			for _, space := range spaces {
+				t.rows = append(t.rows, []string{space.Key, space.Name, space.Type, space.ID})
+			}
+			return printResults(os.Stdout, format, t, next)
 		}
 		fmt.Println("Confluence Spaces:")
 		for _, space := range spaces {
@@ -71,15 +134,166 @@ var spaceListCmd = &cobra.Command{
 			fmt.Printf("ID: %s\n", space.ID)
 			fmt.Println("---")
 		}
+		printNextCursor(os.Stdout, next)
 		return nil
 	},
 }
 
+var spaceUpdateCmd = &cobra.Command{
+	Use:   "update SPACE_KEY",
+	Short: "Update a space",
+	Long:  "Update the name, description, or homepage of a Confluence space",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("homepage", &spaceHomepage); err != nil {
+			return err
+		}
+		req := &api.SpaceUpdateRequest{}
+		if cmd.Flags().Changed("name") {
+			req.Name = &spaceName
+		}
+		if cmd.Flags().Changed("description") {
+			req.Description = &spaceDescription
+		}
+		if cmd.Flags().Changed("homepage") {
+			req.HomepageID = &spaceHomepage
+		}
+		if req.Name == nil && req.Description == nil && req.HomepageID == nil {
+			return fmt.Errorf("nothing to update: use --name, --description, or --homepage")
+		}
+
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		if err := checkSpaceProtection(cfg, args[0], "update"); err != nil {
+			return err
+		}
+
+		space, err := client.UpdateSpace(cmd.Context(), args[0], req)
+		if err != nil {
+			return fmt.Errorf("updating space: %w", err)
+		}
+
+		if outputJSON {
+			return printJSON(space)
+		}
+		fmt.Printf("Space %s updated successfully\n", space.Key)
+		return nil
+	},
+}
+
+var spaceHomepageCmd = &cobra.Command{
+	Use:   "homepage SPACE_KEY",
+	Short: "View or set a space's homepage",
+	Long:  "Display a space's homepage as markdown, or set a different page in the space as its homepage with --set",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("set", &spaceSetHome); err != nil {
+			return err
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		space, err := client.GetSpace(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+
+		if cmd.Flags().Changed("set") {
+			return setSpaceHomepage(cmd.Context(), client, cfg, space, spaceSetHome)
+		}
+
+		if space.HomepageID == "" {
+			return fmt.Errorf("space %s has no homepage", space.Key)
+		}
+
+		page, err := client.GetPage(cmd.Context(), space.HomepageID)
+		if err != nil {
+			return fmt.Errorf("getting homepage: %w", err)
+		}
+
+		if outputJSON {
+			return printJSON(page)
+		}
+		printPageMarkdown(cmd.Context(), client, cfg.BaseURL, page, "Space Homepage")
+		return nil
+	},
+}
+
+// setSpaceHomepage makes pageID the homepage of space. The page must already
+// belong to the space.
+func setSpaceHomepage(ctx context.Context, client *api.Client, cfg *config.Config, space *api.Space, pageID string) error {
+	if strings.TrimSpace(pageID) == "" {
+		return fmt.Errorf("--set requires a page ID")
+	}
+	if err := checkSpaceProtection(cfg, space.Key, "update"); err != nil {
+		return err
+	}
+
+	page, err := client.GetPage(ctx, pageID)
+	if err != nil {
+		return fmt.Errorf("getting page: %w", err)
+	}
+	if page.SpaceID != space.ID {
+		return fmt.Errorf("page %s is not in space %s", pageID, space.Key)
+	}
+
+	updated, err := client.UpdateSpace(ctx, space.Key, &api.SpaceUpdateRequest{HomepageID: &pageID})
+	if err != nil {
+		return fmt.Errorf("updating space: %w", err)
+	}
+
+	if outputJSON {
+		return printJSON(updated)
+	}
+	fmt.Printf("Space %s homepage set to page %s (%s)\n", space.Key, page.ID, page.Title)
+	return nil
+}
+
 func init() {
 	spaceViewCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	spaceViewCmd.Flags().StringVar(&outputTemplate, "template", "", templateUsage)
 	spaceListCmd.Flags().IntVarP(&spaceLimit, "limit", "l", 25, "Maximum number of spaces to list")
+	spaceListCmd.Flags().StringVar(&spaceCursor, "cursor", "", "Resume from the cursor printed by a previous list")
 	spaceListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	spaceListCmd.Flags().StringVar(&outputTemplate, "template", "", templateUsage)
+	spaceListCmd.Flags().BoolVar(&outputJSONL, "jsonl", false, jsonlUsage)
+	spaceListCmd.Flags().BoolVar(&outputIDs, "ids", false, idsUsage)
+	spaceListCmd.Flags().BoolVar(&outputURLs, "urls", false, urlsUsage)
+
+	spaceUpdateCmd.Flags().StringVar(&spaceName, "name", "", "New space name")
+	spaceUpdateCmd.Flags().StringVar(&spaceDescription, "description", "", "New space description (plain text)")
+	spaceUpdateCmd.Flags().StringVar(&spaceHomepage, "homepage", "", "Page ID to use as the space homepage")
+	spaceUpdateCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	spaceUpdateCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+
+	spaceHomepageCmd.Flags().StringVar(&spaceSetHome, "set", "", "Page ID to make the space homepage")
+	spaceHomepageCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	spaceHomepageCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+
+	for _, cmd := range []*cobra.Command{spaceViewCmd, spaceUpdateCmd, spaceHomepageCmd} {
+		cmd.ValidArgsFunction = completeSpaceKeyArg
+	}
 
 	spaceCmd.AddCommand(spaceViewCmd)
 	spaceCmd.AddCommand(spaceListCmd)
+	spaceCmd.AddCommand(spaceUpdateCmd)
+	spaceCmd.AddCommand(spaceHomepageCmd)
+}
+
+// isNumericID reports whether s looks like a numeric Confluence ID rather than a space key.
+func isNumericID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
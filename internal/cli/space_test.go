@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestIsNumericID(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"98765", true},
+		{"0", true},
+		{"", false},
+		{"DOCS", false},
+		{"~jsmith", false},
+		{"12a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := isNumericID(tt.input); got != tt.want {
+				t.Errorf("isNumericID(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpaceListCmd_JSONCursor(t *testing.T) {
+	outputJSON = true
+	spaceLimit = 1
+	t.Cleanup(func() {
+		outputJSON = false
+		spaceLimit = 25
+		spaceCursor = ""
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		resp := api.SpaceListResponse{Results: []api.Space{{ID: "1", Key: "ONE"}}}
+		if r.URL.Query().Get("cursor") == "" {
+			resp.Links.Next = "/wiki/api/v2/spaces?cursor=abc&limit=1"
+		} else {
+			resp.Results = []api.Space{{ID: "2", Key: "TWO"}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	var got struct {
+		Results    []api.Space `json:"results"`
+		NextCursor string      `json:"next_cursor"`
+	}
+
+	finish := captureStdStreams(t)
+	runErr := spaceListCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\nstdout = %q", err, stdout)
+	}
+	if len(got.Results) != 1 || got.Results[0].Key != "ONE" || got.NextCursor == "" {
+		t.Fatalf("first batch = %+v, want ONE with a next_cursor", got)
+	}
+
+	spaceCursor = got.NextCursor
+	got.NextCursor = ""
+	finish = captureStdStreams(t)
+	runErr = spaceListCmd.RunE(testCommand(), nil)
+	stdout, _ = finish()
+	if runErr != nil {
+		t.Fatalf("RunE resume returned error: %v", runErr)
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\nstdout = %q", err, stdout)
+	}
+	if len(got.Results) != 1 || got.Results[0].Key != "TWO" || got.NextCursor != "" {
+		t.Errorf("second batch = %+v, want TWO with no next_cursor", got)
+	}
+}
+
+// homepageHandler serves GetSpace, GetPage, and UpdateSpace, recording the
+// homepage ID sent in the update body.
+func homepageHandler(t *testing.T, gotHomepage *string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/wiki/rest/api/space/DOCS":
+			var body struct {
+				Homepage struct {
+					ID string `json:"id"`
+				} `json:"homepage"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode update body: %v", err)
+			}
+			*gotHomepage = body.Homepage.ID
+			_, _ = w.Write([]byte(`{"id": 98765, "key": "DOCS", "name": "Docs", "type": "global"}`))
+		case r.URL.Path == "/wiki/api/v2/spaces":
+			_ = json.NewEncoder(w).Encode(api.SpaceListResponse{
+				Results: []api.Space{{ID: "98765", Key: "DOCS", HomepageID: "100"}},
+			})
+		case strings.HasPrefix(r.URL.Path, "/wiki/api/v2/pages/"):
+			id := strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/")
+			spaceID := "98765"
+			if id == "999" {
+				spaceID = "other"
+			}
+			_ = json.NewEncoder(w).Encode(api.Page{
+				ID:      id,
+				SpaceID: spaceID,
+				Title:   "Page " + id,
+				Body:    &api.PageBodyGet{Storage: &api.BodyContent{Value: "<h1>Welcome</h1>", Representation: "storage"}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func resetSpaceHomepageFlags(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		spaceSetHome = ""
+		outputJSON = false
+		overrideProtection = false
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestSpaceHomepageCmd_View(t *testing.T) {
+	resetSpaceHomepageFlags(t)
+
+	var gotHomepage string
+	server := httptest.NewServer(homepageHandler(t, &gotHomepage))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := spaceHomepageCmd.RunE(testCommand(), []string{"DOCS"})
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	if !strings.Contains(stdout, "# Welcome") {
+		t.Errorf("stdout = %q, want homepage markdown", stdout)
+	}
+}
+
+func TestSpaceHomepageCmd_Set(t *testing.T) {
+	tests := []struct {
+		name        string
+		pageID      string
+		protections []config.Protection
+		wantErr     string
+		wantUpdate  string
+	}{
+		{name: "page in space", pageID: "200", wantUpdate: "200"},
+		{name: "page in another space", pageID: "999", wantErr: "is not in space DOCS"},
+		{name: "protected space", pageID: "200", protections: []config.Protection{{Rule: "DOCS", SpaceKey: "DOCS"}}, wantErr: "protected by rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSpaceHomepageFlags(t)
+
+			var gotHomepage string
+			server := httptest.NewServer(homepageHandler(t, &gotHomepage))
+			defer server.Close()
+
+			client, err := api.NewClient(server.URL, "e@x", "t")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			withMockClient(t, client, &config.Config{BaseURL: server.URL, Protections: tt.protections})
+
+			cmd := testCommand()
+			cmd.Flags().StringVar(&spaceSetHome, "set", "", "")
+			if err := cmd.Flags().Set("set", tt.pageID); err != nil {
+				t.Fatalf("set flag: %v", err)
+			}
+
+			finish := captureStdStreams(t)
+			runErr := spaceHomepageCmd.RunE(cmd, []string{"DOCS"})
+			stdout, _ := finish()
+
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Fatalf("RunE error = %v, want containing %q", runErr, tt.wantErr)
+				}
+				if gotHomepage != "" {
+					t.Errorf("space was updated despite error (homepage %q)", gotHomepage)
+				}
+				return
+			}
+			if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if gotHomepage != tt.wantUpdate {
+				t.Errorf("updated homepage = %q, want %q", gotHomepage, tt.wantUpdate)
+			}
+			if !strings.Contains(stdout, "homepage set to page 200") {
+				t.Errorf("stdout = %q, want confirmation", stdout)
+			}
+		})
+	}
+}
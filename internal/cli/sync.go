@@ -0,0 +1,675 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+// syncStateFile is where sync keeps the state of a directory's last sync
+const syncStateFile = ".acon-sync.json"
+
+// Sync actions, as reported for each page
+const (
+	syncPulled   = "pulled"
+	syncPushed   = "pushed"
+	syncCreated  = "created"
+	syncRemoved  = "removed"
+	syncConflict = "conflict"
+	syncSkipped  = "skipped"
+)
+
+var (
+	syncRoot    string
+	syncPullOne bool
+	syncPushOne bool
+	syncResolve string
+)
+
+// syncState is what sync remembers of a directory: the page tree it is
+// synced with, and each page's file with the version and content both
+// sides had when they last matched
+type syncState struct {
+	Root  string      `json:"root"`
+	Space string      `json:"space"`
+	Pages []localPage `json:"pages"`
+}
+
+// syncChange is one page sync acted on, or could not
+type syncChange struct {
+	Action string `json:"action"`
+	ID     string `json:"id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Path   string `json:"path"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// localFile is a markdown file in a synced directory that the state does
+// not list yet
+type localFile struct {
+	path    string // slash-separated, relative to the directory
+	id      string // page ID in its frontmatter, if exported
+	version int    // page version in its frontmatter
+}
+
+// syncer syncs one directory with its page tree
+type syncer struct {
+	pageExporter
+	cfg     *config.Config
+	dir     string
+	spaceID string
+	conv    *converter.Converter
+	state   *syncState
+	entries map[string]*localPage // by page ID
+	taken   map[string]map[string]bool
+	changes []syncChange
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync DIR",
+	Short: "Sync a directory of markdown files with a page tree",
+	Long: `Sync a directory of markdown files with a Confluence page and its
+descendants, in the layout page export --recursive writes. Start syncing with
+--root PAGE_ID; the directory then remembers the page tree, and the version
+and content hash each file was last synced at, in .acon-sync.json.
+
+Each run pulls pages changed on Confluence into their files, pushes files
+changed locally to their pages, pulls new pages, and creates pages for new
+files in the directory of an already synced page. A page changed on both
+sides is a conflict: it is left alone unless --resolve keeps one side.
+Pages deleted on Confluence have their unchanged files removed, but files
+deleted locally never delete pages. Changed files whose pages are protected
+in the config file are skipped unless --override-protection is given.
+
+Pages are pulled in round-trip mode, so macros survive being pushed back,
+with their images in attachments/<name>/. Files that already hold a page's
+ID in their frontmatter, such as those from page export, are adopted on the
+first sync instead of being pulled again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("root", &syncRoot); err != nil {
+			return err
+		}
+		if syncPullOne && syncPushOne {
+			return fmt.Errorf("--pull and --push cannot be used together")
+		}
+		if syncResolve != "" && syncResolve != "local" && syncResolve != "remote" {
+			return fmt.Errorf("invalid --resolve %q (valid: local, remote)", syncResolve)
+		}
+
+		dir := args[0]
+		state, err := readSyncState(dir)
+		if err != nil {
+			return err
+		}
+		switch {
+		case state == nil && syncRoot == "":
+			return fmt.Errorf("%s is not synced yet: use --root PAGE_ID to choose the page tree to sync with", dir)
+		case state == nil:
+			state = &syncState{Root: syncRoot}
+		case syncRoot != "" && syncRoot != state.Root:
+			return fmt.Errorf("%s is synced with page %s, not %s", dir, state.Root, syncRoot)
+		}
+
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+		root, err := client.GetPage(cmd.Context(), state.Root)
+		if err != nil {
+			return fmt.Errorf("getting root page: %w", err)
+		}
+		space, err := client.GetSpaceByID(cmd.Context(), root.SpaceID)
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+		state.Space = space.Key
+		if !dryRun {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("creating sync directory: %w", err)
+			}
+		}
+
+		storage := storageOptions()
+		storage.RoundTrip = true
+		s := &syncer{
+			pageExporter: pageExporter{ctx: cmd.Context(), client: client, baseURL: cfg.BaseURL, spaceKey: space.Key, storage: storage},
+			cfg:          cfg,
+			dir:          dir,
+			spaceID:      space.ID,
+			conv:         converter.NewConverter(converterOptions(cfg, space.Key)),
+			state:        state,
+			entries:      map[string]*localPage{},
+			taken:        map[string]map[string]bool{},
+		}
+		for i := range state.Pages {
+			s.entries[state.Pages[i].ID] = &state.Pages[i]
+		}
+
+		runErr := s.run(root)
+		if err := s.save(); err != nil && runErr == nil {
+			runErr = err
+		}
+
+		if outputJSON {
+			if err := printJSON(s.changes); err != nil {
+				return err
+			}
+		} else {
+			for _, c := range s.changes {
+				line := fmt.Sprintf("%-8s %s", c.Action, c.Path)
+				if c.Reason != "" {
+					line += " (" + c.Reason + ")"
+				}
+				fmt.Println(line)
+			}
+		}
+		if dryRun {
+			fmt.Fprintln(os.Stderr, "Dry run: no files or pages were changed")
+		}
+		if runErr != nil {
+			return runErr
+		}
+		conflicts := 0
+		for _, c := range s.changes {
+			if c.Action == syncConflict {
+				conflicts++
+			}
+		}
+		if conflicts > 0 {
+			return fmt.Errorf("%d %s changed on both sides: edit the files, or rerun with --resolve local or --resolve remote", conflicts, pluralPages(conflicts))
+		}
+		return nil
+	},
+}
+
+// pluralPages returns "page" or "pages" to follow n
+func pluralPages(n int) string {
+	if n == 1 {
+		return "page"
+	}
+	return "pages"
+}
+
+// run syncs the page tree under root with the directory
+func (s *syncer) run(root *api.Page) error {
+	remote, err := s.pageTree(root)
+	if err != nil {
+		return err
+	}
+	untracked, err := s.untrackedFiles()
+	if err != nil {
+		return err
+	}
+	adopt := map[string]localFile{}
+	for _, f := range untracked {
+		if f.id != "" {
+			adopt[f.id] = f
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, page := range remote {
+		seen[page.ID] = true
+		entry := s.entries[page.ID]
+		if entry == nil {
+			if f, ok := adopt[page.ID]; ok {
+				entry = s.track(localPage{ID: page.ID, Title: page.Title, ParentID: page.ParentID, Version: f.version, Path: f.path})
+				data, err := os.ReadFile(s.file(f.path))
+				if err != nil {
+					return fmt.Errorf("reading %s: %w", f.path, err)
+				}
+				entry.Hash = fileHash(data)
+				delete(adopt, page.ID)
+			}
+		}
+		if entry == nil {
+			if err := s.pullNew(page); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.syncPage(page, entry); err != nil {
+			return err
+		}
+	}
+
+	// Pages no longer in the tree were deleted or moved out of it
+	for _, entry := range append([]localPage(nil), s.state.Pages...) {
+		if seen[entry.ID] {
+			continue
+		}
+		if err := s.syncGone(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range untracked {
+		if f.id != "" {
+			if entry := s.entries[f.id]; entry != nil && entry.Path != f.path {
+				s.note(syncSkipped, f.path, f.id, "", "page "+f.id+" is synced with "+entry.Path)
+			} else if _, ok := adopt[f.id]; ok {
+				s.note(syncSkipped, f.path, f.id, "", "page "+f.id+" is not in the synced page tree")
+			}
+			continue
+		}
+		if err := s.pushNew(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageTree returns root and its descendants, each before its children,
+// with their bodies
+func (s *syncer) pageTree(root *api.Page) ([]*api.Page, error) {
+	pages := []*api.Page{root}
+	for i := 0; i < len(pages); i++ {
+		children, more, err := s.client.GetChildPages(s.ctx, pages[i].ID, maxExportChildren, "child-position")
+		if err != nil {
+			return nil, fmt.Errorf("listing children of page %s: %w", pages[i].ID, err)
+		}
+		if more {
+			fmt.Fprintf(os.Stderr, "Warning: page %s has more than %d children; only the first %d are synced\n", pages[i].ID, maxExportChildren, maxExportChildren)
+		}
+		for _, child := range children {
+			// Child listings carry no body or version, so each page is
+			// fetched in full
+			full, err := s.client.GetPage(s.ctx, child.ID)
+			if err != nil {
+				return nil, fmt.Errorf("getting page %s: %w", child.ID, err)
+			}
+			pages = append(pages, full)
+		}
+	}
+	return pages, nil
+}
+
+// untrackedFiles returns the markdown files in the directory that the
+// state does not list, parents before children. Hidden files and
+// directories are skipped.
+func (s *syncer) untrackedFiles() ([]localFile, error) {
+	tracked := map[string]bool{}
+	for _, entry := range s.state.Pages {
+		tracked[entry.Path] = true
+	}
+	var files []localFile
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != s.dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if d.IsDir() || (ext != ".md" && ext != ".markdown") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if tracked[rel] {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		f := localFile{path: rel}
+		if fm, _, err := converter.SplitFrontmatter(data); err == nil {
+			f.id, f.version = fm.ID, fm.Version
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading sync directory: %w", err)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i].path, "/") < strings.Count(files[j].path, "/")
+	})
+	return files, nil
+}
+
+// syncPage brings a synced page and its file into step
+func (s *syncer) syncPage(page *api.Page, entry *localPage) error {
+	data, err := os.ReadFile(s.file(entry.Path))
+	if errors.Is(err, os.ErrNotExist) {
+		s.note(syncSkipped, entry.Path, page.ID, page.Title, "deleted locally; delete the page with acon page delete")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", entry.Path, err)
+	}
+	localChanged := fileHash(data) != entry.Hash
+	remoteChanged := page.Version == nil || page.Version.Number != entry.Version
+
+	switch {
+	case localChanged && remoteChanged && syncResolve == "":
+		s.note(syncConflict, entry.Path, page.ID, page.Title, "changed on both sides")
+		return nil
+	case localChanged && (!remoteChanged || syncResolve == "local"):
+		if syncPullOne {
+			return nil
+		}
+		return s.push(page, entry)
+	case remoteChanged:
+		if syncPushOne {
+			return nil
+		}
+		return s.pull(page, entry)
+	}
+	return nil
+}
+
+// syncGone handles a synced page that is no longer in the page tree,
+// removing its file unless it changed locally
+func (s *syncer) syncGone(entry localPage) error {
+	if syncPushOne {
+		return nil
+	}
+	data, err := os.ReadFile(s.file(entry.Path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", entry.Path, err)
+	}
+	if err == nil && fileHash(data) != entry.Hash && syncResolve != "remote" {
+		s.note(syncConflict, entry.Path, entry.ID, entry.Title, "deleted or moved on Confluence but changed locally")
+		return nil
+	}
+	if err == nil && !dryRun {
+		if err := os.Remove(s.file(entry.Path)); err != nil {
+			return fmt.Errorf("removing %s: %w", entry.Path, err)
+		}
+	}
+	s.untrack(entry.ID)
+	s.note(syncRemoved, entry.Path, entry.ID, entry.Title, "")
+	return s.save()
+}
+
+// pull writes a page over its file
+func (s *syncer) pull(page *api.Page, entry *localPage) error {
+	if dryRun {
+		s.note(syncPulled, entry.Path, page.ID, page.Title, "")
+		return nil
+	}
+	if err := s.write(page, entry.Path); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(s.file(entry.Path))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", entry.Path, err)
+	}
+	entry.Title, entry.ParentID, entry.Hash = page.Title, page.ParentID, fileHash(data)
+	if page.Version != nil {
+		entry.Version = page.Version.Number
+	}
+	s.note(syncPulled, entry.Path, page.ID, page.Title, "")
+	return s.save()
+}
+
+// pullNew writes a page that has no file yet, beside its parent's file
+func (s *syncer) pullNew(page *api.Page) error {
+	if syncPushOne {
+		return nil
+	}
+	dir := ""
+	if page.ID != s.state.Root {
+		parent := s.entries[page.ParentID]
+		if parent == nil {
+			// The parent was skipped, so its children have nowhere to go
+			return nil
+		}
+		dir = strings.TrimSuffix(parent.Path, path.Ext(parent.Path))
+	}
+	taken, err := s.takenNames(dir)
+	if err != nil {
+		return err
+	}
+	entry := s.track(localPage{ID: page.ID, Path: path.Join(dir, exportName(page, taken)+".md")})
+	return s.pull(page, entry)
+}
+
+// push updates a page from its file
+func (s *syncer) push(page *api.Page, entry *localPage) error {
+	if err := checkPageProtection(s.ctx, s.client, s.cfg, page.ID, page.SpaceID, "update"); err != nil {
+		// One protected page leaves the rest of the tree to sync
+		var protected *protectedError
+		if errors.As(err, &protected) {
+			s.note(syncSkipped, entry.Path, page.ID, page.Title, fmt.Sprintf("protected by rule %q; use --override-protection to push it", protected.rule))
+			return nil
+		}
+		return err
+	}
+	meta, content, data, err := s.readFile(entry.Path)
+	if err != nil {
+		return err
+	}
+	// Sync never moves pages; the tree on Confluence decides where they are
+	meta.parent = ""
+	result, err := updatePage(s.ctx, s.client, s.conv, s.document(entry.Path), page, meta, content, s.file(entry.Path), "")
+	if err != nil {
+		return fmt.Errorf("%s: %w", entry.Path, err)
+	}
+	entry.Title, entry.Hash = result.Title, fileHash(data)
+	if result.Version != nil {
+		entry.Version = result.Version.Number
+	} else if page.Version != nil {
+		entry.Version = page.Version.Number + 1
+	}
+	s.note(syncPushed, entry.Path, page.ID, result.Title, "")
+	return s.save()
+}
+
+// pushNew creates a page for a new file, under the page whose file is
+// named like the file's directory
+func (s *syncer) pushNew(f localFile) error {
+	if syncPullOne {
+		return nil
+	}
+	dir := path.Dir(f.path)
+	var parent *localPage
+	for i := range s.state.Pages {
+		p := s.state.Pages[i].Path
+		if dir != "." && strings.TrimSuffix(p, path.Ext(p)) == dir {
+			parent = &s.state.Pages[i]
+			break
+		}
+	}
+	if parent == nil {
+		s.note(syncSkipped, f.path, "", "", "not in the directory of a synced page")
+		return nil
+	}
+
+	meta, content, data, err := s.readFile(f.path)
+	if err != nil {
+		return err
+	}
+	if meta.title == "" {
+		meta.title = strings.TrimSuffix(path.Base(f.path), path.Ext(f.path))
+	}
+	meta.parent = parent.ID
+	page, err := createPage(s.ctx, s.client, s.conv, s.document(f.path), s.spaceID, meta, content, s.file(f.path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.path, err)
+	}
+	entry := localPage{ID: page.ID, Title: page.Title, ParentID: parent.ID, Version: 1, Path: f.path, Hash: fileHash(data)}
+	if page.Version != nil {
+		entry.Version = page.Version.Number
+	}
+	s.track(entry)
+	s.note(syncCreated, f.path, page.ID, page.Title, "")
+	return s.save()
+}
+
+// readFile reads a synced file, returning its page settings and body, and
+// the whole file for hashing
+func (s *syncer) readFile(p string) (pageMeta, []byte, []byte, error) {
+	data, err := readAndValidateContent(s.file(p))
+	if err != nil {
+		return pageMeta{}, nil, nil, fmt.Errorf("%s: %w", p, err)
+	}
+	raw, err := os.ReadFile(s.file(p))
+	if err != nil {
+		return pageMeta{}, nil, nil, fmt.Errorf("reading %s: %w", p, err)
+	}
+	meta, content, err := readPageMeta(data)
+	if err != nil {
+		return pageMeta{}, nil, nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return meta, content, raw, nil
+}
+
+// write writes page to the file p, with its images in attachments/<name>/
+func (s *syncer) write(page *api.Page, p string) error {
+	full := s.file(p)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", p, err)
+	}
+	attachmentDir := converter.AttachmentDir + "/" + strings.TrimSuffix(path.Base(p), path.Ext(p))
+	_, _, err := s.writePage(page, full, attachmentDir)
+	return err
+}
+
+// document returns the conversion settings for pushing the file p, with
+// links to other synced files rewritten as links to their pages
+func (s *syncer) document(p string) converter.Document {
+	doc := converter.Document{PageLinks: map[string]converter.PageRef{}, LinkBase: path.Dir(p)}
+	for _, entry := range s.state.Pages {
+		doc.PageLinks[entry.Path] = converter.PageRef{Title: entry.Title}
+	}
+	return doc
+}
+
+// takenNames returns the file names, without extension, in use in the
+// directory dir, which new files must not take
+func (s *syncer) takenNames(dir string) (map[string]bool, error) {
+	if taken, ok := s.taken[dir]; ok {
+		return taken, nil
+	}
+	taken := map[string]bool{converter.AttachmentDir: true}
+	entries, err := os.ReadDir(s.file(dir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+	for _, e := range entries {
+		taken[strings.TrimSuffix(e.Name(), path.Ext(e.Name()))] = true
+	}
+	s.taken[dir] = taken
+	return taken, nil
+}
+
+// track adds entry to the state, returning the stored entry
+func (s *syncer) track(entry localPage) *localPage {
+	s.state.Pages = append(s.state.Pages, entry)
+	s.entries = map[string]*localPage{}
+	for i := range s.state.Pages {
+		s.entries[s.state.Pages[i].ID] = &s.state.Pages[i]
+	}
+	return s.entries[entry.ID]
+}
+
+// untrack removes the page with ID id from the state
+func (s *syncer) untrack(id string) {
+	pages := s.state.Pages[:0]
+	for _, entry := range s.state.Pages {
+		if entry.ID != id {
+			pages = append(pages, entry)
+		}
+	}
+	s.state.Pages = pages
+	s.entries = map[string]*localPage{}
+	for i := range s.state.Pages {
+		s.entries[s.state.Pages[i].ID] = &s.state.Pages[i]
+	}
+}
+
+// note records a change to report
+func (s *syncer) note(action, p, id, title, reason string) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "[Sync] %s %s\n", action, p)
+	}
+	s.changes = append(s.changes, syncChange{Action: action, ID: id, Title: title, Path: p, Reason: reason})
+}
+
+// file returns the path on disk of the slash-separated path p
+func (s *syncer) file(p string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(p))
+}
+
+// save writes the sync state, through a temporary file so an interrupted
+// sync leaves the last complete state. A dry run leaves it as it was.
+func (s *syncer) save() error {
+	if dryRun {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding sync state: %w", err)
+	}
+	p := filepath.Join(s.dir, syncStateFile)
+	if err := os.WriteFile(p+".tmp", append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing sync state: %w", err)
+	}
+	if err := os.Rename(p+".tmp", p); err != nil {
+		return fmt.Errorf("writing sync state: %w", err)
+	}
+	return nil
+}
+
+// readSyncState returns the sync state of dir, or nil if it has never been
+// synced
+func readSyncState(dir string) (*syncState, error) {
+	p := filepath.Join(dir, syncStateFile)
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading sync state: %w", err)
+	}
+	var state syncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing sync state %s: %w", p, err)
+	}
+	if state.Root == "" {
+		return nil, fmt.Errorf("sync state %s names no root page", p)
+	}
+	return &state, nil
+}
+
+// fileHash returns the SHA-256 of a file's content in hex
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncRoot, "root", "", "Page ID of the page tree to sync with (needed on the first sync)")
+	syncCmd.Flags().BoolVar(&syncPullOne, "pull", false, "Only pull changes from Confluence")
+	syncCmd.Flags().BoolVar(&syncPushOne, "push", false, "Only push local changes to Confluence")
+	syncCmd.Flags().StringVar(&syncResolve, "resolve", "", "Settle pages changed on both sides by keeping one side: local, remote")
+	syncCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	syncCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+
+	syncCmd.GroupID = "core"
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+// fakeTree is a page tree on a fake Confluence server that pages can be
+// read, updated, and created in
+type fakeTree struct {
+	mu     sync.Mutex
+	pages  map[string]*api.Page
+	order  []string
+	nextID int
+	puts   []string
+}
+
+func newFakeTree(pages ...api.Page) *fakeTree {
+	f := &fakeTree{pages: map[string]*api.Page{}, nextID: 100}
+	for i := range pages {
+		f.pages[pages[i].ID] = &pages[i]
+		f.order = append(f.order, pages[i].ID)
+	}
+	return f
+}
+
+// edit changes a page's body as a new version
+func (f *fakeTree) edit(id, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.pages[id]
+	p.Body.Storage.Value = body
+	p.Version.Number++
+}
+
+func (f *fakeTree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	path := strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/")
+	switch {
+	case path == "spaces/space-1":
+		_ = json.NewEncoder(w).Encode(api.Space{ID: "space-1", Key: "DOCS"})
+	case strings.HasSuffix(path, "/labels"):
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	case strings.HasSuffix(path, "/children"):
+		parent := strings.TrimSuffix(strings.TrimPrefix(path, "pages/"), "/children")
+		var list api.PageListResponse
+		for _, id := range f.order {
+			if f.pages[id].ParentID == parent {
+				list.Results = append(list.Results, api.Page{ID: id, Title: f.pages[id].Title})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodPost && path == "pages":
+		var req api.PageCreateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.pages[id] = &api.Page{ID: id, SpaceID: req.SpaceID, Title: req.Title, ParentID: req.ParentID, Version: &api.Version{Number: 1},
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Value: req.Body.Value}}}
+		f.order = append(f.order, id)
+		_ = json.NewEncoder(w).Encode(f.pages[id])
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "pages/"):
+		var req api.PageUpdateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		p := f.pages[strings.TrimPrefix(path, "pages/")]
+		p.Title, p.Body.Storage.Value, p.Version.Number = req.Title, req.Body.Value, req.Version.Number
+		f.puts = append(f.puts, p.ID)
+		_ = json.NewEncoder(w).Encode(p)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "pages/"):
+		p, ok := f.pages[strings.TrimPrefix(path, "pages/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(p)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSyncCmd(t *testing.T) {
+	resetPageFlags(t)
+	reset := func() {
+		syncRoot, syncResolve = "", ""
+		syncPullOne, syncPushOne = false, false
+	}
+	reset()
+	t.Cleanup(reset)
+
+	tree := newFakeTree(
+		api.Page{ID: "10", SpaceID: "space-1", Title: "Home", Version: &api.Version{Number: 1},
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Value: "<p>Hello</p>"}}},
+		api.Page{ID: "20", SpaceID: "space-1", Title: "Guide", ParentID: "10", Version: &api.Version{Number: 1},
+			Body: &api.PageBodyGet{Storage: &api.BodyContent{Value: "<p>Read me</p>"}}},
+	)
+	server := httptest.NewServer(tree)
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	cfg := &config.Config{BaseURL: server.URL}
+	withMockClient(t, client, cfg)
+
+	dir := t.TempDir()
+	runSync := func() (string, error) {
+		t.Helper()
+		finish := captureStdStreams(t)
+		runErr := syncCmd.RunE(testCommand(), []string{dir})
+		stdout, _ := finish()
+		return stdout, runErr
+	}
+	guide := filepath.Join(dir, "home", "guide.md")
+
+	if _, err := runSync(); err == nil || !strings.Contains(err.Error(), "--root") {
+		t.Fatalf("first sync without --root error = %v, want --root hint", err)
+	}
+
+	syncRoot = "10"
+	stdout, err := runSync()
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if stdout != "pulled   home.md\npulled   home/guide.md\n" {
+		t.Errorf("first sync output = %q", stdout)
+	}
+	data, err := os.ReadFile(guide)
+	if err != nil || !strings.Contains(string(data), "id: \"20\"") || !strings.Contains(string(data), "Read me") {
+		t.Fatalf("guide = %q, %v, want pulled page", data, err)
+	}
+	syncRoot = ""
+
+	if stdout, err := runSync(); err != nil || stdout != "" {
+		t.Errorf("unchanged sync = %q, %v, want no changes", stdout, err)
+	}
+
+	// A local edit is pushed, and a remote edit pulled
+	if err := os.WriteFile(guide, append(data, []byte("\nMore.\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree.edit("10", "<p>Hello again</p>")
+
+	// A dry run reports the same changes without making them
+	dryRun = true
+	stdout, err = runSync()
+	dryRun = false
+	if err != nil {
+		t.Fatalf("dry run sync: %v", err)
+	}
+	if stdout != "pulled   home.md\npushed   home/guide.md\n" {
+		t.Errorf("dry run output = %q", stdout)
+	}
+	if home, _ := os.ReadFile(filepath.Join(dir, "home.md")); len(tree.puts) != 0 || strings.Contains(string(home), "Hello again") {
+		t.Errorf("dry run puts = %v, home = %q, want nothing changed", tree.puts, home)
+	}
+
+	stdout, err = runSync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if stdout != "pulled   home.md\npushed   home/guide.md\n" {
+		t.Errorf("sync output = %q", stdout)
+	}
+	if len(tree.puts) != 1 || tree.puts[0] != "20" || !strings.Contains(tree.pages["20"].Body.Storage.Value, "More.") {
+		t.Errorf("puts = %v, guide body = %q, want guide pushed", tree.puts, tree.pages["20"].Body.Storage.Value)
+	}
+	if home, _ := os.ReadFile(filepath.Join(dir, "home.md")); !strings.Contains(string(home), "Hello again") {
+		t.Errorf("home = %q, want remote edit", home)
+	}
+	if stdout, err := runSync(); err != nil || stdout != "" {
+		t.Errorf("sync after push = %q, %v, want no changes", stdout, err)
+	}
+
+	// Both sides changed is a conflict until resolved
+	if err := os.WriteFile(guide, []byte("---\ntitle: Guide\n---\nLocal\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree.edit("20", "<p>Remote</p>")
+	stdout, err = runSync()
+	if err == nil || !strings.Contains(err.Error(), "1 page changed on both sides") {
+		t.Errorf("conflict error = %v", err)
+	}
+	if stdout != "conflict home/guide.md (changed on both sides)\n" {
+		t.Errorf("conflict output = %q", stdout)
+	}
+	syncResolve = "remote"
+	if stdout, err := runSync(); err != nil || stdout != "pulled   home/guide.md\n" {
+		t.Errorf("resolved sync = %q, %v", stdout, err)
+	}
+	syncResolve = ""
+
+	// A new file beside a synced page's directory becomes its child
+	if err := os.WriteFile(filepath.Join(dir, "home", "faq.md"), []byte("# FAQ\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stray.md"), []byte("Stray\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err = runSync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if stdout != "skipped  stray.md (not in the directory of a synced page)\ncreated  home/faq.md\n" {
+		t.Errorf("create output = %q", stdout)
+	}
+	if p := tree.pages["101"]; p == nil || p.Title != "faq" || p.ParentID != "10" {
+		t.Errorf("created page = %+v, want faq under home", p)
+	}
+
+	state, err := readSyncState(dir)
+	if err != nil || state.Root != "10" || state.Space != "DOCS" || len(state.Pages) != 3 {
+		t.Errorf("state = %+v, %v, want three pages under 10", state, err)
+	}
+
+	// A protected page is skipped, leaving the rest to sync
+	rule, err := config.ParseProtection("pageID:20")
+	if err != nil {
+		t.Fatalf("ParseProtection: %v", err)
+	}
+	cfg.Protections = []config.Protection{rule}
+	if err := os.WriteFile(guide, []byte("---\ntitle: Guide\n---\nProtected edit\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	puts := len(tree.puts)
+	stdout, err = runSync()
+	if err != nil {
+		t.Fatalf("sync with a protected page: %v", err)
+	}
+	if stdout != "skipped  home/guide.md (protected by rule \"pageID:20\"; use --override-protection to push it)\nskipped  stray.md (not in the directory of a synced page)\n" || len(tree.puts) != puts {
+		t.Errorf("protected sync output = %q, puts = %v", stdout, tree.puts[puts:])
+	}
+	overrideProtection = true
+	if stdout, err := runSync(); err != nil || stdout != "pushed   home/guide.md\nskipped  stray.md (not in the directory of a synced page)\n" {
+		t.Errorf("sync with --override-protection = %q, %v", stdout, err)
+	}
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	taskStatus string
+	taskSpace  string
+	taskPage   string
+	taskLimit  int
+	taskCursor string
+	taskUndo   bool
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Manage inline tasks",
+	Long:  "List and complete inline tasks (action items) embedded in Confluence pages",
+}
+
+var taskListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tasks assigned to you",
+	Long:  "List inline tasks assigned to the authenticated user",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("page", &taskPage); err != nil {
+			return err
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		params := api.TaskListParams{PageID: taskPage}
+		switch taskStatus {
+		case "all":
+		case api.TaskStatusComplete, api.TaskStatusIncomplete:
+			params.Status = taskStatus
+		default:
+			return fmt.Errorf("invalid status '%s' (valid: incomplete, complete, all)", taskStatus)
+		}
+
+		user, err := client.GetCurrentUser(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("getting current user: %w", err)
+		}
+		params.AssignedTo = user.AccountID
+
+		spaceKeyCache := map[string]string{}
+		if taskSpace != "" {
+			space, err := client.GetSpace(cmd.Context(), taskSpace)
+			if err != nil {
+				return fmt.Errorf("getting space: %w", err)
+			}
+			params.SpaceID = space.ID
+			spaceKeyCache[space.ID] = space.Key
+		}
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Task List] Listing tasks for %s (status: %s, limit: %d)\n", user.AccountID, taskStatus, taskLimit)
+		}
+
+		tasks, next, err := client.ListTasksWithCursor(cmd.Context(), params, taskLimit, api.Cursor(taskCursor))
+		if err != nil {
+			return fmt.Errorf("listing tasks: %w", err)
+		}
+
+		if outputJSON {
+			return printJSON(newListOutput(tasks, next))
+		}
+
+		if err := printTaskList(cmd.Context(), client, os.Stdout, cfg.BaseURL, tasks, next != "", spaceKeyCache); err != nil {
+			return err
+		}
+		printNextCursor(os.Stdout, next)
+		return nil
+	},
+}
+
+var taskDoneCmd = &cobra.Command{
+	Use:   "done TASK_ID",
+	Short: "Mark a task complete",
+	Long:  "Mark an inline task complete, or incomplete again with --undo",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		taskID := args[0]
+		status := api.TaskStatusComplete
+		if taskUndo {
+			status = api.TaskStatusIncomplete
+		}
+
+		result, err := client.UpdateTaskStatus(cmd.Context(), taskID, status)
+		if err != nil {
+			return fmt.Errorf("updating task: %w", err)
+		}
+
+		if outputJSON {
+			return printJSON(result)
+		}
+		fmt.Printf("Task %s marked %s\n", result.ID, result.Status)
+		return nil
+	},
+}
+
+// taskText flattens a task's storage body into a single line of plain text.
+func taskText(task api.Task) string {
+	if task.Body == nil || task.Body.Storage == nil {
+		return ""
+	}
+	text := htmlTagRegex.ReplaceAllString(task.Body.Storage.Value, " ")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// printTaskList renders a human-readable task listing, resolving the space key
+// for each task's page URL through the supplied cache.
+func printTaskList(ctx context.Context, client *api.Client, out io.Writer, baseURL string, tasks []api.Task, hasMore bool, spaceKeyCache map[string]string) error {
+	for _, task := range tasks {
+		fmt.Fprintf(out, "ID: %s\n", task.ID)
+		fmt.Fprintf(out, "Status: %s\n", task.Status)
+		fmt.Fprintf(out, "Task: %s\n", taskText(task))
+		if task.DueAt != "" {
+			if due, err := time.Parse(time.RFC3339, task.DueAt); err == nil {
+				fmt.Fprintf(out, "Due: %s\n", due.Format("2006-01-02"))
+			} else {
+				fmt.Fprintf(out, "Due: %s\n", task.DueAt)
+			}
+		}
+
+		contentID := task.PageID
+		if contentID == "" {
+			contentID = task.BlogPostID
+		}
+		if contentID != "" {
+			key, ok := spaceKeyCache[task.SpaceID]
+			if !ok {
+				space, err := client.GetSpaceByID(ctx, task.SpaceID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not resolve space key for task %s: %v\n", task.ID, err)
+				} else {
+					key = space.Key
+				}
+				// Cache misses too so we do not repeat the lookup for every task in the same space.
+				spaceKeyCache[task.SpaceID] = key
+			}
+			if key == "" {
+				fmt.Fprintf(out, "Page: (unresolved, page ID: %s)\n", contentID)
+			} else {
+				fmt.Fprintf(out, "Page: %s\n", pageURL(baseURL, key, contentID))
+			}
+		}
+		fmt.Fprintln(out, "---")
+	}
+
+	resultWord := "tasks"
+	if len(tasks) == 1 {
+		resultWord = "task"
+	}
+	if hasMore {
+		fmt.Fprintf(out, "\nShowing %d %s (more available - increase --limit to see more)\n", len(tasks), resultWord)
+	} else {
+		fmt.Fprintf(out, "\nShowing all %d %s\n", len(tasks), resultWord)
+	}
+	return nil
+}
+
+func init() {
+	taskListCmd.Flags().StringVar(&taskStatus, "status", api.TaskStatusIncomplete, "Task status: incomplete, complete, all")
+	taskListCmd.Flags().StringVarP(&taskSpace, "space", "s", "", "Only list tasks in this space")
+	taskListCmd.Flags().StringVar(&taskPage, "page", "", "Only list tasks on this page ID")
+	taskListCmd.Flags().IntVarP(&taskLimit, "limit", "l", 25, "Maximum number of tasks to list")
+	taskListCmd.Flags().StringVar(&taskCursor, "cursor", "", "Resume from the cursor printed by a previous list")
+	taskListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	taskDoneCmd.Flags().BoolVar(&taskUndo, "undo", false, "Mark the task incomplete instead")
+	taskDoneCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	taskCmd.AddCommand(taskListCmd)
+	taskCmd.AddCommand(taskDoneCmd)
+
+	taskCmd.GroupID = "core"
+	rootCmd.AddCommand(taskCmd)
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func resetTaskFlags(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		taskStatus = api.TaskStatusIncomplete
+		taskSpace = ""
+		taskPage = ""
+		taskLimit = 25
+		taskCursor = ""
+		taskUndo = false
+		outputJSON = false
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestTaskText(t *testing.T) {
+	tests := []struct {
+		name string
+		task api.Task
+		want string
+	}{
+		{"nil body", api.Task{}, ""},
+		{
+			name: "strips markup and entities",
+			task: api.Task{Body: &api.PageBodyGet{Storage: &api.BodyContent{
+				Value: `Review <ac:link><ri:user ri:account-id="x" /></ac:link> &amp; <strong>ship</strong>`,
+			}}},
+			want: "Review & ship",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskText(tt.task); got != tt.want {
+				t.Errorf("taskText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintTaskList(t *testing.T) {
+	client, server := errClient(t)
+	defer server.Close()
+
+	tasks := []api.Task{
+		{ID: "1", Status: "incomplete", SpaceID: "space-1", PageID: "123", DueAt: "2026-10-20T00:00:00Z"},
+	}
+	var out bytes.Buffer
+	if err := printTaskList(context.Background(), client, &out, "https://x", tasks, false, map[string]string{"space-1": "DOCS"}); err != nil {
+		t.Fatalf("printTaskList: %v", err)
+	}
+	for _, want := range []string{"ID: 1", "Due: 2026-10-20", "Page: https://x/wiki/spaces/DOCS/pages/123", "Showing all 1 task"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestTaskListCmd_FiltersByCurrentUser(t *testing.T) {
+	resetTaskFlags(t)
+
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/rest/api/user/current":
+			_ = json.NewEncoder(w).Encode(api.User{AccountID: "acc-1"})
+		case "/wiki/api/v2/tasks":
+			gotQuery = r.URL.RawQuery
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	finish := captureStdStreams(t)
+	runErr := taskListCmd.RunE(testCommand(), nil)
+	stdout, _ := finish()
+
+	if runErr != nil {
+		t.Fatalf("RunE returned error: %v", runErr)
+	}
+	if !strings.Contains(gotQuery, "assigned-to=acc-1") || !strings.Contains(gotQuery, "status=incomplete") {
+		t.Errorf("query = %q, want assigned-to and status filters", gotQuery)
+	}
+	if !strings.Contains(stdout, "Showing all 0 tasks") {
+		t.Errorf("stdout = %q, want empty summary", stdout)
+	}
+}
+
+func TestTaskListCmd_InvalidStatus(t *testing.T) {
+	resetTaskFlags(t)
+	taskStatus = "done"
+
+	client, server := errClient(t)
+	defer server.Close()
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	err := taskListCmd.RunE(testCommand(), nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid status") {
+		t.Errorf("err = %v, want invalid status error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// outputTemplate is the --template that list and view results are written
+// with, one execution per result
+var outputTemplate string
+
+const templateUsage = `Format each result with a Go template, e.g. '{{.ID}}\t{{.Title}}'`
+
+// templateEscapes turns the escapes users type in shell quotes into the
+// characters they mean, so '{{.ID}}\t{{.Title}}' writes a tab
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`)
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+}
+
+// parseOutputTemplate parses --template, turning \t and \n into tabs and newlines
+func parseOutputTemplate() (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(templateEscapes.Replace(outputTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printTemplate writes each item with --template, ending each with a newline
+// unless the template already does
+func printTemplate[T any](out io.Writer, items []T) error {
+	tmpl, err := parseOutputTemplate()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, item); err != nil {
+			return fmt.Errorf("executing --template: %w", err)
+		}
+		line := b.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
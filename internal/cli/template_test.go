@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPrintTemplate(t *testing.T) {
+	pages := []api.Page{
+		{ID: "1", Title: "Home", Version: &api.Version{Number: 3}},
+		{ID: "2", Title: "Guide", Version: &api.Version{Number: 1}},
+	}
+	tests := []struct {
+		template string
+		want     string
+		wantErr  string
+	}{
+		{template: `{{.ID}}\t{{.Title}}`, want: "1\tHome\n2\tGuide\n"},
+		{template: "{{.Title}} v{{.Version.Number}}\n", want: "Home v3\nGuide v1\n"},
+		{template: `{{json .Title}}`, want: "\"Home\"\n\"Guide\"\n"},
+		{template: `{{.Nope}}`, wantErr: "executing --template"},
+		{template: `{{.ID`, wantErr: "invalid --template"},
+	}
+	for _, tt := range tests {
+		resetPageFlags(t)
+		outputTemplate = tt.template
+		var out bytes.Buffer
+		err := printTemplate(&out, pages)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("printTemplate(%q) error = %v, want %q", tt.template, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || out.String() != tt.want {
+			t.Errorf("printTemplate(%q) = %q, %v, want %q", tt.template, out.String(), err, tt.want)
+		}
+	}
+}
+
+func TestTemplateFlag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/api/v2/spaces":
+			_, _ = w.Write([]byte(`{"results":[{"id":"1","key":"OPS","name":"Operations"},{"id":"2","key":"DEV","name":"Development"}],
+				"_links":{"next":"/wiki/api/v2/spaces?cursor=abc"}}`))
+		case "/wiki/api/v2/pages/10":
+			_, _ = w.Write([]byte(`{"id":"10","title":"Home","version":{"number":4}}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	resetPageFlags(t)
+	spaceLimit = 2
+	t.Cleanup(func() { spaceLimit = 25 })
+	outputTemplate = `{{.Key}}: {{.Name}}`
+	finish := captureStdStreams(t)
+	runErr := spaceListCmd.RunE(testCommand(), nil)
+	stdout, stderr := finish()
+	if runErr != nil {
+		t.Fatalf("space list: %v", runErr)
+	}
+	if stdout != "OPS: Operations\nDEV: Development\n" || !strings.HasPrefix(stderr, "Next Cursor: ") {
+		t.Errorf("space list = %q, stderr %q", stdout, stderr)
+	}
+
+	outputTemplate = `{{.Title}} is at version {{.Version.Number}}`
+	finish = captureStdStreams(t)
+	runErr = pageViewCmd.RunE(testCommand(), []string{"10"})
+	stdout, _ = finish()
+	if runErr != nil || stdout != "Home is at version 4\n" {
+		t.Errorf("page view = %q, %v", stdout, runErr)
+	}
+
+	outputJSON = true
+	if err := pageViewCmd.RunE(testCommand(), []string{"10"}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("--template with --json error = %v", err)
+	}
+
+	outputJSON, outputTemplate, outputFormat = false, "", "csv"
+	if err := pageViewCmd.RunE(testCommand(), []string{"10"}); err == nil || !strings.Contains(err.Error(), "only for list and search results") {
+		t.Errorf("page view --output csv error = %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// maxTreeItems caps the content listed by one descendants listing, and the
+// top-level pages listed for a space
+const maxTreeItems = 1000
+
+var (
+	treeDepth int
+	treeURLs  bool
+)
+
+// treeNode is a page, or other content such as a folder, in a page tree
+type treeNode struct {
+	ID       string      `json:"id"`
+	Title    string      `json:"title"`
+	Type     string      `json:"type"`
+	URL      string      `json:"url,omitempty"`
+	Children []*treeNode `json:"children,omitempty"`
+
+	position int
+}
+
+var pageTreeCmd = &cobra.Command{
+	Use:   "tree [PAGE_ID]",
+	Short: "Show the page tree under a page or space",
+	Long: `Show the pages under a page, or under every top-level page of a space,
+as a tree with their IDs. Content other than pages, such as folders, is
+marked with its type.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if treeDepth < 0 {
+			return fmt.Errorf("--depth must be 0 (all levels) or more")
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		var roots []*treeNode
+		var spaceKey string
+		if len(args) == 1 {
+			pageID, err := pageIDArg(args[0])
+			if err != nil {
+				return err
+			}
+			page, err := client.GetPage(cmd.Context(), pageID)
+			if err != nil {
+				return fmt.Errorf("getting page: %w", err)
+			}
+			space, err := client.GetSpaceByID(cmd.Context(), page.SpaceID)
+			if err != nil {
+				return fmt.Errorf("getting space: %w", err)
+			}
+			spaceKey = space.Key
+			roots = []*treeNode{{ID: page.ID, Title: page.Title, Type: "page"}}
+		} else {
+			spaceKey = pageSpace
+			if spaceKey == "" {
+				spaceKey = cfg.SpaceKey
+			}
+			if spaceKey == "" {
+				return fmt.Errorf("page ID or space key required: use --space flag or set CONFLUENCE_SPACE_KEY")
+			}
+			space, err := client.GetSpace(cmd.Context(), spaceKey)
+			if err != nil {
+				return fmt.Errorf("getting space: %w", err)
+			}
+			pages, more, err := client.ListRootPages(cmd.Context(), space.ID, maxTreeItems)
+			if err != nil {
+				return fmt.Errorf("listing pages: %w", err)
+			}
+			if more {
+				fmt.Fprintf(os.Stderr, "Warning: space %s has more than %d top-level pages; only the first %d are shown\n", spaceKey, maxTreeItems, maxTreeItems)
+			}
+			for _, page := range pages {
+				roots = append(roots, &treeNode{ID: page.ID, Title: page.Title, Type: "page"})
+			}
+		}
+
+		for _, root := range roots {
+			if err := addDescendants(cmd.Context(), client, root, treeDepth); err != nil {
+				return err
+			}
+		}
+		if treeURLs {
+			for _, root := range roots {
+				setTreeURLs(root, cfg.BaseURL, spaceKey)
+			}
+		}
+
+		if outputJSON {
+			if len(args) == 1 {
+				return printJSON(roots[0])
+			}
+			return printJSON(roots)
+		}
+		for _, root := range roots {
+			printTree(os.Stdout, root, "", "")
+		}
+		return nil
+	},
+}
+
+// addDescendants fills in the content below node, down to depth levels, or
+// every level if depth is 0. A listing only reaches a few levels down, so
+// content at its deepest level is listed again from there.
+func addDescendants(ctx context.Context, client *api.Client, node *treeNode, depth int) error {
+	levels := api.MaxDescendantDepth
+	if depth > 0 {
+		levels = min(depth, levels)
+	}
+	items, more, err := client.GetDescendants(ctx, node.ID, levels, maxTreeItems)
+	if err != nil {
+		return fmt.Errorf("listing descendants of %s: %w", node.ID, err)
+	}
+	if more {
+		fmt.Fprintf(os.Stderr, "Warning: %s has more than %d descendants; only the first %d are shown\n", node.ID, maxTreeItems, maxTreeItems)
+	}
+
+	nodes := map[string]*treeNode{node.ID: node}
+	for _, item := range items {
+		nodes[item.ID] = &treeNode{ID: item.ID, Title: item.Title, Type: item.Type, position: item.ChildPosition}
+	}
+	for _, item := range items {
+		parent := nodes[item.ParentID]
+		if parent == nil {
+			continue
+		}
+		parent.Children = append(parent.Children, nodes[item.ID])
+	}
+	for _, n := range nodes {
+		sort.SliceStable(n.Children, func(i, j int) bool { return n.Children[i].position < n.Children[j].position })
+	}
+
+	if depth > 0 && depth <= levels {
+		return nil
+	}
+	for _, item := range items {
+		if item.Depth != levels {
+			continue
+		}
+		next := 0
+		if depth > 0 {
+			next = depth - levels
+		}
+		if err := addDescendants(ctx, client, nodes[item.ID], next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTreeURLs sets the URL of each page in the tree under node
+func setTreeURLs(node *treeNode, baseURL, spaceKey string) {
+	if node.Type == "page" {
+		node.URL = pageURL(baseURL, spaceKey, node.ID)
+	}
+	for _, child := range node.Children {
+		setTreeURLs(child, baseURL, spaceKey)
+	}
+}
+
+// printTree writes node and the tree below it. lead prefixes node's own
+// line, and indent the lines of its children.
+func printTree(out io.Writer, node *treeNode, lead, indent string) {
+	line := fmt.Sprintf("%s%s (%s)", lead, node.Title, node.ID)
+	if node.Type != "" && node.Type != "page" {
+		line += " [" + node.Type + "]"
+	}
+	if node.URL != "" {
+		line += " " + node.URL
+	}
+	fmt.Fprintln(out, line)
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printTree(out, child, indent+"└── ", indent+"    ")
+		} else {
+			printTree(out, child, indent+"├── ", indent+"│   ")
+		}
+	}
+}
+
+func init() {
+	pageTreeCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space key, to show the whole space (uses config default if not specified)")
+	pageTreeCmd.Flags().IntVarP(&treeDepth, "depth", "d", 0, "Number of levels to show below each top page (0 for all)")
+	pageTreeCmd.Flags().BoolVar(&treeURLs, "urls", false, "Show the URL of each page")
+	pageTreeCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+
+	pageCmd.AddCommand(pageTreeCmd)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageTreeCmd(t *testing.T) {
+	var depths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wiki/api/v2/pages/1":
+			_, _ = w.Write([]byte(`{"id":"1","spaceId":"s1","title":"Home"}`))
+		case "/wiki/api/v2/spaces/s1":
+			_, _ = w.Write([]byte(`{"id":"s1","key":"DOCS"}`))
+		case "/wiki/api/v2/spaces":
+			_, _ = w.Write([]byte(`{"results":[{"id":"s1","key":"DOCS"}]}`))
+		case "/wiki/api/v2/spaces/s1/pages":
+			_, _ = w.Write([]byte(`{"results":[{"id":"1","title":"Home"},{"id":"9","title":"Archive"}]}`))
+		case "/wiki/api/v2/pages/1/descendants":
+			depths = append(depths, r.URL.Query().Get("depth"))
+			_, _ = w.Write([]byte(`{"results":[
+				{"id":"3","title":"FAQ","type":"page","parentId":"1","depth":1,"childPosition":2},
+				{"id":"2","title":"Install","type":"page","parentId":"1","depth":1,"childPosition":1},
+				{"id":"4","title":"Linux","type":"page","parentId":"2","depth":2,"childPosition":0},
+				{"id":"5","title":"Notes","type":"folder","parentId":"2","depth":2,"childPosition":1}]}`))
+		case "/wiki/api/v2/pages/9/descendants":
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+	reset := func() {
+		treeDepth = 0
+		treeURLs = false
+	}
+	t.Cleanup(reset)
+
+	tests := []struct {
+		name  string
+		args  []string
+		setup func()
+		want  string
+	}{
+		{
+			name: "page",
+			args: []string{"1"},
+			want: "Home (1)\n├── Install (2)\n│   ├── Linux (4)\n│   └── Notes (5) [folder]\n└── FAQ (3)\n",
+		},
+		{
+			name:  "space with urls",
+			setup: func() { pageSpace = "DOCS"; treeURLs = true },
+			want: "Home (1) " + server.URL + "/wiki/spaces/DOCS/pages/1\n" +
+				"├── Install (2) " + server.URL + "/wiki/spaces/DOCS/pages/2\n" +
+				"│   ├── Linux (4) " + server.URL + "/wiki/spaces/DOCS/pages/4\n" +
+				"│   └── Notes (5) [folder]\n" +
+				"└── FAQ (3) " + server.URL + "/wiki/spaces/DOCS/pages/3\n" +
+				"Archive (9) " + server.URL + "/wiki/spaces/DOCS/pages/9\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			reset()
+			if tt.setup != nil {
+				tt.setup()
+			}
+			finish := captureStdStreams(t)
+			runErr := pageTreeCmd.RunE(testCommand(), tt.args)
+			stdout, _ := finish()
+			if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if stdout != tt.want {
+				t.Errorf("stdout =\n%s\nwant\n%s", stdout, tt.want)
+			}
+		})
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		resetPageFlags(t)
+		reset()
+		treeDepth = 1
+		depths = nil
+		finish := captureStdStreams(t)
+		runErr := pageTreeCmd.RunE(testCommand(), []string{"1"})
+		finish()
+		if runErr != nil {
+			t.Fatalf("RunE returned error: %v", runErr)
+		}
+		if strings.Join(depths, ",") != "1" {
+			t.Errorf("descendant depths = %v, want [1]", depths)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/acon/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+var pageUpsertCmd = &cobra.Command{
+	Use:   "upsert",
+	Short: "Create a page, or update it if its title exists",
+	Long: `Look up the page with the title, from --title or the frontmatter, in the
+space and update it if it exists, as page update would, or else create it,
+as page create would. Publishing the same file again updates the same page,
+so scripts need not keep track of page IDs.
+
+Titles are unique within a space. If --parent is given and the page with
+the title is under another parent, the command fails rather than moving it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pageIDFlag("parent", &pageParent); err != nil {
+			return err
+		}
+		client, cfg, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		content, err := readAndValidateContent(pageFile)
+		if err != nil {
+			return err
+		}
+		meta, content, err := readPageMeta(content)
+		if err != nil {
+			return err
+		}
+		if meta.title == "" {
+			return fmt.Errorf("title required: use --title flag or set title in frontmatter")
+		}
+
+		spaceKey := meta.space
+		if spaceKey == "" {
+			spaceKey = cfg.SpaceKey
+		}
+		if spaceKey == "" {
+			return fmt.Errorf("space key required: use --space flag or set CONFLUENCE_SPACE_KEY")
+		}
+		space, err := client.GetSpace(cmd.Context(), spaceKey)
+		if err != nil {
+			return fmt.Errorf("getting space: %w", err)
+		}
+
+		found, err := client.GetPageByTitle(cmd.Context(), space.ID, meta.title)
+		if err != nil {
+			return fmt.Errorf("looking up page: %w", err)
+		}
+
+		opts := converterOptions(cfg, spaceKey)
+		if err := applyConverterFlags(&opts); err != nil {
+			return err
+		}
+		conv := converter.NewConverter(opts)
+
+		if found == nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "[Page Upsert] No page titled %q in %s, creating it\n", meta.title, spaceKey)
+			}
+			result, err := createPage(cmd.Context(), client, conv, converter.Document{}, space.ID, meta, content, pageFile)
+			if err != nil {
+				return err
+			}
+			if outputJSON {
+				return printJSON(result)
+			}
+			if dryRun {
+				printDryRunCreate(result, spaceKey)
+				return nil
+			}
+			fmt.Println(pageURL(cfg.BaseURL, spaceKey, result.ID))
+			return nil
+		}
+
+		existing, err := client.GetPage(cmd.Context(), found.ID)
+		if err != nil {
+			return fmt.Errorf("getting existing page: %w", err)
+		}
+		if meta.parent != "" && meta.parent != existing.ParentID {
+			return fmt.Errorf("page %q (%s) is under parent %s, not %s: move it with page move first", meta.title, existing.ID, existing.ParentID, meta.parent)
+		}
+		if err := checkPageProtection(cmd.Context(), client, cfg, existing.ID, existing.SpaceID, "update"); err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "[Page Upsert] Updating page %s titled %q\n", existing.ID, meta.title)
+		}
+		result, err := updatePage(cmd.Context(), client, conv, converter.Document{}, existing, meta, content, pageFile, updateMsg)
+		if err != nil {
+			return err
+		}
+		if outputJSON {
+			return printJSON(result)
+		}
+		if dryRun {
+			printDryRunUpdate(existing, result)
+			return nil
+		}
+		fmt.Println(pageURL(cfg.BaseURL, spaceKey, result.ID))
+		return nil
+	},
+}
+
+func init() {
+	pageUpsertCmd.Flags().StringVarP(&pageTitle, "title", "t", "", "Page title (required unless set in frontmatter)")
+	pageUpsertCmd.Flags().StringVarP(&pageFile, "file", "f", "", "Markdown file, or - for stdin")
+	pageUpsertCmd.Flags().StringVarP(&pageSpace, "space", "s", "", "Space key (uses config default if not specified)")
+	pageUpsertCmd.Flags().StringVarP(&pageParent, "parent", "p", "", "Parent page ID")
+	pageUpsertCmd.Flags().StringVarP(&updateMsg, "message", "m", "", "Version update message, when the page is updated")
+	pageUpsertCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
+	pageUpsertCmd.Flags().BoolVar(&renderDiagrams, "render-diagrams", false, "Render diagram fences locally and attach them as images")
+	pageUpsertCmd.Flags().StringVar(&diagramFormat, "diagram-format", "svg", "Rendered diagram format: svg, png")
+	pageUpsertCmd.Flags().BoolVar(&pageTOC, "toc", false, "Insert a table of contents at the top if there is no [TOC] marker")
+	pageUpsertCmd.Flags().IntVar(&tocMin, "toc-min-level", 0, "Smallest heading level in the table of contents (1-6)")
+	pageUpsertCmd.Flags().IntVar(&tocMax, "toc-max-level", 0, "Largest heading level in the table of contents (1-6)")
+	pageUpsertCmd.Flags().IntVar(&headingOff, "heading-offset", 0, "Shift heading levels by this much, e.g. 1 to publish # as h2 (-5 to 5)")
+	pageUpsertCmd.Flags().BoolVar(&allowHTML, "allow-html", false, "Pass through raw HTML <br>, <sup>, and <sub> instead of omitting it")
+	pageUpsertCmd.Flags().BoolVar(&bareDates, "dates", false, "Convert ISO dates (2025-03-01) in text to date lozenges, not just {date:...}")
+	pageUpsertCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of omitting raw HTML that cannot be published")
+	pageUpsertCmd.Flags().BoolVar(&typography, "typography", false, "Use curly quotes, en and em dashes, and ellipses for straight quotes, -- and ---, and ...")
+	pageUpsertCmd.Flags().BoolVar(&subscript, "subscript", false, "Convert ~text~ to subscript instead of strikethrough")
+	pageUpsertCmd.Flags().BoolVar(&hardWraps, "hard-wraps", false, "Turn every newline within a paragraph into a line break")
+	pageUpsertCmd.Flags().StringVar(&pageStatus, "status", "", "Page status: current, draft (default current)")
+	pageUpsertCmd.Flags().StringSliceVar(&pageLabels, "label", nil, "Label to add (repeatable, replaces frontmatter labels)")
+	pageUpsertCmd.Flags().BoolVar(&overrideProtection, "override-protection", false, overrideProtectionUsage)
+	pageUpsertCmd.Flags().BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+	pageCmd.AddCommand(pageUpsertCmd)
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/acon/internal/api"
+	"github.com/grantcarthew/acon/internal/config"
+)
+
+func TestPageUpsertCmd(t *testing.T) {
+	var existing bool
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/spaces":
+			_, _ = w.Write([]byte(`{"results":[{"id":"s1","key":"DOCS"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/pages":
+			if got := r.URL.Query().Get("title"); got != "Release Notes" {
+				t.Errorf("title = %q", got)
+			}
+			if existing {
+				_, _ = w.Write([]byte(`{"results":[{"id":"7","title":"Release Notes"}]}`))
+			} else {
+				_, _ = w.Write([]byte(`{"results":[]}`))
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/api/v2/pages/7":
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "7", SpaceID: "s1", Title: "Release Notes", ParentID: "5", Version: &api.Version{Number: 4}})
+		case r.Method == http.MethodPut && r.URL.Path == "/wiki/api/v2/pages/7":
+			var req api.PageUpdateRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decoding update request: %v", err)
+			}
+			requests = append(requests, "update "+req.Title+" "+strings.TrimSpace(req.Body.Value))
+			if req.Version.Number != 5 {
+				t.Errorf("version = %d, want 5", req.Version.Number)
+			}
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "7", SpaceID: "s1", Title: req.Title})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/api/v2/pages":
+			var req api.PageCreateRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decoding create request: %v", err)
+			}
+			requests = append(requests, "create "+req.Title+" "+strings.TrimSpace(req.Body.Value))
+			_ = json.NewEncoder(w).Encode(api.Page{ID: "8", SpaceID: "s1", Title: req.Title})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(server.URL, "e@x", "t")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	withMockClient(t, client, &config.Config{BaseURL: server.URL})
+
+	file := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(file, []byte("---\ntitle: Release Notes\nspace: DOCS\n---\nShipped\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		existing bool
+		parent   string
+		want     string
+		wantOut  string
+		wantErr  string
+	}{
+		{name: "creates", want: "create Release Notes <p>Shipped</p>", wantOut: "/wiki/spaces/DOCS/pages/8\n"},
+		{name: "updates", existing: true, want: "update Release Notes <p>Shipped</p>", wantOut: "/wiki/spaces/DOCS/pages/7\n"},
+		{name: "updates under parent", existing: true, parent: "5", want: "update Release Notes <p>Shipped</p>", wantOut: "/wiki/spaces/DOCS/pages/7\n"},
+		{name: "other parent", existing: true, parent: "6", wantErr: "is under parent 5, not 6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetPageFlags(t)
+			pageFile = file
+			pageParent = tt.parent
+			existing = tt.existing
+			requests = nil
+			finish := captureStdStreams(t)
+			runErr := pageUpsertCmd.RunE(testCommand(), nil)
+			stdout, _ := finish()
+			if tt.wantErr != "" {
+				if runErr == nil || !strings.Contains(runErr.Error(), tt.wantErr) {
+					t.Fatalf("RunE error = %v, want %q", runErr, tt.wantErr)
+				}
+				if len(requests) != 0 {
+					t.Errorf("requests = %q, want none", requests)
+				}
+				return
+			}
+			if runErr != nil {
+				t.Fatalf("RunE returned error: %v", runErr)
+			}
+			if len(requests) != 1 || requests[0] != tt.want {
+				t.Errorf("requests = %q, want %q", requests, tt.want)
+			}
+			if stdout != server.URL+tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout, server.URL+tt.wantOut)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package config
 import (
 	"fmt"
 	"io"
-	"os"
 	"strings"
 )
 
@@ -12,6 +11,81 @@ type Config struct {
 	Email    string
 	APIToken string
 	SpaceKey string
+
+	// Converter is the default converter profile from the config file.
+	Converter ConverterProfile
+	// SpaceConverters holds per-space converter profiles keyed by space key.
+	SpaceConverters map[string]ConverterProfile
+	// Protections are guardrails against mutating critical pages and spaces.
+	Protections []Protection
+	// Profile is the name of the selected [profile.NAME] section, if any.
+	Profile string
+
+	// profiles holds the settings of each [profile.NAME] section by name.
+	profiles map[string]map[string]string
+}
+
+// ConverterProfile holds Markdown conversion settings read from the config file.
+// A nil or empty field means "not set" so space profiles can inherit from the default.
+type ConverterProfile struct {
+	// DisabledMacros lists Confluence macros the destination does not support.
+	DisabledMacros []string
+	// MermaidMacro is the macro name used for ```mermaid fences ("" means unset).
+	MermaidMacro string
+	// PlantUMLMacro is the macro name used for ```plantuml fences ("" means unset).
+	PlantUMLMacro string
+	// PlantUMLServer is the base URL of a PlantUML server that renders
+	// ```plantuml fences to images ("" means unset).
+	PlantUMLServer string
+	// MathBlockMacro and MathInlineMacro are the macro names used for $$ and
+	// $ LaTeX math ("" means unset).
+	MathBlockMacro  string
+	MathInlineMacro string
+	// JiraProjects lists the projects whose issue keys become Jira issue
+	// macros, "*" for any (nil means unset).
+	JiraProjects []string
+	// JiraServer and JiraServerID name the Jira application link used by the
+	// Jira issue macro ("" means unset).
+	JiraServer   string
+	JiraServerID string
+}
+
+// ConverterProfileFor returns the converter profile for spaceKey. Fields set in
+// the space's own profile override those in the default profile.
+func (c Config) ConverterProfileFor(spaceKey string) ConverterProfile {
+	profile := c.Converter
+	space, ok := c.SpaceConverters[spaceKey]
+	if !ok {
+		return profile
+	}
+	if space.DisabledMacros != nil {
+		profile.DisabledMacros = space.DisabledMacros
+	}
+	if space.MermaidMacro != "" {
+		profile.MermaidMacro = space.MermaidMacro
+	}
+	if space.PlantUMLMacro != "" {
+		profile.PlantUMLMacro = space.PlantUMLMacro
+	}
+	if space.PlantUMLServer != "" {
+		profile.PlantUMLServer = space.PlantUMLServer
+	}
+	if space.MathBlockMacro != "" {
+		profile.MathBlockMacro = space.MathBlockMacro
+	}
+	if space.MathInlineMacro != "" {
+		profile.MathInlineMacro = space.MathInlineMacro
+	}
+	if space.JiraProjects != nil {
+		profile.JiraProjects = space.JiraProjects
+	}
+	if space.JiraServer != "" {
+		profile.JiraServer = space.JiraServer
+	}
+	if space.JiraServerID != "" {
+		profile.JiraServerID = space.JiraServerID
+	}
+	return profile
 }
 
 func Load() (Config, error) {
@@ -19,72 +93,193 @@ func Load() (Config, error) {
 }
 
 func LoadWithVerbose(verboseLog io.Writer) (Config, error) {
+	return LoadProfile("", verboseLog)
+}
+
+// LoadProfile loads the configuration with the named profile selected, or
+// the profile named by $ACON_PROFILE if name is empty.
+func LoadProfile(name string, verboseLog io.Writer) (Config, error) {
 	logVerbose := func(format string, args ...interface{}) {
 		if verboseLog != nil {
 			fmt.Fprintf(verboseLog, format, args...)
 		}
 	}
 
-	logVerbose("[Config] Loading configuration from environment\n")
+	cfg := Config{}
 
-	cfg := Config{
-		SpaceKey: os.Getenv("CONFLUENCE_SPACE_KEY"),
+	path, err := FilePath()
+	if err != nil {
+		return Config{}, err
 	}
-
-	if cfg.SpaceKey != "" {
-		logVerbose("[Config] CONFLUENCE_SPACE_KEY: %s\n", cfg.SpaceKey)
+	logVerbose("[Config] Reading config file: %s\n", path)
+	file, err := readFile(path)
+	if err != nil {
+		return Config{}, err
 	}
-
-	// Base URL: CONFLUENCE_BASE_URL or ATLASSIAN_BASE_URL + /wiki
-	cfg.BaseURL = os.Getenv("CONFLUENCE_BASE_URL")
-	if cfg.BaseURL == "" {
-		if atlasURL := os.Getenv("ATLASSIAN_BASE_URL"); atlasURL != "" {
-			atlasURL = strings.TrimSuffix(atlasURL, "/")
-			atlasURL = strings.TrimSuffix(atlasURL, "/wiki")
-			cfg.BaseURL = atlasURL + "/wiki"
-			logVerbose("[Config] Using ATLASSIAN_BASE_URL: %s (appended /wiki)\n", cfg.BaseURL)
-		}
-	} else {
-		logVerbose("[Config] Using CONFLUENCE_BASE_URL: %s\n", cfg.BaseURL)
+	if err := applyFile(&cfg, file); err != nil {
+		return Config{}, fmt.Errorf("config file %s: %w", path, err)
 	}
 
-	// Email: CONFLUENCE_EMAIL or ATLASSIAN_EMAIL
-	cfg.Email = os.Getenv("CONFLUENCE_EMAIL")
-	if cfg.Email == "" {
-		cfg.Email = os.Getenv("ATLASSIAN_EMAIL")
-		if cfg.Email != "" {
-			logVerbose("[Config] Using ATLASSIAN_EMAIL: %s\n", cfg.Email)
-		}
-	} else {
-		logVerbose("[Config] Using CONFLUENCE_EMAIL: %s\n", cfg.Email)
-	}
+	logVerbose("[Config] Loading configuration from environment\n")
 
-	// API Token: CONFLUENCE_API_TOKEN, ATLASSIAN_API_TOKEN, or JIRA_API_TOKEN
-	if val := os.Getenv("CONFLUENCE_API_TOKEN"); val != "" {
-		cfg.APIToken = val
-		logVerbose("[Config] Using CONFLUENCE_API_TOKEN: %s\n", maskToken(val))
-	} else if val := os.Getenv("ATLASSIAN_API_TOKEN"); val != "" {
-		cfg.APIToken = val
-		logVerbose("[Config] Using ATLASSIAN_API_TOKEN: %s\n", maskToken(val))
-	} else if val := os.Getenv("JIRA_API_TOKEN"); val != "" {
-		cfg.APIToken = val
-		logVerbose("[Config] Using JIRA_API_TOKEN: %s\n", maskToken(val))
+	sources, err := cfg.resolve(name)
+	if err != nil {
+		return Config{}, fmt.Errorf("config file %s: %w", path, err)
+	}
+	for _, s := range Settings {
+		if value := *cfg.field(s.Key); value != "" {
+			logVerbose("[Config] Using %s from %s: %s\n", s.Key, sources[s.Key], s.Display(value))
+		}
 	}
 
 	if cfg.BaseURL == "" {
-		return Config{}, fmt.Errorf("CONFLUENCE_BASE_URL (or ATLASSIAN_BASE_URL) not set")
+		return Config{}, fmt.Errorf("CONFLUENCE_BASE_URL (or ATLASSIAN_BASE_URL) not set, and no base_url in the config file (see acon config set)")
 	}
 	if cfg.Email == "" {
-		return Config{}, fmt.Errorf("CONFLUENCE_EMAIL (or ATLASSIAN_EMAIL) not set")
+		return Config{}, fmt.Errorf("CONFLUENCE_EMAIL (or ATLASSIAN_EMAIL) not set, and no email in the config file (see acon config set)")
 	}
 	if cfg.APIToken == "" {
-		return Config{}, fmt.Errorf("API token not set (set CONFLUENCE_API_TOKEN, ATLASSIAN_API_TOKEN, or JIRA_API_TOKEN)")
+		return Config{}, fmt.Errorf("API token not set (set CONFLUENCE_API_TOKEN, ATLASSIAN_API_TOKEN, or JIRA_API_TOKEN, or api_token in the config file)")
 	}
 
 	logVerbose("[Config] Configuration loaded successfully\n")
 	return cfg, nil
 }
 
+// applyFile copies the settings from a parsed config file into cfg.
+func applyFile(cfg *Config, file tables) error {
+	for name, section := range file {
+		switch {
+		case name == "":
+			for key := range section {
+				switch key {
+				case "base_url", "email", "api_token", "space":
+					value, err := stringValue(section, name, key)
+					if err != nil {
+						return err
+					}
+					*cfg.field(key) = value
+				case "protect":
+					rules, err := stringList(section, name, key)
+					if err != nil {
+						return err
+					}
+					for _, rule := range rules {
+						p, err := ParseProtection(rule)
+						if err != nil {
+							return err
+						}
+						cfg.Protections = append(cfg.Protections, p)
+					}
+				default:
+					return fmt.Errorf("unknown key %s", key)
+				}
+			}
+		case strings.HasPrefix(name, "profile."):
+			profile := map[string]string{}
+			for key := range section {
+				if _, err := LookupSetting(key); err != nil {
+					return fmt.Errorf("[%s] %w", name, err)
+				}
+				value, err := stringValue(section, name, key)
+				if err != nil {
+					return err
+				}
+				profile[key] = value
+			}
+			if cfg.profiles == nil {
+				cfg.profiles = map[string]map[string]string{}
+			}
+			cfg.profiles[strings.TrimPrefix(name, "profile.")] = profile
+		case name == "converter":
+			profile, err := parseConverterProfile(name, section)
+			if err != nil {
+				return err
+			}
+			cfg.Converter = profile
+		case strings.HasPrefix(name, "converter.space."):
+			spaceKey := strings.TrimPrefix(name, "converter.space.")
+			profile, err := parseConverterProfile(name, section)
+			if err != nil {
+				return err
+			}
+			if cfg.SpaceConverters == nil {
+				cfg.SpaceConverters = map[string]ConverterProfile{}
+			}
+			cfg.SpaceConverters[spaceKey] = profile
+		default:
+			return fmt.Errorf("unknown section [%s]", name)
+		}
+	}
+	return nil
+}
+
+// parseConverterProfile reads a [converter] or [converter.space.KEY] section.
+func parseConverterProfile(name string, section map[string]any) (ConverterProfile, error) {
+	var profile ConverterProfile
+	for key := range section {
+		switch key {
+		case "disabled_macros":
+			macros, err := stringList(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.DisabledMacros = macros
+		case "mermaid_macro":
+			macro, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.MermaidMacro = macro
+		case "plantuml_macro":
+			macro, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.PlantUMLMacro = macro
+		case "plantuml_server":
+			server, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.PlantUMLServer = server
+		case "math_block_macro":
+			macro, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.MathBlockMacro = macro
+		case "math_inline_macro":
+			macro, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.MathInlineMacro = macro
+		case "jira_projects":
+			projects, err := stringList(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.JiraProjects = projects
+		case "jira_server":
+			server, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.JiraServer = server
+		case "jira_server_id":
+			id, err := stringValue(section, name, key)
+			if err != nil {
+				return ConverterProfile{}, err
+			}
+			profile.JiraServerID = id
+		default:
+			return ConverterProfile{}, fmt.Errorf("[%s] unknown key %s", name, key)
+		}
+	}
+	return profile, nil
+}
+
 // maskToken masks most of the token for security in logs
 func maskToken(token string) string {
 	if len(token) <= 8 {
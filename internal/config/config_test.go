@@ -1,6 +1,9 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -226,11 +229,15 @@ func TestLoad(t *testing.T) {
 				"ATLASSIAN_API_TOKEN",
 				"JIRA_API_TOKEN",
 				"CONFLUENCE_SPACE_KEY",
+				"ACON_PROFILE",
 			}
 			for _, key := range clearEnvVars {
 				t.Setenv(key, "")
 			}
 
+			// Point at a missing config file so a developer's own file cannot leak in
+			t.Setenv("ACON_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+
 			// Set test env vars
 			for key, val := range tt.env {
 				t.Setenv(key, val)
@@ -254,9 +261,227 @@ func TestLoad(t *testing.T) {
 				return
 			}
 
-			if cfg != tt.wantCfg {
+			if !reflect.DeepEqual(cfg, tt.wantCfg) {
 				t.Errorf("Load() = %+v, want %+v", cfg, tt.wantCfg)
 			}
 		})
 	}
 }
+
+// setRequiredEnv sets the minimum environment for Load to succeed.
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFLUENCE_BASE_URL", "https://example.atlassian.net")
+	t.Setenv("CONFLUENCE_EMAIL", "user@example.com")
+	t.Setenv("CONFLUENCE_API_TOKEN", "token123")
+}
+
+// writeConfigFile writes content to a temp config file and points ACON_CONFIG at it.
+func writeConfigFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+	t.Setenv("ACON_CONFIG", path)
+}
+
+func TestLoad_ConverterProfiles(t *testing.T) {
+	setRequiredEnv(t)
+	writeConfigFile(t, `
+[converter]
+disabled_macros = ["mermaid"]
+
+[converter.space.LEGACY]
+disabled_macros = [
+  "mermaid",
+  "code", # no code macro on this instance
+]
+
+[converter.space.OPEN]
+disabled_macros = []
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		space string
+		want  []string
+	}{
+		{"DOCS", []string{"mermaid"}},
+		{"LEGACY", []string{"mermaid", "code"}},
+		{"OPEN", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.space, func(t *testing.T) {
+			got := cfg.ConverterProfileFor(tt.space).DisabledMacros
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConverterProfileFor(%q).DisabledMacros = %v, want %v", tt.space, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_MermaidMacro(t *testing.T) {
+	setRequiredEnv(t)
+	writeConfigFile(t, `
+[converter]
+mermaid_macro = "mermaid-cloud"
+
+[converter.space.LEGACY]
+mermaid_macro = "mermaid-macro"
+
+[converter.space.OPEN]
+disabled_macros = []
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		space string
+		want  string
+	}{
+		{"DOCS", "mermaid-cloud"},
+		{"LEGACY", "mermaid-macro"},
+		{"OPEN", "mermaid-cloud"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.space, func(t *testing.T) {
+			if got := cfg.ConverterProfileFor(tt.space).MermaidMacro; got != tt.want {
+				t.Errorf("ConverterProfileFor(%q).MermaidMacro = %q, want %q", tt.space, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_PlantUML(t *testing.T) {
+	setRequiredEnv(t)
+	writeConfigFile(t, `
+[converter]
+plantuml_server = "https://plantuml.example.com"
+
+[converter.space.LEGACY]
+plantuml_macro = "plantumlrender"
+plantuml_server = "http://legacy:8080/plantuml"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	docs := cfg.ConverterProfileFor("DOCS")
+	if docs.PlantUMLMacro != "" || docs.PlantUMLServer != "https://plantuml.example.com" {
+		t.Errorf("DOCS profile = %+v", docs)
+	}
+	legacy := cfg.ConverterProfileFor("LEGACY")
+	if legacy.PlantUMLMacro != "plantumlrender" || legacy.PlantUMLServer != "http://legacy:8080/plantuml" {
+		t.Errorf("LEGACY profile = %+v", legacy)
+	}
+}
+
+func TestLoad_MathMacros(t *testing.T) {
+	setRequiredEnv(t)
+	writeConfigFile(t, `
+[converter]
+math_block_macro = "latex-block"
+math_inline_macro = "latex-inline"
+
+[converter.space.SCI]
+math_inline_macro = "eqn"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	docs := cfg.ConverterProfileFor("DOCS")
+	if docs.MathBlockMacro != "latex-block" || docs.MathInlineMacro != "latex-inline" {
+		t.Errorf("DOCS profile = %+v", docs)
+	}
+	sci := cfg.ConverterProfileFor("SCI")
+	if sci.MathBlockMacro != "latex-block" || sci.MathInlineMacro != "eqn" {
+		t.Errorf("SCI profile = %+v", sci)
+	}
+}
+
+func TestLoad_Jira(t *testing.T) {
+	setRequiredEnv(t)
+	writeConfigFile(t, `
+[converter]
+jira_projects = ["PROJ", "OPS"]
+jira_server = "Acme Jira"
+
+[converter.space.SANDBOX]
+jira_projects = []
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	docs := cfg.ConverterProfileFor("DOCS")
+	if !reflect.DeepEqual(docs.JiraProjects, []string{"PROJ", "OPS"}) || docs.JiraServer != "Acme Jira" || docs.JiraServerID != "" {
+		t.Errorf("DOCS profile = %+v", docs)
+	}
+	sandbox := cfg.ConverterProfileFor("SANDBOX")
+	if len(sandbox.JiraProjects) != 0 || sandbox.JiraServer != "Acme Jira" {
+		t.Errorf("SANDBOX profile = %+v", sandbox)
+	}
+}
+
+func TestLoad_ConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown section", "[bogus]\nkey = 1\n", "unknown section [bogus]"},
+		{"unknown converter key", "[converter]\nmermaid = true\n", "[converter] unknown key mermaid"},
+		{"wrong type", "[converter]\ndisabled_macros = \"code\"\n", "must be an array of strings"},
+		{"wrong string type", "[converter]\nmermaid_macro = true\n", "[converter] mermaid_macro must be a string"},
+		{"syntax error", "[converter\n", "to end table name"},
+		{"non-string array", "[converter]\ndisabled_macros = [1, 2]\n", "[converter] disabled_macros must be an array of strings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			writeConfigFile(t, tt.content)
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Protections(t *testing.T) {
+	setRequiredEnv(t)
+	writeConfigFile(t, `protect = ["DOCS:HOME", "pageID:123"]`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Protection{
+		{Rule: "DOCS:HOME", SpaceKey: "DOCS", HomeOnly: true},
+		{Rule: "pageID:123", PageID: "123"},
+	}
+	if !reflect.DeepEqual(cfg.Protections, want) {
+		t.Errorf("Protections = %+v, want %+v", cfg.Protections, want)
+	}
+
+	writeConfigFile(t, `protect = ["DOCS:bogus"]`)
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DOCS:bogus") {
+		t.Errorf("Load() error = %v, want invalid rule error", err)
+	}
+}
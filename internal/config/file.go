@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// configFileEnv overrides the config file location.
+const configFileEnv = "ACON_CONFIG"
+
+// FilePath returns the location of the optional config file:
+// $ACON_CONFIG, else $XDG_CONFIG_HOME/acon/config.toml, else ~/.config/acon/config.toml.
+func FilePath() (string, error) {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return path, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "acon", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "acon", "config.toml"), nil
+}
+
+// templateDirEnv overrides the page template directory.
+const templateDirEnv = "ACON_TEMPLATES"
+
+// TemplateDir returns the directory page templates are read from:
+// $ACON_TEMPLATES, else the templates directory beside the config file.
+func TemplateDir() (string, error) {
+	if dir := os.Getenv(templateDirEnv); dir != "" {
+		return dir, nil
+	}
+	path, err := FilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "templates"), nil
+}
+
+// tables maps a dotted section name ("" for top-level keys) to its key/value pairs.
+// Values are as decoded by the toml package, except that arrays of strings are []string.
+type tables map[string]map[string]any
+
+// readFile reads and parses the config file at path.
+// A missing file is not an error and yields no tables.
+func readFile(path string) (tables, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tables{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	t, err := parseTOML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// parseTOML parses a config file into its sections. Nested tables, whether
+// [section] headers or inline tables, become sections named by their dotted
+// path; a table holding only other tables is not a section of its own.
+func parseTOML(data string) (tables, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(data, &doc); err != nil {
+		return nil, err
+	}
+	result := tables{"": {}}
+	addTables(result, "", doc)
+	return result, nil
+}
+
+// addTables adds the table named name, and the tables nested in it, to result.
+func addTables(result tables, name string, table map[string]any) {
+	section := map[string]any{}
+	for key, value := range table {
+		switch v := value.(type) {
+		case map[string]any:
+			child := key
+			if name != "" {
+				child = name + "." + key
+			}
+			addTables(result, child, v)
+		case []any:
+			section[key] = stringArray(v)
+		default:
+			section[key] = v
+		}
+	}
+	if name == "" || len(section) > 0 || len(table) == 0 {
+		result[name] = section
+	}
+}
+
+// stringArray returns values as a []string if every value is a string,
+// else unchanged.
+func stringArray(values []any) any {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return values
+		}
+		strs[i] = s
+	}
+	return strs
+}
+
+// The helpers below read the config file a line at a time for setKey,
+// which edits it in place to keep its comments and layout.
+
+// stripComment removes a trailing # comment that is not inside a string.
+func stripComment(line string) string {
+	if i := indexOutsideStrings(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// arrayClosed reports whether raw contains the closing bracket of an array.
+func arrayClosed(raw string) bool {
+	return indexOutsideStrings(raw, ']') >= 0
+}
+
+// indexOutsideStrings returns the index of the first c in s that is not
+// inside a basic or literal string, or -1.
+func indexOutsideStrings(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == 0 && (s[i] == '"' || s[i] == '\''):
+			quote = s[i]
+		case quote == s[i]:
+			quote = 0
+		case quote == 0 && s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseSectionName normalises a section header into a dotted name,
+// removing quotes around individual segments.
+func parseSectionName(header string) (string, error) {
+	if header == "" {
+		return "", fmt.Errorf("empty section name")
+	}
+	var parts []string
+	for _, part := range strings.Split(header, ".") {
+		part = unquote(strings.TrimSpace(part))
+		if part == "" {
+			return "", fmt.Errorf("invalid section name [%s]", header)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// unquote strips surrounding double or single quotes from s if present.
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// stringList returns the []string value for key, or an error if it has another type.
+func stringList(section map[string]any, sectionName, key string) ([]string, error) {
+	v, ok := section[key].([]string)
+	if !ok {
+		if sectionName == "" {
+			return nil, fmt.Errorf("%s must be an array of strings", key)
+		}
+		return nil, fmt.Errorf("[%s] %s must be an array of strings", sectionName, key)
+	}
+	return v, nil
+}
+
+// stringValue returns the string value for key, or an error if it has another type.
+func stringValue(section map[string]any, sectionName, key string) (string, error) {
+	v, ok := section[key].(string)
+	if !ok {
+		if sectionName == "" {
+			return "", fmt.Errorf("%s must be a string", key)
+		}
+		return "", fmt.Errorf("[%s] %s must be a string", sectionName, key)
+	}
+	return v, nil
+}
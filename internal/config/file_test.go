@@ -0,0 +1,131 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    tables
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  tables{"": {}},
+		},
+		{
+			name:  "scalar values",
+			input: "name = \"acon\" # comment\nenabled = true\ncount = 1_000\n",
+			want:  tables{"": {"name": "acon", "enabled": true, "count": int64(1000)}},
+		},
+		{
+			name:  "hash inside string",
+			input: `title = "Issue #1"`,
+			want:  tables{"": {"title": "Issue #1"}},
+		},
+		{
+			name:  "quoted section segments",
+			input: "[converter.space.\"~user\"]\ndisabled_macros = [\"a\", \"b\"]\n",
+			want:  tables{"": {}, "converter.space.~user": {"disabled_macros": []string{"a", "b"}}},
+		},
+		{
+			name:  "multi-line array",
+			input: "list = [\n  \"a\",\n  \"b\", # trailing\n]\n",
+			want:  tables{"": {"list": []string{"a", "b"}}},
+		},
+		{
+			name:  "literal strings",
+			input: "space = 'DOCS'\nprotect = ['DOCS:HOME', \"pageID:123\"]\n",
+			want:  tables{"": {"space": "DOCS", "protect": []string{"DOCS:HOME", "pageID:123"}}},
+		},
+		{
+			name:  "float",
+			input: "ratio = 1.5",
+			want:  tables{"": {"ratio": 1.5}},
+		},
+		{
+			name:  "inline table",
+			input: "[converter.space]\n\"~user\" = { disabled_macros = [\"a\"] }\n",
+			want:  tables{"": {}, "converter.space.~user": {"disabled_macros": []string{"a"}}},
+		},
+		{
+			name:  "empty section",
+			input: "[profile.work]\n",
+			want:  tables{"": {}, "profile.work": {}},
+		},
+		{
+			name:  "non-string array",
+			input: "a = [1, 2]",
+			want:  tables{"": {"a": []any{int64(1), int64(2)}}},
+		},
+		{
+			name:    "missing equals",
+			input:   "just text",
+			wantErr: "line 1",
+		},
+		{
+			name:    "duplicate key",
+			input:   "a = 1\na = 2",
+			wantErr: "line 2",
+		},
+		{
+			name:    "duplicate section",
+			input:   "[x]\n[x]",
+			wantErr: "line 2",
+		},
+		{
+			name:    "bare value",
+			input:   "a = bare",
+			wantErr: "line 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseTOML() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTOML() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTOML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	t.Setenv("ACON_CONFIG", "/tmp/custom.toml")
+	if got, _ := FilePath(); got != "/tmp/custom.toml" {
+		t.Errorf("FilePath() = %q, want ACON_CONFIG value", got)
+	}
+
+	t.Setenv("ACON_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got, _ := FilePath(); got != "/xdg/acon/config.toml" {
+		t.Errorf("FilePath() = %q, want /xdg/acon/config.toml", got)
+	}
+}
+
+func TestTemplateDir(t *testing.T) {
+	t.Setenv("ACON_TEMPLATES", "/tmp/templates")
+	if got, _ := TemplateDir(); got != "/tmp/templates" {
+		t.Errorf("TemplateDir() = %q, want ACON_TEMPLATES value", got)
+	}
+
+	t.Setenv("ACON_TEMPLATES", "")
+	t.Setenv("ACON_CONFIG", "/tmp/acon/custom.toml")
+	if got, _ := TemplateDir(); got != "/tmp/acon/templates" {
+		t.Errorf("TemplateDir() = %q, want /tmp/acon/templates", got)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Protection is a guardrail rule from the config file's protect list.
+// Mutating commands refuse to touch a protected target unless overridden.
+type Protection struct {
+	Rule     string // Original rule text, used in error messages
+	PageID   string // Set for "pageID:123" rules
+	SpaceKey string // Set for "KEY", "KEY:*", and "KEY:HOME" rules
+	HomeOnly bool   // "KEY:HOME" protects only the space homepage
+}
+
+// ParseProtection parses a protect rule. Supported forms:
+//
+//	pageID:123  a single page
+//	KEY:HOME    the homepage of space KEY
+//	KEY or KEY:* every page in space KEY, and the space itself
+func ParseProtection(rule string) (Protection, error) {
+	trimmed := strings.TrimSpace(rule)
+	if trimmed == "" {
+		return Protection{}, fmt.Errorf("empty protect rule")
+	}
+
+	prefix, rest, hasColon := strings.Cut(trimmed, ":")
+	prefix = strings.TrimSpace(prefix)
+	rest = strings.TrimSpace(rest)
+
+	if hasColon && strings.EqualFold(prefix, "pageID") {
+		if rest == "" {
+			return Protection{}, fmt.Errorf("protect rule %q: missing page ID", rule)
+		}
+		return Protection{Rule: trimmed, PageID: rest}, nil
+	}
+	if prefix == "" {
+		return Protection{}, fmt.Errorf("protect rule %q: missing space key", rule)
+	}
+
+	p := Protection{Rule: trimmed, SpaceKey: prefix}
+	switch {
+	case !hasColon, rest == "*":
+	case strings.EqualFold(rest, "HOME"):
+		p.HomeOnly = true
+	default:
+		return Protection{}, fmt.Errorf("protect rule %q: expected KEY, KEY:*, KEY:HOME, or pageID:N", rule)
+	}
+	return p, nil
+}
+
+// HasSpaceProtections reports whether any rule needs the page's space to be
+// known, so callers can skip lookups when only page ID rules exist.
+func (c Config) HasSpaceProtections() bool {
+	for _, p := range c.Protections {
+		if p.SpaceKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PageProtection returns the first rule protecting the page. spaceKey and
+// homepageID describe the page's space and may be empty when unknown.
+func (c Config) PageProtection(pageID, spaceKey, homepageID string) (Protection, bool) {
+	for _, p := range c.Protections {
+		switch {
+		case p.PageID != "":
+			if p.PageID == pageID {
+				return p, true
+			}
+		case p.SpaceKey == "" || p.SpaceKey != spaceKey:
+		case !p.HomeOnly:
+			return p, true
+		case homepageID != "" && homepageID == pageID:
+			return p, true
+		}
+	}
+	return Protection{}, false
+}
+
+// SpaceProtection returns the first rule protecting the space as a whole.
+func (c Config) SpaceProtection(spaceKey string) (Protection, bool) {
+	for _, p := range c.Protections {
+		if p.SpaceKey == spaceKey && !p.HomeOnly {
+			return p, true
+		}
+	}
+	return Protection{}, false
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProtection(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    Protection
+		wantErr string
+	}{
+		{rule: "pageID:123", want: Protection{Rule: "pageID:123", PageID: "123"}},
+		{rule: "pageid: 456 ", want: Protection{Rule: "pageid: 456", PageID: "456"}},
+		{rule: "DOCS:HOME", want: Protection{Rule: "DOCS:HOME", SpaceKey: "DOCS", HomeOnly: true}},
+		{rule: "DOCS:*", want: Protection{Rule: "DOCS:*", SpaceKey: "DOCS"}},
+		{rule: "DOCS", want: Protection{Rule: "DOCS", SpaceKey: "DOCS"}},
+		{rule: "", wantErr: "empty protect rule"},
+		{rule: "pageID:", wantErr: "missing page ID"},
+		{rule: ":HOME", wantErr: "missing space key"},
+		{rule: "DOCS:drafts", wantErr: "expected KEY, KEY:*, KEY:HOME, or pageID:N"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := ParseProtection(tt.rule)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseProtection(%q) error = %v, want containing %q", tt.rule, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseProtection(%q) unexpected error = %v", tt.rule, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseProtection(%q) = %+v, want %+v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_PageProtection(t *testing.T) {
+	cfg := Config{Protections: []Protection{
+		{Rule: "pageID:1", PageID: "1"},
+		{Rule: "DOCS:HOME", SpaceKey: "DOCS", HomeOnly: true},
+		{Rule: "OPS", SpaceKey: "OPS"},
+	}}
+
+	tests := []struct {
+		name       string
+		pageID     string
+		spaceKey   string
+		homepageID string
+		wantRule   string
+	}{
+		{"page ID rule", "1", "", "", "pageID:1"},
+		{"space homepage", "10", "DOCS", "10", "DOCS:HOME"},
+		{"other page in homepage-only space", "11", "DOCS", "10", ""},
+		{"homepage unknown", "10", "DOCS", "", ""},
+		{"whole space", "99", "OPS", "", "OPS"},
+		{"unprotected space", "99", "TEAM", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := cfg.PageProtection(tt.pageID, tt.spaceKey, tt.homepageID)
+			if ok != (tt.wantRule != "") || p.Rule != tt.wantRule {
+				t.Errorf("PageProtection() = %q, %v; want %q", p.Rule, ok, tt.wantRule)
+			}
+		})
+	}
+
+	if _, ok := cfg.SpaceProtection("OPS"); !ok {
+		t.Error("SpaceProtection(OPS) = false, want true")
+	}
+	if _, ok := cfg.SpaceProtection("DOCS"); ok {
+		t.Error("SpaceProtection(DOCS) = true, want false for homepage-only rule")
+	}
+	if !cfg.HasSpaceProtections() {
+		t.Error("HasSpaceProtections() = false, want true")
+	}
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Setting is a top-level config file key that `acon config` can read and write.
+type Setting struct {
+	Key         string
+	Description string
+	// Env lists the environment variables that override the config file, in
+	// priority order.
+	Env []string
+	// Secret values are masked when shown.
+	Secret bool
+}
+
+// Settings lists the settable keys in the order they are shown.
+var Settings = []Setting{
+	{Key: "base_url", Description: "Confluence site URL", Env: []string{"CONFLUENCE_BASE_URL", "ATLASSIAN_BASE_URL"}},
+	{Key: "email", Description: "Atlassian account email", Env: []string{"CONFLUENCE_EMAIL", "ATLASSIAN_EMAIL"}},
+	{Key: "api_token", Description: "Atlassian API token", Env: []string{"CONFLUENCE_API_TOKEN", "ATLASSIAN_API_TOKEN", "JIRA_API_TOKEN"}, Secret: true},
+	{Key: "space", Description: "Default space key", Env: []string{"CONFLUENCE_SPACE_KEY"}},
+}
+
+// SettingValue is the effective value of a setting and where it came from.
+type SettingValue struct {
+	Setting
+	Value string
+	// Source is where the value came from: "profile NAME", an environment
+	// variable, "config file", or "" when the setting is not set.
+	Source string
+}
+
+var spaceKeyPattern = regexp.MustCompile(`^~?[A-Za-z0-9]+$`)
+
+// LookupSetting returns the setting for key.
+func LookupSetting(key string) (Setting, error) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+	keys := make([]string, len(Settings))
+	for i, s := range Settings {
+		keys[i] = s.Key
+	}
+	return Setting{}, fmt.Errorf("unknown key %s (valid keys: %s)", key, strings.Join(keys, ", "))
+}
+
+// Validate returns an error if value is not acceptable for the setting.
+func (s Setting) Validate(value string) error {
+	if value == "" {
+		return fmt.Errorf("%s cannot be empty", s.Key)
+	}
+	switch s.Key {
+	case "base_url":
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("base_url must be an http or https URL, such as https://example.atlassian.net")
+		}
+	case "email":
+		if !strings.Contains(value, "@") {
+			return fmt.Errorf("email must be an email address")
+		}
+	case "space":
+		if !spaceKeyPattern.MatchString(value) {
+			return fmt.Errorf("space must be a space key, such as DOCS")
+		}
+	}
+	return nil
+}
+
+// Display returns value as it may be shown, masked if the setting is secret.
+func (s Setting) Display(value string) string {
+	if s.Secret && value != "" {
+		return maskToken(value)
+	}
+	return value
+}
+
+// fromEnv returns the value of the first of the setting's environment
+// variables that is set, and the variable's name.
+func (s Setting) fromEnv() (string, string) {
+	for _, name := range s.Env {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if name == "ATLASSIAN_BASE_URL" {
+			// The Atlassian site URL, without the Confluence /wiki path
+			value = strings.TrimSuffix(value, "/")
+			value = strings.TrimSuffix(value, "/wiki") + "/wiki"
+		}
+		return value, name
+	}
+	return "", ""
+}
+
+// field returns the Config field holding the setting's value.
+func (c *Config) field(key string) *string {
+	switch key {
+	case "base_url":
+		return &c.BaseURL
+	case "email":
+		return &c.Email
+	case "api_token":
+		return &c.APIToken
+	case "space":
+		return &c.SpaceKey
+	}
+	return nil
+}
+
+// profileEnv selects a profile when --profile is not given.
+const profileEnv = "ACON_PROFILE"
+
+// resolve settles each setting in c, which holds the top-level config file
+// values, from the selected profile, else the environment, else the config
+// file, and returns where each value came from by key. The profile is name,
+// or $ACON_PROFILE if name is empty.
+func (c *Config) resolve(name string) (map[string]string, error) {
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	var profile map[string]string
+	if name != "" {
+		var ok bool
+		profile, ok = c.profiles[name]
+		if !ok && len(c.profiles) == 0 {
+			return nil, fmt.Errorf("no profile %q: there are no [profile.NAME] sections", name)
+		}
+		if !ok {
+			return nil, fmt.Errorf("no profile %q (profiles: %s)", name, strings.Join(c.profileNames(), ", "))
+		}
+		c.Profile = name
+	}
+
+	sources := map[string]string{}
+	for _, s := range Settings {
+		field := c.field(s.Key)
+		if value := profile[s.Key]; value != "" {
+			*field = value
+			sources[s.Key] = "profile " + name
+		} else if value, env := s.fromEnv(); value != "" {
+			*field = value
+			sources[s.Key] = env
+		} else if *field != "" {
+			sources[s.Key] = "config file"
+		}
+	}
+	return sources, nil
+}
+
+// profileNames returns the names of the profiles in c, sorted.
+func (c Config) profileNames() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profiles returns the names of the profiles in the config file, sorted.
+func Profiles() ([]string, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := applyFile(&cfg, file); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return cfg.profileNames(), nil
+}
+
+// Effective returns the effective value of every setting: from the named
+// profile, or $ACON_PROFILE, if there is one and it sets the value, else from
+// the environment, else from the config file.
+func Effective(profile string) ([]SettingValue, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := applyFile(&cfg, file); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	sources, err := cfg.resolve(profile)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	values := make([]SettingValue, len(Settings))
+	for i, s := range Settings {
+		values[i] = SettingValue{Setting: s, Value: *cfg.field(s.Key), Source: sources[s.Key]}
+	}
+	return values, nil
+}
+
+// SetValue validates value and writes it to key in the config file at path,
+// creating the file if needed. The value goes in the [profile.NAME] section
+// if profile is not empty, else at the top level. Other keys, sections, and
+// comments are kept.
+func SetValue(path, profile, key, value string) error {
+	s, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := s.Validate(value); err != nil {
+		return err
+	}
+	section := ""
+	if profile != "" {
+		if strings.ContainsAny(profile, ".[]\" \t") {
+			return fmt.Errorf("invalid profile name %q", profile)
+		}
+		section = "profile." + profile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	updated := setKey(string(data), section, key, strconv.Quote(value))
+
+	// Refuse to write a file acon could not read back
+	file, err := parseTOML(updated)
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if err := applyFile(&Config{}, file); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return WriteFile(path, updated)
+}
+
+// WriteFile writes the config file at path, creating its directory. The file
+// may hold an API token, so only the owner can read it.
+func WriteFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	return nil
+}
+
+// setKey sets key = raw in a section of a config file, "" for the top-level
+// table. An existing line for key is replaced; otherwise the line is added at
+// the end of the section, adding the section at the end of the file if needed.
+func setKey(data, section, key, raw string) string {
+	line := key + " = " + raw
+	lines := strings.Split(data, "\n")
+	current := ""
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(stripComment(lines[i]))
+		if strings.HasPrefix(trimmed, "[") {
+			if current == section {
+				// The section ends here, before any comments describing the
+				// next one and the blank lines separating them
+				end := i
+				for end > 0 && strings.HasPrefix(strings.TrimSpace(lines[end-1]), "#") {
+					end--
+				}
+				for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+					end--
+				}
+				insert := []string{line}
+				if strings.TrimSpace(lines[end]) != "" {
+					insert = append(insert, "")
+				}
+				return strings.Join(append(lines[:end], append(insert, lines[end:]...)...), "\n")
+			}
+			current, _ = parseSectionName(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]")))
+			continue
+		}
+		name, value, found := strings.Cut(trimmed, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		end := i
+		if strings.HasPrefix(value, "[") {
+			for !arrayClosed(value) && end+1 < len(lines) {
+				end++
+				value += " " + strings.TrimSpace(stripComment(lines[end]))
+			}
+		}
+		if current == section && unquote(strings.TrimSpace(name)) == key {
+			return strings.Join(append(lines[:i], append([]string{line}, lines[end+1:]...)...), "\n")
+		}
+		i = end
+	}
+
+	data = strings.TrimRight(data, "\n")
+	switch {
+	case current == section:
+		// The section, or the top-level table, runs to the end of the file
+	case data == "":
+		data = "[" + section + "]"
+	default:
+		data += "\n\n[" + section + "]"
+	}
+	if data == "" {
+		return line + "\n"
+	}
+	return data + "\n" + line + "\n"
+}
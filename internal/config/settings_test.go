@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearSettingsEnv unsets every environment variable that overrides a setting.
+func clearSettingsEnv(t *testing.T) {
+	t.Helper()
+	for _, s := range Settings {
+		for _, name := range s.Env {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func TestSetting_Validate(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    string
+	}{
+		{"base_url", "https://example.atlassian.net/wiki", ""},
+		{"base_url", "example.atlassian.net", "must be an http or https URL"},
+		{"email", "user@example.com", ""},
+		{"email", "user", "must be an email address"},
+		{"api_token", "abc", ""},
+		{"api_token", "", "cannot be empty"},
+		{"space", "DOCS", ""},
+		{"space", "~12345", ""},
+		{"space", "MY SPACE", "must be a space key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+" "+tt.value, func(t *testing.T) {
+			s, err := LookupSetting(tt.key)
+			if err != nil {
+				t.Fatalf("LookupSetting: %v", err)
+			}
+			err = s.Validate(tt.value)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate(%q) error = %v", tt.value, err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate(%q) error = %v, want containing %q", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LookupSetting("bogus"); err == nil || !strings.Contains(err.Error(), "valid keys: base_url") {
+		t.Errorf("LookupSetting(bogus) error = %v", err)
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	tests := []struct {
+		name, data, section, want string
+	}{
+		{"empty file", "", "", "space = \"DOCS\"\n"},
+		{"no trailing newline", "email = \"a@b\"", "", "email = \"a@b\"\nspace = \"DOCS\"\n"},
+		{"replace", "space = \"OLD\" # default\nemail = \"a@b\"\n", "", "space = \"DOCS\"\nemail = \"a@b\"\n"},
+		{
+			"before first section and its comments",
+			"protect = [\n  \"OPS\",\n]\n\n# Converter\n[converter]\nspace = \"X\"\n",
+			"",
+			"protect = [\n  \"OPS\",\n]\nspace = \"DOCS\"\n\n# Converter\n[converter]\nspace = \"X\"\n",
+		},
+		{
+			"literal strings",
+			"protect = ['#]', 'OPS']\n'space' = 'OLD' # default\n",
+			"",
+			"protect = ['#]', 'OPS']\nspace = \"DOCS\"\n",
+		},
+		{"section first", "[converter]\n", "", "space = \"DOCS\"\n\n[converter]\n"},
+		{
+			"replace in section",
+			"space = \"TOP\"\n\n[profile.work]\nspace = \"OLD\"\n",
+			"profile.work",
+			"space = \"TOP\"\n\n[profile.work]\nspace = \"DOCS\"\n",
+		},
+		{
+			"end of section",
+			"[profile.work]\nemail = \"a@b\"\n\n[converter]\n",
+			"profile.work",
+			"[profile.work]\nemail = \"a@b\"\nspace = \"DOCS\"\n\n[converter]\n",
+		},
+		{
+			"new section",
+			"space = \"TOP\"\n",
+			"profile.work",
+			"space = \"TOP\"\n\n[profile.work]\nspace = \"DOCS\"\n",
+		},
+		{"new section in empty file", "", "profile.work", "[profile.work]\nspace = \"DOCS\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setKey(tt.data, tt.section, "space", `"DOCS"`); got != tt.want {
+				t.Errorf("setKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	clearSettingsEnv(t)
+	path := filepath.Join(t.TempDir(), "acon", "config.toml")
+	t.Setenv("ACON_CONFIG", path)
+
+	if err := SetValue(path, "", "space", "bad key"); err == nil {
+		t.Fatal("SetValue with an invalid space key succeeded")
+	}
+	for key, value := range map[string]string{
+		"base_url":  "https://example.atlassian.net/wiki",
+		"email":     "user@example.com",
+		"api_token": "secret-token-1234",
+		"space":     "DOCS",
+	} {
+		if err := SetValue(path, "", key, value); err != nil {
+			t.Fatalf("SetValue(%s): %v", key, err)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.BaseURL != "https://example.atlassian.net/wiki" || cfg.Email != "user@example.com" ||
+		cfg.APIToken != "secret-token-1234" || cfg.SpaceKey != "DOCS" {
+		t.Errorf("Load() = %+v", cfg)
+	}
+
+	// The environment overrides the file
+	t.Setenv("CONFLUENCE_SPACE_KEY", "ENV")
+	values, err := Effective("")
+	if err != nil {
+		t.Fatalf("Effective() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, v := range values {
+		got[v.Key] = v.Display(v.Value) + " " + v.Source
+	}
+	want := map[string]string{
+		"base_url":  "https://example.atlassian.net/wiki config file",
+		"email":     "user@example.com config file",
+		"api_token": "secr****1234 config file",
+		"space":     "ENV CONFLUENCE_SPACE_KEY",
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("%s = %q, want %q", key, got[key], w)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	clearSettingsEnv(t)
+	t.Setenv("ACON_PROFILE", "")
+	path := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("ACON_CONFIG", path)
+	t.Setenv("CONFLUENCE_EMAIL", "env@example.com")
+
+	for _, set := range []struct{ profile, key, value string }{
+		{"", "base_url", "https://home.atlassian.net/wiki"},
+		{"", "api_token", "home-token"},
+		{"client", "base_url", "https://client.atlassian.net/wiki"},
+		{"client", "email", "me@client.com"},
+		{"other", "space", "OPS"},
+	} {
+		if err := SetValue(path, set.profile, set.key, set.value); err != nil {
+			t.Fatalf("SetValue(%q, %s): %v", set.profile, set.key, err)
+		}
+	}
+	if err := SetValue(path, "a.b", "space", "OPS"); err == nil {
+		t.Error("SetValue with a dotted profile name succeeded")
+	}
+
+	names, err := Profiles()
+	if err != nil || strings.Join(names, ",") != "client,other" {
+		t.Errorf("Profiles() = %v, %v", names, err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "" || cfg.BaseURL != "https://home.atlassian.net/wiki" || cfg.Email != "env@example.com" {
+		t.Errorf("Load() = %+v", cfg)
+	}
+
+	// A profile overrides the environment, and falls back to it and the
+	// top level for the settings it leaves out
+	t.Setenv("ACON_PROFILE", "client")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "client" || cfg.BaseURL != "https://client.atlassian.net/wiki" ||
+		cfg.Email != "me@client.com" || cfg.APIToken != "home-token" {
+		t.Errorf("Load() with ACON_PROFILE = %+v", cfg)
+	}
+
+	cfg, err = LoadProfile("other", nil)
+	if err != nil {
+		t.Fatalf("LoadProfile(other) error = %v", err)
+	}
+	if cfg.Profile != "other" || cfg.SpaceKey != "OPS" || cfg.Email != "env@example.com" {
+		t.Errorf("LoadProfile(other) = %+v", cfg)
+	}
+
+	values, err := Effective("client")
+	if err != nil {
+		t.Fatalf("Effective() error = %v", err)
+	}
+	if values[0].Source != "profile client" || values[1].Source != "profile client" || values[2].Source != "config file" {
+		t.Errorf("Effective() = %+v", values)
+	}
+
+	if _, err := LoadProfile("missing", nil); err == nil || !strings.Contains(err.Error(), `no profile "missing" (profiles: client, other)`) {
+		t.Errorf("LoadProfile(missing) error = %v", err)
+	}
+}
@@ -0,0 +1,668 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adfNode is one node of an Atlassian Document Format document
+type adfNode struct {
+	Type    string         `json:"type"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []adfNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []adfMark      `json:"marks,omitempty"`
+}
+
+// adfMark is a mark, such as strong or link, applied to a text node
+type adfMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// adfPanelMacros maps ADF panel types to the panel macros whose alerts and
+// admonitions they are written as
+var adfPanelMacros = map[string]string{
+	"info":    "info",
+	"note":    "note",
+	"tip":     "tip",
+	"success": "tip",
+	"warning": "warning",
+	"error":   "warning",
+}
+
+// adfMacroExtension is the extension type of Confluence macros in ADF
+const adfMacroExtension = "com.atlassian.confluence.macro.core"
+
+// adfFenceLang is the code fence language holding an ADF node with no
+// Markdown form, as JSON
+const adfFenceLang = "adf"
+
+// adfListTypes lists the nodes written as Markdown lists
+var adfListTypes = map[string]bool{
+	"bulletList":   true,
+	"orderedList":  true,
+	"taskList":     true,
+	"decisionList": true,
+}
+
+// ADFToMarkdown converts an Atlassian Document Format document, as JSON, to
+// Markdown.
+func ADFToMarkdown(adf string) (string, error) {
+	return ADFToMarkdownWithOptions(adf, StorageOptions{})
+}
+
+// ADFToMarkdownWithOptions converts an Atlassian Document Format document,
+// as JSON, to Markdown, as StorageToMarkdownWithOptions converts the same
+// page in storage format. Macros without a body are converted as the
+// storage format macros they stand for. Nodes with no Markdown form, such
+// as extensions of apps, are kept as JSON in adf code fences, after the
+// paragraph or table of an inline node.
+func ADFToMarkdownWithOptions(adf string, opts StorageOptions) (string, error) {
+	doc, err := parseADF(adf)
+	if err != nil {
+		return "", err
+	}
+	r := &adfRenderer{opts: opts}
+	return r.blocks(doc.Content), nil
+}
+
+// parseADF parses an ADF document
+func parseADF(adf string) (adfNode, error) {
+	var doc adfNode
+	if err := json.Unmarshal([]byte(adf), &doc); err != nil {
+		return adfNode{}, fmt.Errorf("parsing ADF: %w", err)
+	}
+	if doc.Type != "doc" {
+		return adfNode{}, fmt.Errorf("parsing ADF: root node is %q, want \"doc\"", doc.Type)
+	}
+	return doc, nil
+}
+
+// adfRenderer writes ADF nodes as Markdown
+type adfRenderer struct {
+	opts StorageOptions
+
+	// pending holds the blocks for inline nodes kept as they are, written
+	// after the block holding them
+	pending []string
+}
+
+// blocks writes nodes as Markdown blocks separated by blank lines
+func (r *adfRenderer) blocks(nodes []adfNode) string {
+	var out []string
+	for _, n := range nodes {
+		if md := r.block(n); md != "" {
+			out = append(out, md)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// block writes a block node as Markdown
+func (r *adfRenderer) block(n adfNode) string {
+	switch n.Type {
+	case "paragraph":
+		return r.paragraph(n.Content)
+	case "heading":
+		level := min(max(adfInt(n.Attrs, "level"), 1), 6)
+		text := strings.ReplaceAll(r.inline(n.Content), hardBreak, " ")
+		if strings.TrimSpace(text) == "" {
+			return r.withPending("")
+		}
+		return r.withPending(strings.Repeat("#", level) + " " + strings.TrimSpace(text))
+	case "rule":
+		return "---"
+	case "blockquote":
+		return quote(r.blocks(n.Content))
+	case "bulletList", "orderedList", "decisionList":
+		return r.list(n)
+	case "taskList":
+		return r.taskList(n)
+	case "codeBlock":
+		var code strings.Builder
+		for _, c := range n.Content {
+			code.WriteString(c.Text)
+		}
+		return codeFence(adfString(n.Attrs, "language"), code.String())
+	case "panel":
+		macro, ok := adfPanelMacros[adfString(n.Attrs, "panelType")]
+		if !ok {
+			macro = "panel"
+		}
+		return r.panel(macro, "", r.blocks(n.Content))
+	case "expand", "nestedExpand":
+		return details(adfString(n.Attrs, "title"), r.blocks(n.Content))
+	case "table":
+		return r.withPending(r.table(n))
+	case "mediaSingle", "mediaGroup":
+		var images []string
+		for _, c := range n.Content {
+			if c.Type == "caption" {
+				continue
+			}
+			image, ok := r.media(c)
+			if !ok {
+				return verbatimADF(n)
+			}
+			images = append(images, image)
+		}
+		return strings.Join(images, " ")
+	case "blockCard", "embedCard":
+		link := adfString(n.Attrs, "url")
+		if link == "" {
+			return verbatimADF(n)
+		}
+		return markdownLink(escapeADFText(link), link)
+	case "extension", "bodiedExtension":
+		return r.extension(n, false)
+	case "layoutSection":
+		return r.layout(n)
+	}
+	return verbatimADF(n)
+}
+
+// paragraph writes inline nodes as a paragraph
+func (r *adfRenderer) paragraph(nodes []adfNode) string {
+	return r.withPending(strings.TrimSpace(escapeBlockStarts(r.inline(nodes))))
+}
+
+// withPending returns md followed by the blocks pending for its inline
+// nodes
+func (r *adfRenderer) withPending(md string) string {
+	if len(r.pending) == 0 {
+		return md
+	}
+	blocks := r.pending
+	r.pending = nil
+	if md != "" {
+		blocks = append([]string{md}, blocks...)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// list writes a bullet, ordered, or decision list. Decision lists, which
+// Markdown has no form of, are written as bullet lists.
+func (r *adfRenderer) list(n adfNode) string {
+	start := 1
+	if order, ok := n.Attrs["order"].(float64); ok && order >= 0 {
+		start = int(order)
+	}
+	var items []string
+	for i, item := range n.Content {
+		marker := "- "
+		if n.Type == "orderedList" {
+			marker = strconv.Itoa(start+i) + ". "
+		}
+		var body string
+		if item.Type == "decisionItem" {
+			body = r.paragraph(item.Content)
+		} else {
+			body = r.itemBody(item.Content)
+		}
+		items = append(items, listItem(marker, body))
+	}
+	return strings.Join(items, "\n")
+}
+
+// itemBody writes the blocks of a list item, with a list nested in the
+// item directly under the text before it
+func (r *adfRenderer) itemBody(nodes []adfNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		md := r.block(n)
+		if md == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			if adfListTypes[n.Type] {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(md)
+	}
+	return b.String()
+}
+
+// taskList writes a task list as [ ] and [x] items. A task list nested in
+// another follows the task it belongs to, so it is written under it.
+func (r *adfRenderer) taskList(n adfNode) string {
+	var items []string
+	for _, item := range n.Content {
+		switch item.Type {
+		case "taskItem":
+			box := "[ ] "
+			if adfString(item.Attrs, "state") == "DONE" {
+				box = "[x] "
+			}
+			items = append(items, listItem("- ", box+r.paragraph(item.Content)))
+		case "taskList":
+			nested := listItem("  ", r.taskList(item))
+			if len(items) == 0 {
+				items = append(items, strings.TrimPrefix(nested, "  "))
+				continue
+			}
+			items[len(items)-1] += "\n" + nested
+		default:
+			items = append(items, listItem("- ", verbatimADF(item)))
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+// listItem writes body as a list item with marker, indenting its later
+// lines to the item's content
+func listItem(marker, body string) string {
+	indent := strings.Repeat(" ", len(marker))
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = strings.TrimRight(marker+line, " ")
+			if line != "" {
+				lines[i] = marker + line
+			}
+		case line != "":
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quote writes md as a blockquote
+func quote(md string) string {
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeFence writes code as a fenced code block, with a fence longer than
+// any run of backticks in it
+func codeFence(lang, code string) string {
+	fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+	code = strings.TrimSuffix(code, "\n")
+	if code == "" {
+		return fence + lang + "\n" + fence
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// admonitionFenceLineRegex matches the colons opening a line of a ::: fence
+var admonitionFenceLineRegex = regexp.MustCompile(`(?m)^(:{3,})`)
+
+// panel writes the body of a panel macro as a GitHub alert, with its title
+// as a bold first line, or with admonitions as a ::: directive block
+func (r *adfRenderer) panel(macro, title, body string) string {
+	title = strings.TrimSpace(title)
+	if r.opts.Admonitions {
+		// A fence must be longer than any fence nested inside it
+		fence := 3
+		for _, m := range admonitionFenceLineRegex.FindAllStringSubmatch(body, -1) {
+			fence = max(fence, len(m[1])+1)
+		}
+		open := strings.Repeat(":", fence) + panelAdmonitions[macro]
+		if title != "" {
+			open += " " + title
+		}
+		return joinBlocks(open, body, strings.Repeat(":", fence))
+	}
+	alert := "[!" + panelAlerts[macro] + "]"
+	if title != "" {
+		return quote(alert + "\n" + joinBlocks("**"+escapeADFText(title)+"**", body))
+	}
+	return quote(alert + "\n" + body)
+}
+
+// details writes an expand as a <details> section
+func details(title, body string) string {
+	open := "<details>"
+	if title = strings.TrimSpace(title); title != "" {
+		open += "<summary>" + title + "</summary>"
+	}
+	return joinBlocks(open, body, "</details>")
+}
+
+// joinBlocks joins the blocks that are not empty with blank lines
+func joinBlocks(blocks ...string) string {
+	var out []string
+	for _, b := range blocks {
+		if b != "" {
+			out = append(out, b)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// table writes a table as a GFM table, whose first row is its header row.
+// Merged cells are written as the cell followed by empty cells.
+func (r *adfRenderer) table(n adfNode) string {
+	var rows [][]string
+	spans := map[int]int{} // column → rows still covered by a cell above
+	for _, row := range n.Content {
+		if row.Type != "tableRow" {
+			continue
+		}
+		var cells []string
+		col := 0
+		next := func() {
+			for spans[col] > 0 {
+				spans[col]--
+				cells = append(cells, "")
+				col++
+			}
+		}
+		for _, cell := range row.Content {
+			next()
+			cells = append(cells, r.tableCell(cell))
+			colspan, rowspan := max(adfInt(cell.Attrs, "colspan"), 1), max(adfInt(cell.Attrs, "rowspan"), 1)
+			for i := 0; i < colspan; i++ {
+				if i > 0 {
+					cells = append(cells, "")
+				}
+				if rowspan > 1 {
+					spans[col] = rowspan - 1
+				}
+				col++
+			}
+		}
+		next()
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, cells := range rows {
+		width = max(width, len(cells))
+	}
+	var b strings.Builder
+	for i, cells := range rows {
+		for len(cells) < width {
+			cells = append(cells, "")
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("|---", width) + "|\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// tableCell writes the content of a table cell on one line, with its
+// blocks separated by spaces and pipes escaped
+func (r *adfRenderer) tableCell(cell adfNode) string {
+	var parts []string
+	for _, c := range cell.Content {
+		var md string
+		if c.Type == "paragraph" {
+			// Kept verbatim nodes go after the table
+			md = r.inline(c.Content)
+		} else {
+			md = r.block(c)
+		}
+		if md = strings.Join(strings.Fields(md), " "); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	return strings.ReplaceAll(strings.Join(parts, " "), "|", `\|`)
+}
+
+// layout writes the columns of a layout section in order, separated by
+// horizontal rules, or in round-trip mode as :::columns fences
+func (r *adfRenderer) layout(n adfNode) string {
+	var cells []string
+	var widths []float64
+	for _, col := range n.Content {
+		if col.Type != "layoutColumn" {
+			continue
+		}
+		cells = append(cells, r.blocks(col.Content))
+		width, _ := col.Attrs["width"].(float64)
+		widths = append(widths, width)
+	}
+	if len(cells) < 2 {
+		return joinBlocks(cells...)
+	}
+	variant, ok := columnsVariant(widths)
+	if !r.opts.RoundTrip || !ok {
+		var out []string
+		for _, cell := range cells {
+			if cell != "" {
+				out = append(out, cell)
+			}
+		}
+		return strings.Join(out, "\n\n---\n\n")
+	}
+
+	// A column fence must be longer than any fence nested inside it, and
+	// the section fence longer again
+	fence := 3
+	for _, cell := range cells {
+		for _, m := range admonitionFenceLineRegex.FindAllStringSubmatch(cell, -1) {
+			fence = max(fence, len(m[1])+1)
+		}
+	}
+	column := strings.Repeat(":", fence)
+	open := strings.Repeat(":", fence+1) + "columns"
+	if variant != "" {
+		open += " " + variant
+	}
+	blocks := []string{open}
+	for _, cell := range cells {
+		blocks = append(blocks, column+"column", cell, column)
+	}
+	blocks = append(blocks, strings.Repeat(":", fence+1))
+	return joinBlocks(blocks...)
+}
+
+// columnsVariant returns the :::columns variant for columns of the given
+// widths: a narrower first or last column of two is a sidebar, and a wider
+// middle column of three has sidebars. It reports false for more than
+// three columns.
+func columnsVariant(widths []float64) (string, bool) {
+	switch {
+	case len(widths) > 3:
+		return "", false
+	case len(widths) == 2 && widths[0] < widths[1]:
+		return "left-sidebar", true
+	case len(widths) == 2 && widths[0] > widths[1]:
+		return "right-sidebar", true
+	case len(widths) == 3 && widths[1] > widths[0] && widths[1] > widths[2]:
+		return "sidebars", true
+	}
+	return "", true
+}
+
+// media writes an image. External images keep their URL, and files link
+// to the attachment in AttachmentDir. It reports false for other media.
+func (r *adfRenderer) media(n adfNode) (string, bool) {
+	if n.Type != "media" && n.Type != "mediaInline" {
+		return "", false
+	}
+	if adfString(n.Attrs, "type") == "external" {
+		src := adfString(n.Attrs, "url")
+		return "![](" + src + ")", src != ""
+	}
+	filename := adfFilename(n)
+	if filename == "" {
+		return "", false
+	}
+	dir := r.opts.AttachmentDir
+	if dir == "" {
+		dir = AttachmentDir
+	}
+	alt := strings.TrimSuffix(filename, path.Ext(filename))
+	return "![" + escapeADFText(alt) + "](" + dir + "/" + url.PathEscape(filename) + ")", true
+}
+
+// adfFilename returns the filename of a media node of a file: the
+// __fileName attribute Confluence adds, or else the alt text, which it sets
+// to the filename
+func adfFilename(n adfNode) string {
+	if adfString(n.Attrs, "type") != "file" {
+		return ""
+	}
+	if filename := adfString(n.Attrs, "__fileName"); filename != "" {
+		return filename
+	}
+	return adfString(n.Attrs, "alt")
+}
+
+// extension writes a macro extension. Panel and expand macros are written
+// as their ADF nodes are. Other macros with a body, and extensions that are
+// not macros, are kept as they are. A macro without a body is converted as
+// the storage format macro it stands for, so it becomes what
+// StorageToMarkdown makes of it, or a confluence-macro fence.
+func (r *adfRenderer) extension(n adfNode, inline bool) string {
+	name := adfString(n.Attrs, "extensionKey")
+	if adfString(n.Attrs, "extensionType") != adfMacroExtension || name == "" {
+		return r.verbatim(n, inline)
+	}
+	params := adfMacroParams(n)
+	if n.Type == "bodiedExtension" {
+		if name == "expand" {
+			return details(params["title"], r.blocks(n.Content))
+		}
+		if _, ok := panelAlerts[name]; ok {
+			return r.panel(name, params["title"], r.blocks(n.Content))
+		}
+		return r.verbatim(n, inline)
+	}
+
+	storage := adfMacroStorage(name, params)
+	if inline {
+		storage = "<p>" + storage + "</p>"
+	}
+	md, err := StorageToMarkdownWithOptions(storage, r.opts)
+	if err != nil {
+		return r.verbatim(n, inline)
+	}
+	if inline && strings.Contains(md, "\n") {
+		r.pending = append(r.pending, md)
+		return macroLabel(storage)
+	}
+	return md
+}
+
+// adfMacroParams returns the parameters of a macro extension
+func adfMacroParams(n adfNode) map[string]string {
+	params := map[string]string{}
+	values, _ := n.Attrs["parameters"].(map[string]any)
+	macroParams, _ := values["macroParams"].(map[string]any)
+	for name, param := range macroParams {
+		value, _ := param.(map[string]any)
+		params[name] = adfString(value, "value")
+	}
+	return params
+}
+
+// adfMacroStorage writes a macro without a body as storage format
+func adfMacroStorage(name string, params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for param := range params {
+		names = append(names, param)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(`<ac:structured-macro ac:name="` + html.EscapeString(name) + `">`)
+	for _, param := range names {
+		if params[param] != "" {
+			b.WriteString(`<ac:parameter ac:name="` + html.EscapeString(param) + `">` + html.EscapeString(params[param]) + "</ac:parameter>")
+		}
+	}
+	b.WriteString("</ac:structured-macro>")
+	return b.String()
+}
+
+// verbatim keeps n as it is in an adf fence. An inline node leaves a
+// comment naming it, and its fence follows the block holding it.
+func (r *adfRenderer) verbatim(n adfNode, inline bool) string {
+	if !inline {
+		return verbatimADF(n)
+	}
+	r.pending = append(r.pending, verbatimADF(n))
+	return "<!-- ADF node: " + n.Type + " -->"
+}
+
+// verbatimADF writes n as JSON in an adf fence
+func verbatimADF(n adfNode) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(n) // a decoded node always encodes
+	return codeFence(adfFenceLang, b.String())
+}
+
+// ADFImageAttachments returns the filenames of the attachments of page
+// pageID shown as images in an ADF document, each once, in the order they
+// first appear. Attachments of other pages, named by a media node's
+// collection, are left out.
+func ADFImageAttachments(adf, pageID string) []string {
+	doc, err := parseADF(adf)
+	if err != nil {
+		return nil
+	}
+	var filenames []string
+	seen := map[string]bool{}
+	var walk func(nodes []adfNode)
+	walk = func(nodes []adfNode) {
+		for _, n := range nodes {
+			owner, ok := strings.CutPrefix(adfString(n.Attrs, "collection"), "contentId-")
+			if (n.Type == "media" || n.Type == "mediaInline") && (!ok || owner == pageID) {
+				if filename := adfFilename(n); filename != "" && !seen[filename] {
+					seen[filename] = true
+					filenames = append(filenames, filename)
+				}
+			}
+			walk(n.Content)
+		}
+	}
+	walk(doc.Content)
+	return filenames
+}
+
+// adfString returns the string attribute key of attrs, or ""
+func adfString(attrs map[string]any, key string) string {
+	switch v := attrs[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// adfInt returns the number attribute key of attrs, or 0
+func adfInt(attrs map[string]any, key string) int {
+	n, _ := strconv.Atoi(adfString(attrs, key))
+	return n
+}
+
+// adfDate returns the ISO date of a date node's timestamp, in
+// milliseconds, or ""
+func adfDate(attrs map[string]any) string {
+	ms, err := strconv.ParseInt(adfString(attrs, "timestamp"), 10, 64)
+	if err != nil {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.DateOnly)
+}
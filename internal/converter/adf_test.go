@@ -0,0 +1,209 @@
+package converter
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestADFToMarkdown(t *testing.T) {
+	text := func(s string) string { return `{"type":"text","text":"` + s + `"}` }
+	para := func(content ...string) string {
+		return `{"type":"paragraph","content":[` + strings.Join(content, ",") + `]}`
+	}
+	doc := func(content ...string) string {
+		return `{"type":"doc","version":1,"content":[` + strings.Join(content, ",") + `]}`
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "heading", input: doc(`{"type":"heading","attrs":{"level":2},"content":[` + text("Title") + `]}`), want: "## Title"},
+		{
+			name:  "marks",
+			input: doc(para(`{"type":"text","text":"bold","marks":[{"type":"strong"}]}`, text(" "), `{"type":"text","text":"site","marks":[{"type":"link","attrs":{"href":"https://x.com"}},{"type":"em"}]}`)),
+			want:  "**bold** [*site*](https://x.com)",
+		},
+		{
+			name:  "inline nodes",
+			input: doc(para(`{"type":"mention","attrs":{"id":"abc","text":"@Jane Doe"}}`, text(" "), `{"type":"emoji","attrs":{"shortName":":smile:","text":"😄"}}`, text(" "), `{"type":"date","attrs":{"timestamp":"1740787200000"}}`)),
+			want:  "@Jane Doe 😄 2025-03-01",
+		},
+		{name: "status", input: doc(para(`{"type":"status","attrs":{"text":"DONE","color":"green"}}`)), opts: StorageOptions{RoundTrip: true}, want: "{status:green|DONE}"},
+		{
+			name:  "ordered list",
+			input: doc(`{"type":"orderedList","attrs":{"order":3},"content":[{"type":"listItem","content":[` + para(text("three")) + `]}]}`),
+			want:  "3. three",
+		},
+		{
+			name:  "code block",
+			input: doc(`{"type":"codeBlock","attrs":{"language":"go"},"content":[` + text(`x := ]]>`) + `]}`),
+			want:  "```go\nx := ]]>\n```",
+		},
+		{name: "panel", input: doc(`{"type":"panel","attrs":{"panelType":"warning"},"content":[` + para(text("careful")) + `]}`), want: "> [!WARNING]\n> careful"},
+		{name: "expand", input: doc(`{"type":"expand","attrs":{"title":"More"},"content":[` + para(text("hidden")) + `]}`), want: "<details><summary>More</summary>\n\nhidden\n\n</details>"},
+		{
+			name:  "task list",
+			input: doc(`{"type":"taskList","content":[{"type":"taskItem","attrs":{"state":"DONE"},"content":[` + text("done") + `]},{"type":"taskItem","attrs":{"state":"TODO"},"content":[` + text("todo") + `]}]}`),
+			want:  "- [x] done\n- [ ] todo",
+		},
+		{
+			name: "table",
+			input: doc(`{"type":"table","content":[` +
+				`{"type":"tableRow","content":[{"type":"tableHeader","content":[` + para(text("A")) + `]},{"type":"tableHeader","content":[` + para(text("B")) + `]}]},` +
+				`{"type":"tableRow","content":[{"type":"tableCell","content":[` + para(text("1")) + `]},{"type":"tableCell","content":[` + para(text("2")) + `]}]}]}`),
+			want: "| A | B |\n|---|---|\n| 1 | 2 |",
+		},
+		{
+			name:  "known macro extension",
+			input: doc(`{"type":"extension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"toc","parameters":{"macroParams":{}}}}`),
+			opts:  StorageOptions{RoundTrip: true},
+			want:  "[TOC]",
+		},
+		{
+			name:  "unknown macro extension kept",
+			input: doc(`{"type":"extension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"chart","parameters":{"macroParams":{"type":{"value":"pie"}}}}}`),
+			want:  "```confluence-macro\n<!-- Confluence macro: chart -->\n<ac:structured-macro ac:name=\"chart\"><ac:parameter ac:name=\"type\">pie</ac:parameter></ac:structured-macro>\n```",
+		},
+		{name: "embed card", input: doc(`{"type":"embedCard","attrs":{"url":"https://x.net/wb/1"}}`), want: "[https://x.net/wb/1](https://x.net/wb/1)"},
+		{name: "attached image", input: doc(`{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"f","alt":"chart.png"}}]}`), want: "![chart](attachments/chart.png)"},
+		{
+			name:  "unknown node kept",
+			input: doc(`{"type":"whiteboard","attrs":{"id":"1"}}`),
+			want:  "```adf\n{\n  \"type\": \"whiteboard\",\n  \"attrs\": {\n    \"id\": \"1\"\n  }\n}\n```",
+		},
+		{
+			name:  "unknown inline node kept after its paragraph",
+			input: doc(para(text("see "), `{"type":"sketch"}`)),
+			want:  "see <!-- ADF node: sketch -->\n\n```adf\n{\n  \"type\": \"sketch\"\n}\n```",
+		},
+		{
+			name:  "app extension kept",
+			input: doc(`{"type":"extension","attrs":{"extensionType":"com.atlassian.ecosystem","extensionKey":"app"}}`),
+			want:  "```adf\n{\n  \"type\": \"extension\",\n  \"attrs\": {\n    \"extensionKey\": \"app\",\n    \"extensionType\": \"com.atlassian.ecosystem\"\n  }\n}\n```",
+		},
+		{
+			name:  "bodied panel macro",
+			input: doc(`{"type":"bodiedExtension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"info","parameters":{"macroParams":{"title":{"value":"Heads up"}}}},"content":[` + para(text("body")) + `]}`),
+			want:  "> [!NOTE]\n> **Heads up**\n>\n> body",
+		},
+		{
+			name:  "panel as admonition",
+			input: doc(`{"type":"panel","attrs":{"panelType":"info"},"content":[` + para(text("x")) + `]}`),
+			opts:  StorageOptions{Admonitions: true},
+			want:  ":::info\n\nx\n\n:::",
+		},
+		{
+			name:  "marks shared across text",
+			input: doc(para(`{"type":"text","text":"a ","marks":[{"type":"strong"}]}`, `{"type":"text","text":"b","marks":[{"type":"strong"},{"type":"em"}]}`, text(" "), `{"type":"text","text":"x_y","marks":[{"type":"code"}]}`)),
+			want:  "**a *b*** `x_y`",
+		},
+		{
+			name:  "underline kept with KeepHTML",
+			input: doc(para(`{"type":"text","text":"u","marks":[{"type":"underline"}]}`, text(" "), `{"type":"text","text":"plain","marks":[{"type":"subsup","attrs":{"type":"sup"}}]}`)),
+			opts:  StorageOptions{KeepHTML: true},
+			want:  "<u>u</u> plain",
+		},
+		{
+			name:  "escaped text",
+			input: doc(para(text(`a * b _c_ x_y [z] <q> # h`)), para(text("# not")), para(text("1. no"))),
+			want:  "a \\* b \\_c\\_ x_y \\[z] \\<q> # h\n\n\\# not\n\n1\\. no",
+		},
+		{name: "hard break", input: doc(para(text("a"), `{"type":"hardBreak"}`, text("b"))), want: "a  \nb"},
+		{
+			name: "nested list",
+			input: doc(`{"type":"bulletList","content":[{"type":"listItem","content":[` + para(text("a")) +
+				`,{"type":"bulletList","content":[{"type":"listItem","content":[` + para(text("b")) + `]}]}]},` +
+				`{"type":"listItem","content":[` + para(text("c")) + `,` + para(text("d")) + `]}]}`),
+			want: "- a\n  - b\n- c\n\n  d",
+		},
+		{
+			name: "merged cells padded",
+			input: doc(`{"type":"table","content":[` +
+				`{"type":"tableRow","content":[{"type":"tableHeader","content":[` + para(text("A")) + `]},{"type":"tableHeader","content":[` + para(text("B|C")) + `]}]},` +
+				`{"type":"tableRow","content":[{"type":"tableCell","attrs":{"colspan":2},"content":[` + para(text("x")) + `]}]}]}`),
+			want: "| A | B\\|C |\n|---|---|\n| x |  |",
+		},
+		{
+			name: "layout as columns",
+			input: doc(`{"type":"layoutSection","content":[` +
+				`{"type":"layoutColumn","attrs":{"width":33.33},"content":[` + para(text("a")) + `]},` +
+				`{"type":"layoutColumn","attrs":{"width":66.66},"content":[` + para(text("b")) + `]}]}`),
+			opts: StorageOptions{RoundTrip: true},
+			want: "::::columns left-sidebar\n\n:::column\n\na\n\n:::\n\n:::column\n\nb\n\n:::\n\n::::",
+		},
+		{
+			name:  "layout flattened",
+			input: doc(`{"type":"layoutSection","content":[{"type":"layoutColumn","content":[` + para(text("a")) + `]},{"type":"layoutColumn","content":[` + para(text("b")) + `]}]}`),
+			want:  "a\n\n---\n\nb",
+		},
+		{
+			name:  "round-trip mention and date",
+			input: doc(para(`{"type":"mention","attrs":{"id":"abc"}}`, text(" "), `{"type":"date","attrs":{"timestamp":"1740787200000"}}`)),
+			opts:  StorageOptions{RoundTrip: true, ResolveUserName: func(id string) (string, error) { return "Jane Doe", nil }},
+			want:  "@{Jane Doe} {date:2025-03-01}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ADFToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("ADFToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFToMarkdown_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "not JSON", input: "<p>x</p>", wantErr: "parsing ADF"},
+		{name: "not a document", input: `{"type":"paragraph"}`, wantErr: `root node is "paragraph"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ADFToMarkdown(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestADFToMarkdown_CloudPage(t *testing.T) {
+	// The ADF body of a page saved by the Confluence Cloud editor, with a
+	// macro, an app extension kept as it is, and an attached image
+	adf, err := os.ReadFile("../../testdata/cloud-page.adf.json")
+	if err != nil {
+		t.Fatalf("reading test data: %v", err)
+	}
+	want, err := os.ReadFile("../../testdata/cloud-page.adf.md")
+	if err != nil {
+		t.Fatalf("reading test data: %v", err)
+	}
+	got, err := ADFToMarkdown(string(adf))
+	if err != nil {
+		t.Fatalf("ADFToMarkdown() error = %v", err)
+	}
+	if got != strings.TrimSuffix(string(want), "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := ADFImageAttachments(string(adf), "123"), []string{"diagram.png"}; !slices.Equal(got, want) {
+		t.Errorf("ADFImageAttachments() = %q, want %q", got, want)
+	}
+	if got := ADFImageAttachments(string(adf), "456"); len(got) != 0 {
+		t.Errorf("ADFImageAttachments() of another page = %q, want none", got)
+	}
+}
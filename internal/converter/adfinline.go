@@ -0,0 +1,253 @@
+package converter
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// hardBreak is the Markdown for a line break within a paragraph
+const hardBreak = "  \n"
+
+// inline writes inline nodes as Markdown. A mark shared by a run of nodes
+// is written once around the run, taking first the mark of the longest run.
+// Code marks are innermost, as code spans hold no other formatting.
+func (r *adfRenderer) inline(nodes []adfNode) string {
+	var b strings.Builder
+	for i := 0; i < len(nodes); {
+		mark, end := r.widestMark(nodes, i)
+		if end == i {
+			b.WriteString(r.inlineNode(nodes[i]))
+			i++
+			continue
+		}
+		run := make([]adfNode, end-i)
+		for j := range run {
+			run[j] = withoutMark(nodes[i+j], mark)
+		}
+		open, closing, _ := r.markDelimiters(mark)
+		b.WriteString(wrapMarkdown(open, closing, r.inline(run)))
+		i = end
+	}
+	return b.String()
+}
+
+// widestMark returns the mark of nodes[i] that the longest run of nodes
+// from i shares, and the end of the run. The end is i if nodes[i] has no
+// mark written around text.
+func (r *adfRenderer) widestMark(nodes []adfNode, i int) (adfMark, int) {
+	var widest adfMark
+	end := i
+	for _, m := range nodes[i].Marks {
+		if _, _, ok := r.markDelimiters(m); !ok {
+			continue
+		}
+		j := i + 1
+		for j < len(nodes) && hasMark(nodes[j], m) {
+			j++
+		}
+		if j > end {
+			widest, end = m, j
+		}
+	}
+	return widest, end
+}
+
+// markDelimiters returns the Markdown written around text with mark m. It
+// reports false for code, which inlineNode writes, and for marks Markdown
+// has no form of, such as colours without KeepHTML, whose text is written
+// plain.
+func (r *adfRenderer) markDelimiters(m adfMark) (string, string, bool) {
+	switch m.Type {
+	case "strong":
+		return "**", "**", true
+	case "em":
+		return "*", "*", true
+	case "strike":
+		return "~~", "~~", true
+	case "link":
+		return "[", "](" + markdownURL(adfString(m.Attrs, "href")) + ")", true
+	case "underline":
+		return "<u>", "</u>", r.opts.KeepHTML
+	case "textColor":
+		colour := adfString(m.Attrs, "color")
+		return `<span style="color: ` + colour + `">`, "</span>", r.opts.KeepHTML && colour != ""
+	}
+	return "", "", false
+}
+
+// hasMark reports whether n has mark m
+func hasMark(n adfNode, m adfMark) bool {
+	for _, mark := range n.Marks {
+		if mark.Type == m.Type && reflect.DeepEqual(mark.Attrs, m.Attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutMark returns n without mark m
+func withoutMark(n adfNode, m adfMark) adfNode {
+	var marks []adfMark
+	for _, mark := range n.Marks {
+		if mark.Type != m.Type {
+			marks = append(marks, mark)
+		}
+	}
+	n.Marks = marks
+	return n
+}
+
+// wrapMarkdown writes open and closing around s, leaving out the
+// whitespace at either end, which would keep them from being read as
+// emphasis
+func wrapMarkdown(open, closing, s string) string {
+	core := strings.TrimSpace(s)
+	if core == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, core)]
+	return lead + open + core + closing + s[len(lead)+len(core):]
+}
+
+// inlineNode writes an inline node, other than its marks, as Markdown
+func (r *adfRenderer) inlineNode(n adfNode) string {
+	switch n.Type {
+	case "text":
+		for _, m := range n.Marks {
+			if m.Type == "code" {
+				return codeSpan(n.Text)
+			}
+		}
+		return escapeADFText(n.Text)
+	case "hardBreak":
+		return hardBreak
+	case "mention":
+		name := strings.TrimPrefix(adfString(n.Attrs, "text"), "@")
+		if name == "" {
+			name = adfString(n.Attrs, "id")
+			if r.opts.ResolveUserName != nil {
+				if resolved, err := r.opts.ResolveUserName(name); err == nil && strings.TrimSpace(resolved) != "" {
+					name = strings.TrimSpace(resolved)
+				}
+			}
+		}
+		if r.opts.RoundTrip {
+			return "@{" + name + "}"
+		}
+		return "@" + escapeADFText(name)
+	case "emoji":
+		if text := adfString(n.Attrs, "text"); text != "" {
+			return text
+		}
+		return adfString(n.Attrs, "shortName")
+	case "date":
+		date := adfDate(n.Attrs)
+		if r.opts.RoundTrip && date != "" {
+			return "{date:" + date + "}"
+		}
+		return date
+	case "status":
+		return r.status(n)
+	case "inlineCard":
+		link := adfString(n.Attrs, "url")
+		if link == "" {
+			return r.verbatim(n, true)
+		}
+		return markdownLink(escapeADFText(link), link)
+	case "media", "mediaInline":
+		if image, ok := r.media(n); ok {
+			return image
+		}
+	case "inlineExtension":
+		return r.extension(n, true)
+	case "placeholder":
+		// Prompt text shown by the editor in an empty spot, not content
+		return ""
+	}
+	return r.verbatim(n, true)
+}
+
+// status writes a status lozenge as its text in bold brackets, or in
+// round-trip mode as a {status:...} shortcode. A status without text is
+// dropped.
+func (r *adfRenderer) status(n adfNode) string {
+	text := strings.TrimSpace(adfString(n.Attrs, "text"))
+	if text == "" {
+		return ""
+	}
+	if !r.opts.RoundTrip {
+		return "**[" + escapeADFText(text) + "]**"
+	}
+	colour := strings.ToLower(adfString(n.Attrs, "color"))
+	if _, ok := statusColours[colour]; !ok || colour == "grey" {
+		return "{status:" + text + "}"
+	}
+	return "{status:" + colour + "|" + text + "}"
+}
+
+// codeSpan writes text as a code span, with delimiters longer than any run
+// of backticks in it
+func codeSpan(text string) string {
+	ticks := strings.Repeat("`", longestRun(text, '`')+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return ticks + " " + text + " " + ticks
+	}
+	return ticks + text + ticks
+}
+
+// markdownLink writes a link to href with text, which is already Markdown
+func markdownLink(text, href string) string {
+	return "[" + text + "](" + markdownURL(href) + ")"
+}
+
+// markdownURL escapes the characters that would end a link destination
+var markdownURL = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E").Replace
+
+// escapeADFText escapes the characters of text that Markdown would read
+// as formatting. Underscores within words are left alone.
+func escapeADFText(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\\', '`', '*', '[':
+			b.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(text)-1 || !isAlphanumeric(text[i-1]) || !isAlphanumeric(text[i+1]) {
+				b.WriteByte('\\')
+			}
+		case '<':
+			if i+1 < len(text) && (isAlphanumeric(text[i+1]) || strings.IndexByte("/!?", text[i+1]) >= 0) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// lineIndentRegex matches the indent of a line
+var lineIndentRegex = regexp.MustCompile(`(?m)^[ \t]+`)
+
+// blockStartRegex matches the start of a line that Markdown would read as
+// a heading, quote, list item, thematic break, or heading underline
+var blockStartRegex = regexp.MustCompile(`(?m)^(#{1,6}(?:[ \t]|$)|>|[-+](?:[ \t]|$)|[-=]+[ \t]*$|\d{1,9}[.)](?:[ \t]|$))`)
+
+// escapeBlockStarts removes the indent of each line of a paragraph, which
+// could make it a code block, and escapes the starts of lines Markdown
+// would read as another block
+func escapeBlockStarts(md string) string {
+	md = lineIndentRegex.ReplaceAllString(md, "")
+	return blockStartRegex.ReplaceAllStringFunc(md, func(match string) string {
+		if i := strings.IndexAny(match, ".)"); i > 0 && match[0] >= '0' && match[0] <= '9' {
+			return match[:i] + `\` + match[i:]
+		}
+		return `\` + match
+	})
+}
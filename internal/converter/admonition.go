@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// admonitionMacros maps ::: directive types to Confluence panel macros
+var admonitionMacros = map[string]string{
+	"info":    "info",
+	"note":    "note",
+	"tip":     "tip",
+	"warning": "warning",
+}
+
+// kindAdmonition is the node kind for ::: directive blocks
+var kindAdmonition = ast.NewNodeKind("Admonition")
+
+// admonition is a fenced directive block:
+//
+//	:::info Optional title
+//	Markdown content
+//	:::
+//
+// The closing fence must be at least as long as the opening one, so an outer
+// block can use more colons to contain an inner one.
+type admonition struct {
+	ast.BaseBlock
+	macro       string
+	title       string
+	fenceLength int
+	open        bool // Until its closing fence, see holdsOpenDirective
+}
+
+func (n *admonition) Kind() ast.NodeKind {
+	return kindAdmonition
+}
+
+func (n *admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Macro": n.macro, "Title": n.title}, nil)
+}
+
+// admonitionParser parses ::: directive blocks into admonition nodes
+type admonitionParser struct{}
+
+func newAdmonitionParser() parser.BlockParser {
+	return &admonitionParser{}
+}
+
+func (b *admonitionParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (b *admonitionParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	fence := colonRun(line[pos:])
+	if fence < 3 {
+		return nil, parser.NoChildren
+	}
+	kind, title, _ := strings.Cut(strings.TrimSpace(string(line[pos+fence:])), " ")
+	macro, ok := admonitionMacros[strings.ToLower(kind)]
+	if !ok {
+		return nil, parser.NoChildren
+	}
+
+	// Consume the opening fence line so its text is not parsed as content
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return &admonition{macro: macro, title: strings.TrimSpace(title), fenceLength: fence, open: true}, parser.HasChildren
+}
+
+func (b *admonitionParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	if closeDirective(reader, node.(*admonition).fenceLength) {
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (b *admonitionParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	node.(*admonition).open = false
+}
+
+func (b *admonitionParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b *admonitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// closeDirective consumes the next line if it is a closing fence for a
+// directive opened with fenceLength colons
+func closeDirective(reader text.Reader, fenceLength int) bool {
+	line, segment := reader.PeekLine()
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w >= 4 || pos >= len(line) {
+		return false
+	}
+	fence := colonRun(line[pos:])
+	if fence < fenceLength || !util.IsBlank(line[pos+fence:]) {
+		return false
+	}
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return true
+}
+
+// colonRun returns the number of leading ':' characters in line
+func colonRun(line []byte) int {
+	n := 0
+	for n < len(line) && line[n] == ':' {
+		n++
+	}
+	return n
+}
+
+// trailingNewline returns 1 if line ends with a newline, else 0
+func trailingNewline(line []byte) int {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		return 1
+	}
+	return 0
+}
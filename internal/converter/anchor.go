@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// anchorPrefix opens an anchor shortcode
+var anchorPrefix = []byte("{anchor:")
+
+// kindAnchor is the node kind for named anchors
+var kindAnchor = ast.NewNodeKind("Anchor")
+
+// anchor is a link target written as {anchor:name}, so [text](#name) can
+// link to any point in the page rather than only to headings
+type anchor struct {
+	ast.BaseInline
+	name string
+}
+
+func (n *anchor) Kind() ast.NodeKind {
+	return kindAnchor
+}
+
+func (n *anchor) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.name}, nil)
+}
+
+// anchorParser parses {anchor:name} shortcodes into anchor nodes
+type anchorParser struct{}
+
+func newAnchorParser() parser.InlineParser {
+	return &anchorParser{}
+}
+
+func (p *anchorParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *anchorParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if len(line) < len(anchorPrefix) || !bytes.EqualFold(line[:len(anchorPrefix)], anchorPrefix) {
+		return nil
+	}
+	end := bytes.IndexByte(line, '}')
+	if end < 0 {
+		return nil
+	}
+	name := string(bytes.TrimSpace(line[len(anchorPrefix):end]))
+	if name == "" {
+		return nil
+	}
+	block.Advance(end + 1)
+	return &anchor{name: name}
+}
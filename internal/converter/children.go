@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// childrenPrefix opens a children shortcode
+var childrenPrefix = []byte("{children")
+
+// childrenSorts maps shortcode sort orders to the children macro's values
+var childrenSorts = map[string]string{
+	"title":    "title",
+	"created":  "creation",
+	"creation": "creation",
+	"modified": "modified",
+}
+
+// kindChildren is the node kind for child page lists
+var kindChildren = ast.NewNodeKind("Children")
+
+// children marks where the children display macro goes. A depth of 0
+// leaves the macro's default, and all lists every descendant.
+type children struct {
+	ast.BaseBlock
+	depth   int
+	all     bool
+	sort    string
+	reverse bool
+}
+
+func (n *children) Kind() ast.NodeKind {
+	return kindChildren
+}
+
+func (n *children) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Depth": strconv.Itoa(n.depth),
+		"Sort":  n.sort,
+	}, nil)
+}
+
+// childrenTransformer replaces paragraphs holding only a children shortcode
+// with children nodes:
+//
+//	{children}
+//	{children depth=2 sort=modified reverse}
+//
+// depth is a number or "all", and sort is title, created, or modified.
+// Invalid values are dropped.
+type childrenTransformer struct{}
+
+func newChildrenTransformer() parser.ASTTransformer {
+	return &childrenTransformer{}
+}
+
+func (t *childrenTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var paragraphs []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == ast.KindParagraph && n.Lines().Len() == 1 {
+			paragraphs = append(paragraphs, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, p := range paragraphs {
+		line := p.Lines().At(0)
+		if node, ok := parseChildren(bytes.TrimSpace(line.Value(source))); ok {
+			p.Parent().ReplaceChild(p.Parent(), p, node)
+		}
+	}
+}
+
+// parseChildren parses a children shortcode
+func parseChildren(s []byte) (*children, bool) {
+	if len(s) <= len(childrenPrefix) || !bytes.EqualFold(s[:len(childrenPrefix)], childrenPrefix) || s[len(s)-1] != '}' {
+		return nil, false
+	}
+	rest := s[len(childrenPrefix) : len(s)-1]
+	if len(rest) > 0 && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false // {childrenX}
+	}
+	node := &children{}
+	for key, value := range parseAttrPairs(string(rest)) {
+		switch key {
+		case "depth":
+			if strings.EqualFold(value, "all") {
+				node.all = true
+			} else if d, err := strconv.Atoi(value); err == nil && d > 0 {
+				node.depth = d
+			}
+		case "sort":
+			node.sort = childrenSorts[strings.ToLower(value)]
+		case "reverse":
+			node.reverse = strings.EqualFold(value, "true")
+		}
+	}
+	return node, true
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// codeAttrs are code macro parameters set in a fence info string:
+//
+//	```go {title="main.go" linenumbers=true collapse=true theme=Midnight}
+type codeAttrs struct {
+	title       string
+	lineNumbers string
+	collapse    string
+	theme       string
+}
+
+// codeFenceInfo splits a fence info string into its language and the
+// attributes in a trailing {...} block. Unknown attributes are ignored.
+func codeFenceInfo(info []byte) (string, codeAttrs) {
+	info = bytes.TrimSpace(info)
+	var attrs codeAttrs
+	if open := bytes.IndexByte(info, '{'); open >= 0 && bytes.HasSuffix(info, []byte("}")) {
+		attrs = parseCodeAttrs(string(info[open+1 : len(info)-1]))
+		info = bytes.TrimSpace(info[:open])
+	}
+	lang := info
+	if i := bytes.IndexFunc(info, unicode.IsSpace); i >= 0 {
+		lang = info[:i]
+	}
+	return string(lang), attrs
+}
+
+// parseCodeAttrs reads the code macro parameters from a {...} block
+func parseCodeAttrs(s string) codeAttrs {
+	var attrs codeAttrs
+	for key, value := range parseAttrPairs(s) {
+		switch key {
+		case "title":
+			attrs.title = value
+		case "linenumbers":
+			attrs.lineNumbers = codeBool(value)
+		case "collapse":
+			attrs.collapse = codeBool(value)
+		case "theme":
+			attrs.theme = value
+		}
+	}
+	return attrs
+}
+
+// parseAttrPairs reads space-separated key=value pairs, with values bare or
+// in double or single quotes, keyed by lower-case name. A bare key means
+// key=true.
+func parseAttrPairs(s string) map[string]string {
+	pairs := map[string]string{}
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return pairs
+		}
+		end := strings.IndexFunc(s, func(r rune) bool { return r == '=' || unicode.IsSpace(r) })
+		if end < 0 {
+			end = len(s)
+		}
+		key := strings.ToLower(s[:end])
+		s = s[end:]
+
+		value := "true"
+		if strings.HasPrefix(s, "=") {
+			s = s[1:]
+			if s != "" && (s[0] == '"' || s[0] == '\'') {
+				if closing := strings.IndexByte(s[1:], s[0]); closing >= 0 {
+					value, s = s[1:1+closing], s[2+closing:]
+				} else {
+					value, s = s[1:], ""
+				}
+			} else {
+				end := strings.IndexFunc(s, unicode.IsSpace)
+				if end < 0 {
+					end = len(s)
+				}
+				value, s = s[:end], s[end:]
+			}
+		}
+		pairs[key] = value
+	}
+}
+
+// codeBool normalises a boolean attribute, returning "" for anything that
+// is not true or false so the parameter is left out
+func codeBool(value string) string {
+	switch strings.ToLower(value) {
+	case "true":
+		return "true"
+	case "false":
+		return "false"
+	}
+	return ""
+}
+
+// formatCodeAttrs writes the code macro parameters in params that a fence
+// info string can hold as a {...} block, or "" if there are none. Boolean
+// parameters are only written when true, as false is the default.
+func formatCodeAttrs(params map[string]string) string {
+	var attrs []string
+	if title := strings.TrimSpace(params["title"]); title != "" {
+		quote := `"`
+		if strings.Contains(title, quote) {
+			quote = "'"
+		}
+		attrs = append(attrs, "title="+quote+title+quote)
+	}
+	for _, key := range []string{"linenumbers", "collapse"} {
+		if codeBool(params[key]) == "true" {
+			attrs = append(attrs, key+"=true")
+		}
+	}
+	if theme := strings.TrimSpace(params["theme"]); theme != "" && !strings.ContainsFunc(theme, unicode.IsSpace) {
+		attrs = append(attrs, "theme="+theme)
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(attrs, " ") + "}"
+}
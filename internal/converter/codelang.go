@@ -0,0 +1,108 @@
+package converter
+
+import "strings"
+
+// codeLanguages are the languages the Confluence code macro highlights
+var codeLanguages = map[string]bool{
+	"abap": true, "actionscript3": true, "ada": true, "applescript": true,
+	"arduino": true, "autoit": true, "bash": true, "c": true,
+	"clojure": true, "coffeescript": true, "coldfusion": true, "cpp": true,
+	"csharp": true, "css": true, "cuda": true, "d": true, "dart": true,
+	"delphi": true, "diff": true, "elixir": true, "erlang": true,
+	"fortran": true, "foxpro": true, "go": true, "graphql": true,
+	"groovy": true, "haskell": true, "haxe": true, "html": true,
+	"java": true, "javafx": true, "javascript": true, "json": true,
+	"jsx": true, "julia": true, "kotlin": true, "livescript": true,
+	"lua": true, "mathematica": true, "matlab": true, "objectivec": true,
+	"objectivej": true, "ocaml": true, "octave": true, "pascal": true,
+	"perl": true, "php": true, "powershell": true, "prolog": true,
+	"puppet": true, "python": true, "qml": true, "r": true,
+	"racket": true, "restructuredtext": true, "ruby": true, "rust": true,
+	"sass": true, "scala": true, "scheme": true, "smalltalk": true,
+	"sql": true, "standardml": true, "swift": true, "tcl": true,
+	"text": true, "tsx": true, "typescript": true, "vala": true,
+	"vbnet": true, "verilog": true, "vhdl": true, "xml": true,
+	"xquery": true, "yaml": true,
+}
+
+// codeLanguageAliases maps other common fence languages to the code
+// macro's names for them
+var codeLanguageAliases = map[string]string{
+	"as3":           "actionscript3",
+	"actionscript":  "actionscript3",
+	"sh":            "bash",
+	"shell":         "bash",
+	"zsh":           "bash",
+	"console":       "bash",
+	"shell-session": "bash",
+	"c++":           "cpp",
+	"cc":            "cpp",
+	"cxx":           "cpp",
+	"h":             "c",
+	"hpp":           "cpp",
+	"c#":            "csharp",
+	"cs":            "csharp",
+	"coffee":        "coffeescript",
+	"cfm":           "coldfusion",
+	"pas":           "delphi",
+	"patch":         "diff",
+	"ex":            "elixir",
+	"exs":           "elixir",
+	"erl":           "erlang",
+	"f90":           "fortran",
+	"golang":        "go",
+	"gql":           "graphql",
+	"hs":            "haskell",
+	"htm":           "html",
+	"xhtml":         "html",
+	"js":            "javascript",
+	"mjs":           "javascript",
+	"cjs":           "javascript",
+	"node":          "javascript",
+	"jsonc":         "json",
+	"json5":         "json",
+	"jl":            "julia",
+	"kt":            "kotlin",
+	"kts":           "kotlin",
+	"m":             "objectivec",
+	"objc":          "objectivec",
+	"objective-c":   "objectivec",
+	"ml":            "ocaml",
+	"pl":            "perl",
+	"ps1":           "powershell",
+	"pwsh":          "powershell",
+	"posh":          "powershell",
+	"py":            "python",
+	"py3":           "python",
+	"python3":       "python",
+	"rst":           "restructuredtext",
+	"rb":            "ruby",
+	"rs":            "rust",
+	"scss":          "sass",
+	"sml":           "standardml",
+	"txt":           "text",
+	"plain":         "text",
+	"plaintext":     "text",
+	"ts":            "typescript",
+	"vb":            "vbnet",
+	"vb.net":        "vbnet",
+	"v":             "verilog",
+	"svg":           "xml",
+	"xsl":           "xml",
+	"xslt":          "xml",
+	"yml":           "yaml",
+}
+
+// codeMacroLanguage returns the code macro language for a fence language,
+// or "none" if the macro cannot highlight it, since the editor rejects
+// unknown values
+func codeMacroLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if alias, ok := codeLanguageAliases[lang]; ok {
+		return alias
+	}
+	if codeLanguages[lang] {
+		return lang
+	}
+	return "none"
+}
@@ -1,6 +1,12 @@
 package converter
 
 import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
 	"github.com/yuin/goldmark/ast"
 	extast "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/renderer"
@@ -8,22 +14,31 @@ import (
 )
 
 // ConfluenceRenderer is a renderer that outputs Confluence Storage Format (XHTML).
-type ConfluenceRenderer struct{}
+type ConfluenceRenderer struct {
+	opts Options
+}
 
 // NewConfluenceRenderer creates a new ConfluenceRenderer.
 func NewConfluenceRenderer() renderer.NodeRenderer {
 	return &ConfluenceRenderer{}
 }
 
+// NewConfluenceRendererWithOptions creates a ConfluenceRenderer that applies opts.
+func NewConfluenceRendererWithOptions(opts Options) renderer.NodeRenderer {
+	return &ConfluenceRenderer{opts: opts}
+}
+
 // RegisterFuncs registers node rendering functions.
 //
-// GFM-provided node kinds (tables, task checkboxes, strikethrough) are
-// intentionally not registered here. goldmark's GFM extension registers
-// them at priority 500; this renderer is wired in at priority 1000 (see
+// Node kinds from goldmark extensions (tables, strikethrough) are
+// intentionally not registered here. The extensions register their
+// renderers at priority 500; this renderer is wired in at priority 1000 (see
 // markdown.go). goldmark sorts renderers ascending by priority and
 // iterates in reverse, so later Register calls overwrite earlier ones in
-// the kind→func map — the numerically lower priority value wins. GFM
-// therefore owns those kinds in the live MarkdownToStorage pipeline.
+// the kind→func map — the numerically lower priority value wins. The
+// extensions therefore own those kinds in the live MarkdownToStorage
+// pipeline. Task checkboxes are parsed without the TaskList extension (see
+// markdown.go), so they are rendered here.
 func (r *ConfluenceRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	// Block elements
 	reg.Register(ast.KindDocument, r.renderDocument)
@@ -34,69 +49,111 @@ func (r *ConfluenceRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegister
 	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
 	reg.Register(ast.KindList, r.renderList)
 	reg.Register(ast.KindListItem, r.renderListItem)
+	reg.Register(extast.KindTaskCheckBox, r.renderTaskCheckBox)
 	reg.Register(ast.KindParagraph, r.renderParagraph)
 	reg.Register(ast.KindTextBlock, r.renderTextBlock)
 	reg.Register(ast.KindThematicBreak, r.renderThematicBreak)
+	reg.Register(kindAdmonition, r.renderAdmonition)
+	reg.Register(kindExpand, r.renderExpand)
+	reg.Register(kindHTMLTable, r.renderHTMLTable)
+	reg.Register(kindTOC, r.renderTOC)
+	reg.Register(kindChildren, r.renderChildren)
+	reg.Register(kindInclude, r.renderInclude)
+	reg.Register(kindExcerpt, r.renderExcerpt)
+	reg.Register(kindLayout, r.renderLayout)
+	reg.Register(kindLayoutSection, r.renderLayoutSection)
+	reg.Register(kindLayoutCell, r.renderLayoutCell)
+	reg.Register(kindMathBlock, r.renderMathBlock)
+	reg.Register(extast.KindFootnoteList, r.renderFootnoteList)
+	reg.Register(extast.KindFootnote, r.renderFootnote)
 
 	// Inline elements
 	reg.Register(ast.KindAutoLink, r.renderAutoLink)
 	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
+	reg.Register(extast.KindFootnoteLink, r.renderFootnoteLink)
+	reg.Register(extast.KindFootnoteBacklink, r.renderFootnoteBacklink)
 	reg.Register(ast.KindEmphasis, r.renderEmphasis)
 	reg.Register(ast.KindImage, r.renderImage)
 	reg.Register(ast.KindLink, r.renderLink)
+	reg.Register(kindWikiLink, r.renderWikiLink)
+	reg.Register(kindEmoji, r.renderEmoji)
+	reg.Register(kindMathInline, r.renderMathInline)
+	reg.Register(kindHighlight, r.renderHighlight)
+	reg.Register(kindScript, r.renderScript)
+	reg.Register(kindStatus, r.renderStatus)
+	reg.Register(kindJiraIssue, r.renderJiraIssue)
+	reg.Register(kindMention, r.renderMention)
+	reg.Register(kindDate, r.renderDate)
+	reg.Register(kindAnchor, r.renderAnchor)
 	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(kindInlineHTML, r.renderInlineHTML)
 	reg.Register(ast.KindText, r.renderText)
 	reg.Register(ast.KindString, r.renderString)
 }
 
-// Helper to write lines from a node
-func (r *ConfluenceRenderer) writeLines(w util.BufWriter, source []byte, n ast.Node) {
+// writeCDATALines writes a node's lines as the content of a CDATA section
+func (r *ConfluenceRenderer) writeCDATALines(w util.BufWriter, source []byte, n ast.Node) {
+	var body bytes.Buffer
 	l := n.Lines().Len()
 	for i := 0; i < l; i++ {
 		line := n.Lines().At(i)
-		_, _ = w.Write(line.Value(source)) //nolint:errcheck
-	}
-}
-
-// isTaskList checks if a list contains task checkboxes
-func isTaskList(node ast.Node) bool {
-	// Check first list item for a task checkbox
-	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
-		if child.Kind() == ast.KindListItem {
-			// Look for TaskCheckBox in the list item's children
-			for grandchild := child.FirstChild(); grandchild != nil; grandchild = grandchild.NextSibling() {
-				// TaskCheckBox can be direct child or inside a paragraph/textblock
-				if grandchild.Kind() == extast.KindTaskCheckBox {
-					return true
-				}
-				// Check inside paragraph or textblock
-				for greatgrandchild := grandchild.FirstChild(); greatgrandchild != nil; greatgrandchild = greatgrandchild.NextSibling() {
-					if greatgrandchild.Kind() == extast.KindTaskCheckBox {
-						return true
-					}
-				}
-			}
-		}
+		body.Write(line.Value(source))
 	}
-	return false
+	writeCDATA(w, body.Bytes())
+}
+
+// cdataEnd closes a CDATA section, so cannot appear within one
+var cdataEnd = []byte("]]>")
+
+// writeCDATA writes b as the content of a CDATA section. Any "]]>" in b is
+// split across two sections, as "]]" closing one and ">" opening the next.
+func writeCDATA(w util.BufWriter, b []byte) {
+	_, _ = w.Write(bytes.ReplaceAll(b, cdataEnd, []byte("]]]]><![CDATA[>"))) //nolint:errcheck
 }
 
-// getTaskCheckBox finds the TaskCheckBox node in a list item
+// getTaskCheckBox returns the checkbox that makes a list item a task, or nil
+// for a plain item. The checkbox is always the first inline of the item's
+// first block.
 func getTaskCheckBox(listItem ast.Node) *extast.TaskCheckBox {
-	for child := listItem.FirstChild(); child != nil; child = child.NextSibling() {
-		if child.Kind() == extast.KindTaskCheckBox {
-			return child.(*extast.TaskCheckBox)
-		}
-		// Check inside paragraph or textblock
-		for grandchild := child.FirstChild(); grandchild != nil; grandchild = grandchild.NextSibling() {
-			if grandchild.Kind() == extast.KindTaskCheckBox {
-				return grandchild.(*extast.TaskCheckBox)
-			}
-		}
+	first := listItem.FirstChild()
+	if first == nil {
+		return nil
+	}
+	if cb, ok := first.FirstChild().(*extast.TaskCheckBox); ok {
+		return cb
 	}
 	return nil
 }
 
+// isTaskItem reports whether node is a list item with a task checkbox
+func isTaskItem(node ast.Node) bool {
+	return node != nil && node.Kind() == ast.KindListItem && getTaskCheckBox(node) != nil
+}
+
+// listItemKinds reports whether list holds task items and plain items
+func listItemKinds(list ast.Node) (tasks, plain bool) {
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		if isTaskItem(item) {
+			tasks = true
+		} else {
+			plain = true
+		}
+	}
+	return tasks, plain
+}
+
+// isHoistedTaskList reports whether list is a task list nested as the last
+// block of a task. Confluence nests task lists directly inside the parent
+// ac:task-list, after the task, rather than inside its ac:task-body.
+func isHoistedTaskList(list ast.Node) bool {
+	parent := list.Parent()
+	if !isTaskItem(parent) || parent.LastChild() != list {
+		return false
+	}
+	tasks, plain := listItemKinds(list)
+	return tasks && !plain
+}
+
 // Document
 func (r *ConfluenceRenderer) renderDocument(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -104,39 +161,314 @@ func (r *ConfluenceRenderer) renderDocument(
 	return ast.WalkContinue, nil
 }
 
-// Heading
+// Heading, led by an anchor macro named after the heading's auto ID so
+// "#section" links resolve. Confluence strips id attributes.
 func (r *ConfluenceRenderer) renderHeading(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Heading)
+	level := r.opts.headingLevel(n.Level)
+	if entering {
+		_, _ = w.WriteString("<h")        //nolint:errcheck
+		_ = w.WriteByte("0123456"[level]) //nolint:errcheck
+		_ = w.WriteByte('>')              //nolint:errcheck
+		if id, ok := n.AttributeString("id"); ok && r.opts.macroEnabled("anchor") {
+			if b, ok := id.([]byte); ok && len(b) > 0 {
+				writeAnchor(w, string(util.EscapeHTML(b)))
+			}
+		}
+	} else {
+		_, _ = w.WriteString("</h")       //nolint:errcheck
+		_ = w.WriteByte("0123456"[level]) //nolint:errcheck
+		_, _ = w.WriteString(">\n")       //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
+
+// writePanelStart opens a panel macro (info, note, tip, warning) with an optional title
+func writePanelStart(w util.BufWriter, macro, title string) {
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="` + macro + `">`) //nolint:errcheck
+	if title != "" {
+		_, _ = w.WriteString(`<ac:parameter ac:name="title">`) //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(title)))         //nolint:errcheck
+		_, _ = w.WriteString(`</ac:parameter>`)                //nolint:errcheck
+	}
+	_, _ = w.WriteString("<ac:rich-text-body>\n") //nolint:errcheck
+}
+
+// writePanelEnd closes a panel macro opened by writePanelStart
+func writePanelEnd(w util.BufWriter) {
+	_, _ = w.WriteString("</ac:rich-text-body></ac:structured-macro>\n") //nolint:errcheck
+}
+
+// Admonition (::: directive), rendered as a panel or, if the macro is
+// disabled, a blockquote led by the title in bold
+func (r *ConfluenceRenderer) renderAdmonition(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*admonition)
+	if !r.opts.macroEnabled(n.macro) {
+		if entering {
+			_, _ = w.WriteString("<blockquote>\n") //nolint:errcheck
+			if n.title != "" {
+				_, _ = w.WriteString("<p><strong>")              //nolint:errcheck
+				_, _ = w.Write(util.EscapeHTML([]byte(n.title))) //nolint:errcheck
+				_, _ = w.WriteString("</strong></p>\n")          //nolint:errcheck
+			}
+		} else {
+			_, _ = w.WriteString("</blockquote>\n") //nolint:errcheck
+		}
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		writePanelStart(w, n.macro, n.title)
+	} else {
+		writePanelEnd(w)
+	}
+	return ast.WalkContinue, nil
+}
+
+// Expand (HTML details), rendered as the expand macro or, if it is
+// disabled, as its content led by the title in bold
+func (r *ConfluenceRenderer) renderExpand(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*expand)
+	if !r.opts.macroEnabled("expand") {
+		if entering {
+			if n.title != "" {
+				_, _ = w.WriteString("<p><strong>")              //nolint:errcheck
+				_, _ = w.Write(util.EscapeHTML([]byte(n.title))) //nolint:errcheck
+				_, _ = w.WriteString("</strong></p>\n")          //nolint:errcheck
+			}
+			writeAllowedHTMLBlock(w, n.body)
+		}
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		writePanelStart(w, "expand", n.title)
+		writeAllowedHTMLBlock(w, n.body)
+	} else {
+		writePanelEnd(w)
+	}
+	return ast.WalkContinue, nil
+}
+
+// TOC, rendered as the toc macro or dropped if the macro is disabled
+func (r *ConfluenceRenderer) renderTOC(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering || !r.opts.macroEnabled("toc") {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="toc">`) //nolint:errcheck
+	if r.opts.TOC.MinLevel > 0 {
+		_, _ = fmt.Fprintf(w, `<ac:parameter ac:name="minLevel">%d</ac:parameter>`, r.opts.TOC.MinLevel) //nolint:errcheck
+	}
+	if r.opts.TOC.MaxLevel > 0 {
+		_, _ = fmt.Fprintf(w, `<ac:parameter ac:name="maxLevel">%d</ac:parameter>`, r.opts.TOC.MaxLevel) //nolint:errcheck
+	}
+	_, _ = w.WriteString("</ac:structured-macro>\n") //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// Children (a {children} shortcode), rendered as the children display macro
+// or, if it is disabled, as nothing
+func (r *ConfluenceRenderer) renderChildren(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering || !r.opts.macroEnabled("children") {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*children)
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="children">`) //nolint:errcheck
+	if n.all {
+		writeMacroParam(w, "all", "true")
+	} else if n.depth > 0 {
+		writeMacroParam(w, "depth", strconv.Itoa(n.depth))
+	}
+	writeMacroParam(w, "sort", n.sort)
+	if n.reverse {
+		writeMacroParam(w, "reverse", "true")
+	}
+	_, _ = w.WriteString("</ac:structured-macro>\n") //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// Include ({include:Page Title}), rendered as the include page macro or,
+// if it is disabled, as a link to the page
+func (r *ConfluenceRenderer) renderInclude(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*include)
+	if !r.opts.macroEnabled("include") {
+		_, _ = w.WriteString("<p><ac:link>") //nolint:errcheck
+		writePageRef(w, n.ref)
+		_, _ = w.WriteString("</ac:link></p>\n") //nolint:errcheck
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="include"><ac:parameter ac:name=""><ac:link>`) //nolint:errcheck
+	writePageRef(w, n.ref)
+	_, _ = w.WriteString("</ac:link></ac:parameter></ac:structured-macro>\n") //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// Excerpt ({excerpt} blocks), rendered as the excerpt macro or, if it is
+// disabled, as its content
+func (r *ConfluenceRenderer) renderExcerpt(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !r.opts.macroEnabled("excerpt") {
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		writePanelStart(w, "excerpt", "")
+	} else {
+		writePanelEnd(w)
+	}
+	return ast.WalkContinue, nil
+}
+
+// Layout (a page with :::columns), rendered as ac:layout
+func (r *ConfluenceRenderer) renderLayout(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("<ac:layout>\n") //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</ac:layout>\n") //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
+
+// LayoutSection (:::columns), rendered as ac:layout-section within a
+// layout, and otherwise as its content
+func (r *ConfluenceRenderer) renderLayoutSection(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*layoutSection)
+	if n.Parent().Kind() != kindLayout {
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		_, _ = w.WriteString(`<ac:layout-section ac:type="` + n.sectionType + `">` + "\n") //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</ac:layout-section>\n") //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
+
+// LayoutCell (:::column), rendered as ac:layout-cell within a layout
+// section, and otherwise as its content
+func (r *ConfluenceRenderer) renderLayoutCell(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	section := node.Parent()
+	if section.Kind() != kindLayoutSection || section.Parent().Kind() != kindLayout {
+		return ast.WalkContinue, nil
+	}
 	if entering {
-		_, _ = w.WriteString("<h")          //nolint:errcheck
-		_ = w.WriteByte("0123456"[n.Level]) //nolint:errcheck
-		_ = w.WriteByte('>')                //nolint:errcheck
+		_, _ = w.WriteString("<ac:layout-cell>\n") //nolint:errcheck
 	} else {
-		_, _ = w.WriteString("</h")         //nolint:errcheck
-		_ = w.WriteByte("0123456"[n.Level]) //nolint:errcheck
-		_, _ = w.WriteString(">\n")         //nolint:errcheck
+		_, _ = w.WriteString("</ac:layout-cell>\n") //nolint:errcheck
 	}
 	return ast.WalkContinue, nil
 }
 
-// Blockquote
+// alertMacros maps GitHub alert markers to Confluence panel macros
+var alertMacros = map[string]string{
+	"NOTE":    "info",
+	"WARNING": "warning",
+	"TIP":     "tip",
+	"CAUTION": "note",
+}
+
+// alertAttr marks a blockquote being rendered as a panel macro, so the
+// closing tag matches after the alert marker has been stripped.
+var alertAttr = []byte("acon-alert")
+
+// alertMacro returns the panel macro for a GitHub-style alert blockquote
+// ("> [!NOTE]" alone on the first line), or "" for a plain blockquote.
+func alertMacro(n ast.Node, source []byte) string {
+	para := n.FirstChild()
+	if para == nil || para.Kind() != ast.KindParagraph || para.Lines().Len() == 0 {
+		return ""
+	}
+	line := para.Lines().At(0)
+	first := strings.TrimSpace(string(line.Value(source)))
+	if !strings.HasPrefix(first, "[!") || !strings.HasSuffix(first, "]") {
+		return ""
+	}
+	return alertMacros[strings.ToUpper(first[2:len(first)-1])]
+}
+
+// stripAlertMarker removes the marker line from an alert's first paragraph,
+// dropping the paragraph entirely if the marker was all it contained.
+func stripAlertMarker(n ast.Node, source []byte) {
+	para := n.FirstChild()
+	markerEnd := para.Lines().At(0).Stop
+	for c := para.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		t, ok := c.(*ast.Text)
+		if !ok || t.Segment.Start >= markerEnd {
+			break
+		}
+		para.RemoveChild(para, c)
+		c = next
+	}
+	if !para.HasChildren() {
+		n.RemoveChild(n, para)
+	}
+}
+
+// Blockquote, or an info/warning/tip/note panel for GitHub-style alerts
 func (r *ConfluenceRenderer) renderBlockquote(
 	w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
+		if macro := alertMacro(n, source); macro != "" && r.opts.macroEnabled(macro) {
+			stripAlertMarker(n, source)
+			n.SetAttribute(alertAttr, macro)
+			writePanelStart(w, macro, "")
+			return ast.WalkContinue, nil
+		}
 		_, _ = w.WriteString("<blockquote>\n") //nolint:errcheck
 	} else {
+		if _, ok := n.Attribute(alertAttr); ok {
+			writePanelEnd(w)
+			return ast.WalkContinue, nil
+		}
 		_, _ = w.WriteString("</blockquote>\n") //nolint:errcheck
 	}
 	return ast.WalkContinue, nil
 }
 
+// writeEscapedLines writes a node's lines with HTML escaping, for plain <pre> output
+func (r *ConfluenceRenderer) writeEscapedLines(w util.BufWriter, source []byte, n ast.Node) {
+	l := n.Lines().Len()
+	for i := 0; i < l; i++ {
+		line := n.Lines().At(i)
+		_, _ = w.Write(util.EscapeHTML(line.Value(source))) //nolint:errcheck
+	}
+}
+
+// renderPlainCodeBlock writes a code block as <pre><code>, used when the code macro is disabled
+func (r *ConfluenceRenderer) renderPlainCodeBlock(w util.BufWriter, source []byte, node ast.Node, lang string) {
+	if lang != "" {
+		_, _ = w.WriteString(`<pre><code class="language-`) //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(lang)))       //nolint:errcheck
+		_, _ = w.WriteString(`">`)                          //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("<pre><code>") //nolint:errcheck
+	}
+	r.writeEscapedLines(w, source, node)
+	_, _ = w.WriteString("</code></pre>\n") //nolint:errcheck
+}
+
 // CodeBlock (indented code)
 func (r *ConfluenceRenderer) renderCodeBlock(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !r.opts.macroEnabled("code") {
+		if entering {
+			r.renderPlainCodeBlock(w, source, node, "")
+		}
+		return ast.WalkContinue, nil
+	}
 	if entering {
 		_, _ = w.WriteString(`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">none</ac:parameter><ac:plain-text-body><![CDATA[`) //nolint:errcheck
-		r.writeLines(w, source, node)
+		r.writeCDATALines(w, source, node)
 	} else {
 		_, _ = w.WriteString("]]></ac:plain-text-body></ac:structured-macro>\n") //nolint:errcheck
 	}
@@ -147,103 +479,363 @@ func (r *ConfluenceRenderer) renderCodeBlock(
 func (r *ConfluenceRenderer) renderFencedCodeBlock(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.FencedCodeBlock)
+	var lang string
+	var attrs codeAttrs
+	if n.Info != nil {
+		lang, attrs = codeFenceInfo(n.Info.Value(source))
+	}
+	if lang == macroFenceLang && r.renderStorageMacro(w, source, n, entering) {
+		return ast.WalkSkipChildren, nil
+	}
+	if lang == adfFenceLang && entering {
+		// ADF kept by ADFToMarkdown has no storage format to publish as
+		if err := r.unsupported(source, n.Info.Segment.Start, "ADF node"); err != nil {
+			return ast.WalkStop, err
+		}
+	}
+	if r.opts.diagramMacro(lang) != "" {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if r.renderDiagram(w, source, n, lang, r.opts.diagramMacro(lang)) {
+			return ast.WalkSkipChildren, nil
+		}
+		// Neither image nor macro is available, so show the source as code
+		if r.opts.macroEnabled("code") {
+			r.renderCodeMacro(w, source, n, lang, attrs)
+		} else {
+			r.renderPlainCodeBlock(w, source, n, lang)
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	if lang == "math" && r.opts.macroEnabled(r.opts.mathBlockMacro()) {
+		if entering {
+			r.writeMathBlockMacro(w, source, n)
+		}
+		return ast.WalkContinue, nil
+	}
+	if !r.opts.macroEnabled("code") {
+		if entering {
+			r.renderPlainCodeBlock(w, source, n, lang)
+		}
+		return ast.WalkContinue, nil
+	}
 	if entering {
-		lang := "none"
-		if n.Language(source) != nil {
-			lang = string(n.Language(source))
-		}
-		_, _ = w.WriteString(`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">`) //nolint:errcheck
-		_, _ = w.WriteString(lang)                                                                    //nolint:errcheck
-		_, _ = w.WriteString(`</ac:parameter><ac:plain-text-body><![CDATA[`)                          //nolint:errcheck
-		r.writeLines(w, source, n)
-	} else {
-		_, _ = w.WriteString("]]></ac:plain-text-body></ac:structured-macro>\n") //nolint:errcheck
+		r.renderCodeMacro(w, source, n, lang, attrs)
 	}
 	return ast.WalkContinue, nil
 }
 
-// HTMLBlock - skip raw HTML for security
+// renderStorageMacro writes a confluence-macro fence, as written by
+// StorageToMarkdown for a macro with no Markdown equivalent, as the storage
+// format it holds. It reports false, so the fence is published as code, if
+// the fence does not hold exactly one macro or the macro is disabled.
+func (r *ConfluenceRenderer) renderStorageMacro(w util.BufWriter, source []byte, node ast.Node, entering bool) bool {
+	var body bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		body.Write(line.Value(source))
+	}
+	raw := strings.TrimSpace(macroLabelRegex.ReplaceAllString(body.String(), ""))
+	if !strings.HasPrefix(raw, macroOpen) || macroEnd(raw, 0) != len(raw) {
+		return false
+	}
+	if !r.opts.macroEnabled(parseMacro(raw).name) {
+		return false
+	}
+	if entering {
+		_, _ = w.WriteString(raw + "\n") //nolint:errcheck
+	}
+	return true
+}
+
+// renderCodeMacro writes a complete code macro for node in one step, with
+// lang mapped to a language the macro supports
+func (r *ConfluenceRenderer) renderCodeMacro(w util.BufWriter, source []byte, node ast.Node, lang string, attrs codeAttrs) {
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="code">`) //nolint:errcheck
+	writeMacroParam(w, "language", codeMacroLanguage(lang))
+	writeMacroParam(w, "title", attrs.title)
+	writeMacroParam(w, "linenumbers", attrs.lineNumbers)
+	writeMacroParam(w, "collapse", attrs.collapse)
+	writeMacroParam(w, "theme", attrs.theme)
+	_, _ = w.WriteString(`<ac:plain-text-body><![CDATA[`) //nolint:errcheck
+	r.writeCDATALines(w, source, node)
+	_, _ = w.WriteString("]]></ac:plain-text-body></ac:structured-macro>\n") //nolint:errcheck
+}
+
+// writeMacroParam writes a macro parameter, or nothing if value is empty
+func writeMacroParam(w util.BufWriter, name, value string) {
+	if value == "" {
+		return
+	}
+	_, _ = w.WriteString(`<ac:parameter ac:name="`) //nolint:errcheck
+	_, _ = w.WriteString(name)                      //nolint:errcheck
+	_, _ = w.WriteString(`">`)                      //nolint:errcheck
+	_, _ = w.Write(util.EscapeHTML([]byte(value)))  //nolint:errcheck
+	_, _ = w.WriteString(`</ac:parameter>`)         //nolint:errcheck
+}
+
+// MathBlock ($$ display math), rendered with the math macro or, if it is
+// disabled, as a code block
+func (r *ConfluenceRenderer) renderMathBlock(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	switch {
+	case r.opts.macroEnabled(r.opts.mathBlockMacro()):
+		r.writeMathBlockMacro(w, source, node)
+	case r.opts.macroEnabled("code"):
+		r.renderCodeMacro(w, source, node, "none", codeAttrs{})
+	default:
+		r.renderPlainCodeBlock(w, source, node, "")
+	}
+	return ast.WalkContinue, nil
+}
+
+// writeMathBlockMacro writes node's lines as the display math macro
+func (r *ConfluenceRenderer) writeMathBlockMacro(w util.BufWriter, source []byte, node ast.Node) {
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="`)           //nolint:errcheck
+	_, _ = w.Write(util.EscapeHTML([]byte(r.opts.mathBlockMacro()))) //nolint:errcheck
+	_, _ = w.WriteString(`"><ac:plain-text-body><![CDATA[`)          //nolint:errcheck
+	r.writeCDATALines(w, source, node)
+	_, _ = w.WriteString("]]></ac:plain-text-body></ac:structured-macro>\n") //nolint:errcheck
+}
+
+// MathInline ($ inline math), rendered with the inline math macro or, if it
+// is disabled, as inline code
+func (r *ConfluenceRenderer) renderMathInline(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*mathInline)
+	macro := r.opts.mathInlineMacro()
+	if !r.opts.macroEnabled(macro) {
+		_, _ = w.WriteString("<code>")           //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML(n.value)) //nolint:errcheck
+		_, _ = w.WriteString("</code>")          //nolint:errcheck
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="`) //nolint:errcheck
+	_, _ = w.Write(util.EscapeHTML([]byte(macro)))         //nolint:errcheck
+	_, _ = w.WriteString(`">`)                             //nolint:errcheck
+	writeMacroParam(w, "body", string(n.value))
+	_, _ = w.WriteString(`</ac:structured-macro>`) //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// renderDiagram writes a diagram fence as an image when a diagram server or
+// renderer is configured, otherwise as the given diagram macro. It reports
+// false if neither is possible so the caller can fall back to a code block.
+func (r *ConfluenceRenderer) renderDiagram(w util.BufWriter, source []byte, node ast.Node, lang, macro string) bool {
+	var body bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		body.Write(line.Value(source))
+	}
+
+	if lang == "plantuml" && r.opts.PlantUMLServer != "" {
+		imageURL := plantUMLImageURL(r.opts.PlantUMLServer, body.Bytes())
+		_, _ = w.WriteString(`<p><ac:image><ri:url ri:value="`) //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(imageURL)))       //nolint:errcheck
+		_, _ = w.WriteString("\" /></ac:image></p>\n")          //nolint:errcheck
+		return true
+	}
+
+	if r.opts.RenderDiagram != nil {
+		if filename, err := r.opts.RenderDiagram(lang, body.Bytes()); err == nil {
+			_, _ = w.WriteString(`<p><ac:image><ri:attachment ri:filename="`) //nolint:errcheck
+			_, _ = w.Write(util.EscapeHTML([]byte(filename)))                 //nolint:errcheck
+			_, _ = w.WriteString("\" /></ac:image></p>\n")                    //nolint:errcheck
+			return true
+		}
+	}
+
+	if !r.opts.macroEnabled(macro) {
+		return false
+	}
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="`)  //nolint:errcheck
+	_, _ = w.Write(util.EscapeHTML([]byte(macro)))          //nolint:errcheck
+	_, _ = w.WriteString(`"><ac:plain-text-body><![CDATA[`) //nolint:errcheck
+	writeCDATA(w, body.Bytes())
+	_, _ = w.WriteString("]]></ac:plain-text-body></ac:structured-macro>\n") //nolint:errcheck
+	return true
+}
+
+// HTMLBlock - omitted for security unless AllowHTML passes through the
+// allowed subset
 func (r *ConfluenceRenderer) renderHTMLBlock(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
-	if entering {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.HTMLBlock)
+	start := n.Lines().At(0).Start
+	if !r.opts.AllowHTML {
+		if n.HTMLBlockType != ast.HTMLBlockType2 { // comments
+			if err := r.unsupported(source, start, "raw HTML"); err != nil {
+				return ast.WalkStop, err
+			}
+		}
 		_, _ = w.WriteString("<!-- raw HTML omitted -->\n") //nolint:errcheck
+		return ast.WalkContinue, nil
+	}
+	var raw bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		raw.Write(line.Value(source))
+	}
+	if n.HasClosure() {
+		raw.Write(n.ClosureLine.Value(source))
+	}
+	if dropped := writeAllowedHTMLBlock(w, raw.Bytes()); dropped != "" {
+		if err := r.unsupported(source, start, "HTML <"+dropped+"> element"); err != nil {
+			return ast.WalkStop, err
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// unsupported returns the error for a construct at offset in source that
+// cannot be published as written, or nil unless Options.Strict is set
+func (r *ConfluenceRenderer) unsupported(source []byte, offset int, construct string) error {
+	if !r.opts.Strict {
+		return nil
+	}
+	line := bytes.Count(source[:offset], []byte("\n")) + 1
+	return fmt.Errorf("line %d: %s: %w", line, construct, ErrUnsupported)
+}
+
+// HTMLTable, written as a well-formed table whatever Options.AllowHTML says,
+// as merged cells cannot be written any other way
+func (r *ConfluenceRenderer) renderHTMLTable(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeHTMLTable(w, node.(*htmlTable).raw)
 	}
 	return ast.WalkContinue, nil
 }
 
-// List
+// List. Lists holding tasks are written by their items, which group runs
+// of tasks into ac:task-list and runs of plain items into ul/ol, as a
+// Confluence task list cannot hold anything but tasks.
 func (r *ConfluenceRenderer) renderList(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.List)
 
-	// Check if this is a task list
-	if isTaskList(node) {
-		if entering {
-			_, _ = w.WriteString("<ac:task-list>\n") //nolint:errcheck
-		} else {
-			_, _ = w.WriteString("</ac:task-list>\n") //nolint:errcheck
+	if tasks, _ := listItemKinds(node); tasks {
+		if entering && isHoistedTaskList(node) {
+			// Close the parent task so this list follows it
+			_, _ = w.WriteString("</ac:task-body>\n</ac:task>\n") //nolint:errcheck
 		}
 		return ast.WalkContinue, nil
 	}
 
-	// Regular list
 	if entering {
-		if n.IsOrdered() {
-			_, _ = w.WriteString("<ol>\n") //nolint:errcheck
-		} else {
-			_, _ = w.WriteString("<ul>\n") //nolint:errcheck
-		}
+		writeListOpen(w, n)
 	} else {
-		if n.IsOrdered() {
-			_, _ = w.WriteString("</ol>\n") //nolint:errcheck
-		} else {
-			_, _ = w.WriteString("</ul>\n") //nolint:errcheck
-		}
+		writeListClose(w, n)
 	}
 	return ast.WalkContinue, nil
 }
 
+// writeListOpen opens a plain ul or ol
+func writeListOpen(w util.BufWriter, n *ast.List) {
+	if n.IsOrdered() {
+		_, _ = w.WriteString("<ol>\n") //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("<ul>\n") //nolint:errcheck
+	}
+}
+
+// writeListClose closes a list opened by writeListOpen
+func writeListClose(w util.BufWriter, n *ast.List) {
+	if n.IsOrdered() {
+		_, _ = w.WriteString("</ol>\n") //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</ul>\n") //nolint:errcheck
+	}
+}
+
 // ListItem
 func (r *ConfluenceRenderer) renderListItem(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
-	// Check if parent is a task list
-	parent := node.Parent()
-	if parent != nil && isTaskList(parent) {
-		checkbox := getTaskCheckBox(node)
+	list := node.Parent().(*ast.List)
+	if tasks, _ := listItemKinds(list); !tasks {
 		if entering {
-			_, _ = w.WriteString("<ac:task>\n") //nolint:errcheck
-			if checkbox != nil && checkbox.IsChecked {
-				_, _ = w.WriteString("<ac:task-status>complete</ac:task-status>\n") //nolint:errcheck
-			} else {
-				_, _ = w.WriteString("<ac:task-status>incomplete</ac:task-status>\n") //nolint:errcheck
-			}
-			_, _ = w.WriteString("<ac:task-body>") //nolint:errcheck
+			_, _ = w.WriteString("<li>") //nolint:errcheck
 		} else {
-			_, _ = w.WriteString("</ac:task-body>\n</ac:task>\n") //nolint:errcheck
+			_, _ = w.WriteString("</li>\n") //nolint:errcheck
 		}
 		return ast.WalkContinue, nil
 	}
 
-	// Regular list item
+	// Open or close the run of like items this one starts or ends
+	task := isTaskItem(node)
 	if entering {
-		_, _ = w.WriteString("<li>") //nolint:errcheck
-	} else {
-		_, _ = w.WriteString("</li>\n") //nolint:errcheck
+		if prev := node.PreviousSibling(); prev == nil || isTaskItem(prev) != task {
+			if task {
+				_, _ = w.WriteString("<ac:task-list>\n") //nolint:errcheck
+			} else {
+				writeListOpen(w, list)
+			}
+		}
 	}
+
+	if !task {
+		if entering {
+			_, _ = w.WriteString("<li>") //nolint:errcheck
+		} else {
+			_, _ = w.WriteString("</li>\n") //nolint:errcheck
+		}
+	} else if entering {
+		_, _ = w.WriteString("<ac:task>\n") //nolint:errcheck
+		if getTaskCheckBox(node).IsChecked {
+			_, _ = w.WriteString("<ac:task-status>complete</ac:task-status>\n") //nolint:errcheck
+		} else {
+			_, _ = w.WriteString("<ac:task-status>incomplete</ac:task-status>\n") //nolint:errcheck
+		}
+		_, _ = w.WriteString("<ac:task-body>") //nolint:errcheck
+	} else if last := node.LastChild(); last == nil || !isHoistedTaskList(last) {
+		_, _ = w.WriteString("</ac:task-body>\n</ac:task>\n") //nolint:errcheck
+	}
+
+	if !entering {
+		if next := node.NextSibling(); next == nil || isTaskItem(next) != task {
+			if task {
+				_, _ = w.WriteString("</ac:task-list>\n") //nolint:errcheck
+			} else {
+				writeListClose(w, list)
+			}
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+// TaskCheckBox - the task status carries it
+func (r *ConfluenceRenderer) renderTaskCheckBox(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	return ast.WalkContinue, nil
 }
 
+// isInlineTaskBody reports whether the task item holds a single paragraph,
+// not counting a hoisted task list, so its text can go straight into the
+// ac:task-body. A body with several blocks keeps them as blocks.
+func isInlineTaskBody(item ast.Node) bool {
+	blocks := item.ChildCount()
+	if last := item.LastChild(); last != nil && isHoistedTaskList(last) {
+		blocks--
+	}
+	first := item.FirstChild()
+	return blocks == 1 && (first.Kind() == ast.KindParagraph || first.Kind() == ast.KindTextBlock)
+}
+
 // Paragraph
 func (r *ConfluenceRenderer) renderParagraph(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
-	// Skip paragraph tags inside task list items (ac:task-body handles content directly)
-	parent := node.Parent()
-	if parent != nil && parent.Kind() == ast.KindListItem {
-		grandparent := parent.Parent()
-		if grandparent != nil && isTaskList(grandparent) {
-			// Don't wrap task item content in <p> tags
-			return ast.WalkContinue, nil
-		}
+	if parent := node.Parent(); isTaskItem(parent) && isInlineTaskBody(parent) {
+		return ast.WalkContinue, nil
 	}
 
 	if entering {
@@ -254,9 +846,16 @@ func (r *ConfluenceRenderer) renderParagraph(
 	return ast.WalkContinue, nil
 }
 
-// TextBlock
+// TextBlock (the text of a tight list item). In a task body of several
+// blocks it is written as a paragraph.
 func (r *ConfluenceRenderer) renderTextBlock(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if parent := node.Parent(); isTaskItem(parent) {
+		if !isInlineTaskBody(parent) {
+			return r.renderParagraph(w, source, node, entering)
+		}
+		return ast.WalkContinue, nil
+	}
 	if !entering {
 		_ = w.WriteByte('\n') //nolint:errcheck
 	}
@@ -327,18 +926,68 @@ func (r *ConfluenceRenderer) renderImage(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Image)
 	if entering {
-		_, _ = w.WriteString(`<ac:image><ri:url ri:value="`)                 //nolint:errcheck
-		_, _ = w.Write(util.EscapeHTML(util.URLEscape(n.Destination, true))) //nolint:errcheck
-		_, _ = w.WriteString(`" /></ac:image>`)                              //nolint:errcheck
+		writeImageStart(w, source, n)
+		if filename, ok := r.attachImage(n.Destination); ok {
+			_, _ = w.WriteString(`<ri:attachment ri:filename="`) //nolint:errcheck
+			_, _ = w.Write(util.EscapeHTML([]byte(filename)))    //nolint:errcheck
+		} else {
+			_, _ = w.WriteString(`<ri:url ri:value="`)                           //nolint:errcheck
+			_, _ = w.Write(util.EscapeHTML(util.URLEscape(n.Destination, true))) //nolint:errcheck
+		}
+		_, _ = w.WriteString(`" />`) //nolint:errcheck
+		writeImageEnd(w, n)
 		return ast.WalkSkipChildren, nil
 	}
 	return ast.WalkContinue, nil
 }
 
+// attachImage hands an image destination that names a local file to
+// Options.AttachImage and returns the attachment filename to reference.
+func (r *ConfluenceRenderer) attachImage(dest []byte) (string, bool) {
+	if r.opts.AttachImage == nil {
+		return "", false
+	}
+	u, err := url.Parse(string(dest))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	filename, err := r.opts.AttachImage(u.Path)
+	if err != nil {
+		return "", false
+	}
+	return filename, true
+}
+
 // Link
 func (r *ConfluenceRenderer) renderLink(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Link)
+	if ref, anchor, ok := r.opts.pageLink(string(n.Destination)); ok {
+		if entering {
+			_, _ = w.WriteString("<ac:link") //nolint:errcheck
+			if anchor != "" {
+				_, _ = w.WriteString(` ac:anchor="`)            //nolint:errcheck
+				_, _ = w.Write(util.EscapeHTML([]byte(anchor))) //nolint:errcheck
+				_ = w.WriteByte('"')                            //nolint:errcheck
+			}
+			_ = w.WriteByte('>') //nolint:errcheck
+			writePageRef(w, ref)
+			_, _ = w.WriteString("<ac:link-body>") //nolint:errcheck
+		} else {
+			_, _ = w.WriteString("</ac:link-body></ac:link>") //nolint:errcheck
+		}
+		return ast.WalkContinue, nil
+	}
+	if anchor, ok := r.anchorLink(n.Destination); ok {
+		if entering {
+			_, _ = w.WriteString(`<ac:link ac:anchor="`)    //nolint:errcheck
+			_, _ = w.Write(util.EscapeHTML([]byte(anchor))) //nolint:errcheck
+			_, _ = w.WriteString(`"><ac:link-body>`)        //nolint:errcheck
+		} else {
+			_, _ = w.WriteString("</ac:link-body></ac:link>") //nolint:errcheck
+		}
+		return ast.WalkContinue, nil
+	}
 	if entering {
 		_, _ = w.WriteString(`<a href="`)                                    //nolint:errcheck
 		_, _ = w.Write(util.EscapeHTML(util.URLEscape(n.Destination, true))) //nolint:errcheck
@@ -356,10 +1005,204 @@ func (r *ConfluenceRenderer) renderLink(
 	return ast.WalkContinue, nil
 }
 
-// RawHTML - skip for security
+// Anchor ({anchor:name}), rendered as the anchor macro or dropped if the
+// macro is disabled
+func (r *ConfluenceRenderer) renderAnchor(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && r.opts.macroEnabled("anchor") {
+		writeAnchor(w, string(util.EscapeHTML([]byte(node.(*anchor).name))))
+	}
+	return ast.WalkContinue, nil
+}
+
+// anchorLink returns the anchor name of a "#fragment" link within the page.
+// Without the anchor macro there are no anchors to link to, so such links
+// stay plain HTML.
+func (r *ConfluenceRenderer) anchorLink(dest []byte) (string, bool) {
+	if len(dest) < 2 || dest[0] != '#' || !r.opts.macroEnabled("anchor") {
+		return "", false
+	}
+	anchor, err := url.PathUnescape(string(dest[1:]))
+	if err != nil {
+		return "", false
+	}
+	return anchor, true
+}
+
+// WikiLink, rendered as a page link Confluence resolves by title
+func (r *ConfluenceRenderer) renderWikiLink(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*wikiLink)
+	_, _ = w.WriteString("<ac:link>") //nolint:errcheck
+	writePageRef(w, PageRef{SpaceKey: n.space, Title: n.title})
+	if n.text != "" {
+		_, _ = w.WriteString("<ac:plain-text-link-body><![CDATA[") //nolint:errcheck
+		writeCDATA(w, []byte(n.text))
+		_, _ = w.WriteString("]]></ac:plain-text-link-body>") //nolint:errcheck
+	}
+	_, _ = w.WriteString("</ac:link>") //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// writePageRef writes the ri:page resource identifier for ref
+func writePageRef(w util.BufWriter, ref PageRef) {
+	_, _ = w.WriteString("<ri:page") //nolint:errcheck
+	if ref.SpaceKey != "" {
+		_, _ = w.WriteString(` ri:space-key="`)               //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(ref.SpaceKey))) //nolint:errcheck
+		_ = w.WriteByte('"')                                  //nolint:errcheck
+	}
+	_, _ = w.WriteString(` ri:content-title="`)        //nolint:errcheck
+	_, _ = w.Write(util.EscapeHTML([]byte(ref.Title))) //nolint:errcheck
+	_, _ = w.WriteString(`" />`)                       //nolint:errcheck
+}
+
+// RawHTML - skipped for security. With AllowHTML, inlineHTMLTransformer
+// has replaced it with the allowed subset.
 func (r *ConfluenceRenderer) renderRawHTML(
 	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
-	// Skip raw HTML
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*ast.RawHTML)
+	var raw bytes.Buffer
+	for i := 0; i < n.Segments.Len(); i++ {
+		segment := n.Segments.At(i)
+		raw.Write(segment.Value(source))
+	}
+	if !bytes.HasPrefix(raw.Bytes(), []byte("<!--")) {
+		if err := r.unsupported(source, n.Segments.At(0).Start, "raw HTML "+raw.String()); err != nil {
+			return ast.WalkStop, err
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// InlineHTML - raw inline HTML reduced to the subset AllowHTML passes
+// through
+func (r *ConfluenceRenderer) renderInlineHTML(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*inlineHTML)
+	if n.dropped != "" {
+		if err := r.unsupported(source, n.offset, "HTML <"+n.dropped+"> element"); err != nil {
+			return ast.WalkStop, err
+		}
+	}
+	_, _ = w.Write(n.html) //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// Emoji - Confluence emoticon where one exists, otherwise the character
+func (r *ConfluenceRenderer) renderEmoji(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*emojiNode)
+	if n.emoticon == "" {
+		_, _ = w.WriteString(n.char) //nolint:errcheck
+		return ast.WalkContinue, nil
+	}
+	writeEmoticon(w, n.emoticon)
+	return ast.WalkContinue, nil
+}
+
+// Script (^sup^ or ~sub~), rendered as <sup> or <sub>
+func (r *ConfluenceRenderer) renderScript(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*script)
+	if entering {
+		_, _ = w.WriteString("<" + n.tag + ">") //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</" + n.tag + ">") //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
+
+// Highlight (==text==), rendered as a span with a background colour
+func (r *ConfluenceRenderer) renderHighlight(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(`<span style="background-color: ` + highlightColour + `;">`) //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</span>") //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
+
+// Status ({status:colour|TITLE}), rendered as the status macro or, if it is
+// disabled, as the title in bold
+func (r *ConfluenceRenderer) renderStatus(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*status)
+	if !r.opts.macroEnabled("status") {
+		_, _ = w.WriteString("<strong>")                 //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(n.title))) //nolint:errcheck
+		_, _ = w.WriteString("</strong>")                //nolint:errcheck
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="status">`) //nolint:errcheck
+	writeMacroParam(w, "colour", n.colour)
+	writeMacroParam(w, "title", n.title)
+	_, _ = w.WriteString(`</ac:structured-macro>`) //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// JiraIssue (an issue key in text), rendered as the Jira issue macro or, if
+// it is disabled, as the key
+func (r *ConfluenceRenderer) renderJiraIssue(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*jiraIssue)
+	if !r.opts.macroEnabled("jira") {
+		_, _ = w.WriteString(n.key) //nolint:errcheck
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="jira">`) //nolint:errcheck
+	writeMacroParam(w, "server", r.opts.Jira.Server)
+	writeMacroParam(w, "serverId", r.opts.Jira.ServerID)
+	writeMacroParam(w, "key", n.key)
+	_, _ = w.WriteString(`</ac:structured-macro>`) //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// Mention (@{Name} or @email), rendered as a user link once resolved to an
+// account ID, otherwise as written
+func (r *ConfluenceRenderer) renderMention(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*mention)
+	if r.opts.ResolveUser != nil {
+		if accountID, err := r.opts.ResolveUser(n.query); err == nil {
+			_, _ = w.WriteString(`<ac:link><ri:user ri:account-id="`) //nolint:errcheck
+			_, _ = w.Write(util.EscapeHTML([]byte(accountID)))        //nolint:errcheck
+			_, _ = w.WriteString(`" /></ac:link>`)                    //nolint:errcheck
+			return ast.WalkContinue, nil
+		}
+	}
+	_, _ = w.Write(util.EscapeHTML([]byte(n.raw))) //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// Date, rendered as a time element that Confluence shows as a date lozenge
+func (r *ConfluenceRenderer) renderDate(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString(`<time datetime="` + node.(*date).value + `" />`) //nolint:errcheck
+	}
 	return ast.WalkContinue, nil
 }
 
@@ -369,8 +1212,8 @@ func (r *ConfluenceRenderer) renderText(
 	if entering {
 		n := node.(*ast.Text)
 		segment := n.Segment
-		_, _ = w.Write(util.EscapeHTML(segment.Value(source))) //nolint:errcheck
-		if n.HardLineBreak() {
+		writeEmojiText(w, segment.Value(source))
+		if n.HardLineBreak() || (n.SoftLineBreak() && r.opts.HardWraps) {
 			_, _ = w.WriteString("<br />\n") //nolint:errcheck
 		} else if n.SoftLineBreak() {
 			_ = w.WriteByte('\n') //nolint:errcheck
@@ -388,3 +1231,89 @@ func (r *ConfluenceRenderer) renderString(
 	}
 	return ast.WalkContinue, nil
 }
+
+// Footnotes become superscript links to anchor macros in a numbered list at
+// the end of the page. Confluence strips id attributes, so HTML fragment
+// links would go nowhere. Without the anchor macro only the numbers remain.
+
+// footnoteRefAnchor names the anchor at a footnote reference. A footnote
+// cited more than once gets one anchor per citation.
+func footnoteRefAnchor(index, refIndex int) string {
+	if refIndex > 0 {
+		return fmt.Sprintf("fnref-%d-%d", index, refIndex)
+	}
+	return fmt.Sprintf("fnref-%d", index)
+}
+
+// writeAnchor writes an anchor macro named name
+func writeAnchor(w util.BufWriter, name string) {
+	_, _ = w.WriteString(`<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">`) //nolint:errcheck
+	_, _ = w.WriteString(name)                                                              //nolint:errcheck
+	_, _ = w.WriteString(`</ac:parameter></ac:structured-macro>`)                           //nolint:errcheck
+}
+
+// writeAnchorLink writes a link with plain text body to the anchor name
+func writeAnchorLink(w util.BufWriter, name, text string) {
+	_, _ = w.WriteString(`<ac:link ac:anchor="`)                    //nolint:errcheck
+	_, _ = w.WriteString(name)                                      //nolint:errcheck
+	_, _ = w.WriteString(`"><ac:plain-text-link-body><![CDATA[`)    //nolint:errcheck
+	_, _ = w.WriteString(text)                                      //nolint:errcheck
+	_, _ = w.WriteString(`]]></ac:plain-text-link-body></ac:link>`) //nolint:errcheck
+}
+
+// FootnoteLink
+func (r *ConfluenceRenderer) renderFootnoteLink(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*extast.FootnoteLink)
+	index := strconv.Itoa(n.Index)
+	_, _ = w.WriteString("<sup>") //nolint:errcheck
+	if r.opts.macroEnabled("anchor") {
+		writeAnchor(w, footnoteRefAnchor(n.Index, n.RefIndex))
+		writeAnchorLink(w, "fn-"+index, index)
+	} else {
+		_, _ = w.WriteString(index) //nolint:errcheck
+	}
+	_, _ = w.WriteString("</sup>") //nolint:errcheck
+	return ast.WalkContinue, nil
+}
+
+// FootnoteBacklink
+func (r *ConfluenceRenderer) renderFootnoteBacklink(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && r.opts.macroEnabled("anchor") {
+		n := node.(*extast.FootnoteBacklink)
+		_, _ = w.WriteString(" ") //nolint:errcheck
+		writeAnchorLink(w, footnoteRefAnchor(n.Index, n.RefIndex), "↩")
+	}
+	return ast.WalkContinue, nil
+}
+
+// Footnote
+func (r *ConfluenceRenderer) renderFootnote(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*extast.Footnote)
+		_, _ = w.WriteString("<li>") //nolint:errcheck
+		if r.opts.macroEnabled("anchor") {
+			writeAnchor(w, "fn-"+strconv.Itoa(n.Index))
+		}
+		_ = w.WriteByte('\n') //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</li>\n") //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
+
+// FootnoteList
+func (r *ConfluenceRenderer) renderFootnoteList(
+	w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("<hr />\n<ol>\n") //nolint:errcheck
+	} else {
+		_, _ = w.WriteString("</ol>\n") //nolint:errcheck
+	}
+	return ast.WalkContinue, nil
+}
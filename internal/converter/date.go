@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"bytes"
+	"regexp"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// datePrefix opens a date shortcode
+var datePrefix = []byte("{date:")
+
+// isoDateLayout is the only date format accepted
+const isoDateLayout = "2006-01-02"
+
+// bareDateRegex matches ISO dates written as whole words
+var bareDateRegex = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+
+// kindDate is the node kind for dates
+var kindDate = ast.NewNodeKind("Date")
+
+// date is a calendar date, rendered as the Confluence date lozenge
+type date struct {
+	ast.BaseInline
+	value string
+}
+
+func (n *date) Kind() ast.NodeKind {
+	return kindDate
+}
+
+func (n *date) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": n.value}, nil)
+}
+
+// validDate reports whether s is a real ISO date such as 2025-03-01
+func validDate(s string) bool {
+	_, err := time.Parse(isoDateLayout, s)
+	return err == nil
+}
+
+// dateParser parses {date:2025-03-01} shortcodes into date nodes. Anything
+// but a valid ISO date stays as text.
+type dateParser struct{}
+
+func newDateParser() parser.InlineParser {
+	return &dateParser{}
+}
+
+func (p *dateParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *dateParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if len(line) < len(datePrefix) || !bytes.EqualFold(line[:len(datePrefix)], datePrefix) {
+		return nil
+	}
+	end := bytes.IndexByte(line, '}')
+	if end < 0 {
+		return nil
+	}
+	value := string(bytes.TrimSpace(line[len(datePrefix):end]))
+	if !validDate(value) {
+		return nil
+	}
+	block.Advance(end + 1)
+	return &date{value: value}
+}
+
+// bareDateTransformer turns ISO dates in prose into date nodes if enabled
+// (Options.BareDates). Dates joined to other text, as in 2025-03-01T10:00 or
+// v2025-03-01, are left alone.
+type bareDateTransformer struct {
+	enabled bool
+}
+
+func newBareDateTransformer(enabled bool) parser.ASTTransformer {
+	return &bareDateTransformer{enabled: enabled}
+}
+
+func (t *bareDateTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	if !t.enabled {
+		return
+	}
+	source := reader.Source()
+	for _, n := range proseTexts(doc) {
+		matches := bareDateRegex.FindAllIndex(n.Segment.Value(source), -1)
+		replaceInText(n, source, matches, func(value []byte, m []int) ast.Node {
+			s := string(value[m[0]:m[1]])
+			if !validDate(s) || !bareDateBoundary(value, m[0], m[1]) {
+				return nil
+			}
+			return &date{value: s}
+		})
+	}
+}
+
+// bareDateBoundary reports whether the date at value[start:end] stands
+// alone rather than being part of a longer token such as 2025-03-01-rc1,
+// a/2025-03-01, or 2025-03-01:00
+func bareDateBoundary(value []byte, start, end int) bool {
+	if start > 0 && bytes.IndexByte([]byte("-/:."), value[start-1]) >= 0 {
+		return false
+	}
+	return end == len(value) || bytes.IndexByte([]byte("-/:"), value[end]) < 0
+}
@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/net/html"
+)
+
+// detailsClose is the HTML block that ends a <details> section
+var detailsClose = []byte("</details>")
+
+// kindExpand is the node kind for <details> sections
+var kindExpand = ast.NewNodeKind("Expand")
+
+// expand is a collapsible section written as HTML details:
+//
+//	<details>
+//	<summary>Title</summary>
+//
+//	Markdown content
+//
+//	</details>
+//
+// body holds any HTML that followed the summary in the opening block; the
+// Markdown content between the opening and closing blocks is its children.
+type expand struct {
+	ast.BaseBlock
+	title string
+	body  []byte
+}
+
+func (n *expand) Kind() ast.NodeKind {
+	return kindExpand
+}
+
+func (n *expand) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Title": n.title}, nil)
+}
+
+// detailsTransformer wraps the blocks between a <details> HTML block and its
+// matching </details> block in expand nodes. Unmatched blocks are left as
+// raw HTML.
+type detailsTransformer struct{}
+
+func newDetailsTransformer() parser.ASTTransformer {
+	return &detailsTransformer{}
+}
+
+func (t *detailsTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	wrapDetails(doc, reader.Source())
+}
+
+// wrapDetails replaces details sections among parent's children, then
+// recurses into every child block
+func wrapDetails(parent ast.Node, source []byte) {
+	for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+		raw := htmlBlockValue(child, source)
+		if raw == nil {
+			continue
+		}
+		node, ok := openDetails(raw)
+		if !ok {
+			continue
+		}
+		if node.body != nil && bytes.HasSuffix(node.body, detailsClose) {
+			// <details><summary>Title</summary>text</details> in one block
+			node.body = bytes.TrimSpace(bytes.TrimSuffix(node.body, detailsClose))
+			parent.ReplaceChild(parent, child, node)
+			child = node
+			continue
+		}
+		closing := matchingDetailsClose(child, source)
+		if closing == nil {
+			continue
+		}
+		for inner := child.NextSibling(); inner != closing; {
+			next := inner.NextSibling()
+			node.AppendChild(node, inner)
+			inner = next
+		}
+		parent.RemoveChild(parent, closing)
+		parent.ReplaceChild(parent, child, node)
+		child = node
+	}
+	for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.Type() == ast.TypeBlock && child.HasChildren() {
+			wrapDetails(child, source)
+		}
+	}
+}
+
+// matchingDetailsClose finds the </details> block closing the section opened
+// by open, skipping nested sections, or nil if there is none
+func matchingDetailsClose(open ast.Node, source []byte) ast.Node {
+	depth := 0
+	for sib := open.NextSibling(); sib != nil; sib = sib.NextSibling() {
+		raw := htmlBlockValue(sib, source)
+		if raw == nil {
+			continue
+		}
+		if bytes.EqualFold(bytes.TrimSpace(raw), detailsClose) {
+			if depth == 0 {
+				return sib
+			}
+			depth--
+		} else if node, ok := openDetails(raw); ok && !bytes.HasSuffix(node.body, detailsClose) {
+			depth++
+		}
+	}
+	return nil
+}
+
+// htmlBlockValue returns the raw HTML of n, or nil if n is not an HTML block
+func htmlBlockValue(n ast.Node, source []byte) []byte {
+	block, ok := n.(*ast.HTMLBlock)
+	if !ok {
+		return nil
+	}
+	var raw bytes.Buffer
+	for i := 0; i < block.Lines().Len(); i++ {
+		line := block.Lines().At(i)
+		raw.Write(line.Value(source))
+	}
+	if block.HasClosure() {
+		raw.Write(block.ClosureLine.Value(source))
+	}
+	return raw.Bytes()
+}
+
+// openDetails reads an HTML block starting with <details> and an optional
+// <summary>, returning an expand node titled by the summary text with any
+// remaining HTML as its body
+func openDetails(raw []byte) (*expand, bool) {
+	z := html.NewTokenizer(bytes.NewReader(raw))
+	offset := 0
+	next := func() html.Token {
+		z.Next()
+		offset += len(z.Raw())
+		return z.Token()
+	}
+	skipSpace := func() html.Token {
+		for {
+			tok := next()
+			if tok.Type != html.TextToken || strings.TrimSpace(tok.Data) != "" {
+				return tok
+			}
+		}
+	}
+
+	tok := skipSpace()
+	if tok.Type != html.StartTagToken || tok.Data != "details" {
+		return nil, false
+	}
+	node := &expand{}
+	start := offset
+	tok = skipSpace()
+	if tok.Type != html.StartTagToken || tok.Data != "summary" {
+		if rest := bytes.TrimSpace(raw[start:]); len(rest) > 0 {
+			node.body = rest
+		}
+		return node, true
+	}
+	var title strings.Builder
+	for {
+		tok = next()
+		if tok.Type == html.ErrorToken || (tok.Type == html.EndTagToken && tok.Data == "summary") {
+			break
+		}
+		if tok.Type == html.TextToken {
+			title.WriteString(tok.Data)
+		}
+	}
+	node.title = strings.Join(strings.Fields(title.String()), " ")
+	if rest := bytes.TrimSpace(raw[min(offset, len(raw)):]); len(rest) > 0 {
+		node.body = rest
+	}
+	return node, true
+}
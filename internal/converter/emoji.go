@@ -0,0 +1,276 @@
+package converter
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// emoji is an emoji known to the converter. Emoticon names the Confluence
+// emoticon it maps to, or is empty if Confluence has none, in which case the
+// character itself is written.
+type emoji struct {
+	emoticon string
+	char     string
+}
+
+// emojiShortcodes maps :shortcode: names to emoji. The names follow GitHub.
+var emojiShortcodes = map[string]emoji{
+	"smile":                  {"smile", "🙂"},
+	"slightly_smiling_face":  {"smile", "🙂"},
+	"blush":                  {"smile", "😊"},
+	"disappointed":           {"sad", "😞"},
+	"slightly_frowning_face": {"sad", "🙁"},
+	"stuck_out_tongue":       {"cheeky", "😛"},
+	"grinning":               {"laugh", "😀"},
+	"smiley":                 {"laugh", "😃"},
+	"laughing":               {"laugh", "😆"},
+	"wink":                   {"wink", "😉"},
+	"+1":                     {"thumbs-up", "👍"},
+	"thumbsup":               {"thumbs-up", "👍"},
+	"-1":                     {"thumbs-down", "👎"},
+	"thumbsdown":             {"thumbs-down", "👎"},
+	"information_source":     {"information", "ℹ️"},
+	"white_check_mark":       {"tick", "✅"},
+	"heavy_check_mark":       {"tick", "✔️"},
+	"x":                      {"cross", "❌"},
+	"warning":                {"warning", "⚠️"},
+	"heavy_plus_sign":        {"plus", "➕"},
+	"heavy_minus_sign":       {"minus", "➖"},
+	"question":               {"question", "❓"},
+	"bulb":                   {"light-on", "💡"},
+	"star":                   {"yellow-star", "⭐"},
+	"heart":                  {"heart", "❤️"},
+	"broken_heart":           {"broken-heart", "💔"},
+	"rocket":                 {"", "🚀"},
+	"tada":                   {"", "🎉"},
+	"fire":                   {"", "🔥"},
+	"bug":                    {"", "🐛"},
+	"memo":                   {"", "📝"},
+	"lock":                   {"", "🔒"},
+	"construction":           {"", "🚧"},
+	"eyes":                   {"", "👀"},
+	"clap":                   {"", "👏"},
+	"sparkles":               {"", "✨"},
+	"zap":                    {"", "⚡"},
+	"wrench":                 {"", "🔧"},
+	"calendar":               {"", "📆"},
+	"no_entry":               {"", "⛔"},
+	"stop_sign":              {"", "🛑"},
+}
+
+// unicodeEmoticons maps emoji characters to Confluence emoticons. Emoji
+// not listed are left as the characters they are.
+var unicodeEmoticons = map[rune]string{
+	'🙂': "smile",
+	'😊': "smile",
+	'😞': "sad",
+	'🙁': "sad",
+	'😛': "cheeky",
+	'😀': "laugh",
+	'😃': "laugh",
+	'😆': "laugh",
+	'😉': "wink",
+	'👍': "thumbs-up",
+	'👎': "thumbs-down",
+	'ℹ': "information",
+	'✅': "tick",
+	'✔': "tick",
+	'❌': "cross",
+	'⚠': "warning",
+	'➕': "plus",
+	'➖': "minus",
+	'❓': "question",
+	'💡': "light-on",
+	'⭐': "yellow-star",
+	'❤': "heart",
+	'💔': "broken-heart",
+}
+
+// emoticonEmoji maps Confluence emoticons to the emoji shown for them, for
+// emoticons stored without their emoji
+var emoticonEmoji = map[string]string{
+	"smile":        "🙂",
+	"sad":          "🙁",
+	"cheeky":       "😛",
+	"laugh":        "😀",
+	"wink":         "😉",
+	"thumbs-up":    "👍",
+	"thumbs-down":  "👎",
+	"information":  "ℹ️",
+	"tick":         "✅",
+	"cross":        "❌",
+	"warning":      "⚠️",
+	"plus":         "➕",
+	"minus":        "➖",
+	"question":     "❓",
+	"light-on":     "💡",
+	"light-off":    "💡",
+	"yellow-star":  "⭐",
+	"red-star":     "⭐",
+	"green-star":   "⭐",
+	"blue-star":    "⭐",
+	"heart":        "❤️",
+	"broken-heart": "💔",
+}
+
+// variationSelector16 asks for the emoji rather than text presentation of
+// the preceding character, as in "⚠️"
+const variationSelector16 = '️'
+
+// kindEmoji is the node kind for emoji
+var kindEmoji = ast.NewNodeKind("Emoji")
+
+// emojiNode is an emoji written as a :shortcode:
+type emojiNode struct {
+	ast.BaseInline
+	emoji
+}
+
+func (n *emojiNode) Kind() ast.NodeKind {
+	return kindEmoji
+}
+
+func (n *emojiNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Emoticon": n.emoticon, "Char": n.char}, nil)
+}
+
+// emojiParser parses :shortcode: emoji. Unknown shortcodes stay as text.
+// Emoji characters are mapped as text is written (see writeEmojiText), as
+// goldmark only triggers inline parsers on ASCII punctuation.
+type emojiParser struct{}
+
+func newEmojiParser() parser.InlineParser {
+	return &emojiParser{}
+}
+
+func (p *emojiParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (p *emojiParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	end := 1
+	for end < len(line) && isShortcodeByte(line[end]) {
+		end++
+	}
+	if end == 1 || end >= len(line) || line[end] != ':' {
+		return nil
+	}
+	e, ok := emojiShortcodes[string(line[1:end])]
+	if !ok {
+		return nil
+	}
+	block.Advance(end + 1)
+	return &emojiNode{emoji: e}
+}
+
+// isShortcodeByte reports whether c may appear in a shortcode name
+func isShortcodeByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '+' || c == '-'
+}
+
+// writeEmoticon writes the Confluence emoticon called name
+func writeEmoticon(w util.BufWriter, name string) {
+	_, _ = w.WriteString(`<ac:emoticon ac:name="`) //nolint:errcheck
+	_, _ = w.WriteString(name)                     //nolint:errcheck
+	_, _ = w.WriteString(`" />`)                   //nolint:errcheck
+}
+
+// writeEmojiText writes escaped text, replacing emoji characters that have a
+// Confluence emoticon with the emoticon
+func writeEmojiText(w util.BufWriter, value []byte) {
+	start := 0
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRune(value[i:])
+		name, ok := unicodeEmoticons[r]
+		if !ok {
+			i += size
+			continue
+		}
+		_, _ = w.Write(util.EscapeHTML(value[start:i])) //nolint:errcheck
+		writeEmoticon(w, name)
+		i += size
+		if next, n := utf8.DecodeRune(value[i:]); next == variationSelector16 {
+			i += n
+		}
+		start = i
+	}
+	_, _ = w.Write(util.EscapeHTML(value[start:])) //nolint:errcheck
+}
+
+// emoticonRegex matches an emoticon, capturing its attributes
+var emoticonRegex = regexp.MustCompile(`<ac:emoticon([^>]*?)/?>(?:\s*</ac:emoticon>)?`)
+
+// convertEmoticons rewrites emoticons as the emoji they show: the stored
+// fallback character, the character for the emoji ID, or the one for the
+// emoticon name, in that order. Custom emoji keep their :shortcode:
+// fallback text, and emoticons with none of these are dropped.
+func convertEmoticons(storage string) string {
+	return emoticonRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		attrs := linkAttrs(emoticonRegex.FindStringSubmatch(match)[1])
+		if fallback := attrs["ac:emoji-fallback"]; fallback != "" && utf8.RuneLen([]rune(fallback)[0]) > 1 {
+			return fallback
+		}
+		if char, ok := emojiFromID(attrs["ac:emoji-id"]); ok {
+			return char
+		}
+		if fallback := attrs["ac:emoji-fallback"]; fallback != "" {
+			return html.EscapeString(fallback)
+		}
+		return emoticonEmoji[attrs["ac:name"]]
+	})
+}
+
+// emojiFromID returns the emoji for an emoji ID as EmojiID writes them
+func emojiFromID(id string) (string, bool) {
+	if id == "" {
+		return "", false
+	}
+	var b strings.Builder
+	for part := range strings.SplitSeq(id, "-") {
+		r, err := strconv.ParseInt(part, 16, 32)
+		if err != nil || r < 0x80 || !utf8.ValidRune(rune(r)) {
+			return "", false
+		}
+		b.WriteRune(rune(r))
+	}
+	return b.String(), true
+}
+
+// EmojiID returns the ID Confluence stores for a page title emoji: the
+// emoji's code points in lower-case hex, joined by hyphens. s is an emoji
+// character or a :shortcode:. Variation selectors are dropped.
+func EmojiID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if name, ok := strings.CutPrefix(s, ":"); ok {
+		e, known := emojiShortcodes[strings.TrimSuffix(name, ":")]
+		if !known {
+			return "", fmt.Errorf("unknown emoji shortcode %s", s)
+		}
+		s = e.char
+	}
+
+	var ids []string
+	for _, r := range s {
+		if r == '\ufe0f' {
+			continue
+		}
+		if r < 0x80 {
+			return "", fmt.Errorf("%q is not an emoji", s)
+		}
+		ids = append(ids, strconv.FormatInt(int64(r), 16))
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("emoji cannot be empty")
+	}
+	return strings.Join(ids, "-"), nil
+}
@@ -0,0 +1,321 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Frontmatter holds page settings from a YAML block at the top of a
+// Markdown file:
+//
+//	---
+//	title: Release Notes
+//	space: DOCS
+//	parent: 123456
+//	status: draft
+//	labels: [release, notes]
+//	emoji: ":rocket:"
+//	full-width: true
+//	---
+//
+// Files written by page export also carry the page's id and version. Keys
+// acon does not use are ignored, so the same file can carry settings for
+// other tools.
+type Frontmatter struct {
+	Title  string
+	Space  string
+	Parent string
+	Status string
+	Labels []string
+	// ID is the page the file was exported from
+	ID string
+	// Version is the page version the file was exported from, or 0
+	Version int
+	// Emoji is the page title emoji, as a character or :shortcode:
+	Emoji string
+	// FullWidth sets the page appearance: full width if true, the default
+	// fixed width if false, and left as it is if nil
+	FullWidth *bool
+}
+
+// frontmatterDelimiter opens and closes a frontmatter block
+const frontmatterDelimiter = "---"
+
+// SplitFrontmatter separates a leading frontmatter block from markdown and
+// returns it with the remaining body. Input without frontmatter is returned
+// unchanged with a zero Frontmatter.
+func SplitFrontmatter(markdown []byte) (Frontmatter, []byte, error) {
+	text := bytes.TrimPrefix(markdown, []byte("\ufeff"))
+	first, rest, ok := bytes.Cut(text, []byte("\n"))
+	if !ok || string(bytes.TrimRight(first, " \t\r")) != frontmatterDelimiter {
+		return Frontmatter{}, markdown, nil
+	}
+
+	var block []string
+	for {
+		var line []byte
+		line, rest, ok = bytes.Cut(rest, []byte("\n"))
+		trimmed := strings.TrimRight(string(line), " \t\r")
+		if trimmed == frontmatterDelimiter || trimmed == "..." {
+			break
+		}
+		if !ok {
+			return Frontmatter{}, nil, fmt.Errorf("frontmatter: missing closing %s", frontmatterDelimiter)
+		}
+		block = append(block, trimmed)
+	}
+
+	fm, err := parseFrontmatter(block)
+	if err != nil {
+		return Frontmatter{}, nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return fm, rest, nil
+}
+
+// parseFrontmatter reads the subset of YAML used in frontmatter: comments,
+// "key: value" scalars (plain or quoted), and string lists written either
+// inline ([a, b]) or as "- item" lines under the key.
+func parseFrontmatter(lines []string) (Frontmatter, error) {
+	var fm Frontmatter
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if isBlankYAML(line) {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return Frontmatter{}, fmt.Errorf("line %d: unexpected indentation", i+2)
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Frontmatter{}, fmt.Errorf("line %d: expected key: value", i+2)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(stripYAMLComment(value))
+
+		// Gather the block belonging to this key: indented lines, or list
+		// items, which YAML allows at the key's own indentation
+		var nested []string
+		for i+1 < len(lines) && (isBlankYAML(lines[i+1]) || lines[i+1][0] == ' ' || lines[i+1][0] == '\t' ||
+			(value == "" && strings.HasPrefix(lines[i+1], "-"))) {
+			i++
+			if !isBlankYAML(lines[i]) {
+				nested = append(nested, strings.TrimSpace(stripYAMLComment(lines[i])))
+			}
+		}
+
+		switch key {
+		case "title", "space", "parent", "status", "emoji", "id":
+			if len(nested) > 0 {
+				return Frontmatter{}, fmt.Errorf("%s must be a single value", key)
+			}
+			s, err := yamlScalar(value)
+			if err != nil {
+				return Frontmatter{}, fmt.Errorf("%s: %w", key, err)
+			}
+			switch key {
+			case "title":
+				fm.Title = s
+			case "space":
+				fm.Space = s
+			case "parent":
+				fm.Parent = s
+			case "status":
+				fm.Status = s
+			case "emoji":
+				fm.Emoji = s
+			case "id":
+				fm.ID = s
+			}
+		case "version":
+			if len(nested) > 0 {
+				return Frontmatter{}, fmt.Errorf("%s must be a single value", key)
+			}
+			s, err := yamlScalar(value)
+			if err != nil {
+				return Frontmatter{}, fmt.Errorf("%s: %w", key, err)
+			}
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return Frontmatter{}, fmt.Errorf("%s: expected a positive number, got %q", key, s)
+			}
+			fm.Version = n
+		case "full-width":
+			if len(nested) > 0 {
+				return Frontmatter{}, fmt.Errorf("%s must be a single value", key)
+			}
+			b, err := yamlBool(value)
+			if err != nil {
+				return Frontmatter{}, fmt.Errorf("%s: %w", key, err)
+			}
+			fm.FullWidth = &b
+		case "labels":
+			labels, err := yamlList(value, nested)
+			if err != nil {
+				return Frontmatter{}, fmt.Errorf("labels: %w", err)
+			}
+			fm.Labels = labels
+		}
+	}
+	return fm, nil
+}
+
+// isBlankYAML reports whether line holds nothing but a comment or spaces
+func isBlankYAML(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// stripYAMLComment removes a trailing " # comment" outside quotes
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// yamlScalar unquotes a scalar value
+func yamlScalar(value string) (string, error) {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			s, err := strconv.Unquote(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid quoted string %s", value)
+			}
+			return s, nil
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+		}
+	}
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+		return "", fmt.Errorf("must be a single value")
+	}
+	return value, nil
+}
+
+// yamlBool reads a true/false value, allowing YAML's yes/no and on/off
+func yamlBool(value string) (bool, error) {
+	s, err := yamlScalar(value)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(s) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false, got %q", s)
+}
+
+// yamlList reads an inline [a, b] list, a "- item" block, or a lone scalar
+func yamlList(value string, nested []string) ([]string, error) {
+	var items []string
+	switch {
+	case value != "" && len(nested) > 0:
+		return nil, fmt.Errorf("mixes an inline value with a block")
+	case strings.HasPrefix(value, "["):
+		if !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("unterminated list")
+		}
+		inner := strings.TrimSpace(value[1 : len(value)-1])
+		if inner == "" {
+			return []string{}, nil
+		}
+		for _, part := range strings.Split(inner, ",") {
+			s, err := yamlScalar(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, s)
+		}
+	case len(nested) > 0:
+		for _, line := range nested {
+			if !strings.HasPrefix(line, "-") {
+				return nil, fmt.Errorf("expected \"- item\", got %q", line)
+			}
+			s, err := yamlScalar(strings.TrimSpace(line[1:]))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, s)
+		}
+	case value != "":
+		s, err := yamlScalar(value)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, nil
+}
+
+// FormatFrontmatter writes fm as a frontmatter block, ending with a blank
+// line, that SplitFrontmatter reads back. Unset fields are left out.
+func FormatFrontmatter(fm Frontmatter) string {
+	var b strings.Builder
+	b.WriteString(frontmatterDelimiter + "\n")
+	for _, field := range []struct{ key, value string }{
+		{"title", fm.Title},
+		{"id", fm.ID},
+		{"space", fm.Space},
+		{"parent", fm.Parent},
+		{"status", fm.Status},
+		{"emoji", fm.Emoji},
+	} {
+		if field.value != "" {
+			b.WriteString(field.key + ": " + yamlQuote(field.value) + "\n")
+		}
+	}
+	if fm.Version > 0 {
+		b.WriteString("version: " + strconv.Itoa(fm.Version) + "\n")
+	}
+	if len(fm.Labels) > 0 {
+		quoted := make([]string, len(fm.Labels))
+		for i, label := range fm.Labels {
+			quoted[i] = yamlQuote(label)
+		}
+		b.WriteString("labels: [" + strings.Join(quoted, ", ") + "]\n")
+	}
+	if fm.FullWidth != nil {
+		b.WriteString("full-width: " + strconv.FormatBool(*fm.FullWidth) + "\n")
+	}
+	b.WriteString(frontmatterDelimiter + "\n\n")
+	return b.String()
+}
+
+// yamlQuote returns s as a YAML scalar, double-quoted unless it is made of
+// letters, digits, spaces, and _ . / - alone, which read back the same
+// plain. Numbers are quoted too, as parent and id values are strings.
+func yamlQuote(s string) string {
+	plain := s == strings.TrimSpace(s) && strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" _./-", r)
+	}) < 0 && strings.IndexFunc(s, unicode.IsLetter) >= 0 && !strings.HasPrefix(s, "-")
+	if plain && !isYAMLKeyword(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// isYAMLKeyword reports whether a plain scalar would read as a boolean or
+// null to other YAML tools
+func isYAMLKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	fullWidth, fixedWidth := true, false
+	tests := []struct {
+		name     string
+		input    string
+		want     Frontmatter
+		wantBody string
+	}{
+		{
+			name:     "no frontmatter",
+			input:    "# Title\n\nBody\n",
+			wantBody: "# Title\n\nBody\n",
+		},
+		{
+			name:     "thematic break later is not frontmatter",
+			input:    "Intro\n---\nMore\n",
+			wantBody: "Intro\n---\nMore\n",
+		},
+		{
+			name: "all fields",
+			input: `---
+title: Release Notes
+space: DOCS
+parent: 123456
+status: draft
+labels: [release, "q3 notes", 'ops']
+---
+# Body
+`,
+			want:     Frontmatter{Title: "Release Notes", Space: "DOCS", Parent: "123456", Status: "draft", Labels: []string{"release", "q3 notes", "ops"}},
+			wantBody: "# Body\n",
+		},
+		{
+			name: "block lists, quotes, comments and unknown keys",
+			input: "---\r\n" +
+				"# publish settings\r\n" +
+				"title: \"Q3: \\\"Plan\\\"\"  # quoted\r\n" +
+				"author:\r\n" +
+				"  name: Sam\r\n" +
+				"labels:\r\n" +
+				"  - alpha\r\n" +
+				"  - beta # trailing\r\n" +
+				"tags:\r\n" +
+				"- ignored\r\n" +
+				"...\r\n" +
+				"Body\r\n",
+			want:     Frontmatter{Title: `Q3: "Plan"`, Labels: []string{"alpha", "beta"}},
+			wantBody: "Body\r\n",
+		},
+		{
+			name:     "unindented block list",
+			input:    "---\nlabels:\n- one\n- two\n---\n",
+			want:     Frontmatter{Labels: []string{"one", "two"}},
+			wantBody: "",
+		},
+		{
+			name:     "single label scalar",
+			input:    "---\nlabels: solo\ntitle: It's here\n---\nx",
+			want:     Frontmatter{Title: "It's here", Labels: []string{"solo"}},
+			wantBody: "x",
+		},
+		{
+			name:     "emoji and full width",
+			input:    "---\nemoji: \"🚀\"\nfull-width: yes\n---\nx",
+			want:     Frontmatter{Emoji: "🚀", FullWidth: &fullWidth},
+			wantBody: "x",
+		},
+		{
+			name:     "fixed width",
+			input:    "---\nemoji: :memo:\nfull-width: false\n---\nx",
+			want:     Frontmatter{Emoji: ":memo:", FullWidth: &fixedWidth},
+			wantBody: "x",
+		},
+		{
+			name:     "byte order mark",
+			input:    "\ufeff---\ntitle: T\n---\nx",
+			want:     Frontmatter{Title: "T"},
+			wantBody: "x",
+		},
+		{
+			name:     "export fields",
+			input:    "---\ntitle: T\nid: \"123\"\nversion: 4\n---\nx",
+			want:     Frontmatter{Title: "T", ID: "123", Version: 4},
+			wantBody: "x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := SplitFrontmatter([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("SplitFrontmatter() error = %v", err)
+			}
+			if !reflect.DeepEqual(fm, tt.want) {
+				t.Errorf("frontmatter = %+v, want %+v", fm, tt.want)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSplitFrontmatter_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"unclosed", "---\ntitle: x\n", "missing closing ---"},
+		{"not key value", "---\njust text\n---\n", "line 2: expected key: value"},
+		{"list as title", "---\ntitle: [a, b]\n---\n", "title: must be a single value"},
+		{"nested title", "---\ntitle:\n  - a\n---\n", "title must be a single value"},
+		{"bad label item", "---\nlabels:\n  name: x\n---\n", `expected "- item"`},
+		{"unterminated list", "---\nlabels: [a, b\n---\n", "unterminated list"},
+		{"stray indentation", "---\n  title: x\n---\n", "unexpected indentation"},
+		{"full width not boolean", "---\nfull-width: wide\n---\n", `full-width: expected true or false, got "wide"`},
+		{"version not a number", "---\nversion: two\n---\n", `version: expected a positive number, got "two"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := SplitFrontmatter([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatFrontmatter(t *testing.T) {
+	fullWidth := true
+	fm := Frontmatter{
+		Title:     "Release: Q3",
+		ID:        "123",
+		Space:     "DOCS",
+		Parent:    "456",
+		Labels:    []string{"release", "q3 notes", "yes"},
+		Version:   7,
+		FullWidth: &fullWidth,
+	}
+	got := FormatFrontmatter(fm)
+	want := "---\ntitle: \"Release: Q3\"\nid: \"123\"\nspace: DOCS\nparent: \"456\"\nversion: 7\n" +
+		"labels: [release, q3 notes, \"yes\"]\nfull-width: true\n---\n\n"
+	if got != want {
+		t.Errorf("FormatFrontmatter() = %q, want %q", got, want)
+	}
+
+	back, body, err := SplitFrontmatter([]byte(got + "# Body\n"))
+	if err != nil {
+		t.Fatalf("SplitFrontmatter() error = %v", err)
+	}
+	if !reflect.DeepEqual(back, fm) {
+		t.Errorf("read back %+v, want %+v", back, fm)
+	}
+	if string(body) != "\n# Body\n" {
+		t.Errorf("body = %q", body)
+	}
+}
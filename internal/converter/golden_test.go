@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files from the converter's current output
+var update = flag.Bool("update", false, "rewrite testdata/golden expected output")
+
+// goldenDir holds Markdown documents with the storage format and round-trip
+// Markdown expected for each
+const goldenDir = "../../testdata/golden"
+
+// TestGolden converts each NAME.md in the golden corpus to storage format
+// and back in round-trip mode, comparing with NAME.storage and
+// NAME.roundtrip.md. Run with -update to accept the current output.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		if strings.HasSuffix(input, ".roundtrip.md") {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(input), ".md")
+		t.Run(name, func(t *testing.T) {
+			markdown, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			storage, err := MarkdownToStorage(string(markdown))
+			if err != nil {
+				t.Fatalf("MarkdownToStorage() error = %v", err)
+			}
+			result, err := StorageToMarkdownWithOptions(storage, StorageOptions{RoundTrip: true})
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			checkGolden(t, filepath.Join(goldenDir, name+".storage"), storage)
+			checkGolden(t, filepath.Join(goldenDir, name+".roundtrip.md"), result+"\n")
+		})
+	}
+}
+
+// checkGolden compares got with the golden file at path, or with -update
+// writes got to it
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run go test -run TestGolden -update)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the converter output:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
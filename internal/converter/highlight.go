@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// highlightColour is the background of highlighted text, Confluence's
+// light yellow
+const highlightColour = "rgb(255,240,179)"
+
+// kindHighlight is the node kind for highlighted text
+var kindHighlight = ast.NewNodeKind("Highlight")
+
+// highlight is text marked with ==double equals==
+type highlight struct {
+	ast.BaseInline
+}
+
+func (n *highlight) Kind() ast.NodeKind {
+	return kindHighlight
+}
+
+func (n *highlight) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// highlightDelimiterProcessor pairs == delimiters into highlight nodes
+type highlightDelimiterProcessor struct{}
+
+func (p *highlightDelimiterProcessor) IsDelimiter(b byte) bool {
+	return b == '='
+}
+
+func (p *highlightDelimiterProcessor) CanOpenCloser(opener, closer *parser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+func (p *highlightDelimiterProcessor) OnMatch(consumes int) ast.Node {
+	return &highlight{}
+}
+
+// highlightParser parses ==text== as highlighted text. Like emphasis, the
+// delimiters must hug the text, so a == b stays as written, and runs of
+// other lengths are text.
+type highlightParser struct{}
+
+func newHighlightParser() parser.InlineParser {
+	return &highlightParser{}
+}
+
+func (p *highlightParser) Trigger() []byte {
+	return []byte{'='}
+}
+
+func (p *highlightParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := parser.ScanDelimiter(line, before, 2, &highlightDelimiterProcessor{})
+	if node == nil || node.OriginalLength != 2 || before == '=' {
+		return nil
+	}
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
@@ -0,0 +1,220 @@
+package converter
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// tableHTMLTags maps the elements kept in HTML tables to the element
+// written, with b and i becoming their storage format equivalents
+var tableHTMLTags = map[string]string{
+	"table":    "table",
+	"caption":  "caption",
+	"colgroup": "colgroup",
+	"col":      "col",
+	"thead":    "thead",
+	"tbody":    "tbody",
+	"tfoot":    "tfoot",
+	"tr":       "tr",
+	"th":       "th",
+	"td":       "td",
+	"p":        "p",
+	"br":       "br",
+	"strong":   "strong",
+	"b":        "strong",
+	"em":       "em",
+	"i":        "em",
+	"u":        "u",
+	"s":        "s",
+	"del":      "del",
+	"code":     "code",
+	"sup":      "sup",
+	"sub":      "sub",
+	"ul":       "ul",
+	"ol":       "ol",
+	"li":       "li",
+	"a":        "a",
+}
+
+// kindHTMLTable is the node kind for HTML tables
+var kindHTMLTable = ast.NewNodeKind("HTMLTable")
+
+// htmlTable is a table written in HTML, which can merge cells where a GFM
+// table cannot
+type htmlTable struct {
+	ast.BaseBlock
+	raw []byte
+}
+
+func (n *htmlTable) Kind() ast.NodeKind {
+	return kindHTMLTable
+}
+
+func (n *htmlTable) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// htmlTableTransformer replaces HTML blocks holding a table with htmlTable
+// nodes. Blank lines split a table into several HTML blocks, so blocks are
+// joined until the one that closes the table.
+type htmlTableTransformer struct{}
+
+func newHTMLTableTransformer() parser.ASTTransformer {
+	return &htmlTableTransformer{}
+}
+
+func (t *htmlTableTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var blocks []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == ast.KindHTMLBlock {
+			blocks = append(blocks, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, block := range blocks {
+		if block.Parent() == nil {
+			continue // joined into an earlier table
+		}
+		raw := htmlBlockValue(block, source)
+		if !startsWithTag(raw, "table") {
+			continue
+		}
+		parts := []ast.Node{block}
+		for !containsTag(raw, "/table") {
+			next := parts[len(parts)-1].NextSibling()
+			if next == nil || next.Kind() != ast.KindHTMLBlock {
+				break
+			}
+			raw = append(append(raw, '\n'), htmlBlockValue(next, source)...)
+			parts = append(parts, next)
+		}
+		if !containsTag(raw, "/table") {
+			continue
+		}
+		parent := block.Parent()
+		for _, part := range parts[1:] {
+			parent.RemoveChild(parent, part)
+		}
+		parent.ReplaceChild(parent, block, &htmlTable{raw: raw})
+	}
+}
+
+// startsWithTag reports whether raw HTML opens with the element name
+func startsWithTag(raw []byte, name string) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < len(name)+2 || raw[0] != '<' || !bytes.EqualFold(raw[1:len(name)+1], []byte(name)) {
+		return false
+	}
+	c := raw[len(name)+1]
+	return c == '>' || c == '/' || util.IsSpace(c)
+}
+
+// containsTag reports whether raw HTML contains the tag <name, such as
+// "/table" for a closing tag
+func containsTag(raw []byte, name string) bool {
+	return bytes.Contains(bytes.ToLower(raw), []byte("<"+name))
+}
+
+// writeHTMLTable parses an HTML table and writes it as well-formed XHTML,
+// keeping table structure, cell spans, and inline formatting. Other
+// elements are dropped but their text is kept, and script and style are
+// dropped with their content.
+func writeHTMLTable(w util.BufWriter, raw []byte) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(bytes.NewReader(bytes.TrimSpace(raw)), context)
+	if err != nil {
+		return // only reader errors, and raw is in memory
+	}
+	for _, n := range nodes {
+		writeTableNode(w, n)
+	}
+	_ = w.WriteByte('\n') //nolint:errcheck
+}
+
+// writeTableNode writes n and its children for writeHTMLTable
+func writeTableNode(w util.BufWriter, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if n.Parent != nil && isTableStructure(n.Parent.Data) && strings.TrimSpace(n.Data) == "" {
+			// Whitespace between rows and cells is layout, kept as line breaks
+			if strings.Contains(n.Data, "\n") {
+				_ = w.WriteByte('\n') //nolint:errcheck
+			}
+			return
+		}
+		_, _ = w.Write(util.EscapeHTML([]byte(n.Data))) //nolint:errcheck
+		return
+	case html.ElementNode:
+	default:
+		return // comments and doctypes
+	}
+	if droppedHTMLContent[n.Data] {
+		return
+	}
+	name, ok := tableHTMLTags[n.Data]
+	if !ok {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeTableNode(w, c)
+		}
+		return
+	}
+	_ = w.WriteByte('<')       //nolint:errcheck
+	_, _ = w.WriteString(name) //nolint:errcheck
+	for _, attr := range n.Attr {
+		value, keep := tableAttr(n.Data, attr)
+		if !keep {
+			continue
+		}
+		_, _ = w.WriteString(" " + attr.Key + `="`)    //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(value))) //nolint:errcheck
+		_ = w.WriteByte('"')                           //nolint:errcheck
+	}
+	if n.Data == "br" || n.Data == "col" {
+		_, _ = w.WriteString(" />") //nolint:errcheck
+		return
+	}
+	_ = w.WriteByte('>') //nolint:errcheck
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeTableNode(w, c)
+	}
+	_, _ = w.WriteString("</" + name + ">") //nolint:errcheck
+}
+
+// isTableStructure reports whether the element holds only other table
+// elements, so whitespace inside it can be dropped
+func isTableStructure(name string) bool {
+	switch name {
+	case "table", "colgroup", "thead", "tbody", "tfoot", "tr":
+		return true
+	}
+	return false
+}
+
+// tableAttr reports whether attr is kept on element, and its value: cell
+// and column spans, and link targets with a safe scheme
+func tableAttr(element string, attr html.Attribute) (string, bool) {
+	if allowedHTMLAttrs[attr.Key] {
+		return attr.Val, true
+	}
+	if element != "a" || attr.Key != "href" {
+		return "", false
+	}
+	u, err := url.Parse(strings.TrimSpace(attr.Val))
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return attr.Val, true
+	}
+	return "", false
+}
@@ -0,0 +1,170 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// imageAttrTransformer moves a {...} block written straight after an image
+// onto the image as attributes:
+//
+//	![Architecture](arch.png){width=600 align=center caption="Data flow"}
+//
+// Sizes must be whole pixels (a px suffix is allowed) and align one of
+// left, center, or right. Other attributes are dropped.
+type imageAttrTransformer struct{}
+
+func newImageAttrTransformer() parser.ASTTransformer {
+	return &imageAttrTransformer{}
+}
+
+func (t *imageAttrTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var images []*ast.Image
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			images = append(images, img)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, img := range images {
+		nodes, value := imageAttrNodes(img, source)
+		end := bytes.IndexByte(value, '}')
+		if end < 0 {
+			continue
+		}
+		for key, v := range parseAttrPairs(string(value[1:end])) {
+			if v, ok := imageAttrValue(key, v); ok {
+				img.SetAttributeString(key, []byte(v))
+			}
+		}
+		// Drop the nodes holding the block, keeping whatever follows it
+		cut := nodes[0].(*ast.Text).Segment.Start + end + 1
+		for _, n := range nodes {
+			t, ok := n.(*ast.Text)
+			if !ok || (t.Segment.Stop <= cut && !t.SoftLineBreak() && !t.HardLineBreak()) {
+				n.Parent().RemoveChild(n.Parent(), n)
+				continue
+			}
+			t.Segment = t.Segment.WithStart(max(t.Segment.Start, cut))
+		}
+	}
+}
+
+// imageAttrNodes returns the nodes directly following an image up to the
+// text closing an attribute block, and the block's source. Inline parsers
+// split text at spaces, and the typographer turns quotes into strings, so a
+// block may span several nodes.
+func imageAttrNodes(img *ast.Image, source []byte) ([]ast.Node, []byte) {
+	var nodes []ast.Node
+	start := -1
+	for next := img.NextSibling(); next != nil; next = next.NextSibling() {
+		switch n := next.(type) {
+		case *ast.String:
+			if start < 0 {
+				return nil, nil
+			}
+		case *ast.Text:
+			if start < 0 {
+				start = n.Segment.Start
+			}
+			value := source[start:n.Segment.Stop]
+			if !bytes.HasPrefix(value, []byte("{")) {
+				return nil, nil
+			}
+			if bytes.IndexByte(value, '}') >= 0 || n.SoftLineBreak() || n.HardLineBreak() {
+				return append(nodes, n), value
+			}
+		default:
+			return nil, nil
+		}
+		nodes = append(nodes, next)
+	}
+	return nil, nil
+}
+
+// imageAttrValue validates an image attribute, returning its normalised
+// value
+func imageAttrValue(key, value string) (string, bool) {
+	switch key {
+	case "width", "height":
+		value = strings.TrimSuffix(value, "px")
+		if value == "" || strings.Trim(value, "0123456789") != "" {
+			return "", false
+		}
+		return value, true
+	case "align":
+		value = strings.ToLower(value)
+		return value, value == "left" || value == "center" || value == "right"
+	case "alt", "caption":
+		return value, true
+	}
+	return "", false
+}
+
+// imageAttr returns the attribute set on an image by imageAttrTransformer
+func imageAttr(n *ast.Image, name string) string {
+	if v, ok := n.AttributeString(name); ok {
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// imageAlt returns the plain text of an image's description
+func imageAlt(n ast.Node, source []byte) string {
+	var alt strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			alt.Write(c.Segment.Value(source))
+		case *ast.String:
+			alt.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return alt.String()
+}
+
+// writeImageStart opens an ac:image element with the attributes of n
+func writeImageStart(w util.BufWriter, source []byte, n *ast.Image) {
+	_, _ = w.WriteString("<ac:image") //nolint:errcheck
+	alt := imageAttr(n, "alt")
+	if alt == "" {
+		alt = imageAlt(n, source)
+	}
+	for _, attr := range []struct{ name, value string }{
+		{"ac:align", imageAttr(n, "align")},
+		{"ac:width", imageAttr(n, "width")},
+		{"ac:height", imageAttr(n, "height")},
+		{"ac:alt", alt},
+		{"ac:title", string(n.Title)},
+	} {
+		if attr.value == "" {
+			continue
+		}
+		_, _ = w.WriteString(" " + attr.name + `="`)        //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(attr.value))) //nolint:errcheck
+		_ = w.WriteByte('"')                                //nolint:errcheck
+	}
+	_ = w.WriteByte('>') //nolint:errcheck
+}
+
+// writeImageEnd writes the caption of n, if any, and closes its ac:image
+func writeImageEnd(w util.BufWriter, n *ast.Image) {
+	if caption := imageAttr(n, "caption"); caption != "" {
+		_, _ = w.WriteString("<ac:caption><p>")          //nolint:errcheck
+		_, _ = w.Write(util.EscapeHTML([]byte(caption))) //nolint:errcheck
+		_, _ = w.WriteString("</p></ac:caption>")        //nolint:errcheck
+	}
+	_, _ = w.WriteString("</ac:image>") //nolint:errcheck
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// includePrefix opens an include shortcode
+var includePrefix = []byte("{include:")
+
+// excerptMarker opens and closes an excerpt
+var excerptMarker = []byte("{excerpt}")
+
+// kindInclude is the node kind for included pages
+var kindInclude = ast.NewNodeKind("Include")
+
+// include embeds another page, written as {include:Page Title} or
+// {include:SPACE:Page Title} on a line of its own
+type include struct {
+	ast.BaseBlock
+	ref PageRef
+}
+
+func (n *include) Kind() ast.NodeKind {
+	return kindInclude
+}
+
+func (n *include) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Space": n.ref.SpaceKey, "Title": n.ref.Title}, nil)
+}
+
+// includeTransformer replaces paragraphs holding only an include shortcode
+// with include nodes
+type includeTransformer struct{}
+
+func newIncludeTransformer() parser.ASTTransformer {
+	return &includeTransformer{}
+}
+
+func (t *includeTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var paragraphs []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == ast.KindParagraph && n.Lines().Len() == 1 {
+			paragraphs = append(paragraphs, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, p := range paragraphs {
+		line := p.Lines().At(0)
+		value := bytes.TrimSpace(line.Value(source))
+		if len(value) <= len(includePrefix) || !bytes.EqualFold(value[:len(includePrefix)], includePrefix) ||
+			value[len(value)-1] != '}' {
+			continue
+		}
+		space, title := splitPageTarget(string(value[len(includePrefix) : len(value)-1]))
+		if title == "" {
+			continue
+		}
+		p.Parent().ReplaceChild(p.Parent(), p, &include{ref: PageRef{SpaceKey: space, Title: title}})
+	}
+}
+
+// kindExcerpt is the node kind for excerpts
+var kindExcerpt = ast.NewNodeKind("Excerpt")
+
+// excerpt is content other pages can reuse through the excerpt include
+// macro:
+//
+//	{excerpt}
+//	Markdown content
+//	{excerpt}
+type excerpt struct {
+	ast.BaseBlock
+}
+
+func (n *excerpt) Kind() ast.NodeKind {
+	return kindExcerpt
+}
+
+func (n *excerpt) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// excerptParser parses {excerpt} blocks into excerpt nodes. An unclosed
+// excerpt runs to the end of its container.
+type excerptParser struct{}
+
+func newExcerptParser() parser.BlockParser {
+	return &excerptParser{}
+}
+
+func (b *excerptParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (b *excerptParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !isExcerptMarker(line[pos:]) {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return &excerpt{}, parser.HasChildren
+}
+
+func (b *excerptParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w < 4 && pos < len(line) && isExcerptMarker(line[pos:]) {
+		reader.Advance(segment.Len() - trailingNewline(line))
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (b *excerptParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (b *excerptParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b *excerptParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// isExcerptMarker reports whether line is an excerpt marker and nothing else
+func isExcerptMarker(line []byte) bool {
+	return bytes.EqualFold(bytes.TrimSpace(line), excerptMarker)
+}
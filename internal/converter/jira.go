@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// JiraOptions configures linking Jira issue keys to the Jira issue macro.
+type JiraOptions struct {
+	// Projects lists the project keys whose issue keys, such as PROJ-123,
+	// become Jira issue macros. "*" matches any project. Empty turns
+	// detection off.
+	Projects []string
+	// Server and ServerID name the Jira application link the macro uses.
+	// Empty uses the site's default link.
+	Server   string
+	ServerID string
+}
+
+// matches reports whether issue keys in project should be linked
+func (o JiraOptions) matches(project string) bool {
+	return slices.Contains(o.Projects, "*") || slices.Contains(o.Projects, project)
+}
+
+// jiraKeyRegex matches issue keys such as PROJ-123 as whole words
+var jiraKeyRegex = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+)-[1-9][0-9]*\b`)
+
+// kindJiraIssue is the node kind for Jira issue keys
+var kindJiraIssue = ast.NewNodeKind("JiraIssue")
+
+// jiraIssue is a Jira issue key found in text
+type jiraIssue struct {
+	ast.BaseInline
+	key string
+}
+
+func (n *jiraIssue) Kind() ast.NodeKind {
+	return kindJiraIssue
+}
+
+func (n *jiraIssue) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Key": n.key}, nil)
+}
+
+// jiraTransformer splits text nodes around issue keys of the configured
+// projects, replacing each key with a jiraIssue node. Text in code, links,
+// and images is left alone.
+type jiraTransformer struct {
+	opts JiraOptions
+}
+
+func newJiraTransformer(opts JiraOptions) parser.ASTTransformer {
+	return &jiraTransformer{opts: opts}
+}
+
+func (t *jiraTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	if len(t.opts.Projects) == 0 {
+		return
+	}
+	source := reader.Source()
+	for _, n := range proseTexts(doc) {
+		matches := jiraKeyRegex.FindAllSubmatchIndex(n.Segment.Value(source), -1)
+		replaceInText(n, source, matches, func(value []byte, m []int) ast.Node {
+			if !t.opts.matches(string(value[m[2]:m[3]])) || !jiraKeyBoundary(value, m[0], m[1]) {
+				return nil
+			}
+			return &jiraIssue{key: string(value[m[0]:m[1]])}
+		})
+	}
+}
+
+// jiraKeyBoundary reports whether the key at value[start:end] stands alone
+// rather than being part of a longer token such as ABC-1-2 or x/ABC-1
+func jiraKeyBoundary(value []byte, start, end int) bool {
+	if start > 0 && (value[start-1] == '-' || value[start-1] == '/') {
+		return false
+	}
+	return end == len(value) || (value[end] != '-' && value[end] != '/')
+}
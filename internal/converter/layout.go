@@ -0,0 +1,260 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// layoutVariants maps the optional word after :::columns to the section
+// types used for two and three columns. Plain columns are equal widths.
+var layoutVariants = map[string][2]string{
+	"":              {"two_equal", "three_equal"},
+	"left-sidebar":  {"two_left_sidebar", "three_equal"},
+	"right-sidebar": {"two_right_sidebar", "three_equal"},
+	"sidebars":      {"two_equal", "three_with_sidebars"},
+}
+
+// kindLayout is the node kind for a page laid out in sections
+var kindLayout = ast.NewNodeKind("Layout")
+
+// layout holds the layout sections making up the whole page. Confluence
+// expects every block of a page with a layout to be inside a section.
+type layout struct {
+	ast.BaseBlock
+}
+
+func (n *layout) Kind() ast.NodeKind {
+	return kindLayout
+}
+
+func (n *layout) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// kindLayoutSection is the node kind for layout sections
+var kindLayoutSection = ast.NewNodeKind("LayoutSection")
+
+// layoutSection is a row of columns:
+//
+//	:::columns
+//	:::column
+//	Left
+//	:::
+//	:::column
+//	Right
+//	:::
+//	:::
+//
+// A fence closes the innermost open column or panel first, so the outer
+// fences may be as long as the inner ones, or longer as round-trip output
+// writes them. sectionType is set once the columns are counted, and a
+// section left without one (nested, or with more than three columns)
+// renders as its content.
+type layoutSection struct {
+	ast.BaseBlock
+	variant     string
+	sectionType string
+	fenceLength int
+}
+
+func (n *layoutSection) Kind() ast.NodeKind {
+	return kindLayoutSection
+}
+
+func (n *layoutSection) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Type": n.sectionType}, nil)
+}
+
+// kindLayoutCell is the node kind for layout columns
+var kindLayoutCell = ast.NewNodeKind("LayoutCell")
+
+// layoutCell is one column of a layoutSection
+type layoutCell struct {
+	ast.BaseBlock
+	fenceLength int
+	open        bool // Until its closing fence
+}
+
+func (n *layoutCell) Kind() ast.NodeKind {
+	return kindLayoutCell
+}
+
+func (n *layoutCell) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// layoutParser parses :::columns and :::column directives into layout
+// sections and cells
+type layoutParser struct{}
+
+func newLayoutParser() parser.BlockParser {
+	return &layoutParser{}
+}
+
+func (b *layoutParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (b *layoutParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	fence := colonRun(line[pos:])
+	if fence < 3 {
+		return nil, parser.NoChildren
+	}
+	kind, variant, _ := strings.Cut(strings.TrimSpace(string(line[pos+fence:])), " ")
+	var node ast.Node
+	switch strings.ToLower(kind) {
+	case "columns":
+		variant = strings.ToLower(strings.TrimSpace(variant))
+		if _, ok := layoutVariants[variant]; !ok {
+			variant = ""
+		}
+		node = &layoutSection{variant: variant, fenceLength: fence}
+	case "column":
+		node = &layoutCell{fenceLength: fence, open: true}
+	default:
+		return nil, parser.NoChildren
+	}
+
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return node, parser.HasChildren
+}
+
+func (b *layoutParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	fence := 0
+	switch n := node.(type) {
+	case *layoutSection:
+		fence = n.fenceLength
+	case *layoutCell:
+		fence = n.fenceLength
+	}
+	if !holdsOpenDirective(node, fence) && closeDirective(reader, fence) {
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (b *layoutParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	if cell, ok := node.(*layoutCell); ok {
+		cell.open = false
+	}
+}
+
+// holdsOpenDirective reports whether node's last child is a column or panel
+// still waiting for a closing fence that would also close node. Goldmark
+// offers each line to the outermost block first, so without this check
+// :::columns would be closed by the ::: ending its first column.
+func holdsOpenDirective(node ast.Node, fenceLength int) bool {
+	switch child := node.LastChild().(type) {
+	case *layoutCell:
+		return child.open && child.fenceLength >= fenceLength
+	case *admonition:
+		return child.open && child.fenceLength >= fenceLength
+	}
+	return false
+}
+
+func (b *layoutParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (b *layoutParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// layoutTransformer turns a page with top-level :::columns into a layout:
+// each section's content is gathered into its columns, and the blocks
+// between sections go into single-column sections. It runs after the
+// table of contents and footnotes are added so they are laid out too.
+type layoutTransformer struct{}
+
+func newLayoutTransformer() parser.ASTTransformer {
+	return &layoutTransformer{}
+}
+
+func (t *layoutTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	found := false
+	for child := doc.FirstChild(); child != nil; child = child.NextSibling() {
+		if section, ok := child.(*layoutSection); ok && typeLayoutSection(section) {
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	page := &layout{}
+	var single *layoutCell
+	for child := doc.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		doc.RemoveChild(doc, child)
+		if section, ok := child.(*layoutSection); ok && section.sectionType != "" {
+			page.AppendChild(page, section)
+			single = nil
+		} else {
+			if single == nil {
+				single = &layoutCell{}
+				section := &layoutSection{sectionType: "single"}
+				section.AppendChild(section, single)
+				page.AppendChild(page, section)
+			}
+			single.AppendChild(single, child)
+		}
+		child = next
+	}
+	doc.AppendChild(doc, page)
+}
+
+// typeLayoutSection moves any content outside the columns of section into
+// the column before it (or the start of the first column), then sets the
+// section type from the number of columns. It reports whether the section
+// can be laid out.
+func typeLayoutSection(section *layoutSection) bool {
+	var cells []*layoutCell
+	var stray []ast.Node
+	for child := section.FirstChild(); child != nil; child = child.NextSibling() {
+		if cell, ok := child.(*layoutCell); ok {
+			cells = append(cells, cell)
+		} else {
+			stray = append(stray, child)
+		}
+	}
+	if len(cells) == 0 || len(cells) > 3 {
+		return false
+	}
+	var leading []ast.Node
+	for _, child := range stray {
+		prev, ok := child.PreviousSibling().(*layoutCell)
+		section.RemoveChild(section, child)
+		if ok {
+			prev.AppendChild(prev, child)
+		} else {
+			leading = append(leading, child)
+		}
+	}
+	first := cells[0]
+	for i := len(leading) - 1; i >= 0; i-- {
+		if first.FirstChild() == nil {
+			first.AppendChild(first, leading[i])
+		} else {
+			first.InsertBefore(first, first.FirstChild(), leading[i])
+		}
+	}
+	switch len(cells) {
+	case 1:
+		section.sectionType = "single"
+	case 2:
+		section.sectionType = layoutVariants[section.variant][0]
+	case 3:
+		section.sectionType = layoutVariants[section.variant][1]
+	}
+	return true
+}
@@ -2,6 +2,8 @@ package converter
 
 import (
 	"bytes"
+	"errors"
+	"sync"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
@@ -10,30 +12,153 @@ import (
 	"github.com/yuin/goldmark/util"
 )
 
+// ErrUnsupported is wrapped by the errors of Options.Strict conversions for
+// Markdown that cannot be published as written.
+var ErrUnsupported = errors.New("unsupported in Confluence storage format")
+
 // MarkdownToStorage converts markdown to Confluence Storage Format using Goldmark.
-func MarkdownToStorage(markdown string) string {
-	// Create Goldmark parser with extensions
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM, // GitHub Flavored Markdown (includes tables)
-		),
+func MarkdownToStorage(markdown string) (string, error) {
+	return MarkdownToStorageWithOptions(markdown, Options{})
+}
+
+// MarkdownToStorageWithOptions converts markdown to Confluence Storage Format,
+// applying opts to tailor the output to the destination. Callers converting
+// more than one document should build a Converter once instead.
+func MarkdownToStorageWithOptions(markdown string, opts Options) (string, error) {
+	return NewConverter(opts).Convert(markdown)
+}
+
+// Converter converts Markdown to Confluence Storage Format with a fixed set
+// of options. The Goldmark pipeline is built once by NewConverter and reused
+// by every Convert call. Conversions are serialised, as the settings of a
+// Document apply to the shared renderer for the length of one conversion.
+type Converter struct {
+	md       goldmark.Markdown
+	renderer *ConfluenceRenderer
+	opts     Options
+	mu       sync.Mutex
+}
+
+// Document holds the settings that differ between the documents one
+// Converter converts, such as the callbacks that collect a page's
+// attachments. Set fields replace the Options fields of the same name for
+// one conversion; zero fields keep the Converter's.
+type Document struct {
+	RenderDiagram DiagramRenderer
+	AttachImage   ImageAttacher
+	ResolveUser   UserResolver
+	PageLinks     map[string]PageRef
+	LinkBase      string
+}
+
+// NewConverter creates a Converter that applies opts to every conversion.
+func NewConverter(opts Options) *Converter {
+	r := &ConfluenceRenderer{opts: opts}
+	return &Converter{md: newMarkdown(opts, r), renderer: r, opts: opts}
+}
+
+// Convert converts markdown to Confluence Storage Format.
+func (c *Converter) Convert(markdown string) (string, error) {
+	return c.ConvertDocument(markdown, Document{})
+}
+
+// ConvertDocument converts markdown to Confluence Storage Format with the
+// settings in doc.
+func (c *Converter) ConvertDocument(markdown string, doc Document) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.renderer.opts = c.opts.withDocument(doc)
+	defer func() { c.renderer.opts = c.opts }()
+
+	var buf bytes.Buffer
+	if err := c.md.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// newMarkdown builds the Goldmark pipeline for opts around r
+func newMarkdown(opts Options, r *ConfluenceRenderer) goldmark.Markdown {
+	// GitHub Flavored Markdown, less the TaskList extension whose checkbox
+	// renderer would take precedence over ours
+	extensions := []goldmark.Extender{
+		extension.Linkify,
+		extension.Table,
+		extension.Strikethrough,
+	}
+	if opts.Typography {
+		extensions = append(extensions, newTypographer())
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(), // Add IDs to headings
+			parser.WithAttribute(),     // ## Heading {#id}
+			parser.WithBlockParsers(
+				util.Prioritized(newAdmonitionParser(), 750), // ::: directive panels
+				util.Prioritized(newLayoutParser(), 755),     // :::columns layouts
+				util.Prioritized(newMathBlockParser(), 760),  // $$ display math
+				util.Prioritized(newExcerptParser(), 770),    // {excerpt} blocks
+				util.Prioritized(extension.NewFootnoteBlockParser(), 999),
+			),
+			// Footnotes are wired piecemeal rather than via extension.Footnote,
+			// whose HTML renderer would take precedence over ours
+			parser.WithInlineParsers(
+				util.Prioritized(extension.NewTaskCheckBoxParser(), 0), // - [ ] tasks
+				util.Prioritized(extension.NewFootnoteParser(), 101),
+				util.Prioritized(newWikiLinkParser(), 150),             // [[Page Title]], ahead of links
+				util.Prioritized(newScriptParser(opts.Subscript), 450), // ^sup^ and ~sub~, ahead of ~~strike~~
+				util.Prioritized(newMathInlineParser(), 500),           // $inline math$
+				util.Prioritized(newHighlightParser(), 550),            // ==highlight==
+				util.Prioritized(newStatusParser(), 600),               // {status:green|DONE}
+				util.Prioritized(newDateParser(), 610),                 // {date:2025-03-01}
+				util.Prioritized(newAnchorParser(), 620),               // {anchor:name}
+				util.Prioritized(newMentionParser(), 700),              // @{Name} and @email mentions
+				util.Prioritized(newEmojiParser(), 900),                // :shortcode: emoji
+			),
+			parser.WithASTTransformers(
+				util.Prioritized(newImageAttrTransformer(), 480),     // ![alt](src){width=600}
+				util.Prioritized(newHTMLTableTransformer(), 490),     // HTML tables
+				util.Prioritized(newDetailsTransformer(), 500),       // <details> sections
+				util.Prioritized(newChildrenTransformer(), 550),      // {children} page lists
+				util.Prioritized(newIncludeTransformer(), 560),       // {include:Page Title}
+				util.Prioritized(newJiraTransformer(opts.Jira), 600), // PROJ-123 issue keys
+				util.Prioritized(newBareDateTransformer(opts.BareDates), 610),
+				util.Prioritized(extension.NewFootnoteASTTransformer(), 999),
+				util.Prioritized(newTOCTransformer(opts.TOC.Insert), 1000),
+				util.Prioritized(newInlineHTMLTransformer(opts.AllowHTML), 1050), // --allow-html inline tags
+				util.Prioritized(newLayoutTransformer(), 1100),                   // last, to lay out everything
+			),
 		),
 		goldmark.WithRenderer(
 			renderer.NewRenderer(
 				renderer.WithNodeRenderers(
-					util.Prioritized(NewConfluenceRenderer(), 1000),
+					util.Prioritized(r, 1000),
 				),
 			),
 		),
 	)
+}
 
-	var buf bytes.Buffer
-	if err := md.Convert([]byte(markdown), &buf); err != nil {
-		// If conversion fails, return original markdown as fallback
-		return markdown
-	}
+// typographicCharacters replaces the typographer's HTML entities, which
+// the renderer would escape, with the characters themselves. Angle quotes
+// are left alone, as << and >> are more often operators than quotation.
+var typographicCharacters = map[extension.TypographicPunctuation][]byte{
+	extension.LeftSingleQuote:  []byte("\u2018"),
+	extension.RightSingleQuote: []byte("\u2019"),
+	extension.LeftDoubleQuote:  []byte("\u201c"),
+	extension.RightDoubleQuote: []byte("\u201d"),
+	extension.EnDash:           []byte("\u2013"),
+	extension.EmDash:           []byte("\u2014"),
+	extension.Ellipsis:         []byte("\u2026"),
+	extension.LeftAngleQuote:   nil,
+	extension.RightAngleQuote:  nil,
+	extension.Apostrophe:       []byte("\u2019"),
+}
 
-	return buf.String()
+// newTypographer returns the typographer extension for Options.Typography
+func newTypographer() goldmark.Extender {
+	return extension.NewTypographer(extension.WithTypographicSubstitutions(typographicCharacters))
 }
@@ -3,6 +3,7 @@ package converter
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 
@@ -16,11 +17,28 @@ type mdCase struct {
 	excludes []string // substrings that must NOT be present in output
 }
 
+// convert is MarkdownToStorage for inputs expected to convert cleanly
+func convert(tb testing.TB, markdown string) string {
+	tb.Helper()
+	return convertWith(tb, markdown, Options{})
+}
+
+// convertWith is MarkdownToStorageWithOptions for inputs expected to
+// convert cleanly
+func convertWith(tb testing.TB, markdown string, opts Options) string {
+	tb.Helper()
+	out, err := MarkdownToStorageWithOptions(markdown, opts)
+	if err != nil {
+		tb.Fatalf("MarkdownToStorageWithOptions(%q) error = %v", markdown, err)
+	}
+	return out
+}
+
 func runMarkdownCases(t *testing.T, cases []mdCase) {
 	t.Helper()
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			result := MarkdownToStorage(tt.input)
+			result := convert(t, tt.input)
 			for _, want := range tt.contains {
 				if !strings.Contains(result, want) {
 					t.Errorf("MarkdownToStorage(%q)\n  got: %q\n  missing: %q", tt.input, result, want)
@@ -97,6 +115,29 @@ func TestMarkdownToStorage_InlineFormatting(t *testing.T) {
 			input:    "Some ~~deleted~~ text",
 			contains: []string{"<del>deleted</del>"},
 		},
+		{
+			name:     "highlight",
+			input:    "Some ==marked **text**== here",
+			contains: []string{`<span style="background-color: rgb(255,240,179);">marked <strong>text</strong></span>`},
+		},
+		{
+			name:     "spaced or unbalanced equals stay text",
+			input:    "a == b and ===c=== and =d=",
+			contains: []string{"a == b and ===c=== and =d="},
+			excludes: []string{"<span"},
+		},
+		{
+			name:     "superscript, with single tildes left to strikethrough",
+			input:    "x^2^ and H~2~O with ~~struck~~ text",
+			contains: []string{"x<sup>2</sup> and H<del>2</del>O with <del>struck</del> text"},
+			excludes: []string{"<sub>"},
+		},
+		{
+			name:     "script markers around spaces stay text",
+			input:    "a^ b^ and ^^",
+			contains: []string{"a^ b^ and ^^"},
+			excludes: []string{"<sup>"},
+		},
 	})
 }
 
@@ -147,6 +188,32 @@ func TestMarkdownToStorage_InlineCode(t *testing.T) {
 
 func TestMarkdownToStorage_CodeBlocks(t *testing.T) {
 	runMarkdownCases(t, []mdCase{
+		{
+			name:     "language alias mapped",
+			input:    "```shell\nls\n```\n\n```golang\nx\n```\n\n```YML\na: 1\n```",
+			contains: []string{`language">bash<`, `language">go<`, `language">yaml<`},
+		},
+		{
+			name:     "unknown language becomes none",
+			input:    "```brainfuck\n+\n```",
+			contains: []string{`<ac:parameter ac:name="language">none</ac:parameter>`},
+			excludes: []string{"brainfuck"},
+		},
+		{
+			name:     "CDATA terminator in fenced code split",
+			input:    "```xml\n<![CDATA[x]]>\n```",
+			contains: []string{"<![CDATA[<![CDATA[x]]]]><![CDATA[>\n]]></ac:plain-text-body>"},
+		},
+		{
+			name:     "CDATA terminator in indented code split",
+			input:    "    a]]>b",
+			contains: []string{"<![CDATA[a]]]]><![CDATA[>b\n]]></ac:plain-text-body>"},
+		},
+		{
+			name:     "CDATA terminator in display math split",
+			input:    "$$\nx]]>y\n$$",
+			contains: []string{"<![CDATA[x]]]]><![CDATA[>y\n]]></ac:plain-text-body>"},
+		},
 		{
 			name:  "code block with language",
 			input: "```go\nfunc main() {}\n```",
@@ -189,6 +256,34 @@ func TestMarkdownToStorage_CodeBlocks(t *testing.T) {
 			input:    "```go\nfirst\n```\n\n```python\nsecond\n```",
 			contains: []string{`ac:name="code"`, "first", "python", "second"},
 		},
+		{
+			name:  "fence attributes become code macro parameters",
+			input: "```go {title=\"main.go\" linenumbers=true collapse=true theme=Midnight}\nx := 1\n```",
+			contains: []string{
+				`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter>` +
+					`<ac:parameter ac:name="title">main.go</ac:parameter>` +
+					`<ac:parameter ac:name="linenumbers">true</ac:parameter>` +
+					`<ac:parameter ac:name="collapse">true</ac:parameter>` +
+					`<ac:parameter ac:name="theme">Midnight</ac:parameter><ac:plain-text-body>`,
+			},
+		},
+		{
+			name:     "attributes without space after language",
+			input:    "```python{title='My Script' linenumbers}\np\n```",
+			contains: []string{`<ac:parameter ac:name="language">python</ac:parameter><ac:parameter ac:name="title">My Script</ac:parameter><ac:parameter ac:name="linenumbers">true</ac:parameter>`},
+			excludes: []string{"{", "}"},
+		},
+		{
+			name:     "attributes without language",
+			input:    "```{title=\"a & b\"}\ncode\n```",
+			contains: []string{`<ac:parameter ac:name="language">none</ac:parameter><ac:parameter ac:name="title">a &amp; b</ac:parameter>`},
+		},
+		{
+			name:     "unknown attributes and invalid booleans ignored",
+			input:    "```go {linenumbers=maybe highlight=3}\ncode\n```",
+			contains: []string{`<ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body>`},
+			excludes: []string{"linenumbers", "highlight"},
+		},
 	})
 }
 
@@ -267,6 +362,63 @@ func TestMarkdownToStorage_TaskLists(t *testing.T) {
 			contains: []string{"<ac:task-body>", "no paragraph wrapper", "</ac:task-body>"},
 			excludes: []string{"<p>no paragraph wrapper</p>"},
 		},
+		{
+			name:     "checkbox markup not rendered",
+			input:    "- [x] done",
+			contains: []string{"<ac:task-body>done</ac:task-body>"},
+			excludes: []string{"<input", "checkbox"},
+		},
+		{
+			name:     "nested task list follows its parent task",
+			input:    "- [ ] parent\n  - [x] child",
+			contains: []string{"<ac:task-body>parent</ac:task-body>\n</ac:task>\n<ac:task-list>\n<ac:task>\n<ac:task-status>complete</ac:task-status>\n<ac:task-body>child</ac:task-body>\n</ac:task>\n</ac:task-list>\n</ac:task-list>\n"},
+		},
+		{
+			name:     "mixed list splits into task and bullet runs",
+			input:    "- [ ] task\n- plain\n- [x] another",
+			contains: []string{"</ac:task-list>\n<ul>\n<li>plain\n</li>\n</ul>\n<ac:task-list>\n"},
+			excludes: []string{"<ac:task-body>plain"},
+		},
+		{
+			name:     "ordered mixed list keeps numbering element",
+			input:    "1. [ ] task\n2. plain",
+			contains: []string{"</ac:task-list>\n<ol>\n<li>plain\n</li>\n</ol>\n"},
+		},
+		{
+			name:     "plain item holds nested task list",
+			input:    "- plain\n  - [ ] task",
+			contains: []string{"<ul>\n<li>plain\n<ac:task-list>\n", "</ac:task-list>\n</li>\n</ul>\n"},
+		},
+		{
+			name:     "task keeps nested bullet list in its body",
+			input:    "- [ ] task\n  - note",
+			contains: []string{"<ac:task-body><p>task</p>\n<ul>\n<li>note\n</li>\n</ul>\n</ac:task-body>\n</ac:task>\n</ac:task-list>\n"},
+		},
+		{
+			name:     "inline formatting in task body",
+			input:    "- [ ] Fix **bold** `a<b>` [docs](https://example.com)",
+			contains: []string{"<ac:task-body>Fix <strong>bold</strong> <code>a&lt;b&gt;</code> <a href=\"https://example.com\">docs</a></ac:task-body>"},
+		},
+		{
+			name:     "loose task list stays inline",
+			input:    "- [ ] one\n\n- [x] two",
+			contains: []string{"<ac:task-body>one</ac:task-body>", "<ac:task-body>two</ac:task-body>"},
+		},
+		{
+			name:     "several paragraphs kept apart",
+			input:    "- [ ] First\n\n  Second *para*\n- [ ] next",
+			contains: []string{"<ac:task-body><p>First</p>\n<p>Second <em>para</em></p>\n</ac:task-body>", "<ac:task-body>next</ac:task-body>"},
+		},
+		{
+			name:     "code block in task body",
+			input:    "- [ ] Run\n\n  ```sh\n  make\n  ```",
+			contains: []string{"<ac:task-body><p>Run</p>\n<ac:structured-macro ac:name=\"code\"><ac:parameter ac:name=\"language\">bash</ac:parameter><ac:plain-text-body><![CDATA[make\n]]></ac:plain-text-body></ac:structured-macro>\n</ac:task-body>"},
+		},
+		{
+			name:     "blocks with a hoisted sub-task list",
+			input:    "- [ ] parent\n\n  more\n  - [ ] child",
+			contains: []string{"<ac:task-body><p>parent</p>\n<p>more</p>\n</ac:task-body>\n</ac:task>\n<ac:task-list>\n<ac:task>"},
+		},
 	})
 }
 
@@ -353,7 +505,7 @@ func TestMarkdownToStorage_LinksAndImages(t *testing.T) {
 		{
 			name:     "image basic",
 			input:    "![alt text](https://example.com/img.png)",
-			contains: []string{"<ac:image>", `ri:value="https://example.com/img.png"`, "</ac:image>"},
+			contains: []string{`<ac:image ac:alt="alt text">`, `ri:value="https://example.com/img.png"`, "</ac:image>"},
 		},
 		{
 			name:     "image escapes ampersand in url",
@@ -420,147 +572,1897 @@ func TestMarkdownToStorage_Blocks(t *testing.T) {
 	})
 }
 
-func TestMarkdownToStorage_LineBreaks(t *testing.T) {
-	runMarkdownCases(t, []mdCase{
+func TestMarkdownToStorageWithOptions_AllowHTML(t *testing.T) {
+	opts := Options{AllowHTML: true}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
 		{
-			name:     "hard line break with trailing spaces",
-			input:    "first line  \nsecond line",
-			contains: []string{"<br />"},
+			name:  "inline line break, superscript and subscript",
+			input: "one<br>two E=mc<sup>2</sup> H<sub>2</sub>O",
+			want:  "<p>one<br />two E=mc<sup>2</sup> H<sub>2</sub>O</p>\n",
 		},
 		{
-			name:     "hard line break with backslash",
-			input:    "first line\\\nsecond line",
-			contains: []string{"<br />"},
+			name:  "table keeps spans and drops other attributes",
+			input: "<table>\n<tr><th colspan=\"2\" onclick=\"x()\" style=\"color:red\">Head</th></tr>\n<tr><td rowspan=2>a & b</td></tr>\n</table>",
+			want:  "<table>\n<tbody><tr><th colspan=\"2\">Head</th></tr>\n<tr><td rowspan=\"2\">a &amp; b</td></tr>\n</tbody></table>",
 		},
 		{
-			name:     "soft line break",
-			input:    "first line\nsecond line",
-			contains: []string{"first line", "\n", "second line"},
+			name:  "unclosed details dropped",
+			input: "<details>\n<summary>More</summary>\n\nHidden **text**",
+			want:  "<p>More</p>\n<p>Hidden <strong>text</strong></p>",
 		},
-	})
-}
-
-func TestMarkdownToStorage_Escaping(t *testing.T) {
-	runMarkdownCases(t, []mdCase{
 		{
-			name:     "text with html-significant chars escapes",
-			input:    "5 < 10 && 10 > 5",
-			contains: []string{"5 &lt; 10", "&amp;&amp;", "10 &gt; 5"},
+			name:  "other tags dropped with text kept",
+			input: "<div class=\"note\">div <em>text</em></div>",
+			want:  "<p>div text</p>",
 		},
 		{
-			name:     "escaped markdown chars in text",
-			input:    "Literal \\* asterisk and \\_ underscore",
-			contains: []string{"Literal", "asterisk", "underscore"},
-			excludes: []string{"<em>", "<strong>"},
+			name:  "script content dropped",
+			input: "<div><script>alert(1)</script>safe</div>",
+			want:  "<p>safe</p>",
 		},
-	})
+		{
+			name:  "comments dropped",
+			input: "a <!-- hidden --> b",
+			want:  "<p>a  b</p>\n",
+		},
+		{
+			name:  "block text wrapped in paragraphs",
+			input: "<div>one</div><div>two <sup>2</div>",
+			want:  "<p>one</p>\n<p>two <sup>2</sup></p>",
+		},
+		{
+			name:  "unclosed inline element closed with its paragraph",
+			input: "a <sup>b *c* d",
+			want:  "<p>a <sup>b <em>c</em> d</sup></p>",
+		},
+		{
+			name:  "inline element closed within emphasis",
+			input: "*a <sub>b* c</sub>",
+			want:  "<p><em>a <sub>b</sub></em> c</p>",
+		},
+		{
+			name:  "stray table tags dropped",
+			input: "a </tr> b <td>c</td>",
+			want:  "<p>a  b c</p>",
+		},
+		{
+			name:  "unmatched end tags dropped",
+			input: "a </sup> <sub>b</sup></sub>",
+			want:  "<p>a  <sub>b</sub></p>",
+		},
+		{
+			name:  "inline script content dropped",
+			input: "a <script>alert(1)</script> b",
+			want:  "<p>a  b</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, opts); strings.TrimSpace(got) != strings.TrimSpace(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
-func TestMarkdownToStorage_Edge(t *testing.T) {
-	runMarkdownCases(t, []mdCase{
+func TestMarkdownToStorageWithOptions_Typography(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
 		{
-			name:     "empty input",
-			input:    "",
-			contains: []string{}, // Should not panic
+			name:  "quotes, dashes, and ellipsis",
+			input: `"Quoted" -- it's 'single' --- done...`,
+			opts:  Options{Typography: true},
+			want:  "<p>\u201cQuoted\u201d \u2013 it\u2019s \u2018single\u2019 \u2014 done\u2026</p>\n",
 		},
 		{
-			name:     "whitespace only",
-			input:    "   \n\t\n   ",
-			contains: []string{}, // Should not panic
+			name:  "angle quotes left alone",
+			input: "a << b >> c",
+			opts:  Options{Typography: true},
+			want:  "<p>a &lt;&lt; b &gt;&gt; c</p>\n",
 		},
-	})
+		{
+			name:  "code kept exact",
+			input: "`\"x\" -- y...`\n\n```\n\"x\" -- y...\n```",
+			opts:  Options{Typography: true},
+			want:  "<p><code>&quot;x&quot; -- y...</code></p>\n" + `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">none</ac:parameter><ac:plain-text-body><![CDATA["x" -- y...` + "\n]]></ac:plain-text-body></ac:structured-macro>\n",
+		},
+		{
+			name:  "image caption quotes",
+			input: `![a](x.png){width=600 caption="Bob's flow"}`,
+			opts:  Options{Typography: true},
+			want:  `<p><ac:image ac:width="600" ac:alt="a"><ri:url ri:value="x.png" /><ac:caption><p>Bob's flow</p></ac:caption></ac:image></p>` + "\n",
+		},
+		{
+			name:  "dates after dashes",
+			input: "Due -- 2025-03-01",
+			opts:  Options{Typography: true, BareDates: true},
+			want:  "<p>Due \u2013 <time datetime=\"2025-03-01\" /></p>\n",
+		},
+		{
+			name:  "off by default",
+			input: `"Quoted" -- it's...`,
+			want:  "<p>&quot;Quoted&quot; -- it's...</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
-// TestRenderStringDirect exercises renderString directly because goldmark's
-// default parsers do not emit ast.String nodes from plain markdown — they
-// come from extensions or programmatic AST construction.
-//
-// This is a defensive contract test, not a coverage signal: renderString is
-// registered for ast.KindString in case future extensions emit String nodes.
-// The function does not run in the live MarkdownToStorage pipeline today.
-func TestRenderStringDirect(t *testing.T) {
+func TestMarkdownToStorageWithOptions_Subscript(t *testing.T) {
 	tests := []struct {
 		name  string
-		value string
+		input string
+		opts  Options
 		want  string
 	}{
-		{"plain text", "hello", "hello"},
-		{"escapes angle brackets", "a < b > c", "a &lt; b &gt; c"},
-		{"escapes ampersand", "x & y", "x &amp; y"},
-		{"escapes double quote", `say "hi"`, "say &quot;hi&quot;"},
-		{"empty value", "", ""},
-		{"unicode preserved", "日本語", "日本語"},
+		{
+			name:  "single tildes are strikethrough by default",
+			input: "H~2~O and ~text~ and ~~struck~~",
+			want:  "<p>H<del>2</del>O and <del>text</del> and <del>struck</del></p>\n",
+		},
+		{
+			name:  "single tildes are subscript with the option",
+			input: "H~2~O and ~text~ and ~~struck~~",
+			opts:  Options{Subscript: true},
+			want:  "<p>H<sub>2</sub>O and <sub>text</sub> and <del>struck</del></p>\n",
+		},
+		{
+			name:  "spaced tildes stay strikethrough with the option",
+			input: "~two words~",
+			opts:  Options{Subscript: true},
+			want:  "<p><del>two words</del></p>\n",
+		},
 	}
 
-	r := &ConfluenceRenderer{}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var buf bytes.Buffer
-			bw := bufio.NewWriter(&buf)
-			node := ast.NewString([]byte(tt.value))
-
-			status, err := r.renderString(bw, nil, node, true)
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-			if status != ast.WalkContinue {
-				t.Errorf("got status %v, want WalkContinue", status)
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
 			}
+		})
+	}
+}
 
-			// Exit branch should be a no-op.
-			if _, err := r.renderString(bw, nil, node, false); err != nil {
-				t.Fatalf("exit branch error: %v", err)
-			}
+func TestMarkdownToStorageWithOptions_HeadingOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		offset int
+		want   string
+	}{
+		{
+			name:   "demote by one",
+			input:  "# Title\n\n## Section",
+			offset: 1,
+			want:   "<h2>" + `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">title</ac:parameter></ac:structured-macro>` + "Title</h2>\n<h3>" + `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">section</ac:parameter></ac:structured-macro>` + "Section</h3>\n",
+		},
+		{
+			name:   "capped at six",
+			input:  "##### Five\n\n###### Six",
+			offset: 2,
+			want:   "<h6>" + `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">five</ac:parameter></ac:structured-macro>` + "Five</h6>\n<h6>" + `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">six</ac:parameter></ac:structured-macro>` + "Six</h6>\n",
+		},
+		{
+			name:   "promote, not above one",
+			input:  "## Two\n\n# One",
+			offset: -1,
+			want:   "<h1>" + `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">two</ac:parameter></ac:structured-macro>` + "Two</h1>\n<h1>" + `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">one</ac:parameter></ac:structured-macro>` + "One</h1>\n",
+		},
+	}
 
-			_ = bw.Flush()
-			if got := buf.String(); got != tt.want {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, Options{HeadingOffset: tt.offset}); got != tt.want {
 				t.Errorf("got %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestMarkdownToStorage_MultipleElements(t *testing.T) {
-	input := `# Title
+func TestMarkdownToStorageWithOptions_HardWraps(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "lines joined by default",
+			input: "one\ntwo",
+			want:  "<p>one\ntwo</p>\n",
+		},
+		{
+			name:  "hard wraps",
+			input: "one\ntwo\nthree",
+			opts:  Options{HardWraps: true},
+			want:  "<p>one<br />\ntwo<br />\nthree</p>\n",
+		},
+		{
+			name:  "explicit break not doubled",
+			input: "one\\\ntwo",
+			opts:  Options{HardWraps: true},
+			want:  "<p>one<br />\ntwo</p>\n",
+		},
+		{
+			name:  "list item lines",
+			input: "- one\n  two",
+			opts:  Options{HardWraps: true},
+			want:  "<ul>\n<li>one<br />\ntwo\n</li>\n</ul>\n",
+		},
+		{
+			name:  "code blocks unaffected",
+			input: "```go\na\nb\n```",
+			opts:  Options{HardWraps: true},
+			want:  `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[a` + "\nb\n]]></ac:plain-text-body></ac:structured-macro>\n",
+		},
+	}
 
-This is a paragraph with **bold** and *italic* text.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
 
-## Code Example
+func TestMarkdownToStorageWithOptions_Strict(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    Options
+		wantErr string
+	}{
+		{name: "omitted HTML block", input: "Text\n\n<div>x</div>", opts: Options{Strict: true}, wantErr: "line 3: raw HTML"},
+		{name: "omitted inline HTML", input: "a <span>b</span>", opts: Options{Strict: true}, wantErr: "line 1: raw HTML <span>"},
+		{name: "stripped element", input: "a <span>b</span>", opts: Options{Strict: true, AllowHTML: true}, wantErr: "line 1: HTML <span> element"},
+		{name: "comments allowed", input: "<!-- note -->\n\na <!-- inline -->", opts: Options{Strict: true}},
+		{name: "allowed elements", input: "a<br>b <sup>2</sup>", opts: Options{Strict: true, AllowHTML: true}},
+		{name: "adf fence", input: "Text\n\n```adf\n{\"type\": \"sketch\"}\n```", opts: Options{Strict: true}, wantErr: "line 3: ADF node"},
+		{name: "not strict", input: "<div>x</div>"},
+	}
 
-` + "```go" + `
-func hello() {
-    fmt.Println("Hello")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MarkdownToStorageWithOptions(tt.input, tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, ErrUnsupported) {
+				t.Errorf("err = %v, want %q wrapping ErrUnsupported", err, tt.wantErr)
+			}
+		})
+	}
 }
-` + "```" + `
-
-## List
 
-- Item 1
-- Item 2
+func TestConverter_Reuse(t *testing.T) {
+	opts := Options{TOC: TOCOptions{Insert: true}, DisabledMacros: []string{"code"}}
+	inputs := []string{
+		"# Title\n\nNote[^1]\n\n[^1]: Footnote",
+		"# Title\n\n```go\nx := 1\n```",
+		"# Other\n\n- [ ] task",
+	}
 
-Done.
-`
+	c := NewConverter(opts)
+	for range 2 {
+		for _, input := range inputs {
+			got, err := c.Convert(input)
+			if err != nil {
+				t.Fatalf("Convert(%q) error = %v", input, err)
+			}
+			if want := convertWith(t, input, opts); got != want {
+				t.Errorf("Convert(%q) =\n%s\nwant\n%s", input, got, want)
+			}
+		}
+	}
+}
 
-	result := MarkdownToStorage(input)
+func TestConverter_ConvertDocument(t *testing.T) {
+	opts := Options{PageLinks: map[string]PageRef{"guide/setup.md": {Title: "Setup"}}}
+	c := NewConverter(opts)
+	input := "[Setup](setup.md) ![x](a.png)"
 
-	expected := []string{
-		"<h1", "Title", "</h1>",
-		"<strong>bold</strong>",
-		"<em>italic</em>",
-		"<h2", "Code Example", "</h2>",
-		`ac:name="code"`,
-		"func hello()",
-		"<h2", "List", "</h2>",
-		"<ul>",
-		"Item 1",
-		"Item 2",
-		"</ul>",
-		"Done.",
+	var attached []string
+	doc := Document{
+		LinkBase: "guide",
+		AttachImage: func(path string) (string, error) {
+			attached = append(attached, path)
+			return path, nil
+		},
 	}
-
-	for _, want := range expected {
+	got, err := c.ConvertDocument(input, doc)
+	if err != nil {
+		t.Fatalf("ConvertDocument() error = %v", err)
+	}
+	want := `<p><ac:link><ri:page ri:content-title="Setup" /><ac:link-body>Setup</ac:link-body></ac:link> <ac:image ac:alt="x"><ri:attachment ri:filename="a.png" /></ac:image></p>` + "\n"
+	if got != want {
+		t.Errorf("ConvertDocument() =\n%s\nwant\n%s", got, want)
+	}
+	if len(attached) != 1 {
+		t.Errorf("AttachImage called %d times, want 1", len(attached))
+	}
+
+	// The document's settings last for one conversion only
+	got, err = c.Convert(input)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if want := convertWith(t, input, opts); got != want {
+		t.Errorf("Convert() after ConvertDocument() =\n%s\nwant\n%s", got, want)
+	}
+	if len(attached) != 1 {
+		t.Errorf("AttachImage called %d times after Convert(), want 1", len(attached))
+	}
+}
+
+func TestMarkdownToStorage_ImageAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "width and alignment",
+			input: "![Arch](a.png){width=600 align=center}",
+			want:  `<p><ac:image ac:align="center" ac:width="600" ac:alt="Arch"><ri:url ri:value="a.png" /></ac:image></p>` + "\n",
+		},
+		{
+			name:  "title and caption",
+			input: `![Arch](a.png "Overview"){caption="Data flow" height=200px}`,
+			want:  `<p><ac:image ac:height="200" ac:alt="Arch" ac:title="Overview"><ri:url ri:value="a.png" /><ac:caption><p>Data flow</p></ac:caption></ac:image></p>` + "\n",
+		},
+		{
+			name:  "alt override and escaping",
+			input: `![**x**](a.png){alt="A & B"}`,
+			want:  `<p><ac:image ac:alt="A &amp; B"><ri:url ri:value="a.png" /></ac:image></p>` + "\n",
+		},
+		{
+			name:  "invalid values dropped",
+			input: "![](a.png){width=50% align=middle style=x}",
+			want:  `<p><ac:image><ri:url ri:value="a.png" /></ac:image></p>` + "\n",
+		},
+		{
+			name:  "text after attributes kept",
+			input: "![](a.png){width=10} and more",
+			want:  `<p><ac:image ac:width="10"><ri:url ri:value="a.png" /></ac:image> and more</p>` + "\n",
+		},
+		{
+			name:  "separated braces are text",
+			input: "![](a.png) {width=10}",
+			want:  `<p><ac:image><ri:url ri:value="a.png" /></ac:image> {width=10}</p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_HTMLTables(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "merged cells",
+			input: "<table>\n<tr><th colspan=\"2\">Head</th></tr>\n<tr><td rowspan=2>a</td><td>b</td></tr>\n</table>",
+			want:  "<table>\n<tbody><tr><th colspan=\"2\">Head</th></tr>\n<tr><td rowspan=\"2\">a</td><td>b</td></tr>\n</tbody></table>\n",
+		},
+		{
+			name:  "unclosed cells and rows closed",
+			input: "<table><tr><td>a<td>b<tr><td>c</table>",
+			want:  "<table><tbody><tr><td>a</td><td>b</td></tr><tr><td>c</td></tr></tbody></table>\n",
+		},
+		{
+			name:  "blank lines within the table",
+			input: "<table>\n<tr><td>a</td></tr>\n\n<tr><td>b</td></tr>\n</table>\n\nAfter",
+			want:  "<table>\n<tbody><tr><td>a</td></tr>\n<tr><td>b</td></tr>\n</tbody></table>\n<p>After</p>\n",
+		},
+		{
+			name:  "cell formatting kept and mapped",
+			input: "<table><tr><td><b>bold</b> <i>it</i> <code>x</code><br>next</td></tr></table>",
+			want:  "<table><tbody><tr><td><strong>bold</strong> <em>it</em> <code>x</code><br />next</td></tr></tbody></table>\n",
+		},
+		{
+			name:  "unsafe content removed",
+			input: "<table><tr><td style=\"color:red\" onclick=\"x()\"><span>text</span><script>alert(1)</script> <a href=\"javascript:alert(1)\">bad</a> <a href=\"https://example.com\">ok</a></td></tr></table>",
+			want:  "<table><tbody><tr><td>text <a>bad</a> <a href=\"https://example.com\">ok</a></td></tr></tbody></table>\n",
+		},
+		{
+			name:  "unterminated table stays raw HTML",
+			input: "<table><tr><td>a",
+			want:  "<!-- raw HTML omitted -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_Details(t *testing.T) {
+	runMarkdownCases(t, []mdCase{
+		{
+			name:  "details becomes expand macro",
+			input: "<details>\n<summary>More info</summary>\n\nHidden **text**\n\n</details>",
+			contains: []string{
+				`<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">More info</ac:parameter><ac:rich-text-body>`,
+				"<p>Hidden <strong>text</strong></p>\n</ac:rich-text-body></ac:structured-macro>",
+			},
+			excludes: []string{"<details>", "raw HTML omitted"},
+		},
+		{
+			name:     "summary markup reduced to text",
+			input:    "<details><summary>Click <b>here</b> &amp; see</summary>\n\nx\n\n</details>",
+			contains: []string{`<ac:parameter ac:name="title">Click here &amp; see</ac:parameter>`},
+		},
+		{
+			name:     "without summary",
+			input:    "<details>\n\nx\n\n</details>",
+			contains: []string{`<ac:structured-macro ac:name="expand"><ac:rich-text-body>`},
+			excludes: []string{`ac:name="title"`},
+		},
+		{
+			name:     "single block",
+			input:    "<details><summary>One</summary>Short <em>text</em></details>",
+			contains: []string{`<ac:parameter ac:name="title">One</ac:parameter><ac:rich-text-body>` + "\n<p>Short text</p>\n</ac:rich-text-body>"},
+		},
+		{
+			name:  "nested",
+			input: "<details>\n<summary>Outer</summary>\n\n<details>\n<summary>Inner</summary>\n\n- a\n\n</details>\n\n</details>",
+			contains: []string{
+				`<ac:parameter ac:name="title">Outer</ac:parameter><ac:rich-text-body>` + "\n" +
+					`<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Inner</ac:parameter>`,
+				"</ul>\n</ac:rich-text-body></ac:structured-macro>\n</ac:rich-text-body></ac:structured-macro>",
+			},
+		},
+		{
+			name:     "inside blockquote",
+			input:    "> <details>\n> <summary>Quoted</summary>\n>\n> body\n>\n> </details>",
+			contains: []string{"<blockquote>\n" + `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Quoted</ac:parameter>`},
+		},
+		{
+			name:     "unclosed stays raw HTML",
+			input:    "<details>\n<summary>Open</summary>\n\ntext",
+			contains: []string{"<!-- raw HTML omitted -->", "<p>text</p>"},
+			excludes: []string{`ac:name="expand"`},
+		},
+	})
+}
+
+func TestMarkdownToStorage_Alerts(t *testing.T) {
+	runMarkdownCases(t, []mdCase{
+		{
+			name:     "note becomes info panel",
+			input:    "> [!NOTE]\n> Useful information",
+			contains: []string{`<ac:structured-macro ac:name="info"><ac:rich-text-body>`, "<p>Useful information</p>", "</ac:rich-text-body></ac:structured-macro>"},
+			excludes: []string{"[!NOTE]", "<blockquote>"},
+		},
+		{
+			name:     "warning becomes warning panel",
+			input:    "> [!WARNING]\n> Check twice",
+			contains: []string{`ac:name="warning"`, "Check twice"},
+		},
+		{
+			name:     "tip becomes tip panel",
+			input:    "> [!TIP]\n> Try this",
+			contains: []string{`ac:name="tip"`, "Try this"},
+		},
+		{
+			name:     "caution becomes note panel",
+			input:    "> [!CAUTION]\n> Irreversible",
+			contains: []string{`ac:name="note"`, "Irreversible"},
+		},
+		{
+			name:     "marker is case insensitive",
+			input:    "> [!note]\n> lower",
+			contains: []string{`ac:name="info"`},
+			excludes: []string{"[!note]"},
+		},
+		{
+			name:     "marker on its own paragraph",
+			input:    "> [!TIP]\n>\n> First **bold**\n>\n> Second",
+			contains: []string{`ac:name="tip"`, "<p>First <strong>bold</strong></p>", "<p>Second</p>"},
+			excludes: []string{"<p></p>"},
+		},
+		{
+			name:     "text after marker is a plain blockquote",
+			input:    "> [!NOTE] not an alert\n> body",
+			contains: []string{"<blockquote>", "[!NOTE] not an alert"},
+			excludes: []string{"ac:structured-macro"},
+		},
+		{
+			name:     "unknown marker is a plain blockquote",
+			input:    "> [!IMPORTANT]\n> body",
+			contains: []string{"<blockquote>", "[!IMPORTANT]"},
+			excludes: []string{"ac:structured-macro"},
+		},
+	})
+}
+
+func TestMarkdownToStorage_Admonitions(t *testing.T) {
+	runMarkdownCases(t, []mdCase{
+		{
+			name:     "panel with title",
+			input:    ":::info Before you start\nRead the **guide**\n:::",
+			contains: []string{`<ac:structured-macro ac:name="info"><ac:parameter ac:name="title">Before you start</ac:parameter><ac:rich-text-body>`, "<p>Read the <strong>guide</strong></p>", "</ac:rich-text-body></ac:structured-macro>"},
+			excludes: []string{":::"},
+		},
+		{
+			name:     "panel without title",
+			input:    ":::warning\nCareful\n:::",
+			contains: []string{`<ac:structured-macro ac:name="warning"><ac:rich-text-body>`, "<p>Careful</p>"},
+			excludes: []string{`ac:name="title"`},
+		},
+		{
+			name:     "all panel types",
+			input:    ":::note\na\n:::\n\n:::tip\nb\n:::",
+			contains: []string{`ac:name="note"`, `ac:name="tip"`},
+		},
+		{
+			name:     "type is case insensitive",
+			input:    ":::INFO\nx\n:::",
+			contains: []string{`ac:name="info"`},
+		},
+		{
+			name:     "title is escaped",
+			input:    ":::tip Use <b> & co\nx\n:::",
+			contains: []string{`<ac:parameter ac:name="title">Use &lt;b&gt; &amp; co</ac:parameter>`},
+		},
+		{
+			name:     "block content inside panel",
+			input:    ":::info\n- one\n- two\n\n```go\nx := 1\n```\n:::",
+			contains: []string{"<ul>", "<li>one", `ac:name="code"`, "x := 1"},
+		},
+		{
+			name:     "longer outer fence nests panels",
+			input:    "::::note Outer\n:::tip Inner\nx\n:::\ny\n::::",
+			contains: []string{`ac:name="note"`, `ac:name="tip"`, "<p>x</p>\n</ac:rich-text-body></ac:structured-macro>\n<p>y</p>"},
+		},
+		{
+			name:     "content after closing fence",
+			input:    ":::info\ninside\n:::\noutside",
+			contains: []string{"</ac:rich-text-body></ac:structured-macro>\n<p>outside</p>"},
+		},
+		{
+			name:     "unknown type stays text",
+			input:    ":::bogus\nx\n:::",
+			contains: []string{"<p>:::bogus"},
+			excludes: []string{"ac:structured-macro"},
+		},
+	})
+}
+
+func TestMarkdownToStorage_Layouts(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "two columns with content around them",
+			input: "Intro\n\n::::columns\n:::column\nLeft\n:::\n:::column\nRight\n:::\n::::\n\nOutro",
+			want: "<ac:layout>\n" +
+				`<ac:layout-section ac:type="single">` + "\n<ac:layout-cell>\n<p>Intro</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				`<ac:layout-section ac:type="two_equal">` + "\n<ac:layout-cell>\n<p>Left</p>\n</ac:layout-cell>\n<ac:layout-cell>\n<p>Right</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				`<ac:layout-section ac:type="single">` + "\n<ac:layout-cell>\n<p>Outro</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				"</ac:layout>\n",
+		},
+		{
+			name:  "three-colon fences throughout",
+			input: ":::columns\n:::column\nLeft\n:::\n:::column\nRight\n:::\n:::",
+			want: "<ac:layout>\n" +
+				`<ac:layout-section ac:type="two_equal">` + "\n<ac:layout-cell>\n<p>Left</p>\n</ac:layout-cell>\n<ac:layout-cell>\n<p>Right</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				"</ac:layout>\n",
+		},
+		{
+			name:  "three-colon panel inside a column",
+			input: ":::columns\n:::column\n:::info\nHint\n:::\n:::\n:::column\nRight\n:::\n:::\n\nAfter",
+			want: "<ac:layout>\n" +
+				`<ac:layout-section ac:type="two_equal">` + "\n<ac:layout-cell>\n" + `<ac:structured-macro ac:name="info"><ac:rich-text-body>` + "\n<p>Hint</p>\n</ac:rich-text-body></ac:structured-macro>\n</ac:layout-cell>\n<ac:layout-cell>\n<p>Right</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				`<ac:layout-section ac:type="single">` + "\n<ac:layout-cell>\n<p>After</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				"</ac:layout>\n",
+		},
+		{
+			name:  "sidebar variant and stray content",
+			input: "::::columns left-sidebar\nfirst\n:::column\nNav\n:::\n:::column\nBody\n:::\n::::",
+			want: "<ac:layout>\n" +
+				`<ac:layout-section ac:type="two_left_sidebar">` + "\n<ac:layout-cell>\n<p>first</p>\n<p>Nav</p>\n</ac:layout-cell>\n<ac:layout-cell>\n<p>Body</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				"</ac:layout>\n",
+		},
+		{
+			name:  "three columns",
+			input: "::::columns\n:::column\na\n:::\n:::column\nb\n:::\n:::column\nc\n:::\n::::",
+			want: "<ac:layout>\n" +
+				`<ac:layout-section ac:type="three_equal">` + "\n<ac:layout-cell>\n<p>a</p>\n</ac:layout-cell>\n<ac:layout-cell>\n<p>b</p>\n</ac:layout-cell>\n<ac:layout-cell>\n<p>c</p>\n</ac:layout-cell>\n</ac:layout-section>\n" +
+				"</ac:layout>\n",
+		},
+		{
+			name:  "nested columns render as content",
+			input: "> ::::columns\n> :::column\n> a\n> :::\n> ::::",
+			want:  "<blockquote>\n<p>a</p>\n</blockquote>\n",
+		},
+		{
+			name:  "too many columns render as content",
+			input: "::::columns\n:::column\na\n:::\n:::column\nb\n:::\n:::column\nc\n:::\n:::column\nd\n:::\n::::",
+			want:  "<p>a</p>\n<p>b</p>\n<p>c</p>\n<p>d</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_LineBreaks(t *testing.T) {
+	runMarkdownCases(t, []mdCase{
+		{
+			name:     "hard line break with trailing spaces",
+			input:    "first line  \nsecond line",
+			contains: []string{"<br />"},
+		},
+		{
+			name:     "hard line break with backslash",
+			input:    "first line\\\nsecond line",
+			contains: []string{"<br />"},
+		},
+		{
+			name:     "soft line break",
+			input:    "first line\nsecond line",
+			contains: []string{"first line", "\n", "second line"},
+		},
+	})
+}
+
+func TestMarkdownToStorage_Escaping(t *testing.T) {
+	runMarkdownCases(t, []mdCase{
+		{
+			name:     "text with html-significant chars escapes",
+			input:    "5 < 10 && 10 > 5",
+			contains: []string{"5 &lt; 10", "&amp;&amp;", "10 &gt; 5"},
+		},
+		{
+			name:     "escaped markdown chars in text",
+			input:    "Literal \\* asterisk and \\_ underscore",
+			contains: []string{"Literal", "asterisk", "underscore"},
+			excludes: []string{"<em>", "<strong>"},
+		},
+	})
+}
+
+func TestMarkdownToStorage_Edge(t *testing.T) {
+	runMarkdownCases(t, []mdCase{
+		{
+			name:     "empty input",
+			input:    "",
+			contains: []string{}, // Should not panic
+		},
+		{
+			name:     "whitespace only",
+			input:    "   \n\t\n   ",
+			contains: []string{}, // Should not panic
+		},
+	})
+}
+
+// TestRenderStringDirect exercises renderString directly because goldmark's
+// default parsers do not emit ast.String nodes from plain markdown — they
+// come from extensions or programmatic AST construction.
+//
+// This is a defensive contract test, not a coverage signal: renderString is
+// registered for ast.KindString in case future extensions emit String nodes.
+// The function does not run in the live MarkdownToStorage pipeline today.
+func TestRenderStringDirect(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain text", "hello", "hello"},
+		{"escapes angle brackets", "a < b > c", "a &lt; b &gt; c"},
+		{"escapes ampersand", "x & y", "x &amp; y"},
+		{"escapes double quote", `say "hi"`, "say &quot;hi&quot;"},
+		{"empty value", "", ""},
+		{"unicode preserved", "日本語", "日本語"},
+	}
+
+	r := &ConfluenceRenderer{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			bw := bufio.NewWriter(&buf)
+			node := ast.NewString([]byte(tt.value))
+
+			status, err := r.renderString(bw, nil, node, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status != ast.WalkContinue {
+				t.Errorf("got status %v, want WalkContinue", status)
+			}
+
+			// Exit branch should be a no-op.
+			if _, err := r.renderString(bw, nil, node, false); err != nil {
+				t.Fatalf("exit branch error: %v", err)
+			}
+
+			_ = bw.Flush()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_MultipleElements(t *testing.T) {
+	input := `# Title
+
+This is a paragraph with **bold** and *italic* text.
+
+## Code Example
+
+` + "```go" + `
+func hello() {
+    fmt.Println("Hello")
+}
+` + "```" + `
+
+## List
+
+- Item 1
+- Item 2
+
+Done.
+`
+
+	result := convert(t, input)
+
+	expected := []string{
+		"<h1", "Title", "</h1>",
+		"<strong>bold</strong>",
+		"<em>italic</em>",
+		"<h2", "Code Example", "</h2>",
+		`ac:name="code"`,
+		"func hello()",
+		"<h2", "List", "</h2>",
+		"<ul>",
+		"Item 1",
+		"Item 2",
+		"</ul>",
+		"Done.",
+	}
+
+	for _, want := range expected {
 		if !strings.Contains(result, want) {
 			t.Errorf("Complex document missing: %q\nGot: %s", want, result)
 		}
 	}
 }
+
+func TestMarkdownToStorageWithOptions_DisabledMacros(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "code macro enabled by default",
+			input:    "```go\nx := 1\n```",
+			contains: []string{`ac:name="code"`, "<![CDATA["},
+		},
+		{
+			name:     "fenced code falls back to pre",
+			input:    "```go\nif a < b {}\n```",
+			opts:     Options{DisabledMacros: []string{"code"}},
+			contains: []string{`<pre><code class="language-go">if a &lt; b {}`, "</code></pre>"},
+			excludes: []string{"ac:structured-macro", "CDATA"},
+		},
+		{
+			name:     "indented code falls back to pre",
+			input:    "    plain code",
+			opts:     Options{DisabledMacros: []string{"code"}},
+			contains: []string{"<pre><code>plain code"},
+			excludes: []string{"ac:structured-macro"},
+		},
+		{
+			name:     "disabled panel macro keeps alert as blockquote",
+			input:    "> [!NOTE]\n> body",
+			opts:     Options{DisabledMacros: []string{"info"}},
+			contains: []string{"<blockquote>", "[!NOTE]"},
+			excludes: []string{"ac:structured-macro"},
+		},
+		{
+			name:     "disabled panel macro renders directive as blockquote",
+			input:    ":::tip Title\nbody\n:::",
+			opts:     Options{DisabledMacros: []string{"tip"}},
+			contains: []string{"<blockquote>", "<p><strong>Title</strong></p>", "<p>body</p>"},
+			excludes: []string{"ac:structured-macro", ":::"},
+		},
+		{
+			name:     "disabled status macro renders title in bold",
+			input:    "{status:green|DONE}",
+			opts:     Options{DisabledMacros: []string{"status"}},
+			contains: []string{"<p><strong>DONE</strong></p>"},
+			excludes: []string{"ac:structured-macro"},
+		},
+		{
+			name:     "disabled expand macro keeps details content under bold title",
+			input:    "<details>\n<summary>More</summary>\n\nbody\n\n</details>",
+			opts:     Options{DisabledMacros: []string{"expand"}},
+			contains: []string{"<p><strong>More</strong></p>\n<p>body</p>"},
+			excludes: []string{"ac:structured-macro", "raw HTML omitted"},
+		},
+		{
+			name:     "disabled preserved macro publishes as code",
+			input:    "```confluence-macro\n<ac:structured-macro ac:name=\"chart\"></ac:structured-macro>\n```",
+			opts:     Options{DisabledMacros: []string{"chart"}},
+			contains: []string{`ac:name="code"`, `<![CDATA[<ac:structured-macro ac:name="chart">`},
+		},
+		{
+			name:     "mermaid uses default macro",
+			input:    "```mermaid\ngraph TD\n  A --> B\n```",
+			contains: []string{`<ac:structured-macro ac:name="mermaid"><ac:plain-text-body><![CDATA[graph TD`, "A --> B"},
+			excludes: []string{`ac:name="code"`},
+		},
+		{
+			name:     "mermaid uses configured macro",
+			input:    "```mermaid\ngraph TD\n```",
+			opts:     Options{MermaidMacro: "mermaid-cloud"},
+			contains: []string{`ac:name="mermaid-cloud"`},
+		},
+		{
+			name:     "disabled mermaid macro falls back to code macro",
+			input:    "```mermaid\ngraph TD\n```",
+			opts:     Options{DisabledMacros: []string{"mermaid"}},
+			contains: []string{`ac:name="code"`, `<ac:parameter ac:name="language">none</ac:parameter>`},
+		},
+		{
+			name:     "mermaid falls back to pre when code also disabled",
+			input:    "```mermaid\nA --> B\n```",
+			opts:     Options{DisabledMacros: []string{"mermaid", "code"}},
+			contains: []string{`<pre><code class="language-mermaid">A --&gt; B`},
+			excludes: []string{"ac:structured-macro"},
+		},
+		{
+			name:     "plantuml uses default macro",
+			input:    "```plantuml\n@startuml\nA -> B\n@enduml\n```",
+			contains: []string{`<ac:structured-macro ac:name="plantuml"><ac:plain-text-body><![CDATA[@startuml`, "A -> B"},
+			excludes: []string{`ac:name="code"`},
+		},
+		{
+			name:     "plantuml uses configured macro",
+			input:    "```plantuml\nA -> B\n```",
+			opts:     Options{PlantUMLMacro: "plantumlrender"},
+			contains: []string{`ac:name="plantumlrender"`},
+		},
+		{
+			name:     "disabled plantuml macro falls back to code macro",
+			input:    "```plantuml\nA -> B\n```",
+			opts:     Options{DisabledMacros: []string{"plantuml"}},
+			contains: []string{`ac:name="code"`, `<ac:parameter ac:name="language">none</ac:parameter>`},
+		},
+		{
+			name:     "plantuml server renders image url",
+			input:    "```plantuml\nA -> B\n```",
+			opts:     Options{PlantUMLServer: "https://plantuml.example.com/"},
+			contains: []string{`<p><ac:image><ri:url ri:value="https://plantuml.example.com/png/~h41202d3e20420a" /></ac:image></p>`},
+			excludes: []string{"ac:structured-macro"},
+		},
+		{
+			name:     "disabled anchor macro leaves plain footnote numbers",
+			input:    "Text[^a].\n\n[^a]: Note.",
+			opts:     Options{DisabledMacros: []string{"anchor"}},
+			contains: []string{"Text<sup>1</sup>.", "<ol>\n<li>\n<p>Note.</p>"},
+			excludes: []string{"ac:anchor", "ac:structured-macro", "↩"},
+		},
+		{
+			name:     "disabled anchor macro leaves plain headings and fragment links",
+			input:    "## Setup\n\nSee [setup](#setup).",
+			opts:     Options{DisabledMacros: []string{"anchor"}},
+			contains: []string{"<h2>Setup</h2>", `<a href="#setup">setup</a>`},
+			excludes: []string{"ac:anchor", "ac:structured-macro"},
+		},
+		{
+			name:     "disabled toc macro drops marker",
+			input:    "[TOC]\n\n## A",
+			opts:     Options{DisabledMacros: []string{"toc"}},
+			contains: []string{`<h2><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">a</ac:parameter></ac:structured-macro>A</h2>`},
+			excludes: []string{"[TOC]", `ac:name="toc"`},
+		},
+		{
+			name:     "disabled anchor macro drops shortcode",
+			input:    "{anchor:here}Text",
+			opts:     Options{DisabledMacros: []string{"anchor"}},
+			contains: []string{"<p>Text</p>"},
+			excludes: []string{"{anchor:", "ac:structured-macro"},
+		},
+		{
+			name:     "disabled children macro drops shortcode",
+			input:    "{children}\n\nText",
+			opts:     Options{DisabledMacros: []string{"children"}},
+			contains: []string{"<p>Text</p>"},
+			excludes: []string{"{children}", `ac:name="children"`},
+		},
+		{
+			name:     "disabled include macro becomes page link",
+			input:    "{include:DOCS:Setup}",
+			opts:     Options{DisabledMacros: []string{"include"}},
+			contains: []string{`<p><ac:link><ri:page ri:space-key="DOCS" ri:content-title="Setup" /></ac:link></p>`},
+			excludes: []string{`ac:name="include"`},
+		},
+		{
+			name:     "disabled excerpt macro keeps content",
+			input:    "{excerpt}\nShared\n{excerpt}",
+			opts:     Options{DisabledMacros: []string{"excerpt"}},
+			contains: []string{"<p>Shared</p>"},
+			excludes: []string{"{excerpt}", `ac:name="excerpt"`},
+		},
+		{
+			name:     "unrelated macro disabled",
+			input:    "```go\nx := 1\n```",
+			opts:     Options{DisabledMacros: []string{"mermaid"}},
+			contains: []string{`ac:name="code"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertWith(t, tt.input, tt.opts)
+			for _, want := range tt.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("got %q, missing %q", result, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("got %q, unexpected %q", result, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_Footnotes(t *testing.T) {
+	input := "One[^a], two[^b], one again[^a].\n\n[^a]: First *note*.\n[^b]: Second note."
+	result := convert(t, input)
+
+	wants := []string{
+		// References are superscript links with an anchor to return to
+		`One<sup><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">fnref-1</ac:parameter></ac:structured-macro><ac:link ac:anchor="fn-1"><ac:plain-text-link-body><![CDATA[1]]></ac:plain-text-link-body></ac:link></sup>,`,
+		`<ac:parameter ac:name="">fnref-1-1</ac:parameter>`,
+		`<ac:link ac:anchor="fn-2"><ac:plain-text-link-body><![CDATA[2]]>`,
+		// Definitions collect in a numbered list at the end
+		"<hr />\n<ol>\n<li>",
+		`<ac:parameter ac:name="">fn-1</ac:parameter></ac:structured-macro>` + "\n<p>First <em>note</em>.",
+		`<ac:link ac:anchor="fnref-1-1"><ac:plain-text-link-body><![CDATA[↩]]>`,
+		`<ac:link ac:anchor="fnref-2">`,
+	}
+	for _, want := range wants {
+		if !strings.Contains(result, want) {
+			t.Errorf("got %q, missing %q", result, want)
+		}
+	}
+	for _, unwanted := range []string{"[^a]", "id=", `href="#`} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("got %q, unexpected %q", result, unwanted)
+		}
+	}
+}
+
+// anchorMacro is the anchor macro a heading with the given auto ID starts with
+func anchorMacro(name string) string {
+	return `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">` + name + `</ac:parameter></ac:structured-macro>`
+}
+
+func TestMarkdownToStorage_HeadingAnchors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "heading gets anchor from auto ID",
+			input: "## Getting Started",
+			want:  "<h2>" + anchorMacro("getting-started") + "Getting Started</h2>\n",
+		},
+		{
+			name:  "heading ID attribute names the anchor",
+			input: "## Getting Started {#setup_v2}",
+			want:  "<h2>" + anchorMacro("setup_v2") + "Getting Started</h2>\n",
+		},
+		{
+			name:  "duplicate headings get distinct anchors",
+			input: "# Intro\n\n# Intro",
+			want:  "<h1>" + anchorMacro("intro") + "Intro</h1>\n<h1>" + anchorMacro("intro-1") + "Intro</h1>\n",
+		},
+		{
+			name:  "fragment link targets anchor",
+			input: "See [the setup](#getting-started).",
+			want:  `<p>See <ac:link ac:anchor="getting-started"><ac:link-body>the setup</ac:link-body></ac:link>.</p>` + "\n",
+		},
+		{
+			name:  "external fragment stays a plain link",
+			input: "[x](https://example.com/#top)",
+			want:  `<p><a href="https://example.com/#top">x</a></p>` + "\n",
+		},
+		{
+			name:  "bare hash stays a plain link",
+			input: "[x](#)",
+			want:  `<p><a href="#">x</a></p>` + "\n",
+		},
+		{
+			name:  "anchor shortcode",
+			input: "{anchor:install-steps}Steps and [back](#install-steps)",
+			want:  "<p>" + anchorMacro("install-steps") + `Steps and <ac:link ac:anchor="install-steps"><ac:link-body>back</ac:link-body></ac:link></p>` + "\n",
+		},
+		{
+			name:  "empty anchor shortcode stays text",
+			input: "{anchor:} and {anchor:a&b}",
+			want:  "<p>{anchor:} and " + anchorMacro("a&amp;b") + "</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_TOC(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "marker replaced in place",
+			input: "# Title\n\n[TOC]\n\n## Section",
+			want:  "<h1>" + anchorMacro("title") + "Title</h1>\n" + `<ac:structured-macro ac:name="toc"></ac:structured-macro>` + "\n<h2>" + anchorMacro("section") + "Section</h2>\n",
+		},
+		{
+			name:  "marker is case-insensitive",
+			input: "[toc]",
+			want:  `<ac:structured-macro ac:name="toc"></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "levels become parameters",
+			input: "[TOC]",
+			opts:  Options{TOC: TOCOptions{MinLevel: 2, MaxLevel: 3}},
+			want:  `<ac:structured-macro ac:name="toc"><ac:parameter ac:name="minLevel">2</ac:parameter><ac:parameter ac:name="maxLevel">3</ac:parameter></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "insert adds macro at top",
+			input: "## Section",
+			opts:  Options{TOC: TOCOptions{Insert: true}},
+			want:  `<ac:structured-macro ac:name="toc"></ac:structured-macro>` + "\n<h2>" + anchorMacro("section") + "Section</h2>\n",
+		},
+		{
+			name:  "insert respects existing marker",
+			input: "## Intro\n\n[TOC]",
+			opts:  Options{TOC: TOCOptions{Insert: true}},
+			want:  "<h2>" + anchorMacro("intro") + "Intro</h2>\n" + `<ac:structured-macro ac:name="toc"></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "marker inside text is left alone",
+			input: "See [TOC] below",
+			want:  "<p>See [TOC] below</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_Children(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "bare shortcode",
+			input: "{children}",
+			want:  `<ac:structured-macro ac:name="children"></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "depth, sort, and reverse",
+			input: "{children depth=2 sort=Modified reverse}",
+			want:  `<ac:structured-macro ac:name="children"><ac:parameter ac:name="depth">2</ac:parameter><ac:parameter ac:name="sort">modified</ac:parameter><ac:parameter ac:name="reverse">true</ac:parameter></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "all descendants and created order",
+			input: "{CHILDREN depth=all sort=created}",
+			want:  `<ac:structured-macro ac:name="children"><ac:parameter ac:name="all">true</ac:parameter><ac:parameter ac:name="sort">creation</ac:parameter></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "invalid values dropped",
+			input: "{children depth=-1 sort=size}",
+			want:  `<ac:structured-macro ac:name="children"></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "shortcode inside text is left alone",
+			input: "See {children} and {childrenx}",
+			want:  "<p>See {children} and {childrenx}</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_IncludeAndExcerpt(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "include by title",
+			input: "{include:Shared Page}",
+			want:  `<ac:structured-macro ac:name="include"><ac:parameter ac:name=""><ac:link><ri:page ri:content-title="Shared Page" /></ac:link></ac:parameter></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "include with space key",
+			input: "{include:DOCS:Setup: Linux & Mac}",
+			want:  `<ac:structured-macro ac:name="include"><ac:parameter ac:name=""><ac:link><ri:page ri:space-key="DOCS" ri:content-title="Setup: Linux &amp; Mac" /></ac:link></ac:parameter></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "include inside text is left alone",
+			input: "See {include:X} and {include:}",
+			want:  "<p>See {include:X} and {include:}</p>\n",
+		},
+		{
+			name:  "excerpt wraps content",
+			input: "Intro\n{excerpt}\nShared **text**\n\n- item\n{excerpt}\n\nAfter",
+			want: "<p>Intro</p>\n" + `<ac:structured-macro ac:name="excerpt"><ac:rich-text-body>` +
+				"\n<p>Shared <strong>text</strong></p>\n<ul>\n<li>item\n</li>\n</ul>\n</ac:rich-text-body></ac:structured-macro>\n<p>After</p>\n",
+		},
+		{
+			name:  "unclosed excerpt runs to the end",
+			input: "{excerpt}\nAll of it",
+			want:  `<ac:structured-macro ac:name="excerpt"><ac:rich-text-body>` + "\n<p>All of it</p>\n</ac:rich-text-body></ac:structured-macro>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_WikiLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "title only",
+			input: "See [[Home]].",
+			want:  `<p>See <ac:link><ri:page ri:content-title="Home" /></ac:link>.</p>` + "\n",
+		},
+		{
+			name:  "space and title",
+			input: "[[DOCS:API Guide]]",
+			want:  `<p><ac:link><ri:page ri:space-key="DOCS" ri:content-title="API Guide" /></ac:link></p>` + "\n",
+		},
+		{
+			name:  "personal space",
+			input: "[[~jdoe:Scratch]]",
+			want:  `<p><ac:link><ri:page ri:space-key="~jdoe" ri:content-title="Scratch" /></ac:link></p>` + "\n",
+		},
+		{
+			name:  "link text",
+			input: "[[DOCS:API Guide|the API]]",
+			want:  `<p><ac:link><ri:page ri:space-key="DOCS" ri:content-title="API Guide" /><ac:plain-text-link-body><![CDATA[the API]]></ac:plain-text-link-body></ac:link></p>` + "\n",
+		},
+		{
+			name:  "colon in title without space key",
+			input: "[[Meeting: Q3 & Q4]]",
+			want:  `<p><ac:link><ri:page ri:content-title="Meeting: Q3 &amp; Q4" /></ac:link></p>` + "\n",
+		},
+		{
+			name:  "unclosed stays text",
+			input: "[[Home",
+			want:  "<p>[[Home</p>\n",
+		},
+		{
+			name:  "empty title stays text",
+			input: "[[ ]]",
+			want:  "<p>[[ ]]</p>\n",
+		},
+		{
+			name:  "code span untouched",
+			input: "`[[Home]]`",
+			want:  "<p><code>[[Home]]</code></p>\n",
+		},
+		{
+			name:  "markdown link still works",
+			input: "[Home](https://example.com)",
+			want:  `<p><a href="https://example.com">Home</a></p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_Emoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "shortcode with emoticon",
+			input: "Careful :warning: here",
+			want:  `<p>Careful <ac:emoticon ac:name="warning" /> here</p>` + "\n",
+		},
+		{
+			name:  "plus and minus one",
+			input: ":+1: :-1:",
+			want:  `<p><ac:emoticon ac:name="thumbs-up" /> <ac:emoticon ac:name="thumbs-down" /></p>` + "\n",
+		},
+		{
+			name:  "shortcode without emoticon",
+			input: "Shipped :rocket:",
+			want:  "<p>Shipped 🚀</p>\n",
+		},
+		{
+			name:  "unknown shortcode stays text",
+			input: ":not_an_emoji:",
+			want:  "<p>:not_an_emoji:</p>\n",
+		},
+		{
+			name:  "times are not shortcodes",
+			input: "At 10:30:00",
+			want:  "<p>At 10:30:00</p>\n",
+		},
+		{
+			name:  "unicode with emoticon",
+			input: "Done ✅ and 👍",
+			want:  `<p>Done <ac:emoticon ac:name="tick" /> and <ac:emoticon ac:name="thumbs-up" /></p>` + "\n",
+		},
+		{
+			name:  "variation selector consumed",
+			input: "⚠️ & more",
+			want:  `<p><ac:emoticon ac:name="warning" /> &amp; more</p>` + "\n",
+		},
+		{
+			name:  "unicode without emoticon kept",
+			input: "Party 🎉",
+			want:  "<p>Party 🎉</p>\n",
+		},
+		{
+			name:  "code span untouched",
+			input: "`:warning: ✅`",
+			want:  "<p><code>:warning: ✅</code></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmojiID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "🚀", want: "1f680"},
+		{input: ":rocket:", want: "1f680"},
+		{input: "⚠️", want: "26a0"},
+		{input: "👍🏽", want: "1f44d-1f3fd"},
+		{input: ":nope:", wantErr: "unknown emoji shortcode :nope:"},
+		{input: "ab", wantErr: "is not an emoji"},
+		{input: " ", wantErr: "emoji cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := EmojiID(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("EmojiID(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("EmojiID(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_Status(t *testing.T) {
+	lozenge := func(colour, title string) string {
+		s := `<ac:structured-macro ac:name="status">`
+		if colour != "" {
+			s += `<ac:parameter ac:name="colour">` + colour + `</ac:parameter>`
+		}
+		return s + `<ac:parameter ac:name="title">` + title + `</ac:parameter></ac:structured-macro>`
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "colour and title",
+			input: "State: {status:green|DONE}",
+			want:  "<p>State: " + lozenge("Green", "DONE") + "</p>\n",
+		},
+		{
+			name:  "default colour",
+			input: "{status:In review}",
+			want:  "<p>" + lozenge("", "In review") + "</p>\n",
+		},
+		{
+			name:  "case and spacing",
+			input: "{Status: Gray | on hold }",
+			want:  "<p>" + lozenge("Grey", "on hold") + "</p>\n",
+		},
+		{
+			name:  "title escaped",
+			input: "{status:red|R&D}",
+			want:  "<p>" + lozenge("Red", "R&amp;D") + "</p>\n",
+		},
+		{
+			name:  "unknown colour stays text",
+			input: "{status:pink|X}",
+			want:  "<p>{status:pink|X}</p>\n",
+		},
+		{
+			name:  "empty title stays text",
+			input: "{status:}",
+			want:  "<p>{status:}</p>\n",
+		},
+		{
+			name:  "code span untouched",
+			input: "`{status:DONE}`",
+			want:  "<p><code>{status:DONE}</code></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("escaped pipe in table cell", func(t *testing.T) {
+		got := convert(t, "| State |\n|---|\n| {status:blue\\|NEW} |")
+		if want := "<td>" + lozenge("Blue", "NEW") + "</td>"; !strings.Contains(got, want) {
+			t.Errorf("got %q, missing %q", got, want)
+		}
+	})
+}
+
+func TestMarkdownToStorage_Math(t *testing.T) {
+	inline := func(body string) string {
+		return `<ac:structured-macro ac:name="mathinline"><ac:parameter ac:name="body">` + body + `</ac:parameter></ac:structured-macro>`
+	}
+	block := func(body string) string {
+		return `<ac:structured-macro ac:name="mathblock"><ac:plain-text-body><![CDATA[` + body + `]]></ac:plain-text-body></ac:structured-macro>` + "\n"
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "inline",
+			input: "Area is $\\pi r^2$ here",
+			want:  "<p>Area is " + inline("\\pi r^2") + " here</p>\n",
+		},
+		{
+			name:  "inline escapes markup",
+			input: "$a<b$",
+			want:  "<p>" + inline("a&lt;b") + "</p>\n",
+		},
+		{
+			name:  "double dollar within text",
+			input: "so $$x^2$$ holds",
+			want:  "<p>so " + inline("x^2") + " holds</p>\n",
+		},
+		{
+			name:  "prices stay text",
+			input: "Costs $5 and $10 today",
+			want:  "<p>Costs $5 and $10 today</p>\n",
+		},
+		{
+			name:  "space after opener is not math",
+			input: "$ x$",
+			want:  "<p>$ x$</p>\n",
+		},
+		{
+			name:  "code span untouched",
+			input: "`$x$`",
+			want:  "<p><code>$x$</code></p>\n",
+		},
+		{
+			name:  "display block",
+			input: "$$\n\\int_0^1 f(x)\\,dx\n$$",
+			want:  block("\\int_0^1 f(x)\\,dx\n"),
+		},
+		{
+			name:  "display block on one line",
+			input: "$$ E = mc^2 $$\n\nAfter",
+			want:  block("E = mc^2") + "<p>After</p>\n",
+		},
+		{
+			name:  "delimiters on formula lines",
+			input: "$$a +\nb$$",
+			want:  block("a +\nb"),
+		},
+		{
+			name:  "math fence",
+			input: "```math\na^2 + b^2\n```",
+			want:  block("a^2 + b^2\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(t, tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_Math(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "custom macro names",
+			input: "$x$\n\n$$\ny\n$$",
+			opts:  Options{MathInlineMacro: "latex-inline", MathBlockMacro: "latex-block"},
+			want: `<p><ac:structured-macro ac:name="latex-inline"><ac:parameter ac:name="body">x</ac:parameter></ac:structured-macro></p>` + "\n" +
+				`<ac:structured-macro ac:name="latex-block"><ac:plain-text-body><![CDATA[y` + "\n" + `]]></ac:plain-text-body></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "disabled macros fall back to code",
+			input: "$x$\n\n$$\ny\n$$",
+			opts:  Options{DisabledMacros: []string{"mathinline", "mathblock"}},
+			want: "<p><code>x</code></p>\n" +
+				`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">none</ac:parameter><ac:plain-text-body><![CDATA[y` + "\n" + `]]></ac:plain-text-body></ac:structured-macro>` + "\n",
+		},
+		{
+			name:  "disabled math fence stays code",
+			input: "```math\ny\n```",
+			opts:  Options{DisabledMacros: []string{"mathblock"}},
+			want:  `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">none</ac:parameter><ac:plain-text-body><![CDATA[y` + "\n" + `]]></ac:plain-text-body></ac:structured-macro>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorage_Dates(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "shortcode",
+			input: "Due {date:2025-03-01}.",
+			want:  `<p>Due <time datetime="2025-03-01" />.</p>` + "\n",
+		},
+		{
+			name:  "invalid date stays text",
+			input: "{date:2025-02-30} {date:soon}",
+			want:  "<p>{date:2025-02-30} {date:soon}</p>\n",
+		},
+		{
+			name:  "bare dates off by default",
+			input: "Due 2025-03-01",
+			want:  "<p>Due 2025-03-01</p>\n",
+		},
+		{
+			name:  "bare dates",
+			input: "From 2025-03-01 to 2025-03-14.",
+			opts:  Options{BareDates: true},
+			want:  `<p>From <time datetime="2025-03-01" /> to <time datetime="2025-03-14" />.</p>` + "\n",
+		},
+		{
+			name:  "bare dates within other tokens",
+			input: "2025-03-01T10:00 v2025-03-01 2025-03-01-rc1 logs/2025-03-01 2025-13-01",
+			opts:  Options{BareDates: true},
+			want:  "<p>2025-03-01T10:00 v2025-03-01 2025-03-01-rc1 logs/2025-03-01 2025-13-01</p>\n",
+		},
+		{
+			name:  "bare dates in code untouched",
+			input: "`2025-03-01`",
+			opts:  Options{BareDates: true},
+			want:  "<p><code>2025-03-01</code></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_Jira(t *testing.T) {
+	issue := func(key string) string {
+		return `<ac:structured-macro ac:name="jira"><ac:parameter ac:name="key">` + key + `</ac:parameter></ac:structured-macro>`
+	}
+	projects := JiraOptions{Projects: []string{"PROJ", "OPS"}}
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "off by default",
+			input: "Fixes PROJ-123",
+			want:  "<p>Fixes PROJ-123</p>\n",
+		},
+		{
+			name:  "configured projects",
+			input: "Fixes PROJ-123 and OPS-7, not UTF-8",
+			opts:  Options{Jira: projects},
+			want:  "<p>Fixes " + issue("PROJ-123") + " and " + issue("OPS-7") + ", not UTF-8</p>\n",
+		},
+		{
+			name:  "any project",
+			input: "ABC-1",
+			opts:  Options{Jira: JiraOptions{Projects: []string{"*"}}},
+			want:  "<p>" + issue("ABC-1") + "</p>\n",
+		},
+		{
+			name:  "server parameters",
+			input: "PROJ-1",
+			opts:  Options{Jira: JiraOptions{Projects: []string{"PROJ"}, Server: "Acme Jira", ServerID: "1a2b"}},
+			want: `<p><ac:structured-macro ac:name="jira"><ac:parameter ac:name="server">Acme Jira</ac:parameter>` +
+				`<ac:parameter ac:name="serverId">1a2b</ac:parameter><ac:parameter ac:name="key">PROJ-1</ac:parameter></ac:structured-macro></p>` + "\n",
+		},
+		{
+			name:  "part of a longer token",
+			input: "PROJ-1-2 src/PROJ-3 XPROJ-4 PROJ-05",
+			opts:  Options{Jira: projects},
+			want:  "<p>PROJ-1-2 src/PROJ-3 XPROJ-4 PROJ-05</p>\n",
+		},
+		{
+			name:  "code and links untouched",
+			input: "`PROJ-1` [PROJ-2](https://example.com) **PROJ-3**",
+			opts:  Options{Jira: projects},
+			want:  `<p><code>PROJ-1</code> <a href="https://example.com">PROJ-2</a> <strong>` + issue("PROJ-3") + "</strong></p>\n",
+		},
+		{
+			name:  "line breaks kept",
+			input: "See PROJ-1\nand OPS-2",
+			opts:  Options{Jira: projects},
+			want:  "<p>See " + issue("PROJ-1") + "\nand " + issue("OPS-2") + "</p>\n",
+		},
+		{
+			name:  "disabled macro leaves the key",
+			input: "PROJ-1",
+			opts:  Options{Jira: projects, DisabledMacros: []string{"jira"}},
+			want:  "<p>PROJ-1</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_Mentions(t *testing.T) {
+	opts := Options{ResolveUser: func(query string) (string, error) {
+		if query == "Nobody" {
+			return "", errors.New("no user found")
+		}
+		return "id:" + query, nil
+	}}
+	user := func(id string) string {
+		return `<ac:link><ri:user ri:account-id="` + id + `" /></ac:link>`
+	}
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "display name",
+			input: "Ask @{ Jane Doe } today",
+			opts:  opts,
+			want:  "<p>Ask " + user("id:Jane Doe") + " today</p>\n",
+		},
+		{
+			name:  "email without trailing punctuation",
+			input: "Ask @jane.doe@example.com.",
+			opts:  opts,
+			want:  "<p>Ask " + user("id:jane.doe@example.com") + ".</p>\n",
+		},
+		{
+			name:  "unresolved stays as written",
+			input: "@{Nobody} & co",
+			opts:  opts,
+			want:  "<p>@{Nobody} &amp; co</p>\n",
+		},
+		{
+			name:  "no resolver",
+			input: "@{Jane Doe}",
+			want:  "<p>@{Jane Doe}</p>\n",
+		},
+		{
+			name:  "handles are not mentions",
+			input: "@jane and @{} and @ x",
+			opts:  opts,
+			want:  "<p>@jane and @{} and @ x</p>\n",
+		},
+		{
+			name:  "code span untouched",
+			input: "`@{Jane Doe}`",
+			opts:  opts,
+			want:  "<p><code>@{Jane Doe}</code></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_PageLinks(t *testing.T) {
+	opts := Options{
+		PageLinks: map[string]PageRef{
+			"guide/setup.md":   {Title: "Setup"},
+			"guide/index.md":   {Title: "Guide"},
+			"reference/api.md": {SpaceKey: "API", Title: "API & SDK"},
+		},
+		LinkBase: "guide",
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "sibling document",
+			input: "[Setup](./setup.md)",
+			want:  `<p><ac:link><ri:page ri:content-title="Setup" /><ac:link-body>Setup</ac:link-body></ac:link></p>` + "\n",
+		},
+		{
+			name:  "fragment becomes anchor",
+			input: "[install](setup.md#install-steps)",
+			want:  `<p><ac:link ac:anchor="install-steps"><ri:page ri:content-title="Setup" /><ac:link-body>install</ac:link-body></ac:link></p>` + "\n",
+		},
+		{
+			name:  "parent directory and other space",
+			input: "[the **API**](../reference/api.md)",
+			want:  `<p><ac:link><ri:page ri:space-key="API" ri:content-title="API &amp; SDK" /><ac:link-body>the <strong>API</strong></ac:link-body></ac:link></p>` + "\n",
+		},
+		{
+			name:  "unmapped document left alone",
+			input: "[x](missing.md)",
+			want:  `<p><a href="missing.md">x</a></p>` + "\n",
+		},
+		{
+			name:  "absolute url left alone",
+			input: "[x](https://example.com/setup.md)",
+			want:  `<p><a href="https://example.com/setup.md">x</a></p>` + "\n",
+		},
+		{
+			name:  "same page fragment links to anchor",
+			input: "[x](#setup)",
+			want:  `<p><ac:link ac:anchor="setup"><ac:link-body>x</ac:link-body></ac:link></p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertWith(t, tt.input, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_AttachImage(t *testing.T) {
+	var paths []string
+	opts := Options{
+		AttachImage: func(path string) (string, error) {
+			paths = append(paths, path)
+			if strings.Contains(path, "missing") {
+				return "", errors.New("no such file")
+			}
+			return "arch.png", nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		wantPath string
+	}{
+		{
+			name:     "relative path becomes attachment",
+			input:    "![diagram](./img/arch.png)",
+			want:     `<p><ac:image ac:alt="diagram"><ri:attachment ri:filename="arch.png" /></ac:image></p>` + "\n",
+			wantPath: "./img/arch.png",
+		},
+		{
+			name:     "escaped path is decoded",
+			input:    "![x](my%20img.png)",
+			want:     `<p><ac:image ac:alt="x"><ri:attachment ri:filename="arch.png" /></ac:image></p>` + "\n",
+			wantPath: "my img.png",
+		},
+		{
+			name:  "url stays external",
+			input: "![x](https://example.com/a.png)",
+			want:  `<p><ac:image ac:alt="x"><ri:url ri:value="https://example.com/a.png" /></ac:image></p>` + "\n",
+		},
+		{
+			name:     "attacher error keeps reference",
+			input:    "![x](missing.png)",
+			want:     `<p><ac:image ac:alt="x"><ri:url ri:value="missing.png" /></ac:image></p>` + "\n",
+			wantPath: "missing.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths = nil
+			if got := convertWith(t, tt.input, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			gotPath := ""
+			if len(paths) > 0 {
+				gotPath = paths[0]
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("AttachImage called with %q, want %q", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestMarkdownToStorageWithOptions_RenderDiagram(t *testing.T) {
+	var gotLang, gotSource string
+	opts := Options{
+		RenderDiagram: func(lang string, source []byte) (string, error) {
+			gotLang, gotSource = lang, string(source)
+			return "mermaid-abc.svg", nil
+		},
+	}
+
+	result := convertWith(t, "```mermaid\ngraph TD\n  A --> B\n```\n\n```go\nx := 1\n```", opts)
+
+	if gotLang != "mermaid" || gotSource != "graph TD\n  A --> B\n" {
+		t.Errorf("renderer called with %q, %q", gotLang, gotSource)
+	}
+	if !strings.Contains(result, `<ac:image><ri:attachment ri:filename="mermaid-abc.svg" /></ac:image>`) {
+		t.Errorf("got %q, missing attachment image", result)
+	}
+	if strings.Contains(result, `ac:name="mermaid"`) {
+		t.Errorf("got %q, want no mermaid macro when rendered", result)
+	}
+	if !strings.Contains(result, `<ac:parameter ac:name="language">go</ac:parameter>`) {
+		t.Errorf("got %q, non-diagram code block should be unaffected", result)
+	}
+}
+
+func TestMarkdownToStorageWithOptions_PlantUMLServerBeforeRenderer(t *testing.T) {
+	called := false
+	opts := Options{
+		PlantUMLServer: "https://plantuml.example.com",
+		RenderDiagram: func(lang string, source []byte) (string, error) {
+			called = true
+			return lang + ".svg", nil
+		},
+	}
+
+	result := convertWith(t, "```plantuml\nA -> B\n```", opts)
+	if called {
+		t.Error("renderer called for plantuml with a server configured")
+	}
+	if !strings.Contains(result, "ri:url") {
+		t.Errorf("got %q, want server image", result)
+	}
+}
+
+func TestMarkdownToStorageWithOptions_RenderDiagramError(t *testing.T) {
+	opts := Options{
+		RenderDiagram: func(lang string, source []byte) (string, error) {
+			return "", errors.New("renderer unavailable")
+		},
+	}
+
+	result := convertWith(t, "```mermaid\ngraph TD\n```", opts)
+	if !strings.Contains(result, `ac:name="mermaid"`) || strings.Contains(result, "ac:image") {
+		t.Errorf("got %q, want fallback to mermaid macro", result)
+	}
+}
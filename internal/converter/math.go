@@ -0,0 +1,169 @@
+package converter
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// mathDelimiter opens and closes display math
+var mathDelimiter = []byte("$$")
+
+// kindMathBlock is the node kind for $$ display math
+var kindMathBlock = ast.NewNodeKind("MathBlock")
+
+// mathBlock is LaTeX display math, on one line or fenced by $$ lines:
+//
+//	$$
+//	E = mc^2
+//	$$
+type mathBlock struct {
+	ast.BaseBlock
+	closed bool // the closing $$ has been read
+}
+
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathBlockParser parses $$ display math into mathBlock nodes
+type mathBlockParser struct{}
+
+func newMathBlockParser() parser.BlockParser {
+	return &mathBlockParser{}
+}
+
+func (b *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (b *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], mathDelimiter) {
+		return nil, parser.NoChildren
+	}
+	node := &mathBlock{}
+	start := segment.Start + pos + len(mathDelimiter)
+	rest := util.TrimRightSpace(line[pos+len(mathDelimiter):])
+
+	// $$ E = mc^2 $$ on a single line. Text after the closing $$ makes it
+	// inline math in a paragraph instead.
+	if end := bytes.Index(rest, mathDelimiter); end >= 0 && end != len(rest)-len(mathDelimiter) {
+		return nil, parser.NoChildren
+	}
+	if len(rest) >= len(mathDelimiter) && bytes.HasSuffix(rest, mathDelimiter) {
+		inner := text.NewSegment(start, start+len(rest)-len(mathDelimiter))
+		inner = inner.TrimLeftSpace(reader.Source())
+		node.Lines().Append(inner.TrimRightSpace(reader.Source()))
+		reader.Advance(segment.Len() - trailingNewline(line))
+		node.closed = true
+		return node, parser.NoChildren
+	}
+	if len(util.TrimLeftSpace(rest)) > 0 {
+		node.Lines().Append(text.NewSegment(start, segment.Stop))
+	}
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return node, parser.NoChildren
+}
+
+func (b *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	if node.(*mathBlock).closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	trimmed := util.TrimRightSpace(line)
+	if bytes.HasSuffix(trimmed, mathDelimiter) {
+		if before := len(trimmed) - len(mathDelimiter); len(util.TrimLeftSpace(trimmed[:before])) > 0 {
+			node.Lines().Append(text.NewSegment(segment.Start, segment.Start+before))
+		}
+		reader.Advance(segment.Len() - trailingNewline(line))
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return parser.Continue | parser.NoChildren
+}
+
+func (b *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (b *mathBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (b *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// kindMathInline is the node kind for $ inline math
+var kindMathInline = ast.NewNodeKind("MathInline")
+
+// mathInline is LaTeX math within text, as in $x^2$ or $$x^2$$
+type mathInline struct {
+	ast.BaseInline
+	value []byte
+}
+
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMathInline
+}
+
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.value)}, nil)
+}
+
+// mathInlineParser parses $...$ into mathInline nodes. As in pandoc, the
+// opening $ must be followed by a non-space and the closing $ preceded by a
+// non-space and not followed by a digit, so prices such as "$5 and $10"
+// stay text.
+type mathInlineParser struct{}
+
+func newMathInlineParser() parser.InlineParser {
+	return &mathInlineParser{}
+}
+
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delim := 1
+	if bytes.HasPrefix(line, mathDelimiter) {
+		delim = 2
+	}
+	body := line[delim:]
+	if len(body) == 0 || util.IsSpace(body[0]) {
+		return nil
+	}
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++ // \$ is part of the formula
+		case '$':
+			if delim == 2 && !bytes.HasPrefix(body[i:], mathDelimiter) {
+				continue
+			}
+			if i == 0 || util.IsSpace(body[i-1]) {
+				return nil
+			}
+			if next := i + delim; next < len(body) && body[next] >= '0' && body[next] <= '9' {
+				return nil
+			}
+			block.Advance(delim + i + delim)
+			return &mathInline{value: body[:i]}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// mentionEmailRegex matches the email address of an @email@example.com
+// mention, leaving out trailing sentence punctuation
+var mentionEmailRegex = regexp.MustCompile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+`)
+
+// kindMention is the node kind for user mentions
+var kindMention = ast.NewNodeKind("Mention")
+
+// mention is a user mention written as @{Display Name} or
+// @email@example.com. raw is the text as written, kept if the user cannot
+// be resolved.
+type mention struct {
+	ast.BaseInline
+	query string
+	raw   string
+}
+
+func (n *mention) Kind() ast.NodeKind {
+	return kindMention
+}
+
+func (n *mention) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Query": n.query}, nil)
+}
+
+// mentionParser parses @{Display Name} and @email mentions into mention
+// nodes
+type mentionParser struct{}
+
+func newMentionParser() parser.InlineParser {
+	return &mentionParser{}
+}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	rest := line[1:]
+	if bytes.HasPrefix(rest, []byte("{")) {
+		end := bytes.IndexByte(rest, '}')
+		if end < 0 {
+			return nil
+		}
+		name := string(bytes.TrimSpace(rest[1:end]))
+		if name == "" {
+			return nil
+		}
+		block.Advance(end + 2)
+		return &mention{query: name, raw: string(line[:end+2])}
+	}
+	email := mentionEmailRegex.Find(rest)
+	if email == nil {
+		return nil
+	}
+	block.Advance(len(email) + 1)
+	return &mention{query: string(email), raw: string(line[:len(email)+1])}
+}
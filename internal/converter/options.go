@@ -0,0 +1,269 @@
+package converter
+
+// Options controls optional behaviour of the Markdown → storage conversion.
+// The zero value produces the default output.
+type Options struct {
+	// DisabledMacros lists Confluence macro names (e.g. "code") that the
+	// destination does not support. Content that would use a disabled macro
+	// falls back to plain XHTML instead.
+	DisabledMacros []string
+
+	// MermaidMacro is the name of the macro that renders ```mermaid fences.
+	// Confluence has no built-in Mermaid support, so it depends on the
+	// installed app. Empty means DefaultMermaidMacro.
+	MermaidMacro string
+
+	// PlantUMLMacro is the name of the macro that renders ```plantuml fences.
+	// Empty means DefaultPlantUMLMacro.
+	PlantUMLMacro string
+
+	// PlantUMLServer is the base URL of a PlantUML server, for example
+	// "https://www.plantuml.com/plantuml". When set, ```plantuml fences become
+	// images served by it instead of a macro.
+	PlantUMLServer string
+
+	// MathBlockMacro and MathInlineMacro name the macros that render $$
+	// display math and $ inline math. Confluence has no built-in LaTeX
+	// support, so they depend on the installed app. Empty means
+	// DefaultMathBlockMacro and DefaultMathInlineMacro.
+	MathBlockMacro  string
+	MathInlineMacro string
+
+	// RenderDiagram, if set, renders diagram fences (e.g. ```mermaid) to
+	// images instead of emitting a diagram macro.
+	RenderDiagram DiagramRenderer
+
+	// AttachImage, if set, is called for images that reference a local
+	// file rather than a URL, so they can be uploaded as attachments.
+	AttachImage ImageAttacher
+
+	// ResolveUser, if set, looks up the account ID of a user mentioned as
+	// @{Display Name} or @email@example.com. Without it, mentions stay text.
+	ResolveUser UserResolver
+
+	// AllowHTML passes raw HTML through instead of omitting it, limited to
+	// line breaks, superscript, and subscript elements. Other tags are
+	// dropped but their text is kept. HTML tables are kept regardless.
+	AllowHTML bool
+
+	// Strict fails the conversion with an error wrapping ErrUnsupported,
+	// instead of dropping content, when raw HTML would be omitted or
+	// stripped of elements, or an adf fence would become a code block.
+	// HTML comments are still dropped quietly.
+	Strict bool
+
+	// Typography replaces straight quotes with curly ones, "--" and "---"
+	// with en and em dashes, and "..." with an ellipsis. Off by default so
+	// text is published exactly as written.
+	Typography bool
+
+	// Subscript converts ~text~ to subscript, as in Pandoc. Off by default
+	// because GFM reads single tildes as strikethrough, so ~text~ stays
+	// struck through unless asked for. ~~text~~ is always strikethrough.
+	Subscript bool
+
+	// HardWraps turns every newline within a paragraph into a line break,
+	// as in pasted notes. By default, as in CommonMark, lines are joined and
+	// only a trailing backslash or two spaces break the line.
+	HardWraps bool
+
+	// HeadingOffset shifts every heading's level, so 1 turns # into <h2>
+	// for documents whose top heading repeats the page title. Negative
+	// values promote headings. Levels are kept within 1 to 6.
+	HeadingOffset int
+
+	// TOC controls the table of contents macro that replaces "[TOC]".
+	TOC TOCOptions
+
+	// Jira controls which Jira issue keys in text become Jira issue macros.
+	Jira JiraOptions
+
+	// BareDates converts ISO dates such as 2025-03-01 in text into date
+	// lozenges, as {date:2025-03-01} always is.
+	BareDates bool
+
+	// PageLinks maps Markdown files, as slash-separated paths relative to
+	// the root of a published directory, to the pages they became. Relative
+	// links to a mapped file are rewritten into Confluence page links.
+	PageLinks map[string]PageRef
+	// LinkBase is the directory of the document being converted, relative
+	// to the same root, against which its relative links are resolved.
+	LinkBase string
+}
+
+// TOCOptions configures the table of contents macro.
+type TOCOptions struct {
+	// Insert adds a table of contents at the top of documents that have
+	// no "[TOC]" marker.
+	Insert bool
+	// MinLevel and MaxLevel limit the heading levels listed. Zero leaves
+	// the macro's default.
+	MinLevel int
+	MaxLevel int
+}
+
+// DefaultMermaidMacro is the macro used for ```mermaid fences when
+// Options.MermaidMacro is empty.
+const DefaultMermaidMacro = "mermaid"
+
+// DefaultPlantUMLMacro is the macro used for ```plantuml fences when
+// Options.PlantUMLMacro is empty.
+const DefaultPlantUMLMacro = "plantuml"
+
+// DefaultMathBlockMacro is the macro used for $$ display math when
+// Options.MathBlockMacro is empty.
+const DefaultMathBlockMacro = "mathblock"
+
+// DefaultMathInlineMacro is the macro used for $ inline math when
+// Options.MathInlineMacro is empty.
+const DefaultMathInlineMacro = "mathinline"
+
+// DiagramRenderer renders diagram source written in lang to an image and
+// returns the filename of the page attachment that will hold it. The caller
+// is responsible for uploading the image. If it returns an error, the block
+// falls back to the diagram macro.
+type DiagramRenderer func(lang string, source []byte) (filename string, err error)
+
+// ImageAttacher arranges for the local image at path to be attached to the
+// page and returns the attachment's filename. If it returns an error, the
+// image keeps its original reference.
+type ImageAttacher func(path string) (filename string, err error)
+
+// PageResolver returns the URL of the page ref names. If it returns an
+// error, the link is written as a wiki link.
+type PageResolver func(ref PageRef) (url string, err error)
+
+// UserNameResolver returns the display name of the user with the given
+// account ID. If it returns an error, the mention is written with the
+// account ID.
+type UserNameResolver func(accountID string) (name string, err error)
+
+// ChildPage is a child page listed by a children macro.
+type ChildPage struct {
+	ID    string
+	Title string
+	URL   string
+}
+
+// ChildrenResolver returns the child pages of the page with the given ID in
+// page tree order, or those of the page being converted for an empty ID. If
+// it returns an error, the children macro is kept.
+type ChildrenResolver func(pageID string) ([]ChildPage, error)
+
+// UserResolver returns the account ID of the user named by query, a display
+// name or an email address. If it returns an error, the mention is left as
+// written.
+type UserResolver func(query string) (accountID string, err error)
+
+// withDocument returns o with the fields set in doc replaced
+func (o Options) withDocument(doc Document) Options {
+	if doc.RenderDiagram != nil {
+		o.RenderDiagram = doc.RenderDiagram
+	}
+	if doc.AttachImage != nil {
+		o.AttachImage = doc.AttachImage
+	}
+	if doc.ResolveUser != nil {
+		o.ResolveUser = doc.ResolveUser
+	}
+	if doc.PageLinks != nil {
+		o.PageLinks = doc.PageLinks
+	}
+	if doc.LinkBase != "" {
+		o.LinkBase = doc.LinkBase
+	}
+	return o
+}
+
+// diagramMacro returns the macro name for a diagram fence language, or ""
+// if lang is not a diagram language.
+func (o Options) diagramMacro(lang string) string {
+	switch lang {
+	case "mermaid":
+		if o.MermaidMacro != "" {
+			return o.MermaidMacro
+		}
+		return DefaultMermaidMacro
+	case "plantuml":
+		if o.PlantUMLMacro != "" {
+			return o.PlantUMLMacro
+		}
+		return DefaultPlantUMLMacro
+	}
+	return ""
+}
+
+// mathBlockMacro returns the macro name for display math
+func (o Options) mathBlockMacro() string {
+	if o.MathBlockMacro != "" {
+		return o.MathBlockMacro
+	}
+	return DefaultMathBlockMacro
+}
+
+// mathInlineMacro returns the macro name for inline math
+func (o Options) mathInlineMacro() string {
+	if o.MathInlineMacro != "" {
+		return o.MathInlineMacro
+	}
+	return DefaultMathInlineMacro
+}
+
+// headingLevel returns the level written for a heading of the given level
+func (o Options) headingLevel(level int) int {
+	return min(max(level+o.HeadingOffset, 1), 6)
+}
+
+// macroEnabled reports whether the named macro may be emitted.
+func (o Options) macroEnabled(name string) bool {
+	for _, disabled := range o.DisabledMacros {
+		if disabled == name {
+			return false
+		}
+	}
+	return true
+}
+
+// StorageOptions controls optional behaviour of the storage → Markdown
+// conversion. The zero value produces the default output.
+type StorageOptions struct {
+	// Admonitions writes panel macros as ::: directive blocks, which keep
+	// the panel type and title, instead of GitHub alerts.
+	Admonitions bool
+
+	// RoundTrip writes macros that have no Markdown equivalent as the
+	// shortcodes MarkdownToStorage reads, such as {status:green|DONE}, so
+	// they survive being published again. Otherwise they are written as
+	// plain Markdown for reading.
+	RoundTrip bool
+
+	// KeepHTML writes what Markdown cannot express as inline HTML instead
+	// of dropping it: tables with merged cells, which MarkdownToStorage
+	// publishes again, and coloured and underlined text.
+	KeepHTML bool
+
+	// AttachmentDir is the directory, relative to the Markdown, that images
+	// of the page's attachments link to. It defaults to AttachmentDir.
+	AttachmentDir string
+
+	// ResolvePage, if set, looks up the URL of a page linked by title, so
+	// the link becomes a Markdown link to it. Otherwise, and in round-trip
+	// mode, page links are written as [[Page Title]] wiki links.
+	ResolvePage PageResolver
+
+	// ResolveUserName, if set, looks up the display name of a mentioned
+	// user, so the mention becomes @Display Name, or @{Display Name} in
+	// round-trip mode. Otherwise mentions are written as @account-id.
+	ResolveUserName UserNameResolver
+
+	// ResolveChildren, if set, lists child pages, so a children macro
+	// becomes a list of links to the pages it shows. Otherwise, and in
+	// round-trip mode, the macro is kept.
+	ResolveChildren ChildrenResolver
+
+	// JiraURL is the base URL of the Jira site, such as
+	// "https://example.atlassian.net". Jira issue macros become links to
+	// JiraURL/browse/KEY, or the bare issue key if it is empty or in
+	// round-trip mode.
+	JiraURL string
+}
@@ -0,0 +1,36 @@
+package converter
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PageRef identifies a Confluence page by title. An empty SpaceKey means
+// the space of the page being written.
+type PageRef struct {
+	SpaceKey string
+	Title    string
+}
+
+// pageLink resolves a relative Markdown link such as "./other.md#setup"
+// through Options.PageLinks. It returns the target page and the fragment,
+// or false if dest is not a mapped local document.
+func (o Options) pageLink(dest string) (PageRef, string, bool) {
+	if len(o.PageLinks) == 0 {
+		return PageRef{}, "", false
+	}
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return PageRef{}, "", false
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if ext != ".md" && ext != ".markdown" {
+		return PageRef{}, "", false
+	}
+	ref, ok := o.PageLinks[path.Join(o.LinkBase, u.Path)]
+	if !ok {
+		return PageRef{}, "", false
+	}
+	return ref, u.Fragment, true
+}
@@ -0,0 +1,13 @@
+package converter
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// plantUMLImageURL returns the URL at which server renders source as a PNG.
+// The source travels hex-encoded ("~h"), which every PlantUML server accepts
+// without the deflate dance of its default encoding.
+func plantUMLImageURL(server string, source []byte) string {
+	return strings.TrimRight(server, "/") + "/png/~h" + hex.EncodeToString(source)
+}
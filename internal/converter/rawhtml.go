@@ -0,0 +1,250 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"golang.org/x/net/html"
+)
+
+// allowedHTMLTags lists the raw HTML elements passed through when
+// Options.AllowHTML is set, and whether each is a void element. Tables are
+// only kept whole, by htmlTableTransformer.
+var allowedHTMLTags = map[string]bool{
+	"br":  true,
+	"sup": false,
+	"sub": false,
+}
+
+// allowedHTMLAttrs lists the attributes kept on allowed elements
+var allowedHTMLAttrs = map[string]bool{
+	"colspan": true,
+	"rowspan": true,
+	"span":    true,
+}
+
+// droppedHTMLContent lists elements whose content is removed along with them
+var droppedHTMLContent = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+// htmlBlockTags lists the dropped elements that break an HTML block into
+// paragraphs
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"caption": true, "center": true, "dd": true, "details": true, "dialog": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "summary": true, "table": true, "tbody": true, "td": true,
+	"tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// allowedHTML keeps the allowed elements of raw HTML written in pieces,
+// such as the tags of inline HTML, balanced across the pieces
+type allowedHTML struct {
+	open     []string // allowed elements left open, innermost last
+	skipping string   // element whose content is being dropped
+	dropped  string   // the first element dropped
+}
+
+// write writes a piece of raw HTML to out as XHTML, keeping the elements
+// and attributes in the allow lists and the text of everything else. An end
+// tag closes its element and any left open inside it; end tags of elements
+// not open are dropped. blockBreak, if set, is called at the tags of block
+// elements.
+func (a *allowedHTML) write(out *bytes.Buffer, raw []byte, blockBreak func()) {
+	z := html.NewTokenizer(bytes.NewReader(raw))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return // io.EOF, as the input is in memory
+		}
+		tok := z.Token()
+		name := strings.ToLower(tok.Data)
+		if a.skipping != "" {
+			if tt == html.EndTagToken && name == a.skipping {
+				a.skipping = ""
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			out.Write(util.EscapeHTML([]byte(tok.Data)))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			void, ok := allowedHTMLTags[name]
+			if !ok {
+				if a.dropped == "" {
+					a.dropped = name
+				}
+				if droppedHTMLContent[name] && tt == html.StartTagToken {
+					a.skipping = name
+				} else if htmlBlockTags[name] && blockBreak != nil {
+					blockBreak()
+				}
+				continue
+			}
+			out.WriteString("<" + name)
+			for _, attr := range tok.Attr {
+				if !allowedHTMLAttrs[strings.ToLower(attr.Key)] {
+					continue
+				}
+				out.WriteString(" " + strings.ToLower(attr.Key) + `="`)
+				out.Write(util.EscapeHTML([]byte(attr.Val)))
+				out.WriteByte('"')
+			}
+			switch {
+			case void:
+				out.WriteString(" />")
+			case tt == html.SelfClosingTagToken:
+				out.WriteString("></" + name + ">")
+			default:
+				out.WriteByte('>')
+				a.open = append(a.open, name)
+			}
+		case html.EndTagToken:
+			if i := lastIndex(a.open, name); i >= 0 {
+				for len(a.open) > i {
+					a.closeLast(out)
+				}
+			} else if htmlBlockTags[name] && blockBreak != nil {
+				blockBreak()
+			}
+		}
+	}
+}
+
+// close writes the end tags of the elements left open
+func (a *allowedHTML) close(out *bytes.Buffer) {
+	for len(a.open) > 0 {
+		a.closeLast(out)
+	}
+}
+
+// closeLast writes the end tag of the innermost open element
+func (a *allowedHTML) closeLast(out *bytes.Buffer) {
+	out.WriteString("</" + a.open[len(a.open)-1] + ">")
+	a.open = a.open[:len(a.open)-1]
+}
+
+// lastIndex returns the index of the last name in names, or -1
+func lastIndex(names []string, name string) int {
+	for i := len(names) - 1; i >= 0; i-- {
+		if names[i] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeAllowedHTMLBlock writes an HTML block's allowed elements and text as
+// paragraphs, split where the block's own block elements were, with every
+// element closed. It returns the name of the first element dropped, if any.
+func writeAllowedHTMLBlock(w util.BufWriter, raw []byte) (dropped string) {
+	var a allowedHTML
+	var para bytes.Buffer
+	flush := func() {
+		a.close(&para)
+		if content := bytes.TrimSpace(para.Bytes()); len(content) > 0 {
+			_, _ = w.WriteString("<p>")    //nolint:errcheck
+			_, _ = w.Write(content)        //nolint:errcheck
+			_, _ = w.WriteString("</p>\n") //nolint:errcheck
+		}
+		para.Reset()
+	}
+	a.write(&para, raw, flush)
+	flush()
+	return a.dropped
+}
+
+// kindInlineHTML is the node kind for allowed inline HTML
+var kindInlineHTML = ast.NewNodeKind("InlineHTML")
+
+// inlineHTML is a piece of raw inline HTML reduced to its allowed elements,
+// or the end tags of elements its parent leaves open
+type inlineHTML struct {
+	ast.BaseInline
+	html    []byte
+	dropped string // the first element dropped
+	offset  int    // where the raw HTML starts in the source
+}
+
+func (n *inlineHTML) Kind() ast.NodeKind {
+	return kindInlineHTML
+}
+
+func (n *inlineHTML) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"HTML": string(n.html)}, nil)
+}
+
+// inlineHTMLTransformer replaces raw inline HTML with inlineHTML nodes when
+// Options.AllowHTML is set. Each tag of inline HTML is a node of its own, so
+// elements are balanced across the raw HTML of a parent, such as a
+// paragraph or emphasis, and those left open are closed after its last
+// child. Text inside script and style is dropped.
+type inlineHTMLTransformer struct {
+	enabled bool
+}
+
+func newInlineHTMLTransformer(enabled bool) parser.ASTTransformer {
+	return &inlineHTMLTransformer{enabled: enabled}
+}
+
+func (t *inlineHTMLTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	if !t.enabled {
+		return
+	}
+	var parents []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == ast.KindRawHTML {
+			if p := n.Parent(); len(parents) == 0 || parents[len(parents)-1] != p {
+				parents = append(parents, p)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, parent := range parents {
+		balanceInlineHTML(parent, reader.Source())
+	}
+}
+
+// balanceInlineHTML replaces the raw HTML among parent's children. A parent
+// met again after a nested inline has none left and is unchanged.
+func balanceInlineHTML(parent ast.Node, source []byte) {
+	var a allowedHTML
+	for child := parent.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		raw, ok := child.(*ast.RawHTML)
+		switch {
+		case ok:
+			var value []byte
+			for i := 0; i < raw.Segments.Len(); i++ {
+				segment := raw.Segments.At(i)
+				value = append(value, segment.Value(source)...)
+			}
+			var out bytes.Buffer
+			before := a.dropped
+			a.write(&out, value, nil)
+			n := &inlineHTML{html: out.Bytes(), offset: raw.Segments.At(0).Start}
+			if a.dropped != before {
+				n.dropped = a.dropped
+			}
+			parent.ReplaceChild(parent, child, n)
+		case a.skipping != "":
+			parent.RemoveChild(parent, child)
+		}
+		child = next
+	}
+	if len(a.open) > 0 {
+		var out bytes.Buffer
+		a.close(&out)
+		parent.AppendChild(parent, &inlineHTML{html: out.Bytes()})
+	}
+}
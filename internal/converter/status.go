@@ -0,0 +1,82 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// statusPrefix opens a status shortcode
+var statusPrefix = []byte("{status:")
+
+// statusColours maps shortcode colours to the status macro's colour names
+var statusColours = map[string]string{
+	"grey":   "Grey",
+	"gray":   "Grey",
+	"red":    "Red",
+	"yellow": "Yellow",
+	"green":  "Green",
+	"blue":   "Blue",
+	"purple": "Purple",
+}
+
+// kindStatus is the node kind for status lozenges
+var kindStatus = ast.NewNodeKind("Status")
+
+// status is a status lozenge written as {status:green|DONE}, or
+// {status:DONE} for the default grey. Colours are grey, red, yellow, green,
+// blue, and purple.
+type status struct {
+	ast.BaseInline
+	colour string
+	title  string
+}
+
+func (n *status) Kind() ast.NodeKind {
+	return kindStatus
+}
+
+func (n *status) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Colour": n.colour, "Title": n.title}, nil)
+}
+
+// statusParser parses {status:...} shortcodes into status nodes. A shortcode
+// with an unknown colour stays as text.
+type statusParser struct{}
+
+func newStatusParser() parser.InlineParser {
+	return &statusParser{}
+}
+
+func (p *statusParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *statusParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if len(line) < len(statusPrefix) || !bytes.EqualFold(line[:len(statusPrefix)], statusPrefix) {
+		return nil
+	}
+	end := bytes.IndexByte(line, '}')
+	if end < 0 {
+		return nil
+	}
+	content := string(line[len(statusPrefix):end])
+	node := &status{}
+	if colour, title, ok := strings.Cut(content, "|"); ok {
+		colour = strings.TrimSuffix(colour, `\`) // {status:green\|DONE} in a table cell
+		if node.colour, ok = statusColours[strings.ToLower(strings.TrimSpace(colour))]; !ok {
+			return nil
+		}
+		content = title
+	}
+	node.title = strings.TrimSpace(content)
+	if node.title == "" {
+		return nil
+	}
+	block.Advance(end + 1)
+	return node
+}
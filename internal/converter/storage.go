@@ -1,8 +1,10 @@
 package converter
 
 import (
+	"bytes"
 	"html"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
@@ -10,6 +12,7 @@ import (
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/strikethrough"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/table"
+	"github.com/yuin/goldmark/parser"
 )
 
 var storageConverter = converter.NewConverter(
@@ -39,42 +42,77 @@ var emptyCodeMacroRegex = regexp.MustCompile(
 var languageRegex = regexp.MustCompile(
 	`<ac:parameter[^>]*ac:name="language"[^>]*>([^<]*)</ac:parameter>`)
 
-// taskListRegex matches Confluence task list macro
-var taskListRegex = regexp.MustCompile(`<ac:task-list>([\s\S]*?)</ac:task-list>`)
+// taskListClose closes a Confluence task list
+const taskListClose = "</ac:task-list>"
 
-// taskRegex matches individual task items
-var taskRegex = regexp.MustCompile(
-	`<ac:task>\s*<ac:task-status>([^<]*)</ac:task-status>\s*<ac:task-body>([\s\S]*?)</ac:task-body>\s*</ac:task>`)
+// taskListOpenRegex matches the opening tag of a task list, which pages
+// saved by Confluence give an ac:task-list-id
+var taskListOpenRegex = regexp.MustCompile(`<ac:task-list(?:\s[^>]*)?>`)
+
+// taskRegex matches individual task items. Pages saved by Confluence give
+// each task an ac:task-id and ac:task-uuid before its status.
+var taskRegex = regexp.MustCompile(`<ac:task>([\s\S]*?)</ac:task>`)
+
+// taskStatusRegex matches the status of a task
+var taskStatusRegex = regexp.MustCompile(`<ac:task-status>([^<]*)</ac:task-status>`)
+
+// taskBodyRegex matches the body of a task
+var taskBodyRegex = regexp.MustCompile(`<ac:task-body>([\s\S]*?)</ac:task-body>`)
+
+// taskPlaceholderRegex matches the span Confluence wraps task text in
+var taskPlaceholderRegex = regexp.MustCompile(`<span class="placeholder-inline-tasks">([\s\S]*?)</span>`)
 
 // imageRegex matches Confluence image macro with external URL
 var imageRegex = regexp.MustCompile(
 	`<ac:image[^>]*>\s*<ri:url\s+ri:value="([^"]*)"[^/]*/>\s*</ac:image>`)
 
+// StorageToMarkdown converts Confluence storage format to Markdown with the
+// default options.
 func StorageToMarkdown(storage string) (string, error) {
+	return StorageToMarkdownWithOptions(storage, StorageOptions{})
+}
+
+// StorageToMarkdownWithOptions converts Confluence storage format to
+// Markdown. Like StorageToMarkdownStream, it converts the page one
+// top-level block at a time.
+func StorageToMarkdownWithOptions(storage string, opts StorageOptions) (string, error) {
+	var b strings.Builder
+	if err := StorageToMarkdownStream(&b, strings.NewReader(storage), opts); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// convertStorageBlock converts a run of top-level storage format content
+// found by splitStorage to Markdown. ids holds the heading IDs of the
+// page's earlier blocks.
+func convertStorageBlock(storage string, opts StorageOptions, ids parser.IDs) (string, error) {
+	// Pre-process: expand children macros into lists of links
+	processed := convertChildren(storage, opts)
+
+	// Pre-process: set aside macros with no Markdown equivalent, to be
+	// restored as confluence-macro fences once converted
+	processed, macros := hideUnknownMacros(processed)
+
+	// Fence info strings holding code macro attributes, restored once
+	// converted
+	var codeInfos []string
+
 	// Pre-process: convert Confluence code macros WITH content to standard HTML pre/code blocks
-	processed := codeMacroRegex.ReplaceAllStringFunc(storage, func(match string) string {
+	processed = codeMacroRegex.ReplaceAllStringFunc(processed, func(match string) string {
 		submatches := codeMacroRegex.FindStringSubmatch(match)
 		if len(submatches) < 3 {
 			return match
 		}
 		params := submatches[1]
-		code := submatches[2]
-
-		// Extract language from parameters
-		var language string
-		if langMatch := languageRegex.FindStringSubmatch(params); len(langMatch) >= 2 {
-			language = strings.TrimSpace(langMatch[1])
-		}
+		// Rejoin CDATA sections split around a "]]>" in the code
+		code := strings.ReplaceAll(submatches[2], "]]><![CDATA[", "")
 
 		// Escape HTML entities in code content (< and > must be escaped for HTML parsing)
 		code = strings.ReplaceAll(code, "<", "&lt;")
 		code = strings.ReplaceAll(code, ">", "&gt;")
 
-		// Build pre/code with optional language class
-		if language != "" {
-			return `<pre><code class="language-` + language + `">` + code + `</code></pre>`
-		}
-		return `<pre><code>` + code + `</code></pre>`
+		return codeBlockOpen(params, &codeInfos) + code + `</code></pre>`
 	})
 
 	// Pre-process: convert empty code macros (no content) to empty code blocks
@@ -83,56 +121,11 @@ func StorageToMarkdown(storage string) (string, error) {
 		if len(submatches) < 2 {
 			return match
 		}
-		params := submatches[1]
-
-		// Extract language from parameters
-		var language string
-		if langMatch := languageRegex.FindStringSubmatch(params); len(langMatch) >= 2 {
-			language = strings.TrimSpace(langMatch[1])
-		}
-
-		// Build empty pre/code with optional language class
-		if language != "" {
-			return `<pre><code class="language-` + language + `"></code></pre>`
-		}
-		return `<pre><code></code></pre>`
+		return codeBlockOpen(submatches[1], &codeInfos) + `</code></pre>`
 	})
 
 	// Pre-process: convert Confluence task lists to HTML checkboxes
-	processed = taskListRegex.ReplaceAllStringFunc(processed, func(match string) string {
-		// Extract task list content
-		listMatch := taskListRegex.FindStringSubmatch(match)
-		if len(listMatch) < 2 {
-			return match
-		}
-		taskListContent := listMatch[1]
-
-		// Convert each task to a list item with checkbox
-		var result strings.Builder
-		result.WriteString("<ul>\n")
-
-		tasks := taskRegex.FindAllStringSubmatch(taskListContent, -1)
-		for _, task := range tasks {
-			if len(task) < 3 {
-				continue
-			}
-			status := strings.TrimSpace(task[1])
-			body := strings.TrimSpace(task[2])
-
-			// Remove any paragraph tags from body
-			body = strings.TrimPrefix(body, "<p>")
-			body = strings.TrimSuffix(body, "</p>")
-			body = strings.TrimSpace(body)
-
-			if status == "complete" {
-				result.WriteString("<li>[x] " + body + "</li>\n")
-			} else {
-				result.WriteString("<li>[ ] " + body + "</li>\n")
-			}
-		}
-		result.WriteString("</ul>")
-		return result.String()
-	})
+	processed = convertTaskLists(processed)
 
 	// Pre-process: convert Confluence images to standard HTML img tags
 	processed = imageRegex.ReplaceAllStringFunc(processed, func(match string) string {
@@ -145,6 +138,53 @@ func StorageToMarkdown(storage string) (string, error) {
 		return `<img src="` + url + `" alt="" />`
 	})
 
+	// Pre-process: convert images of page attachments to img tags
+	processed = convertAttachmentImages(processed, opts.AttachmentDir)
+
+	// Pre-process: set aside anchors, other than heading anchors made again
+	// on publish, and point same-page anchor links at their fragment
+	processed, anchors := convertAnchors(processed, ids)
+	processed = convertAnchorLinks(processed)
+
+	// Pre-process: convert links to pages by title
+	processed = convertPageLinks(processed, opts)
+
+	// Pre-process: convert user mentions to @names
+	processed = convertMentions(processed, opts)
+
+	// Pre-process: convert table of contents macros to markers
+	processed = convertTOCs(processed, opts.RoundTrip)
+
+	// Pre-process: convert Jira issue macros to links
+	processed = convertJiraIssues(processed, opts)
+
+	// Pre-process: convert emoticons to emoji
+	processed = convertEmoticons(processed)
+
+	// Pre-process: convert panel macros to alerts or ::: admonitions
+	processed = convertPanels(processed, opts.Admonitions)
+
+	// Pre-process: convert expand macros to <details> sections
+	processed = convertExpands(processed)
+
+	// Pre-process: convert status macros to bold text or shortcodes
+	processed = convertStatuses(processed, opts.RoundTrip)
+
+	// Pre-process: convert dates to ISO dates or {date:...} shortcodes
+	processed = convertDates(processed, opts.RoundTrip)
+
+	// Pre-process: flatten page layouts, or convert them to :::columns
+	processed = convertLayouts(processed, opts.RoundTrip)
+
+	// Pre-process: set aside HTML for what Markdown cannot express, if kept
+	var kept []string
+	if opts.KeepHTML {
+		processed, kept = keepHTML(processed)
+	}
+
+	// Pre-process: keep only a leading row of th cells as the header row
+	processed = convertTableHeaders(processed)
+
 	markdown, err := storageConverter.ConvertString(processed)
 	if err != nil {
 		return "", err
@@ -163,6 +203,15 @@ func StorageToMarkdown(storage string) (string, error) {
 	// Pattern 2: \\X -> \X (double backslash: only backslash was escaped, char is literal)
 	markdown = fixOverEscaping(markdown)
 
+	// Fix escaped alert markers and admonition titles
+	markdown = alertMarkerRegex.ReplaceAllString(markdown, "${1}> [!$2]\n")
+	markdown = admonitionFenceRegex.ReplaceAllStringFunc(markdown, unescapeMarkdown)
+	markdown = summaryRegex.ReplaceAllStringFunc(markdown, unescapeMarkdown)
+	markdown = statusTextRegex.ReplaceAllString(markdown, "**[$1]**")
+	markdown = statusShortcodeRegex.ReplaceAllStringFunc(markdown, unescapeShortcode)
+	markdown = wikiLinkTextRegex.ReplaceAllStringFunc(markdown, unescapeShortcode)
+	markdown = tocMarkerRegex.ReplaceAllString(markdown, "[TOC]")
+
 	// Fix intra-word underscores globally (safe even in code blocks since pattern is specific)
 	// The pattern alphanumeric\_alphanumeric never needs escaping in Markdown
 	for intraWordUnderscoreRegex.MatchString(markdown) {
@@ -173,9 +222,129 @@ func StorageToMarkdown(storage string) (string, error) {
 	// The html-to-markdown library creates "loose" lists with blank lines before nested items
 	markdown = fixNestedListSpacing(markdown)
 
+	// Restore HTML kept for what Markdown cannot express
+	markdown = restoreHTML(markdown, kept)
+
+	// Restore anchors as HTML anchors or {anchor:name} shortcodes
+	markdown = restoreAnchors(markdown, anchors, opts.RoundTrip)
+
+	// Restore code macro attributes as fence {...} blocks
+	markdown = restoreCodeInfos(markdown, codeInfos)
+
+	// Restore macros with no Markdown equivalent as confluence-macro fences
+	markdown = restoreUnknownMacros(markdown, macros)
+
 	return markdown, nil
 }
 
+// convertTaskLists rewrites Confluence task lists as lists of "[ ]" and
+// "[x]" items, innermost first. A task list nested in another follows the
+// task it belongs to, so it is moved into that task's list item. Bullet
+// lists directly before or after a task list are joined with it, restoring
+// Markdown lists that mix tasks and plain items. It makes one pass over
+// storage, with each open task list collecting its content until it closes.
+func convertTaskLists(storage string) string {
+	if !strings.Contains(storage, taskListClose) {
+		return storage
+	}
+	type openList struct {
+		tag     string // opening tag, restored if the list is not closed
+		content bytes.Buffer
+	}
+	opens := taskListOpenRegex.FindAllStringIndex(storage, -1)
+	stack := []*openList{{}}
+	pos := 0
+	for {
+		end := indexFrom(storage, taskListClose, pos)
+		for len(opens) > 0 && (end < 0 || opens[0][0] < end) {
+			start, stop := opens[0][0], opens[0][1]
+			opens = opens[1:]
+			stack[len(stack)-1].content.WriteString(storage[pos:start])
+			stack = append(stack, &openList{tag: storage[start:stop]})
+			pos = stop
+		}
+		if end < 0 {
+			break
+		}
+		top := stack[len(stack)-1]
+		top.content.WriteString(storage[pos:end])
+		pos = end + len(taskListClose)
+		if len(stack) == 1 {
+			// An end tag with no task list open is kept
+			top.content.WriteString(taskListClose)
+			continue
+		}
+		stack = stack[:len(stack)-1]
+		parent := &stack[len(stack)-1].content
+
+		list := taskListToHTML(top.content.String())
+		if trimmed := bytes.TrimRight(parent.Bytes(), " \t\n"); bytes.HasSuffix(trimmed, []byte("</ul>")) {
+			parent.Truncate(len(trimmed) - len("</ul>"))
+			list = strings.TrimPrefix(list, "<ul>")
+		}
+		if trimmed := strings.TrimLeft(storage[pos:], " \t\n"); strings.HasPrefix(trimmed, "<ul>") {
+			pos = len(storage) - len(trimmed) + len("<ul>")
+			list = strings.TrimSuffix(list, "</ul>")
+		}
+		parent.WriteString(list)
+	}
+	stack[len(stack)-1].content.WriteString(storage[pos:])
+	for len(stack) > 1 {
+		unclosed := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stack[len(stack)-1].content.WriteString(unclosed.tag + unclosed.content.String())
+	}
+	return stack[0].content.String()
+}
+
+// taskListToHTML converts the content of one ac:task-list, whose nested
+// task lists are already converted, to a ul
+func taskListToHTML(content string) string {
+	var result strings.Builder
+	result.WriteString("<ul>\n")
+
+	open := false
+	pos := 0
+	for _, m := range taskRegex.FindAllStringSubmatchIndex(content, -1) {
+		// Anything between tasks is a nested list belonging to the previous one
+		if between := strings.TrimSpace(content[pos:m[0]]); between != "" && open {
+			result.WriteString(between)
+		}
+		if open {
+			result.WriteString("</li>\n")
+		}
+		task := content[m[2]:m[3]]
+		var status, body string
+		if sub := taskStatusRegex.FindStringSubmatch(task); sub != nil {
+			status = strings.TrimSpace(sub[1])
+		}
+		if sub := taskBodyRegex.FindStringSubmatch(task); sub != nil {
+			body = strings.TrimSpace(taskPlaceholderRegex.ReplaceAllString(sub[1], "$1"))
+		}
+
+		// Remove any paragraph tags from body
+		body = strings.TrimPrefix(body, "<p>")
+		body = strings.TrimSuffix(body, "</p>")
+		body = strings.TrimSpace(body)
+
+		if status == "complete" {
+			result.WriteString("<li>[x] " + body)
+		} else {
+			result.WriteString("<li>[ ] " + body)
+		}
+		open = true
+		pos = m[1]
+	}
+	if open {
+		if rest := strings.TrimSpace(content[pos:]); rest != "" {
+			result.WriteString(rest)
+		}
+		result.WriteString("</li>\n")
+	}
+	result.WriteString("</ul>")
+	return result.String()
+}
+
 // nestedListBlankLineRegex matches blank lines before nested list items
 // Pattern: newline, spaces, newline, spaces, list marker (- or digit.)
 var nestedListBlankLineRegex = regexp.MustCompile(`(\n)([ \t]+)\n([ \t]+[-*]|[ \t]+\d+\.)`)
@@ -262,3 +431,45 @@ func fixEscapesInText(text string) string {
 
 	return result
 }
+
+// codeBlockOpen returns the pre/code start tags for a code macro with the
+// given parameters, with the language as its class. A macro with attributes
+// a fence can hold, such as its title, takes a placeholder class instead,
+// and its fence info string is added to infos for restoreCodeInfos.
+func codeBlockOpen(params string, infos *[]string) string {
+	var language string
+	if langMatch := languageRegex.FindStringSubmatch(params); len(langMatch) >= 2 {
+		language = strings.TrimSpace(langMatch[1])
+	}
+	values := map[string]string{}
+	for _, match := range macroParamRegex.FindAllStringSubmatch(params, -1) {
+		values[match[1]] = html.UnescapeString(match[2])
+	}
+	if attrs := formatCodeAttrs(values); attrs != "" {
+		*infos = append(*infos, strings.TrimSpace(language+" "+attrs))
+		language = "ACONCODE" + strconv.Itoa(len(*infos)-1) + "X"
+	}
+	if language != "" {
+		return `<pre><code class="language-` + language + `">`
+	}
+	return `<pre><code>`
+}
+
+// codeInfoRegex matches a fence opening whose info string is a placeholder
+// left by codeBlockOpen
+var codeInfoRegex = regexp.MustCompile("(?m)(```+|~~~+)ACONCODE(\\d+)X$")
+
+// restoreCodeInfos puts back the fence info strings codeBlockOpen set aside
+func restoreCodeInfos(markdown string, infos []string) string {
+	if len(infos) == 0 {
+		return markdown
+	}
+	return codeInfoRegex.ReplaceAllStringFunc(markdown, func(match string) string {
+		m := codeInfoRegex.FindStringSubmatch(match)
+		i, err := strconv.Atoi(m[2])
+		if err != nil || i >= len(infos) {
+			return match
+		}
+		return m[1] + infos[i]
+	})
+}
@@ -1,7 +1,9 @@
 package converter
 
 import (
+	"errors"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -201,8 +203,8 @@ func TestStorageToMarkdown_CodeMacros(t *testing.T) {
 		{
 			name:     "multiple parameters with language",
 			input:    `<ac:structured-macro ac:name="code"><ac:parameter ac:name="title">Example</ac:parameter><ac:parameter ac:name="language">python</ac:parameter><ac:parameter ac:name="collapse">true</ac:parameter><ac:plain-text-body><![CDATA[print("hello")]]></ac:plain-text-body></ac:structured-macro>`,
-			contains: []string{"```python", `print("hello")`},
-			excludes: []string{"CDATA", "title", "collapse"},
+			contains: []string{"```python {title=\"Example\" collapse=true}", `print("hello")`},
+			excludes: []string{"CDATA"},
 		},
 		{
 			name:     "language parameter after other parameters",
@@ -300,8 +302,8 @@ func main() {
 }
 ]]></ac:plain-text-body>
 </ac:structured-macro>`,
-			contains: []string{"```go", "package main", `import "fmt"`, "func main()", `fmt.Println("Hello, World!")`},
-			excludes: []string{"CDATA", "Example Code", "macro-id"},
+			contains: []string{"```go {title=\"Example Code\"}", "package main", `import "fmt"`, "func main()", `fmt.Println("Hello, World!")`},
+			excludes: []string{"CDATA", "macro-id"},
 		},
 	}
 
@@ -640,140 +642,1234 @@ func TestStorageToMarkdown_EdgeCases(t *testing.T) {
 	}
 }
 
-func TestRoundTrip_ComprehensiveFile(t *testing.T) {
-	// Read the comprehensive test markdown file
-	mdContent, err := os.ReadFile("../../testdata/comprehensive-test.md")
-	if err != nil {
-		t.Skipf("Skipping: cannot read comprehensive-test.md: %v", err)
+func TestStorageToMarkdown_HeadingAnchors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "heading anchor dropped",
+			input: `<h2><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">getting-started</ac:parameter></ac:structured-macro>Getting Started</h2>`,
+			want:  "## Getting Started",
+		},
+		{
+			name:  "heading anchor differing from auto ID kept as attribute",
+			input: `<h2><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">setup_v2</ac:parameter></ac:structured-macro>Getting Started</h2>`,
+			want:  "## Getting Started {#setup_v2}",
+		},
+		{
+			name:  "heading id kept as attribute",
+			input: `<h3 id="custom">Heading &amp; more</h3>`,
+			want:  "### Heading & more {#custom}",
+		},
+		{
+			name:  "heading id matching auto ID dropped",
+			input: `<h3 id="heading--more">Heading &amp; more</h3>`,
+			want:  "### Heading & more",
+		},
+		{
+			name:  "duplicate heading anchors dropped",
+			input: `<h1>Intro</h1><h1><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">intro-1</ac:parameter></ac:structured-macro>Intro</h1>`,
+			want:  "# Intro\n\n# Intro",
+		},
+		{
+			name:  "heading anchor with spaces kept below heading",
+			input: `<h3><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">My Anchor</ac:parameter></ac:structured-macro>Spaced</h3>`,
+			want:  "### Spaced\n\n<a id=\"My Anchor\"></a>",
+		},
+		{
+			name:  "anchor macro becomes HTML anchor",
+			input: `<p>Text <ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">point_a</ac:parameter></ac:structured-macro>here</p>`,
+			want:  `Text <a id="point_a"></a>here`,
+		},
+		{
+			name:  "anchor without name dropped",
+			input: `<p>a <ac:structured-macro ac:name="anchor"><ac:parameter ac:name=""></ac:parameter></ac:structured-macro>b</p>`,
+			want:  "a b",
+		},
+		{
+			name:  "anchor link becomes fragment link",
+			input: `<p>See <ac:link ac:anchor="getting-started"><ac:link-body>the setup</ac:link-body></ac:link>.</p>`,
+			want:  "See [the setup](#getting-started).",
+		},
+		{
+			name:  "anchor link with plain text body",
+			input: `<p>Note<sup><ac:link ac:anchor="fn-1"><ac:plain-text-link-body><![CDATA[1 & <2>]]></ac:plain-text-link-body></ac:link></sup></p>`,
+			want:  "Note[1 & <2>](#fn-1)",
+		},
 	}
 
-	// Convert Markdown -> Storage
-	storage := MarkdownToStorage(string(mdContent))
-
-	// Convert Storage -> Markdown
-	result, err := StorageToMarkdown(storage)
-	if err != nil {
-		t.Fatalf("StorageToMarkdown() error = %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
 	}
+}
 
-	// Check that snake_case identifiers are not escaped
-	snakeCaseTests := []string{
-		"my_variable_name",
-		"user_account_settings",
-		"database_connection_pool",
-		"api_handler",
-		"config_manager",
-		"data_processor",
+func TestStorageToMarkdown_Panels(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{
+			name:  "info panel becomes note alert",
+			input: `<ac:structured-macro ac:name="info" ac:schema-version="1"><ac:rich-text-body><p>Read this.</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!NOTE]\n> Read this.",
+		},
+		{
+			name:  "note panel becomes caution alert",
+			input: `<ac:structured-macro ac:name="note"><ac:rich-text-body><p>Careful.</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!CAUTION]\n> Careful.",
+		},
+		{
+			name:  "plain panel becomes note alert",
+			input: `<ac:structured-macro ac:name="panel"><ac:rich-text-body><p>Boxed.</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!NOTE]\n> Boxed.",
+		},
+		{
+			name:  "title becomes bold first line",
+			input: `<ac:structured-macro ac:name="warning"><ac:parameter ac:name="title">Before you start</ac:parameter><ac:rich-text-body><p>Back up.</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!WARNING]\n> **Before you start**\n> \n> Back up.",
+		},
+		{
+			name:  "nested panel",
+			input: `<ac:structured-macro ac:name="warning"><ac:rich-text-body><p>outer</p><ac:structured-macro ac:name="tip"><ac:rich-text-body><p>inner</p></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!WARNING]\n> outer\n> \n> > [!TIP]\n> > inner",
+		},
+		{
+			name:  "code block in panel",
+			input: `<ac:structured-macro ac:name="tip"><ac:rich-text-body><ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[a < b]]></ac:plain-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!TIP]\n> ```\n> a < b\n> ```",
+		},
+		{
+			name:  "admonition with title",
+			input: `<ac:structured-macro ac:name="note"><ac:parameter ac:name="title">Heads [up] &amp; more</ac:parameter><ac:rich-text-body><p>Careful.</p></ac:rich-text-body></ac:structured-macro>`,
+			opts:  StorageOptions{Admonitions: true},
+			want:  ":::note Heads [up] & more\n\nCareful.\n\n:::",
+		},
+		{
+			name:  "nested admonitions use longer outer fence",
+			input: `<ac:structured-macro ac:name="info"><ac:rich-text-body><ac:structured-macro ac:name="tip"><ac:rich-text-body><p>inner</p></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			opts:  StorageOptions{Admonitions: true},
+			want:  "::::info\n\n:::tip\n\ninner\n\n:::\n\n::::",
+		},
+		{
+			name:  "other macros untouched",
+			input: `<p>text</p><ac:structured-macro ac:name="toc" /><ac:structured-macro ac:name="info"><ac:rich-text-body><p>i</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "text\n\n<!-- table of contents -->\n\n> [!NOTE]\n> i",
+		},
 	}
 
-	for _, identifier := range snakeCaseTests {
-		escaped := strings.ReplaceAll(identifier, "_", `\_`)
-		if strings.Contains(result, escaped) {
-			t.Errorf("Found escaped underscore in %q - should be %q", escaped, identifier)
-		}
-		if !strings.Contains(result, identifier) {
-			t.Errorf("Missing identifier %q in output", identifier)
-		}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
-// Benchmark tests for performance tracking
-
-var benchmarkMarkdown = `# Benchmark Document
-
-This is a paragraph with **bold**, *italic*, and ` + "`code`" + ` formatting.
-
-## Section One
-
-- Item one with some text
-- Item two with more text
-- Item three with even more text
-
-### Subsection
-
-1. First ordered item
-2. Second ordered item
-3. Third ordered item
-
-## Code Example
-
-Here is some inline ` + "`code`" + ` and a code block:
-
-` + "```go" + `
-package main
-
-import "fmt"
+func TestRoundTrip_Panels(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+	}{
+		{name: "alert", input: "> [!TIP]\n> Use the cache."},
+		{name: "caution alert", input: "> [!CAUTION]\n> Mind the gap."},
+		{name: "admonition", input: ":::warning Deprecated\n\nUse v2.\n\n:::", opts: StorageOptions{Admonitions: true}},
+	}
 
-func main() {
-    fmt.Println("Hello, World!")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(convert(t, tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.input {
+				t.Errorf("round trip = %q, want %q", got, tt.input)
+			}
+		})
+	}
 }
-` + "```" + `
 
-## Table
+func TestStorageToMarkdown_Expands(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "expand with title",
+			input: `<ac:structured-macro ac:name="expand" ac:schema-version="1"><ac:parameter ac:name="title">More *detail*</ac:parameter><ac:rich-text-body><p>Hidden <strong>text</strong>.</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "<details><summary>More *detail*</summary>\n\nHidden **text**.\n\n</details>",
+		},
+		{
+			name:  "expand without title",
+			input: `<ac:structured-macro ac:name="expand"><ac:rich-text-body><p>Hidden.</p></ac:rich-text-body></ac:structured-macro>`,
+			want:  "<details>\n\nHidden.\n\n</details>",
+		},
+		{
+			name:  "nested expand",
+			input: `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Outer</ac:parameter><ac:rich-text-body><ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Inner</ac:parameter><ac:rich-text-body><p>deep</p></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			want:  "<details><summary>Outer</summary>\n\n<details><summary>Inner</summary>\n\ndeep\n\n</details>\n\n</details>",
+		},
+		{
+			name:  "expand holding a panel",
+			input: `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Notes</ac:parameter><ac:rich-text-body><ac:structured-macro ac:name="tip"><ac:rich-text-body><p>hint</p></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			want:  "<details><summary>Notes</summary>\n\n> [!TIP]\n> hint\n\n</details>",
+		},
+	}
 
-| Column A | Column B | Column C |
-|----------|----------|----------|
-| Value 1  | Value 2  | Value 3  |
-| Value 4  | Value 5  | Value 6  |
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
 
-## Links and Images
+func TestRoundTrip_Expands(t *testing.T) {
+	input := "<details><summary>Show the log</summary>\n\nLine **one**\n\n- item\n\n</details>"
+	got, err := StorageToMarkdown(convert(t, input))
+	if err != nil {
+		t.Fatalf("StorageToMarkdown() error = %v", err)
+	}
+	if strings.TrimSpace(got) != input {
+		t.Errorf("round trip = %q, want %q", got, input)
+	}
+}
 
-Visit [Google](https://google.com) for more information.
+func TestStorageToMarkdown_Statuses(t *testing.T) {
+	green := `<ac:structured-macro ac:name="status" ac:schema-version="1"><ac:parameter ac:name="colour">Green</ac:parameter><ac:parameter ac:name="title">DONE</ac:parameter></ac:structured-macro>`
+	grey := `<ac:structured-macro ac:name="status"><ac:parameter ac:name="title">TO DO</ac:parameter></ac:structured-macro>`
 
-> This is a blockquote with some important information.
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "status as bold text", input: "<p>State: " + green + "</p>", want: "State: **[DONE]**"},
+		{name: "grey status as bold text", input: "<p>" + grey + "</p>", want: "**[TO DO]**"},
+		{
+			name:  "status without title dropped",
+			input: `<p>a <ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">Red</ac:parameter></ac:structured-macro>b</p>`,
+			want:  "a b",
+		},
+		{name: "round trip shortcode", input: "<p>State: " + green + "</p>", opts: StorageOptions{RoundTrip: true}, want: "State: {status:green|DONE}"},
+		{name: "round trip grey shortcode", input: "<p>" + grey + "</p>", opts: StorageOptions{RoundTrip: true}, want: "{status:TO DO}"},
+		{
+			name:  "round trip shortcode in table escapes pipe",
+			input: "<table><tbody><tr><th>State</th></tr><tr><td>" + green + "</td></tr></tbody></table>",
+			opts:  StorageOptions{RoundTrip: true},
+			want:  "| State                |\n|----------------------|\n| {status:green\\|DONE} |",
+		},
+	}
 
-The end.
-`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
 
-var benchmarkStorage = `<h1>Benchmark Document</h1>
-<p>This is a paragraph with <strong>bold</strong>, <em>italic</em>, and <code>code</code> formatting.</p>
-<h2>Section One</h2>
-<ul>
-<li>Item one with some text</li>
-<li>Item two with more text</li>
-<li>Item three with even more text</li>
-</ul>
-<h3>Subsection</h3>
-<ol>
-<li>First ordered item</li>
-<li>Second ordered item</li>
-<li>Third ordered item</li>
-</ol>
-<h2>Code Example</h2>
-<p>Here is some inline <code>code</code> and a code block:</p>
-<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[package main
+func TestRoundTrip_Statuses(t *testing.T) {
+	input := "Build {status:green|PASSED_ALL} and review {status:IN REVIEW}"
+	got, err := StorageToMarkdownWithOptions(convert(t, input), StorageOptions{RoundTrip: true})
+	if err != nil {
+		t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+	}
+	if strings.TrimSpace(got) != input {
+		t.Errorf("round trip = %q, want %q", got, input)
+	}
+}
 
-import "fmt"
+func TestStorageToMarkdown_Dates(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "date as ISO text", input: `<p>Meeting on <time datetime="2025-03-01" /> at noon</p>`, want: "Meeting on 2025-03-01 at noon"},
+		{name: "date with end tag", input: `<p><time datetime="2025-03-01"></time></p>`, want: "2025-03-01"},
+		{name: "round trip shortcode", input: `<p>Due <time datetime="2025-03-01" /></p>`, opts: StorageOptions{RoundTrip: true}, want: "Due {date:2025-03-01}"},
+		{name: "round trip invalid date as text", input: `<p><time datetime="2025-13-45" /></p>`, opts: StorageOptions{RoundTrip: true}, want: "2025-13-45"},
+		{name: "date without value dropped", input: `<p>a <time datetime="" />b</p>`, want: "a b"},
+		{
+			name:  "date in list item",
+			input: `<ul><li><time datetime="2025-03-01" /> kickoff</li></ul>`,
+			want:  "- 2025-03-01 kickoff",
+		},
+	}
 
-func main() {
-    fmt.Println("Hello, World!")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
-]]></ac:plain-text-body></ac:structured-macro>
-<h2>Table</h2>
-<table><thead><tr><th>Column A</th><th>Column B</th><th>Column C</th></tr></thead><tbody><tr><td>Value 1</td><td>Value 2</td><td>Value 3</td></tr><tr><td>Value 4</td><td>Value 5</td><td>Value 6</td></tr></tbody></table>
-<h2>Links and Images</h2>
-<p>Visit <a href="https://google.com">Google</a> for more information.</p>
-<blockquote><p>This is a blockquote with some important information.</p></blockquote>
-<p>The end.</p>`
 
-func BenchmarkMarkdownToStorage(b *testing.B) {
-	for b.Loop() {
-		MarkdownToStorage(benchmarkMarkdown)
+func TestRoundTrip_Dates(t *testing.T) {
+	input := "Kickoff {date:2025-03-01} and review {date:2025-04-15}"
+	got, err := StorageToMarkdownWithOptions(convert(t, input), StorageOptions{RoundTrip: true})
+	if err != nil {
+		t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+	}
+	if strings.TrimSpace(got) != input {
+		t.Errorf("round trip = %q, want %q", got, input)
 	}
 }
 
-func BenchmarkStorageToMarkdown(b *testing.B) {
-	for b.Loop() {
-		_, _ = StorageToMarkdown(benchmarkStorage) //nolint:errcheck
+func TestStorageToMarkdown_PageLinks(t *testing.T) {
+	resolve := func(ref PageRef) (string, error) {
+		if ref.Title == "Missing" {
+			return "", errors.New("page not found")
+		}
+		return "https://example.atlassian.net/wiki/spaces/DOCS/pages/42", nil
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{
+			name:  "unresolved link becomes wiki link",
+			input: `<p>See <ac:link><ri:page ri:content-title="Setup &amp; Install" /></ac:link>.</p>`,
+			want:  "See [[Setup & Install]].",
+		},
+		{
+			name:  "wiki link keeps space and text",
+			input: `<p><ac:link><ri:page ri:space-key="DOCS" ri:content-title="Style_Guide" /><ac:plain-text-link-body><![CDATA[the guide]]></ac:plain-text-link-body></ac:link></p>`,
+			want:  "[[DOCS:Style_Guide|the guide]]",
+		},
+		{
+			name:  "resolved link uses page URL",
+			input: `<p>See <ac:link><ri:page ri:content-title="Setup" /></ac:link>.</p>`,
+			opts:  StorageOptions{ResolvePage: resolve},
+			want:  "See [Setup](https://example.atlassian.net/wiki/spaces/DOCS/pages/42).",
+		},
+		{
+			name:  "resolved link keeps anchor and rich body",
+			input: `<p><ac:link ac:anchor="usage"><ri:page ri:content-title="Setup" /><ac:link-body><strong>Usage</strong></ac:link-body></ac:link></p>`,
+			opts:  StorageOptions{ResolvePage: resolve},
+			want:  "[**Usage**](https://example.atlassian.net/wiki/spaces/DOCS/pages/42#usage)",
+		},
+		{
+			name:  "resolve failure falls back to wiki link",
+			input: `<p><ac:link><ri:page ri:content-title="Missing" /></ac:link></p>`,
+			opts:  StorageOptions{ResolvePage: resolve},
+			want:  "[[Missing]]",
+		},
+		{
+			name:  "round trip keeps wiki link",
+			input: `<p><ac:link><ri:page ri:content-title="Setup" /></ac:link></p>`,
+			opts:  StorageOptions{ResolvePage: resolve, RoundTrip: true},
+			want:  "[[Setup]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
-func BenchmarkMarkdownToStorage_Large(b *testing.B) {
-	// Create a larger document by repeating the benchmark content
-	large := strings.Repeat(benchmarkMarkdown, 10)
-	b.ResetTimer()
-	for b.Loop() {
-		MarkdownToStorage(large)
+func TestRoundTrip_WikiLinks(t *testing.T) {
+	input := "Read [[Getting Started]] and [[DOCS:API Reference|the API docs]]."
+	got, err := StorageToMarkdown(convert(t, input))
+	if err != nil {
+		t.Fatalf("StorageToMarkdown() error = %v", err)
+	}
+	if strings.TrimSpace(got) != input {
+		t.Errorf("round trip = %q, want %q", got, input)
+	}
+}
+
+func TestStorageToMarkdown_Mentions(t *testing.T) {
+	mention := `<ac:link><ri:user ri:account-id="557058:abc" /></ac:link>`
+	resolve := func(accountID string) (string, error) {
+		if accountID == "557058:abc" {
+			return "Jane Doe", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "unresolved mention uses account ID", input: "<p>Ask " + mention + ".</p>", want: "Ask @557058:abc."},
+		{name: "resolved mention", input: "<p>Ask " + mention + ".</p>", opts: StorageOptions{ResolveUserName: resolve}, want: "Ask @Jane Doe."},
+		{name: "round trip mention", input: "<p>Ask " + mention + ".</p>", opts: StorageOptions{ResolveUserName: resolve, RoundTrip: true}, want: "Ask @{Jane Doe}."},
+		{
+			name:  "resolve failure uses account ID",
+			input: `<p><ac:link><ri:user ri:account-id="other" /></ac:link></p>`,
+			opts:  StorageOptions{ResolveUserName: resolve},
+			want:  "@other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_AttachmentImages(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "attachment image",
+			input: `<p><ac:image><ri:attachment ri:filename="diagram.png" /></ac:image></p>`,
+			want:  "![diagram](attachments/diagram.png)",
+		},
+		{
+			name:  "alt text and escaped filename",
+			input: `<p><ac:image ac:alt="Build chart" ac:width="400"><ri:attachment ri:filename="build chart.png" /></ac:image></p>`,
+			want:  "![Build chart](attachments/build%20chart.png)",
+		},
+		{
+			name:  "caption dropped",
+			input: `<p><ac:image><ri:attachment ri:filename="a.svg" /><ac:caption><p>Figure 1</p></ac:caption></ac:image></p>`,
+			want:  "![a](attachments/a.svg)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_AttachmentDir(t *testing.T) {
+	input := `<p><ac:image><ri:attachment ri:filename="diagram.png" /></ac:image></p>`
+	got, err := StorageToMarkdownWithOptions(input, StorageOptions{AttachmentDir: "attachments/setup"})
+	if err != nil {
+		t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+	}
+	if want := "![diagram](attachments/setup/diagram.png)"; strings.TrimSpace(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestImageAttachments(t *testing.T) {
+	storage := `<p><ac:image><ri:attachment ri:filename="a.png" /></ac:image></p>` +
+		`<p><ac:image><ri:attachment ri:filename="b.png"><ri:page ri:content-title="Other" /></ri:attachment></ac:image></p>` +
+		`<p><ac:image><ri:url ri:value="https://example.com/c.png" /></ac:image></p>` +
+		`<p><ac:image ac:width="50"><ri:attachment ri:filename="a.png" /></ac:image><ac:image><ri:attachment ri:filename="d.png" /></ac:image></p>`
+
+	got := ImageAttachments(storage)
+	want := []string{"a.png", "d.png"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ImageAttachments() = %q, want %q", got, want)
+	}
+}
+
+func TestStorageToMarkdown_Emoticons(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "emoticon name", input: `<p>Done <ac:emoticon ac:name="tick" /></p>`, want: "Done ✅"},
+		{name: "emoticon with variation selector", input: `<p><ac:emoticon ac:name="warning" /> careful</p>`, want: "⚠️ careful"},
+		{
+			name:  "emoji fallback character",
+			input: `<p><ac:emoticon ac:name="blue-star" ac:emoji-shortname=":rocket:" ac:emoji-id="1f680" ac:emoji-fallback="🚀" /> launch</p>`,
+			want:  "🚀 launch",
+		},
+		{
+			name:  "emoji ID with several code points",
+			input: `<p><ac:emoticon ac:name="blue-star" ac:emoji-id="1f44d-1f3fd" ac:emoji-fallback=":thumbsup::skin-tone-3:" /></p>`,
+			want:  "👍🏽",
+		},
+		{
+			name:  "custom emoji keeps shortcode",
+			input: `<p><ac:emoticon ac:name="blue-star" ac:emoji-id="a1b2c3-custom" ac:emoji-fallback=":partyparrot:" /></p>`,
+			want:  ":partyparrot:",
+		},
+		{name: "unknown emoticon dropped", input: `<p>a<ac:emoticon ac:name="unknown" />b</p>`, want: "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_JiraIssues(t *testing.T) {
+	issue := `<ac:structured-macro ac:name="jira" ac:schema-version="1"><ac:parameter ac:name="server">System Jira</ac:parameter><ac:parameter ac:name="key">PROJ-123</ac:parameter></ac:structured-macro>`
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "issue link", input: "<p>Fixed in " + issue + ".</p>", opts: StorageOptions{JiraURL: "https://example.atlassian.net/"}, want: "Fixed in [PROJ-123](https://example.atlassian.net/browse/PROJ-123)."},
+		{name: "bare key without Jira URL", input: "<p>Fixed in " + issue + ".</p>", want: "Fixed in PROJ-123."},
+		{name: "bare key in round trip mode", input: "<p>" + issue + "</p>", opts: StorageOptions{JiraURL: "https://example.atlassian.net", RoundTrip: true}, want: "PROJ-123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_TOC(t *testing.T) {
+	toc := `<ac:structured-macro ac:name="toc" ac:schema-version="1"><ac:parameter ac:name="maxLevel">3</ac:parameter></ac:structured-macro>`
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "toc becomes comment", input: toc + "<h1>Intro</h1>", want: "<!-- table of contents -->\n\n# Intro"},
+		{name: "toc becomes marker in round trip mode", input: toc + "<h1>Intro</h1>", opts: StorageOptions{RoundTrip: true}, want: "[TOC]\n\n# Intro"},
+		{
+			name:  "self-closing toc in paragraph",
+			input: `<h1>Intro</h1><p><ac:structured-macro ac:name="toc" /></p>`,
+			opts:  StorageOptions{RoundTrip: true},
+			want:  "# Intro\n\n[TOC]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_Layouts(t *testing.T) {
+	single := `<ac:layout-section ac:type="single"><ac:layout-cell><p>Intro</p></ac:layout-cell></ac:layout-section>`
+	sidebar := `<ac:layout-section ac:type="two_left_sidebar"><ac:layout-cell><p>Nav</p></ac:layout-cell><ac:layout-cell><p>Body</p></ac:layout-cell></ac:layout-section>`
+	info := `<ac:structured-macro ac:name="info"><ac:rich-text-body><p>hint</p></ac:rich-text-body></ac:structured-macro>`
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "columns flattened with rules", input: "<ac:layout>" + single + sidebar + "</ac:layout>", want: "Intro\n\nNav\n\n* * *\n\nBody"},
+		{
+			name:  "columns in round trip mode",
+			input: "<ac:layout>" + single + sidebar + "</ac:layout>",
+			opts:  StorageOptions{RoundTrip: true},
+			want:  "Intro\n\n::::columns left-sidebar\n\n:::column\n\nNav\n\n:::\n\n:::column\n\nBody\n\n:::\n\n::::",
+		},
+		{
+			name:  "equal columns in round trip mode",
+			input: `<ac:layout><ac:layout-section ac:type="three_equal"><ac:layout-cell><p>a</p></ac:layout-cell><ac:layout-cell><p>b</p></ac:layout-cell><ac:layout-cell><p>c</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			opts:  StorageOptions{RoundTrip: true},
+			want:  "::::columns\n\n:::column\n\na\n\n:::\n\n:::column\n\nb\n\n:::\n\n:::column\n\nc\n\n:::\n\n::::",
+		},
+		{
+			name:  "column fences longer than admonitions",
+			input: `<ac:layout><ac:layout-section ac:type="two_equal"><ac:layout-cell>` + info + `</ac:layout-cell><ac:layout-cell><p>b</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			opts:  StorageOptions{RoundTrip: true, Admonitions: true},
+			want:  ":::::columns\n\n::::column\n\n:::info\n\nhint\n\n:::\n\n::::\n\n::::column\n\nb\n\n::::\n\n:::::",
+		},
+		{
+			name:  "four columns flattened in round trip mode",
+			input: `<ac:layout><ac:layout-section ac:type="four_equal"><ac:layout-cell><p>a</p></ac:layout-cell><ac:layout-cell><p>b</p></ac:layout-cell><ac:layout-cell><p>c</p></ac:layout-cell><ac:layout-cell><p>d</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			opts:  StorageOptions{RoundTrip: true},
+			want:  "a\n\n* * *\n\nb\n\n* * *\n\nc\n\n* * *\n\nd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_Layouts(t *testing.T) {
+	input := "Intro\n\n::::columns right-sidebar\n\n:::column\n\nBody\n\n:::\n\n:::column\n\nLinks\n\n:::\n\n::::\n\nOutro"
+	got, err := StorageToMarkdownWithOptions(convert(t, input), StorageOptions{RoundTrip: true})
+	if err != nil {
+		t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+	}
+	if strings.TrimSpace(got) != input {
+		t.Errorf("round trip = %q, want %q", got, input)
+	}
+}
+
+func TestStorageToMarkdown_TableHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "header cells in tbody",
+			input: `<table><colgroup><col /></colgroup><tbody><tr><th><p>A</p></th><th><p>B</p></th></tr><tr><td><p>1</p></td><td><p>2</p></td></tr></tbody></table>`,
+			want:  "| A | B |\n|---|---|\n| 1 | 2 |",
+		},
+		{
+			name:  "header cells in later rows",
+			input: `<table><tbody><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr><tr><th>C</th><td>3</td></tr></tbody></table>`,
+			want:  "| A | B |\n|---|---|\n| 1 | 2 |\n| C | 3 |",
+		},
+		{
+			name:  "header column keeps row order",
+			input: `<table><tbody><tr><th>H1</th><td>1</td></tr><tr><th>H2</th><td>2</td></tr></tbody></table>`,
+			want:  "|    |   |\n|----|---|\n| H1 | 1 |\n| H2 | 2 |",
+		},
+		{
+			name:  "header row below the first is not moved up",
+			input: `<table><tbody><tr><td>t</td><td>u</td></tr><tr><th>A</th><th>B</th></tr></tbody></table>`,
+			want:  "|   |   |\n|---|---|\n| t | u |\n| A | B |",
+		},
+		{
+			name:  "thead kept",
+			input: `<table><thead><tr><th>A</th></tr></thead><tbody><tr><td>1</td></tr></tbody></table>`,
+			want:  "| A |\n|---|\n| 1 |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_KeepHTML(t *testing.T) {
+	merged := `<table><tbody><tr><th colspan="2">Head</th></tr><tr><td><strong>a</strong></td><td>b</td></tr></tbody></table>`
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{
+			name:  "merged cells kept as HTML table",
+			input: merged + "<p>after</p>",
+			opts:  StorageOptions{KeepHTML: true},
+			want:  merged + "\n\nafter",
+		},
+		{
+			name:  "table without merged cells stays Markdown",
+			input: `<table><tbody><tr><th>A</th></tr><tr><td>1</td></tr></tbody></table>`,
+			opts:  StorageOptions{KeepHTML: true},
+			want:  "| A |\n|---|\n| 1 |",
+		},
+		{
+			name:  "coloured text keeps colour styles only",
+			input: `<p>Some <span style="color: rgb(255,0,0);font-size: 12px">red</span> <span>plain</span> text</p>`,
+			opts:  StorageOptions{KeepHTML: true},
+			want:  `Some <span style="color: rgb(255,0,0)">red</span> plain text`,
+		},
+		{
+			name:  "nested spans and underline",
+			input: `<p><span style="background-color: yellow">lit <span>and</span> <u>under</u></span></p>`,
+			opts:  StorageOptions{KeepHTML: true},
+			want:  `<span style="background-color: yellow">lit and <u>under</u></span>`,
+		},
+		{
+			name:  "HTML dropped without option",
+			input: `<p><span style="color: red">red</span> <u>under</u></p>`,
+			want:  "red under",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_KeepHTMLTables(t *testing.T) {
+	merged := `<table><tbody><tr><th rowspan="2">Side</th><td>1</td></tr><tr><td>2</td></tr></tbody></table>`
+	md, err := StorageToMarkdownWithOptions(merged, StorageOptions{KeepHTML: true})
+	if err != nil {
+		t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+	}
+	if got := convert(t, md); !strings.Contains(got, merged) {
+		t.Errorf("round trip = %q, want %q", got, merged)
+	}
+}
+
+func TestStorageToMarkdown_Children(t *testing.T) {
+	tree := map[string][]ChildPage{
+		"":  {{ID: "2", Title: "Beta", URL: "https://x/2"}, {ID: "1", Title: "Alpha", URL: "https://x/1"}},
+		"1": {{ID: "3", Title: "Alpha One", URL: "https://x/3"}},
+	}
+	resolve := func(pageID string) ([]ChildPage, error) {
+		return tree[pageID], nil
+	}
+	failing := func(string) ([]ChildPage, error) { return nil, errors.New("not found") }
+	children := func(params string) string {
+		return `<ac:structured-macro ac:name="children">` + params + `</ac:structured-macro>`
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  StorageOptions
+		want  string
+	}{
+		{name: "children listed", input: children(""), opts: StorageOptions{ResolveChildren: resolve}, want: "- [Beta](https://x/2)\n- [Alpha](https://x/1)"},
+		{
+			name:  "descendants nested to depth",
+			input: children(`<ac:parameter ac:name="depth">2</ac:parameter>`),
+			opts:  StorageOptions{ResolveChildren: resolve},
+			want:  "- [Beta](https://x/2)\n- [Alpha](https://x/1)\n  - [Alpha One](https://x/3)",
+		},
+		{
+			name:  "sorted by title and reversed",
+			input: children(`<ac:parameter ac:name="sort">title</ac:parameter><ac:parameter ac:name="reverse">true</ac:parameter>`),
+			opts:  StorageOptions{ResolveChildren: resolve},
+			want:  "- [Beta](https://x/2)\n- [Alpha](https://x/1)",
+		},
+		{
+			name:  "first pages only",
+			input: children(`<ac:parameter ac:name="sort">title</ac:parameter><ac:parameter ac:name="first">1</ac:parameter>`),
+			opts:  StorageOptions{ResolveChildren: resolve},
+			want:  "- [Alpha](https://x/1)",
+		},
+		{name: "kept without resolver", input: children(""), want: "```confluence-macro\n<!-- Confluence macro: children -->\n" + children("") + "\n```"},
+		{
+			name:  "kept in round trip mode",
+			input: children(""),
+			opts:  StorageOptions{ResolveChildren: resolve, RoundTrip: true},
+			want:  "```confluence-macro\n<!-- Confluence macro: children -->\n" + children("") + "\n```",
+		},
+		{
+			name:  "kept when lookup fails",
+			input: children(""),
+			opts:  StorageOptions{ResolveChildren: failing},
+			want:  "```confluence-macro\n<!-- Confluence macro: children -->\n" + children("") + "\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdownWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_UnknownMacros(t *testing.T) {
+	chart := `<ac:structured-macro ac:name="chart"><ac:parameter ac:name="type">pie</ac:parameter><ac:rich-text-body><p>a &amp; b</p></ac:rich-text-body></ac:structured-macro>`
+	fence := "```confluence-macro\n<!-- Confluence macro: chart -->\n" + chart + "\n```"
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "macro kept in fence", input: "<p>Before</p>" + chart + "<p>After</p>", want: "Before\n\n" + fence + "\n\nAfter"},
+		{name: "macro in quote", input: "<blockquote>" + chart + "</blockquote>", want: "> " + strings.ReplaceAll(fence, "\n", "\n> ")},
+		{name: "macro in list item", input: "<ul><li>" + chart + "</li></ul>", want: "- " + strings.ReplaceAll(fence, "\n", "\n  ")},
+		{
+			name:  "macro in panel",
+			input: `<ac:structured-macro ac:name="info"><ac:rich-text-body>` + chart + `</ac:rich-text-body></ac:structured-macro>`,
+			want:  "> [!NOTE]\n> " + strings.ReplaceAll(fence, "\n", "\n> "),
+		},
+		{
+			name:  "fence longer than backticks in macro",
+			input: `<ac:structured-macro ac:name="html"><ac:plain-text-body><![CDATA[` + "```" + `]]></ac:plain-text-body></ac:structured-macro>`,
+			want:  "````confluence-macro\n<!-- Confluence macro: html -->\n<ac:structured-macro ac:name=\"html\"><ac:plain-text-body><![CDATA[```]]></ac:plain-text-body></ac:structured-macro>\n````",
+		},
+		{name: "macro inline with text leaves label", input: "<h2>Title " + chart + "</h2>", want: "## Title <!-- Confluence macro: chart -->"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdown_CodeAttributes(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		body   string
+		want   string
+	}{
+		{
+			name:   "all attributes",
+			params: `<ac:parameter ac:name="language">go</ac:parameter><ac:parameter ac:name="title">main.go</ac:parameter><ac:parameter ac:name="linenumbers">true</ac:parameter><ac:parameter ac:name="collapse">true</ac:parameter><ac:parameter ac:name="theme">Midnight</ac:parameter>`,
+			body:   "package main",
+			want:   "```go {title=\"main.go\" linenumbers=true collapse=true theme=Midnight}\npackage main\n```",
+		},
+		{
+			name:   "title without language",
+			params: `<ac:parameter ac:name="title">Say &quot;hi&quot;</ac:parameter>`,
+			body:   "echo hi",
+			want:   "```{title='Say \"hi\"'}\necho hi\n```",
+		},
+		{
+			name:   "false settings left out",
+			params: `<ac:parameter ac:name="language">go</ac:parameter><ac:parameter ac:name="linenumbers">false</ac:parameter><ac:parameter ac:name="collapse">false</ac:parameter>`,
+			body:   "x := 1",
+			want:   "```go\nx := 1\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := `<ac:structured-macro ac:name="code">` + tt.params + `<ac:plain-text-body><![CDATA[` + tt.body + `]]></ac:plain-text-body></ac:structured-macro>`
+			got, err := StorageToMarkdown(input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("StorageToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_CodeAttributes(t *testing.T) {
+	code := `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">bash</ac:parameter><ac:parameter ac:name="title">Install</ac:parameter><ac:parameter ac:name="linenumbers">true</ac:parameter><ac:plain-text-body><![CDATA[make install]]></ac:plain-text-body></ac:structured-macro>`
+	for _, storage := range []string{code, "<ul><li><p>Step</p>" + code + "</li></ul>"} {
+		md, err := StorageToMarkdown(storage)
+		if err != nil {
+			t.Fatalf("StorageToMarkdown() error = %v", err)
+		}
+		got := convert(t, md)
+		for _, want := range []string{
+			`<ac:parameter ac:name="language">bash</ac:parameter>`,
+			`<ac:parameter ac:name="title">Install</ac:parameter>`,
+			`<ac:parameter ac:name="linenumbers">true</ac:parameter>`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("round trip of %q = %q, want %q", md, got, want)
+			}
+		}
+	}
+}
+
+func TestRoundTrip_UnknownMacros(t *testing.T) {
+	chart := `<ac:structured-macro ac:name="chart"><ac:parameter ac:name="type">pie</ac:parameter><ac:rich-text-body><p>a &amp; b</p></ac:rich-text-body></ac:structured-macro>`
+	md, err := StorageToMarkdown("<p>Before</p>" + chart + "<p>After</p>")
+	if err != nil {
+		t.Fatalf("StorageToMarkdown() error = %v", err)
+	}
+	got := convert(t, md)
+	if !strings.Contains(got, "<p>Before</p>\n"+chart+"\n<p>After</p>") {
+		t.Errorf("round trip = %q, want macro %q restored", got, chart)
+	}
+	if strings.Contains(got, "Confluence macro:") {
+		t.Errorf("round trip = %q, want label removed", got)
+	}
+}
+
+func TestStorageToMarkdown_TaskLists(t *testing.T) {
+	task := func(id, status, body string) string {
+		return "<ac:task>\n<ac:task-id>" + id + "</ac:task-id>\n<ac:task-uuid>uuid-" + id + "</ac:task-uuid>\n" +
+			"<ac:task-status>" + status + "</ac:task-status>\n<ac:task-body>" + body + "</ac:task-body>\n</ac:task>\n"
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "tasks saved by Confluence",
+			input: `<ac:task-list ac:task-list-id="a1">` + "\n" + task("1", "incomplete", `<span class="placeholder-inline-tasks">Write <strong>docs</strong></span>`) + task("2", "complete", "<p>Review</p>") + "</ac:task-list>",
+			want:  "- [ ] Write **docs**\n- [x] Review",
+		},
+		{
+			name: "nested tasks saved by Confluence",
+			input: `<ac:task-list ac:task-list-id="a1">` + task("1", "incomplete", "Parent") +
+				`<ac:task-list ac:task-list-id="a2">` + task("2", "complete", "Child") + "</ac:task-list>" +
+				task("3", "incomplete", "Next") + "</ac:task-list>",
+			want: "- [ ] Parent\n  - [x] Child\n- [ ] Next",
+		},
+		{name: "task without body", input: "<ac:task-list>" + "<ac:task><ac:task-status>incomplete</ac:task-status></ac:task>" + "</ac:task-list>", want: "- [ ]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceMacros(t *testing.T) {
+	upper := func(m storageMacro) (string, bool) {
+		if m.name != "x" {
+			return "", false
+		}
+		return "[" + strings.ToUpper(m.body) + "]", true
+	}
+	x := func(body string) string {
+		return `<ac:structured-macro ac:name="x"><ac:rich-text-body>` + body + `</ac:rich-text-body></ac:structured-macro>`
+	}
+	other := `<ac:structured-macro ac:name="other"><ac:rich-text-body>` + x("b") + `</ac:rich-text-body></ac:structured-macro>`
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "no macros", input: "<p>a</p>", want: "<p>a</p>"},
+		{name: "innermost first", input: x("a" + x("b")), want: "[A[B]]"},
+		{name: "declined macro keeps replaced body", input: other, want: strings.Replace(other, x("b"), "[B]", 1)},
+		{name: "self-closing macro", input: `a<ac:structured-macro ac:name="x" />b`, want: "a[]b"},
+		{name: "unclosed macro kept", input: `<ac:structured-macro ac:name="x">` + x("b"), want: `<ac:structured-macro ac:name="x">[B]`},
+		{name: "stray end tag kept", input: "a</ac:structured-macro>" + x("b"), want: "a</ac:structured-macro>[B]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceMacros(tt.input, upper); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertTaskLists_Unbalanced(t *testing.T) {
+	task := "<ac:task><ac:task-status>complete</ac:task-status><ac:task-body>x</ac:task-body></ac:task>"
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unclosed list kept", input: "<ac:task-list>" + "<ac:task-list>" + task + "</ac:task-list>", want: "<ac:task-list><ul>\n<li>[x] x</li>\n</ul>"},
+		{name: "stray end tag kept", input: "</ac:task-list><ac:task-list>" + task + "</ac:task-list>", want: "</ac:task-list><ul>\n<li>[x] x</li>\n</ul>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertTaskLists(tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_TaskLists(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "nested tasks", input: "- [ ] parent\n  - [x] child\n  - [ ] other\n- [ ] next"},
+		{name: "mixed tasks and plain items", input: "- [ ] task\n- plain\n- [x] done"},
+		{name: "tasks under a plain item", input: "- plain\n  - [ ] task"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(convert(t, tt.input))
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.input {
+				t.Errorf("round trip = %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_CodeWithCDATATerminator(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "fenced", input: "```xml\n<![CDATA[x]]>\n```"},
+		{name: "repeated", input: "```xml\na]]>b]]>]]>\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StorageToMarkdown(convert(t, tt.input))
+			if err != nil {
+				t.Fatalf("StorageToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(got) != tt.input {
+				t.Errorf("round trip = %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_HeadingAnchors(t *testing.T) {
+	for _, input := range []string{
+		"# Intro\n\nSee [the intro](#intro).",
+		"## Setup {#setup_v2}\n\nJump {anchor:step_2}here, then [back](#setup_v2).",
+	} {
+		got, err := StorageToMarkdownWithOptions(convert(t, input), StorageOptions{RoundTrip: true})
+		if err != nil {
+			t.Fatalf("StorageToMarkdownWithOptions() error = %v", err)
+		}
+		if strings.TrimSpace(got) != input {
+			t.Errorf("round trip = %q, want %q", got, input)
+		}
+	}
+}
+
+func TestRoundTrip_ComprehensiveFile(t *testing.T) {
+	// Read the comprehensive test markdown file
+	mdContent, err := os.ReadFile("../../testdata/comprehensive-test.md")
+	if err != nil {
+		t.Skipf("Skipping: cannot read comprehensive-test.md: %v", err)
+	}
+
+	// Convert Markdown -> Storage
+	storage := convert(t, string(mdContent))
+
+	// Convert Storage -> Markdown
+	result, err := StorageToMarkdown(storage)
+	if err != nil {
+		t.Fatalf("StorageToMarkdown() error = %v", err)
+	}
+
+	// Check that snake_case identifiers are not escaped
+	snakeCaseTests := []string{
+		"my_variable_name",
+		"user_account_settings",
+		"database_connection_pool",
+		"api_handler",
+		"config_manager",
+		"data_processor",
+	}
+
+	for _, identifier := range snakeCaseTests {
+		escaped := strings.ReplaceAll(identifier, "_", `\_`)
+		if strings.Contains(result, escaped) {
+			t.Errorf("Found escaped underscore in %q - should be %q", escaped, identifier)
+		}
+		if !strings.Contains(result, identifier) {
+			t.Errorf("Missing identifier %q in output", identifier)
+		}
+	}
+}
+
+// Benchmark tests for performance tracking
+
+var benchmarkMarkdown = `# Benchmark Document
+
+This is a paragraph with **bold**, *italic*, and ` + "`code`" + ` formatting.
+
+## Section One
+
+- Item one with some text
+- Item two with more text
+- Item three with even more text
+
+### Subsection
+
+1. First ordered item
+2. Second ordered item
+3. Third ordered item
+
+## Code Example
+
+Here is some inline ` + "`code`" + ` and a code block:
+
+` + "```go" + `
+package main
+
+import "fmt"
+
+func main() {
+    fmt.Println("Hello, World!")
+}
+` + "```" + `
+
+## Table
+
+| Column A | Column B | Column C |
+|----------|----------|----------|
+| Value 1  | Value 2  | Value 3  |
+| Value 4  | Value 5  | Value 6  |
+
+## Links and Images
+
+Visit [Google](https://google.com) for more information.
+
+> This is a blockquote with some important information.
+
+The end.
+`
+
+var benchmarkStorage = `<h1>Benchmark Document</h1>
+<p>This is a paragraph with <strong>bold</strong>, <em>italic</em>, and <code>code</code> formatting.</p>
+<h2>Section One</h2>
+<ul>
+<li>Item one with some text</li>
+<li>Item two with more text</li>
+<li>Item three with even more text</li>
+</ul>
+<h3>Subsection</h3>
+<ol>
+<li>First ordered item</li>
+<li>Second ordered item</li>
+<li>Third ordered item</li>
+</ol>
+<h2>Code Example</h2>
+<p>Here is some inline <code>code</code> and a code block:</p>
+<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[package main
+
+import "fmt"
+
+func main() {
+    fmt.Println("Hello, World!")
+}
+]]></ac:plain-text-body></ac:structured-macro>
+<h2>Table</h2>
+<table><thead><tr><th>Column A</th><th>Column B</th><th>Column C</th></tr></thead><tbody><tr><td>Value 1</td><td>Value 2</td><td>Value 3</td></tr><tr><td>Value 4</td><td>Value 5</td><td>Value 6</td></tr></tbody></table>
+<h2>Links and Images</h2>
+<p>Visit <a href="https://google.com">Google</a> for more information.</p>
+<blockquote><p>This is a blockquote with some important information.</p></blockquote>
+<p>The end.</p>`
+
+func BenchmarkMarkdownToStorage(b *testing.B) {
+	for b.Loop() {
+		convert(b, benchmarkMarkdown)
+	}
+}
+
+func BenchmarkConverter(b *testing.B) {
+	c := NewConverter(Options{})
+	for b.Loop() {
+		if _, err := c.Convert(benchmarkMarkdown); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStorageToMarkdown(b *testing.B) {
+	for b.Loop() {
+		_, _ = StorageToMarkdown(benchmarkStorage) //nolint:errcheck
+	}
+}
+
+func BenchmarkMarkdownToStorage_Large(b *testing.B) {
+	// Create a larger document by repeating the benchmark content
+	large := strings.Repeat(benchmarkMarkdown, 10)
+	b.ResetTimer()
+	for b.Loop() {
+		convert(b, large)
 	}
 }
 
@@ -785,3 +1881,17 @@ func BenchmarkStorageToMarkdown_Large(b *testing.B) {
 		_, _ = StorageToMarkdown(large) //nolint:errcheck
 	}
 }
+
+func BenchmarkStorageToMarkdown_Macros(b *testing.B) {
+	// Macros and task lists throughout a large page, which each conversion
+	// pass must find
+	macros := `<ac:structured-macro ac:name="info"><ac:rich-text-body><p>i</p></ac:rich-text-body></ac:structured-macro>` +
+		`<ac:structured-macro ac:name="status"><ac:parameter ac:name="title">OK</ac:parameter></ac:structured-macro>` +
+		`<ac:task-list><ac:task><ac:task-status>complete</ac:task-status><ac:task-body>x</ac:task-body></ac:task></ac:task-list>` +
+		`<ac:structured-macro ac:name="chart"></ac:structured-macro>`
+	large := strings.Repeat(benchmarkStorage+macros, 1000)
+	b.ResetTimer()
+	for b.Loop() {
+		_, _ = StorageToMarkdown(large) //nolint:errcheck
+	}
+}
@@ -0,0 +1,140 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+)
+
+// headingRegex matches a heading, capturing its attributes and content
+var headingRegex = regexp.MustCompile(`<h([1-6])\b([^>]*)>([\s\S]*?)</h[1-6]>`)
+
+// headingIDRegex extracts the id attribute of a heading
+var headingIDRegex = regexp.MustCompile(`\sid="([^"]*)"`)
+
+// leadingAnchorRegex matches the anchor macro leading a heading's content
+var leadingAnchorRegex = regexp.MustCompile(`^\s*<ac:structured-macro[^>]*ac:name="anchor"[\s\S]*?</ac:structured-macro>`)
+
+// anchorTokenRegex matches a token left by convertAnchors
+var anchorTokenRegex = regexp.MustCompile(` ?ACONANCHOR(\d+)X`)
+
+// anchorLinkRegex matches a link to an anchor on the same page, with a
+// rich or plain text link body
+var anchorLinkRegex = regexp.MustCompile(
+	`<ac:link\s+ac:anchor="([^"]*)"\s*>\s*` +
+		`(<ac:link-body>[\s\S]*?</ac:link-body>|<ac:plain-text-link-body>\s*<!\[CDATA\[[\s\S]*?\]\]>\s*</ac:plain-text-link-body>)` +
+		`\s*</ac:link>`)
+
+// storageAnchor is an anchor set aside by convertAnchors
+type storageAnchor struct {
+	name    string
+	heading bool // written as the heading's {#id} attribute
+}
+
+// convertAnchors replaces anchor macros with tokens, returning the anchors
+// in token order for restoreAnchors. A heading's anchor, or its id
+// attribute, is only kept if it differs from the auto ID MarkdownToStorage
+// gives the heading, as the anchor is otherwise made again on publish.
+// Anchor macros with no name are dropped. The auto IDs are made with ids,
+// shared by every block of the page so repeated headings number on.
+func convertAnchors(storage string, ids parser.IDs) (string, []storageAnchor) {
+	var anchors []storageAnchor
+	token := func(a storageAnchor) string {
+		anchors = append(anchors, a)
+		return "ACONANCHOR" + strconv.Itoa(len(anchors)-1) + "X"
+	}
+
+	storage = headingRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		m := headingRegex.FindStringSubmatch(match)
+		attrs, content := m[2], m[3]
+		var name string
+		if id := headingIDRegex.FindStringSubmatch(attrs); id != nil {
+			name = strings.TrimSpace(html.UnescapeString(id[1]))
+			attrs = strings.Replace(attrs, id[0], "", 1)
+		}
+		if macro := leadingAnchorRegex.FindString(content); macro != "" {
+			content = content[len(macro):]
+			if anchor := anchorName(macro); anchor != "" {
+				name = anchor
+			}
+		}
+		text := strings.TrimSpace(html.UnescapeString(tagRegex.ReplaceAllString(content, "")))
+		auto := string(ids.Generate([]byte(text), ast.KindHeading))
+		var after string
+		switch {
+		case name == "" || name == auto:
+		case strings.ContainsFunc(name, unicode.IsSpace) || strings.ContainsAny(name, "{}"):
+			// Not a valid {#id}, and text in the heading would change its
+			// auto ID, so kept as an anchor just below it
+			after = "<p>" + token(storageAnchor{name: name}) + "</p>"
+		default:
+			content += " " + token(storageAnchor{name: name, heading: true})
+		}
+		return "<h" + m[1] + attrs + ">" + content + "</h" + m[1] + ">" + after
+	})
+
+	storage = replaceMacros(storage, func(m storageMacro) (string, bool) {
+		if m.name != "anchor" {
+			return "", false
+		}
+		name := strings.TrimSpace(m.params[""])
+		if name == "" {
+			return "", true
+		}
+		return token(storageAnchor{name: name}), true
+	})
+	return storage, anchors
+}
+
+// anchorName returns the name of an anchor macro
+func anchorName(macro string) string {
+	if m := macroParamRegex.FindStringSubmatch(macro); m != nil && m[1] == "" {
+		return strings.TrimSpace(html.UnescapeString(m[2]))
+	}
+	return ""
+}
+
+// restoreAnchors puts back the anchors convertAnchors set aside. Heading
+// IDs become {#id} attributes. Other anchors become HTML anchors for
+// Markdown viewers, or in round-trip mode {anchor:name} shortcodes that
+// publish back as anchor macros.
+func restoreAnchors(markdown string, anchors []storageAnchor, roundTrip bool) string {
+	if len(anchors) == 0 {
+		return markdown
+	}
+	return anchorTokenRegex.ReplaceAllStringFunc(markdown, func(match string) string {
+		i, err := strconv.Atoi(anchorTokenRegex.FindStringSubmatch(match)[1])
+		if err != nil || i >= len(anchors) {
+			return match
+		}
+		a := anchors[i]
+		space := match[:len(match)-len(strings.TrimLeft(match, " "))]
+		switch {
+		case a.heading:
+			return " {#" + a.name + "}"
+		case roundTrip:
+			return space + "{anchor:" + a.name + "}"
+		}
+		return space + `<a id="` + html.EscapeString(a.name) + `"></a>`
+	})
+}
+
+// convertAnchorLinks rewrites links to anchors on the same page as links to
+// their fragment. Footnotes publish with plain text link bodies.
+func convertAnchorLinks(storage string) string {
+	return anchorLinkRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		m := anchorLinkRegex.FindStringSubmatch(match)
+		body := m[2]
+		if sub := plainLinkBodyRegex.FindStringSubmatch(body); sub != nil {
+			body = html.EscapeString(strings.ReplaceAll(sub[1], "]]><![CDATA[", ""))
+		} else if sub := richLinkBodyRegex.FindStringSubmatch(body); sub != nil {
+			body = sub[1]
+		}
+		return `<a href="#` + m[1] + `">` + body + `</a>`
+	})
+}
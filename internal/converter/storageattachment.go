@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AttachmentDir is the directory, relative to the Markdown, that images
+// attached to a page are linked from by StorageToMarkdown
+const AttachmentDir = "attachments"
+
+// attachmentImageRegex matches an image of a page attachment, with the
+// image's attributes and the attachment's
+var attachmentImageRegex = regexp.MustCompile(
+	`<ac:image([^>]*)>\s*<ri:attachment([^>]*?)(?:/>|>[\s\S]*?</ri:attachment>)\s*` +
+		`(?:<ac:caption>[\s\S]*?</ac:caption>\s*)?</ac:image>`)
+
+// convertAttachmentImages rewrites images of attachments as img tags
+// linking to the file in dir, or AttachmentDir if dir is "". The alt text
+// defaults to the filename without its extension.
+func convertAttachmentImages(storage, dir string) string {
+	if dir == "" {
+		dir = AttachmentDir
+	}
+	return attachmentImageRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		m := attachmentImageRegex.FindStringSubmatch(match)
+		image, attachment := linkAttrs(m[1]), linkAttrs(m[2])
+		filename := attachment["ri:filename"]
+		if filename == "" {
+			return match
+		}
+		alt := image["ac:alt"]
+		if alt == "" {
+			alt = strings.TrimSuffix(filename, path.Ext(filename))
+		}
+		src := dir + "/" + url.PathEscape(filename)
+		return `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `" />`
+	})
+}
+
+// ImageAttachments returns the filenames of the page's own attachments
+// shown as images in storage, each once, in the order they first appear.
+// Attachments of other pages are left out.
+func ImageAttachments(storage string) []string {
+	var filenames []string
+	seen := map[string]bool{}
+	for _, m := range attachmentImageRegex.FindAllStringSubmatch(storage, -1) {
+		if strings.Contains(m[0], "<ri:page") || strings.Contains(m[0], "<ri:blog-post") {
+			continue
+		}
+		filename := linkAttrs(m[2])["ri:filename"]
+		if filename != "" && !seen[filename] {
+			seen[filename] = true
+			filenames = append(filenames, filename)
+		}
+	}
+	return filenames
+}
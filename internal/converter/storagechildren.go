@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// childrenMaxDepth limits the levels listed for a children macro showing
+// all descendants
+const childrenMaxDepth = 10
+
+// convertChildren rewrites children macros listing the converted page's
+// children as nested lists of links to them, looked up through
+// opts.ResolveChildren. The depth, all, sort=title, reverse, and first
+// parameters are honoured. Macros listing another page's children, or
+// whose children cannot be looked up, are kept.
+func convertChildren(storage string, opts StorageOptions) string {
+	if opts.ResolveChildren == nil || opts.RoundTrip {
+		return storage
+	}
+	return replaceMacros(storage, func(m storageMacro) (string, bool) {
+		if m.name != "children" || strings.TrimSpace(m.params["page"]) != "" {
+			return "", false
+		}
+		depth := 1
+		if n, err := strconv.Atoi(strings.TrimSpace(m.params["depth"])); err == nil && n > 0 {
+			depth = min(n, childrenMaxDepth)
+		}
+		if strings.TrimSpace(m.params["all"]) == "true" {
+			depth = childrenMaxDepth
+		}
+		first, _ := strconv.Atoi(strings.TrimSpace(m.params["first"]))
+		list, err := childrenList(opts.ResolveChildren, "", depth, m.params, first)
+		if err != nil {
+			return "", false
+		}
+		return list, true
+	})
+}
+
+// childrenList returns the children of pageID as a ul of links, with their
+// own children nested below depth levels deep. Only the first pages are
+// listed if first is positive.
+func childrenList(resolve ChildrenResolver, pageID string, depth int, params map[string]string, first int) (string, error) {
+	pages, err := resolve(pageID)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) == 0 {
+		return "", nil
+	}
+	if strings.TrimSpace(params["sort"]) == "title" {
+		pages = slices.Clone(pages)
+		slices.SortStableFunc(pages, func(a, b ChildPage) int {
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		})
+	}
+	if strings.TrimSpace(params["reverse"]) == "true" {
+		pages = slices.Clone(pages)
+		slices.Reverse(pages)
+	}
+	if first > 0 && len(pages) > first {
+		pages = pages[:first]
+	}
+
+	var b strings.Builder
+	b.WriteString("<ul>")
+	for _, page := range pages {
+		b.WriteString(`<li><a href="` + html.EscapeString(page.URL) + `">` + html.EscapeString(page.Title) + "</a>")
+		if depth > 1 && page.ID != "" {
+			nested, err := childrenList(resolve, page.ID, depth-1, params, 0)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(nested)
+		}
+		b.WriteString("</li>")
+	}
+	b.WriteString("</ul>")
+	return b.String(), nil
+}
@@ -0,0 +1,24 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// timeRegex matches a time element, which Confluence shows as a date
+// lozenge, capturing its datetime attribute
+var timeRegex = regexp.MustCompile(`<time\b[^>]*?\bdatetime="([^"]*)"[^>]*?(?:/>|>[\s\S]*?</time>)`)
+
+// convertDates rewrites time elements as their ISO date, or in round-trip
+// mode as {date:...} shortcodes. A date that is not a valid ISO date is
+// written as it is, and one with no value is dropped.
+func convertDates(storage string, roundTrip bool) string {
+	return timeRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		value := strings.TrimSpace(html.UnescapeString(timeRegex.FindStringSubmatch(match)[1]))
+		if roundTrip && validDate(value) {
+			return "{date:" + value + "}"
+		}
+		return html.EscapeString(value)
+	})
+}
@@ -0,0 +1,27 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// summaryRegex matches the summary of a <details> section written by
+// convertExpands
+var summaryRegex = regexp.MustCompile(`(?m)<details><summary>.*</summary>$`)
+
+// convertExpands rewrites expand macros as paragraphs holding the opening
+// and closing lines of a <details> section, which html-to-markdown writes
+// as text, so the macro body between them is still converted to Markdown.
+func convertExpands(storage string) string {
+	return replaceMacros(storage, func(m storageMacro) (string, bool) {
+		if m.name != "expand" {
+			return "", false
+		}
+		open := "<details>"
+		if title := strings.TrimSpace(m.params["title"]); title != "" {
+			open += "<summary>" + title + "</summary>"
+		}
+		return "<p>" + html.EscapeString(open) + "</p>" + m.body + "<p>" + html.EscapeString("</details>") + "</p>", true
+	})
+}
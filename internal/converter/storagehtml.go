@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// spannedCellRegex matches a table cell that merges two or more columns or
+// rows
+var spannedCellRegex = regexp.MustCompile(`<t[hd]\b[^>]*\s(?:col|row)span="0*[2-9]\d*"`)
+
+// inlineHTMLTagRegex matches the start or end tag of an element that may
+// carry formatting Markdown has no syntax for
+var inlineHTMLTagRegex = regexp.MustCompile(`<(/?)(span|u)\b([^>]*)>`)
+
+// styleAttrRegex extracts the style attribute of a tag
+var styleAttrRegex = regexp.MustCompile(`\sstyle="([^"]*)"`)
+
+// keptStyles lists the CSS properties kept on spans, which Confluence uses
+// for text and highlight colours
+var keptStyles = map[string]bool{
+	"color":            true,
+	"background-color": true,
+}
+
+// htmlTokenRegex matches a token left by keepHTML
+var htmlTokenRegex = regexp.MustCompile(`ACONHTML(\d+)X`)
+
+// keepHTML replaces constructs Markdown cannot express with tokens,
+// returning their HTML in token order for restoreHTML. Tables that merge
+// cells are kept whole, written as the HTML tables MarkdownToStorage reads,
+// and coloured and underlined text keeps its span and u tags.
+func keepHTML(storage string) (string, []string) {
+	var kept []string
+	token := func(h string) string {
+		kept = append(kept, h)
+		return "ACONHTML" + strconv.Itoa(len(kept)-1) + "X"
+	}
+
+	storage = tableRegex.ReplaceAllStringFunc(storage, func(table string) string {
+		if strings.Contains(table[1:], "<table") || !spannedCellRegex.MatchString(table) {
+			return table
+		}
+		var b strings.Builder
+		w := bufio.NewWriter(&b)
+		writeHTMLTable(w, []byte(table))
+		_ = w.Flush()
+		return "<p>" + token(strings.TrimSpace(b.String())) + "</p>"
+	})
+
+	// Each end tag closes the innermost open element of its name, and is
+	// kept only if that start tag was
+	open := map[string][]bool{}
+	return inlineHTMLTagRegex.ReplaceAllStringFunc(storage, func(tag string) string {
+		m := inlineHTMLTagRegex.FindStringSubmatch(tag)
+		name := m[2]
+		if m[1] == "/" {
+			stack := open[name]
+			if len(stack) == 0 {
+				return tag
+			}
+			keep := stack[len(stack)-1]
+			open[name] = stack[:len(stack)-1]
+			if keep {
+				return token("</" + name + ">")
+			}
+			return tag
+		}
+		if strings.HasSuffix(m[3], "/") {
+			return tag
+		}
+		start := "<u>"
+		if name == "span" {
+			start = ""
+			if style := keptStyle(m[3]); style != "" {
+				start = `<span style="` + style + `">`
+			}
+		}
+		open[name] = append(open[name], start != "")
+		if start == "" {
+			return tag
+		}
+		return token(start)
+	}), kept
+}
+
+// keptStyle returns the declarations of a tag's style attribute listed in
+// keptStyles, or ""
+func keptStyle(attrs string) string {
+	m := styleAttrRegex.FindStringSubmatch(attrs)
+	if m == nil {
+		return ""
+	}
+	var kept []string
+	for _, decl := range strings.Split(m[1], ";") {
+		property, value, ok := strings.Cut(decl, ":")
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.TrimSpace(value)
+		if ok && keptStyles[property] && value != "" {
+			kept = append(kept, property+": "+value)
+		}
+	}
+	return strings.Join(kept, "; ")
+}
+
+// restoreHTML puts back the HTML keepHTML set aside
+func restoreHTML(markdown string, kept []string) string {
+	if len(kept) == 0 {
+		return markdown
+	}
+	return htmlTokenRegex.ReplaceAllStringFunc(markdown, func(match string) string {
+		i, err := strconv.Atoi(htmlTokenRegex.FindStringSubmatch(match)[1])
+		if err != nil || i >= len(kept) {
+			return match
+		}
+		return kept[i]
+	})
+}
@@ -0,0 +1,28 @@
+package converter
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// convertJiraIssues rewrites single-issue Jira macros as links to the issue
+// under opts.JiraURL, or as the bare issue key, which MarkdownToStorage
+// links again for projects in JiraOptions.Projects. Macros showing a JQL
+// query are left alone.
+func convertJiraIssues(storage string, opts StorageOptions) string {
+	return replaceMacros(storage, func(m storageMacro) (string, bool) {
+		if m.name != "jira" {
+			return "", false
+		}
+		key := strings.TrimSpace(m.params["key"])
+		if key == "" {
+			return "", false
+		}
+		if opts.JiraURL == "" || opts.RoundTrip {
+			return html.EscapeString(key), true
+		}
+		link := strings.TrimRight(opts.JiraURL, "/") + "/browse/" + url.PathEscape(key)
+		return `<a href="` + html.EscapeString(link) + `">` + html.EscapeString(key) + `</a>`, true
+	})
+}
@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// layoutTagRegex matches the ac:layout tags around a page's sections
+var layoutTagRegex = regexp.MustCompile(`</?ac:layout\s*>`)
+
+// layoutSectionRegex matches one layout section. Layouts do not nest.
+var layoutSectionRegex = regexp.MustCompile(
+	`<ac:layout-section\b([^>]*)>([\s\S]*?)</ac:layout-section>`)
+
+// layoutCellRegex matches one column of a layout section
+var layoutCellRegex = regexp.MustCompile(`<ac:layout-cell\b[^>]*>([\s\S]*?)</ac:layout-cell>`)
+
+// convertLayouts flattens a page layout into its content, in document
+// order. Columns are separated by horizontal rules, or in round-trip mode
+// written as paragraphs holding the :::columns fences MarkdownToStorage
+// reads. Sections of one column, or of more columns than those fences
+// allow, are always flattened. It runs after convertPanels so the column
+// fences can be made longer than any admonition fence in the columns.
+func convertLayouts(storage string, roundTrip bool) string {
+	storage = layoutSectionRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		section := layoutSectionRegex.FindStringSubmatch(match)
+		var cells []string
+		for _, cell := range layoutCellRegex.FindAllStringSubmatch(section[2], -1) {
+			cells = append(cells, cell[1])
+		}
+		if len(cells) < 2 {
+			return strings.Join(cells, "")
+		}
+
+		variant, ok := sectionVariant(linkAttrs(section[1])["ac:type"], len(cells))
+		if !roundTrip || !ok {
+			return strings.Join(cells, "<hr />")
+		}
+
+		// A column fence must be longer than any fence nested inside it,
+		// and the section fence longer again
+		fence := 3
+		for _, cell := range cells {
+			for _, m := range admonitionParagraphRegex.FindAllStringSubmatch(cell, -1) {
+				fence = max(fence, len(m[1])+1)
+			}
+		}
+		column := strings.Repeat(":", fence)
+		open := strings.Repeat(":", fence+1) + "columns"
+		if variant != "" {
+			open += " " + variant
+		}
+		var b strings.Builder
+		b.WriteString("<p>" + open + "</p>")
+		for _, cell := range cells {
+			b.WriteString("<p>" + column + "column</p>" + cell + "<p>" + column + "</p>")
+		}
+		b.WriteString("<p>" + strings.Repeat(":", fence+1) + "</p>")
+		return b.String()
+	})
+	return layoutTagRegex.ReplaceAllString(storage, "")
+}
+
+// sectionVariant returns the :::columns variant publishing as sectionType
+// with the given number of columns, or "" for equal widths, which other
+// section types become. It reports false for more than three columns.
+func sectionVariant(sectionType string, columns int) (string, bool) {
+	if columns > 3 {
+		return "", false
+	}
+	for _, variant := range []string{"left-sidebar", "right-sidebar", "sidebars"} {
+		if layoutVariants[variant][columns-2] == sectionType && layoutVariants[""][columns-2] != sectionType {
+			return variant, true
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// pageLinkRegex matches a link to a page by title, with its attributes and
+// optional link body
+var pageLinkRegex = regexp.MustCompile(
+	`<ac:link([^>]*)>\s*<ri:page([^>]*?)/?>\s*(?:</ri:page>\s*)?([\s\S]*?)</ac:link>`)
+
+// linkAttrRegex matches one attribute of a link or resource identifier
+var linkAttrRegex = regexp.MustCompile(`([a-z]+:[a-z-]+)="([^"]*)"`)
+
+// plainLinkBodyRegex and richLinkBodyRegex match a link's text
+var (
+	plainLinkBodyRegex = regexp.MustCompile(
+		`^<ac:plain-text-link-body>\s*<!\[CDATA\[([\s\S]*?)\]\]>\s*</ac:plain-text-link-body>$`)
+	richLinkBodyRegex = regexp.MustCompile(`^<ac:link-body>([\s\S]*?)</ac:link-body>$`)
+)
+
+// wikiLinkTextRegex matches a wiki link escaped by html-to-markdown
+var wikiLinkTextRegex = regexp.MustCompile(`\\\[\\\[([^\]\n]*)\]\]`)
+
+// tagRegex matches an HTML tag
+var tagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// convertPageLinks rewrites links to pages by title as links to the URL
+// ResolvePage finds, or as [[SPACE:Page Title|text]] wiki links.
+func convertPageLinks(storage string, opts StorageOptions) string {
+	return pageLinkRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		m := pageLinkRegex.FindStringSubmatch(match)
+		link, page := linkAttrs(m[1]), linkAttrs(m[2])
+		ref := PageRef{SpaceKey: page["ri:space-key"], Title: page["ri:content-title"]}
+		if ref.Title == "" {
+			return match
+		}
+
+		// The link body is HTML; body is the same as plain text
+		body := html.EscapeString(ref.Title)
+		text := ""
+		content := strings.TrimSpace(m[3])
+		if sub := plainLinkBodyRegex.FindStringSubmatch(content); sub != nil {
+			text = strings.ReplaceAll(sub[1], "]]><![CDATA[", "")
+			body = html.EscapeString(text)
+		} else if sub := richLinkBodyRegex.FindStringSubmatch(content); sub != nil {
+			body = sub[1]
+			text = html.UnescapeString(tagRegex.ReplaceAllString(body, ""))
+		}
+
+		if opts.ResolvePage != nil && !opts.RoundTrip {
+			if url, err := opts.ResolvePage(ref); err == nil {
+				if anchor := link["ac:anchor"]; anchor != "" {
+					url += "#" + anchor
+				}
+				return `<a href="` + html.EscapeString(url) + `">` + body + `</a>`
+			}
+		}
+
+		target := ref.Title
+		if ref.SpaceKey != "" {
+			target = ref.SpaceKey + ":" + target
+		}
+		if text = strings.TrimSpace(text); text != "" && text != ref.Title {
+			target += "|" + text
+		}
+		return html.EscapeString("[[" + target + "]]")
+	})
+}
+
+// linkAttrs returns the attributes in a tag's attribute text
+func linkAttrs(attrs string) map[string]string {
+	values := map[string]string{}
+	for _, m := range linkAttrRegex.FindAllStringSubmatch(attrs, -1) {
+		values[m[1]] = html.UnescapeString(m[2])
+	}
+	return values
+}
@@ -0,0 +1,203 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Confluence structured macro tags
+const (
+	macroOpen  = "<ac:structured-macro"
+	macroClose = "</ac:structured-macro>"
+)
+
+// storageMacro is one ac:structured-macro found in storage format
+type storageMacro struct {
+	name   string
+	params map[string]string // parameter values, with entities decoded
+	body   string            // rich-text-body content, or plain-text-body text
+}
+
+// macroNameRegex extracts the macro name from its start tag
+var macroNameRegex = regexp.MustCompile(`\sac:name="([^"]*)"`)
+
+// macroParamRegex matches a macro parameter, which may be empty
+var macroParamRegex = regexp.MustCompile(
+	`<ac:parameter\s+ac:name="([^"]*)"\s*(?:/>|>([\s\S]*?)</ac:parameter>)`)
+
+// macroTag is the start or end tag of a structured macro in storage format
+type macroTag struct {
+	start, end  int // byte offsets of the tag
+	open        bool
+	selfClosing bool
+}
+
+// macroTags returns the structured macro tags in storage in order, in one
+// pass. An opening tag that is never ended with > stops the scan.
+func macroTags(storage string) []macroTag {
+	var tags []macroTag
+	// The next tags of each kind are only searched for again once passed,
+	// with len(storage) standing for none
+	nextOpen, nextClose := -1, -1
+	pos := 0
+	for {
+		if nextOpen < pos {
+			nextOpen = indexOrEnd(storage, macroOpen, pos)
+		}
+		if nextClose < pos {
+			nextClose = indexOrEnd(storage, macroClose, pos)
+		}
+		switch {
+		case nextOpen < nextClose:
+			tagEnd := indexFrom(storage, ">", nextOpen)
+			if tagEnd < 0 {
+				return tags
+			}
+			tags = append(tags, macroTag{start: nextOpen, end: tagEnd + 1, open: true, selfClosing: storage[tagEnd-1] == '/'})
+			pos = tagEnd + 1
+		case nextClose < len(storage):
+			tags = append(tags, macroTag{start: nextClose, end: nextClose + len(macroClose)})
+			pos = nextClose + len(macroClose)
+		default:
+			return tags
+		}
+	}
+}
+
+// replaceMacros replaces structured macros in storage with the result of
+// replace, innermost first, so a macro's body never holds a macro replace
+// handles. Macros for which replace returns false, or that are not closed,
+// are kept as they are. It makes one pass over storage, with each open
+// macro collecting its content, inner macros replaced, until it closes.
+func replaceMacros(storage string, replace func(m storageMacro) (string, bool)) string {
+	tags := macroTags(storage)
+	if len(tags) == 0 {
+		return storage
+	}
+	stack := []*strings.Builder{{}}
+	stack[0].Grow(len(storage))
+	pos := 0
+	for _, tag := range tags {
+		top := stack[len(stack)-1]
+		top.WriteString(storage[pos:tag.start])
+		pos = tag.end
+		switch {
+		case tag.selfClosing:
+			writeReplaced(top, storage[tag.start:tag.end], replace)
+		case tag.open:
+			macro := &strings.Builder{}
+			macro.WriteString(storage[tag.start:tag.end])
+			stack = append(stack, macro)
+		case len(stack) > 1:
+			top.WriteString(storage[tag.start:tag.end])
+			stack = stack[:len(stack)-1]
+			writeReplaced(stack[len(stack)-1], top.String(), replace)
+		default:
+			// An end tag with no macro open is kept
+			top.WriteString(storage[tag.start:tag.end])
+		}
+	}
+	stack[len(stack)-1].WriteString(storage[pos:])
+	for len(stack) > 1 {
+		unclosed := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stack[len(stack)-1].WriteString(unclosed.String())
+	}
+	return stack[0].String()
+}
+
+// writeReplaced writes the result of replace for the complete macro raw to
+// b, or raw itself if replace declines it
+func writeReplaced(b *strings.Builder, raw string, replace func(m storageMacro) (string, bool)) {
+	if out, ok := replace(parseMacro(raw)); ok {
+		b.WriteString(out)
+		return
+	}
+	b.WriteString(raw)
+}
+
+// macroEnd returns the index just past the end of the macro starting at
+// start, or -1 if it is not closed
+func macroEnd(storage string, start int) int {
+	depth := 0
+	pos := start
+	for {
+		open := indexFrom(storage, macroOpen, pos)
+		closing := indexFrom(storage, macroClose, pos)
+		if open >= 0 && (closing < 0 || open < closing) {
+			tagEnd := indexFrom(storage, ">", open)
+			if tagEnd < 0 {
+				return -1
+			}
+			pos = tagEnd + 1
+			if storage[tagEnd-1] == '/' {
+				if depth == 0 {
+					return pos
+				}
+				continue
+			}
+			depth++
+			continue
+		}
+		if closing < 0 {
+			return -1
+		}
+		depth--
+		pos = closing + len(macroClose)
+		if depth == 0 {
+			return pos
+		}
+	}
+}
+
+// indexFrom returns the index of substr in s at or after from, or -1
+func indexFrom(s, substr string, from int) int {
+	i := strings.Index(s[from:], substr)
+	if i < 0 {
+		return -1
+	}
+	return from + i
+}
+
+// indexOrEnd returns the index of substr in s at or after from, or len(s)
+func indexOrEnd(s, substr string, from int) int {
+	if i := indexFrom(s, substr, from); i >= 0 {
+		return i
+	}
+	return len(s)
+}
+
+// parseMacro reads the name, parameters, and body of a complete macro
+func parseMacro(macro string) storageMacro {
+	tagEnd := strings.Index(macro, ">")
+	m := storageMacro{params: map[string]string{}}
+	if match := macroNameRegex.FindStringSubmatch(macro[:tagEnd]); match != nil {
+		m.name = strings.ToLower(match[1])
+	}
+	if macro[tagEnd-1] == '/' {
+		return m
+	}
+	inner := strings.TrimSuffix(macro[tagEnd+1:], macroClose)
+
+	// Parameters come before the body, so nested macros' are not read
+	head := inner
+	if i := strings.Index(inner, "<ac:rich-text-body>"); i >= 0 {
+		head = inner[:i]
+		if j := strings.LastIndex(inner, "</ac:rich-text-body>"); j > i {
+			m.body = inner[i+len("<ac:rich-text-body>") : j]
+		}
+	} else if i := strings.Index(inner, "<ac:plain-text-body>"); i >= 0 {
+		head = inner[:i]
+		if j := strings.LastIndex(inner, "</ac:plain-text-body>"); j > i {
+			body := inner[i+len("<ac:plain-text-body>") : j]
+			body = strings.ReplaceAll(body, "]]><![CDATA[", "")
+			body = strings.TrimPrefix(body, "<![CDATA[")
+			m.body = strings.TrimSuffix(body, "]]>")
+		}
+	}
+	for _, match := range macroParamRegex.FindAllStringSubmatch(head, -1) {
+		m.params[match[1]] = html.UnescapeString(match[2])
+	}
+	return m
+}
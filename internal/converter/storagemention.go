@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// userLinkRegex matches a user mention, capturing the account ID
+var userLinkRegex = regexp.MustCompile(
+	`<ac:link[^>]*>\s*<ri:user\s[^>]*?ri:account-id="([^"]*)"[^>]*?/?>\s*(?:</ri:user>\s*)?[\s\S]*?</ac:link>`)
+
+// convertMentions rewrites user mentions as @Display Name, or in round-trip
+// mode as @{Display Name} as MarkdownToStorage reads them. Without a name
+// from ResolveUserName, the account ID stands in for it.
+func convertMentions(storage string, opts StorageOptions) string {
+	return userLinkRegex.ReplaceAllStringFunc(storage, func(match string) string {
+		accountID := html.UnescapeString(userLinkRegex.FindStringSubmatch(match)[1])
+		name := accountID
+		if opts.ResolveUserName != nil {
+			if resolved, err := opts.ResolveUserName(accountID); err == nil && strings.TrimSpace(resolved) != "" {
+				name = strings.TrimSpace(resolved)
+			}
+		}
+		if opts.RoundTrip {
+			return html.EscapeString("@{" + name + "}")
+		}
+		return html.EscapeString("@" + name)
+	})
+}
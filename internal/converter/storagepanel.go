@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// panelAlerts maps Confluence panel macros to GitHub alert markers, the
+// reverse of alertMacros. The plain panel macro has no type of its own.
+var panelAlerts = map[string]string{
+	"info":    "NOTE",
+	"warning": "WARNING",
+	"tip":     "TIP",
+	"note":    "CAUTION",
+	"panel":   "NOTE",
+}
+
+// panelAdmonitions maps Confluence panel macros to ::: directive types
+var panelAdmonitions = map[string]string{
+	"info":    "info",
+	"warning": "warning",
+	"tip":     "tip",
+	"note":    "note",
+	"panel":   "info",
+}
+
+// alertMarkerRegex matches an alert marker escaped by html-to-markdown,
+// with the blank quote line that follows it
+var alertMarkerRegex = regexp.MustCompile(
+	`(?m)^((?:> )*)> \\\[!(NOTE|TIP|WARNING|CAUTION)\]\n(?:[> ]*\n)?`)
+
+// admonitionFenceRegex matches the opening line of a ::: admonition
+var admonitionFenceRegex = regexp.MustCompile(`(?m)^:{3,}[a-z]+ .*$`)
+
+// admonitionParagraphRegex matches a paragraph holding a ::: fence
+var admonitionParagraphRegex = regexp.MustCompile(`<p>(:{3,})`)
+
+// markdownEscapeRegex matches a backslash escape of ASCII punctuation
+var markdownEscapeRegex = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+
+// convertPanels rewrites info, note, warning, tip, and panel macros as
+// blockquotes that become GitHub alerts, or with admonitions as paragraphs
+// holding ::: fences. A panel title is kept as the admonition title, or as
+// a bold first line of the alert.
+func convertPanels(storage string, admonitions bool) string {
+	return replaceMacros(storage, func(m storageMacro) (string, bool) {
+		title := strings.TrimSpace(m.params["title"])
+		if admonitions {
+			kind, ok := panelAdmonitions[m.name]
+			if !ok {
+				return "", false
+			}
+			// A fence must be longer than any fence nested inside it
+			fence := 3
+			for _, match := range admonitionParagraphRegex.FindAllStringSubmatch(m.body, -1) {
+				fence = max(fence, len(match[1])+1)
+			}
+			open := strings.Repeat(":", fence) + kind
+			if title != "" {
+				open += " " + html.EscapeString(title)
+			}
+			return "<p>" + open + "</p>" + m.body + "<p>" + strings.Repeat(":", fence) + "</p>", true
+		}
+
+		marker, ok := panelAlerts[m.name]
+		if !ok {
+			return "", false
+		}
+		var b strings.Builder
+		b.WriteString("<blockquote><p>[!" + marker + "]</p>")
+		if title != "" {
+			b.WriteString("<p><strong>" + html.EscapeString(title) + "</strong></p>")
+		}
+		b.WriteString(m.body)
+		b.WriteString("</blockquote>")
+		return b.String(), true
+	})
+}
+
+// unescapeMarkdown removes backslash escapes, for text such as an
+// admonition title that Markdown does not parse
+func unescapeMarkdown(s string) string {
+	return markdownEscapeRegex.ReplaceAllString(s, "$1")
+}
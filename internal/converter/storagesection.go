@@ -0,0 +1,108 @@
+package converter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// headingLevels maps the heading elements to their levels
+var headingLevels = map[string]int{"h1": 1, "h2": 2, "h3": 3, "h4": 4, "h5": 5, "h6": 6}
+
+// ReplaceStorageSection replaces the content of the section of storage under
+// the heading whose text is heading with replacement, keeping the heading
+// itself. The section runs to the next heading of the same or a higher
+// level beside it, or to the end of the element the heading is in, such as
+// a layout cell. Heading text, leaving out that of macros such as anchors,
+// is matched ignoring case and surrounding space, and must match exactly
+// one heading.
+func ReplaceStorageSection(storage, heading, replacement string) (string, error) {
+	want := strings.Join(strings.Fields(heading), " ")
+	if want == "" {
+		return "", fmt.Errorf("heading cannot be empty")
+	}
+
+	// The wrapper gives the fragment a single root, as for CheckStorage
+	const wrapper = "<storage>"
+	input := wrapper + storage + "</storage>"
+	d := xml.NewDecoder(strings.NewReader(input))
+	d.Entity = xml.HTMLEntity
+
+	type match struct {
+		level, depth int
+		start, end   int64
+	}
+	var matches []match
+	var open *match // the matched section still looking for its end
+
+	depth := 0
+	inHeading, headingLevel, headingDepth := false, 0, 0
+	// macroDepth is the depth of the macro inside a heading whose text is
+	// being skipped, such as the anchor macro MarkdownToStorage puts in
+	// each heading, or 0
+	macroDepth := 0
+	var text strings.Builder
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parsing storage format: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			name := elementName(t.Name)
+			if inHeading && macroDepth == 0 && (name == "ac:structured-macro" || name == "ac:parameter") {
+				macroDepth = depth
+			}
+			level, isHeading := headingLevels[name]
+			if !isHeading || inHeading {
+				continue
+			}
+			if open != nil && depth == open.depth && level <= open.level {
+				open.end = offset
+				matches = append(matches, *open)
+				open = nil
+			}
+			inHeading, headingLevel, headingDepth = true, level, depth
+			text.Reset()
+		case xml.EndElement:
+			if depth == macroDepth {
+				macroDepth = 0
+			}
+			if open != nil && depth == open.depth-1 {
+				open.end = offset
+				matches = append(matches, *open)
+				open = nil
+			}
+			if inHeading && depth == headingDepth {
+				inHeading = false
+				if strings.EqualFold(strings.Join(strings.Fields(text.String()), " "), want) {
+					open = &match{level: headingLevel, depth: depth, start: d.InputOffset()}
+				}
+			}
+			depth--
+		case xml.CharData:
+			if inHeading && macroDepth == 0 {
+				text.Write(t)
+			}
+		}
+	}
+	// The wrapper's end tag closes any section still open
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no heading %q", heading)
+	case 1:
+	default:
+		return "", fmt.Errorf("%d headings match %q", len(matches), heading)
+	}
+	m := matches[0]
+	start, end := int(m.start)-len(wrapper), int(m.end)-len(wrapper)
+	return storage[:start] + replacement + storage[end:], nil
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplaceStorageSection(t *testing.T) {
+	const page = `<h1>Runbook</h1><p>Intro</p>` +
+		`<h2>Deploy</h2><p>Ship it</p><h3>Checks</h3><p>Look</p>` +
+		`<h2>Rollback  &amp; Recovery</h2><p>Old steps</p><h3>Notes</h3><p>Careful</p>` +
+		`<h2>Contacts</h2><p>Ops</p>`
+
+	tests := []struct {
+		name    string
+		storage string
+		heading string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "up to the next heading of the same level",
+			storage: page,
+			heading: "rollback & recovery",
+			want: `<h1>Runbook</h1><p>Intro</p>` +
+				`<h2>Deploy</h2><p>Ship it</p><h3>Checks</h3><p>Look</p>` +
+				`<h2>Rollback  &amp; Recovery</h2><p>NEW</p>` +
+				`<h2>Contacts</h2><p>Ops</p>`,
+		},
+		{
+			name:    "up to a higher level heading",
+			storage: page,
+			heading: "Checks",
+			want: `<h1>Runbook</h1><p>Intro</p>` +
+				`<h2>Deploy</h2><p>Ship it</p><h3>Checks</h3><p>NEW</p>` +
+				`<h2>Rollback  &amp; Recovery</h2><p>Old steps</p><h3>Notes</h3><p>Careful</p>` +
+				`<h2>Contacts</h2><p>Ops</p>`,
+		},
+		{
+			name:    "to the end of the page",
+			storage: page,
+			heading: " Contacts ",
+			want: `<h1>Runbook</h1><p>Intro</p>` +
+				`<h2>Deploy</h2><p>Ship it</p><h3>Checks</h3><p>Look</p>` +
+				`<h2>Rollback  &amp; Recovery</h2><p>Old steps</p><h3>Notes</h3><p>Careful</p>` +
+				`<h2>Contacts</h2><p>NEW</p>`,
+		},
+		{
+			name:    "to the end of a layout cell",
+			storage: `<ac:layout><ac:layout-section ac:type="two_equal"><ac:layout-cell><h2>Left</h2><p>a</p></ac:layout-cell><ac:layout-cell><h2>Right</h2><p>b</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			heading: "Left",
+			want:    `<ac:layout><ac:layout-section ac:type="two_equal"><ac:layout-cell><h2>Left</h2><p>NEW</p></ac:layout-cell><ac:layout-cell><h2>Right</h2><p>b</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+		},
+		{
+			name:    "heading with markup",
+			storage: `<h2><strong>Bold</strong> step</h2><p>x</p>`,
+			heading: "Bold step",
+			want:    `<h2><strong>Bold</strong> step</h2><p>NEW</p>`,
+		},
+		{name: "missing", storage: page, heading: "Nope", wantErr: `no heading "Nope"`},
+		{name: "ambiguous", storage: `<h2>A</h2><h2>A</h2>`, heading: "A", wantErr: `2 headings match "A"`},
+		{name: "empty heading", storage: page, heading: " ", wantErr: "heading cannot be empty"},
+		{name: "malformed", storage: `<h2>A</h2><p>`, heading: "A", wantErr: "parsing storage format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReplaceStorageSection(tt.storage, tt.heading, "<p>NEW</p>")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceStorageSection_PublishedPage(t *testing.T) {
+	storage, err := MarkdownToStorage("# Runbook\n\n## Deploy\n\nShip it\n\n## Rollback Steps\n\nOld steps\n\n### Notes\n\nCareful\n\n## Contacts\n\nOps\n")
+	if err != nil {
+		t.Fatalf("MarkdownToStorage() error = %v", err)
+	}
+
+	got, err := ReplaceStorageSection(storage, "Rollback Steps", "<p>NEW</p>")
+	if err != nil {
+		t.Fatalf("ReplaceStorageSection() error = %v", err)
+	}
+	if strings.Contains(got, "Old steps") || strings.Contains(got, "Careful") {
+		t.Errorf("section content kept:\n%s", got)
+	}
+	for _, kept := range []string{"Ship it", "rollback-steps</ac:parameter></ac:structured-macro>Rollback Steps</h2><p>NEW</p>", "Contacts", "Ops"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("result missing %q:\n%s", kept, got)
+		}
+	}
+
+	if _, err := ReplaceStorageSection(storage, "rollback-steps", "<p>NEW</p>"); err == nil {
+		t.Error("ReplaceStorageSection() matched the anchor name instead of the heading text")
+	}
+}
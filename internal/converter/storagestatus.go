@@ -0,0 +1,49 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// statusTextRegex matches a status written as bold bracketed text, which
+// html-to-markdown escapes
+var statusTextRegex = regexp.MustCompile(`\*\*\\\[([^\]\n]*)\]\*\*`)
+
+// statusShortcodeRegex matches a {status:...} shortcode, whose title
+// Markdown does not parse
+var statusShortcodeRegex = regexp.MustCompile(`\{status:[^}\n]*\}`)
+
+// convertStatuses rewrites status macros as their title in bold brackets,
+// or in round-trip mode as {status:...} shortcodes. A status without a
+// title is dropped.
+func convertStatuses(storage string, roundTrip bool) string {
+	return replaceMacros(storage, func(m storageMacro) (string, bool) {
+		if m.name != "status" {
+			return "", false
+		}
+		title := strings.TrimSpace(m.params["title"])
+		if title == "" {
+			return "", true
+		}
+		if !roundTrip {
+			return "<strong>[" + html.EscapeString(title) + "]</strong>", true
+		}
+		colour := strings.ToLower(strings.TrimSpace(m.params["colour"]))
+		if _, ok := statusColours[colour]; !ok || colour == "grey" {
+			return html.EscapeString("{status:" + title + "}"), true
+		}
+		return html.EscapeString("{status:" + colour + "|" + title + "}"), true
+	})
+}
+
+// unescapeShortcode removes backslash escapes from a shortcode, except
+// before a pipe, which a table cell needs escaped
+func unescapeShortcode(s string) string {
+	return markdownEscapeRegex.ReplaceAllStringFunc(s, func(escape string) string {
+		if escape == `\|` {
+			return escape
+		}
+		return escape[1:]
+	})
+}
@@ -0,0 +1,183 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/yuin/goldmark/parser"
+	"golang.org/x/net/html"
+)
+
+// storageBlockKind classifies the top-level content of a page for
+// splitStorage
+type storageBlockKind int
+
+const (
+	storageNone   storageBlockKind = iota // nothing yet, or markup with no content
+	storageInline                         // text and inline elements
+	storageBlock                          // a block element
+	storageList                           // a list, which must stay next to any list beside it
+)
+
+// storageBlockTags lists the elements that start a block of their own
+var storageBlockTags = map[string]storageBlockKind{
+	"p":                 storageBlock,
+	"h1":                storageBlock,
+	"h2":                storageBlock,
+	"h3":                storageBlock,
+	"h4":                storageBlock,
+	"h5":                storageBlock,
+	"h6":                storageBlock,
+	"table":             storageBlock,
+	"blockquote":        storageBlock,
+	"pre":               storageBlock,
+	"hr":                storageBlock,
+	"div":               storageBlock,
+	"ac:layout-section": storageBlock,
+	"ul":                storageList,
+	"ol":                storageList,
+	"ac:task-list":      storageList,
+}
+
+// inlineMacros lists the macros that convert to inline text. Other macros
+// convert to blocks, including those kept as confluence-macro fences.
+var inlineMacros = map[string]bool{
+	"status": true,
+	"anchor": true,
+	"jira":   true,
+}
+
+// storageVoidTags lists the elements that have no end tag even when written
+// without a trailing slash
+var storageVoidTags = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+	"col": true,
+}
+
+// StorageToMarkdownStream converts Confluence storage format read from r to
+// Markdown written to w. The storage is read with a streaming tokenizer and
+// converted one top-level block at a time, so memory use follows the
+// largest block, such as one table, rather than the size of the page.
+func StorageToMarkdownStream(w io.Writer, r io.Reader, opts StorageOptions) error {
+	ids := parser.NewContext().IDs()
+	written := false
+	return splitStorage(r, func(block string) error {
+		markdown, err := convertStorageBlock(block, opts, ids)
+		if err != nil {
+			return err
+		}
+		if markdown == "" {
+			return nil
+		}
+		if written {
+			markdown = "\n\n" + markdown
+		}
+		written = true
+		_, err = io.WriteString(w, markdown)
+		return err
+	})
+}
+
+// splitStorage reads storage format from r with a streaming tokenizer and
+// calls emit with each run of top-level content that converts on its own.
+// Runs break only between two block elements, so inline content is never
+// split from the text around it, and lists beside each other stay together
+// for task lists to join and Markdown to keep apart. Page layouts are split
+// into their sections. Unclosed elements extend the run to the end of the
+// page, which then converts as a whole.
+func splitStorage(r io.Reader, emit func(block string) error) error {
+	z := html.NewTokenizer(r)
+	z.AllowCDATA(true)
+
+	var run strings.Builder
+	depth := 0
+	last := storageNone
+	flush := func() error {
+		if run.Len() == 0 {
+			return nil
+		}
+		block := run.String()
+		run.Reset()
+		return emit(block)
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return err
+			}
+			return flush()
+		}
+		// Copy the token before TagName, which lowercases it in place
+		raw := string(z.Raw())
+		if depth > 0 {
+			run.WriteString(raw)
+			switch tt {
+			case html.StartTagToken:
+				if name, _ := z.TagName(); !storageVoidTags[string(name)] {
+					depth++
+				}
+			case html.EndTagToken:
+				depth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			if strings.TrimSpace(raw) != "" {
+				last = storageInline
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := string(name)
+			if tag == "ac:layout" {
+				// The layout wraps the whole page; its sections are the blocks
+				break
+			}
+			kind := storageTagKind(z, tag, hasAttr)
+			if kind != storageInline && last != storageNone && last != storageInline && (kind != storageList || last != storageList) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			last = kind
+			if tt == html.StartTagToken && !storageVoidTags[tag] {
+				depth++
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) != "ac:layout" {
+				// An end tag with nothing open is kept with the text around it
+				last = storageInline
+			}
+		}
+		run.WriteString(raw)
+	}
+}
+
+// storageTagKind returns the kind of a top-level element named tag whose
+// start tag z has just read
+func storageTagKind(z *html.Tokenizer, tag string, hasAttr bool) storageBlockKind {
+	if tag == "ac:structured-macro" {
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			if bytes.Equal(key, []byte("ac:name")) {
+				if inlineMacros[strings.ToLower(string(val))] {
+					return storageInline
+				}
+				break
+			}
+		}
+		return storageBlock
+	}
+	if kind, ok := storageBlockTags[tag]; ok {
+		return kind
+	}
+	return storageInline
+}
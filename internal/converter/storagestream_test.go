@@ -0,0 +1,150 @@
+package converter
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestSplitStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage string
+		want    []string
+	}{
+		{
+			name:    "block elements",
+			storage: `<h1>Title</h1><p>One</p> <table><tbody><tr><td>x</td></tr></tbody></table>`,
+			want:    []string{`<h1>Title</h1>`, `<p>One</p> `, `<table><tbody><tr><td>x</td></tr></tbody></table>`},
+		},
+		{
+			name:    "inline content kept with its blocks",
+			storage: `<p>One</p>loose <strong>text</strong><p>Two</p><p>Three</p>`,
+			want:    []string{`<p>One</p>loose <strong>text</strong><p>Two</p>`, `<p>Three</p>`},
+		},
+		{
+			name:    "adjacent lists kept together",
+			storage: `<ul><li>a</li></ul><ac:task-list><ac:task></ac:task></ac:task-list><ol><li>b</li></ol><p>End</p>`,
+			want:    []string{`<ul><li>a</li></ul><ac:task-list><ac:task></ac:task></ac:task-list><ol><li>b</li></ol>`, `<p>End</p>`},
+		},
+		{
+			name:    "void elements",
+			storage: `<p>a<br>b</p><hr><p>c</p>`,
+			want:    []string{`<p>a<br>b</p>`, `<hr>`, `<p>c</p>`},
+		},
+		{
+			name: "inline macros",
+			storage: `<p>a</p><ac:structured-macro ac:name="status"></ac:structured-macro>` +
+				`<p>b</p><ac:structured-macro ac:name="info"></ac:structured-macro>`,
+			want: []string{
+				`<p>a</p><ac:structured-macro ac:name="status"></ac:structured-macro><p>b</p>`,
+				`<ac:structured-macro ac:name="info"></ac:structured-macro>`,
+			},
+		},
+		{
+			name:    "layout split into sections",
+			storage: `<ac:layout><ac:layout-section ac:type="single"><ac:layout-cell><p>a</p></ac:layout-cell></ac:layout-section><ac:layout-section ac:type="single"><ac:layout-cell><p>b</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			want: []string{
+				`<ac:layout><ac:layout-section ac:type="single"><ac:layout-cell><p>a</p></ac:layout-cell></ac:layout-section>`,
+				`<ac:layout-section ac:type="single"><ac:layout-cell><p>b</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			},
+		},
+		{
+			name:    "CDATA left whole",
+			storage: `<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[</p><p>]]></ac:plain-text-body></ac:structured-macro><p>x</p>`,
+			want:    []string{`<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[</p><p>]]></ac:plain-text-body></ac:structured-macro>`, `<p>x</p>`},
+		},
+		{
+			name:    "unclosed element runs to the end",
+			storage: `<p>a</p><div><p>b</p><p>c</p>`,
+			want:    []string{`<p>a</p>`, `<div><p>b</p><p>c</p>`},
+		},
+		{
+			name:    "empty",
+			storage: ``,
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := splitStorage(strings.NewReader(tt.storage), func(block string) error {
+				got = append(got, block)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("splitStorage() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageToMarkdownStream(t *testing.T) {
+	// The first block is written before the rest of the page is read
+	r, pw := io.Pipe()
+	out, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- StorageToMarkdownStream(w, r, StorageOptions{})
+		w.Close()
+	}()
+
+	if _, err := io.WriteString(pw, `<h1>First</h1><p>Second</p>`); err != nil {
+		t.Fatal(err)
+	}
+	first := make([]byte, len("# First"))
+	if _, err := io.ReadFull(out, first); err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != "# First" {
+		t.Errorf("first block = %q, want %q", first, "# First")
+	}
+	pw.Close()
+	go io.Copy(io.Discard, out) //nolint:errcheck
+	if err := <-done; err != nil {
+		t.Fatalf("StorageToMarkdownStream() error = %v", err)
+	}
+}
+
+func TestStorageToMarkdownStream_MatchesWholePage(t *testing.T) {
+	// Converting a block at a time gives the same Markdown as converting
+	// the page in one go, read in any size of piece
+	storage := strings.Repeat(benchmarkStorage, 3)
+	want, err := convertStorageBlock(storage, StorageOptions{}, parser.NewContext().IDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got strings.Builder
+	if err := StorageToMarkdownStream(&got, iotest.OneByteReader(strings.NewReader(storage)), StorageOptions{}); err != nil {
+		t.Fatalf("StorageToMarkdownStream() error = %v", err)
+	}
+	if got.String() != want {
+		t.Errorf("streamed output differs:\ngot:\n%s\nwant:\n%s", got.String(), want)
+	}
+}
+
+func BenchmarkStorageToMarkdownStream_10MB(b *testing.B) {
+	// A 10MB page, converted a block at a time. Headings are numbered, as
+	// thousands of repeats of one heading make its auto IDs the cost.
+	var large strings.Builder
+	for i := 0; large.Len() < 10<<20; i++ {
+		large.WriteString(strings.ReplaceAll(benchmarkStorage, "</h", " "+strconv.Itoa(i)+"</h"))
+	}
+	page := large.String()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if err := StorageToMarkdownStream(io.Discard, strings.NewReader(page), StorageOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tableRegex matches a table. A match holding another table start is a
+// table with one nested in it, which is left alone.
+var tableRegex = regexp.MustCompile(`<table\b[^>]*>[\s\S]*?</table>`)
+
+// tableRowRegex matches a table row
+var tableRowRegex = regexp.MustCompile(`<tr\b[^>]*>[\s\S]*?</tr>`)
+
+// tableCellTagRegex matches the start or end tag of a table cell
+var tableCellTagRegex = regexp.MustCompile(`<(/?)t([hd])\b`)
+
+// convertTableHeaders makes the first row of a table without a thead its
+// header row if every cell in it is a th, as Confluence writes header rows,
+// and turns th cells anywhere else into td cells. Otherwise html-to-markdown
+// takes the row of the first th it finds as the header, moving a later row
+// or a row with a header column to the top.
+func convertTableHeaders(storage string) string {
+	return tableRegex.ReplaceAllStringFunc(storage, func(table string) string {
+		if strings.Contains(table[1:], "<table") || strings.Contains(table, "<thead") || !strings.Contains(table, "<th") {
+			return table
+		}
+		rows := tableRowRegex.FindAllStringIndex(table, -1)
+		if len(rows) == 0 {
+			return table
+		}
+		bodyStart := 0
+		if headerRow(table[rows[0][0]:rows[0][1]]) {
+			bodyStart = rows[0][1]
+		}
+		return table[:bodyStart] + tableCellTagRegex.ReplaceAllString(table[bodyStart:], "<${1}td")
+	})
+}
+
+// headerRow reports whether every cell in row is a th
+func headerRow(row string) bool {
+	cells := 0
+	for _, m := range tableCellTagRegex.FindAllStringSubmatch(row, -1) {
+		if m[1] != "" {
+			continue
+		}
+		if m[2] != "h" {
+			return false
+		}
+		cells++
+	}
+	return cells > 0
+}
@@ -0,0 +1,27 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+)
+
+// tocComment stands in for a table of contents outside round-trip mode
+const tocComment = "<!-- table of contents -->"
+
+// tocMarkerRegex matches a [TOC] marker escaped by html-to-markdown
+var tocMarkerRegex = regexp.MustCompile(`(?m)^\\\[TOC\]$`)
+
+// convertTOCs rewrites table of contents macros as paragraphs holding the
+// [TOC] marker in round-trip mode, or otherwise an HTML comment noting
+// where the table was, since the Markdown has none.
+func convertTOCs(storage string, roundTrip bool) string {
+	return replaceMacros(storage, func(m storageMacro) (string, bool) {
+		if m.name != "toc" {
+			return "", false
+		}
+		if roundTrip {
+			return "<p>[TOC]</p>", true
+		}
+		return "<p>" + html.EscapeString(tocComment) + "</p>", true
+	})
+}
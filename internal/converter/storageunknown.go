@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// macroFenceLang is the code fence language holding a macro's storage
+// format, which MarkdownToStorage publishes as it is
+const macroFenceLang = "confluence-macro"
+
+// macroLabelRegex matches the comment leading a macro fence
+var macroLabelRegex = regexp.MustCompile(`^<!-- Confluence macro: [^\n]* -->\n`)
+
+// macroTokenRegex matches a token left by hideUnknownMacros
+var macroTokenRegex = regexp.MustCompile(`ACONMACRO(\d+)X`)
+
+// macroTokenLineRegex matches a line holding only a token, after the
+// prefix of any quote or list it is in
+var macroTokenLineRegex = regexp.MustCompile(`(?m)^([ \t>]*(?:(?:[-*+]|\d+[.)]) )?)ACONMACRO(\d+)X$`)
+
+// macroLabel returns the comment naming a macro for the reader
+func macroLabel(raw string) string {
+	return "<!-- Confluence macro: " + parseMacro(raw).name + " -->"
+}
+
+// knownMacro reports whether StorageToMarkdown converts m itself
+func knownMacro(m storageMacro) bool {
+	switch m.name {
+	case "code", "anchor", "expand", "status", "toc":
+		return true
+	case "jira":
+		return strings.TrimSpace(m.params["key"]) != ""
+	}
+	_, ok := panelAlerts[m.name]
+	return ok
+}
+
+// hideUnknownMacros replaces each outermost macro with no Markdown
+// equivalent by a paragraph holding a token, returning the macros' storage
+// format in token order. The tokens pass through html-to-markdown as plain
+// text, so the storage format is not escaped or decoded on the way.
+func hideUnknownMacros(storage string) (string, []string) {
+	var macros []string
+	var b strings.Builder
+	copied, pos := 0, 0
+	for {
+		start := indexFrom(storage, macroOpen, pos)
+		if start < 0 {
+			break
+		}
+		stop := macroEnd(storage, start)
+		if stop < 0 {
+			break
+		}
+		raw := storage[start:stop]
+		if knownMacro(parseMacro(raw)) {
+			// Look for unknown macros within the body
+			pos = start + len(macroOpen)
+			continue
+		}
+		b.WriteString(storage[copied:start])
+		b.WriteString("<p>ACONMACRO" + strconv.Itoa(len(macros)) + "X</p>")
+		macros = append(macros, raw)
+		copied, pos = stop, stop
+	}
+	if len(macros) == 0 {
+		return storage, nil
+	}
+	b.WriteString(storage[copied:])
+	return b.String(), macros
+}
+
+// restoreUnknownMacros replaces the tokens left by hideUnknownMacros with
+// code fences holding the macros' storage format, led by a comment naming
+// the macro. Fence lines keep the prefix of the quote or list the token is
+// in. A token sharing its line with other text, as in a table or heading,
+// cannot hold a fence, so only the comment is left there.
+func restoreUnknownMacros(markdown string, macros []string) string {
+	if len(macros) == 0 {
+		return markdown
+	}
+	markdown = macroTokenLineRegex.ReplaceAllStringFunc(markdown, func(match string) string {
+		sub := macroTokenLineRegex.FindStringSubmatch(match)
+		i, err := strconv.Atoi(sub[2])
+		if err != nil || i >= len(macros) {
+			return match
+		}
+		raw := macros[i]
+		fence := strings.Repeat("`", max(3, longestRun(raw, '`')+1))
+		label := macroLabel(raw)
+
+		// Later lines of a list item are indented to its content
+		first := sub[1]
+		rest := strings.Map(func(r rune) rune {
+			if r == '>' {
+				return r
+			}
+			return ' '
+		}, first)
+		lines := append([]string{fence + macroFenceLang, label}, strings.Split(raw, "\n")...)
+		lines = append(lines, fence)
+		for j := range lines {
+			if j == 0 {
+				lines[j] = first + lines[j]
+			} else {
+				lines[j] = rest + lines[j]
+			}
+		}
+		return strings.Join(lines, "\n")
+	})
+	return macroTokenRegex.ReplaceAllStringFunc(markdown, func(match string) string {
+		i, err := strconv.Atoi(macroTokenRegex.FindStringSubmatch(match)[1])
+		if err != nil || i >= len(macros) {
+			return match
+		}
+		return macroLabel(macros[i])
+	})
+}
+
+// longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
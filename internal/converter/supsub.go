@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// kindScript is the node kind for superscript and subscript text
+var kindScript = ast.NewNodeKind("Script")
+
+// script is ^superscript^ or ~subscript~ text, with tag sup or sub
+type script struct {
+	ast.BaseInline
+	tag string
+}
+
+func (n *script) Kind() ast.NodeKind {
+	return kindScript
+}
+
+func (n *script) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Tag": n.tag}, nil)
+}
+
+// scriptParser parses ^sup^ and, with Options.Subscript, ~sub~ as in Pandoc:
+// the text between the markers cannot be empty or contain spaces, so x^2^
+// and H~2~O convert but ~ approximately ~ does not. Double tildes, and
+// single tildes without Options.Subscript, are left to strikethrough.
+type scriptParser struct {
+	subscript bool
+}
+
+func newScriptParser(subscript bool) parser.InlineParser {
+	return &scriptParser{subscript: subscript}
+}
+
+func (p *scriptParser) Trigger() []byte {
+	if p.subscript {
+		return []byte{'^', '~'}
+	}
+	return []byte{'^'}
+}
+
+func (p *scriptParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	marker := line[0]
+	if block.PrecendingCharacter() == rune(marker) || len(line) < 3 || line[1] == marker {
+		return nil
+	}
+	end := bytes.IndexByte(line[1:], marker) + 1
+	if end < 1 || bytes.ContainsFunc(line[1:end], func(r rune) bool { return r < 0x80 && util.IsSpace(byte(r)) }) {
+		return nil
+	}
+	node := &script{tag: "sup"}
+	if marker == '~' {
+		node.tag = "sub"
+	}
+	node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+1, segment.Start+end)))
+	block.Advance(end + 1)
+	return node
+}
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// proseTexts returns the text nodes under doc that are ordinary prose, not
+// the content of code spans, links, or images. Inline parsers can leave a
+// run of text in several nodes, so adjacent ones are first merged, letting
+// a pattern match across the split.
+func proseTexts(doc ast.Node) []*ast.Text {
+	var texts []*ast.Text
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindCodeSpan, ast.KindLink, ast.KindAutoLink, ast.KindImage, kindWikiLink:
+			return ast.WalkSkipChildren, nil
+		case ast.KindText:
+			texts = append(texts, n.(*ast.Text))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	merged := texts[:0]
+	for _, n := range texts {
+		if prev, ok := n.PreviousSibling().(*ast.Text); ok && len(merged) > 0 && merged[len(merged)-1] == prev &&
+			prev.Segment.Stop == n.Segment.Start && !prev.SoftLineBreak() && !prev.HardLineBreak() &&
+			!prev.IsRaw() && !n.IsRaw() {
+			prev.Segment = prev.Segment.WithStop(n.Segment.Stop)
+			prev.SetSoftLineBreak(n.SoftLineBreak())
+			prev.SetHardLineBreak(n.HardLineBreak())
+			n.Parent().RemoveChild(n.Parent(), n)
+			continue
+		}
+		merged = append(merged, n)
+	}
+	return merged
+}
+
+// replaceInText splits n around the regexp submatch indexes in matches,
+// replacing each match with the node returned by replace. Matches for which
+// replace returns nil stay text.
+func replaceInText(n *ast.Text, source []byte, matches [][]int, replace func(value []byte, m []int) ast.Node) {
+	value := n.Segment.Value(source)
+	parent := n.Parent()
+	start := 0
+	for _, m := range matches {
+		node := replace(value, m)
+		if node == nil {
+			continue
+		}
+		if m[0] > start {
+			before := text.NewSegment(n.Segment.Start+start, n.Segment.Start+m[0])
+			parent.InsertBefore(parent, n, ast.NewTextSegment(before))
+		}
+		parent.InsertBefore(parent, n, node)
+		start = m[1]
+	}
+	if start == 0 {
+		return
+	}
+	if start == len(value) && !n.SoftLineBreak() && !n.HardLineBreak() {
+		parent.RemoveChild(parent, n)
+		return
+	}
+	// The rest keeps the node, and with it any line break
+	n.Segment = n.Segment.WithStart(n.Segment.Start + start)
+}
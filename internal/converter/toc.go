@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// tocMarker is the paragraph text replaced by a table of contents
+var tocMarker = []byte("[TOC]")
+
+// kindTOC is the node kind for table of contents placeholders
+var kindTOC = ast.NewNodeKind("TOC")
+
+// toc marks where the table of contents macro goes
+type toc struct {
+	ast.BaseBlock
+}
+
+func (n *toc) Kind() ast.NodeKind {
+	return kindTOC
+}
+
+func (n *toc) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// tocTransformer replaces top-level "[TOC]" paragraphs with toc nodes. If
+// insert is set and the document has no marker, a toc is added at the top.
+type tocTransformer struct {
+	insert bool
+}
+
+func newTOCTransformer(insert bool) parser.ASTTransformer {
+	return &tocTransformer{insert: insert}
+}
+
+func (t *tocTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	found := false
+	for child := doc.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		if isTOCMarker(child, source) {
+			doc.ReplaceChild(doc, child, &toc{})
+			found = true
+		}
+		child = next
+	}
+	if t.insert && !found {
+		if first := doc.FirstChild(); first != nil {
+			doc.InsertBefore(doc, first, &toc{})
+		} else {
+			doc.AppendChild(doc, &toc{})
+		}
+	}
+}
+
+// isTOCMarker reports whether n is a paragraph holding only "[TOC]"
+func isTOCMarker(n ast.Node, source []byte) bool {
+	if n.Kind() != ast.KindParagraph || n.Lines().Len() != 1 {
+		return false
+	}
+	line := n.Lines().At(0)
+	return bytes.EqualFold(bytes.TrimSpace(line.Value(source)), tocMarker)
+}
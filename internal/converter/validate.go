@@ -0,0 +1,217 @@
+package converter
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidStorage is wrapped by ValidateStorage errors for storage format
+// that Confluence would reject.
+var ErrInvalidStorage = errors.New("invalid storage format")
+
+// storageElements lists the XHTML elements the Confluence Cloud (Fabric)
+// editor accepts in storage format
+var storageElements = map[string]bool{
+	"a": true, "b": true, "blockquote": true, "br": true, "caption": true,
+	"code": true, "col": true, "colgroup": true, "del": true, "div": true,
+	"em": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "hr": true, "i": true, "li": true, "ol": true, "p": true,
+	"pre": true, "s": true, "span": true, "strike": true, "strong": true,
+	"sub": true, "sup": true, "table": true, "tbody": true, "td": true,
+	"tfoot": true, "th": true, "thead": true, "time": true, "tr": true,
+	"u": true, "ul": true,
+}
+
+// storageParents lists the ac: and ri: elements the editor accepts, each
+// with the elements it must appear directly inside. A nil list means the
+// element may appear anywhere.
+var storageParents = map[string][]string{
+	"ac:structured-macro":      nil,
+	"ac:parameter":             {"ac:structured-macro"},
+	"ac:rich-text-body":        {"ac:structured-macro"},
+	"ac:plain-text-body":       {"ac:structured-macro"},
+	"ac:link":                  nil,
+	"ac:link-body":             {"ac:link"},
+	"ac:plain-text-link-body":  {"ac:link"},
+	"ac:image":                 nil,
+	"ac:caption":               {"ac:image"},
+	"ac:emoticon":              nil,
+	"ac:placeholder":           nil,
+	"ac:inline-comment-marker": nil,
+	"ac:task-list":             nil,
+	"ac:task":                  {"ac:task-list"},
+	"ac:task-id":               {"ac:task"},
+	"ac:task-status":           {"ac:task"},
+	"ac:task-uuid":             {"ac:task"},
+	"ac:task-body":             {"ac:task"},
+	"ac:layout":                {""},
+	"ac:layout-section":        {"ac:layout"},
+	"ac:layout-cell":           {"ac:layout-section"},
+	"ac:adf-extension":         nil,
+	"ac:adf-node":              {"ac:adf-extension", "ac:adf-node"},
+	"ac:adf-attribute":         {"ac:adf-node"},
+	"ac:adf-content":           {"ac:adf-node"},
+	"ac:adf-fallback":          {"ac:adf-extension"},
+	"ri:page":                  nil,
+	"ri:blog-post":             nil,
+	"ri:attachment":            nil,
+	"ri:url":                   nil,
+	"ri:user":                  nil,
+	"ri:space":                 nil,
+	"ri:content-entity":        nil,
+	"ri:shortcut":              nil,
+}
+
+// StorageProblem is a problem found in storage format, at a 1-based line
+// and column.
+type StorageProblem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// String returns the problem as line:column: message.
+func (p StorageProblem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// ValidateStorage checks that storage is well-formed XHTML made of elements
+// and macros the Confluence editor accepts, so a page can be refused before
+// upload rather than by an opaque API error. It reports the first problem
+// found, with its line number.
+func ValidateStorage(storage string) error {
+	problems := CheckStorage(storage)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("line %d: %s: %w", problems[0].Line, problems[0].Message, ErrInvalidStorage)
+}
+
+// CheckStorage returns the problems ValidateStorage looks for in storage,
+// in document order. Unsupported or misplaced elements are all reported,
+// but checking stops at the first point where storage is not well-formed,
+// such as an unescaped & or an unterminated CDATA section.
+func CheckStorage(storage string) []StorageProblem {
+	// The wrapper gives the fragment a single root without shifting lines
+	input := "<storage>" + storage + "</storage>"
+	d := xml.NewDecoder(strings.NewReader(input))
+	d.Entity = xml.HTMLEntity
+
+	var problems []StorageProblem
+	var stack []string
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			return problems
+		}
+		if err != nil {
+			problem := storageProblem(input, d.InputOffset(), err.Error())
+			var syntax *xml.SyntaxError
+			if errors.As(err, &syntax) {
+				problem.Line, problem.Message = syntax.Line, syntax.Msg
+			}
+			return append(problems, problem)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := elementName(t.Name)
+			if len(stack) == 0 {
+				stack = append(stack, "")
+				continue
+			}
+			parent := stack[len(stack)-1]
+			if err := validateElement(name, parent, t); err != nil {
+				problems = append(problems, storageProblem(input, offset, err.Error()))
+			}
+			stack = append(stack, name)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// storageProblem returns a problem at offset in input, the storage format
+// inside its wrapper
+func storageProblem(input string, offset int64, message string) StorageProblem {
+	before := input[:min(int(offset), len(input)-len("</storage>"))]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	if line == 1 {
+		column = max(column-len("<storage>"), 1)
+	}
+	return StorageProblem{Line: line, Column: column, Message: message}
+}
+
+// validateElement checks one element against its parent's name, which is
+// "" at the top level
+func validateElement(name, parent string, start xml.StartElement) error {
+	if parent == "code" {
+		return fmt.Errorf("unescaped <%s> inside <code>", name)
+	}
+	if parent == "ac:plain-text-body" || parent == "ac:plain-text-link-body" {
+		return fmt.Errorf("<%s> inside <%s>, which holds only text", name, parent)
+	}
+
+	if storageElements[name] {
+		return nil
+	}
+	parents, ok := storageParents[name]
+	if !ok {
+		return fmt.Errorf("unsupported element <%s>", name)
+	}
+	if parents != nil && !slices.Contains(parents, parent) {
+		where := "<" + parent + ">"
+		if parent == "" {
+			where = "the top level"
+		}
+		return fmt.Errorf("<%s> not allowed inside %s", name, where)
+	}
+	if name == "ac:structured-macro" {
+		macro := attrValue(start, "ac:name")
+		if !validMacroName(macro) {
+			return fmt.Errorf("macro with invalid name %q", macro)
+		}
+	}
+	return nil
+}
+
+// elementName returns an element's name with its namespace prefix, as
+// written in storage format
+func elementName(name xml.Name) string {
+	if name.Space == "" {
+		return strings.ToLower(name.Local)
+	}
+	return name.Space + ":" + name.Local
+}
+
+// attrValue returns the value of the named attribute of start, or ""
+func attrValue(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if elementName(attr.Name) == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// validMacroName reports whether name can name a macro: letters, digits,
+// hyphens, and underscores
+func validMacroName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
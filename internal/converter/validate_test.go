@@ -0,0 +1,123 @@
+package converter
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "paragraph", input: "<p>Hello &amp; <strong>world</strong>&nbsp;</p>"},
+		{name: "macro", input: `<ac:structured-macro ac:name="info"><ac:parameter ac:name="title">T</ac:parameter><ac:rich-text-body><p>x</p></ac:rich-text-body></ac:structured-macro>`},
+		{name: "code macro", input: `<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[<b>x</b>]]></ac:plain-text-body></ac:structured-macro>`},
+		{name: "link", input: `<p><ac:link><ri:page ri:content-title="Home" /><ac:plain-text-link-body><![CDATA[Home]]></ac:plain-text-link-body></ac:link></p>`},
+		{name: "adf extension", input: `<ac:adf-extension><ac:adf-node type="panel"><ac:adf-attribute key="panel-type">custom</ac:adf-attribute><ac:adf-content><p>x</p></ac:adf-content></ac:adf-node><ac:adf-fallback><div class="panel"><p>x</p></div></ac:adf-fallback></ac:adf-extension>`},
+		{name: "adf attribute outside node", input: `<ac:adf-extension><ac:adf-attribute key="k">v</ac:adf-attribute></ac:adf-extension>`, wantErr: "<ac:adf-attribute> not allowed inside <ac:adf-extension>"},
+		{name: "layout", input: `<ac:layout><ac:layout-section ac:type="single"><ac:layout-cell><p>x</p></ac:layout-cell></ac:layout-section></ac:layout>`},
+		{name: "unclosed element", input: "<p>one\n<p>two</p>", wantErr: "line 2: element <p> closed by </storage>"},
+		{name: "bare ampersand", input: "<p>a & b</p>", wantErr: "line 1:"},
+		{name: "unsupported element", input: "<p>x</p>\n<div><script>x</script></div>", wantErr: "line 2: unsupported element <script>"},
+		{name: "unknown ac element", input: "<ac:widget />", wantErr: "unsupported element <ac:widget>"},
+		{name: "unescaped code span", input: "<p><code>a <b>b</b></code></p>", wantErr: "unescaped <b> inside <code>"},
+		{name: "parameter outside macro", input: `<p><ac:parameter ac:name="x">y</ac:parameter></p>`, wantErr: "<ac:parameter> not allowed inside <p>"},
+		{name: "nested layout", input: `<p><ac:layout></ac:layout></p>`, wantErr: "<ac:layout> not allowed inside <p>"},
+		{name: "macro without name", input: `<ac:structured-macro></ac:structured-macro>`, wantErr: `macro with invalid name ""`},
+		{name: "element in plain text body", input: `<ac:structured-macro ac:name="code"><ac:plain-text-body><p>x</p></ac:plain-text-body></ac:structured-macro>`, wantErr: "<p> inside <ac:plain-text-body>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStorage(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, ErrInvalidStorage) {
+				t.Errorf("err = %v, want %q wrapping ErrInvalidStorage", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckStorage(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "valid", input: "<p>Hello</p>"},
+		{
+			name:  "every misplaced element",
+			input: "<p>x</p><script>y</script>\n  <p><ac:parameter ac:name=\"x\">y</ac:parameter></p>",
+			want:  []string{"1:9: unsupported element <script>", "2:6: <ac:parameter> not allowed inside <p>"},
+		},
+		{
+			name:  "stops at malformed XML",
+			input: "<blink>x</blink>\n<p>a & b</p><script />",
+			want:  []string{"1:1: unsupported element <blink>", "2:7: invalid character entity & (no semicolon)"},
+		},
+		{
+			name:  "unterminated CDATA",
+			input: `<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[x`,
+			want:  []string{"1:67: unexpected EOF in CDATA section"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range CheckStorage(tt.input) {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckStorage_CloudPage(t *testing.T) {
+	// The body of a page saved by the Confluence Cloud editor, with ADF
+	// extensions for a decision list and a custom panel
+	page, err := os.ReadFile("../../testdata/cloud-page.storage")
+	if err != nil {
+		t.Fatalf("reading test data: %v", err)
+	}
+	if problems := CheckStorage(string(page)); len(problems) != 0 {
+		t.Errorf("problems = %v, want none", problems)
+	}
+}
+
+func TestValidateStorage_ConvertedMarkdown(t *testing.T) {
+	comprehensive, err := os.ReadFile("../../testdata/comprehensive-test.md")
+	if err != nil {
+		t.Fatalf("reading test data: %v", err)
+	}
+	inputs := []string{
+		string(comprehensive),
+		"> [!NOTE]\n> Note\n\n:::tip Title\nTip\n:::\n\n<details><summary>More</summary>\n\nBody\n\n</details>",
+		"[TOC]\n\n# A\n\nText[^1] with `<b>` and ==mark== ^sup^ ~sub~ :smile: {status:green|OK} {date:2025-03-01} {anchor:here}\n\n[^1]: Note",
+		"::::columns\n:::column\nLeft\n:::\n:::column\nRight\n:::\n::::\n\nAfter",
+		"- [ ] one\n  - [x] two\n- plain\n\n{children depth=2}\n\n{include:DOC:Home}\n\n[[DOC:Home]]",
+		"a <sup>b </tr> <td>c\n\n<div>block <sub>x</div>\n\n*d <sup>e* f</sup>",
+		"<table><tr><td colspan=\"2\">a</td></tr></table>\n\n$$\nx]]>y\n$$\n\n```go {title=\"main.go\"}\nx := \"]]>\"\n```",
+	}
+
+	for _, opts := range []Options{{}, {AllowHTML: true, Subscript: true}, {DisabledMacros: []string{"code", "info", "tip", "expand", "toc", "status"}}} {
+		for _, input := range inputs {
+			out := convertWith(t, input, opts)
+			if err := ValidateStorage(out); err != nil {
+				t.Errorf("ValidateStorage() error = %v for output\n%s", err, out)
+			}
+		}
+	}
+}
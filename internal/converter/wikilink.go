@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// wikiSpaceKeyRegex matches the space key prefix of a wiki link target.
+// Keys must be upper case (or a ~personal key), so titles such as
+// "Meeting: Notes" are not mistaken for a space reference.
+var wikiSpaceKeyRegex = regexp.MustCompile(`^(?:[A-Z0-9]+|~[A-Za-z0-9_.@-]+)$`)
+
+// kindWikiLink is the node kind for [[Page Title]] links
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLink is a link to another Confluence page by title:
+//
+//	[[Page Title]]
+//	[[SPACE:Page Title]]
+//	[[Page Title|link text]]
+type wikiLink struct {
+	ast.BaseInline
+	space string
+	title string
+	text  string
+}
+
+func (n *wikiLink) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+func (n *wikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Space": n.space, "Title": n.title, "Text": n.text}, nil)
+}
+
+// wikiLinkParser parses [[...]] into wikiLink nodes
+type wikiLinkParser struct{}
+
+func newWikiLinkParser() parser.InlineParser {
+	return &wikiLinkParser{}
+}
+
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (p *wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	inner := string(line[2 : 2+end])
+	if strings.ContainsAny(inner, "[]") {
+		return nil
+	}
+
+	target, linkText, _ := strings.Cut(inner, "|")
+	n := &wikiLink{text: strings.TrimSpace(linkText)}
+	n.space, n.title = splitPageTarget(target)
+	if n.title == "" {
+		return nil
+	}
+
+	block.Advance(2 + end + 2)
+	return n
+}
+
+// splitPageTarget splits "SPACE:Page Title" into its space key and title.
+// The space key is optional, and empty if missing.
+func splitPageTarget(target string) (space, title string) {
+	target = strings.TrimSpace(target)
+	if key, title, ok := strings.Cut(target, ":"); ok && wikiSpaceKeyRegex.MatchString(key) {
+		return key, strings.TrimSpace(title)
+	}
+	return "", target
+}
@@ -0,0 +1,210 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// opKind is the kind of an edit to a line
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// edit is one line of an edit script, with its line number in each text
+// (0-based, or the next line for the side it is not in)
+type edit struct {
+	kind opKind
+	from int
+	to   int
+	line string
+}
+
+// Unified returns a unified diff of from and to, with the header naming
+// them fromName and toName, or "" if they are the same. A final newline is
+// ignored.
+func Unified(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	edits := lineEdits(splitLines(from), splitLines(to))
+	groups := hunks(edits)
+	if len(groups) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range groups {
+		writeHunk(&b, edits[h[0]:h[1]])
+	}
+	return b.String()
+}
+
+// Hunks returns the number of separate changes in a unified diff from
+// Unified
+func Hunks(unified string) int {
+	n := 0
+	for _, line := range strings.Split(unified, "\n") {
+		if strings.HasPrefix(line, "@@ ") {
+			n++
+		}
+	}
+	return n
+}
+
+// splitLines splits s into lines, ignoring a final newline
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// lineEdits returns the shortest edit script turning a into b, found with
+// Myers' algorithm
+func lineEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+2)
+	// trace[d] holds v[k] for k in [-d, d] after step d
+	var trace [][]int
+	done := false
+	for d := 0; d <= limit && !done; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	// Walk back from the end, collecting edits in reverse
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{opEqual, x, y, a[x]})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{opInsert, x, y, b[y]})
+		} else {
+			x--
+			edits = append(edits, edit{opDelete, x, y, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{opEqual, x, y, a[x]})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunks groups edits into hunks of changes with their context, returning
+// the start and end index of each in edits. Changes closer than twice the
+// context share a hunk.
+func hunks(edits []edit) [][2]int {
+	var result [][2]int
+	for i := 0; i < len(edits); {
+		if edits[i].kind == opEqual {
+			i++
+			continue
+		}
+		start := max(i-contextLines, 0)
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].kind == opEqual {
+				run++
+			}
+			if run == len(edits) || run-end > 2*contextLines {
+				end = min(end+contextLines, len(edits))
+				break
+			}
+			end = run
+		}
+		result = append(result, [2]int{start, end})
+		i = end
+	}
+	return result
+}
+
+// writeHunk writes a hunk header and its lines
+func writeHunk(b *strings.Builder, edits []edit) {
+	fromStart, toStart := edits[0].from, edits[0].to
+	var fromCount, toCount int
+	for _, e := range edits {
+		if e.kind != opInsert {
+			fromCount++
+		}
+		if e.kind != opDelete {
+			toCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(fromStart, fromCount), hunkRange(toStart, toCount))
+	for _, e := range edits {
+		switch e.kind {
+		case opEqual:
+			b.WriteString(" ")
+		case opDelete:
+			b.WriteString("-")
+		case opInsert:
+			b.WriteString("+")
+		}
+		b.WriteString(e.line + "\n")
+	}
+}
+
+// hunkRange formats the line range of a hunk in one text, given its 0-based
+// start and line count. An empty range names the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{name: "same", from: "a\nb\n", to: "a\nb", want: ""},
+		{
+			name: "changed line",
+			from: "a\nb\nc",
+			to:   "a\nB\nc",
+			want: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "insert at start",
+			from: "b",
+			to:   "a\nb",
+			want: "--- from\n+++ to\n@@ -1 +1,2 @@\n+a\n b\n",
+		},
+		{
+			name: "from empty",
+			from: "",
+			to:   "a",
+			want: "--- from\n+++ to\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "delete at end",
+			from: "a\nb",
+			to:   "a",
+			want: "--- from\n+++ to\n@@ -1,2 +1 @@\n a\n-b\n",
+		},
+		{
+			name: "distant changes in separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve",
+			want: "--- from\n+++ to\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name: "close changes share a hunk",
+			from: "1\n2\n3\n4\n5\n6\n7",
+			to:   "one\n2\n3\n4\n5\n6\nseven",
+			want: "--- from\n+++ to\n@@ -1,7 +1,7 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n-7\n+seven\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("from", "to", tt.from, tt.to); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnified_Applies(t *testing.T) {
+	// Rebuilding each side from the diff lines checks the edit script
+	from := "a\nb\nc\nd\ne\nf\ng"
+	to := "a\nc\nd\nx\ny\ne\ng\nh"
+	var gotFrom, gotTo []string
+	for _, line := range strings.Split(strings.TrimSuffix(Unified("f", "t", from, to), "\n"), "\n")[2:] {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+		case strings.HasPrefix(line, "-"):
+			gotFrom = append(gotFrom, line[1:])
+		case strings.HasPrefix(line, "+"):
+			gotTo = append(gotTo, line[1:])
+		default:
+			gotFrom = append(gotFrom, line[1:])
+			gotTo = append(gotTo, line[1:])
+		}
+	}
+	if strings.Join(gotFrom, "\n") != from || strings.Join(gotTo, "\n") != to {
+		t.Errorf("diff lines rebuild %q and %q, want %q and %q", gotFrom, gotTo, from, to)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	unified := Unified("a", "b", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10", "x\n2\n3\n4\n5\n6\n7\n8\n9\ny")
+	if got := Hunks(unified); got != 2 {
+		t.Errorf("Hunks() = %d, want 2", got)
+	}
+	if got := Hunks(""); got != 0 {
+		t.Errorf("Hunks(\"\") = %d, want 0", got)
+	}
+}